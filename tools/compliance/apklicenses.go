@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// apkManifest is the path to the binary manifest inside an APK.
+const apkManifest = "AndroidManifest.xml"
+
+// manifestElement is an element of an Android manifest with the attributes
+// by local name, e.g. "license" for "android:license".
+type manifestElement struct {
+	name  string
+	attrs map[string]string
+}
+
+// ApkLicenseExtractor describes APKs with MetaLicStubs using the licenses
+// their AndroidManifest.xml declares in <uses-license android:name="...">
+// elements or android:license attributes.
+//
+// Reads the manifest with `aapt2 dump xmltree`, or with a minimal binary XML
+// parser when aapt2 is not available.
+type ApkLicenseExtractor struct {
+	// Aapt2 is the aapt2 binary to run or empty for "aapt2" on PATH.
+	Aapt2 string
+	// LookPath finds Aapt2 or is nil to use exec.LookPath.
+	LookPath func(file string) (string, error)
+	// Output runs command `name` with `args` returning its standard output
+	// or is nil to use os/exec.
+	Output func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// Extract returns the MetaLicStub named "<apkPath>.meta_lic" for the APK
+// `apkPath`.
+//
+// The license kinds come from the SPDX identifiers or well-known license
+// URLs the manifest declares. Licenses naming no known license make the
+// license kind unidentified. The package name and version come from the
+// package and android:versionName attributes of the manifest element, with
+// the APK file name as the fallback package name.
+//
+// Returns an *UnknownLicenseError when the manifest declares no license.
+func (e *ApkLicenseExtractor) Extract(ctx context.Context, apkPath string) (MetaLicStub, error) {
+	elements, err := e.readManifest(ctx, apkPath)
+	if err != nil {
+		return MetaLicStub{}, err
+	}
+
+	stub := MetaLicStub{
+		Name:        apkPath + ".meta_lic",
+		PackageName: strings.TrimSuffix(filepath.Base(apkPath), ".apk"),
+	}
+	var licenses []string
+	for _, el := range elements {
+		if el.name == "manifest" {
+			stub.PackageName = firstNonEmpty(el.attrs["package"], stub.PackageName)
+			stub.Version = el.attrs["versionName"]
+		}
+		if el.name == "uses-license" {
+			if name := strings.TrimSpace(el.attrs["name"]); len(name) > 0 {
+				licenses = append(licenses, name)
+			}
+		}
+		if license := strings.TrimSpace(el.attrs["license"]); len(license) > 0 {
+			licenses = append(licenses, license)
+		}
+	}
+	if len(licenses) == 0 {
+		return MetaLicStub{}, &UnknownLicenseError{apkPath, "no uses-license element or android:license attribute"}
+	}
+
+	kinds := make(map[string]string)
+	for _, license := range licenses {
+		ids := licenseIDs([]string{license})
+		if len(ids) == 0 {
+			// Licenses naming no known license need someone to review
+			// them.
+			kinds[unidentifiedLicenseKind] = "by_exception_only"
+		}
+		for _, id := range ids {
+			kinds[spdxLicenseKindPrefix+id] = spdxConditions[id]
+		}
+	}
+	conditions := make(map[string]struct{})
+	for kind, condition := range kinds {
+		stub.LicenseKinds = append(stub.LicenseKinds, kind)
+		conditions[condition] = struct{}{}
+	}
+	for condition := range conditions {
+		stub.LicenseConditions = append(stub.LicenseConditions, condition)
+	}
+	sort.Strings(stub.LicenseKinds)
+	sort.Strings(stub.LicenseConditions)
+	return stub, nil
+}
+
+// readManifest returns the elements of the manifest of the APK `apkPath` in
+// document order using aapt2 when available.
+func (e *ApkLicenseExtractor) readManifest(ctx context.Context, apkPath string) ([]manifestElement, error) {
+	lookPath := e.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	aapt2, err := lookPath(firstNonEmpty(e.Aapt2, "aapt2"))
+	if err != nil {
+		return readBinaryManifest(apkPath)
+	}
+	output := e.Output
+	if output == nil {
+		output = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, name, args...).Output()
+		}
+	}
+	out, err := output(ctx, aapt2, "dump", "xmltree", "--file", apkManifest, apkPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dump the manifest of %q: %w", apkPath, err)
+	}
+	elements, err := parseXMLTree(out)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the manifest of %q: %w", apkPath, err)
+	}
+	return elements, nil
+}
+
+// readBinaryManifest returns the elements of the binary manifest of the APK
+// `apkPath` in document order.
+func readBinaryManifest(apkPath string) ([]manifestElement, error) {
+	zr, err := zip.OpenReader(apkPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APK %q: %w", apkPath, err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name != apkManifest {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the manifest of %q: %w", apkPath, err)
+		}
+		elements, err := parseBinaryXML(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the manifest of %q: %w", apkPath, err)
+		}
+		return elements, nil
+	}
+	return nil, &UnknownLicenseError{apkPath, "no " + apkManifest}
+}
+
+// parseXMLTree returns the elements in the output of `aapt2 dump xmltree`,
+// which prints each element as `E: name (line=N)` and each of its attributes
+// as `A: namespace:name(0xresid)=value` on the following lines. String
+// values are quoted and followed by the raw value, e.g.
+// `A: package="com.example" (Raw: "com.example")`.
+func parseXMLTree(out []byte) ([]manifestElement, error) {
+	var elements []manifestElement
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(text, "E: "):
+			name, _, _ := strings.Cut(strings.TrimPrefix(text, "E: "), " ")
+			elements = append(elements, manifestElement{name, make(map[string]string)})
+		case strings.HasPrefix(text, "A: "):
+			if len(elements) == 0 {
+				return nil, fmt.Errorf("line %d: attribute outside any element", line)
+			}
+			name, value, ok := strings.Cut(strings.TrimPrefix(text, "A: "), "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: want name=value, got %q", line, text)
+			}
+			// Drop any resource id and namespace.
+			name, _, _ = strings.Cut(name, "(")
+			name = name[strings.LastIndex(name, ":")+1:]
+			if quoted, err := strconv.QuotedPrefix(value); err == nil {
+				value, _ = strconv.Unquote(quoted)
+			}
+			elements[len(elements)-1].attrs[name] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
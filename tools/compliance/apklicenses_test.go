@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// expectedWidgetStub is the stub for testdata/apk/widget.apk.
+var expectedWidgetStub = MetaLicStub{
+	Name:              "testdata/apk/widget.apk.meta_lic",
+	PackageName:       "com.example.widget",
+	Version:           "1.2.3",
+	LicenseKinds:      []string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-MIT"},
+	LicenseConditions: []string{"notice"},
+}
+
+// notFound is an ApkLicenseExtractor.LookPath finding no aapt2.
+func notFound(file string) (string, error) {
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
+
+func TestApkLicenseExtractorAapt2(t *testing.T) {
+	xmltree, err := os.ReadFile("testdata/apk/widget.xmltree.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ran []string
+	e := &ApkLicenseExtractor{
+		Aapt2: "aapt2",
+		LookPath: func(file string) (string, error) {
+			return "/opt/build-tools/" + file, nil
+		},
+		Output: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			ran = append([]string{name}, args...)
+			return xmltree, nil
+		},
+	}
+	stub, err := e.Extract(context.Background(), "testdata/apk/widget.apk")
+	if err != nil {
+		t.Fatalf("Extract(): got error %s, want no error", err)
+	}
+	if !reflect.DeepEqual(stub, expectedWidgetStub) {
+		t.Errorf("Extract(): got %+v, want %+v", stub, expectedWidgetStub)
+	}
+	expectedRan := []string{"/opt/build-tools/aapt2", "dump", "xmltree", "--file", "AndroidManifest.xml", "testdata/apk/widget.apk"}
+	if !reflect.DeepEqual(ran, expectedRan) {
+		t.Errorf("Extract(): ran %q, want %q", ran, expectedRan)
+	}
+
+	e.Output = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return nil, errors.New("exit status 1")
+	}
+	if _, err := e.Extract(context.Background(), "testdata/apk/widget.apk"); err == nil || !strings.Contains(err.Error(), "cannot dump the manifest") {
+		t.Errorf("Extract(aapt2 fails): got error %v, want cannot dump the manifest", err)
+	}
+}
+
+func TestApkLicenseExtractorBinaryManifest(t *testing.T) {
+	e := &ApkLicenseExtractor{LookPath: notFound}
+	stub, err := e.Extract(context.Background(), "testdata/apk/widget.apk")
+	if err != nil {
+		t.Fatalf("Extract(): got error %s, want no error", err)
+	}
+	if !reflect.DeepEqual(stub, expectedWidgetStub) {
+		t.Errorf("Extract(): got %+v, want %+v", stub, expectedWidgetStub)
+	}
+
+	// nolicense.apk has a UTF-8 string pool.
+	elements, err := readBinaryManifest("testdata/apk/nolicense.apk")
+	if err != nil {
+		t.Fatalf("readBinaryManifest(): got error %s, want no error", err)
+	}
+	expected := []manifestElement{
+		{"manifest", map[string]string{"versionCode": "1", "package": "com.example.nolicense"}},
+		{"application", map[string]string{"label": "No License"}},
+	}
+	if !reflect.DeepEqual(elements, expected) {
+		t.Errorf("readBinaryManifest(): got %v, want %v", elements, expected)
+	}
+}
+
+func TestApkLicenseExtractorErrors(t *testing.T) {
+	widget, err := zip.OpenReader("testdata/apk/widget.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := readZipFile(widget.File[0])
+	widget.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// writeApk writes an APK holding `files` returning its path.
+	writeApk := func(files map[string][]byte) string {
+		path := filepath.Join(t.TempDir(), "app.apk")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for name, data := range files {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		return path
+	}
+
+	tests := []struct {
+		name          string
+		apk           string
+		expectUnknown bool
+		expectedError string
+	}{
+		{"no license", "testdata/apk/nolicense.apk", true, "no uses-license element or android:license attribute"},
+		{"no manifest", writeApk(map[string][]byte{"classes.dex": nil}), true, "no AndroidManifest.xml"},
+		{"truncated manifest", writeApk(map[string][]byte{"AndroidManifest.xml": manifest[:len(manifest)/2]}), false, "truncated binary XML document"},
+		{"text manifest", writeApk(map[string][]byte{"AndroidManifest.xml": []byte("<manifest/>")}), false, "not a binary XML document"},
+		{"not an apk", "testdata/apk/README.md", false, "invalid APK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&ApkLicenseExtractor{LookPath: notFound}).Extract(context.Background(), tt.apk)
+			if err == nil {
+				t.Fatalf("Extract(): got no error, want %q", tt.expectedError)
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Extract(): got error %q, want %q", err, tt.expectedError)
+			}
+			var unknown *UnknownLicenseError
+			if errors.As(err, &unknown) != tt.expectUnknown {
+				t.Errorf("Extract(): got error %T, want *UnknownLicenseError %v", err, tt.expectUnknown)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Chunk types and value types of the Android binary XML format per
+// frameworks/base/libs/androidfw/include/androidfw/ResourceTypes.h.
+const (
+	binaryXMLStringPool   = 0x0001
+	binaryXMLDocument     = 0x0003
+	binaryXMLStartElement = 0x0102
+
+	// binaryXMLUTF8 flags a string pool holding UTF-8 rather than UTF-16.
+	binaryXMLUTF8 = 1 << 8
+	// binaryXMLNoString marks an absent string pool reference.
+	binaryXMLNoString = 0xffffffff
+
+	binaryXMLTypeString  = 0x03
+	binaryXMLTypeIntDec  = 0x10
+	binaryXMLTypeBoolean = 0x12
+)
+
+// parseBinaryXML returns the elements of the Android binary XML document
+// `data`, e.g. the AndroidManifest.xml of an APK, in document order.
+//
+// Only understands enough of the format to read element names and string,
+// integer and boolean attribute values. Formats other values, e.g. resource
+// references, as hexadecimal.
+func parseBinaryXML(data []byte) ([]manifestElement, error) {
+	if len(data) < 8 || binary.LittleEndian.Uint16(data) != binaryXMLDocument {
+		return nil, fmt.Errorf("not a binary XML document")
+	}
+	if size := binary.LittleEndian.Uint32(data[4:]); uint64(size) > uint64(len(data)) {
+		return nil, fmt.Errorf("truncated binary XML document: got %d bytes, want %d", len(data), size)
+	}
+	_, headerSize, size, err := binaryXMLChunk(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	data = data[:size]
+
+	var pool []string
+	var elements []manifestElement
+	for offset := int(headerSize); offset < len(data); {
+		typ, _, size, err := binaryXMLChunk(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		chunk := data[offset : offset+int(size)]
+		switch typ {
+		case binaryXMLStringPool:
+			pool, err = parseBinaryXMLStrings(chunk)
+			if err != nil {
+				return nil, err
+			}
+		case binaryXMLStartElement:
+			el, err := parseBinaryXMLElement(chunk, pool)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, el)
+		}
+		offset += int(size)
+	}
+	return elements, nil
+}
+
+// binaryXMLChunk returns the type, header size and size of the chunk at
+// `offset` in `data` checking that it fits.
+func binaryXMLChunk(data []byte, offset int) (uint16, uint16, uint32, error) {
+	if offset+8 > len(data) {
+		return 0, 0, 0, fmt.Errorf("truncated binary XML chunk header at offset %d", offset)
+	}
+	typ := binary.LittleEndian.Uint16(data[offset:])
+	headerSize := binary.LittleEndian.Uint16(data[offset+2:])
+	size := binary.LittleEndian.Uint32(data[offset+4:])
+	if size < 8 || uint32(headerSize) > size || uint64(offset)+uint64(size) > uint64(len(data)) {
+		return 0, 0, 0, fmt.Errorf("invalid binary XML chunk 0x%04x at offset %d: header size %d, size %d", typ, offset, headerSize, size)
+	}
+	return typ, headerSize, size, nil
+}
+
+// parseBinaryXMLStrings returns the strings of the string pool `chunk`.
+func parseBinaryXMLStrings(chunk []byte) ([]string, error) {
+	if len(chunk) < 28 {
+		return nil, fmt.Errorf("truncated binary XML string pool")
+	}
+	count := int(binary.LittleEndian.Uint32(chunk[8:]))
+	flags := binary.LittleEndian.Uint32(chunk[16:])
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:]))
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	if count < 0 || headerSize+4*count > len(chunk) || stringsStart > len(chunk) {
+		return nil, fmt.Errorf("invalid binary XML string pool: %d strings", count)
+	}
+	result := make([]string, count)
+	for i := range result {
+		offset := stringsStart + int(binary.LittleEndian.Uint32(chunk[headerSize+4*i:]))
+		if offset < 0 || offset >= len(chunk) {
+			return nil, fmt.Errorf("invalid binary XML string %d: offset %d", i, offset)
+		}
+		var s string
+		var ok bool
+		if flags&binaryXMLUTF8 != 0 {
+			s, ok = binaryXMLUTF8String(chunk[offset:])
+		} else {
+			s, ok = binaryXMLUTF16String(chunk[offset:])
+		}
+		if !ok {
+			return nil, fmt.Errorf("truncated binary XML string %d", i)
+		}
+		result[i] = s
+	}
+	return result, nil
+}
+
+// binaryXMLUTF8String decodes the UTF-8 string at the start of `b`, which
+// starts with its length in UTF-16 units and its length in bytes, each one
+// byte or two when the high bit is set.
+func binaryXMLUTF8String(b []byte) (string, bool) {
+	length := func() (int, bool) {
+		if len(b) < 1 {
+			return 0, false
+		}
+		n := int(b[0])
+		if n&0x80 == 0 {
+			b = b[1:]
+			return n, true
+		}
+		if len(b) < 2 {
+			return 0, false
+		}
+		n = (n&0x7f)<<8 | int(b[1])
+		b = b[2:]
+		return n, true
+	}
+	if _, ok := length(); !ok {
+		return "", false
+	}
+	n, ok := length()
+	if !ok || n > len(b) {
+		return "", false
+	}
+	return string(b[:n]), true
+}
+
+// binaryXMLUTF16String decodes the UTF-16 string at the start of `b`, which
+// starts with its length in UTF-16 units, one unit or two when the high bit
+// is set.
+func binaryXMLUTF16String(b []byte) (string, bool) {
+	if len(b) < 2 {
+		return "", false
+	}
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	if n&0x8000 != 0 {
+		if len(b) < 2 {
+			return "", false
+		}
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+	}
+	if 2*n > len(b) {
+		return "", false
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// parseBinaryXMLElement returns the element of the start element `chunk`
+// using the string pool `pool`.
+func parseBinaryXMLElement(chunk []byte, pool []string) (manifestElement, error) {
+	// The 16 byte node header precedes the element: namespace and name
+	// references, then the offset, size and count of the attributes.
+	if len(chunk) < 16+20 {
+		return manifestElement{}, fmt.Errorf("truncated binary XML element")
+	}
+	ext := chunk[16:]
+	str := func(ref uint32) (string, error) {
+		if ref == binaryXMLNoString {
+			return "", nil
+		}
+		if int(ref) >= len(pool) {
+			return "", fmt.Errorf("invalid binary XML string reference %d of %d", ref, len(pool))
+		}
+		return pool[ref], nil
+	}
+	name, err := str(binary.LittleEndian.Uint32(ext[4:]))
+	if err != nil {
+		return manifestElement{}, err
+	}
+	el := manifestElement{name, make(map[string]string)}
+	start := int(binary.LittleEndian.Uint16(ext[8:]))
+	attrSize := int(binary.LittleEndian.Uint16(ext[10:]))
+	count := int(binary.LittleEndian.Uint16(ext[12:]))
+	if attrSize < 20 || start+attrSize*count > len(ext) {
+		return manifestElement{}, fmt.Errorf("invalid binary XML element %q: %d attributes of %d bytes", name, count, attrSize)
+	}
+	for i := 0; i < count; i++ {
+		attr := ext[start+attrSize*i:]
+		attrName, err := str(binary.LittleEndian.Uint32(attr[4:]))
+		if err != nil {
+			return manifestElement{}, err
+		}
+		value, err := str(binary.LittleEndian.Uint32(attr[8:]))
+		if err != nil {
+			return manifestElement{}, err
+		}
+		if binary.LittleEndian.Uint32(attr[8:]) == binaryXMLNoString {
+			dataType := attr[15]
+			data := binary.LittleEndian.Uint32(attr[16:])
+			switch dataType {
+			case binaryXMLTypeString:
+				value, err = str(data)
+				if err != nil {
+					return manifestElement{}, err
+				}
+			case binaryXMLTypeIntDec:
+				value = strconv.Itoa(int(int32(data)))
+			case binaryXMLTypeBoolean:
+				value = strconv.FormatBool(data != 0)
+			default:
+				value = fmt.Sprintf("0x%08x", data)
+			}
+		}
+		el.attrs[attrName] = value
+	}
+	return el, nil
+}
@@ -182,7 +182,7 @@ func billOfMaterials(ctx *context, files ...string) error {
 		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
 	}
 
-	for path := range ni.InstallPaths() {
+	for _, path := range ni.AllInstallPaths() {
 		fmt.Fprintln(ctx.stdout, ctx.strip(path))
 	}
 	return nil
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance/compliance_proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	failConflicts      = fmt.Errorf("conflicts")
+	failNoneRequested  = fmt.Errorf("\nNo license metadata files requested")
+	failUnknownCommand = fmt.Errorf("\nUnknown command")
+)
+
+type ctlContext struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	client      compliance_proto.ComplianceClient
+	product     string
+	stripPrefix []string
+	// noTexts requests the hash of each license text instead of the text.
+	noTexts bool
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} command file.meta_lic {file.meta_lic...}
+
+Queries a complianced server about the license graph rooted at the license
+metadata files. The server resolves the files relative to its own directory.
+
+Commands:
+  build   reads the graph into the server's cache and reports its size
+  notice  writes the notice as a notice_proto.Notice textproto
+  check   reports on stderr any targets where policy says that the source
+          both must and must not be shared, and writes PASS or FAIL to stdout
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	server := flags.String("server", "localhost:8790", "The address of the complianced server.")
+	product := flags.String("product", "", "The name of the product for which the notice is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	noTexts := flags.Bool("no_texts", false, "Request the sha256 of each license text instead of the text.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify a command and at least one root target.
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := grpc.Dial(*server, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not connect to %q: %s\n", *server, err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx := &ctlContext{os.Stdout, os.Stderr, compliance_proto.NewComplianceClient(conn), *product, *stripPrefix, *noTexts}
+
+	err = complianceCtl(ctx, flags.Arg(0), flags.Args()[1:]...)
+	if err != nil {
+		if err == failNoneRequested || errors.Is(err, failUnknownCommand) {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		conn.Close()
+		os.Exit(1)
+	}
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// complianceCtl implements the compliancectl utility.
+func complianceCtl(ctx *ctlContext, command string, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	switch command {
+	case "build":
+		resp, err := ctx.client.BuildGraph(context.Background(), &compliance_proto.BuildGraphRequest{RootFiles: files})
+		if err != nil {
+			return fmt.Errorf("Unable to build license graph for %q: %v\n", files, err)
+		}
+		cached := ""
+		if resp.GetCached() {
+			cached = " (cached)"
+		}
+		fmt.Fprintf(ctx.stdout, "%s: %d targets, %d edges%s\n", resp.GetGraphId(), resp.GetTargets(), resp.GetEdges(), cached)
+	case "notice":
+		req := &compliance_proto.GetNoticeRequest{RootFiles: files, StripPrefix: ctx.stripPrefix}
+		if len(ctx.product) > 0 {
+			req.Product = proto.String(ctx.product)
+		}
+		if ctx.noTexts {
+			req.NoTexts = proto.Bool(true)
+		}
+		resp, err := ctx.client.GetNotice(context.Background(), req)
+		if err != nil {
+			return fmt.Errorf("Unable to get notice for %q: %v\n", files, err)
+		}
+		data, err := prototext.MarshalOptions{Multiline: true}.Marshal(resp.GetNotice())
+		if err != nil {
+			return fmt.Errorf("Unable to write textproto notice: %v\n", err)
+		}
+		ctx.stdout.Write(data)
+	case "check":
+		resp, err := ctx.client.CheckPolicy(context.Background(), &compliance_proto.CheckPolicyRequest{RootFiles: files})
+		if err != nil {
+			return fmt.Errorf("Unable to check policy for %q: %v\n", files, err)
+		}
+		for _, c := range resp.Conflicts {
+			fmt.Fprintf(ctx.stderr, "%s %s and must share from %s condition\n", c.GetTarget(), c.GetPrivacyCondition(), c.GetShareCondition())
+		}
+		if len(resp.Conflicts) > 0 {
+			fmt.Fprintln(ctx.stdout, "FAIL")
+			return failConflicts
+		}
+		fmt.Fprintln(ctx.stdout, "PASS")
+	default:
+		return fmt.Errorf("%w %q", failUnknownCommand, command)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/compliance_proto"
+	"android/soong/tools/compliance/notice_proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeServer answers every request with canned responses and records the
+// requests.
+type fakeServer struct {
+	compliance_proto.UnimplementedComplianceServer
+
+	requests  []proto.Message
+	conflicts []*compliance_proto.Conflict
+}
+
+func (s *fakeServer) BuildGraph(_ context.Context, req *compliance_proto.BuildGraphRequest) (*compliance_proto.BuildGraphResponse, error) {
+	s.requests = append(s.requests, req)
+	return &compliance_proto.BuildGraphResponse{
+		GraphId: proto.String("sha256:abc"),
+		Targets: proto.Int32(3),
+		Edges:   proto.Int32(2),
+		Cached:  proto.Bool(len(s.requests) > 1),
+	}, nil
+}
+
+func (s *fakeServer) GetNotice(_ context.Context, req *compliance_proto.GetNoticeRequest) (*compliance_proto.GetNoticeResponse, error) {
+	s.requests = append(s.requests, req)
+	return &compliance_proto.GetNoticeResponse{Notice: &notice_proto.Notice{
+		Product:   req.Product,
+		Libraries: []*notice_proto.Library{{Name: proto.String("libfoo")}},
+	}}, nil
+}
+
+func (s *fakeServer) CheckPolicy(_ context.Context, req *compliance_proto.CheckPolicyRequest) (*compliance_proto.CheckPolicyResponse, error) {
+	s.requests = append(s.requests, req)
+	if strings.HasSuffix(req.RootFiles[0], "missing.meta_lic") {
+		return nil, status.Error(codes.InvalidArgument, "unable to read")
+	}
+	return &compliance_proto.CheckPolicyResponse{Conflicts: s.conflicts}, nil
+}
+
+// newTestContext serves `s` over a loopback connection and returns a context
+// with a client for it.
+func newTestContext(t *testing.T, s *fakeServer) (*ctlContext, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	compliance_proto.RegisterComplianceServer(gs, s)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.Dial("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("cannot connect to test server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	return &ctlContext{stdout, stderr, compliance_proto.NewComplianceClient(conn), "", nil, false}, stdout, stderr
+}
+
+func TestBuild(t *testing.T) {
+	s := &fakeServer{}
+	ctx, stdout, _ := newTestContext(t, s)
+	for _, expected := range []string{
+		"sha256:abc: 3 targets, 2 edges\n",
+		"sha256:abc: 3 targets, 2 edges (cached)\n",
+	} {
+		stdout.Reset()
+		if err := complianceCtl(ctx, "build", "a.meta_lic", "b.meta_lic"); err != nil {
+			t.Fatalf("compliancectl build: got error %s, want no error", err)
+		}
+		if stdout.String() != expected {
+			t.Errorf("compliancectl build: got stdout %q, want %q", stdout.String(), expected)
+		}
+	}
+	if actual := s.requests[0].(*compliance_proto.BuildGraphRequest).RootFiles; !reflect.DeepEqual(actual, []string{"a.meta_lic", "b.meta_lic"}) {
+		t.Errorf("compliancectl build: got root files %q, want [a.meta_lic b.meta_lic]", actual)
+	}
+}
+
+func TestNotice(t *testing.T) {
+	s := &fakeServer{}
+	ctx, stdout, _ := newTestContext(t, s)
+	ctx.product = "fictional"
+	ctx.stripPrefix = []string{"out/"}
+	ctx.noTexts = true
+	if err := complianceCtl(ctx, "notice", "a.meta_lic"); err != nil {
+		t.Fatalf("compliancectl notice: got error %s, want no error", err)
+	}
+
+	expectedReq := &compliance_proto.GetNoticeRequest{
+		RootFiles:   []string{"a.meta_lic"},
+		Product:     proto.String("fictional"),
+		StripPrefix: []string{"out/"},
+		NoTexts:     proto.Bool(true),
+	}
+	if !proto.Equal(s.requests[0], expectedReq) {
+		t.Errorf("compliancectl notice: got request %v, want %v", s.requests[0], expectedReq)
+	}
+
+	var n notice_proto.Notice
+	if err := prototext.Unmarshal(stdout.Bytes(), &n); err != nil {
+		t.Fatalf("compliancectl notice: cannot parse stdout %q: %s", stdout.String(), err)
+	}
+	if n.GetProduct() != "fictional" || len(n.GetLibraries()) != 1 || n.GetLibraries()[0].GetName() != "libfoo" {
+		t.Errorf("compliancectl notice: got notice %v, want product fictional with libfoo", &n)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name           string
+		conflicts      []*compliance_proto.Conflict
+		expectedStdout string
+		expectedStderr string
+		expectedErr    error
+	}{
+		{
+			name:           "pass",
+			expectedStdout: "PASS\n",
+		},
+		{
+			name: "fail",
+			conflicts: []*compliance_proto.Conflict{{
+				Target:           proto.String("bin/bin2.meta_lic"),
+				PrivacyCondition: proto.String("proprietary"),
+				ShareCondition:   proto.String("restricted"),
+			}},
+			expectedStdout: "FAIL\n",
+			expectedStderr: "bin/bin2.meta_lic proprietary and must share from restricted condition\n",
+			expectedErr:    failConflicts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stdout, stderr := newTestContext(t, &fakeServer{conflicts: tt.conflicts})
+			err := complianceCtl(ctx, "check", "a.meta_lic")
+			if err != tt.expectedErr {
+				t.Errorf("compliancectl check: got error %v, want %v", err, tt.expectedErr)
+			}
+			if stdout.String() != tt.expectedStdout {
+				t.Errorf("compliancectl check: got stdout %q, want %q", stdout.String(), tt.expectedStdout)
+			}
+			if stderr.String() != tt.expectedStderr {
+				t.Errorf("compliancectl check: got stderr %q, want %q", stderr.String(), tt.expectedStderr)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx, _, _ := newTestContext(t, &fakeServer{})
+	if err := complianceCtl(ctx, "build"); err != failNoneRequested {
+		t.Errorf("compliancectl build with no files: got %v, want failNoneRequested", err)
+	}
+	if err := complianceCtl(ctx, "frob", "a.meta_lic"); !errors.Is(err, failUnknownCommand) {
+		t.Errorf("compliancectl frob: got %v, want failUnknownCommand", err)
+	}
+	err := complianceCtl(ctx, "check", "missing.meta_lic")
+	if err == nil || !strings.Contains(err.Error(), "unable to read") {
+		t.Errorf("compliancectl check with server error: got %v, want the server error", err)
+	}
+}
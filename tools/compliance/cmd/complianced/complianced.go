@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/compliance_proto"
+	"android/soong/tools/compliance/notice_proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	failNoneRequested = status.Error(codes.InvalidArgument, "no license metadata files requested")
+)
+
+// server implements the Compliance service for the license metadata files
+// under rootFS.
+type server struct {
+	compliance_proto.UnimplementedComplianceServer
+
+	rootFS fs.FS
+	// graphs caches the license graphs by root set.
+	graphs *compliance.LicenseGraphCache
+}
+
+func newServer(rootFS fs.FS, stderr io.Writer) *server {
+	return &server{rootFS: rootFS, graphs: compliance.NewLicenseGraphCache(rootFS, stderr)}
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options}
+
+Serves the Compliance gRPC service defined in compliance_proto/compliance.proto
+answering license graph queries for the license metadata files under the
+current directory. compliancectl is a client for the service.
+
+Reads each distinct set of root files once and answers later requests for the
+same set from memory. Restart the server after the license metadata changes.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	listen := flags.String("listen", "localhost:8790", "The address on which to listen.")
+
+	flags.Parse(expandedArgs)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not listen on %q: %s\n", *listen, err)
+		os.Exit(1)
+	}
+	s := grpc.NewServer()
+	compliance_proto.RegisterComplianceServer(s, newServer(compliance.FS, os.Stderr))
+	fmt.Fprintf(os.Stderr, "serving on %s\n", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// graph returns the graph id and the license graph rooted at `rootFiles`
+// from the cache. `cached` reports whether the graph was already cached.
+func (s *server) graph(rootFiles []string) (id string, lg *compliance.LicenseGraph, cached bool, err error) {
+	if len(rootFiles) < 1 {
+		return "", nil, false, failNoneRequested
+	}
+	id, lg, cached, err = s.graphs.Get(rootFiles)
+	if err != nil {
+		files, _ := compliance.RootSet(rootFiles)
+		return id, nil, cached, status.Errorf(codes.InvalidArgument, "unable to read license metadata file(s) %q: %v", files, err)
+	}
+	return id, lg, cached, nil
+}
+
+// BuildGraph implements compliance_proto.ComplianceServer.
+func (s *server) BuildGraph(_ context.Context, req *compliance_proto.BuildGraphRequest) (*compliance_proto.BuildGraphResponse, error) {
+	id, lg, cached, err := s.graph(req.RootFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &compliance_proto.BuildGraphResponse{
+		GraphId: proto.String(id),
+		Targets: proto.Int32(int32(len(lg.Targets()))),
+		Edges:   proto.Int32(int32(len(lg.Edges()))),
+		Cached:  proto.Bool(cached),
+	}, nil
+}
+
+// GetNotice implements compliance_proto.ComplianceServer.
+func (s *server) GetNotice(_ context.Context, req *compliance_proto.GetNoticeRequest) (*compliance_proto.GetNoticeResponse, error) {
+	_, lg, _, err := s.graph(req.RootFiles)
+	if err != nil {
+		return nil, err
+	}
+	ni, err := compliance.IndexLicenseTexts(s.rootFS, lg, compliance.ResolveNotices(lg))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "unable to read license text file(s) for %q: %v", req.RootFiles, err)
+	}
+	n, err := noticeProto(ni, req)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "unable to describe notice for %q: %v", req.RootFiles, err)
+	}
+	return &compliance_proto.GetNoticeResponse{Notice: n}, nil
+}
+
+// CheckPolicy implements compliance_proto.ComplianceServer.
+func (s *server) CheckPolicy(_ context.Context, req *compliance_proto.CheckPolicyRequest) (*compliance_proto.CheckPolicyResponse, error) {
+	_, lg, _, err := s.graph(req.RootFiles)
+	if err != nil {
+		return nil, err
+	}
+	resp := &compliance_proto.CheckPolicyResponse{}
+	for _, conflict := range compliance.ConflictingSharedPrivateSource(lg) {
+		resp.Conflicts = append(resp.Conflicts, &compliance_proto.Conflict{
+			Target:           proto.String(conflict.SourceNode.Name()),
+			PrivacyCondition: proto.String(conflict.PrivacyCondition.Name()),
+			ShareCondition:   proto.String(conflict.ShareCondition.Name()),
+		})
+	}
+	sort.Slice(resp.Conflicts, func(i, j int) bool {
+		ci, cj := resp.Conflicts[i], resp.Conflicts[j]
+		if ci.GetTarget() != cj.GetTarget() {
+			return ci.GetTarget() < cj.GetTarget()
+		}
+		if ci.GetPrivacyCondition() != cj.GetPrivacyCondition() {
+			return ci.GetPrivacyCondition() < cj.GetPrivacyCondition()
+		}
+		return ci.GetShareCondition() < cj.GetShareCondition()
+	})
+	return resp, nil
+}
+
+// noticeProto describes every library in `ni` in the same form as jsonnotice
+// -format=proto.
+func noticeProto(ni *compliance.NoticeIndex, req *compliance_proto.GetNoticeRequest) (*notice_proto.Notice, error) {
+	n := &notice_proto.Notice{}
+	if len(req.GetProduct()) > 0 {
+		n.Product = proto.String(req.GetProduct())
+	}
+	strip := func(installPath string) string {
+		return compliance.StripPrefix(installPath, req.StripPrefix, req.GetProduct())
+	}
+	texts := make(map[string][]byte)
+	for _, libName := range ni.AllLibraries() {
+		l := &notice_proto.Library{Name: proto.String(libName)}
+		kinds := make(map[string]struct{})
+		installPaths := make(map[string]struct{})
+		var conditions compliance.LicenseConditionSet
+		for _, h := range ni.LibHashes(libName) {
+			for _, kind := range ni.HashLibLicenseKinds(h, libName) {
+				kinds[kind] = struct{}{}
+			}
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				installPaths[strip(installPath)] = struct{}{}
+				conditions = conditions.Union(ni.InstallHashLibConditions(installPath, h, libName))
+			}
+			text, err := ni.HashText(h)
+			if err != nil {
+				return nil, err
+			}
+			hash := fmt.Sprintf("sha256:%x", sha256.Sum256(text))
+			l.TextHashes = append(l.TextHashes, hash)
+			texts[hash] = text
+		}
+		for kind := range kinds {
+			l.LicenseKinds = append(l.LicenseKinds, kind)
+		}
+		sort.Strings(l.LicenseKinds)
+		for installPath := range installPaths {
+			l.InstallPaths = append(l.InstallPaths, installPath)
+		}
+		sort.Strings(l.InstallPaths)
+		l.Conditions = conditions.Names()
+		sort.Strings(l.Conditions)
+
+		pms, err := ni.LibProjectMetadata(libName)
+		if err != nil {
+			return nil, err
+		}
+		for _, pm := range pms {
+			p := &notice_proto.Project{Project: proto.String(pm.Project())}
+			if len(pm.Name()) > 0 {
+				p.Name = proto.String(pm.Name())
+			}
+			if len(pm.Version()) > 0 {
+				p.Version = proto.String(pm.Version())
+			}
+			if url := pm.UrlsByTypeName().DownloadUrl(); len(url) > 0 {
+				p.Url = proto.String(url)
+			}
+			l.Projects = append(l.Projects, p)
+		}
+		n.Libraries = append(n.Libraries, l)
+	}
+
+	hashes := make([]string, 0, len(texts))
+	for h := range texts {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+	for _, h := range hashes {
+		t := &notice_proto.LicenseText{Hash: proto.String(h)}
+		if !req.GetNoTexts() {
+			t.Text = proto.String(string(texts[h]))
+		}
+		n.Texts = append(n.Texts, t)
+	}
+	return n, nil
+}
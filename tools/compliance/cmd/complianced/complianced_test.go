@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/compliance_proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// startServer serves a new server over a loopback connection and returns a
+// client for it.
+func startServer(t *testing.T) compliance_proto.ComplianceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	compliance_proto.RegisterComplianceServer(s, newServer(compliance.GetFS(""), &bytes.Buffer{}))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("cannot connect to test server: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return compliance_proto.NewComplianceClient(conn)
+}
+
+func TestBuildGraph(t *testing.T) {
+	client := startServer(t)
+	ctx := context.Background()
+
+	roots := []string{"testdata/restricted/highest.apex.meta_lic", "testdata/restricted/container.zip.meta_lic"}
+	lg, err := compliance.ReadLicenseGraph(compliance.GetFS(""), &bytes.Buffer{}, roots)
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+
+	first, err := client.BuildGraph(ctx, &compliance_proto.BuildGraphRequest{RootFiles: roots})
+	if err != nil {
+		t.Fatalf("BuildGraph: got error %s, want no error", err)
+	}
+	if first.GetCached() {
+		t.Errorf("BuildGraph: got cached graph on first request, want read")
+	}
+	if actual, expected := first.GetTargets(), int32(len(lg.Targets())); actual != expected {
+		t.Errorf("BuildGraph: got %d targets, want %d", actual, expected)
+	}
+	if actual, expected := first.GetEdges(), int32(len(lg.Edges())); actual != expected {
+		t.Errorf("BuildGraph: got %d edges, want %d", actual, expected)
+	}
+
+	// The same root set in another order with a duplicate hits the cache.
+	second, err := client.BuildGraph(ctx, &compliance_proto.BuildGraphRequest{RootFiles: []string{roots[1], roots[0], roots[1]}})
+	if err != nil {
+		t.Fatalf("BuildGraph: got error %s, want no error", err)
+	}
+	if !second.GetCached() {
+		t.Errorf("BuildGraph: got graph read again for the same root set, want cached")
+	}
+	if second.GetGraphId() != first.GetGraphId() {
+		t.Errorf("BuildGraph: got graph id %q for the same root set, want %q", second.GetGraphId(), first.GetGraphId())
+	}
+
+	// A different root set reads a new graph.
+	other, err := client.BuildGraph(ctx, &compliance_proto.BuildGraphRequest{RootFiles: roots[:1]})
+	if err != nil {
+		t.Fatalf("BuildGraph: got error %s, want no error", err)
+	}
+	if other.GetCached() || other.GetGraphId() == first.GetGraphId() {
+		t.Errorf("BuildGraph: got cached graph %q for a different root set, want new graph", other.GetGraphId())
+	}
+}
+
+func TestBuildGraphConcurrent(t *testing.T) {
+	client := startServer(t)
+
+	const n = 8
+	var wg sync.WaitGroup
+	reads := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.BuildGraph(context.Background(), &compliance_proto.BuildGraphRequest{RootFiles: []string{"testdata/notice/highest.apex.meta_lic"}})
+			if err != nil {
+				t.Errorf("BuildGraph: got error %s, want no error", err)
+				return
+			}
+			reads <- !resp.GetCached()
+		}()
+	}
+	wg.Wait()
+	close(reads)
+	count := 0
+	for read := range reads {
+		if read {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("BuildGraph: got %d reads for concurrent requests, want 1", count)
+	}
+}
+
+func TestGetNotice(t *testing.T) {
+	client := startServer(t)
+	ctx := context.Background()
+
+	roots := []string{"testdata/notice/highest.apex.meta_lic"}
+	resp, err := client.GetNotice(ctx, &compliance_proto.GetNoticeRequest{
+		RootFiles:   roots,
+		Product:     proto.String("highest"),
+		StripPrefix: []string{"out/target/product/fictional/"},
+	})
+	if err != nil {
+		t.Fatalf("GetNotice: got error %s, want no error", err)
+	}
+	n := resp.GetNotice()
+	if n.GetProduct() != "highest" {
+		t.Errorf("GetNotice: got product %q, want %q", n.GetProduct(), "highest")
+	}
+
+	lg, err := compliance.ReadLicenseGraph(compliance.GetFS(""), &bytes.Buffer{}, roots)
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := compliance.IndexLicenseTexts(compliance.GetFS(""), lg, compliance.ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	var libs []string
+	for _, l := range n.GetLibraries() {
+		libs = append(libs, l.GetName())
+		for _, installPath := range l.GetInstallPaths() {
+			if strings.HasPrefix(installPath, "out/") {
+				t.Errorf("GetNotice: got install path %q for %q, want prefix stripped", installPath, l.GetName())
+			}
+		}
+	}
+	if !reflect.DeepEqual(libs, ni.AllLibraries()) {
+		t.Errorf("GetNotice: got libraries %q, want %q", libs, ni.AllLibraries())
+	}
+	if len(n.GetTexts()) != len(ni.AllHashes()) {
+		t.Errorf("GetNotice: got %d texts, want %d", len(n.GetTexts()), len(ni.AllHashes()))
+	}
+	for _, text := range n.GetTexts() {
+		if len(text.GetText()) == 0 {
+			t.Errorf("GetNotice: got empty text for %s", text.GetHash())
+		}
+	}
+
+	resp, err = client.GetNotice(ctx, &compliance_proto.GetNoticeRequest{RootFiles: roots, NoTexts: proto.Bool(true)})
+	if err != nil {
+		t.Fatalf("GetNotice: got error %s, want no error", err)
+	}
+	for _, text := range resp.GetNotice().GetTexts() {
+		if text.Text != nil {
+			t.Errorf("GetNotice: got text for %s with no_texts, want hash only", text.GetHash())
+		}
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	tests := []struct {
+		roots    []string
+		expected []string
+	}{
+		{[]string{"testdata/notice/highest.apex.meta_lic"}, nil},
+		{[]string{"testdata/restricted/highest.apex.meta_lic"}, nil},
+		{
+			[]string{"testdata/proprietary/highest.apex.meta_lic"},
+			[]string{"testdata/proprietary/bin/bin2.meta_lic proprietary restricted"},
+		},
+		{
+			[]string{"testdata/proprietary/application.meta_lic"},
+			[]string{"testdata/proprietary/lib/liba.so.meta_lic proprietary restricted"},
+		},
+	}
+	client := startServer(t)
+	for _, tt := range tests {
+		t.Run(tt.roots[0], func(t *testing.T) {
+			resp, err := client.CheckPolicy(context.Background(), &compliance_proto.CheckPolicyRequest{RootFiles: tt.roots})
+			if err != nil {
+				t.Fatalf("CheckPolicy: got error %s, want no error", err)
+			}
+			var actual []string
+			for _, c := range resp.GetConflicts() {
+				actual = append(actual, c.GetTarget()+" "+c.GetPrivacyCondition()+" "+c.GetShareCondition())
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("CheckPolicy: got conflicts %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	client := startServer(t)
+	ctx := context.Background()
+
+	_, err := client.BuildGraph(ctx, &compliance_proto.BuildGraphRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("BuildGraph with no roots: got %v, want InvalidArgument", err)
+	}
+
+	missing := []string{"testdata/notice/missing.meta_lic"}
+	for i := 0; i < 2; i++ {
+		_, err = client.BuildGraph(ctx, &compliance_proto.BuildGraphRequest{RootFiles: missing})
+		if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "missing.meta_lic") {
+			t.Errorf("BuildGraph with missing root: got %v, want InvalidArgument naming the file", err)
+		}
+	}
+	_, err = client.GetNotice(ctx, &compliance_proto.GetNoticeRequest{RootFiles: missing})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetNotice with missing root: got %v, want InvalidArgument", err)
+	}
+	_, err = client.CheckPolicy(ctx, &compliance_proto.CheckPolicyRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CheckPolicy with no roots: got %v, want InvalidArgument", err)
+	}
+}
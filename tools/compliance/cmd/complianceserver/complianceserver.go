@@ -0,0 +1,446 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+// maxRequestBytes limits the size of a request body.
+const maxRequestBytes = 1 << 20
+
+// server implements the REST API for the license metadata files under rootFS.
+type server struct {
+	rootFS fs.FS
+	// graphs caches the license graphs by root set.
+	graphs *compliance.LicenseGraphCache
+}
+
+func newServer(rootFS fs.FS, stderr io.Writer) *server {
+	return &server{rootFS: rootFS, graphs: compliance.NewLicenseGraphCache(rootFS, stderr)}
+}
+
+// noticeRequest is the body of a POST /notice request.
+type noticeRequest struct {
+	// Roots lists the root license metadata files.
+	Roots []string `json:"roots"`
+	// Format selects the notice format: "text" (default), "json" or "html".
+	Format string `json:"format"`
+	// Product names the product for the notice if any.
+	Product string `json:"product"`
+	// StripPrefix lists prefixes to remove from install paths.
+	StripPrefix []string `json:"stripPrefix"`
+}
+
+// checkRequest is the body of a POST /check request.
+type checkRequest struct {
+	// Roots lists the root license metadata files.
+	Roots []string `json:"roots"`
+}
+
+// checkResponse is the body of the response to a POST /check request.
+type checkResponse struct {
+	// Pass is true when there are no conflicts.
+	Pass      bool       `json:"pass"`
+	Conflicts []conflict `json:"conflicts"`
+}
+
+// conflict describes a target where policy says that the source both must
+// and must not be shared.
+type conflict struct {
+	Target           string `json:"target"`
+	PrivacyCondition string `json:"privacyCondition"`
+	ShareCondition   string `json:"shareCondition"`
+}
+
+// library describes a library in a json notice.
+type library struct {
+	Name         string    `json:"name"`
+	LicenseKinds []string  `json:"licenseKinds"`
+	Conditions   []string  `json:"conditions"`
+	InstallPaths []string  `json:"installPaths"`
+	Licenses     []license `json:"licenses"`
+}
+
+// license describes a license text of a library in a json notice.
+type license struct {
+	// Hash is "sha256:" followed by the hex digest of Text.
+	Hash string `json:"hash"`
+	Text string `json:"text"`
+}
+
+// httpError is an error with the HTTP status code to report it.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func (e *httpError) Unwrap() error { return e.err }
+
+// statusError returns an httpError reporting `err` with `status`.
+func statusError(status int, format string, args ...interface{}) error {
+	return &httpError{status, fmt.Errorf(format, args...)}
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options}
+
+Serves an HTTP REST API answering license queries for the license metadata
+files under the current directory.
+
+  POST /notice {"roots": [...], "format": "text|json|html"}
+      returns the notice for the root files. Also accepts "product" and
+      "stripPrefix".
+  POST /check {"roots": [...]}
+      returns {"pass": bool, "conflicts": [...]} listing the targets where
+      policy says that the source both must and must not be shared.
+
+Requests must have Content-Type application/json. Responds 200 on success,
+422 for malformed requests and 500 for internal errors.
+
+Reads each distinct set of root files once and answers later requests for the
+same set from memory. Restart the server after the license metadata changes.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	listen := flags.String("listen", "localhost:8791", "The address on which to listen.")
+
+	flags.Parse(expandedArgs)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	fmt.Fprintf(os.Stderr, "serving on %s\n", *listen)
+	if err := http.ListenAndServe(*listen, newServer(compliance.FS, os.Stderr).handler()); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// handler returns the handler serving the REST API.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/notice", s.handle(s.notice))
+	mux.HandleFunc("/check", s.handle(s.check))
+	return mux
+}
+
+// handle returns a handler accepting only POST requests with a json body
+// that calls `fn` with the body and writes its result or error.
+//
+// `fn` returns the content type and content of a successful response.
+func (s *server) handle(fn func(body []byte) (string, []byte, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, statusError(http.StatusMethodNotAllowed, "method %s not allowed", r.Method))
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			writeError(w, statusError(http.StatusUnsupportedMediaType, "Content-Type must be application/json, got %q", r.Header.Get("Content-Type")))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		if err != nil {
+			writeError(w, statusError(http.StatusRequestEntityTooLarge, "cannot read request: %v", err))
+			return
+		}
+		contentType, content, err := fn(body)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	}
+}
+
+// writeError responds with the status of `err`, or 500 unless an httpError,
+// and a json body describing the error.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var he *httpError
+	if errors.As(err, &he) {
+		status = he.status
+	}
+	data, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+// decode parses the json request `body` into `v` rejecting unknown fields.
+func decode(body []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return statusError(http.StatusUnprocessableEntity, "malformed request: %v", err)
+	}
+	if dec.More() {
+		return statusError(http.StatusUnprocessableEntity, "malformed request: trailing data")
+	}
+	return nil
+}
+
+// graph returns the license graph rooted at `roots` from the cache.
+func (s *server) graph(roots []string) (*compliance.LicenseGraph, error) {
+	if len(roots) < 1 {
+		return nil, statusError(http.StatusUnprocessableEntity, "no roots requested")
+	}
+	_, lg, _, err := s.graphs.Get(roots)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, statusError(http.StatusUnprocessableEntity, "unable to read license metadata file(s) %q: %v", roots, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read license metadata file(s) %q: %v", roots, err)
+	}
+	return lg, nil
+}
+
+// notice implements POST /notice.
+func (s *server) notice(body []byte) (string, []byte, error) {
+	var req noticeRequest
+	if err := decode(body, &req); err != nil {
+		return "", nil, err
+	}
+	switch req.Format {
+	case "", "text", "json", "html":
+	default:
+		return "", nil, statusError(http.StatusUnprocessableEntity, "format must be text, json or html, got %q", req.Format)
+	}
+	lg, err := s.graph(req.Roots)
+	if err != nil {
+		return "", nil, err
+	}
+	ni, err := compliance.IndexLicenseTexts(s.rootFS, lg, compliance.ResolveNotices(lg))
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read license text file(s) for %q: %v", req.Roots, err)
+	}
+	strip := func(installPath string) string {
+		return compliance.StripPrefix(installPath, req.StripPrefix, req.Product)
+	}
+
+	var buf bytes.Buffer
+	switch req.Format {
+	case "json":
+		libs, err := jsonNotice(ni, strip)
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := json.MarshalIndent(libs, "", "  ")
+		if err != nil {
+			return "", nil, err
+		}
+		buf.Write(data)
+		buf.WriteString("\n")
+		return "application/json", buf.Bytes(), nil
+	case "html":
+		if err := writeHTML(&buf, ni, req.Product, strip); err != nil {
+			return "", nil, err
+		}
+		return "text/html; charset=utf-8", buf.Bytes(), nil
+	default:
+		if err := writeText(&buf, ni, strip); err != nil {
+			return "", nil, err
+		}
+		return "text/plain; charset=utf-8", buf.Bytes(), nil
+	}
+}
+
+// check implements POST /check.
+func (s *server) check(body []byte) (string, []byte, error) {
+	var req checkRequest
+	if err := decode(body, &req); err != nil {
+		return "", nil, err
+	}
+	lg, err := s.graph(req.Roots)
+	if err != nil {
+		return "", nil, err
+	}
+	resp := checkResponse{Conflicts: []conflict{}}
+	for _, c := range compliance.ConflictingSharedPrivateSource(lg) {
+		resp.Conflicts = append(resp.Conflicts, conflict{c.SourceNode.Name(), c.PrivacyCondition.Name(), c.ShareCondition.Name()})
+	}
+	sort.Slice(resp.Conflicts, func(i, j int) bool {
+		ci, cj := resp.Conflicts[i], resp.Conflicts[j]
+		if ci.Target != cj.Target {
+			return ci.Target < cj.Target
+		}
+		if ci.PrivacyCondition != cj.PrivacyCondition {
+			return ci.PrivacyCondition < cj.PrivacyCondition
+		}
+		return ci.ShareCondition < cj.ShareCondition
+	})
+	resp.Pass = len(resp.Conflicts) == 0
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+	return "application/json", append(data, '\n'), nil
+}
+
+// writeText writes the notice for `ni` as plain text in the same form as
+// textnotice: the libraries and install paths using each license text
+// followed by the text.
+func writeText(w io.Writer, ni *compliance.NoticeIndex, strip func(string) string) error {
+	for _, h := range ni.AllHashes() {
+		text, err := ni.HashText(h)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "==============================================================================")
+		for _, libName := range ni.HashLibs(h) {
+			fmt.Fprintf(w, "%s used by:\n", libName)
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				fmt.Fprintf(w, "  %s\n", strip(installPath))
+			}
+			fmt.Fprintln(w)
+		}
+		w.Write(text)
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// writeHTML writes the notice for `ni` as an html document listing the
+// libraries and install paths using each license text followed by the text.
+func writeHTML(w io.Writer, ni *compliance.NoticeIndex, product string, strip func(string) string) error {
+	fmt.Fprintln(w, "<!DOCTYPE html>")
+	fmt.Fprintln(w, "<html><head>")
+	fmt.Fprintln(w, "<style type=\"text/css\">")
+	fmt.Fprintln(w, "body { padding: 2px; margin: 0; }")
+	fmt.Fprintln(w, "ul { list-style-type: none; margin: 0; padding: 0; }")
+	fmt.Fprintln(w, "li { padding-left: 1em; }")
+	fmt.Fprintln(w, ".file-list { margin-left: 1em; }")
+	fmt.Fprintln(w, "</style>")
+	if len(product) > 0 {
+		fmt.Fprintf(w, "<title>%s</title>\n", html.EscapeString(product))
+	}
+	fmt.Fprintln(w, "</head>")
+	fmt.Fprintln(w, "<body>")
+	if len(product) > 0 {
+		fmt.Fprintf(w, "  <h1>%s</h1>\n", html.EscapeString(product))
+	}
+	for _, h := range ni.AllHashes() {
+		text, err := ni.HashText(h)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  <div id=\"%s\">\n", h.String())
+		for _, libName := range ni.HashLibs(h) {
+			fmt.Fprintf(w, "    <strong>%s</strong> used by:\n    <ul class=\"file-list\">\n", html.EscapeString(libName))
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				fmt.Fprintf(w, "      <li>%s\n", html.EscapeString(strip(installPath)))
+			}
+			fmt.Fprintln(w, "    </ul>")
+		}
+		fmt.Fprintf(w, "    <pre class=\"license-text\">%s</pre>\n", html.EscapeString(string(text)))
+		fmt.Fprintln(w, "  </div>")
+	}
+	fmt.Fprintln(w, "</body></html>")
+	return nil
+}
+
+// jsonNotice describes every library in `ni` in the same form as jsonnotice
+// without the project metadata.
+func jsonNotice(ni *compliance.NoticeIndex, strip func(string) string) ([]library, error) {
+	libs := []library{}
+	for _, libName := range ni.AllLibraries() {
+		lib := library{Name: libName, LicenseKinds: []string{}, Conditions: []string{}, InstallPaths: []string{}}
+		kinds := make(map[string]struct{})
+		installPaths := make(map[string]struct{})
+		var conditions compliance.LicenseConditionSet
+		for _, h := range ni.LibHashes(libName) {
+			for _, kind := range ni.HashLibLicenseKinds(h, libName) {
+				kinds[kind] = struct{}{}
+			}
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				installPaths[strip(installPath)] = struct{}{}
+				conditions = conditions.Union(ni.InstallHashLibConditions(installPath, h, libName))
+			}
+			text, err := ni.HashText(h)
+			if err != nil {
+				return nil, err
+			}
+			lib.Licenses = append(lib.Licenses, license{fmt.Sprintf("sha256:%x", sha256.Sum256(text)), string(text)})
+		}
+		for kind := range kinds {
+			lib.LicenseKinds = append(lib.LicenseKinds, kind)
+		}
+		sort.Strings(lib.LicenseKinds)
+		for installPath := range installPaths {
+			lib.InstallPaths = append(lib.InstallPaths, installPath)
+		}
+		sort.Strings(lib.InstallPaths)
+		lib.Conditions = append(lib.Conditions, conditions.Names()...)
+		sort.Strings(lib.Conditions)
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
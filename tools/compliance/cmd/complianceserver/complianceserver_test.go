@@ -0,0 +1,234 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// post sends `body` to `path` on `ts` with `contentType` and returns the
+// status, content type and body of the response.
+func post(t *testing.T, ts *httptest.Server, path, contentType, body string) (int, string, string) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+path, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: got error %s, want no error", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("POST %s: cannot read response: %s", path, err)
+	}
+	return resp.StatusCode, resp.Header.Get("Content-Type"), string(data)
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(newServer(compliance.GetFS(""), &bytes.Buffer{}).handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestNotice(t *testing.T) {
+	ts := newTestServer(t)
+	roots := `["testdata/notice/highest.apex.meta_lic"]`
+	strip := `"stripPrefix": ["out/target/product/fictional/"]`
+
+	tests := []struct {
+		format              string
+		expectedContentType string
+		expectedContains    []string
+	}{
+		{"", "text/plain; charset=utf-8", []string{"==========", "Android used by:\n  system/apex/highest.apex\n"}},
+		{"text", "text/plain; charset=utf-8", []string{"==========", "Device used by:\n"}},
+		{"html", "text/html; charset=utf-8", []string{"<!DOCTYPE html>", "<h1>highest</h1>", "<li>system/apex/highest.apex\n", "<pre class=\"license-text\">"}},
+		{"json", "application/json", []string{"\"name\": \"Android\"", "\"installPaths\": [", "\"system/apex/highest.apex\""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			body := fmt.Sprintf(`{"roots": %s, "format": %q, "product": "highest", %s}`, roots, tt.format, strip)
+			status, contentType, content := post(t, ts, "/notice", "application/json", body)
+			if status != http.StatusOK {
+				t.Fatalf("POST /notice: got status %d, want 200; body %s", status, content)
+			}
+			if contentType != tt.expectedContentType {
+				t.Errorf("POST /notice: got Content-Type %q, want %q", contentType, tt.expectedContentType)
+			}
+			for _, s := range tt.expectedContains {
+				if !strings.Contains(content, s) {
+					t.Errorf("POST /notice: got body missing %q:\n%s", s, content)
+				}
+			}
+			if strings.Contains(content, "out/target/product/fictional/") {
+				t.Errorf("POST /notice: got unstripped install paths:\n%s", content)
+			}
+		})
+	}
+
+	_, _, content := post(t, ts, "/notice", "application/json", `{"roots": `+roots+`, "format": "json"}`)
+	var libs []library
+	if err := json.Unmarshal([]byte(content), &libs); err != nil {
+		t.Fatalf("POST /notice json: cannot parse %q: %s", content, err)
+	}
+	for _, lib := range libs {
+		if len(lib.Licenses) == 0 || len(lib.Licenses[0].Text) == 0 {
+			t.Errorf("POST /notice json: got no license text for %q", lib.Name)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ts := newTestServer(t)
+	tests := []struct {
+		roots    string
+		expected checkResponse
+	}{
+		{`["testdata/restricted/highest.apex.meta_lic"]`, checkResponse{true, []conflict{}}},
+		{`["testdata/proprietary/highest.apex.meta_lic"]`, checkResponse{false, []conflict{
+			{"testdata/proprietary/bin/bin2.meta_lic", "proprietary", "restricted"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.roots, func(t *testing.T) {
+			status, contentType, content := post(t, ts, "/check", "application/json; charset=utf-8", `{"roots": `+tt.roots+`}`)
+			if status != http.StatusOK {
+				t.Fatalf("POST /check: got status %d, want 200; body %s", status, content)
+			}
+			if contentType != "application/json" {
+				t.Errorf("POST /check: got Content-Type %q, want application/json", contentType)
+			}
+			var actual checkResponse
+			if err := json.Unmarshal([]byte(content), &actual); err != nil {
+				t.Fatalf("POST /check: cannot parse %q: %s", content, err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("POST /check: got %+v, want %+v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ts := newTestServer(t)
+	tests := []struct {
+		name           string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"wrong content type", "/notice", "text/plain", `{"roots": ["a.meta_lic"]}`, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"no content type", "/check", "", `{"roots": ["a.meta_lic"]}`, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"malformed json", "/notice", "application/json", `{"roots": [`, http.StatusUnprocessableEntity, "malformed request"},
+		{"unknown field", "/check", "application/json", `{"root": ["a.meta_lic"]}`, http.StatusUnprocessableEntity, "malformed request"},
+		{"trailing data", "/check", "application/json", `{"roots": ["a.meta_lic"]} {}`, http.StatusUnprocessableEntity, "trailing data"},
+		{"no roots", "/notice", "application/json", `{"format": "text"}`, http.StatusUnprocessableEntity, "no roots"},
+		{"bad format", "/notice", "application/json", `{"roots": ["a.meta_lic"], "format": "pdf"}`, http.StatusUnprocessableEntity, "format must be text, json or html"},
+		{"missing root", "/check", "application/json", `{"roots": ["testdata/notice/missing.meta_lic"]}`, http.StatusUnprocessableEntity, "missing.meta_lic"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, contentType, content := post(t, ts, tt.path, tt.contentType, tt.body)
+			if status != tt.expectedStatus {
+				t.Errorf("POST %s: got status %d, want %d; body %s", tt.path, status, tt.expectedStatus, content)
+			}
+			if contentType != "application/json" {
+				t.Errorf("POST %s: got Content-Type %q, want application/json", tt.path, contentType)
+			}
+			var e struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(content), &e); err != nil || !strings.Contains(e.Error, tt.expectedError) {
+				t.Errorf("POST %s: got body %q, want error containing %q", tt.path, content, tt.expectedError)
+			}
+		})
+	}
+
+	resp, err := http.Get(ts.URL + "/notice")
+	if err != nil {
+		t.Fatalf("GET /notice: got error %s, want no error", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET /notice: got status %d Allow %q, want 405 Allow POST", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
+
+func TestInternalError(t *testing.T) {
+	ts := httptest.NewServer(newServer(&testfs.TestFS{"bad.meta_lic": []byte("package_name: {")}, &bytes.Buffer{}).handler())
+	defer ts.Close()
+	status, _, content := post(t, ts, "/check", "application/json", `{"roots": ["bad.meta_lic"]}`)
+	if status != http.StatusInternalServerError || !strings.Contains(content, "bad.meta_lic") {
+		t.Errorf("POST /check: got status %d body %s, want 500 naming bad.meta_lic", status, content)
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	ts := newTestServer(t)
+	bodies := []string{
+		`{"roots": ["testdata/notice/highest.apex.meta_lic"], "format": "text"}`,
+		`{"roots": ["testdata/notice/highest.apex.meta_lic"], "format": "json"}`,
+		`{"roots": ["testdata/restricted/container.zip.meta_lic"], "format": "html"}`,
+		`{"roots": ["testdata/reciprocal/application.meta_lic"]}`,
+	}
+	expected := make([]string, len(bodies))
+	for i, body := range bodies {
+		_, _, expected[i] = post(t, ts, "/notice", "application/json", body)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		for i, body := range bodies {
+			wg.Add(1)
+			go func(i int, body string) {
+				defer wg.Done()
+				resp, err := http.Post(ts.URL+"/notice", "application/json", strings.NewReader(body))
+				if err != nil {
+					t.Errorf("POST /notice: got error %s, want no error", err)
+					return
+				}
+				defer resp.Body.Close()
+				data, _ := io.ReadAll(resp.Body)
+				if resp.StatusCode != http.StatusOK || string(data) != expected[i] {
+					t.Errorf("POST /notice %s: got status %d and different output for concurrent request", body, resp.StatusCode)
+				}
+			}(i, body)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,285 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	rootFS      fs.FS
+	product     string
+	stripPrefix []string
+	// top limits the projects listed per condition.
+	top int
+	// format selects the output format: "text", "json" or "csv".
+	format string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// conditionStat summarizes the shipped targets with a license condition.
+type conditionStat struct {
+	Condition    string         `json:"condition"`
+	Targets      int            `json:"targets"`
+	InstallPaths int            `json:"installPaths"`
+	TopProjects  []projectCount `json:"topProjects"`
+}
+
+// projectCount counts the targets of a project with a license condition.
+type projectCount struct {
+	Project string `json:"project"`
+	Targets int    `json:"targets"`
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs, for each license condition that applies to a shipped target after
+resolving the license graph, the number of targets, the number of install
+paths, and the projects contributing the most targets.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the statistics. (default stdout)")
+	product := flags.String("product", "", "The name of the product for which the statistics are generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	top := flags.Int("top", 5, "The number of projects to list per condition.")
+	asJSON := flags.Bool("json", false, "Whether to output json.")
+	asCSV := flags.Bool("csv", false, "Whether to output csv.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if *asJSON && *asCSV {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "cannot specify both -json and -csv\n")
+		os.Exit(2)
+	}
+
+	if *top < 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-top must not be negative\n")
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	format := "text"
+	if *asJSON {
+		format = "json"
+	} else if *asCSV {
+		format = "csv"
+	}
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, *top, format}
+
+	err := conditionStats(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// conditionStats implements the conditionstats utility.
+func conditionStats(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+
+	stats := gatherStats(ctx, licenseGraph)
+
+	switch ctx.format {
+	case "json":
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Unable to write json statistics: %v\n", err)
+		}
+		fmt.Fprintln(ctx.stdout, string(data))
+	case "csv":
+		w := csv.NewWriter(ctx.stdout)
+		w.Write([]string{"condition", "targets", "install_paths", "top_projects"})
+		for _, s := range stats {
+			w.Write([]string{s.Condition, strconv.Itoa(s.Targets), strconv.Itoa(s.InstallPaths), projectList(s.TopProjects)})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("Unable to write csv statistics: %v\n", err)
+		}
+	default:
+		w := tabwriter.NewWriter(ctx.stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "CONDITION\tTARGETS\tINSTALL_PATHS\tTOP_PROJECTS")
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.Condition, s.Targets, s.InstallPaths, projectList(s.TopProjects))
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+// gatherStats resolves the conditions of the shipped targets in `lg` and
+// returns the statistics for each condition applying to any target in
+// condition order.
+func gatherStats(ctx *context, lg *compliance.LicenseGraph) []conditionStat {
+	compliance.ResolveTopDownConditions(lg)
+	actions := compliance.WalkResolutionsForCondition(lg, compliance.AllLicenseConditions).AllActions()
+
+	stats := []conditionStat{}
+	for _, lc := range compliance.AllLicenseConditions.AsList() {
+		targets := 0
+		installPaths := make(map[string]struct{})
+		projects := make(map[string]int)
+		for tn, cs := range actions {
+			if !cs.HasAny(lc) {
+				continue
+			}
+			targets++
+			for _, installPath := range tn.Installed() {
+				installPaths[ctx.strip(installPath)] = struct{}{}
+			}
+			for _, p := range tn.Projects() {
+				projects[p]++
+			}
+		}
+		if targets == 0 {
+			continue
+		}
+
+		s := conditionStat{lc.Name(), targets, len(installPaths), []projectCount{}}
+		for p, n := range projects {
+			s.TopProjects = append(s.TopProjects, projectCount{p, n})
+		}
+		sort.Slice(s.TopProjects, func(i, j int) bool {
+			if s.TopProjects[i].Targets != s.TopProjects[j].Targets {
+				return s.TopProjects[i].Targets > s.TopProjects[j].Targets
+			}
+			return s.TopProjects[i].Project < s.TopProjects[j].Project
+		})
+		if len(s.TopProjects) > ctx.top {
+			s.TopProjects = s.TopProjects[:ctx.top]
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// projectList returns `projects` as "project (count)" joined by ", ".
+func projectList(projects []projectCount) string {
+	var parts []string
+	for _, p := range projects {
+		parts = append(parts, fmt.Sprintf("%s (%d)", p.Project, p.Targets))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// count summarizes a conditionStat without its projects.
+type count struct {
+	condition    string
+	targets      int
+	installPaths int
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition string
+		roots     []string
+		expected  []count
+	}{
+		{
+			condition: "firstparty",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected:  []count{{"notice", 6, 5}},
+		},
+		{
+			condition: "notice",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected:  []count{{"notice", 6, 5}},
+		},
+		{
+			condition: "reciprocal",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []count{
+				{"notice", 4, 4},
+				{"reciprocal", 2, 1},
+			},
+		},
+		{
+			condition: "restricted",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []count{
+				{"notice", 3, 3},
+				{"reciprocal", 1, 0},
+				{"restricted", 3, 3},
+				{"restricted_if_statically_linked", 4, 3},
+			},
+		},
+		{
+			condition: "restricted",
+			roots:     []string{"container.zip.meta_lic", "application.meta_lic"},
+			expected: []count{
+				{"notice", 4, 4},
+				{"reciprocal", 1, 0},
+				{"restricted", 5, 5},
+				{"restricted_if_statically_linked", 5, 4},
+			},
+		},
+		{
+			condition: "proprietary",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []count{
+				{"notice", 2, 2},
+				{"restricted", 3, 3},
+				{"proprietary", 3, 2},
+				{"by_exception_only", 3, 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+strings.Join(tt.roots, " "), func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			rootFiles := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+			}
+
+			ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, 10, "json"}
+			if err := conditionStats(&ctx, rootFiles...); err != nil {
+				t.Fatalf("conditionstats: error = %v, stderr = %v", err, stderr)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("conditionstats: gotStderr = %v, want none", stderr)
+			}
+
+			var stats []conditionStat
+			if err := json.Unmarshal(stdout.Bytes(), &stats); err != nil {
+				t.Fatalf("conditionstats: cannot parse output %q: %v", stdout.String(), err)
+			}
+			actual := []count{}
+			for _, s := range stats {
+				actual = append(actual, count{s.Condition, s.Targets, s.InstallPaths})
+				total := 0
+				for _, p := range s.TopProjects {
+					total += p.Targets
+				}
+				if total != s.Targets {
+					t.Errorf("conditionstats: %s projects count %d targets, want %d", s.Condition, total, s.Targets)
+				}
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("conditionstats: got %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		top      int
+		expected []string
+	}{
+		{
+			name:   "text",
+			format: "text",
+			top:    2,
+			expected: []string{
+				"CONDITION                        TARGETS  INSTALL_PATHS  TOP_PROJECTS",
+				"notice                           3        3              dynamic/binary (1), highest/apex (1)",
+				"reciprocal                       1        0              static/library (1)",
+				"restricted                       3        3              base/library (1), dynamic/binary (1)",
+				"restricted_if_statically_linked  4        3              device/library (1), highest/apex (1)",
+			},
+		},
+		{
+			name:   "csv",
+			format: "csv",
+			top:    1,
+			expected: []string{
+				"condition,targets,install_paths,top_projects",
+				"notice,3,3,dynamic/binary (1)",
+				"reciprocal,1,0,static/library (1)",
+				"restricted,3,3,base/library (1)",
+				"restricted_if_statically_linked,4,3,device/library (1)",
+			},
+		},
+		{
+			name:   "csv no projects",
+			format: "csv",
+			top:    0,
+			expected: []string{
+				"condition,targets,install_paths,top_projects",
+				"notice,3,3,",
+				"reciprocal,1,0,",
+				"restricted,3,3,",
+				"restricted_if_statically_linked,4,3,",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			ctx := context{stdout, stderr, compliance.GetFS(""), "", nil, tt.top, tt.format}
+			if err := conditionStats(&ctx, "testdata/restricted/highest.apex.meta_lic"); err != nil {
+				t.Fatalf("conditionstats: error = %v, stderr = %v", err, stderr)
+			}
+			actual := strings.Split(strings.TrimRight(stdout.String(), "\n"), "\n")
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("conditionstats: got:\n%s\nwant:\n%s", strings.Join(actual, "\n"), strings.Join(tt.expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := context{stdout, stderr, compliance.GetFS(""), "", nil, 5, "text"}
+	if err := conditionStats(&ctx); err != failNoneRequested {
+		t.Errorf("conditionstats: got error %v, want %v", err, failNoneRequested)
+	}
+	if err := conditionStats(&ctx, "testdata/notice/missing.meta_lic"); err == nil {
+		t.Errorf("conditionstats: got no error for missing file, want error")
+	}
+}
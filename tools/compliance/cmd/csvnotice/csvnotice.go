@@ -0,0 +1,390 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"crypto/sha256"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+
+	"github.com/google/blueprint/deptools"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+// header names the columns of the output.
+var header = []string{"library", "conditions", "license_kinds", "text_hashes", "install_path", "project_url", "project_version"}
+
+type context struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	rootFS      fs.FS
+	product     string
+	stripPrefix []string
+	// tsv separates the columns with tabs instead of commas.
+	tsv bool
+	// overrides replace the license conditions of targets per -overrides_file.
+	overrides []compliance.Override
+	// delta applies -baseline and -write_baseline to the notice index.
+	delta *compliance.NoticeDelta
+	// missing applies -report_missing and -missing_fatal to the notice index.
+	missing *compliance.MissingTextReport
+	// emptyText selects how to index empty license texts per -empty_text.
+	emptyText compliance.EmptyTextMode
+	// keepGoing reports every malformed license metadata file per -keep_going.
+	keepGoing bool
+	deps      *[]string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs a CSV file with a header row and one row per library and install path
+listing the license conditions, license kinds, license text hashes and the
+project URL and version from METADATA when available.
+
+Lists of values within a column are separated by spaces.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the CSV file. (default stdout)")
+	depsFile := flags.String("d", "", "Where to write the deps file")
+	product := flags.String("product", "", "The name of the product for which the notice is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	baseline := flags.String("baseline", "", "A baseline of license text hashes per library from a prior release. Only libraries with new or changed license texts appear in the notice, and removed libraries are listed on stderr.")
+	writeBaseline := flags.String("write_baseline", "", "Where to write the baseline of license text hashes per library for use with -baseline in the next release.")
+	reportMissing := flags.String("report_missing", "", "Where to write the shipped targets contributing no license text to the notice with their license conditions and install paths.")
+	missingFatal := flags.Bool("missing_fatal", false, "Whether to fail when any shipped target contributes no license text to the notice.")
+	emptyTextName := flags.String("empty_text", "keep", "How to treat license text files that are empty or only whitespace after warning about them: skip, placeholder or keep.")
+	keepGoing := flags.Bool("keep_going", false, "Report every malformed license metadata file, up to 100, instead of stopping at the first.")
+	zipFile := flags.String("zip", "", "Read the license metadata, license texts and METADATA files from this zip archive, or from a directory in it given as archive.zip!/dir.")
+	overridesFile := flags.String("overrides_file", "", "A YAML file of {path, condition} entries replacing the license conditions declared by the named license metadata files.")
+	tsv := flags.Bool("tsv", false, "Whether to separate the columns with tabs instead of commas.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	var deps []string
+
+	var overrides []compliance.Override
+	if len(*overridesFile) > 0 {
+		var err error
+		overrides, err = compliance.ReadOverridesFile(*overridesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read overrides file %q: %s\n", *overridesFile, err)
+			os.Exit(1)
+		}
+	}
+
+	emptyText, err := compliance.ParseEmptyTextMode(*emptyTextName)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	var rootFS fs.FS = compliance.FS
+	var zipFS *compliance.ZipFS
+	if len(*zipFile) > 0 {
+		zipFS, err = compliance.OpenZipFS(*zipFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *zipFile, err)
+			os.Exit(1)
+		}
+		rootFS = zipFS
+	}
+
+	ctx := &context{
+		stdout:      ofile,
+		stderr:      os.Stderr,
+		rootFS:      rootFS,
+		product:     *product,
+		stripPrefix: *stripPrefix,
+		tsv:         *tsv,
+		overrides:   overrides,
+		delta:       &compliance.NoticeDelta{Baseline: *baseline, WriteBaseline: *writeBaseline},
+		missing:     &compliance.MissingTextReport{File: *reportMissing, Fatal: *missingFatal},
+		emptyText:   emptyText,
+		keepGoing:   *keepGoing,
+		deps:        &deps,
+	}
+
+	err = csvNotice(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	if *depsFile != "" {
+		if zipFS != nil {
+			// The files read are inside the archive.
+			deps = []string{zipFS.Archive()}
+		}
+		if len(*baseline) > 0 {
+			deps = append(deps, *baseline)
+		}
+		if len(*overridesFile) > 0 {
+			deps = append(deps, *overridesFile)
+		}
+		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// csvNotice implements the csvnotice utility.
+func csvNotice(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraphWithOptions(gocontext.Background(), rootFS, ctx.stderr, files, compliance.ReadOptions{KeepGoing: ctx.keepGoing})
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+	if len(ctx.overrides) > 0 {
+		licenseGraph = compliance.ApplyOverrides(licenseGraph, ctx.overrides)
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTextsWithOptions(gocontext.Background(), rootFS, licenseGraph, rs, compliance.IndexOptions{EmptyText: ctx.emptyText})
+	if err != nil {
+		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
+	}
+	compliance.WarnEmptyTexts(ctx.stderr, ni)
+	if err := ctx.delta.Apply(ni, ctx.stderr); err != nil {
+		return err
+	}
+	if err := ctx.missing.Apply(ni); err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(ctx.stdout)
+	if ctx.tsv {
+		w.Comma = '\t'
+	}
+	w.Write(header)
+	for _, libName := range ni.AllLibraries() {
+		rows, err := libraryRows(ctx, ni, libName)
+		if err != nil {
+			return fmt.Errorf("Unable to read project metadata for %q: %v\n", libName, err)
+		}
+		for _, row := range rows {
+			w.Write(row)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Unable to write csv: %v\n", err)
+	}
+
+	*ctx.deps = rootFS.Files()
+
+	return nil
+}
+
+// libraryRows returns the rows for library `libName` in `ni` ordered by
+// install path.
+func libraryRows(ctx *context, ni *compliance.NoticeIndex, libName string) ([][]string, error) {
+	pms, err := ni.LibProjectMetadata(libName)
+	if err != nil {
+		return nil, err
+	}
+	var urls, versions []string
+	for _, pm := range pms {
+		if url := pm.UrlsByTypeName().DownloadUrl(); len(url) > 0 {
+			urls = append(urls, url)
+		}
+		if version := pm.Version(); len(version) > 0 {
+			versions = append(versions, version)
+		}
+	}
+
+	// installs maps the stripped install paths to the license text hashes,
+	// conditions and kinds applying to the library there.
+	type install struct {
+		hashes     map[string]struct{}
+		conditions compliance.LicenseConditionSet
+		kinds      map[string]struct{}
+	}
+	installs := make(map[string]*install)
+	for _, h := range ni.LibHashes(libName) {
+		text, err := ni.HashText(h)
+		if err != nil {
+			return nil, err
+		}
+		textHash := fmt.Sprintf("sha256:%x", sha256.Sum256(text))
+		kinds := ni.HashLibLicenseKinds(h, libName)
+		for _, installPath := range ni.HashLibInstalls(h, libName) {
+			stripped := ctx.strip(installPath)
+			in, ok := installs[stripped]
+			if !ok {
+				in = &install{hashes: make(map[string]struct{}), kinds: make(map[string]struct{})}
+				installs[stripped] = in
+			}
+			in.hashes[textHash] = struct{}{}
+			in.conditions = in.conditions.Union(ni.InstallHashLibConditions(installPath, h, libName))
+			for _, kind := range kinds {
+				in.kinds[kind] = struct{}{}
+			}
+		}
+	}
+
+	installPaths := make([]string, 0, len(installs))
+	for installPath := range installs {
+		installPaths = append(installPaths, installPath)
+	}
+	sort.Strings(installPaths)
+
+	rows := make([][]string, 0, len(installPaths))
+	for _, installPath := range installPaths {
+		in := installs[installPath]
+		conditions := in.conditions.Names()
+		sort.Strings(conditions)
+		rows = append(rows, []string{
+			libName,
+			strings.Join(conditions, " "),
+			strings.Join(sortedKeys(in.kinds), " "),
+			strings.Join(sortedKeys(in.hashes), " "),
+			installPath,
+			strings.Join(urls, " "),
+			strings.Join(versions, " "),
+		})
+	}
+	return rows, nil
+}
+
+// sortedKeys returns the keys of `m` in order.
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition    string
+		root         string
+		expectedRows int
+	}{
+		{"firstparty", "highest.apex.meta_lic", 5},
+		{"firstparty", "container.zip.meta_lic", 5},
+		{"firstparty", "application.meta_lic", 1},
+		{"firstparty", "bin/bin1.meta_lic", 1},
+		{"firstparty", "bin/bin2.meta_lic", 1},
+		{"firstparty", "lib/liba.so.meta_lic", 1},
+		{"notice", "highest.apex.meta_lic", 7},
+		{"notice", "container.zip.meta_lic", 7},
+		{"notice", "application.meta_lic", 2},
+		{"notice", "bin/bin1.meta_lic", 3},
+		{"notice", "bin/bin2.meta_lic", 1},
+		{"notice", "lib/liba.so.meta_lic", 1},
+		{"reciprocal", "highest.apex.meta_lic", 7},
+		{"reciprocal", "container.zip.meta_lic", 7},
+		{"reciprocal", "application.meta_lic", 2},
+		{"reciprocal", "bin/bin1.meta_lic", 3},
+		{"reciprocal", "bin/bin2.meta_lic", 1},
+		{"reciprocal", "lib/liba.so.meta_lic", 1},
+		{"restricted", "highest.apex.meta_lic", 7},
+		{"restricted", "container.zip.meta_lic", 7},
+		{"restricted", "application.meta_lic", 2},
+		{"restricted", "bin/bin1.meta_lic", 3},
+		{"restricted", "bin/bin2.meta_lic", 1},
+		{"restricted", "lib/liba.so.meta_lic", 1},
+		{"proprietary", "highest.apex.meta_lic", 7},
+		{"proprietary", "container.zip.meta_lic", 7},
+		{"proprietary", "application.meta_lic", 2},
+		{"proprietary", "bin/bin1.meta_lic", 3},
+		{"proprietary", "bin/bin2.meta_lic", 1},
+		{"proprietary", "lib/liba.so.meta_lic", 1},
+	}
+	for _, tt := range tests {
+		for _, tsv := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s %s tsv=%t", tt.condition, tt.root, tsv), func(t *testing.T) {
+				stdout, stderr := notice(t, compliance.GetFS(""), tsv, "testdata/"+tt.condition+"/"+tt.root)
+				if stderr.Len() > 0 {
+					t.Errorf("csvnotice: gotStderr = %v, want none", stderr)
+				}
+
+				records := parse(t, stdout, tsv)
+				if !reflect.DeepEqual(records[0], header) {
+					t.Errorf("csvnotice: got header %q, want %q", records[0], header)
+				}
+				rows := records[1:]
+				if len(rows) != tt.expectedRows {
+					t.Errorf("csvnotice: got %d rows, want %d:\n%s", len(rows), tt.expectedRows, stdout)
+				}
+				for i, row := range rows {
+					if len(row[0]) == 0 || len(row[1]) == 0 || len(row[4]) == 0 {
+						t.Errorf("csvnotice: got row %q without library, conditions or install path", row)
+					}
+					if !strings.HasPrefix(row[3], "sha256:") {
+						t.Errorf("csvnotice: got text hashes %q, want sha256: hashes", row[3])
+					}
+					if strings.HasPrefix(row[4], "out/") {
+						t.Errorf("csvnotice: got unstripped install path %q", row[4])
+					}
+					if i > 0 {
+						prev := rows[i-1]
+						if prev[0] > row[0] || prev[0] == row[0] && prev[4] >= row[4] {
+							t.Errorf("csvnotice: got row %q after %q, want rows ordered by library and install path", row, prev)
+						}
+					}
+				}
+
+				again, _ := notice(t, compliance.GetFS(""), tsv, "testdata/"+tt.condition+"/"+tt.root)
+				if !bytes.Equal(again.Bytes(), stdout.Bytes()) {
+					t.Errorf("csvnotice: got different output for identical inputs:\n%s\nwant:\n%s", again, stdout)
+				}
+			})
+		}
+	}
+}
+
+func TestQuotingAndMetadata(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "bin1"
+projects: "external/bin1"
+license_kinds: "SPDX-license-identifier-MIT"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin,1"
+`)},
+		"external/bin1/METADATA": {Data: []byte(`name: "bin \"1\", quoted"
+third_party {
+  version: "2.1"
+  url {
+    type: GIT
+    value: "https://example.com/bin1.git"
+  }
+}
+`)},
+		"LICENSE": {Data: []byte("%%%Notice License%%%\n")},
+	}
+
+	stdout, _ := notice(t, rootFS, false, "bin/bin1.meta_lic")
+	records := parse(t, stdout, false)
+
+	expected := [][]string{
+		header,
+		{"bin \"1\", quoted_v_2.1", "notice", "SPDX-license-identifier-MIT", records[1][3], "system/bin/bin,1", "https://example.com/bin1.git", "2.1"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("csvnotice: got records %q, want %q", records, expected)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	overrides, err := compliance.ParseOverrides(strings.NewReader("- {path: \"testdata/restricted/lib/libb.so.meta_lic\", condition: \"notice\"}\n"))
+	if err != nil {
+		t.Fatalf("csvnotice: cannot parse overrides: %v", err)
+	}
+	conditions := func(overrides []compliance.Override) map[string]string {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		var deps []string
+		ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), stripPrefix: []string{"out/target/product/fictional/"}, overrides: overrides, deps: &deps}
+		if err := csvNotice(&ctx, "testdata/restricted/container.zip.meta_lic"); err != nil {
+			t.Fatalf("csvnotice: error = %v, stderr = %v", err, stderr)
+		}
+		result := make(map[string]string)
+		for _, record := range parse(t, stdout, false)[1:] {
+			result[record[4]] = record[1]
+		}
+		return result
+	}
+
+	declared := conditions(nil)
+	overridden := conditions(overrides)
+	tests := []struct {
+		installPath        string
+		expectedDeclared   string
+		expectedOverridden string
+	}{
+		{"data/container.zip/libb.so", "restricted", "notice"},
+		{"data/container.zip/bin2", "notice restricted", "notice"},
+	}
+	for _, tt := range tests {
+		if actual := declared[tt.installPath]; actual != tt.expectedDeclared {
+			t.Errorf("csvnotice: got conditions %q for %q, want %q", actual, tt.installPath, tt.expectedDeclared)
+		}
+		if actual := overridden[tt.installPath]; actual != tt.expectedOverridden {
+			t.Errorf("csvnotice: got overridden conditions %q for %q, want %q", actual, tt.installPath, tt.expectedOverridden)
+		}
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	release := func(libs map[string]string) fstest.MapFS {
+		rootFS := fstest.MapFS{}
+		for name, text := range libs {
+			rootFS[name+".txt"] = &fstest.MapFile{Data: []byte(text + "\n")}
+			rootFS[name+".meta_lic"] = &fstest.MapFile{Data: []byte(fmt.Sprintf("package_name: %q\nlicense_conditions: \"notice\"\nlicense_texts: \"%s.txt\"\ninstalled: \"out/target/product/fictional/system/lib/%s.so\"\n", name, name, name))}
+		}
+		return rootFS
+	}
+	run := func(rootFS fs.FS, delta *compliance.NoticeDelta, roots ...string) ([][]string, string) {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		var deps []string
+		ctx := context{stdout: stdout, stderr: stderr, rootFS: rootFS, stripPrefix: []string{"out/target/product/fictional/"}, delta: delta, deps: &deps}
+		if err := csvNotice(&ctx, roots...); err != nil {
+			t.Fatalf("csvnotice: error = %v, stderr = %v", err, stderr)
+		}
+		return parse(t, stdout, false), stderr.String()
+	}
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+
+	prior := release(map[string]string{"Same": "same", "Changed": "old", "Removed": "removed"})
+	records, _ := run(prior, &compliance.NoticeDelta{WriteBaseline: baseline}, "Same.meta_lic", "Changed.meta_lic", "Removed.meta_lic")
+	if len(records) != 4 {
+		t.Errorf("csvnotice: got %d records writing baseline, want header and 3 libraries", len(records))
+	}
+
+	next := release(map[string]string{"Same": "same", "Changed": "new", "Added": "added"})
+	records, stderr := run(next, &compliance.NoticeDelta{Baseline: baseline}, "Same.meta_lic", "Changed.meta_lic", "Added.meta_lic")
+	actual := []string{}
+	for _, record := range records[1:] {
+		actual = append(actual, record[0])
+	}
+	if expected := []string{"Added", "Changed"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("csvnotice: got libraries %q, want %q", actual, expected)
+	}
+	if expected := "removed since baseline: Removed\n"; stderr != expected {
+		t.Errorf("csvnotice: got stderr %q, want %q", stderr, expected)
+	}
+}
+
+// notice returns the output and the error output of csvnotice for `roots`.
+func notice(t *testing.T, rootFS fs.FS, tsv bool, roots ...string) (*bytes.Buffer, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	ctx := context{stdout: stdout, stderr: stderr, rootFS: rootFS, stripPrefix: []string{"out/target/product/fictional/"}, tsv: tsv, deps: &deps}
+
+	err := csvNotice(&ctx, roots...)
+	if err != nil {
+		t.Fatalf("csvnotice: error = %v, stderr = %v", err, stderr)
+	}
+	return stdout, stderr
+}
+
+// parse returns the records of the csv or tsv `output`.
+func parse(t *testing.T, output *bytes.Buffer, tsv bool) [][]string {
+	r := csv.NewReader(bytes.NewReader(output.Bytes()))
+	if tsv {
+		r.Comma = '\t'
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("csvnotice: cannot parse output: %v\n%s", err, output)
+	}
+	if len(records) == 0 {
+		t.Fatalf("csvnotice: got no records, want header")
+	}
+	return records
+}
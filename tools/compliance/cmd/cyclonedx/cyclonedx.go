@@ -0,0 +1,463 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/projectmetadata"
+
+	"github.com/google/blueprint/deptools"
+)
+
+// dtTimeout limits how long an upload to Dependency-Track may take.
+const dtTimeout = 5 * time.Minute
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	rootFS      fs.FS
+	product     string
+	stripPrefix []string
+	// productFS holds the product out directory to hash installed artifacts
+	// or nil to omit hashes.
+	productFS    fs.FS
+	creationTime creationTimeGetter
+	deps         *[]string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// bom describes a CycloneDX 1.5 bill of materials.
+type bom struct {
+	BomFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	SerialNumber string       `json:"serialNumber"`
+	Version      int          `json:"version"`
+	Metadata     metadata     `json:"metadata"`
+	Components   []component  `json:"components"`
+	Dependencies []dependency `json:"dependencies"`
+}
+
+// metadata describes the creation of the bill of materials.
+type metadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []component `json:"components"`
+	} `json:"tools"`
+	Component *component `json:"component,omitempty"`
+}
+
+// component describes a shipped target.
+type component struct {
+	Type       string     `json:"type"`
+	BomRef     string     `json:"bom-ref,omitempty"`
+	Name       string     `json:"name"`
+	Version    string     `json:"version,omitempty"`
+	Licenses   []license  `json:"licenses,omitempty"`
+	Hashes     []hash     `json:"hashes,omitempty"`
+	Properties []property `json:"properties,omitempty"`
+}
+
+// license identifies a license of a component by SPDX identifier, or by name
+// when the license kind has no SPDX identifier.
+type license struct {
+	License struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"license"`
+}
+
+// hash describes the digest of an installed artifact.
+type hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// property describes an Android-specific attribute of a component.
+type property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// dependency lists the components a component depends on.
+type dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs a CycloneDX 1.5 JSON bill of materials with a component for each
+shipped target and the dependencies between them.
+
+Uploads the bill of materials to the Dependency-Track project named by
+-product with -upload_dt. The API key comes from -dt_api_key or else the
+DT_API_KEY environment variable.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the CycloneDX json file. (default stdout)")
+	depsFile := flags.String("d", "", "Where to write the deps file")
+	product := flags.String("product", "", "The name of the product for which the bill of materials is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	productOut := flags.String("product_out", "", "The product out directory holding the installed artifacts to hash. Install paths with -strip_prefix removed are relative to it.")
+	created := flags.String("creation_time", "", "The creation time to record in the bill of materials as YYYY-MM-DDThh:mm:ssZ. (default now)")
+	uploadDT := flags.Bool("upload_dt", false, "Whether to upload the bill of materials to Dependency-Track. (requires -dt_url and -product)")
+	dtURL := flags.String("dt_url", "", "The base URL of the Dependency-Track server. e.g. https://dtrack.example.com")
+	dtAPIKey := flags.String("dt_api_key", "", "The Dependency-Track API key with the BOM_UPLOAD permission. (default $DT_API_KEY)")
+	dtProjectVersion := flags.String("dt_project_version", "", "The version of the Dependency-Track project to upload to.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var uploader *dependencyTrackUploader
+	if *uploadDT {
+		if len(*dtURL) == 0 || len(*product) == 0 {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "-upload_dt requires -dt_url and -product\n")
+			os.Exit(2)
+		}
+		apiKey := *dtAPIKey
+		if len(apiKey) == 0 {
+			apiKey = os.Getenv("DT_API_KEY")
+		}
+		uploader = &dependencyTrackUploader{URL: *dtURL, APIKey: apiKey, Client: &http.Client{Timeout: dtTimeout}}
+	}
+
+	var productFS fs.FS
+	if len(*productOut) > 0 {
+		fi, err := os.Stat(*productOut)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read -product_out %q: %s\n", *productOut, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "-product_out %q is not a directory\n", *productOut)
+			os.Exit(1)
+		}
+		productFS = os.DirFS(*productOut)
+	}
+
+	creationTime := actualTime
+	if len(*created) > 0 {
+		if _, err := time.Parse("2006-01-02T15:04:05Z", *created); err != nil {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "invalid -creation_time %q: %s\n", *created, err)
+			os.Exit(2)
+		}
+		creationTime = func() string { return *created }
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	var obuf *bytes.Buffer
+	if *outputFile != "-" || uploader != nil {
+		obuf = &bytes.Buffer{}
+		ofile = obuf
+	}
+
+	var deps []string
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, productFS, creationTime, &deps}
+
+	err := cycloneDX(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, obuf.Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	} else if obuf != nil {
+		os.Stdout.Write(obuf.Bytes())
+	}
+	if uploader != nil {
+		// Stop uploading on interrupt.
+		uploadCtx, stop := signal.NotifyContext(gocontext.Background(), os.Interrupt)
+		err := uploader.Upload(uploadCtx, obuf.Bytes(), *product, *dtProjectVersion)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+	}
+	if *depsFile != "" {
+		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+type creationTimeGetter func() string
+
+// actualTime returns current time in UTC
+func actualTime() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// serialNumber returns a version 5 style UUID URN derived from `data` so that
+// identical bills of materials get identical serial numbers.
+func serialNumber(data []byte) string {
+	h := sha1.Sum(data)
+	h[6] = (h[6] & 0x0f) | 0x50
+	h[8] = (h[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// cycloneDX implements the cyclonedx utility.
+//
+// Components and dependencies appear in target name order so that identical
+// inputs produce identical output.
+func cycloneDX(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(rootFS, ctx.stderr, files)
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+
+	shipped := compliance.ShippedNodes(licenseGraph)
+	targets := make(compliance.TargetNodeList, 0, len(shipped))
+	for tn := range shipped {
+		targets = append(targets, tn)
+	}
+	sort.Sort(targets)
+
+	roots := make(map[string]struct{})
+	for _, f := range files {
+		roots[f] = struct{}{}
+	}
+
+	pmix := projectmetadata.NewIndex(rootFS)
+
+	b := bom{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		Version:      1,
+		Components:   []component{},
+		Dependencies: []dependency{},
+	}
+	b.Metadata.Timestamp = ctx.creationTime()
+	b.Metadata.Tools.Components = []component{{Type: "application", Name: "cyclonedx"}}
+	if len(ctx.product) > 0 {
+		b.Metadata.Component = &component{Type: "device", Name: ctx.product}
+	}
+
+	for _, tn := range targets {
+		c, err := describeComponent(ctx, pmix, tn)
+		if err != nil {
+			return err
+		}
+		if _, isRoot := roots[tn.Name()]; isRoot {
+			c.Type = "application"
+		}
+		b.Components = append(b.Components, c)
+
+		dependsOn := []string{}
+		for _, e := range tn.Dependencies() {
+			if shipped.Contains(e.Dependency()) {
+				dependsOn = append(dependsOn, e.Dependency().Name())
+			}
+		}
+		sort.Strings(dependsOn)
+		b.Dependencies = append(b.Dependencies, dependency{tn.Name(), dependsOn})
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("Unable to write CycloneDX bom: %v\n", err)
+	}
+	b.SerialNumber = serialNumber(data)
+
+	data, err = json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to write CycloneDX bom: %v\n", err)
+	}
+	fmt.Fprintln(ctx.stdout, string(data))
+
+	*ctx.deps = rootFS.Files()
+
+	return nil
+}
+
+// describeComponent returns the component for shipped target `tn`.
+//
+// The name and version come from the METADATA of the project when available.
+// Components installing exactly one artifact found in the product out
+// directory get the hashes of the artifact.
+func describeComponent(ctx *context, pmix *projectmetadata.Index, tn *compliance.TargetNode) (component, error) {
+	c := component{
+		Type:   "library",
+		BomRef: tn.Name(),
+		Name:   strings.TrimSuffix(filepath.Base(tn.Name()), ".meta_lic"),
+	}
+
+	pms, err := pmix.MetadataForProjects(tn.Projects()...)
+	if err != nil {
+		return component{}, fmt.Errorf("Unable to read project metadata for %q: %v\n", tn.Name(), err)
+	}
+	for _, pm := range pms {
+		if len(pm.Name()) > 0 {
+			c.Name = pm.Name()
+			c.Version = pm.Version()
+			break
+		}
+	}
+
+	kinds := append([]string{}, tn.LicenseKinds()...)
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		var l license
+		if id, ok := compliance.SpdxLicenseID(kind); ok {
+			l.License.ID = id
+		} else {
+			l.License.Name = kind
+		}
+		c.Licenses = append(c.Licenses, l)
+	}
+
+	installed := tn.Installed()
+	for _, installPath := range installed {
+		c.Properties = append(c.Properties, property{"android:install_path", ctx.strip(installPath)})
+	}
+	sort.Slice(c.Properties, func(i, j int) bool { return c.Properties[i].Value < c.Properties[j].Value })
+
+	if ctx.productFS != nil && len(installed) == 1 {
+		data, err := fs.ReadFile(ctx.productFS, ctx.strip(installed[0]))
+		if err == nil {
+			sha1sum := sha1.Sum(data)
+			sha256sum := sha256.Sum256(data)
+			c.Hashes = []hash{
+				{"SHA-1", hex.EncodeToString(sha1sum[:])},
+				{"SHA-256", hex.EncodeToString(sha256sum[:])},
+			}
+		} else if !os.IsNotExist(err) {
+			return component{}, fmt.Errorf("Unable to hash %q: %v\n", installed[0], err)
+		}
+	}
+	return c, nil
+}
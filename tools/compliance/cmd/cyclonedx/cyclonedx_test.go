@@ -0,0 +1,252 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func fixedTime() string {
+	return "2026-01-01T00:00:00Z"
+}
+
+// serialNumberPattern matches the serialNumber per the CycloneDX 1.5 schema.
+var serialNumberPattern = regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// expectedComponent describes a component expected in the bill of materials.
+type expectedComponent struct {
+	name      string
+	licenses  []string
+	dependsOn []string
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition string
+		name      string
+		root      string
+		expected  []expectedComponent
+	}{
+		{
+			condition: "firstparty",
+			name:      "highest.apex",
+			root:      "highest.apex.meta_lic",
+			expected: []expectedComponent{
+				{"bin1", []string{"Apache-2.0"}, []string{"lib/liba.so.meta_lic", "lib/libc.a.meta_lic"}},
+				{"bin2", []string{"Apache-2.0"}, []string{"lib/libb.so.meta_lic"}},
+				{"highest.apex", []string{"Apache-2.0"}, []string{"bin/bin1.meta_lic", "bin/bin2.meta_lic", "lib/liba.so.meta_lic", "lib/libb.so.meta_lic"}},
+				{"liba.so", []string{"Apache-2.0"}, []string{}},
+				{"libb.so", []string{"Apache-2.0"}, []string{}},
+				{"libc.a", []string{"Apache-2.0"}, []string{}},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "container",
+			root:      "container.zip.meta_lic",
+			expected: []expectedComponent{
+				{"bin1", []string{"Apache-2.0"}, []string{"lib/liba.so.meta_lic", "lib/libc.a.meta_lic"}},
+				{"bin2", []string{"Apache-2.0"}, []string{"lib/libb.so.meta_lic"}},
+				{"container.zip", []string{"Apache-2.0"}, []string{"bin/bin1.meta_lic", "bin/bin2.meta_lic", "lib/liba.so.meta_lic", "lib/libb.so.meta_lic"}},
+				{"liba.so", []string{"SPDX-license-identifier-BSD"}, []string{}},
+				{"libb.so", []string{"Apache-2.0"}, []string{}},
+				{"libc.a", []string{"MIT"}, []string{}},
+			},
+		},
+		{
+			condition: "proprietary",
+			name:      "highest.apex",
+			root:      "highest.apex.meta_lic",
+			expected: []expectedComponent{
+				{"bin1", []string{"Apache-2.0"}, []string{"lib/liba.so.meta_lic", "lib/libc.a.meta_lic"}},
+				{"bin2", []string{"legacy_proprietary"}, []string{"lib/libb.so.meta_lic"}},
+				{"highest.apex", []string{"Apache-2.0"}, []string{"bin/bin1.meta_lic", "bin/bin2.meta_lic", "lib/liba.so.meta_lic", "lib/libb.so.meta_lic"}},
+				{"liba.so", []string{"legacy_proprietary"}, []string{}},
+				{"libb.so", []string{"GPL-2.0"}, []string{}},
+				{"libc.a", []string{"legacy_proprietary"}, []string{}},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "container",
+			root:      "container.zip.meta_lic",
+			expected: []expectedComponent{
+				{"bin1", []string{"Apache-2.0"}, []string{"lib/liba.so.meta_lic", "lib/libc.a.meta_lic"}},
+				{"bin2", []string{"Apache-2.0"}, []string{"lib/libb.so.meta_lic"}},
+				{"container.zip", []string{"Apache-2.0"}, []string{"bin/bin1.meta_lic", "bin/bin2.meta_lic", "lib/liba.so.meta_lic", "lib/libb.so.meta_lic"}},
+				{"liba.so", []string{"SPDX-license-identifier-LGPL-2.0"}, []string{}},
+				{"libb.so", []string{"GPL-2.0"}, []string{}},
+				{"libc.a", []string{"SPDX-license-identifier-MPL"}, []string{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
+			root := "testdata/" + tt.condition + "/" + tt.root
+			b := generate(t, compliance.GetFS(""), nil, root)
+
+			actual := []expectedComponent{}
+			for _, c := range b.Components {
+				ec := expectedComponent{name: c.Name, dependsOn: []string{}}
+				for _, l := range c.Licenses {
+					ec.licenses = append(ec.licenses, l.License.ID+l.License.Name)
+				}
+				actual = append(actual, ec)
+				if (c.BomRef == root) != (c.Type == "application") {
+					t.Errorf("got type %q for %q", c.Type, c.BomRef)
+				}
+			}
+			for i, d := range b.Dependencies {
+				if i >= len(actual) || d.Ref != b.Components[i].BomRef {
+					t.Fatalf("got dependencies %v, want one per component in component order", b.Dependencies)
+				}
+				for _, ref := range d.DependsOn {
+					actual[i].dependsOn = append(actual[i].dependsOn, strings.TrimPrefix(ref, "testdata/"+tt.condition+"/"))
+				}
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("got components:\n%v\nwant:\n%v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVersionAndHashes(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin.meta_lic": &fstest.MapFile{Data: []byte(`package_name: "Bin"
+projects: "external/bin"
+license_kinds: "SPDX-license-identifier-MIT"
+license_conditions: "notice"
+installed: "out/target/product/fictional/system/bin/bin"
+deps: {
+  file: "lib.meta_lic"
+  annotations: "static"
+}
+`)},
+		"lib.meta_lic": &fstest.MapFile{Data: []byte(`package_name: "Lib"
+license_kinds: "legacy_notice"
+license_conditions: "notice"
+installed: "out/target/product/fictional/system/lib/lib.so"
+`)},
+		"external/bin/METADATA": &fstest.MapFile{Data: []byte(`name: "bin"
+third_party {
+  version: "2.1"
+}
+`)},
+	}
+	productFS := fstest.MapFS{
+		"system/bin/bin": &fstest.MapFile{Data: []byte("abc")},
+	}
+	b := generate(t, rootFS, productFS, "bin.meta_lic")
+
+	if len(b.Components) != 2 {
+		t.Fatalf("got %d components, want 2", len(b.Components))
+	}
+	bin, lib := b.Components[0], b.Components[1]
+	if bin.Name != "bin" || bin.Version != "2.1" {
+		t.Errorf("got %q version %q, want \"bin\" version \"2.1\"", bin.Name, bin.Version)
+	}
+	expectedHashes := []hash{
+		{"SHA-1", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"SHA-256", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	if !reflect.DeepEqual(bin.Hashes, expectedHashes) {
+		t.Errorf("got hashes %v, want %v", bin.Hashes, expectedHashes)
+	}
+	if lib.Name != "lib" || lib.Version != "" || lib.Hashes != nil {
+		t.Errorf("got %q version %q hashes %v, want \"lib\" without version or hashes", lib.Name, lib.Version, lib.Hashes)
+	}
+	if len(lib.Licenses) != 1 || lib.Licenses[0].License.Name != "legacy_notice" || lib.Licenses[0].License.ID != "" {
+		t.Errorf("got licenses %v, want name legacy_notice", lib.Licenses)
+	}
+	if expected := []property{{"android:install_path", "system/lib/lib.so"}}; !reflect.DeepEqual(lib.Properties, expected) {
+		t.Errorf("got properties %v, want %v", lib.Properties, expected)
+	}
+	if b.Metadata.Component == nil || b.Metadata.Component.Name != "fictional" {
+		t.Errorf("got metadata component %v, want device \"fictional\"", b.Metadata.Component)
+	}
+}
+
+// generate returns the parsed bill of materials for `roots` after checking
+// the properties required by the CycloneDX 1.5 schema and determinism.
+func generate(t *testing.T, rootFS, productFS fs.FS, roots ...string) *bom {
+	t.Helper()
+	run := func() []byte {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		var deps []string
+		ctx := &context{stdout, stderr, rootFS, "fictional", []string{"out/target/product/fictional/"}, productFS, fixedTime, &deps}
+		if err := cycloneDX(ctx, roots...); err != nil {
+			t.Fatalf("cyclonedx: error = %v, stderr = %v", err, stderr)
+		}
+		if len(deps) == 0 {
+			t.Errorf("cyclonedx: no deps recorded")
+		}
+		return stdout.Bytes()
+	}
+	data := run()
+	if again := run(); !bytes.Equal(data, again) {
+		t.Errorf("cyclonedx: output not deterministic:\n%s\n%s", string(data), string(again))
+	}
+
+	var b bom
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatalf("cyclonedx: unable to parse output: %s\n%s", err, string(data))
+	}
+	if b.BomFormat != "CycloneDX" || b.SpecVersion != "1.5" || b.Version != 1 {
+		t.Errorf("cyclonedx: got bomFormat %q specVersion %q version %d", b.BomFormat, b.SpecVersion, b.Version)
+	}
+	if !serialNumberPattern.MatchString(b.SerialNumber) {
+		t.Errorf("cyclonedx: got serialNumber %q, want %s", b.SerialNumber, serialNumberPattern)
+	}
+	refs := make(map[string]bool)
+	for _, c := range b.Components {
+		if len(c.Type) == 0 || len(c.Name) == 0 || len(c.BomRef) == 0 {
+			t.Errorf("cyclonedx: component missing type, name or bom-ref: %v", c)
+		}
+		if refs[c.BomRef] {
+			t.Errorf("cyclonedx: duplicate bom-ref %q", c.BomRef)
+		}
+		refs[c.BomRef] = true
+	}
+	for _, d := range b.Dependencies {
+		for _, ref := range append([]string{d.Ref}, d.DependsOn...) {
+			if !refs[ref] {
+				t.Errorf("cyclonedx: dependency refers to unknown bom-ref %q", ref)
+			}
+		}
+	}
+	return &b
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxDependencyTrackError limits how much of an error response body an
+// upload error quotes.
+const maxDependencyTrackError = 512
+
+// dependencyTrackUploader uploads CycloneDX bills of materials to a
+// Dependency-Track server using its REST API.
+type dependencyTrackUploader struct {
+	// URL is the base URL of the server, e.g. "https://dtrack.example.com".
+	URL string
+	// APIKey authenticates the uploads. The key needs the BOM_UPLOAD
+	// permission, and PROJECT_CREATION_UPLOAD for projects not yet on the
+	// server.
+	APIKey string
+	// Client sends the requests or is nil to use http.DefaultClient.
+	Client *http.Client
+}
+
+// dependencyTrackBom is the body of a PUT /api/v1/bom request.
+type dependencyTrackBom struct {
+	ProjectName    string `json:"projectName"`
+	ProjectVersion string `json:"projectVersion,omitempty"`
+	AutoCreate     bool   `json:"autoCreate"`
+	// Bom is the base64 encoded bill of materials.
+	Bom string `json:"bom"`
+}
+
+// Upload uploads the bill of materials `sbom` to the project `projectName`
+// at `projectVersion`, creating the project when missing, until `ctx` is
+// done.
+//
+// The server processes the upload asynchronously, so a nil error means only
+// that the server accepted it.
+func (u *dependencyTrackUploader) Upload(ctx gocontext.Context, sbom []byte, projectName, projectVersion string) error {
+	if len(u.URL) == 0 {
+		return fmt.Errorf("no Dependency-Track URL to upload to")
+	}
+	if len(projectName) == 0 {
+		return fmt.Errorf("no Dependency-Track project name to upload to")
+	}
+	body, err := json.Marshal(dependencyTrackBom{
+		ProjectName:    projectName,
+		ProjectVersion: projectVersion,
+		AutoCreate:     true,
+		Bom:            base64.StdEncoding.EncodeToString(sbom),
+	})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(u.URL, "/") + "/api/v1/bom"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid Dependency-Track URL %q: %w", u.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", u.APIKey)
+
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not upload bill of materials to %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxDependencyTrackError))
+		return fmt.Errorf("could not upload bill of materials to %q: %s: %s", url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	// Drain the body so the client can reuse the connection.
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
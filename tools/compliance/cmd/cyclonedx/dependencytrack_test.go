@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDependencyTrackUpload(t *testing.T) {
+	sbom := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5"}`)
+	var got dependencyTrackBom
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v1/bom" {
+			t.Errorf("Upload: got %s %s, want PUT /api/v1/bom", r.Method, r.URL.Path)
+		}
+		if key := r.Header.Get("X-Api-Key"); key != "secret" {
+			t.Errorf("Upload: got API key %q, want %q", key, "secret")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Upload: got invalid body: %s", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "66c2a5c2-2a3c-4bd7-8e1e-6d2b4f1c9a10"}`))
+	}))
+	defer server.Close()
+
+	u := &dependencyTrackUploader{server.URL + "/", "secret", server.Client()}
+	if err := u.Upload(gocontext.Background(), sbom, "fictional", "1.0"); err != nil {
+		t.Fatalf("Upload: got error %s, want no error", err)
+	}
+	if got.ProjectName != "fictional" || got.ProjectVersion != "1.0" || !got.AutoCreate {
+		t.Errorf("Upload: got request %+v, want project fictional 1.0 created automatically", got)
+	}
+	if bom, err := base64.StdEncoding.DecodeString(got.Bom); err != nil || string(bom) != string(sbom) {
+		t.Errorf("Upload: got bom %q error %v, want %q", bom, err, sbom)
+	}
+}
+
+func TestDependencyTrackUploadErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			http.Error(w, "The API key could not be validated", http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, strings.Repeat("x", 2*maxDependencyTrackError), http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		uploader *dependencyTrackUploader
+		project  string
+		expected string
+	}{
+		{"unauthorized", &dependencyTrackUploader{server.URL, "wrong", server.Client()}, "fictional", "401 Unauthorized: The API key could not be validated"},
+		{"servererror", &dependencyTrackUploader{server.URL, "secret", server.Client()}, "fictional", "500 Internal Server Error: " + strings.Repeat("x", maxDependencyTrackError)},
+		{"nourl", &dependencyTrackUploader{"", "secret", nil}, "fictional", "no Dependency-Track URL"},
+		{"noproject", &dependencyTrackUploader{server.URL, "secret", server.Client()}, "", "no Dependency-Track project name"},
+		{"badurl", &dependencyTrackUploader{"http://[::1", "secret", nil}, "fictional", "invalid Dependency-Track URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.uploader.Upload(gocontext.Background(), []byte("{}"), tt.project, "")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Upload: got error %v, want error containing %q", err, tt.expected)
+			}
+			if err != nil && strings.Contains(err.Error(), strings.Repeat("x", maxDependencyTrackError+1)) {
+				t.Errorf("Upload: got error quoting more than %d bytes of the response", maxDependencyTrackError)
+			}
+		})
+	}
+
+	// A server that went away fails the upload.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	u := &dependencyTrackUploader{closed.URL, "secret", nil}
+	if err := u.Upload(gocontext.Background(), []byte("{}"), "fictional", ""); err == nil {
+		t.Errorf("Upload(closed server): got no error, want error")
+	}
+}
@@ -156,7 +156,8 @@ Options:
 
 	lcs := make([]compliance.LicenseCondition, 0, len(*conditions))
 	for _, name := range *conditions {
-		lcs = append(lcs, compliance.RecognizedConditionNames[name])
+		lc, _ := compliance.Conditions.Condition(name)
+		lcs = append(lcs, lc)
 	}
 	ctx := &context{
 		conditions:      lcs,
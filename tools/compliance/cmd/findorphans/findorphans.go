@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failOrphans       = fmt.Errorf("orphans")
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoRootDir     = fmt.Errorf("\nNo -root_dir directory given")
+)
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} -root_dir dir file.meta_lic {file.meta_lic...}
+
+Outputs the license metadata files (*.meta_lic) under the -root_dir
+directory that are not reachable from any of the root license metadata
+files, one per line in sorted order.
+
+Build refactors sometimes leave license metadata files behind that no
+longer describe any target in the build.
+
+With -fail, exits with status 1 when any orphan is found.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the list of orphans. (default stdout)")
+	rootDir := flags.String("root_dir", "", "The directory to search for license metadata files.")
+	failOnOrphans := flags.Bool("fail", false, "Exit with status 1 when any orphan is found.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	var obuf *bytes.Buffer
+	if *outputFile != "-" {
+		obuf = &bytes.Buffer{}
+		ofile = obuf
+	}
+
+	err := findOrphans(ofile, os.Stderr, compliance.FS, *rootDir, *failOnOrphans, flags.Args()...)
+	if err != nil && err != failOrphans {
+		if err == failNoneRequested || err == failNoRootDir {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, obuf.Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q from %q: %s\n", *outputFile, os.Getenv("PWD"), err)
+			os.Exit(1)
+		}
+	}
+	if err == failOrphans {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// findOrphans implements the findorphans utility.
+func findOrphans(stdout, stderr io.Writer, rootFS fs.FS, rootDir string, failOnOrphans bool, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+	if len(rootDir) == 0 {
+		return failNoRootDir
+	}
+
+	orphans, err := compliance.FindOrphanMetaLic(rootDir, rootFS, files)
+	if err != nil {
+		return fmt.Errorf("Unable to find orphans under %q for %q: %w\n", rootDir, files, err)
+	}
+
+	for _, orphan := range orphans {
+		fmt.Fprintln(stdout, orphan)
+	}
+	if failOnOrphans && len(orphans) > 0 {
+		fmt.Fprintf(stderr, "%d orphaned license metadata file(s) under %q\n", len(orphans), rootDir)
+		return failOrphans
+	}
+	return nil
+}
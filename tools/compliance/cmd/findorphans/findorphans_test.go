@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		name          string
+		rootDir       string
+		roots         []string
+		failOnOrphans bool
+		expectedOut   []string
+		expectedErr   error
+	}{
+		{
+			name:    "bin",
+			rootDir: "testdata/regressorphans",
+			roots:   []string{"testdata/regressorphans/bin/bin1.meta_lic"},
+			expectedOut: []string{
+				"testdata/regressorphans/lib/libold.so.meta_lic",
+				"testdata/regressorphans/obj/stale.a.meta_lic",
+			},
+		},
+		{
+			name:    "bin+fail",
+			rootDir: "testdata/regressorphans/",
+			roots:   []string{"testdata/regressorphans/bin/bin1.meta_lic"},
+			expectedOut: []string{
+				"testdata/regressorphans/lib/libold.so.meta_lic",
+				"testdata/regressorphans/obj/stale.a.meta_lic",
+			},
+			failOnOrphans: true,
+			expectedErr:   failOrphans,
+		},
+		{
+			name:    "lib",
+			rootDir: "testdata/regressorphans",
+			roots:   []string{"testdata/regressorphans/lib/liba.so.meta_lic"},
+			expectedOut: []string{
+				"testdata/regressorphans/bin/bin1.meta_lic",
+				"testdata/regressorphans/lib/libold.so.meta_lic",
+				"testdata/regressorphans/obj/stale.a.meta_lic",
+			},
+		},
+		{
+			name:    "allroots",
+			rootDir: "testdata/regressorphans",
+			roots: []string{
+				"testdata/regressorphans/bin/bin1.meta_lic",
+				"testdata/regressorphans/lib/libold.so.meta_lic",
+				"testdata/regressorphans/obj/stale.a.meta_lic",
+			},
+			failOnOrphans: true,
+		},
+		{
+			name:    "libdir",
+			rootDir: "testdata/regressorphans/lib",
+			roots:   []string{"testdata/regressorphans/bin/bin1.meta_lic"},
+			expectedOut: []string{
+				"testdata/regressorphans/lib/libold.so.meta_lic",
+			},
+		},
+		{
+			name:        "nonerequested",
+			rootDir:     "testdata/regressorphans",
+			expectedErr: failNoneRequested,
+		},
+		{
+			name:        "norootdir",
+			roots:       []string{"testdata/regressorphans/bin/bin1.meta_lic"},
+			expectedErr: failNoRootDir,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := findOrphans(stdout, stderr, compliance.GetFS(""), tt.rootDir, tt.failOnOrphans, tt.roots...)
+			if err != tt.expectedErr {
+				t.Fatalf("findorphans: got error %v, want %v, stderr = %v", err, tt.expectedErr, stderr)
+			}
+			if tt.expectedErr == failNoneRequested || tt.expectedErr == failNoRootDir {
+				return
+			}
+			var lines []string
+			if stdout.Len() > 0 {
+				lines = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			}
+			if len(lines) != len(tt.expectedOut) {
+				t.Fatalf("findorphans: got %d lines %q, want %d lines %q", len(lines), lines, len(tt.expectedOut), tt.expectedOut)
+			}
+			for i, line := range lines {
+				if line != tt.expectedOut[i] {
+					t.Errorf("findorphans: unexpected line %d: got %q, want %q", i+1, line, tt.expectedOut[i])
+				}
+			}
+			if tt.expectedErr == nil && stderr.Len() > 0 {
+				t.Errorf("findorphans: got stderr %q, want none", stderr)
+			}
+		})
+	}
+}
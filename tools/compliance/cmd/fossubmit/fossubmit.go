@@ -0,0 +1,283 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+// requestTimeout limits how long each request to Fossology may take.
+const requestTimeout = 5 * time.Minute
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout io.Writer
+	stderr io.Writer
+	rootFS fs.FS
+	client *compliance.FossologyClient
+	// name and version identify the submitted package.
+	name    string
+	version string
+	// wait limits how long to wait for the scan results or is 0 to only
+	// submit the package.
+	wait time.Duration
+	// pollInterval is how long to wait between requests for the results.
+	pollInterval time.Duration
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Bundles the license metadata files and license texts of the license graph
+into a gzipped tarball and submits it to Fossology for license scanning.
+
+Outputs the Fossology upload ID, or with -wait the findings for each file as
+JSON once the scan finishes. The token comes from -token or else the
+FOSSOLOGY_TOKEN environment variable.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the upload ID or findings. (default stdout)")
+	url := flags.String("url", "", "The base URL of the Fossology REST API. e.g. https://fossology.example.com/repo")
+	token := flags.String("token", "", "The Fossology token with write access. (default $FOSSOLOGY_TOKEN)")
+	folderID := flags.Int64("folder_id", 0, "The Fossology folder to upload into. (default the root folder)")
+	name := flags.String("name", "", "The name of the package to submit, e.g. the product.")
+	version := flags.String("version", "", "The version of the package to submit.")
+	wait := flags.Duration("wait", 0, "How long to wait for the scan to finish and output its findings. e.g. 30m (default 0 means submit only)")
+	pollInterval := flags.Duration("poll_interval", 30*time.Second, "How long to wait between requests for the findings with -wait.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*url) == 0 || len(*name) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify -url and -name\n")
+		os.Exit(2)
+	}
+	if *wait < 0 || *pollInterval <= 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-wait must not be negative and -poll_interval must be positive\n")
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	apiToken := *token
+	if len(apiToken) == 0 {
+		apiToken = os.Getenv("FOSSOLOGY_TOKEN")
+	}
+	client := &compliance.FossologyClient{URL: *url, Token: apiToken, FolderID: *folderID, Client: &http.Client{Timeout: requestTimeout}}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, client, *name, *version, *wait, *pollInterval}
+
+	// Stop submitting or waiting on interrupt.
+	gctx, stop := signal.NotifyContext(gocontext.Background(), os.Interrupt)
+	err := fossSubmit(gctx, ctx, flags.Args()...)
+	stop()
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// fossSubmit implements the fossubmit utility.
+func fossSubmit(gctx gocontext.Context, ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	tarball, err := bundle(ctx, files...)
+	if err != nil {
+		return err
+	}
+
+	uploadID, err := ctx.client.SubmitPackage(gctx, ctx.name, ctx.version, bytes.NewReader(tarball))
+	if err != nil {
+		return fmt.Errorf("Unable to submit %q to Fossology: %w\n", ctx.name, err)
+	}
+	if ctx.wait == 0 {
+		fmt.Fprintln(ctx.stdout, uploadID)
+		return nil
+	}
+	fmt.Fprintf(ctx.stderr, "waiting for Fossology to scan upload %d\n", uploadID)
+
+	gctx, cancel := gocontext.WithTimeout(gctx, ctx.wait)
+	defer cancel()
+	for {
+		findings, err := ctx.client.GetScanResult(gctx, uploadID)
+		if err == nil {
+			enc := json.NewEncoder(ctx.stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(findings)
+		}
+		// A request cut short by the deadline also means not finished.
+		if gctx.Err() == nil && !errors.Is(err, compliance.ErrFossologyScanPending) {
+			return fmt.Errorf("Unable to get Fossology findings for upload %d: %w\n", uploadID, err)
+		}
+		select {
+		case <-gctx.Done():
+			if gctx.Err() == gocontext.DeadlineExceeded {
+				return fmt.Errorf("Unable to get Fossology findings for upload %d: scan not finished after %s\n", uploadID, ctx.wait)
+			}
+			return gctx.Err()
+		case <-time.After(ctx.pollInterval):
+		}
+	}
+}
+
+// bundle returns a gzipped tarball of the license metadata files and
+// license texts of the license graph for the root `files`.
+//
+// The tarball lists the files in sorted order with fixed modification times
+// so that the same graph always bundles the same bytes.
+func bundle(ctx *context, files ...string) ([]byte, error) {
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return nil, failNoLicenses
+	}
+
+	paths := make(map[string]struct{})
+	for _, tn := range licenseGraph.Targets() {
+		paths[tn.Name()] = struct{}{}
+		for _, text := range tn.LicenseTexts() {
+			paths[filepath.Clean(strings.SplitN(text, ":", 2)[0])] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, path := range sorted {
+		data, err := fs.ReadFile(ctx.rootFS, path)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read %q to bundle: %w\n", path, err)
+		}
+		hdr := &tar.Header{Name: path, Mode: 0644, Size: int64(len(data)), ModTime: time.Unix(0, 0), Format: tar.FormatPAX}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
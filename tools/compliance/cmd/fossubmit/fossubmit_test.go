@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// roundTripFunc implements http.RoundTripper by calling the function.
+type roundTripFunc func(*http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// fakeFossology returns a client answering the requests like Fossology
+// recording the names of the files in the uploaded tarball in `uploaded`.
+// The scan finishes after `pending` requests for the findings.
+func fakeFossology(t *testing.T, uploaded *[]string, pending int) *compliance.FossologyClient {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repo/api/v1/uploads":
+			f, _, err := r.FormFile("fileInput")
+			if err != nil {
+				t.Fatalf("fossubmit: got no uploaded file: %s", err)
+			}
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("fossubmit: got invalid gzip upload: %s", err)
+			}
+			tr := tar.NewReader(gz)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("fossubmit: got invalid tarball: %s", err)
+				}
+				*uploaded = append(*uploaded, hdr.Name)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"code": 201, "message": 42, "type": "INFO"}`))
+		case "/repo/api/v1/jobs":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"code": 201, "message": 7, "type": "INFO"}`))
+		case "/repo/api/v1/uploads/42/licenses":
+			if pending > 0 {
+				pending--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`[{"filePath": "testdata/notice/NOTICE_LICENSE", "findings": {"scanner": ["Apache-2.0"], "conclusion": [], "copyright": []}}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	})}
+	return &compliance.FossologyClient{URL: "https://fossology.example.com/repo", Token: "secret", Client: client}
+}
+
+func TestFossSubmit(t *testing.T) {
+	var uploaded []string
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := &context{stdout, stderr, compliance.GetFS(""), fakeFossology(t, &uploaded, 0), "fictional", "1.0", 0, time.Millisecond}
+
+	if err := fossSubmit(gocontext.Background(), ctx, "testdata/notice/application.meta_lic"); err != nil {
+		t.Fatalf("fossubmit: error = %v, stderr = %v", err, stderr)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "42" {
+		t.Errorf("fossubmit: got stdout %q, want upload ID %q", got, "42")
+	}
+	expected := []string{
+		"testdata/firstparty/FIRST_PARTY_LICENSE",
+		"testdata/notice/NOTICE_LICENSE",
+		"testdata/notice/application.meta_lic",
+		"testdata/notice/bin/bin3.meta_lic",
+		"testdata/notice/lib/liba.so.meta_lic",
+		"testdata/notice/lib/libb.so.meta_lic",
+	}
+	if !reflect.DeepEqual(uploaded, expected) {
+		t.Errorf("fossubmit: got uploaded files %q, want %q", uploaded, expected)
+	}
+}
+
+func TestFossSubmitWait(t *testing.T) {
+	var uploaded []string
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := &context{stdout, stderr, compliance.GetFS(""), fakeFossology(t, &uploaded, 2), "fictional", "", time.Minute, time.Millisecond}
+
+	if err := fossSubmit(gocontext.Background(), ctx, "testdata/notice/application.meta_lic"); err != nil {
+		t.Fatalf("fossubmit: error = %v, stderr = %v", err, stderr)
+	}
+	var findings []compliance.FossologyFinding
+	if err := json.Unmarshal(stdout.Bytes(), &findings); err != nil {
+		t.Fatalf("fossubmit: got invalid findings %q: %s", stdout, err)
+	}
+	if len(findings) != 1 || findings[0].FilePath != "testdata/notice/NOTICE_LICENSE" {
+		t.Errorf("fossubmit: got findings %+v, want the NOTICE_LICENSE finding", findings)
+	}
+
+	// Gives up when the scan does not finish in time.
+	ctx.stdout = &bytes.Buffer{}
+	ctx.client = fakeFossology(t, &uploaded, 1000)
+	ctx.wait = 10 * time.Millisecond
+	err := fossSubmit(gocontext.Background(), ctx, "testdata/notice/application.meta_lic")
+	if err == nil || !strings.Contains(err.Error(), "scan not finished") {
+		t.Errorf("fossubmit: got error %v, want scan not finished", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance/fixturegen"
+)
+
+type context struct {
+	stdout io.Writer
+	stderr io.Writer
+	opts   fixturegen.Options
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} -o dir
+
+Generates a synthetic tree of license metadata files and license texts under
+dir for tests and performance measurements. The root of the tree is
+dir/%s, a container installing system.img.
+
+Reference the generated files from the current directory the same way as
+dir, e.g. textnotice dir/%s
+
+Options:
+`, filepath.Base(os.Args[0]), fixturegen.Root, fixturegen.Root)
+		flags.PrintDefaults()
+	}
+
+	outDir := flags.String("o", "", "The directory in which to generate the fixtures.")
+	nodes := flags.Int("nodes", 10, "The number of targets in the tree including the root.")
+	conditions := flags.String("conditions", "notice", "Comma-separated license conditions assigned randomly to targets.")
+	depth := flags.Int("depth", 3, "The maximum number of edges from the root to any target.")
+	seed := flags.Int64("seed", 0, "The seed for the random choices; the same seed and options generate the same tree.")
+
+	flags.Parse(expandedArgs)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outDir) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify directory for -o\n")
+		os.Exit(2)
+	}
+
+	ctx := &context{os.Stdout, os.Stderr, fixturegen.Options{
+		Nodes:      *nodes,
+		Conditions: strings.Split(*conditions, ","),
+		Depth:      *depth,
+		Seed:       *seed,
+		Dir:        filepath.ToSlash(*outDir),
+	}}
+
+	err := genFixtures(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// genFixtures implements the genfixtures utility.
+func genFixtures(ctx *context) error {
+	files, err := fixturegen.Generate(ctx.opts)
+	if err != nil {
+		return fmt.Errorf("Unable to generate fixtures: %v\n", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.FromSlash(name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return fmt.Errorf("Unable to create directory for %q: %v\n", path, err)
+		}
+		if err := os.WriteFile(path, files[name], 0666); err != nil {
+			return fmt.Errorf("Unable to write %q: %v\n", path, err)
+		}
+	}
+	fmt.Fprintf(ctx.stdout, "%s\n", filepath.Join(filepath.FromSlash(ctx.opts.Dir), fixturegen.Root))
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/fixturegen"
+)
+
+func Test(t *testing.T) {
+	dir := filepath.ToSlash(t.TempDir())
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := context{stdout, stderr, fixturegen.Options{
+		Nodes:      10,
+		Conditions: []string{"notice", "reciprocal"},
+		Depth:      2,
+		Seed:       1,
+		Dir:        dir,
+	}}
+
+	if err := genFixtures(&ctx); err != nil {
+		t.Fatalf("genfixtures: error = %v, stderr = %v", err, stderr)
+	}
+	root := strings.TrimSpace(stdout.String())
+	if root != filepath.Join(dir, fixturegen.Root) {
+		t.Errorf("genfixtures: got root %q, want %q", root, filepath.Join(dir, fixturegen.Root))
+	}
+
+	// The generated files reference each other from the current directory.
+	lg, err := compliance.ReadLicenseGraph(compliance.FS, stderr, []string{root})
+	if err != nil {
+		t.Fatalf("genfixtures: cannot read generated graph: %v, stderr = %v", err, stderr)
+	}
+	if len(lg.Targets()) != 10 {
+		t.Errorf("genfixtures: got %d targets, want 10", len(lg.Targets()))
+	}
+	ni, err := compliance.IndexLicenseTexts(compliance.FS, lg, compliance.ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("genfixtures: cannot read generated license texts: %v", err)
+	}
+	libs := len(ni.AllLibraries())
+	if libs != 10 {
+		t.Errorf("genfixtures: got %d libraries in the notice, want 10", libs)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context{&bytes.Buffer{}, &bytes.Buffer{}, fixturegen.Options{
+		Nodes:      10,
+		Conditions: []string{"unknown"},
+		Depth:      2,
+		Dir:        filepath.ToSlash(t.TempDir()),
+	}}
+	if err := genFixtures(&ctx); err == nil {
+		t.Errorf("genfixtures: got no error for an unknown condition, want error")
+	}
+}
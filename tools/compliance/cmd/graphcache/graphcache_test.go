@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// edgeStrings returns the sorted edges of `lg` as target -> dependency with
+// the sorted annotations.
+func edgeStrings(lg *compliance.LicenseGraph) []string {
+	var result []string
+	for _, e := range lg.Edges() {
+		annotations := e.Annotations().AsList()
+		sort.Strings(annotations)
+		result = append(result, fmt.Sprintf("%s -%v> %s", e.Target().Name(), annotations, e.Dependency().Name()))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition string
+		roots     []string
+	}{
+		{"firstparty", []string{"highest.apex.meta_lic"}},
+		{"notice", []string{"container.zip.meta_lic"}},
+		{"reciprocal", []string{"application.meta_lic"}},
+		{"restricted", []string{"container.zip.meta_lic", "highest.apex.meta_lic"}},
+		{"proprietary", []string{"bin/bin2.meta_lic", "lib/libb.so.meta_lic"}},
+		{"regressspdx", []string{"bin/bin1.meta_lic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			rootFiles := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+			}
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			var deps []string
+			outputFile := filepath.Join(t.TempDir(), "graph.pb")
+			ctx := context{stdout, stderr, compliance.GetFS(""), outputFile, &deps}
+			if err := graphCache(&ctx, rootFiles...); err != nil {
+				t.Fatalf("graphcache: error = %v, stderr = %v", err, stderr)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("graphcache: gotStderr = %v, want none", stderr)
+			}
+
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("graphcache: cannot read output: %v", err)
+			}
+			loaded, err := compliance.UnmarshalGraph(data)
+			if err != nil {
+				t.Fatalf("graphcache: cannot load output: %v", err)
+			}
+			read, err := compliance.ReadLicenseGraph(compliance.GetFS(""), stderr, rootFiles)
+			if err != nil {
+				t.Fatalf("graphcache: cannot read graph: %v", err)
+			}
+
+			actualTargets, expectedTargets := loaded.TargetNames(), read.TargetNames()
+			sort.Strings(actualTargets)
+			sort.Strings(expectedTargets)
+			if !reflect.DeepEqual(actualTargets, expectedTargets) {
+				t.Errorf("graphcache: got targets %q, want %q", actualTargets, expectedTargets)
+			}
+			if actual, expected := edgeStrings(loaded), edgeStrings(read); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("graphcache: got edges %q, want %q", actual, expected)
+			}
+			sort.Strings(deps)
+			if !reflect.DeepEqual(deps, expectedTargets) {
+				t.Errorf("graphcache: got deps %q, want %q", deps, expectedTargets)
+			}
+		})
+	}
+}
+
+func TestNoRoots(t *testing.T) {
+	var deps []string
+	ctx := context{&bytes.Buffer{}, &bytes.Buffer{}, compliance.GetFS(""), filepath.Join(t.TempDir(), "graph.pb"), &deps}
+	if err := graphCache(&ctx); err != failNoneRequested {
+		t.Errorf("graphcache: got error %v, want %v", err, failNoneRequested)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failWrongArgs  = fmt.Errorf("\nExpected base and new root license metadata files")
+	failNoLicenses = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout io.Writer
+	stderr io.Writer
+	baseFS fs.FS
+	newFS  fs.FS
+	// conditionsOnly reports only the changed license conditions.
+	conditionsOnly bool
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(2)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(2)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} -base base.meta_lic {-base base.meta_lic...} -new new.meta_lic {-new new.meta_lic...}
+
+Outputs the differences between the license graph read from the base roots
+and the license graph read from the new roots, one per line ordered by target:
+the added and removed targets, and for targets in both graphs the changed
+license conditions, license kinds, dependency edges and edge annotations.
+
+Exits 0 when the graphs match, 1 when they differ, and 2 on error.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	baseRoots := newMultiString(flags, "base", "A root license metadata file of the base graph. (multiple allowed)")
+	newRoots := newMultiString(flags, "new", "A root license metadata file of the new graph. (multiple allowed)")
+	baseDir := flags.String("base_dir", "", "The directory from which to read the base graph. (default current directory)")
+	newDir := flags.String("new_dir", "", "The directory from which to read the new graph. (default current directory)")
+	conditionsOnly := flags.Bool("conditions_only", false, "Whether to report only the targets whose license conditions changed.")
+	outputFile := flags.String("o", "-", "Where to write the differences. (default stdout)")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root of each graph.
+	if flags.NArg() != 0 || len(*baseRoots) == 0 || len(*newRoots) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	var baseFS, newFS fs.FS
+	baseFS, newFS = compliance.FS, compliance.FS
+	if len(*baseDir) > 0 {
+		baseFS = compliance.GetFS(*baseDir)
+	}
+	if len(*newDir) > 0 {
+		newFS = compliance.GetFS(*newDir)
+	}
+
+	ctx := &context{ofile, os.Stderr, baseFS, newFS, *conditionsOnly}
+
+	differ, err := graphDiff(ctx, *baseRoots, *newRoots)
+	if err != nil {
+		if err == failWrongArgs {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(2)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(2)
+		}
+	}
+	if differ {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// targetEdges maps the dependencies of a target to the annotations of the
+// edges to them.
+type targetEdges map[string]string
+
+// edgesOf returns the dependencies of `tn` with their edge annotations.
+func edgesOf(tn *compliance.TargetNode) targetEdges {
+	annotations := make(map[string][]string)
+	for _, e := range tn.Dependencies() {
+		dep := e.Dependency().Name()
+		annotations[dep] = append(annotations[dep], strings.Join(e.Annotations().AsList(), ":"))
+	}
+	edges := make(targetEdges)
+	for dep, anns := range annotations {
+		sort.Strings(anns)
+		edges[dep] = strings.Join(anns, "|")
+	}
+	return edges
+}
+
+// graphDiff implements the graphdiff utility returning true when the graphs
+// differ.
+func graphDiff(ctx *context, baseRoots, newRoots []string) (bool, error) {
+	// Must be at least one root of each graph.
+	if len(baseRoots) == 0 || len(newRoots) == 0 {
+		return false, failWrongArgs
+	}
+
+	base, err := readGraph(ctx.baseFS, ctx.stderr, baseRoots)
+	if err != nil {
+		return false, fmt.Errorf("Unable to read base license metadata file(s) %q: %v\n", baseRoots, err)
+	}
+	after, err := readGraph(ctx.newFS, ctx.stderr, newRoots)
+	if err != nil {
+		return false, fmt.Errorf("Unable to read new license metadata file(s) %q: %v\n", newRoots, err)
+	}
+
+	targets := make(map[string]struct{})
+	for name := range base {
+		targets[name] = struct{}{}
+	}
+	for name := range after {
+		targets[name] = struct{}{}
+	}
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	differ := false
+	report := func(format string, args ...interface{}) {
+		fmt.Fprintf(ctx.stdout, format+"\n", args...)
+		differ = true
+	}
+	for _, name := range names {
+		b, inBase := base[name]
+		a, inNew := after[name]
+		if !inNew {
+			if !ctx.conditionsOnly {
+				report("removed %s", name)
+			}
+			continue
+		}
+		if !inBase {
+			if !ctx.conditionsOnly {
+				report("added %s", name)
+			}
+			continue
+		}
+		if b.LicenseConditions() != a.LicenseConditions() {
+			report("%s: conditions %s -> %s", name, conditionNames(b.LicenseConditions()), conditionNames(a.LicenseConditions()))
+		}
+		if ctx.conditionsOnly {
+			continue
+		}
+		if bk, ak := sortedKinds(b), sortedKinds(a); bk != ak {
+			report("%s: license kinds %s -> %s", name, bk, ak)
+		}
+
+		bEdges, aEdges := edgesOf(b), edgesOf(a)
+		deps := make([]string, 0, len(bEdges)+len(aEdges))
+		for dep := range bEdges {
+			deps = append(deps, dep)
+		}
+		for dep := range aEdges {
+			if _, ok := bEdges[dep]; !ok {
+				deps = append(deps, dep)
+			}
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			bAnn, inBase := bEdges[dep]
+			aAnn, inNew := aEdges[dep]
+			switch {
+			case !inNew:
+				report("%s: removed edge -[%s]> %s", name, bAnn, dep)
+			case !inBase:
+				report("%s: added edge -[%s]> %s", name, aAnn, dep)
+			case bAnn != aAnn:
+				report("%s: annotations -> %s: %s -> %s", name, dep, bAnn, aAnn)
+			}
+		}
+	}
+	return differ, nil
+}
+
+// readGraph reads the license graph from `roots` in `rootFS` and returns its
+// targets by name.
+func readGraph(rootFS fs.FS, stderr io.Writer, roots []string) (map[string]*compliance.TargetNode, error) {
+	licenseGraph, err := compliance.ReadLicenseGraph(rootFS, stderr, roots)
+	if err != nil {
+		return nil, err
+	}
+	if licenseGraph == nil {
+		return nil, failNoLicenses
+	}
+	targets := make(map[string]*compliance.TargetNode)
+	for _, tn := range licenseGraph.Targets() {
+		targets[tn.Name()] = tn
+	}
+	return targets, nil
+}
+
+// conditionNames returns the names of the conditions in `cs` joined by "|".
+func conditionNames(cs compliance.LicenseConditionSet) string {
+	return strings.Join(cs.Names(), "|")
+}
+
+// sortedKinds returns the license kinds of `tn` sorted and joined by "|".
+func sortedKinds(tn *compliance.TargetNode) string {
+	kinds := append([]string{}, tn.LicenseKinds()...)
+	sort.Strings(kinds)
+	return strings.Join(kinds, "|")
+}
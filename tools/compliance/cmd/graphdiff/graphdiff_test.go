@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+// writeGraph writes license metadata files under a new temporary directory
+// for targets given as "name:kind:condition:dep1/annotation,dep2" strings,
+// and returns the directory.
+func writeGraph(t *testing.T, targets ...string) string {
+	dir := t.TempDir()
+	for _, target := range targets {
+		fields := strings.SplitN(target, ":", 4)
+		var b strings.Builder
+		b.WriteString("package_name: \"" + fields[0] + "\"\n")
+		b.WriteString("license_kinds: \"" + fields[1] + "\"\n")
+		b.WriteString("license_conditions: \"" + fields[2] + "\"\n")
+		if len(fields[3]) > 0 {
+			for _, dep := range strings.Split(fields[3], ",") {
+				file, annotation, _ := strings.Cut(dep, "/")
+				b.WriteString("deps: {\n  file: \"" + file + ".meta_lic\"\n")
+				if len(annotation) > 0 {
+					b.WriteString("  annotations: \"" + annotation + "\"\n")
+				}
+				b.WriteString("}\n")
+			}
+		}
+		err := os.WriteFile(filepath.Join(dir, fields[0]+".meta_lic"), []byte(b.String()), 0666)
+		if err != nil {
+			t.Fatalf("cannot write license metadata: %v", err)
+		}
+	}
+	return dir
+}
+
+func Test(t *testing.T) {
+	base := []string{
+		"bin:Apache-2.0:notice:liba/static,libb/dynamic",
+		"liba:MIT:notice:",
+		"libb:BSD:notice:",
+	}
+	tests := []struct {
+		name           string
+		newGraph       []string
+		conditionsOnly bool
+		expectedOut    []string
+	}{
+		{
+			name:     "unchanged",
+			newGraph: base,
+		},
+		{
+			name: "relicensed",
+			newGraph: []string{
+				"bin:Apache-2.0:notice:liba/static,libb/dynamic",
+				"liba:GPL-2.0:restricted:",
+				"libb:BSD:notice:",
+			},
+			expectedOut: []string{
+				"liba.meta_lic: conditions notice -> restricted",
+				"liba.meta_lic: license kinds MIT -> GPL-2.0",
+			},
+		},
+		{
+			name: "edges",
+			newGraph: []string{
+				"bin:Apache-2.0:notice:liba/dynamic,libc/static",
+				"liba:MIT:notice:",
+				"libc:BSD:notice:",
+			},
+			expectedOut: []string{
+				"bin.meta_lic: annotations -> liba.meta_lic: static -> dynamic",
+				"bin.meta_lic: removed edge -[dynamic]> libb.meta_lic",
+				"bin.meta_lic: added edge -[static]> libc.meta_lic",
+				"removed libb.meta_lic",
+				"added libc.meta_lic",
+			},
+		},
+		{
+			name: "conditions_only",
+			newGraph: []string{
+				"bin:Apache-2.0:notice:liba/static,libc/static",
+				"liba:GPL-2.0:restricted:",
+				"libc:BSD:notice:",
+			},
+			conditionsOnly: true,
+			expectedOut: []string{
+				"liba.meta_lic: conditions notice -> restricted",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir := writeGraph(t, base...)
+			newDir := writeGraph(t, tt.newGraph...)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			ctx := context{stdout, stderr, compliance.GetFS(baseDir), compliance.GetFS(newDir), tt.conditionsOnly}
+
+			differ, err := graphDiff(&ctx, []string{"bin.meta_lic"}, []string{"bin.meta_lic"})
+			if err != nil {
+				t.Fatalf("graphdiff: error = %v, stderr = %v", err, stderr)
+			}
+			if differ != (len(tt.expectedOut) > 0) {
+				t.Errorf("graphdiff: got differ = %v, want %v", differ, len(tt.expectedOut) > 0)
+			}
+			var actual []string
+			if stdout.Len() > 0 {
+				actual = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			}
+			if !reflect.DeepEqual(actual, tt.expectedOut) {
+				t.Errorf("graphdiff: got %q, want %q", actual, tt.expectedOut)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := writeGraph(t, "bin:Apache-2.0:notice:")
+	ctx := context{&bytes.Buffer{}, &bytes.Buffer{}, compliance.GetFS(dir), compliance.GetFS(dir), false}
+
+	if _, err := graphDiff(&ctx, []string{"bin.meta_lic"}, nil); err != failWrongArgs {
+		t.Errorf("graphdiff: got error %v without new roots, want %v", err, failWrongArgs)
+	}
+	if _, err := graphDiff(&ctx, []string{"bin.meta_lic"}, []string{"missing.meta_lic"}); err == nil {
+		t.Errorf("graphdiff: got no error for a missing root, want error")
+	}
+}
@@ -17,6 +17,9 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
+	gocontext "context"
+	"crypto/md5"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"html"
@@ -24,8 +27,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"android/soong/response"
 	"android/soong/tools/compliance"
@@ -38,31 +43,78 @@ var (
 	failNoLicenses    = fmt.Errorf("No licenses found")
 )
 
+var (
+	// productOutPrefix matches the product out directory of install paths.
+	productOutPrefix = regexp.MustCompile(`(^|/)target/product/[^/]+/`)
+
+	// partitions lists the partitions getting their own notice file.
+	partitions = map[string]struct{}{
+		"odm":         {},
+		"odm_dlkm":    {},
+		"product":     {},
+		"system":      {},
+		"system_dlkm": {},
+		"system_ext":  {},
+		"vendor":      {},
+		"vendor_dlkm": {},
+	}
+)
+
 type context struct {
-	stdout      io.Writer
-	stderr      io.Writer
-	rootFS      fs.FS
-	includeTOC  bool
-	product     string
-	stripPrefix []string
-	title       string
-	deps        *[]string
+	stdout       io.Writer
+	stderr       io.Writer
+	rootFS       fs.FS
+	includeTOC   bool
+	collapsible  bool
+	product      string
+	stripPrefix  []string
+	title        []string
+	mergeSimilar float64
+	showSpdx     bool
+	// partitionOutput names the directory for per-partition notices or is
+	// empty to write a single notice to stdout.
+	partitionOutput  string
+	unknownPartition string
+	gzip             bool
+	skipBuildtime    bool
+	progress         compliance.ProgressFunc
+	// maxSize limits the size in bytes of the notice before splitting it
+	// into parts next to outputFile or is 0 for no limit.
+	maxSize    int64
+	outputFile string
+	// jsonIndex receives a JSON index of the rendered libraries or is nil.
+	jsonIndex io.Writer
+	// verbose traces the edges walked resolving the notice conditions.
+	verbose bool
+	// overrides replace the license conditions of targets per -overrides_file.
+	overrides []compliance.Override
+	// delta applies -baseline and -write_baseline to the notice index.
+	delta *compliance.NoticeDelta
+	// missing applies -report_missing and -missing_fatal to the notice index.
+	missing *compliance.MissingTextReport
+	// emptyText selects how to index empty license texts per -empty_text.
+	emptyText compliance.EmptyTextMode
+	// keepGoing reports every malformed license metadata file per -keep_going.
+	keepGoing bool
+	deps      *[]string
 }
 
 func (ctx context) strip(installPath string) string {
-	for _, prefix := range ctx.stripPrefix {
-		if strings.HasPrefix(installPath, prefix) {
-			p := strings.TrimPrefix(installPath, prefix)
-			if 0 == len(p) {
-				p = ctx.product
-			}
-			if 0 == len(p) {
-				continue
-			}
-			return p
-		}
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// partition returns the partition to which `installPath` installs or
+// ctx.unknownPartition when not installed to a known partition.
+func (ctx context) partition(installPath string) string {
+	loc := productOutPrefix.FindStringIndex(installPath)
+	if loc == nil {
+		return ctx.unknownPartition
 	}
-	return installPath
+	p := strings.SplitN(installPath[loc[1]:], "/", 2)[0]
+	if _, ok := partitions[p]; !ok {
+		return ctx.unknownPartition
+	}
+	return p
 }
 
 // newMultiString creates a flag that allows multiple values in an array.
@@ -106,7 +158,18 @@ func main() {
 		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
 
 Outputs an html NOTICE.html or gzipped NOTICE.html.gz file if the -o filename
-ends with ".gz".
+ends with ".gz" or if -gzip is given.
+
+With -max_size, when the notice would exceed the given number of bytes, writes
+the license texts into numbered NOTICE-<n>.html parts next to the -o file
+instead, keeping each library section whole, and writes an index page linking
+to the parts to the -o file.
+
+With -partition_output, writes a NOTICE_<partition>.html file into the given
+directory for each of the system, vendor, odm etc. partitions instead. Each
+file lists only the libraries with at least one install path in the
+partition. Install paths outside any known partition go to the file for the
+-unknown_partition bucket.
 
 Options:
 `, filepath.Base(os.Args[0]))
@@ -116,9 +179,31 @@ Options:
 	outputFile := flags.String("o", "-", "Where to write the NOTICE text file. (default stdout)")
 	depsFile := flags.String("d", "", "Where to write the deps file")
 	includeTOC := flags.Bool("toc", true, "Whether to include a table of contents.")
+	collapsible := flags.Bool("collapsible", false, "Whether to wrap each license text section in a collapsible <details> element.")
+	gzipOutput := flags.Bool("gzip", false, "Whether to gzip the output. (implied when -o ends with \".gz\")")
 	product := flags.String("product", "", "The name of the product for which the notice is generated.")
 	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
-	title := flags.String("title", "", "The title of the notice file.")
+	baseline := flags.String("baseline", "", "A baseline of license text hashes per library from a prior release. Only libraries with new or changed license texts appear in the notice, and removed libraries are listed on stderr.")
+	writeBaseline := flags.String("write_baseline", "", "Where to write the baseline of license text hashes per library for use with -baseline in the next release.")
+	reportMissing := flags.String("report_missing", "", "Where to write the shipped targets contributing no license text to the notice with their license conditions and install paths.")
+	missingFatal := flags.Bool("missing_fatal", false, "Whether to fail when any shipped target contributes no license text to the notice.")
+	emptyTextName := flags.String("empty_text", "keep", "How to treat license text files that are empty or only whitespace after warning about them: skip, placeholder or keep.")
+	keepGoing := flags.Bool("keep_going", false, "Report every malformed license metadata file, up to 100, instead of stopping at the first.")
+	zipFile := flags.String("zip", "", "Read the license metadata, license texts and METADATA files from this zip archive, or from a directory in it given as archive.zip!/dir.")
+	overridesFile := flags.String("overrides_file", "", "A YAML file of {path, condition} entries replacing the license conditions declared by the named license metadata files.")
+	title := newMultiString(flags, "title", "The title of the notice file. (multiple allowed; one heading line each)")
+	mergeSimilar := flags.Float64("merge_similar", 0, "Merge license texts at least this similar (0.0 to 1.0) into one section. e.g. 0.9 (default 0 means no merging)")
+	partitionOutput := flags.String("partition_output", "", "Directory in which to write one NOTICE_<partition>.html file per partition instead of -o.")
+	skipBuildtime := flags.Bool("skip_buildtime", false, "Whether to ignore dependencies annotated \"buildtime\" or \"test\" so their licenses do not apply.")
+	showProgress := flags.Bool("progress", false, "Whether to report progress to stderr at most once per second.")
+	showSpdx := flags.Bool("show_spdx", false, "Whether to show the SPDX license identifiers of each library under its heading.")
+	unknownPartition := flags.String("unknown_partition", "unknown", "The partition name for install paths outside any known partition with -partition_output.")
+
+	jsonIndex := flags.String("json_index", "", "Where to write a JSON index of the libraries, license texts, and anchors in the notice.")
+	var verbose bool
+	flags.BoolVar(&verbose, "v", false, "Whether to trace each edge walked resolving the license conditions on stderr.")
+	flags.BoolVar(&verbose, "verbose", false, "Same as -v.")
+	maxSize := flags.Int64("max_size", 0, "Split the notice into numbered parts next to -o plus an index page at -o when it would exceed this many bytes. (default 0 means no limit)")
 
 	flags.Parse(expandedArgs)
 
@@ -128,6 +213,42 @@ Options:
 		os.Exit(2)
 	}
 
+	if *mergeSimilar < 0 || *mergeSimilar > 1 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-merge_similar must be between 0.0 and 1.0\n")
+		os.Exit(2)
+	}
+
+	if len(*partitionOutput) > 0 && *outputFile != "-" {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "cannot specify both -o and -partition_output\n")
+		os.Exit(2)
+	}
+
+	if *maxSize < 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-max_size must not be negative\n")
+		os.Exit(2)
+	}
+
+	if *maxSize > 0 && (*outputFile == "-" || len(*partitionOutput) > 0) {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-max_size requires -o and cannot be combined with -partition_output\n")
+		os.Exit(2)
+	}
+
+	if len(*jsonIndex) > 0 && len(*partitionOutput) > 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "cannot specify both -json_index and -partition_output\n")
+		os.Exit(2)
+	}
+
+	if len(*unknownPartition) == 0 || strings.ContainsAny(*unknownPartition, "/\\") {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-unknown_partition must be a non-empty file name component\n")
+		os.Exit(2)
+	}
+
 	if len(*outputFile) == 0 {
 		flags.Usage()
 		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
@@ -158,15 +279,85 @@ Options:
 		ofile = obuf
 	}
 	if strings.HasSuffix(*outputFile, ".gz") {
-		ofile, _ = gzip.NewWriterLevel(obuf, gzip.BestCompression)
-		closer = ofile.(io.Closer)
+		*gzipOutput = true
+	}
+	if *gzipOutput {
+		gz := newGzipWriter(ofile)
+		ofile = gz
+		closer = gz
 	}
 
 	var deps []string
 
-	ctx := &context{ofile, os.Stderr, compliance.FS, *includeTOC, *product, *stripPrefix, *title, &deps}
+	var jsonBuf *bytes.Buffer
+	var jsonWriter io.Writer
+	if len(*jsonIndex) > 0 {
+		jsonBuf = &bytes.Buffer{}
+		jsonWriter = jsonBuf
+	}
+
+	var progress compliance.ProgressFunc
+	if *showProgress {
+		progress = compliance.NewThrottledProgress(os.Stderr, time.Second).Report
+	}
+
+	var overrides []compliance.Override
+	if len(*overridesFile) > 0 {
+		var err error
+		overrides, err = compliance.ReadOverridesFile(*overridesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read overrides file %q: %s\n", *overridesFile, err)
+			os.Exit(1)
+		}
+	}
 
-	err := htmlNotice(ctx, flags.Args()...)
+	emptyText, err := compliance.ParseEmptyTextMode(*emptyTextName)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	var rootFS fs.FS = compliance.FS
+	var zipFS *compliance.ZipFS
+	if len(*zipFile) > 0 {
+		zipFS, err = compliance.OpenZipFS(*zipFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *zipFile, err)
+			os.Exit(1)
+		}
+		rootFS = zipFS
+	}
+
+	ctx := &context{
+		stdout:           ofile,
+		stderr:           os.Stderr,
+		rootFS:           rootFS,
+		includeTOC:       *includeTOC,
+		collapsible:      *collapsible,
+		product:          *product,
+		stripPrefix:      *stripPrefix,
+		title:            *title,
+		mergeSimilar:     *mergeSimilar,
+		showSpdx:         *showSpdx,
+		partitionOutput:  *partitionOutput,
+		unknownPartition: *unknownPartition,
+		gzip:             *gzipOutput,
+		skipBuildtime:    *skipBuildtime,
+		progress:         progress,
+		maxSize:          *maxSize,
+		outputFile:       *outputFile,
+		jsonIndex:        jsonWriter,
+		verbose:          verbose,
+		overrides:        overrides,
+		delta:            &compliance.NoticeDelta{Baseline: *baseline, WriteBaseline: *writeBaseline},
+		missing:          &compliance.MissingTextReport{File: *reportMissing, Fatal: *missingFatal},
+		emptyText:        emptyText,
+		keepGoing:        *keepGoing,
+		deps:             &deps,
+	}
+
+	err = htmlNotice(ctx, flags.Args()...)
 	if err != nil {
 		if err == failNoneRequested {
 			flags.Usage()
@@ -175,7 +366,10 @@ Options:
 		os.Exit(1)
 	}
 	if closer != nil {
-		closer.Close()
+		if err := closer.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not compress output: %s\n", err)
+			os.Exit(1)
+		}
 	}
 
 	if *outputFile != "-" {
@@ -185,8 +379,29 @@ Options:
 			os.Exit(1)
 		}
 	}
+	if jsonBuf != nil {
+		err := os.WriteFile(*jsonIndex, jsonBuf.Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write json index to %q: %s\n", *jsonIndex, err)
+			os.Exit(1)
+		}
+	}
 	if *depsFile != "" {
-		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		if zipFS != nil {
+			// The files read are inside the archive.
+			deps = []string{zipFS.Archive()}
+		}
+		if len(*baseline) > 0 {
+			deps = append(deps, *baseline)
+		}
+		if len(*overridesFile) > 0 {
+			deps = append(deps, *overridesFile)
+		}
+		target := *outputFile
+		if len(*partitionOutput) > 0 {
+			target = *partitionOutput
+		}
+		err := deptools.WriteDepFile(*depsFile, target, deps)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
 			os.Exit(1)
@@ -195,6 +410,16 @@ Options:
 	os.Exit(0)
 }
 
+// newGzipWriter returns a writer compressing to `w` with a fixed gzip header
+// so identical input always yields identical output bytes.
+func newGzipWriter(w io.Writer) *gzip.Writer {
+	gz, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+	// Leave the name, comment and modification time empty, and record an
+	// unknown OS, so the header does not depend on the host or build time.
+	gz.Header = gzip.Header{OS: 255}
+	return gz
+}
+
 // htmlNotice implements the htmlnotice utility.
 func htmlNotice(ctx *context, files ...string) error {
 	// Must be at least one root file.
@@ -202,82 +427,497 @@ func htmlNotice(ctx *context, files ...string) error {
 		return failNoneRequested
 	}
 
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
 	// Read the license graph from the license metadata files (*.meta_lic).
-	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	licenseGraph, err := compliance.ReadLicenseGraphWithOptions(gocontext.Background(), rootFS, ctx.stderr, files, compliance.ReadOptions{Progress: ctx.progress, KeepGoing: ctx.keepGoing})
 	if err != nil {
 		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
 	}
 	if licenseGraph == nil {
 		return failNoLicenses
 	}
+	if len(ctx.overrides) > 0 {
+		licenseGraph = compliance.ApplyOverrides(licenseGraph, ctx.overrides)
+	}
+	if ctx.skipBuildtime {
+		licenseGraph.SkipBuildtimeDeps()
+	}
+	if ctx.verbose {
+		licenseGraph.TraceEdges(func(edge *compliance.TargetEdge, conditions compliance.LicenseConditionSet) {
+			for _, name := range conditions.Names() {
+				fmt.Fprintf(ctx.stderr, "TRACE: %s --[%s]--> %s\n", edge.Target().Name(), name, edge.Dependency().Name())
+			}
+		})
+	}
 
 	// rs contains all notice resolutions.
 	rs := compliance.ResolveNotices(licenseGraph)
 
-	ni, err := compliance.IndexLicenseTexts(ctx.rootFS, licenseGraph, rs)
+	ni, err := compliance.IndexLicenseTextsWithOptions(gocontext.Background(), rootFS, licenseGraph, rs, compliance.IndexOptions{EmptyText: ctx.emptyText})
 	if err != nil {
 		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
 	}
+	compliance.WarnEmptyTexts(ctx.stderr, ni)
+	if ctx.mergeSimilar > 0 {
+		ni.MergeSimilarTexts(ctx.mergeSimilar)
+	}
+	if err := ctx.delta.Apply(ni, ctx.stderr); err != nil {
+		return err
+	}
+	if err := ctx.missing.Apply(ni); err != nil {
+		return err
+	}
+
+	*ctx.deps = rootFS.Files()
+
+	if len(ctx.partitionOutput) > 0 {
+		return writePartitionNotices(ctx, ni)
+	}
+	if ctx.jsonIndex != nil {
+		_, sections, err := noticeSections(ctx, ni, "")
+		if err != nil {
+			return err
+		}
+		err = writeJSONIndex(ctx, ctx.jsonIndex, sections)
+		if err != nil {
+			return fmt.Errorf("could not write json index: %w", err)
+		}
+	}
+	if ctx.maxSize > 0 {
+		return writeSplitNotice(ctx, ni)
+	}
+	return writeNotice(ctx, ctx.stdout, ni, "")
+}
+
+// writePartitionNotices writes a notice file for each partition with at least
+// one install path into the ctx.partitionOutput directory.
+func writePartitionNotices(ctx *context, ni *compliance.NoticeIndex) error {
+	found := make(map[string]struct{})
+	for _, installPath := range ni.AllInstallPaths() {
+		found[ctx.partition(installPath)] = struct{}{}
+	}
+	names := make([]string, 0, len(found))
+	for p := range found {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+
+	err := os.MkdirAll(ctx.partitionOutput, 0777)
+	if err != nil {
+		return fmt.Errorf("could not create directory %q: %w", ctx.partitionOutput, err)
+	}
+	for _, p := range names {
+		fname := filepath.Join(ctx.partitionOutput, "NOTICE_"+p+".html")
+		obuf := &bytes.Buffer{}
+		if ctx.gzip {
+			fname += ".gz"
+			gz := newGzipWriter(obuf)
+			if err := writeNotice(ctx, gz, ni, p); err != nil {
+				return err
+			}
+			if err := gz.Close(); err != nil {
+				return fmt.Errorf("could not compress output for %q: %w", fname, err)
+			}
+		} else if err := writeNotice(ctx, obuf, ni, p); err != nil {
+			return err
+		}
+		err := os.WriteFile(fname, obuf.Bytes(), 0666)
+		if err != nil {
+			return fmt.Errorf("could not write output to %q: %w", fname, err)
+		}
+	}
+	return nil
+}
+
+// writeNotice writes the html notice for `ni` to `w` including only the
+// install paths in `partition` unless empty.
+func writeNotice(ctx *context, w io.Writer, ni *compliance.NoticeIndex, partition string) error {
+	installPaths, sections, err := noticeSections(ctx, ni, partition)
+	if err != nil {
+		return err
+	}
 
-	fmt.Fprintln(ctx.stdout, "<!DOCTYPE html>")
-	fmt.Fprintln(ctx.stdout, "<html><head>")
-	fmt.Fprintln(ctx.stdout, "<style type=\"text/css\">")
-	fmt.Fprintln(ctx.stdout, "body { padding: 2px; margin: 0; }")
-	fmt.Fprintln(ctx.stdout, "ul { list-style-type: none; margin: 0; padding: 0; }")
-	fmt.Fprintln(ctx.stdout, "li { padding-left: 1em; }")
-	fmt.Fprintln(ctx.stdout, ".file-list { margin-left: 1em; }")
-	fmt.Fprintln(ctx.stdout, "</style>")
+	writeHead(ctx, w)
+	var ids map[string]string
+	if ctx.includeTOC {
+		ids = stableIDs("id", installPaths, anchorDigits)
+		writeTOC(ctx, w, ni, installPaths, ids, func(string) string { return "" })
+	}
+	for i, section := range sections {
+		section.write(w, ids, "")
+		if ctx.progress != nil {
+			ctx.progress(compliance.ProgressEmitSections, i+1, len(sections))
+		}
+	}
+	fmt.Fprintln(w, "</body></html>")
+	return nil
+}
+
+// writeHead writes the start of an html notice document through the headings.
+func writeHead(ctx *context, w io.Writer) {
+	fmt.Fprintln(w, "<!DOCTYPE html>")
+	fmt.Fprintln(w, "<html><head>")
+	fmt.Fprintln(w, "<style type=\"text/css\">")
+	fmt.Fprintln(w, "body { padding: 2px; margin: 0; }")
+	fmt.Fprintln(w, "ul { list-style-type: none; margin: 0; padding: 0; }")
+	fmt.Fprintln(w, "li { padding-left: 1em; }")
+	fmt.Fprintln(w, ".file-list { margin-left: 1em; }")
+	if ctx.collapsible {
+		fmt.Fprintln(w, "summary { cursor: pointer; }")
+	}
+	if ctx.showSpdx {
+		fmt.Fprintln(w, ".spdx-id { font-family: monospace; border: 1px solid #888; border-radius: 3px; padding: 0 3px; }")
+	}
+	fmt.Fprintln(w, "</style>")
 	if len(ctx.title) > 0 {
-		fmt.Fprintf(ctx.stdout, "<title>%s</title>\n", html.EscapeString(ctx.title))
+		fmt.Fprintf(w, "<title>%s</title>\n", html.EscapeString(strings.Join(ctx.title, " - ")))
 	} else if len(ctx.product) > 0 {
-		fmt.Fprintf(ctx.stdout, "<title>%s</title>\n", html.EscapeString(ctx.product))
+		fmt.Fprintf(w, "<title>%s</title>\n", html.EscapeString(ctx.product))
 	}
-	fmt.Fprintln(ctx.stdout, "</head>")
-	fmt.Fprintln(ctx.stdout, "<body>")
+	fmt.Fprintln(w, "</head>")
+	fmt.Fprintln(w, "<body>")
 
 	if len(ctx.title) > 0 {
-		fmt.Fprintf(ctx.stdout, "  <h1>%s</h1>\n", html.EscapeString(ctx.title))
+		for _, title := range ctx.title {
+			fmt.Fprintf(w, "  <h1>%s</h1>\n", html.EscapeString(title))
+		}
 	} else if len(ctx.product) > 0 {
-		fmt.Fprintf(ctx.stdout, "  <h1>%s</h1>\n", html.EscapeString(ctx.product))
+		fmt.Fprintf(w, "  <h1>%s</h1>\n", html.EscapeString(ctx.product))
 	}
-	ids := make(map[string]string)
-	if ctx.includeTOC {
-		fmt.Fprintln(ctx.stdout, "  <ul class=\"toc\">")
-		i := 0
-		for installPath := range ni.InstallPaths() {
-			id := fmt.Sprintf("id%d", i)
-			i++
-			ids[installPath] = id
-			fmt.Fprintf(ctx.stdout, "    <li id=\"%s\"><strong>%s</strong>\n      <ul>\n", id, html.EscapeString(ctx.strip(installPath)))
-			for _, h := range ni.InstallHashes(installPath) {
-				libs := ni.InstallHashLibs(installPath, h)
-				fmt.Fprintf(ctx.stdout, "        <li><a href=\"#%s\">%s</a>\n", h.String(), html.EscapeString(strings.Join(libs, ", ")))
+}
+
+// writeTOC writes the table of contents for `installPaths` to `w` using the
+// anchor IDs in `ids`. Links to license texts go to the document named by
+// `file` given the text's anchor, which is empty for the same document.
+func writeTOC(ctx *context, w io.Writer, ni *compliance.NoticeIndex, installPaths []string, ids map[string]string, file func(anchor string) string) {
+	fmt.Fprintln(w, "  <ul class=\"toc\">")
+	for _, installPath := range installPaths {
+		id := ids[installPath]
+		fmt.Fprintf(w, "    <li id=\"%s\"><strong>%s</strong>\n      <ul>\n", id, html.EscapeString(ctx.strip(installPath)))
+		for _, h := range ni.InstallHashes(installPath) {
+			libs := ni.InstallHashLibs(installPath, h)
+			fmt.Fprintf(w, "        <li><a href=\"%s#%s\">%s</a>\n", file(h.String()), h.String(), html.EscapeString(strings.Join(libs, ", ")))
+		}
+		fmt.Fprintln(w, "      </ul>")
+	}
+	fmt.Fprintln(w, "  </ul><!-- toc -->")
+}
+
+// noticeSection describes the section of a notice for one license text.
+type noticeSection struct {
+	// anchor identifies the license text within the notice.
+	anchor string
+	// libs lists the libraries using the license text.
+	libs []noticeLibrary
+	// write writes the section to `w` linking each install path with an
+	// anchor ID in `ids` to the table of contents in the document named
+	// `tocFile`, which is empty for the same document.
+	write func(w io.Writer, ids map[string]string, tocFile string)
+}
+
+// noticeLibrary describes a library in a noticeSection.
+type noticeLibrary struct {
+	// name is the library name.
+	name string
+	// id is the anchor ID of the library's usage list.
+	id string
+	// installPaths lists the install paths using the library.
+	installPaths []string
+	// licenseKinds lists the license kinds of the library.
+	licenseKinds []string
+}
+
+// noticeSections returns the install paths and the license text sections of
+// the notice for `ni` including only the install paths in `partition` unless
+// empty.
+func noticeSections(ctx *context, ni *compliance.NoticeIndex, partition string) ([]string, []noticeSection, error) {
+	inPartition := func(installPath string) bool {
+		return len(partition) == 0 || ctx.partition(installPath) == partition
+	}
+
+	var installPaths []string
+	for _, installPath := range ni.AllInstallPaths() {
+		if inPartition(installPath) {
+			installPaths = append(installPaths, installPath)
+		}
+	}
+
+	// libKey identifies the usage list of `libName` in the section for `h`.
+	libKey := func(h fmt.Stringer, libName string) string {
+		return h.String() + "/" + libName
+	}
+	var libKeys []string
+	for _, h := range ni.AllHashes() {
+		for _, libName := range ni.HashLibs(h) {
+			for _, installPath := range ni.SortedInstallPaths(h, libName) {
+				if inPartition(installPath) {
+					libKeys = append(libKeys, libKey(h, libName))
+					break
+				}
 			}
-			fmt.Fprintln(ctx.stdout, "      </ul>")
 		}
-		fmt.Fprintln(ctx.stdout, "  </ul><!-- toc -->")
 	}
-	for h := range ni.Hashes() {
-		fmt.Fprintln(ctx.stdout, "  <hr>")
+	libIDs := stableIDs("lib", libKeys, anchorDigits)
+
+	var sections []noticeSection
+	for _, h := range ni.AllHashes() {
+		h := h
+		var libs []noticeLibrary
 		for _, libName := range ni.HashLibs(h) {
-			fmt.Fprintf(ctx.stdout, "  <strong>%s</strong> used by:\n    <ul class=\"file-list\">\n", html.EscapeString(libName))
-			for _, installPath := range ni.HashLibInstalls(h, libName) {
-				if id, ok := ids[installPath]; ok {
-					fmt.Fprintf(ctx.stdout, "      <li><a href=\"#%s\">%s</a>\n", id, html.EscapeString(ctx.strip(installPath)))
-				} else {
-					fmt.Fprintf(ctx.stdout, "      <li>%s\n", html.EscapeString(ctx.strip(installPath)))
+			var installs []string
+			for _, installPath := range ni.SortedInstallPaths(h, libName) {
+				if inPartition(installPath) {
+					installs = append(installs, installPath)
 				}
 			}
-			fmt.Fprintf(ctx.stdout, "    </ul>\n")
+			if len(installs) == 0 {
+				continue
+			}
+			libs = append(libs, noticeLibrary{libName, libIDs[libKey(h, libName)], installs, ni.HashLibLicenseKinds(h, libName)})
+		}
+		if len(libs) == 0 {
+			continue
 		}
-		fmt.Fprintf(ctx.stdout, "  </ul>\n  <a id=\"%s\"/><pre class=\"license-text\">", h.String())
-		fmt.Fprintln(ctx.stdout, html.EscapeString(string(ni.HashText(h))))
-		fmt.Fprintln(ctx.stdout, "  </pre><!-- license-text -->")
+		text, err := ni.HashText(h)
+		if err != nil {
+			return nil, nil, err
+		}
+		sections = append(sections, noticeSection{h.String(), libs, func(w io.Writer, ids map[string]string, tocFile string) {
+			fmt.Fprintln(w, "  <hr>")
+			if ctx.collapsible {
+				installCount := 0
+				var libNames []string
+				for _, lib := range libs {
+					installCount += len(lib.installPaths)
+					libNames = append(libNames, lib.name)
+				}
+				fmt.Fprintln(w, "  <details class=\"license-section\">")
+				fmt.Fprintf(w, "    <summary><strong>%s</strong> (%d install paths)</summary>\n", html.EscapeString(strings.Join(libNames, ", ")), installCount)
+			}
+			for _, lib := range libs {
+				fmt.Fprintf(w, "  <strong id=\"%s\">%s</strong> used by:\n", lib.id, html.EscapeString(lib.name))
+				if ctx.showSpdx {
+					writeSpdxBadges(w, lib.licenseKinds)
+				}
+				fmt.Fprintf(w, "    <ul class=\"file-list\">\n")
+				for _, installPath := range lib.installPaths {
+					if id, ok := ids[installPath]; ok {
+						fmt.Fprintf(w, "      <li><a href=\"%s#%s\">%s</a>\n", tocFile, id, html.EscapeString(ctx.strip(installPath)))
+					} else {
+						fmt.Fprintf(w, "      <li>%s\n", html.EscapeString(ctx.strip(installPath)))
+					}
+				}
+				fmt.Fprintf(w, "    </ul>\n")
+			}
+			fmt.Fprintf(w, "  <a id=\"%s\"></a><pre class=\"license-text\">", h.String())
+			fmt.Fprintln(w, html.EscapeString(string(text)))
+			fmt.Fprintln(w, "  </pre><!-- license-text -->")
+			if ctx.collapsible {
+				fmt.Fprintln(w, "  </details>")
+			}
+		}})
 	}
-	fmt.Fprintln(ctx.stdout, "</body></html>")
+	return installPaths, sections, nil
+}
 
-	*ctx.deps = ni.InputFiles()
-	sort.Strings(*ctx.deps)
+// jsonIndexEntry describes a library in a rendered license text section for
+// the -json_index file.
+type jsonIndexEntry struct {
+	LibraryName  string   `json:"libraryName"`
+	TextHash     string   `json:"textHash"`
+	AnchorID     string   `json:"anchorId"`
+	InstallPaths []string `json:"installPaths"`
+	LicenseKinds []string `json:"licenseKinds"`
+}
 
-	return nil
+// writeJSONIndex writes a JSON array to `w` with an entry for each library in
+// each of `sections`.
+func writeJSONIndex(ctx *context, w io.Writer, sections []noticeSection) error {
+	entries := []jsonIndexEntry{}
+	for _, section := range sections {
+		for _, lib := range section.libs {
+			installPaths := make([]string, 0, len(lib.installPaths))
+			for _, installPath := range lib.installPaths {
+				installPaths = append(installPaths, ctx.strip(installPath))
+			}
+			entries = append(entries, jsonIndexEntry{lib.name, section.anchor, lib.id, installPaths, lib.licenseKinds})
+		}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeSplitNotice writes the html notice for `ni` to ctx.stdout unless it
+// exceeds ctx.maxSize bytes. Otherwise, writes the license text sections into
+// numbered part files next to ctx.outputFile, each within ctx.maxSize where
+// possible, and writes an index page linking to the parts to ctx.stdout.
+//
+// Sizes count uncompressed bytes. Sections never split across parts so a
+// single section larger than ctx.maxSize gets a part to itself.
+func writeSplitNotice(ctx *context, ni *compliance.NoticeIndex) error {
+	obuf := &bytes.Buffer{}
+	if err := writeNotice(ctx, obuf, ni, ""); err != nil {
+		return err
+	}
+	if int64(obuf.Len()) <= ctx.maxSize {
+		_, err := ctx.stdout.Write(obuf.Bytes())
+		return err
+	}
+
+	installPaths, sections, err := noticeSections(ctx, ni, "")
+	if err != nil {
+		return err
+	}
+	var ids map[string]string
+	if ctx.includeTOC {
+		ids = stableIDs("id", installPaths, anchorDigits)
+	}
+
+	head := &bytes.Buffer{}
+	writeHead(ctx, head)
+	const foot = "</body></html>\n"
+
+	indexFile := filepath.Base(ctx.outputFile)
+	stem := strings.TrimSuffix(indexFile, ".gz")
+	ext := filepath.Ext(stem)
+	stem = strings.TrimSuffix(stem, ext)
+	if len(ext) == 0 {
+		ext = ".html"
+	}
+	if ctx.gzip {
+		ext += ".gz"
+	}
+
+	// Fill each part in order with as many whole sections as fit.
+	var parts []*bytes.Buffer
+	partOf := make(map[string]string)
+	var part *bytes.Buffer
+	for _, section := range sections {
+		sbuf := &bytes.Buffer{}
+		section.write(sbuf, ids, html.EscapeString(indexFile))
+		if part == nil || int64(part.Len()+sbuf.Len()+len(foot)) > ctx.maxSize && part.Len() > head.Len() {
+			if part != nil {
+				part.WriteString(foot)
+			}
+			part = &bytes.Buffer{}
+			part.Write(head.Bytes())
+			parts = append(parts, part)
+		}
+		part.Write(sbuf.Bytes())
+		partOf[section.anchor] = fmt.Sprintf("%s-%d%s", stem, len(parts), ext)
+	}
+	if part != nil {
+		part.WriteString(foot)
+	}
+
+	dir := filepath.Dir(ctx.outputFile)
+	for i, part := range parts {
+		fname := filepath.Join(dir, fmt.Sprintf("%s-%d%s", stem, i+1, ext))
+		obuf := part
+		if ctx.gzip {
+			obuf = &bytes.Buffer{}
+			gz := newGzipWriter(obuf)
+			gz.Write(part.Bytes())
+			if err := gz.Close(); err != nil {
+				return fmt.Errorf("could not compress output for %q: %w", fname, err)
+			}
+		}
+		err := os.WriteFile(fname, obuf.Bytes(), 0666)
+		if err != nil {
+			return fmt.Errorf("could not write output to %q: %w", fname, err)
+		}
+	}
+
+	w := ctx.stdout
+	w.Write(head.Bytes())
+	fmt.Fprintln(w, "  <ul class=\"parts\">")
+	for i := range parts {
+		fmt.Fprintf(w, "    <li><a href=\"%s-%d%s\">Part %d</a>\n", html.EscapeString(stem), i+1, html.EscapeString(ext), i+1)
+	}
+	fmt.Fprintln(w, "  </ul><!-- parts -->")
+	if ctx.includeTOC {
+		writeTOC(ctx, w, ni, installPaths, ids, func(anchor string) string {
+			return html.EscapeString(partOf[anchor])
+		})
+	}
+	_, err = io.WriteString(w, foot)
+	return err
+}
+
+// anchorDigits is the number of hexadecimal digits of a digest used in anchor
+// IDs when no other anchor ID shares them.
+const anchorDigits = 8
+
+// stableIDs returns an anchor ID for each of `keys` derived from the key alone
+// so that links into the notice remain valid when other keys come and go.
+//
+// Each ID is `prefix` followed by the first `digits` hexadecimal digits of the
+// md5sum of the key. When the truncated digests of different keys collide,
+// each of the colliding keys uses just enough additional digits to differ.
+func stableIDs(prefix string, keys []string, digits int) map[string]string {
+	keyOf := make(map[string]string)
+	digests := make([]string, 0, len(keys))
+	for _, key := range keys {
+		digest := fmt.Sprintf("%x", md5.Sum([]byte(key)))
+		if _, ok := keyOf[digest]; ok {
+			continue
+		}
+		keyOf[digest] = key
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+
+	// commonPrefix returns the number of leading digits `a` and `b` share.
+	commonPrefix := func(a, b string) int {
+		n := 0
+		for n < len(a) && n < len(b) && a[n] == b[n] {
+			n++
+		}
+		return n
+	}
+
+	ids := make(map[string]string)
+	for i, digest := range digests {
+		n := digits
+		if i > 0 {
+			if c := commonPrefix(digest, digests[i-1]) + 1; c > n {
+				n = c
+			}
+		}
+		if i+1 < len(digests) {
+			if c := commonPrefix(digest, digests[i+1]) + 1; c > n {
+				n = c
+			}
+		}
+		if n > len(digest) {
+			n = len(digest)
+		}
+		ids[keyOf[digest]] = prefix + digest[:n]
+	}
+	return ids
+}
+
+// writeSpdxBadges writes a line to `w` showing the SPDX license identifier of
+// each license kind in `kinds`.
+func writeSpdxBadges(w io.Writer, kinds []string) {
+	if len(kinds) == 0 {
+		return
+	}
+	seen := make(map[string]struct{})
+	var badges []string
+	for _, kind := range kinds {
+		id := compliance.SpdxIdentifierFromKind(kind)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		badges = append(badges, fmt.Sprintf("<span class=\"spdx-id\">%s</span>", html.EscapeString(id)))
+	}
+	fmt.Fprintf(w, "    <div class=\"spdx-ids\">%s</div>\n", strings.Join(badges, " "))
 }
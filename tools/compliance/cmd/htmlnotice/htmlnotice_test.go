@@ -17,12 +17,20 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"html"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"android/soong/tools/compliance"
@@ -33,13 +41,21 @@ var (
 	bodyTag        = regexp.MustCompile(`^\s*<body>\s*$`)
 	boilerPlate    = regexp.MustCompile(`^\s*(?:<ul class="file-list">|<ul>|</.*)\s*$`)
 	tocTag         = regexp.MustCompile(`^\s*<ul class="toc">\s*$`)
-	libraryName    = regexp.MustCompile(`^\s*<strong>(.*)</strong>\s\s*used\s\s*by\s*:\s*$`)
-	licenseText    = regexp.MustCompile(`^\s*<a id="[^"]{32}"/><pre class="license-text">(.*)$`)
+	libraryName    = regexp.MustCompile(`^\s*<strong id="lib[0-9a-f]+">(.*)</strong>\s\s*used\s\s*by\s*:\s*$`)
+	licenseText    = regexp.MustCompile(`^\s*<a id="[^"]{32}"></a><pre class="license-text">(.*)$`)
+	licenseAnchor  = regexp.MustCompile(`^\s*<a id="([^"]{32})"></a>`)
+	libraryAnchor  = regexp.MustCompile(`^\s*<strong id="([^"]*)">(.*)</strong>\s\s*used`)
+	installAnchor  = regexp.MustCompile(`^\s*<li id="([^"]*)"><strong>(.*)</strong>\s*$`)
 	titleTag       = regexp.MustCompile(`^\s*<title>(.*)</title>\s*$`)
 	h1Tag          = regexp.MustCompile(`^\s*<h1>(.*)</h1>\s*$`)
-	usedByTarget   = regexp.MustCompile(`^\s*<li>(?:<a href="#id[0-9]+">)?((?:out/(?:[^/<]*/)+)[^/<]*)(?:</a>)?\s*$`)
-	installTarget  = regexp.MustCompile(`^\s*<li id="id[0-9]+"><strong>(.*)</strong>\s*$`)
+	usedByTarget   = regexp.MustCompile(`^\s*<li>(?:<a href="#id[0-9a-f]+">)?((?:out/(?:[^/<]*/)+)[^/<]*)(?:</a>)?\s*$`)
+	installTarget  = regexp.MustCompile(`^\s*<li id="id[0-9a-f]+"><strong>(.*)</strong>\s*$`)
 	libReference   = regexp.MustCompile(`^\s*<li><a href="#[^"]{32}">(.*)</a>\s*$`)
+	detailsTag     = regexp.MustCompile(`^\s*<details class="license-section">\s*$`)
+	summaryTag     = regexp.MustCompile(`^\s*<summary><strong>(.*)</strong> \(([0-9]+) install paths\)</summary>\s*$`)
+	spdxIDs        = regexp.MustCompile(`^\s*<div class="spdx-ids">(.*)</div>\s*$`)
+	spdxID         = regexp.MustCompile(`<span class="spdx-id">([^<]*)</span>`)
+	installPath    = regexp.MustCompile(`^\s*<li>(?:<a href="#id[0-9a-f]+">)?([^<]*)(?:</a>)?\s*$`)
 )
 
 func TestMain(m *testing.M) {
@@ -54,15 +70,19 @@ func TestMain(m *testing.M) {
 
 func Test(t *testing.T) {
 	tests := []struct {
-		condition    string
-		name         string
-		outDir       string
-		roots        []string
-		includeTOC   bool
-		stripPrefix  string
-		title        string
-		expectedOut  []matcher
-		expectedDeps []string
+		condition     string
+		name          string
+		outDir        string
+		roots         []string
+		includeTOC    bool
+		collapsible   bool
+		gzip          bool
+		stripPrefix   string
+		title         []string
+		showSpdx      bool
+		skipBuildtime bool
+		expectedOut   []matcher
+		expectedDeps  []string
 	}{
 		{
 			condition: "firstparty",
@@ -130,7 +150,7 @@ func Test(t *testing.T) {
 			condition: "firstparty",
 			name:      "apex-with-title",
 			roots:     []string{"highest.apex.meta_lic"},
-			title:     "Emperor",
+			title:     []string{"Emperor"},
 			expectedOut: []matcher{
 				pageTitle{"Emperor"},
 				hr{},
@@ -153,12 +173,40 @@ func Test(t *testing.T) {
 				"testdata/firstparty/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "firstparty",
+			name:      "apex-with-stacked-title",
+			roots:     []string{"highest.apex.meta_lic"},
+			title:     []string{"Licenses", "Emperor & <Sons>"},
+			expectedOut: []matcher{
+				pageTitle{"Licenses"},
+				pageTitle{"Emperor & <Sons>"},
+				hr{},
+				library{"Android"},
+				usedBy{"highest.apex"},
+				usedBy{"highest.apex/bin/bin1"},
+				usedBy{"highest.apex/bin/bin2"},
+				usedBy{"highest.apex/lib/liba.so"},
+				usedBy{"highest.apex/lib/libb.so"},
+				firstParty{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/firstparty/bin/bin1.meta_lic",
+				"testdata/firstparty/bin/bin2.meta_lic",
+				"testdata/firstparty/highest.apex.meta_lic",
+				"testdata/firstparty/lib/liba.so.meta_lic",
+				"testdata/firstparty/lib/libb.so.meta_lic",
+				"testdata/firstparty/lib/libc.a.meta_lic",
+				"testdata/firstparty/lib/libd.so.meta_lic",
+			},
+		},
 		{
 			condition:  "firstparty",
 			name:       "apex-with-title+toc",
 			roots:      []string{"highest.apex.meta_lic"},
 			includeTOC: true,
-			title:      "Emperor",
+			title:      []string{"Emperor"},
 			expectedOut: []matcher{
 				pageTitle{"Emperor"},
 				toc{},
@@ -354,6 +402,30 @@ func Test(t *testing.T) {
 				"testdata/notice/lib/libb.so.meta_lic",
 			},
 		},
+		{
+			condition: "notice",
+			name:      "application+gzip",
+			roots:     []string{"application.meta_lic"},
+			gzip:      true,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"application"},
+				firstParty{},
+				hr{},
+				library{"Device"},
+				usedBy{"application"},
+				notice{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/notice/application.meta_lic",
+				"testdata/notice/bin/bin3.meta_lic",
+				"testdata/notice/lib/liba.so.meta_lic",
+				"testdata/notice/lib/libb.so.meta_lic",
+			},
+		},
 		{
 			condition: "notice",
 			name:      "binary",
@@ -425,6 +497,42 @@ func Test(t *testing.T) {
 				"testdata/reciprocal/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "reciprocal",
+			name:      "apex+show_spdx",
+			roots:     []string{"highest.apex.meta_lic"},
+			showSpdx:  true,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				spdx{[]string{"Apache-2.0"}},
+				usedBy{"highest.apex"},
+				usedBy{"highest.apex/bin/bin1"},
+				usedBy{"highest.apex/bin/bin2"},
+				usedBy{"highest.apex/lib/libb.so"},
+				firstParty{},
+				hr{},
+				library{"Device"},
+				spdx{[]string{"MPL"}},
+				usedBy{"highest.apex/bin/bin1"},
+				usedBy{"highest.apex/lib/liba.so"},
+				library{"External"},
+				spdx{[]string{"MPL"}},
+				usedBy{"highest.apex/bin/bin1"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/reciprocal/bin/bin1.meta_lic",
+				"testdata/reciprocal/bin/bin2.meta_lic",
+				"testdata/reciprocal/highest.apex.meta_lic",
+				"testdata/reciprocal/lib/liba.so.meta_lic",
+				"testdata/reciprocal/lib/libb.so.meta_lic",
+				"testdata/reciprocal/lib/libc.a.meta_lic",
+				"testdata/reciprocal/lib/libd.so.meta_lic",
+			},
+		},
 		{
 			condition: "reciprocal",
 			name:      "container",
@@ -556,6 +664,48 @@ func Test(t *testing.T) {
 				"testdata/restricted/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "restricted",
+			name:      "apex+show_spdx",
+			roots:     []string{"highest.apex.meta_lic"},
+			showSpdx:  true,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				spdx{[]string{"Apache-2.0"}},
+				usedBy{"highest.apex"},
+				usedBy{"highest.apex/bin/bin1"},
+				usedBy{"highest.apex/bin/bin2"},
+				firstParty{},
+				hr{},
+				library{"Android"},
+				spdx{[]string{"GPL-2.0"}},
+				usedBy{"highest.apex/bin/bin2"},
+				usedBy{"highest.apex/lib/libb.so"},
+				library{"Device"},
+				spdx{[]string{"LGPL-2.0"}},
+				usedBy{"highest.apex/bin/bin1"},
+				usedBy{"highest.apex/lib/liba.so"},
+				restricted{},
+				hr{},
+				library{"External"},
+				spdx{[]string{"MPL"}},
+				usedBy{"highest.apex/bin/bin1"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/restricted/RESTRICTED_LICENSE",
+				"testdata/restricted/bin/bin1.meta_lic",
+				"testdata/restricted/bin/bin2.meta_lic",
+				"testdata/restricted/highest.apex.meta_lic",
+				"testdata/restricted/lib/liba.so.meta_lic",
+				"testdata/restricted/lib/libb.so.meta_lic",
+				"testdata/restricted/lib/libc.a.meta_lic",
+				"testdata/restricted/lib/libd.so.meta_lic",
+			},
+		},
 		{
 			condition: "restricted",
 			name:      "container",
@@ -593,6 +743,50 @@ func Test(t *testing.T) {
 				"testdata/restricted/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition:   "restricted",
+			name:        "container+collapsible",
+			roots:       []string{"container.zip.meta_lic"},
+			collapsible: true,
+			expectedOut: []matcher{
+				hr{},
+				details{},
+				summary{"Android", 3},
+				library{"Android"},
+				usedBy{"container.zip"},
+				usedBy{"container.zip/bin1"},
+				usedBy{"container.zip/bin2"},
+				firstParty{},
+				hr{},
+				details{},
+				summary{"Android, Device", 4},
+				library{"Android"},
+				usedBy{"container.zip/bin2"},
+				usedBy{"container.zip/libb.so"},
+				library{"Device"},
+				usedBy{"container.zip/bin1"},
+				usedBy{"container.zip/liba.so"},
+				restricted{},
+				hr{},
+				details{},
+				summary{"External", 1},
+				library{"External"},
+				usedBy{"container.zip/bin1"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/restricted/RESTRICTED_LICENSE",
+				"testdata/restricted/bin/bin1.meta_lic",
+				"testdata/restricted/bin/bin2.meta_lic",
+				"testdata/restricted/container.zip.meta_lic",
+				"testdata/restricted/lib/liba.so.meta_lic",
+				"testdata/restricted/lib/libb.so.meta_lic",
+				"testdata/restricted/lib/libc.a.meta_lic",
+				"testdata/restricted/lib/libd.so.meta_lic",
+			},
+		},
 		{
 			condition: "restricted",
 			name:      "application",
@@ -796,6 +990,146 @@ func Test(t *testing.T) {
 				"testdata/proprietary/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "regressshared",
+			name:      "container",
+			roots:     []string{"container.zip.meta_lic"},
+			expectedOut: []matcher{
+				hr{},
+				library{"Alpha"},
+				usedBy{"container.zip/bin2"},
+				library{"Beta"},
+				usedBy{"container.zip/liba.so"},
+				library{"Delta"},
+				usedBy{"container.zip/bin1"},
+				library{"Gamma"},
+				usedBy{"container.zip/libb.so"},
+				library{"Zeta"},
+				usedBy{"container.zip/bin1"},
+				notice{},
+				hr{},
+				library{"Android"},
+				usedBy{"container.zip"},
+				firstParty{},
+				hr{},
+				library{"Gamma"},
+				usedBy{"container.zip/libb.so"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/regressshared/bin/bin1.meta_lic",
+				"testdata/regressshared/bin/bin2.meta_lic",
+				"testdata/regressshared/container.zip.meta_lic",
+				"testdata/regressshared/lib/liba.so.meta_lic",
+				"testdata/regressshared/lib/libb.so.meta_lic",
+				"testdata/regressshared/lib/libc.a.meta_lic",
+			},
+		},
+		{
+			condition:  "regressshared",
+			name:       "container+toc",
+			roots:      []string{"container.zip.meta_lic"},
+			includeTOC: true,
+			expectedOut: []matcher{
+				toc{},
+				target{"container.zip"},
+				uses{"Android"},
+				target{"container.zip/bin1"},
+				uses{"Delta, Zeta"},
+				target{"container.zip/bin2"},
+				uses{"Alpha"},
+				target{"container.zip/liba.so"},
+				uses{"Beta"},
+				target{"container.zip/libb.so"},
+				uses{"Gamma"},
+				uses{"Gamma"},
+				hr{},
+				library{"Alpha"},
+				usedBy{"container.zip/bin2"},
+				library{"Beta"},
+				usedBy{"container.zip/liba.so"},
+				library{"Delta"},
+				usedBy{"container.zip/bin1"},
+				library{"Gamma"},
+				usedBy{"container.zip/libb.so"},
+				library{"Zeta"},
+				usedBy{"container.zip/bin1"},
+				notice{},
+				hr{},
+				library{"Android"},
+				usedBy{"container.zip"},
+				firstParty{},
+				hr{},
+				library{"Gamma"},
+				usedBy{"container.zip/libb.so"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/regressshared/bin/bin1.meta_lic",
+				"testdata/regressshared/bin/bin2.meta_lic",
+				"testdata/regressshared/container.zip.meta_lic",
+				"testdata/regressshared/lib/liba.so.meta_lic",
+				"testdata/regressshared/lib/libb.so.meta_lic",
+				"testdata/regressshared/lib/libc.a.meta_lic",
+			},
+		},
+		{
+			condition: "regressbuildtime",
+			name:      "bin",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"bin1"},
+				library{"Runtime"},
+				usedBy{"bin1"},
+				notice{},
+				hr{},
+				library{"Generator"},
+				usedBy{"bin1"},
+				restricted{},
+				hr{},
+				library{"Harness"},
+				usedBy{"bin1"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/regressbuildtime/bin/bin1.meta_lic",
+				"testdata/regressbuildtime/bin/gen.meta_lic",
+				"testdata/regressbuildtime/lib/liba.a.meta_lic",
+				"testdata/regressbuildtime/lib/libharness.a.meta_lic",
+				"testdata/restricted/RESTRICTED_LICENSE",
+			},
+		},
+		{
+			condition:     "regressbuildtime",
+			name:          "bin+skip_buildtime",
+			roots:         []string{"bin/bin1.meta_lic"},
+			skipBuildtime: true,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"bin1"},
+				library{"Runtime"},
+				usedBy{"bin1"},
+				notice{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regressbuildtime/bin/bin1.meta_lic",
+				"testdata/regressbuildtime/bin/gen.meta_lic",
+				"testdata/regressbuildtime/lib/liba.a.meta_lic",
+				"testdata/regressbuildtime/lib/libharness.a.meta_lic",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
@@ -809,7 +1143,16 @@ func Test(t *testing.T) {
 
 			var deps []string
 
-			ctx := context{stdout, stderr, compliance.GetFS(tt.outDir), tt.includeTOC, "", []string{tt.stripPrefix}, tt.title, &deps}
+			var ofile io.Writer = stdout
+			var compressed *bytes.Buffer
+			var gz *gzip.Writer
+			if tt.gzip {
+				compressed = &bytes.Buffer{}
+				gz = newGzipWriter(compressed)
+				ofile = gz
+			}
+
+			ctx := context{stdout: ofile, stderr: stderr, rootFS: compliance.GetFS(tt.outDir), includeTOC: tt.includeTOC, collapsible: tt.collapsible, stripPrefix: []string{tt.stripPrefix}, title: tt.title, showSpdx: tt.showSpdx, skipBuildtime: tt.skipBuildtime, deps: &deps}
 
 			err := htmlNotice(&ctx, rootFiles...)
 			if err != nil {
@@ -820,6 +1163,30 @@ func Test(t *testing.T) {
 				t.Errorf("htmlnotice: gotStderr = %v, want none", stderr)
 			}
 
+			if tt.gzip {
+				if err := gz.Close(); err != nil {
+					t.Fatalf("htmlnotice: could not compress output: %s", err)
+				}
+				r, err := gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+				if err != nil {
+					t.Fatalf("htmlnotice: could not read compressed output: %s", err)
+				}
+				if !r.ModTime.IsZero() || r.Name != "" || r.Comment != "" {
+					t.Errorf("htmlnotice: got gzip header %+v, want no name, comment or modification time", r.Header)
+				}
+				if _, err := io.Copy(stdout, r); err != nil {
+					t.Fatalf("htmlnotice: could not decompress output: %s", err)
+				}
+
+				again := &bytes.Buffer{}
+				gz2 := newGzipWriter(again)
+				gz2.Write(stdout.Bytes())
+				gz2.Close()
+				if !bytes.Equal(compressed.Bytes(), again.Bytes()) {
+					t.Errorf("htmlnotice: got different compressed bytes for identical output, want identical bytes")
+				}
+			}
+
 			t.Logf("got stdout: %s", stdout.String())
 
 			t.Logf("want stdout: %s", matcherList(tt.expectedOut).String())
@@ -828,7 +1195,9 @@ func Test(t *testing.T) {
 			lineno := 0
 			inBody := false
 			hasTitle := false
-			ttle, expectTitle := tt.expectedOut[0].(pageTitle)
+			anchors := make(map[string]struct{})
+			ttle := html.EscapeString(strings.Join(tt.title, " - "))
+			expectTitle := len(tt.title) > 0
 			for out.Scan() {
 				line := out.Text()
 				if strings.TrimLeft(line, " ") == "" {
@@ -837,8 +1206,8 @@ func Test(t *testing.T) {
 				if !inBody {
 					if expectTitle {
 						if tl := checkTitle(line); len(tl) > 0 {
-							if tl != ttle.t {
-								t.Errorf("htmlnotice: unexpected title: got %q, want %q", tl, ttle.t)
+							if tl != ttle {
+								t.Errorf("htmlnotice: unexpected title: got %q, want %q", tl, ttle)
 							}
 							hasTitle = true
 						}
@@ -846,7 +1215,7 @@ func Test(t *testing.T) {
 					if bodyTag.MatchString(line) {
 						inBody = true
 						if expectTitle && !hasTitle {
-							t.Errorf("htmlnotice: missing title: got no <title> tag, want <title>%s</title>", ttle.t)
+							t.Errorf("htmlnotice: missing title: got no <title> tag, want <title>%s</title>", ttle)
 						}
 					}
 					continue
@@ -854,6 +1223,12 @@ func Test(t *testing.T) {
 				if boilerPlate.MatchString(line) {
 					continue
 				}
+				if groups := licenseAnchor.FindStringSubmatch(line); len(groups) == 2 {
+					if _, ok := anchors[groups[1]]; ok {
+						t.Errorf("htmlnotice: duplicate license text at line %d: got %q again, want each text exactly once", lineno+1, groups[1])
+					}
+					anchors[groups[1]] = struct{}{}
+				}
 				if len(tt.expectedOut) <= lineno {
 					t.Errorf("htmlnotice: unexpected output at line %d: got %q, want nothing (wanted %d lines)", lineno+1, line, len(tt.expectedOut))
 				} else if !tt.expectedOut[lineno].isMatch(line) {
@@ -881,6 +1256,601 @@ func Test(t *testing.T) {
 	}
 }
 
+func TestEscaping(t *testing.T) {
+	licenseText, err := os.ReadFile("testdata/regressescape/ESCAPE_LICENSE")
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	wantText := strings.TrimSpace(string(licenseText))
+	wantLib := "Script <Lib> & Co"
+	wantInstall := "bin/bin1&<x>"
+
+	tests := []struct {
+		name        string
+		includeTOC  bool
+		collapsible bool
+	}{
+		{name: "plain"},
+		{name: "toc", includeTOC: true},
+		{name: "collapsible", collapsible: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			var deps []string
+
+			ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), includeTOC: tt.includeTOC, collapsible: tt.collapsible, stripPrefix: []string{"out/target/product/fictional/system/"}, deps: &deps}
+
+			err := htmlNotice(&ctx, "testdata/regressescape/bin/bin1.meta_lic")
+			if err != nil {
+				t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("htmlnotice: gotStderr = %v, want none", stderr)
+			}
+
+			t.Logf("got stdout: %s", stdout.String())
+
+			if strings.Contains(stdout.String(), "<script>") {
+				t.Errorf("htmlnotice: got unescaped <script> tag in output, want escaped text")
+			}
+
+			// Parse the output the way a lenient browser would and confirm
+			// the text survives intact without introducing any elements.
+			d := xml.NewDecoder(stdout)
+			d.Strict = false
+			d.AutoClose = xml.HTMLAutoClose
+			d.Entity = xml.HTMLEntity
+			var stack []string
+			var preText, strongText []string
+			var sawInstall bool
+			for {
+				tok, err := d.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("htmlnotice: unparseable output: %s", err)
+				}
+				switch tok := tok.(type) {
+				case xml.StartElement:
+					switch tok.Name.Local {
+					case "script", "b":
+						t.Errorf("htmlnotice: got <%s> element from escaped text, want none", tok.Name.Local)
+					}
+					stack = append(stack, tok.Name.Local)
+				case xml.EndElement:
+					if len(stack) > 0 {
+						stack = stack[:len(stack)-1]
+					}
+				case xml.CharData:
+					if len(stack) == 0 {
+						continue
+					}
+					switch stack[len(stack)-1] {
+					case "pre":
+						preText = append(preText, string(tok))
+					case "strong":
+						strongText = append(strongText, string(tok))
+					case "li", "a":
+						if strings.TrimSpace(string(tok)) == wantInstall {
+							sawInstall = true
+						}
+					}
+				}
+			}
+			if got := strings.TrimSpace(strings.Join(preText, "")); got != wantText {
+				t.Errorf("htmlnotice: unexpected license text: got %q, want %q", got, wantText)
+			}
+			foundLib := false
+			for _, s := range strongText {
+				if s == wantLib {
+					foundLib = true
+				}
+			}
+			if !foundLib {
+				t.Errorf("htmlnotice: missing library name: got %q, want %q", strongText, wantLib)
+			}
+			if !sawInstall {
+				t.Errorf("htmlnotice: missing install path: got none, want %q", wantInstall)
+			}
+		})
+	}
+}
+
+func TestPartitionOutput(t *testing.T) {
+	roots := []string{
+		"testdata/regresspartition/bin/bin1.meta_lic",
+		"testdata/regresspartition/bin/tool.meta_lic",
+		"testdata/regresspartition/lib/libo.so.meta_lic",
+		"testdata/regresspartition/lib/libs.so.meta_lic",
+		"testdata/regresspartition/lib/libv.so.meta_lic",
+	}
+	expected := map[string]map[string][]string{
+		"NOTICE_odm.html": {
+			"Gamma": {"odm/lib/libo.so"},
+		},
+		"NOTICE_other.html": {
+			"Epsilon": {"out/host/linux-x86/bin/tool"},
+		},
+		"NOTICE_system.html": {
+			"Alpha": {"system/bin/bin1"},
+			"Delta": {"system/lib/libs.so"},
+		},
+		"NOTICE_vendor.html": {
+			"Beta":  {"vendor/lib/libv.so"},
+			"Delta": {"vendor/lib/libs.so"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		gzip bool
+	}{
+		{name: "plain"},
+		{name: "gzip", gzip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			dir := t.TempDir()
+
+			var deps []string
+
+			ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), stripPrefix: []string{"out/target/product/fictional/"}, partitionOutput: dir, unknownPartition: "other", gzip: tt.gzip, deps: &deps}
+
+			err := htmlNotice(&ctx, roots...)
+			if err != nil {
+				t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("htmlnotice: gotStderr = %v, want none", stderr)
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("htmlnotice: got stdout %q, want none", stdout)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("htmlnotice: cannot read output directory: %s", err)
+			}
+			actual := make(map[string]map[string][]string)
+			for _, e := range entries {
+				data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+				if err != nil {
+					t.Fatalf("htmlnotice: cannot read %q: %s", e.Name(), err)
+				}
+				name := e.Name()
+				if tt.gzip {
+					name = strings.TrimSuffix(name, ".gz")
+					r, err := gzip.NewReader(bytes.NewReader(data))
+					if err != nil {
+						t.Fatalf("htmlnotice: cannot decompress %q: %s", e.Name(), err)
+					}
+					data, err = io.ReadAll(r)
+					if err != nil {
+						t.Fatalf("htmlnotice: cannot decompress %q: %s", e.Name(), err)
+					}
+				}
+				actual[name] = partitionLibraries(string(data))
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("htmlnotice: got partitions %v, want %v", actual, expected)
+			}
+
+			expectedDeps := []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/regresspartition/bin/bin1.meta_lic",
+				"testdata/regresspartition/bin/tool.meta_lic",
+				"testdata/regresspartition/lib/libo.so.meta_lic",
+				"testdata/regresspartition/lib/libs.so.meta_lic",
+				"testdata/regresspartition/lib/libv.so.meta_lic",
+			}
+			if !reflect.DeepEqual(deps, expectedDeps) {
+				t.Errorf("htmlnotice: unexpected deps, wanted:\n%s\ngot:\n%s\n",
+					strings.Join(expectedDeps, "\n"), strings.Join(deps, "\n"))
+			}
+		})
+	}
+}
+
+func TestStableAnchors(t *testing.T) {
+	// anchors maps the library names and install paths in the table of
+	// contents to their anchor IDs.
+	anchors := func(roots ...string) map[string][]string {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		var deps []string
+
+		ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), includeTOC: true, stripPrefix: []string{"out/target/product/fictional/"}, deps: &deps}
+
+		err := htmlNotice(&ctx, roots...)
+		if err != nil {
+			t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+		}
+		result := make(map[string][]string)
+		for _, line := range strings.Split(stdout.String(), "\n") {
+			if groups := libraryAnchor.FindStringSubmatch(line); len(groups) == 3 {
+				result[groups[2]] = append(result[groups[2]], groups[1])
+			} else if groups := installAnchor.FindStringSubmatch(line); len(groups) == 3 {
+				result[groups[2]] = append(result[groups[2]], groups[1])
+			}
+		}
+		return result
+	}
+
+	// bin1 sorts before bin2 so positional anchors for bin2 would change.
+	before := anchors("testdata/notice/bin/bin2.meta_lic")
+	after := anchors("testdata/notice/bin/bin2.meta_lic", "testdata/notice/bin/bin1.meta_lic")
+	if len(before) == 0 {
+		t.Fatalf("htmlnotice: got no anchors, want anchors")
+	}
+	for name, ids := range before {
+		if !reflect.DeepEqual(after[name], ids) {
+			t.Errorf("htmlnotice: got anchors %q for %q after adding a root, want %q", after[name], name, ids)
+		}
+	}
+	if len(after) <= len(before) {
+		t.Errorf("htmlnotice: got %d anchors after adding a root, want more than %d", len(after), len(before))
+	}
+}
+
+func TestProgress(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	type report struct {
+		done, total int
+	}
+	var mu sync.Mutex
+	reports := make(map[compliance.ProgressPhase][]report)
+	progress := func(phase compliance.ProgressPhase, done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports[phase] = append(reports[phase], report{done, total})
+	}
+
+	var deps []string
+
+	ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), progress: progress, deps: &deps}
+
+	err := htmlNotice(&ctx, "testdata/notice/highest.apex.meta_lic")
+	if err != nil {
+		t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+	}
+
+	// last returns the final report for `phase`.
+	last := func(phase compliance.ProgressPhase) report {
+		if len(reports[phase]) == 0 {
+			t.Fatalf("htmlnotice: got no %q progress, want progress", phase)
+		}
+		return reports[phase][len(reports[phase])-1]
+	}
+
+	metaLics := 0
+	for _, dep := range deps {
+		if strings.HasSuffix(dep, ".meta_lic") {
+			metaLics++
+		}
+	}
+	if actual := last(compliance.ProgressReadMetadata); actual.done != metaLics || actual.total != metaLics {
+		t.Errorf("htmlnotice: got final read progress %d/%d, want %d/%d", actual.done, actual.total, metaLics, metaLics)
+	}
+	if actual := last(compliance.ProgressHashTexts); actual.done != len(deps)-metaLics || actual.total != 0 {
+		t.Errorf("htmlnotice: got final hash progress %d/%d, want %d/0", actual.done, actual.total, len(deps)-metaLics)
+	}
+	sections := strings.Count(stdout.String(), "<pre class=\"license-text\">")
+	if actual := last(compliance.ProgressEmitSections); actual.done != sections || actual.total != sections {
+		t.Errorf("htmlnotice: got final emit progress %d/%d, want %d/%d", actual.done, actual.total, sections, sections)
+	}
+	if n := len(reports[compliance.ProgressEmitSections]); n != sections {
+		t.Errorf("htmlnotice: got %d emit reports, want %d", n, sections)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	roots := []string{"testdata/notice/highest.apex.meta_lic"}
+
+	// notice runs htmlnotice with `maxSize` writing NOTICE.html into `dir`
+	// and returns the content of every file in `dir` by name.
+	notice := func(dir string, maxSize int64) map[string]string {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		var deps []string
+
+		outputFile := filepath.Join(dir, "NOTICE.html")
+		ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), includeTOC: true, stripPrefix: []string{"out/target/product/fictional/"}, maxSize: maxSize, outputFile: outputFile, deps: &deps}
+
+		err := htmlNotice(&ctx, roots...)
+		if err != nil {
+			t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+		}
+		files := map[string]string{"NOTICE.html": stdout.String()}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("htmlnotice: cannot read output directory: %v", err)
+		}
+		for _, entry := range entries {
+			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				t.Fatalf("htmlnotice: cannot read %q: %v", entry.Name(), err)
+			}
+			files[entry.Name()] = string(data)
+		}
+		return files
+	}
+
+	single := notice(t.TempDir(), 0)
+	if len(single) != 1 {
+		t.Fatalf("htmlnotice: got %d files without -max_size, want 1", len(single))
+	}
+	size := int64(len(single["NOTICE.html"]))
+
+	t.Run("fits", func(t *testing.T) {
+		actual := notice(t.TempDir(), size)
+		if !reflect.DeepEqual(actual, single) {
+			t.Errorf("htmlnotice: got %d files within -max_size, want the single notice", len(actual))
+		}
+	})
+
+	t.Run("split", func(t *testing.T) {
+		maxSize := size / 3
+		actual := notice(t.TempDir(), maxSize)
+		if again := notice(t.TempDir(), maxSize); !reflect.DeepEqual(again, actual) {
+			t.Errorf("htmlnotice: got different parts on second run, want identical output")
+		}
+		if len(actual) < 3 {
+			t.Fatalf("htmlnotice: got %d files, want an index and at least 2 parts", len(actual))
+		}
+
+		// Every license text appears whole in exactly one part.
+		anchor := regexp.MustCompile(`<a id="([^"]+)"></a><pre class="license-text">`)
+		expectedAnchors := anchor.FindAllStringSubmatch(single["NOTICE.html"], -1)
+		partOf := make(map[string]string)
+		for name, text := range actual {
+			if name == "NOTICE.html" {
+				if anchor.MatchString(text) {
+					t.Errorf("htmlnotice: got license text in the index, want only in parts")
+				}
+				continue
+			}
+			if !strings.HasPrefix(text, "<!DOCTYPE html>") || !strings.HasSuffix(text, "</body></html>\n") {
+				t.Errorf("htmlnotice: got incomplete html document in %q", name)
+			}
+			sections := strings.Count(text, "<hr>")
+			if ends := strings.Count(text, "</pre><!-- license-text -->"); ends != sections {
+				t.Errorf("htmlnotice: got %d license texts in %d sections of %q, want whole sections", ends, sections, name)
+			}
+			if sections > 1 && int64(len(text)) > maxSize {
+				t.Errorf("htmlnotice: got %d bytes in %q with %d sections, want at most %d", len(text), name, sections, maxSize)
+			}
+			for _, groups := range anchor.FindAllStringSubmatch(text, -1) {
+				if other, ok := partOf[groups[1]]; ok {
+					t.Errorf("htmlnotice: got license text %q in %q and %q, want one part", groups[1], other, name)
+				}
+				partOf[groups[1]] = name
+			}
+		}
+		if len(partOf) != len(expectedAnchors) {
+			t.Errorf("htmlnotice: got %d license texts in parts, want %d", len(partOf), len(expectedAnchors))
+		}
+
+		// Every link in the index reaches its license text.
+		link := regexp.MustCompile(`<a href="([^"#]+)#([^"]+)">`)
+		links := link.FindAllStringSubmatch(actual["NOTICE.html"], -1)
+		if len(links) == 0 {
+			t.Errorf("htmlnotice: got no links in the index, want table of contents")
+		}
+		for _, groups := range links {
+			if partOf[groups[2]] != groups[1] {
+				t.Errorf("htmlnotice: got link to %q in %q, want %q", groups[2], groups[1], partOf[groups[2]])
+			}
+		}
+		for i := 1; i < len(actual); i++ {
+			part := fmt.Sprintf("<a href=\"NOTICE-%d.html\">Part %d</a>", i, i)
+			if !strings.Contains(actual["NOTICE.html"], part) {
+				t.Errorf("htmlnotice: got no link to part %d in the index, want %q", i, part)
+			}
+		}
+	})
+}
+
+func TestJSONIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		roots   []string
+		showToc bool
+	}{
+		{
+			name:  "notice",
+			roots: []string{"testdata/notice/highest.apex.meta_lic"},
+		},
+		{
+			name:    "noticetoc",
+			roots:   []string{"testdata/notice/highest.apex.meta_lic"},
+			showToc: true,
+		},
+		{
+			name:  "reciprocal",
+			roots: []string{"testdata/reciprocal/bin/bin1.meta_lic", "testdata/reciprocal/bin/bin2.meta_lic"},
+		},
+		{
+			name:  "escaping",
+			roots: []string{"testdata/regressescape/bin/bin1.meta_lic"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			jsonIndex := &bytes.Buffer{}
+
+			var deps []string
+
+			ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), includeTOC: tt.showToc, stripPrefix: []string{"out/target/product/fictional/"}, showSpdx: true, jsonIndex: jsonIndex, deps: &deps}
+
+			err := htmlNotice(&ctx, tt.roots...)
+			if err != nil {
+				t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+			}
+
+			var entries []jsonIndexEntry
+			err = json.Unmarshal(jsonIndex.Bytes(), &entries)
+			if err != nil {
+				t.Fatalf("htmlnotice: cannot unmarshal json index %q: %v", jsonIndex, err)
+			}
+			rendered := stdout.String()
+
+			libraries := regexp.MustCompile(`<strong id="(lib[0-9a-f]+)">`).FindAllString(rendered, -1)
+			if len(entries) != len(libraries) {
+				t.Errorf("htmlnotice: got %d json index entries, want %d rendered libraries", len(entries), len(libraries))
+			}
+			hashes := make(map[string]struct{})
+			installs := 0
+			for _, entry := range entries {
+				hashes[entry.TextHash] = struct{}{}
+				installs += len(entry.InstallPaths)
+				library := fmt.Sprintf("<strong id=\"%s\">%s</strong> used by:", entry.AnchorID, html.EscapeString(entry.LibraryName))
+				if !strings.Contains(rendered, library) {
+					t.Errorf("htmlnotice: got json index library %q not rendered as %q", entry.LibraryName, library)
+				}
+				if !strings.Contains(rendered, fmt.Sprintf("<a id=\"%s\"></a><pre class=\"license-text\">", entry.TextHash)) {
+					t.Errorf("htmlnotice: got json index text hash %q not rendered", entry.TextHash)
+				}
+				if len(entry.LicenseKinds) == 0 {
+					t.Errorf("htmlnotice: got no license kinds for %q, want license kinds", entry.LibraryName)
+				}
+				for _, kind := range entry.LicenseKinds {
+					badge := fmt.Sprintf("<span class=\"spdx-id\">%s</span>", html.EscapeString(compliance.SpdxIdentifierFromKind(kind)))
+					if !strings.Contains(rendered, badge) {
+						t.Errorf("htmlnotice: got json index license kind %q not rendered", kind)
+					}
+				}
+				for _, installPath := range entry.InstallPaths {
+					if !strings.Contains(rendered, html.EscapeString(installPath)+"\n") && !strings.Contains(rendered, html.EscapeString(installPath)+"</a>\n") {
+						t.Errorf("htmlnotice: got json index install path %q not rendered", installPath)
+					}
+				}
+			}
+			if sections := strings.Count(rendered, "<pre class=\"license-text\">"); len(hashes) != sections {
+				t.Errorf("htmlnotice: got %d json index text hashes, want %d rendered sections", len(hashes), sections)
+			}
+			if usedBy := len(regexp.MustCompile(`(?m)^      <li>`).FindAllString(rendered, -1)); installs != usedBy {
+				t.Errorf("htmlnotice: got %d json index install paths, want %d rendered", installs, usedBy)
+			}
+		})
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		expected []string
+	}{
+		{
+			name: "restricted",
+			root: "testdata/restricted/application.meta_lic",
+			expected: []string{
+				"TRACE: testdata/restricted/application.meta_lic --[restricted]--> testdata/restricted/lib/liba.so.meta_lic",
+				"TRACE: testdata/restricted/application.meta_lic --[restricted_if_statically_linked]--> testdata/restricted/lib/liba.so.meta_lic",
+				"TRACE: testdata/restricted/application.meta_lic --[restricted]--> testdata/restricted/lib/libb.so.meta_lic",
+			},
+		},
+		{
+			name: "notice",
+			root: "testdata/notice/bin/bin1.meta_lic",
+			expected: []string{
+				"TRACE: testdata/notice/bin/bin1.meta_lic --[notice]--> testdata/notice/lib/liba.so.meta_lic",
+				"TRACE: testdata/notice/bin/bin1.meta_lic --[notice]--> testdata/notice/lib/libc.a.meta_lic",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			var deps []string
+
+			ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), includeTOC: true, verbose: true, deps: &deps}
+
+			err := htmlNotice(&ctx, tt.root)
+			if err != nil {
+				t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+			}
+
+			actual := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+			sort.Strings(actual)
+			expected := append([]string{}, tt.expected...)
+			sort.Strings(expected)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("htmlnotice: got trace:\n%s\nwant:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestStableIDs(t *testing.T) {
+	var keys []string
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf("out/target/product/fictional/system/lib/lib%d.so", i))
+	}
+	ids := stableIDs("id", keys, 1)
+	seen := make(map[string]string)
+	for _, key := range keys {
+		id, ok := ids[key]
+		if !ok {
+			t.Fatalf("stableIDs(): got no id for %q, want id", key)
+		}
+		if other, ok := seen[id]; ok {
+			t.Errorf("stableIDs(): got id %q for both %q and %q, want distinct ids", id, other, key)
+		}
+		seen[id] = key
+		digest := fmt.Sprintf("%x", md5.Sum([]byte(key)))
+		if !strings.HasPrefix("id"+digest, id) || len(id) < len("id")+1 {
+			t.Errorf("stableIDs(): got id %q for %q, want a prefix of %q", id, key, "id"+digest)
+		}
+	}
+
+	// Collisions resolve the same way regardless of the order of the keys.
+	reversed := make([]string, 0, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		reversed = append(reversed, keys[i])
+	}
+	if actual := stableIDs("id", reversed, 1); !reflect.DeepEqual(actual, ids) {
+		t.Errorf("stableIDs(): got %v for reversed keys, want %v", actual, ids)
+	}
+
+	// Keys without collisions use the requested number of digits.
+	if actual := stableIDs("lib", keys[:1], 8); len(actual[keys[0]]) != len("lib")+8 {
+		t.Errorf("stableIDs(): got %q, want 8 digits", actual[keys[0]])
+	}
+}
+
+// partitionLibraries maps the library names in html notice `text` to the
+// install paths listed for each.
+func partitionLibraries(text string) map[string][]string {
+	result := make(map[string][]string)
+	libName := ""
+	for _, line := range strings.Split(text, "\n") {
+		if groups := libraryName.FindStringSubmatch(line); len(groups) == 2 {
+			libName = html.UnescapeString(groups[1])
+		} else if groups := installPath.FindStringSubmatch(line); len(groups) == 2 && len(libName) > 0 {
+			result[libName] = append(result[libName], html.UnescapeString(groups[1]))
+		} else if strings.Contains(line, "</ul>") {
+			libName = ""
+		}
+	}
+	return result
+}
+
 func checkTitle(line string) string {
 	groups := titleTag.FindStringSubmatch(line)
 	if len(groups) != 2 {
@@ -952,6 +1922,33 @@ func (m uses) String() string {
 	return `  <li><a href="#hash">` + html.EscapeString(m.name) + `</a>`
 }
 
+type details struct{}
+
+func (m details) isMatch(line string) bool {
+	return detailsTag.MatchString(line)
+}
+
+func (m details) String() string {
+	return `  <details class="license-section">`
+}
+
+type summary struct {
+	libs  string
+	count int
+}
+
+func (m summary) isMatch(line string) bool {
+	groups := summaryTag.FindStringSubmatch(line)
+	if len(groups) != 3 {
+		return false
+	}
+	return groups[1] == html.EscapeString(m.libs) && groups[2] == fmt.Sprintf("%d", m.count)
+}
+
+func (m summary) String() string {
+	return fmt.Sprintf("    <summary><strong>%s</strong> (%d install paths)</summary>", html.EscapeString(m.libs), m.count)
+}
+
 type hr struct{}
 
 func (m hr) isMatch(line string) bool {
@@ -978,6 +1975,26 @@ func (m library) String() string {
 	return "  <strong>" + html.EscapeString(m.name) + "</strong> used by:"
 }
 
+type spdx struct {
+	ids []string
+}
+
+func (m spdx) isMatch(line string) bool {
+	groups := spdxIDs.FindStringSubmatch(line)
+	if len(groups) != 2 {
+		return false
+	}
+	var ids []string
+	for _, id := range spdxID.FindAllStringSubmatch(groups[1], -1) {
+		ids = append(ids, html.UnescapeString(id[1]))
+	}
+	return reflect.DeepEqual(ids, m.ids)
+}
+
+func (m spdx) String() string {
+	return "    <div class=\"spdx-ids\">" + strings.Join(m.ids, " ") + "</div>"
+}
+
 type usedBy struct {
 	name string
 }
@@ -1003,7 +2020,7 @@ func matchesText(line, text string) bool {
 }
 
 func expectedText(text string) string {
-	return `  <a href="#hash"/><pre class="license-text">` + html.EscapeString(text)
+	return `  <a id="hash"></a><pre class="license-text">` + html.EscapeString(text)
 }
 
 type firstParty struct{}
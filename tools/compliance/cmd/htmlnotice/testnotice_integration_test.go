@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"html"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/goldentest"
+)
+
+var (
+	textRule       = regexp.MustCompile(`^=+$`)
+	textLibUsedBy  = regexp.MustCompile(`^(\S.*) used by:$`)
+	htmlLibUsedBy  = regexp.MustCompile(`<strong id="lib[^"]*">(.*)</strong> used by:`)
+	htmlLicenseTag = regexp.MustCompile(`<pre class="license-text">(.*)`)
+)
+
+// libraryLicenses maps each library name to the sorted first lines of its
+// license texts.
+//
+// The fixture license texts start with a line naming the license condition
+// e.g. "%%%Notice License%%%", so the first lines identify the conditions.
+type libraryLicenses map[string][]string
+
+// add records that library `lib` uses the license text starting with `first`.
+func (ll libraryLicenses) add(lib, first string) {
+	for _, l := range ll[lib] {
+		if l == first {
+			return
+		}
+	}
+	ll[lib] = append(ll[lib], first)
+	sort.Strings(ll[lib])
+}
+
+// textLicenses returns the libraries and licenses in the textnotice output.
+func textLicenses(text string) libraryLicenses {
+	ll := make(libraryLicenses)
+	var libs []string
+	inHeader := false
+	for _, line := range strings.Split(text, "\n") {
+		if textRule.MatchString(line) {
+			libs = nil
+			inHeader = true
+			continue
+		}
+		if !inHeader {
+			continue
+		}
+		if m := textLibUsedBy.FindStringSubmatch(line); m != nil {
+			libs = append(libs, m[1])
+			continue
+		}
+		if len(strings.TrimSpace(line)) == 0 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		for _, lib := range libs {
+			ll.add(lib, strings.TrimSpace(line))
+		}
+		inHeader = false
+	}
+	return ll
+}
+
+// htmlLicenses returns the libraries and licenses in the htmlnotice output.
+func htmlLicenses(text string) libraryLicenses {
+	ll := make(libraryLicenses)
+	var libs []string
+	for _, line := range strings.Split(text, "\n") {
+		if horizontalRule.MatchString(line) {
+			libs = nil
+			continue
+		}
+		if m := htmlLibUsedBy.FindStringSubmatch(line); m != nil {
+			libs = append(libs, html.UnescapeString(m[1]))
+			continue
+		}
+		if m := htmlLicenseTag.FindStringSubmatch(line); m != nil {
+			for _, lib := range libs {
+				ll.add(lib, strings.TrimSpace(html.UnescapeString(m[1])))
+			}
+		}
+	}
+	return ll
+}
+
+// TestTextNoticeIntegration cross-checks the htmlnotice output against the
+// textnotice golden output for the same graph so that the formats cannot
+// diverge in the libraries listed or their licenses.
+func TestTextNoticeIntegration(t *testing.T) {
+	conditions := []string{"firstparty", "notice", "reciprocal", "restricted", "proprietary"}
+	targets := []struct {
+		name string
+		root string
+	}{
+		{"apex", "highest.apex.meta_lic"},
+		{"container", "container.zip.meta_lic"},
+		{"application", "application.meta_lic"},
+		{"binary", "bin/bin1.meta_lic"},
+		{"library", "lib/libd.so.meta_lic"},
+	}
+	for _, condition := range conditions {
+		for _, target := range targets {
+			t.Run(condition+"/"+target.name, func(t *testing.T) {
+				golden, err := os.ReadFile(goldentest.Path("textnotice/" + condition + "_" + target.name + ".txt"))
+				if err != nil {
+					t.Fatalf("cannot read textnotice output: %v", err)
+				}
+
+				stdout := &bytes.Buffer{}
+				stderr := &bytes.Buffer{}
+				var deps []string
+				ctx := context{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), deps: &deps}
+				err = htmlNotice(&ctx, "testdata/"+condition+"/"+target.root)
+				if err != nil {
+					t.Fatalf("htmlnotice: error = %v, stderr = %v", err, stderr)
+				}
+
+				textLibs := textLicenses(string(golden))
+				htmlLibs := htmlLicenses(stdout.String())
+				if len(textLibs) == 0 {
+					t.Fatalf("no libraries found in textnotice output:\n%s", golden)
+				}
+				for lib, licenses := range textLibs {
+					if _, ok := htmlLibs[lib]; !ok {
+						t.Errorf("library %q in textnotice output missing from htmlnotice output", lib)
+					} else if !reflect.DeepEqual(htmlLibs[lib], licenses) {
+						t.Errorf("library %q: htmlnotice licenses %q, textnotice licenses %q", lib, htmlLibs[lib], licenses)
+					}
+				}
+				for lib := range htmlLibs {
+					if _, ok := textLibs[lib]; !ok {
+						t.Errorf("library %q in htmlnotice output missing from textnotice output", lib)
+					}
+				}
+			})
+		}
+	}
+}
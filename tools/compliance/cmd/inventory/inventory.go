@@ -0,0 +1,278 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/projectmetadata"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout       io.Writer
+	stderr       io.Writer
+	rootFS       fs.FS
+	product      string
+	stripPrefix  []string
+	filterPrefix []string
+	ndjson       bool
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// selected returns true when -filter_prefix is absent or when any of
+// `installPaths` starts with one of its prefixes.
+func (ctx context) selected(installPaths []string) bool {
+	if len(ctx.filterPrefix) == 0 {
+		return true
+	}
+	for _, installPath := range installPaths {
+		for _, prefix := range ctx.filterPrefix {
+			if strings.HasPrefix(installPath, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// record describes a shipped target in the inventory.
+type record struct {
+	Target       string    `json:"target"`
+	PackageName  string    `json:"packageName"`
+	ModuleName   string    `json:"moduleName"`
+	Conditions   []string  `json:"conditions"`
+	LicenseKinds []string  `json:"licenseKinds"`
+	InstallPaths []string  `json:"installPaths"`
+	Projects     []project `json:"projects"`
+}
+
+// project describes the METADATA of a project building a target.
+type project struct {
+	Project string `json:"project"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs a JSON array with a record for each shipped target describing its
+package name, module name, license conditions, license kinds, install paths
+and the METADATA of its projects. Records appear in target name order.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the inventory. (default stdout)")
+	ndjson := flags.Bool("ndjson", false, "Write one record per line instead of a JSON array.")
+	product := flags.String("product", "", "The name of the product for which the inventory is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	filterPrefix := newMultiString(flags, "filter_prefix", "Only include targets with an install path, after -strip_prefix, starting with prefix (multiple allowed)")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, *filterPrefix, *ndjson}
+
+	err := inventory(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// inventory implements the inventory utility.
+func inventory(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+
+	shipped := compliance.ShippedNodes(licenseGraph)
+	targets := make(compliance.TargetNodeList, 0, len(shipped))
+	for tn := range shipped {
+		targets = append(targets, tn)
+	}
+	sort.Sort(targets)
+
+	pmix := projectmetadata.NewIndex(ctx.rootFS)
+
+	records := []record{}
+	for _, tn := range targets {
+		r, err := describeTarget(ctx, pmix, tn)
+		if err != nil {
+			return err
+		}
+		if !ctx.selected(r.InstallPaths) {
+			continue
+		}
+		records = append(records, r)
+	}
+
+	if ctx.ndjson {
+		enc := json.NewEncoder(ctx.stdout)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("Unable to write inventory: %v\n", err)
+			}
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to write inventory: %v\n", err)
+	}
+	fmt.Fprintln(ctx.stdout, string(data))
+	return nil
+}
+
+// describeTarget returns the inventory record for shipped target `tn`.
+func describeTarget(ctx *context, pmix *projectmetadata.Index, tn *compliance.TargetNode) (record, error) {
+	r := record{
+		Target:       tn.Name(),
+		PackageName:  tn.PackageName(),
+		ModuleName:   tn.ModuleName(),
+		Conditions:   append([]string{}, tn.LicenseConditions().Names()...),
+		LicenseKinds: append([]string{}, tn.LicenseKinds()...),
+		InstallPaths: []string{},
+		Projects:     []project{},
+	}
+	sort.Strings(r.Conditions)
+	sort.Strings(r.LicenseKinds)
+	for _, installPath := range tn.Installed() {
+		r.InstallPaths = append(r.InstallPaths, ctx.strip(installPath))
+	}
+	sort.Strings(r.InstallPaths)
+
+	pms, err := pmix.MetadataForProjects(tn.Projects()...)
+	if err != nil {
+		return record{}, fmt.Errorf("Unable to read project metadata for %q: %v\n", tn.Name(), err)
+	}
+	for _, pm := range pms {
+		r.Projects = append(r.Projects, project{pm.Project(), pm.Name(), pm.Version(), pm.UrlsByTypeName().DownloadUrl()})
+	}
+	return r, nil
+}
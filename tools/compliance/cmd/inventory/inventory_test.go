@@ -0,0 +1,240 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition string
+		expected  record
+	}{
+		{
+			condition: "firstparty",
+			expected: record{
+				Target:       "testdata/firstparty/lib/liba.so.meta_lic",
+				PackageName:  "Android",
+				Conditions:   []string{"notice"},
+				LicenseKinds: []string{"SPDX-license-identifier-Apache-2.0"},
+				InstallPaths: []string{"system/lib/liba.so"},
+				Projects:     []project{},
+			},
+		},
+		{
+			condition: "notice",
+			expected: record{
+				Target:       "testdata/notice/lib/liba.so.meta_lic",
+				PackageName:  "Device",
+				Conditions:   []string{"notice"},
+				LicenseKinds: []string{"SPDX-license-identifier-BSD"},
+				InstallPaths: []string{"system/lib/liba.so"},
+				Projects:     []project{},
+			},
+		},
+		{
+			condition: "reciprocal",
+			expected: record{
+				Target:       "testdata/reciprocal/lib/liba.so.meta_lic",
+				PackageName:  "Device",
+				Conditions:   []string{"reciprocal"},
+				LicenseKinds: []string{"SPDX-license-identifier-MPL"},
+				InstallPaths: []string{"system/lib/liba.so"},
+				Projects:     []project{},
+			},
+		},
+		{
+			condition: "restricted",
+			expected: record{
+				Target:       "testdata/restricted/lib/liba.so.meta_lic",
+				PackageName:  "Device",
+				Conditions:   []string{"restricted_if_statically_linked"},
+				LicenseKinds: []string{"SPDX-license-identifier-LGPL-2.0"},
+				InstallPaths: []string{"system/lib/liba.so"},
+				Projects:     []project{},
+			},
+		},
+		{
+			condition: "proprietary",
+			expected: record{
+				Target:       "testdata/proprietary/lib/liba.so.meta_lic",
+				PackageName:  "Device",
+				Conditions:   []string{"by_exception_only", "proprietary"},
+				LicenseKinds: []string{"legacy_proprietary"},
+				InstallPaths: []string{"system/lib/liba.so"},
+				Projects:     []project{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			root := "testdata/" + tt.condition + "/container.zip.meta_lic"
+			records := generate(t, compliance.GetFS(""), false, nil, root)
+
+			expectedTargets := []string{
+				"bin/bin1.meta_lic",
+				"bin/bin2.meta_lic",
+				"container.zip.meta_lic",
+				"lib/liba.so.meta_lic",
+				"lib/libb.so.meta_lic",
+				"lib/libc.a.meta_lic",
+			}
+			actualTargets := []string{}
+			for _, r := range records {
+				actualTargets = append(actualTargets, strings.TrimPrefix(r.Target, "testdata/"+tt.condition+"/"))
+				if r.Target == tt.expected.Target && !reflect.DeepEqual(r, tt.expected) {
+					t.Errorf("got record:\n%#v\nwant:\n%#v", r, tt.expected)
+				}
+			}
+			if !reflect.DeepEqual(actualTargets, expectedTargets) {
+				t.Errorf("got targets %q, want %q", actualTargets, expectedTargets)
+			}
+
+			ndjson := generate(t, compliance.GetFS(""), true, nil, root)
+			if !reflect.DeepEqual(ndjson, records) {
+				t.Errorf("got -ndjson records:\n%v\nwant:\n%v", ndjson, records)
+			}
+		})
+	}
+}
+
+func TestFilterPrefix(t *testing.T) {
+	tests := []struct {
+		name         string
+		filterPrefix []string
+		expected     []string
+	}{
+		{"none", nil, []string{"bin1", "bin2", "container.zip", "liba.so", "libb.so", "libc.a"}},
+		{"lib", []string{"system/lib/"}, []string{"liba.so", "libb.so"}},
+		{"multiple", []string{"system/bin/bin2", "data/"}, []string{"bin2", "container.zip"}},
+		{"nomatch", []string{"vendor/"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := generate(t, compliance.GetFS(""), false, tt.filterPrefix, "testdata/notice/container.zip.meta_lic")
+			actual := []string{}
+			for _, r := range records {
+				actual = append(actual, strings.TrimSuffix(r.Target[strings.LastIndex(r.Target, "/")+1:], ".meta_lic"))
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("got targets %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProjectMetadata(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin.meta_lic": &fstest.MapFile{Data: []byte(`package_name: "Bin"
+module_name: "bin"
+projects: "external/bin"
+license_kinds: "SPDX-license-identifier-MIT"
+license_conditions: "notice"
+installed: "out/target/product/fictional/system/bin/bin"
+installed: "out/target/product/fictional/system/bin/alias"
+`)},
+		"external/bin/METADATA": &fstest.MapFile{Data: []byte(`name: "bin"
+third_party {
+  version: "2.1"
+  url {
+    type: GIT
+    value: "https://example.com/bin.git"
+  }
+}
+`)},
+	}
+	records := generate(t, rootFS, false, nil, "bin.meta_lic")
+	expected := []record{{
+		Target:       "bin.meta_lic",
+		PackageName:  "Bin",
+		ModuleName:   "bin",
+		Conditions:   []string{"notice"},
+		LicenseKinds: []string{"SPDX-license-identifier-MIT"},
+		InstallPaths: []string{"system/bin/alias", "system/bin/bin"},
+		Projects:     []project{{"external/bin", "bin", "2.1", "https://example.com/bin.git"}},
+	}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("got records:\n%#v\nwant:\n%#v", records, expected)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	ctx := &context{stdout, stderr, compliance.GetFS(""), "", nil, nil, false}
+	if err := inventory(ctx); err != failNoneRequested {
+		t.Errorf("got error %v, want %v", err, failNoneRequested)
+	}
+	if err := inventory(ctx, "testdata/notice/missing.meta_lic"); err == nil {
+		t.Errorf("got no error for missing root, want error")
+	}
+}
+
+// generate runs the inventory for `roots` twice to check the output is
+// deterministic and returns the parsed records.
+func generate(t *testing.T, rootFS fs.FS, ndjson bool, filterPrefix []string, roots ...string) []record {
+	t.Helper()
+	run := func() []byte {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		ctx := &context{stdout, stderr, rootFS, "fictional", []string{"out/target/product/fictional/"}, filterPrefix, ndjson}
+		if err := inventory(ctx, roots...); err != nil {
+			t.Fatalf("inventory: error = %v, stderr = %v", err, stderr)
+		}
+		return stdout.Bytes()
+	}
+	data := run()
+	if again := run(); !bytes.Equal(data, again) {
+		t.Errorf("inventory: output not deterministic:\n%s\n%s", string(data), string(again))
+	}
+
+	records := []record{}
+	if !ndjson {
+		if err := json.Unmarshal(data, &records); err != nil {
+			t.Fatalf("inventory: unable to parse output: %s\n%s", err, string(data))
+		}
+		return records
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("inventory: unable to parse line: %s\n%s", err, line)
+		}
+		records = append(records, r)
+	}
+	return records
+}
@@ -0,0 +1,449 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/notice_proto"
+
+	"github.com/google/blueprint/deptools"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout        io.Writer
+	stderr        io.Writer
+	rootFS        fs.FS
+	product       string
+	stripPrefix   []string
+	skipBuildtime bool
+	// noTexts writes the hash of each license text instead of the text.
+	noTexts bool
+	// format selects the output format: "json", "proto" or "textproto".
+	format string
+	// overrides replace the license conditions of targets per -overrides_file.
+	overrides []compliance.Override
+	// delta applies -baseline and -write_baseline to the notice index.
+	delta *compliance.NoticeDelta
+	// missing applies -report_missing and -missing_fatal to the notice index.
+	missing *compliance.MissingTextReport
+	// emptyText selects how to index empty license texts per -empty_text.
+	emptyText compliance.EmptyTextMode
+	// keepGoing reports every malformed license metadata file per -keep_going.
+	keepGoing bool
+	deps      *[]string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// library describes a library in the notice.
+type library struct {
+	Name         string    `json:"name"`
+	LicenseKinds []string  `json:"licenseKinds"`
+	Conditions   []string  `json:"conditions"`
+	InstallPaths []string  `json:"installPaths"`
+	Projects     []project `json:"projects,omitempty"`
+	Licenses     []license `json:"licenses"`
+}
+
+// project describes the METADATA of a project building a library.
+type project struct {
+	Project string `json:"project"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// license describes a license text of a library.
+type license struct {
+	// Hash is "sha256:" followed by the hex digest of Text.
+	Hash string `json:"hash"`
+	// Text is the license text or empty with -no_texts.
+	Text string `json:"text,omitempty"`
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs a JSON array describing each library in the notice with its license
+kinds, conditions, install paths, project metadata and license texts.
+
+With -format=proto or -format=textproto, outputs a notice_proto.Notice
+message instead, which lists each distinct license text once by hash.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the JSON notice file. (default stdout)")
+	depsFile := flags.String("d", "", "Where to write the deps file")
+	product := flags.String("product", "", "The name of the product for which the notice is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	baseline := flags.String("baseline", "", "A baseline of license text hashes per library from a prior release. Only libraries with new or changed license texts appear in the notice, and removed libraries are listed on stderr.")
+	writeBaseline := flags.String("write_baseline", "", "Where to write the baseline of license text hashes per library for use with -baseline in the next release.")
+	reportMissing := flags.String("report_missing", "", "Where to write the shipped targets contributing no license text to the notice with their license conditions and install paths.")
+	missingFatal := flags.Bool("missing_fatal", false, "Whether to fail when any shipped target contributes no license text to the notice.")
+	emptyTextName := flags.String("empty_text", "keep", "How to treat license text files that are empty or only whitespace after warning about them: skip, placeholder or keep.")
+	keepGoing := flags.Bool("keep_going", false, "Report every malformed license metadata file, up to 100, instead of stopping at the first.")
+	zipFile := flags.String("zip", "", "Read the license metadata, license texts and METADATA files from this zip archive, or from a directory in it given as archive.zip!/dir.")
+	overridesFile := flags.String("overrides_file", "", "A YAML file of {path, condition} entries replacing the license conditions declared by the named license metadata files.")
+	skipBuildtime := flags.Bool("skip_buildtime", false, "Whether to ignore dependencies annotated \"buildtime\" or \"test\" so their licenses do not apply.")
+	noTexts := flags.Bool("no_texts", false, "Whether to write only the hash of each license text instead of the text.")
+	format := flags.String("format", "json", "The output format: json, proto for a binary notice_proto.Notice, or textproto.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	switch *format {
+	case "json", "proto", "textproto":
+	default:
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "unknown -format %q; use json, proto or textproto\n", *format)
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	var deps []string
+
+	var overrides []compliance.Override
+	if len(*overridesFile) > 0 {
+		var err error
+		overrides, err = compliance.ReadOverridesFile(*overridesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read overrides file %q: %s\n", *overridesFile, err)
+			os.Exit(1)
+		}
+	}
+
+	emptyText, err := compliance.ParseEmptyTextMode(*emptyTextName)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	var rootFS fs.FS = compliance.FS
+	var zipFS *compliance.ZipFS
+	if len(*zipFile) > 0 {
+		zipFS, err = compliance.OpenZipFS(*zipFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *zipFile, err)
+			os.Exit(1)
+		}
+		rootFS = zipFS
+	}
+
+	ctx := &context{ofile, os.Stderr, rootFS, *product, *stripPrefix, *skipBuildtime, *noTexts, *format, overrides, &compliance.NoticeDelta{Baseline: *baseline, WriteBaseline: *writeBaseline}, &compliance.MissingTextReport{File: *reportMissing, Fatal: *missingFatal}, emptyText, *keepGoing, &deps}
+
+	err = jsonNotice(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	if *depsFile != "" {
+		if zipFS != nil {
+			// The files read are inside the archive.
+			deps = []string{zipFS.Archive()}
+		}
+		if len(*baseline) > 0 {
+			deps = append(deps, *baseline)
+		}
+		if len(*overridesFile) > 0 {
+			deps = append(deps, *overridesFile)
+		}
+		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// jsonNotice implements the jsonnotice utility.
+func jsonNotice(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraphWithOptions(gocontext.Background(), rootFS, ctx.stderr, files, compliance.ReadOptions{KeepGoing: ctx.keepGoing})
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+	if len(ctx.overrides) > 0 {
+		licenseGraph = compliance.ApplyOverrides(licenseGraph, ctx.overrides)
+	}
+	if ctx.skipBuildtime {
+		licenseGraph.SkipBuildtimeDeps()
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTextsWithOptions(gocontext.Background(), rootFS, licenseGraph, rs, compliance.IndexOptions{EmptyText: ctx.emptyText})
+	if err != nil {
+		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
+	}
+	compliance.WarnEmptyTexts(ctx.stderr, ni)
+	if err := ctx.delta.Apply(ni, ctx.stderr); err != nil {
+		return err
+	}
+	if err := ctx.missing.Apply(ni); err != nil {
+		return err
+	}
+
+	libs := []library{}
+	for _, libName := range ni.AllLibraries() {
+		lib, err := describeLibrary(ctx, ni, libName)
+		if err != nil {
+			return fmt.Errorf("Unable to read project metadata for %q: %v\n", libName, err)
+		}
+		libs = append(libs, lib)
+	}
+
+	switch ctx.format {
+	case "proto":
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(noticeProto(ctx, libs))
+		if err != nil {
+			return fmt.Errorf("Unable to write proto notice: %v\n", err)
+		}
+		ctx.stdout.Write(data)
+	case "textproto":
+		data, err := prototext.MarshalOptions{Multiline: true}.Marshal(noticeProto(ctx, libs))
+		if err != nil {
+			return fmt.Errorf("Unable to write textproto notice: %v\n", err)
+		}
+		ctx.stdout.Write(data)
+	default:
+		data, err := json.MarshalIndent(libs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Unable to write json notice: %v\n", err)
+		}
+		fmt.Fprintln(ctx.stdout, string(data))
+	}
+
+	*ctx.deps = rootFS.Files()
+
+	return nil
+}
+
+// describeLibrary returns the description of library `libName` in `ni`.
+//
+// The license texts appear in notice order and every other list is sorted so
+// that identical inputs produce identical output.
+func describeLibrary(ctx *context, ni *compliance.NoticeIndex, libName string) (library, error) {
+	lib := library{Name: libName, LicenseKinds: []string{}, Conditions: []string{}, InstallPaths: []string{}}
+
+	kinds := make(map[string]struct{})
+	installPaths := make(map[string]struct{})
+	var conditions compliance.LicenseConditionSet
+	for _, h := range ni.LibHashes(libName) {
+		for _, kind := range ni.HashLibLicenseKinds(h, libName) {
+			kinds[kind] = struct{}{}
+		}
+		for _, installPath := range ni.HashLibInstalls(h, libName) {
+			installPaths[ctx.strip(installPath)] = struct{}{}
+			conditions = conditions.Union(ni.InstallHashLibConditions(installPath, h, libName))
+		}
+		text, err := ni.HashText(h)
+		if err != nil {
+			return library{}, err
+		}
+		l := license{Hash: fmt.Sprintf("sha256:%x", sha256.Sum256(text))}
+		if !ctx.noTexts {
+			l.Text = string(text)
+		}
+		lib.Licenses = append(lib.Licenses, l)
+	}
+	for kind := range kinds {
+		lib.LicenseKinds = append(lib.LicenseKinds, kind)
+	}
+	sort.Strings(lib.LicenseKinds)
+	for installPath := range installPaths {
+		lib.InstallPaths = append(lib.InstallPaths, installPath)
+	}
+	sort.Strings(lib.InstallPaths)
+	lib.Conditions = append(lib.Conditions, conditions.Names()...)
+	sort.Strings(lib.Conditions)
+
+	pms, err := ni.LibProjectMetadata(libName)
+	if err != nil {
+		return library{}, err
+	}
+	for _, pm := range pms {
+		lib.Projects = append(lib.Projects, project{pm.Project(), pm.Name(), pm.Version(), pm.UrlsByTypeName().DownloadUrl()})
+	}
+	return lib, nil
+}
+
+// noticeProto converts `libs` into a notice_proto.Notice listing each distinct
+// license text once ordered by hash.
+func noticeProto(ctx *context, libs []library) *notice_proto.Notice {
+	n := &notice_proto.Notice{}
+	if len(ctx.product) > 0 {
+		n.Product = proto.String(ctx.product)
+	}
+	texts := make(map[string]string)
+	for _, lib := range libs {
+		l := &notice_proto.Library{
+			Name:         proto.String(lib.Name),
+			LicenseKinds: lib.LicenseKinds,
+			Conditions:   lib.Conditions,
+			InstallPaths: lib.InstallPaths,
+		}
+		for _, license := range lib.Licenses {
+			l.TextHashes = append(l.TextHashes, license.Hash)
+			texts[license.Hash] = license.Text
+		}
+		for _, p := range lib.Projects {
+			pp := &notice_proto.Project{Project: proto.String(p.Project)}
+			if len(p.Name) > 0 {
+				pp.Name = proto.String(p.Name)
+			}
+			if len(p.Version) > 0 {
+				pp.Version = proto.String(p.Version)
+			}
+			if len(p.URL) > 0 {
+				pp.Url = proto.String(p.URL)
+			}
+			l.Projects = append(l.Projects, pp)
+		}
+		n.Libraries = append(n.Libraries, l)
+	}
+	sort.Slice(n.Libraries, func(i, j int) bool { return n.Libraries[i].GetName() < n.Libraries[j].GetName() })
+
+	hashes := make([]string, 0, len(texts))
+	for h := range texts {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+	for _, h := range hashes {
+		t := &notice_proto.LicenseText{Hash: proto.String(h)}
+		if !ctx.noTexts {
+			t.Text = proto.String(texts[h])
+		}
+		n.Texts = append(n.Texts, t)
+	}
+	return n
+}
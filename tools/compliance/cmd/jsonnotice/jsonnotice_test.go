@@ -0,0 +1,424 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/notice_proto"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// expectedLibrary describes a library expected in the json notice.
+type expectedLibrary struct {
+	name         string
+	licenseKinds []string
+	conditions   []string
+	installPaths []string
+	licenses     int
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition string
+		name      string
+		roots     []string
+		expected  []expectedLibrary
+	}{
+		{
+			condition: "firstparty",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice"},
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/liba.so", "system/apex/highest.apex/lib/libb.so"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "firstparty",
+			name:      "application",
+			roots:     []string{"application.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice"},
+					[]string{"bin/application"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "firstparty",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice"},
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/libb.so"},
+					1,
+				},
+				{
+					"Device",
+					[]string{"SPDX-license-identifier-BSD"},
+					[]string{"notice"},
+					[]string{"system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/lib/liba.so"},
+					1,
+				},
+				{
+					"External",
+					[]string{"SPDX-license-identifier-MIT"},
+					[]string{"notice"},
+					[]string{"system/apex/highest.apex/bin/bin1"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "application",
+			roots:     []string{"application.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice"},
+					[]string{"bin/application"},
+					1,
+				},
+				{
+					"Device",
+					[]string{"SPDX-license-identifier-BSD"},
+					[]string{"notice"},
+					[]string{"bin/application"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+				{
+					"Device",
+					[]string{"SPDX-license-identifier-BSD"},
+					[]string{"notice"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+				{
+					"External",
+					[]string{"SPDX-license-identifier-MIT"},
+					[]string{"notice"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-GPL-2.0"},
+					[]string{"notice", "restricted", "restricted_if_statically_linked"},
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/libb.so"},
+					2,
+				},
+				{
+					"Device",
+					[]string{"SPDX-license-identifier-LGPL-2.0"},
+					[]string{"restricted_if_statically_linked"},
+					[]string{"system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/lib/liba.so"},
+					1,
+				},
+				{
+					"External",
+					[]string{"SPDX-license-identifier-MPL"},
+					[]string{"reciprocal", "restricted_if_statically_linked"},
+					[]string{"system/apex/highest.apex/bin/bin1"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "application",
+			roots:     []string{"application.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice", "restricted", "restricted_if_statically_linked"},
+					[]string{"bin/application"},
+					1,
+				},
+				{
+					"Device",
+					[]string{"SPDX-license-identifier-LGPL-2.0"},
+					[]string{"restricted", "restricted_if_statically_linked"},
+					[]string{"bin/application"},
+					1,
+				},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []expectedLibrary{
+				{
+					"Android",
+					[]string{"SPDX-license-identifier-Apache-2.0"},
+					[]string{"notice", "restricted_if_statically_linked"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+				{
+					"Device",
+					[]string{"SPDX-license-identifier-LGPL-2.0"},
+					[]string{"restricted_if_statically_linked"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+				{
+					"External",
+					[]string{"SPDX-license-identifier-MPL"},
+					[]string{"reciprocal", "restricted_if_statically_linked"},
+					[]string{"system/bin/bin1"},
+					1,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, noTexts := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s %s no_texts=%t", tt.condition, tt.name, noTexts), func(t *testing.T) {
+				rootFiles := make([]string, 0, len(tt.roots))
+				for _, r := range tt.roots {
+					rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+				}
+
+				stdout, stderr := notice(t, compliance.GetFS(""), noTexts, rootFiles...)
+				if stderr.Len() > 0 {
+					t.Errorf("jsonnotice: gotStderr = %v, want none", stderr)
+				}
+
+				var libs []library
+				if err := json.Unmarshal(stdout.Bytes(), &libs); err != nil {
+					t.Fatalf("jsonnotice: cannot parse output: %v\n%s", err, stdout)
+				}
+				if len(libs) != len(tt.expected) {
+					t.Fatalf("jsonnotice: got %d libraries %v, want %d", len(libs), libs, len(tt.expected))
+				}
+				for i, lib := range libs {
+					expected := tt.expected[i]
+					actual := expectedLibrary{lib.Name, lib.LicenseKinds, lib.Conditions, lib.InstallPaths, len(lib.Licenses)}
+					if !reflect.DeepEqual(actual, expected) {
+						t.Errorf("jsonnotice: got library %+v, want %+v", actual, expected)
+					}
+					for _, l := range lib.Licenses {
+						if noTexts {
+							if len(l.Text) > 0 {
+								t.Errorf("jsonnotice: got text for %q with -no_texts, want none", lib.Name)
+							}
+							continue
+						}
+						if len(l.Text) == 0 {
+							t.Errorf("jsonnotice: got no text for %q, want text", lib.Name)
+						}
+						if expected := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(l.Text))); l.Hash != expected {
+							t.Errorf("jsonnotice: got hash %q for %q, want %q", l.Hash, lib.Name, expected)
+						}
+					}
+				}
+
+				again, _ := notice(t, compliance.GetFS(""), noTexts, rootFiles...)
+				if !bytes.Equal(again.Bytes(), stdout.Bytes()) {
+					t.Errorf("jsonnotice: got different output for identical inputs:\n%s\nwant:\n%s", again, stdout)
+				}
+			})
+		}
+	}
+}
+
+func TestProjectMetadata(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "Android"
+projects: "external/bin1"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin1"
+`)},
+		"external/bin1/METADATA": {Data: []byte(`name: "bin1"
+third_party {
+  version: "2.1"
+  url {
+    type: GIT
+    value: "https://example.com/bin1.git"
+  }
+}
+`)},
+		"LICENSE": {Data: []byte("%%%Notice License%%%\n")},
+	}
+
+	stdout, stderr := notice(t, rootFS, false, "bin/bin1.meta_lic")
+
+	var libs []library
+	if err := json.Unmarshal(stdout.Bytes(), &libs); err != nil {
+		t.Fatalf("jsonnotice: cannot parse output: %v\n%s", err, stdout)
+	}
+	if len(libs) != 1 {
+		t.Fatalf("jsonnotice: got %d libraries %v, want 1, stderr = %v", len(libs), libs, stderr)
+	}
+	expected := []project{{"external/bin1", "bin1", "2.1", "https://example.com/bin1.git"}}
+	if !reflect.DeepEqual(libs[0].Projects, expected) {
+		t.Errorf("jsonnotice: got projects %+v, want %+v", libs[0].Projects, expected)
+	}
+}
+
+func TestProtoFormats(t *testing.T) {
+	for _, condition := range []string{"firstparty", "notice", "reciprocal", "restricted", "proprietary"} {
+		for _, noTexts := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s no_texts=%v", condition, noTexts), func(t *testing.T) {
+				root := "testdata/" + condition + "/highest.apex.meta_lic"
+				stdout, _ := noticeFormat(t, compliance.GetFS(""), "json", noTexts, root)
+				var libs []library
+				if err := json.Unmarshal(stdout.Bytes(), &libs); err != nil {
+					t.Fatalf("jsonnotice: cannot parse json output: %v", err)
+				}
+				expected := noticeProto(&context{noTexts: noTexts}, libs)
+
+				stdout, _ = noticeFormat(t, compliance.GetFS(""), "proto", noTexts, root)
+				binary := stdout.Bytes()
+				actual := &notice_proto.Notice{}
+				if err := proto.Unmarshal(binary, actual); err != nil {
+					t.Fatalf("jsonnotice: cannot parse proto output: %v", err)
+				}
+				if !proto.Equal(actual, expected) {
+					t.Errorf("jsonnotice: got proto %v, want %v", actual, expected)
+				}
+				if again, err := (proto.MarshalOptions{Deterministic: true}).Marshal(actual); err != nil || !bytes.Equal(again, binary) {
+					t.Errorf("jsonnotice: proto round trip changed output: %v", err)
+				}
+
+				stdout, _ = noticeFormat(t, compliance.GetFS(""), "textproto", noTexts, root)
+				text := &notice_proto.Notice{}
+				if err := prototext.Unmarshal(stdout.Bytes(), text); err != nil {
+					t.Fatalf("jsonnotice: cannot parse textproto output: %v\n%s", err, stdout)
+				}
+				if !proto.Equal(text, actual) {
+					t.Errorf("jsonnotice: got textproto %v, want %v", text, actual)
+				}
+
+				texts := make(map[string]*notice_proto.LicenseText)
+				for _, lt := range actual.GetTexts() {
+					texts[lt.GetHash()] = lt
+					if noTexts != (lt.Text == nil) {
+						t.Errorf("jsonnotice: got text %q for %s with no_texts=%v", lt.GetText(), lt.GetHash(), noTexts)
+					} else if !noTexts {
+						if h := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(lt.GetText()))); h != lt.GetHash() {
+							t.Errorf("jsonnotice: got hash %s for text with hash %s", lt.GetHash(), h)
+						}
+					}
+				}
+				for _, lib := range actual.GetLibraries() {
+					if len(lib.GetTextHashes()) == 0 {
+						t.Errorf("jsonnotice: library %q has no texts", lib.GetName())
+					}
+					for _, h := range lib.GetTextHashes() {
+						if _, ok := texts[h]; !ok {
+							t.Errorf("jsonnotice: library %q refers to missing text %s", lib.GetName(), h)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// notice returns the output and the error output of jsonnotice for `roots`.
+func notice(t *testing.T, rootFS fs.FS, noTexts bool, roots ...string) (*bytes.Buffer, *bytes.Buffer) {
+	return noticeFormat(t, rootFS, "json", noTexts, roots...)
+}
+
+// noticeFormat returns the output and the error output of jsonnotice for
+// `roots` in output format `format`.
+func noticeFormat(t *testing.T, rootFS fs.FS, format string, noTexts bool, roots ...string) (*bytes.Buffer, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	ctx := context{stdout, stderr, rootFS, "", []string{"out/target/product/fictional/"}, false, noTexts, format, nil, nil, nil, 0, false, &deps}
+
+	err := jsonNotice(&ctx, roots...)
+	if err != nil {
+		t.Fatalf("jsonnotice: error = %v, stderr = %v", err, stderr)
+	}
+	return stdout, stderr
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+
+	"github.com/google/blueprint/deptools"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+
+	// textFile matches the names of the license text files in the pool.
+	textFile = regexp.MustCompile(`^[0-9a-f]{64}\.txt$`)
+)
+
+// indexFile names the index of the license texts within the output directory.
+const indexFile = "index.json"
+
+type context struct {
+	stdout        io.Writer
+	stderr        io.Writer
+	rootFS        fs.FS
+	product       string
+	stripPrefix   []string
+	skipBuildtime bool
+	// outDir names the directory holding the license texts and the index.
+	outDir string
+	// prune removes the license texts no longer referenced from outDir.
+	prune bool
+	deps  *[]string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+// usage describes a library using a license text in the index.
+type usage struct {
+	Library      string   `json:"library"`
+	Targets      []string `json:"targets"`
+	LicenseKinds []string `json:"licenseKinds"`
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} -outdir dir file.meta_lic {file.meta_lic...}
+
+Writes each distinct license text in the notice to dir/<sha256>.txt, where
+<sha256> is the hex digest of the text, and writes dir/%s mapping each
+hash to the libraries using the text with their install paths and license
+kinds.
+
+Skips the texts already in dir. With -prune, also removes the texts in dir
+that the notice no longer references.
+
+Options:
+`, filepath.Base(os.Args[0]), indexFile)
+		flags.PrintDefaults()
+	}
+
+	outDir := flags.String("outdir", "", "The directory holding the license texts and the index.")
+	depsFile := flags.String("d", "", "Where to write the deps file")
+	product := flags.String("product", "", "The name of the product for which the notice is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	skipBuildtime := flags.Bool("skip_buildtime", false, "Whether to ignore dependencies annotated \"buildtime\" or \"test\" so their licenses do not apply.")
+	prune := flags.Bool("prune", false, "Whether to remove the license texts no longer referenced from -outdir.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outDir) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify directory for -outdir\n")
+		os.Exit(2)
+	}
+
+	var deps []string
+
+	ctx := &context{os.Stdout, os.Stderr, compliance.FS, *product, *stripPrefix, *skipBuildtime, *outDir, *prune, &deps}
+
+	err := licenseDump(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *depsFile != "" {
+		err := deptools.WriteDepFile(*depsFile, filepath.Join(*outDir, indexFile), deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// licenseDump implements the licensedump utility.
+func licenseDump(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(rootFS, ctx.stderr, files)
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+	if ctx.skipBuildtime {
+		licenseGraph.SkipBuildtimeDeps()
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTexts(rootFS, licenseGraph, rs)
+	if err != nil {
+		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
+	}
+
+	if err := os.MkdirAll(ctx.outDir, 0777); err != nil {
+		return fmt.Errorf("Unable to create %q: %v\n", ctx.outDir, err)
+	}
+
+	// Hash the texts the same way as jsonnotice so the index and the
+	// notices agree.
+	index := make(map[string][]usage)
+	written, skipped, pruned := 0, 0, 0
+	for _, h := range ni.AllHashes() {
+		text, err := ni.HashText(h)
+		if err != nil {
+			return fmt.Errorf("Unable to read license text %s: %v\n", h, err)
+		}
+		digest := fmt.Sprintf("%x", sha256.Sum256(text))
+
+		// Texts differing only in normalized copyrights share a digest.
+		usages, seen := index[digest]
+		for _, libName := range ni.HashLibs(h) {
+			u := usage{libName, []string{}, []string{}}
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				u.Targets = append(u.Targets, ctx.strip(installPath))
+			}
+			sort.Strings(u.Targets)
+			u.LicenseKinds = append(u.LicenseKinds, ni.HashLibLicenseKinds(h, libName)...)
+			sort.Strings(u.LicenseKinds)
+			usages = append(usages, u)
+		}
+		sort.Slice(usages, func(i, j int) bool {
+			return usages[i].Library < usages[j].Library
+		})
+		index[digest] = usages
+		if seen {
+			continue
+		}
+
+		path := filepath.Join(ctx.outDir, digest+".txt")
+		if _, err := os.Stat(path); err == nil {
+			skipped++
+			continue
+		}
+		if err := os.WriteFile(path, text, 0666); err != nil {
+			return fmt.Errorf("Unable to write license text %q: %v\n", path, err)
+		}
+		written++
+	}
+
+	if ctx.prune {
+		entries, err := os.ReadDir(ctx.outDir)
+		if err != nil {
+			return fmt.Errorf("Unable to read %q: %v\n", ctx.outDir, err)
+		}
+		for _, e := range entries {
+			if e.IsDir() || !textFile.MatchString(e.Name()) {
+				continue
+			}
+			if _, ok := index[strings.TrimSuffix(e.Name(), ".txt")]; ok {
+				continue
+			}
+			if err := os.Remove(filepath.Join(ctx.outDir, e.Name())); err != nil {
+				return fmt.Errorf("Unable to prune %q: %v\n", e.Name(), err)
+			}
+			pruned++
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to write index: %v\n", err)
+	}
+	indexPath := filepath.Join(ctx.outDir, indexFile)
+	if err := os.WriteFile(indexPath, append(data, '\n'), 0666); err != nil {
+		return fmt.Errorf("Unable to write index %q: %v\n", indexPath, err)
+	}
+
+	fmt.Fprintf(ctx.stdout, "%d license texts: %d written, %d already present, %d pruned\n", len(index), written, skipped, pruned)
+
+	*ctx.deps = rootFS.Files()
+
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// dump runs licensedump for `roots` writing to `outDir` and returns the index.
+func dump(t *testing.T, outDir string, prune bool, roots ...string) map[string][]usage {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	var deps []string
+	ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, false, outDir, prune, &deps}
+	if err := licenseDump(&ctx, roots...); err != nil {
+		t.Fatalf("licensedump: error = %v, stderr = %v", err, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, indexFile))
+	if err != nil {
+		t.Fatalf("licensedump: cannot read index: %v", err)
+	}
+	var index map[string][]usage
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("licensedump: cannot parse index %q: %v", string(data), err)
+	}
+	return index
+}
+
+// textFiles returns the names of the license text files in `dir`.
+func textFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("cannot read %q: %v", dir, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".txt") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+func Test(t *testing.T) {
+	outDir := t.TempDir()
+	index := dump(t, outDir, false, "testdata/restricted/container.zip.meta_lic")
+
+	libs := make(map[string][]string)
+	for digest, usages := range index {
+		text, err := os.ReadFile(filepath.Join(outDir, digest+".txt"))
+		if err != nil {
+			t.Fatalf("licensedump: cannot read text for %s: %v", digest, err)
+		}
+		if actual := fmt.Sprintf("%x", sha256.Sum256(text)); actual != digest {
+			t.Errorf("licensedump: got hash %s for text %s", actual, digest)
+		}
+		for _, u := range usages {
+			libs[u.Library] = append(libs[u.Library], strings.TrimSpace(string(text)))
+		}
+	}
+	for _, texts := range libs {
+		sort.Strings(texts)
+	}
+	expected := map[string][]string{
+		"Android":  {"###Restricted License###", "&&&First Party License&&&"},
+		"Device":   {"###Restricted License###"},
+		"External": {"$$$Reciprocal License$$$"},
+	}
+	if !reflect.DeepEqual(libs, expected) {
+		t.Errorf("licensedump: got libraries %q, want %q", libs, expected)
+	}
+	if len(textFiles(t, outDir)) != len(index) {
+		t.Errorf("licensedump: got text files %q for %d hashes", textFiles(t, outDir), len(index))
+	}
+
+	var external []usage
+	for _, usages := range index {
+		for _, u := range usages {
+			if u.Library == "External" {
+				external = append(external, u)
+			}
+		}
+	}
+	expectedUsage := []usage{{"External", []string{"data/container.zip/bin1"}, []string{"SPDX-license-identifier-MPL"}}}
+	if !reflect.DeepEqual(external, expectedUsage) {
+		t.Errorf("licensedump: got usages %+v for External, want %+v", external, expectedUsage)
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	outDir := t.TempDir()
+	index := dump(t, outDir, false, "testdata/restricted/container.zip.meta_lic")
+
+	// Mark an existing text to show the next run leaves it alone.
+	var digest string
+	for digest = range index {
+		break
+	}
+	marked := filepath.Join(outDir, digest+".txt")
+	if err := os.WriteFile(marked, []byte("marked"), 0666); err != nil {
+		t.Fatalf("cannot mark %q: %v", marked, err)
+	}
+	other := filepath.Join(outDir, "README")
+	if err := os.WriteFile(other, []byte("not a license text"), 0666); err != nil {
+		t.Fatalf("cannot write %q: %v", other, err)
+	}
+
+	dump(t, outDir, false, "testdata/restricted/container.zip.meta_lic")
+	if data, _ := os.ReadFile(marked); string(data) != "marked" {
+		t.Errorf("licensedump: rewrote existing text %q", marked)
+	}
+
+	// Without -prune the texts of the old notice remain.
+	newIndex := dump(t, outDir, false, "testdata/notice/bin/bin1.meta_lic")
+	if len(textFiles(t, outDir)) <= len(newIndex) {
+		t.Errorf("licensedump: got text files %q without -prune, want the old texts kept", textFiles(t, outDir))
+	}
+
+	// With -prune only the texts of the new notice remain.
+	newIndex = dump(t, outDir, true, "testdata/notice/bin/bin1.meta_lic")
+	var expected []string
+	for digest := range newIndex {
+		expected = append(expected, digest+".txt")
+	}
+	sort.Strings(expected)
+	if actual := textFiles(t, outDir); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("licensedump -prune: got text files %q, want %q", actual, expected)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("licensedump -prune: removed %q: %v", other, err)
+	}
+}
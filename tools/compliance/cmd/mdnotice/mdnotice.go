@@ -0,0 +1,400 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+
+	"github.com/google/blueprint/deptools"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+// markdownSpecial matches the characters with special meaning in Markdown
+// inline text.
+var markdownSpecial = regexp.MustCompile("[\\\\`*_{}\\[\\]()<>#+\\-.!|~&]")
+
+type context struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	rootFS      fs.FS
+	product     string
+	stripPrefix []string
+	title       string
+	// toc writes a table of contents linking to each library.
+	toc bool
+	// overrides replace the license conditions of targets per -overrides_file.
+	overrides []compliance.Override
+	// delta applies -baseline and -write_baseline to the notice index.
+	delta *compliance.NoticeDelta
+	// missing applies -report_missing and -missing_fatal to the notice index.
+	missing *compliance.MissingTextReport
+	// emptyText selects how to index empty license texts per -empty_text.
+	emptyText compliance.EmptyTextMode
+	// keepGoing reports every malformed license metadata file per -keep_going.
+	keepGoing bool
+	deps      *[]string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs a Markdown NOTICE file with a section for each library listing its
+install paths and license texts.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the NOTICE markdown file. (default stdout)")
+	depsFile := flags.String("d", "", "Where to write the deps file")
+	product := flags.String("product", "", "The name of the product for which the notice is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	baseline := flags.String("baseline", "", "A baseline of license text hashes per library from a prior release. Only libraries with new or changed license texts appear in the notice, and removed libraries are listed on stderr.")
+	writeBaseline := flags.String("write_baseline", "", "Where to write the baseline of license text hashes per library for use with -baseline in the next release.")
+	reportMissing := flags.String("report_missing", "", "Where to write the shipped targets contributing no license text to the notice with their license conditions and install paths.")
+	missingFatal := flags.Bool("missing_fatal", false, "Whether to fail when any shipped target contributes no license text to the notice.")
+	emptyTextName := flags.String("empty_text", "keep", "How to treat license text files that are empty or only whitespace after warning about them: skip, placeholder or keep.")
+	keepGoing := flags.Bool("keep_going", false, "Report every malformed license metadata file, up to 100, instead of stopping at the first.")
+	zipFile := flags.String("zip", "", "Read the license metadata, license texts and METADATA files from this zip archive, or from a directory in it given as archive.zip!/dir.")
+	overridesFile := flags.String("overrides_file", "", "A YAML file of {path, condition} entries replacing the license conditions declared by the named license metadata files.")
+	title := flags.String("title", "", "The title of the notice file.")
+	toc := flags.Bool("toc", false, "Whether to write a table of contents linking to each library.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	var deps []string
+
+	var overrides []compliance.Override
+	if len(*overridesFile) > 0 {
+		var err error
+		overrides, err = compliance.ReadOverridesFile(*overridesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read overrides file %q: %s\n", *overridesFile, err)
+			os.Exit(1)
+		}
+	}
+
+	emptyText, err := compliance.ParseEmptyTextMode(*emptyTextName)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	var rootFS fs.FS = compliance.FS
+	var zipFS *compliance.ZipFS
+	if len(*zipFile) > 0 {
+		zipFS, err = compliance.OpenZipFS(*zipFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *zipFile, err)
+			os.Exit(1)
+		}
+		rootFS = zipFS
+	}
+
+	ctx := &context{ofile, os.Stderr, rootFS, *product, *stripPrefix, *title, *toc, overrides, &compliance.NoticeDelta{Baseline: *baseline, WriteBaseline: *writeBaseline}, &compliance.MissingTextReport{File: *reportMissing, Fatal: *missingFatal}, emptyText, *keepGoing, &deps}
+
+	err = mdNotice(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	if *depsFile != "" {
+		if zipFS != nil {
+			// The files read are inside the archive.
+			deps = []string{zipFS.Archive()}
+		}
+		if len(*baseline) > 0 {
+			deps = append(deps, *baseline)
+		}
+		if len(*overridesFile) > 0 {
+			deps = append(deps, *overridesFile)
+		}
+		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// mdNotice implements the mdnotice utility.
+func mdNotice(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraphWithOptions(gocontext.Background(), rootFS, ctx.stderr, files, compliance.ReadOptions{KeepGoing: ctx.keepGoing})
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+	if len(ctx.overrides) > 0 {
+		licenseGraph = compliance.ApplyOverrides(licenseGraph, ctx.overrides)
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTextsWithOptions(gocontext.Background(), rootFS, licenseGraph, rs, compliance.IndexOptions{EmptyText: ctx.emptyText})
+	if err != nil {
+		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
+	}
+	compliance.WarnEmptyTexts(ctx.stderr, ni)
+	if err := ctx.delta.Apply(ni, ctx.stderr); err != nil {
+		return err
+	}
+	if err := ctx.missing.Apply(ni); err != nil {
+		return err
+	}
+
+	var libs []string
+	for _, libName := range ni.AllLibraries() {
+		libs = append(libs, libName)
+	}
+
+	if len(ctx.title) > 0 {
+		fmt.Fprintf(ctx.stdout, "# %s\n\n", escape(ctx.title))
+	}
+	if ctx.toc {
+		anchors := newAnchors()
+		if len(ctx.title) > 0 {
+			anchors.anchor(ctx.title)
+		}
+		for _, libName := range libs {
+			fmt.Fprintf(ctx.stdout, "- [%s](#%s)\n", escape(libName), anchors.anchor(libName))
+		}
+		fmt.Fprintln(ctx.stdout)
+	}
+	for _, libName := range libs {
+		fmt.Fprintf(ctx.stdout, "## %s\n\n", escape(libName))
+
+		hashes := ni.LibHashes(libName)
+		installed := make(map[string]struct{})
+		for _, h := range hashes {
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				installPath = ctx.strip(installPath)
+				if _, ok := installed[installPath]; ok {
+					continue
+				}
+				installed[installPath] = struct{}{}
+				fmt.Fprintf(ctx.stdout, "- %s\n", codeSpan(installPath))
+			}
+		}
+		fmt.Fprintln(ctx.stdout)
+		for _, h := range hashes {
+			text, err := ni.HashText(h)
+			if err != nil {
+				return err
+			}
+			writeCodeBlock(ctx.stdout, string(text))
+		}
+	}
+
+	*ctx.deps = rootFS.Files()
+
+	return nil
+}
+
+// escape returns `text` with the characters with special meaning in Markdown
+// escaped by backslashes.
+func escape(text string) string {
+	return markdownSpecial.ReplaceAllString(text, "\\$0")
+}
+
+// codeSpan returns `text` as inline code.
+func codeSpan(text string) string {
+	// Delimit with more backticks than any run in the text and pad with
+	// spaces so leading or trailing backticks do not merge.
+	fence := strings.Repeat("`", longestRun(text, '`')+1)
+	return fence + " " + text + " " + fence
+}
+
+// writeCodeBlock writes `text` to `w` as a fenced code block.
+func writeCodeBlock(w io.Writer, text string) {
+	n := longestRun(text, '`') + 1
+	if n < 3 {
+		n = 3
+	}
+	fence := strings.Repeat("`", n)
+	fmt.Fprintln(w, fence)
+	io.WriteString(w, text)
+	if !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%s\n\n", fence)
+}
+
+// longestRun returns the length of the longest run of `c` in `s`.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// anchors assigns the link targets of headings the way common Markdown
+// renderers do.
+type anchors struct {
+	// seen counts the headings given each anchor so far.
+	seen map[string]int
+}
+
+// newAnchors returns anchors for a document without headings.
+func newAnchors() *anchors {
+	return &anchors{seen: make(map[string]int)}
+}
+
+// anchor returns the link target of the next heading with text `heading`.
+//
+// The target is the lower-case heading with spaces replaced by hyphens and
+// punctuation other than hyphens and underscores removed. Repeated targets
+// get "-1", "-2" etc. appended.
+func (a *anchors) anchor(heading string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			sb.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		}
+	}
+	slug := sb.String()
+	n := a.seen[slug]
+	a.seen[slug] = n + 1
+	if n > 0 {
+		return fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
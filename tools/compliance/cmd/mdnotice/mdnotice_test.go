@@ -0,0 +1,529 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+var (
+	// escaped matches a backslash-escaped character in Markdown text.
+	escaped = regexp.MustCompile(`\\(.)`)
+	// codeSpanLine matches a bullet of inline code.
+	codeSpanLine = regexp.MustCompile("^- (`+) (.*) (`+)$")
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// section describes the part of the markdown notice for a library.
+type section struct {
+	libName      string
+	installPaths []string
+	// texts lists the license text files or the texts.
+	texts []string
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition string
+		name      string
+		roots     []string
+		expected  []section
+	}{
+		{
+			condition: "firstparty",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/liba.so", "system/apex/highest.apex/lib/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "firstparty",
+			name:      "container.zip",
+			roots:     []string{"container.zip.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"data/container.zip", "data/container.zip/bin1", "data/container.zip/bin2", "data/container.zip/liba.so", "data/container.zip/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "firstparty",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/lib/liba.so"},
+					[]string{"testdata/notice/NOTICE_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/apex/highest.apex/bin/bin1"},
+					[]string{"testdata/notice/NOTICE_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "container.zip",
+			roots:     []string{"container.zip.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"data/container.zip", "data/container.zip/bin1", "data/container.zip/bin2", "data/container.zip/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"data/container.zip/bin1", "data/container.zip/liba.so"},
+					[]string{"testdata/notice/NOTICE_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"data/container.zip/bin1"},
+					[]string{"testdata/notice/NOTICE_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/notice/NOTICE_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/notice/NOTICE_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "reciprocal",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/lib/liba.so"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/apex/highest.apex/bin/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "reciprocal",
+			name:      "container.zip",
+			roots:     []string{"container.zip.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"data/container.zip", "data/container.zip/bin1", "data/container.zip/bin2", "data/container.zip/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"data/container.zip/bin1", "data/container.zip/liba.so"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"data/container.zip/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "reciprocal",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/apex/highest.apex", "system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE", "testdata/restricted/RESTRICTED_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/lib/liba.so"},
+					[]string{"testdata/restricted/RESTRICTED_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/apex/highest.apex/bin/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "container.zip",
+			roots:     []string{"container.zip.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"data/container.zip", "data/container.zip/bin1", "data/container.zip/bin2", "data/container.zip/libb.so"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE", "testdata/restricted/RESTRICTED_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"data/container.zip/bin1", "data/container.zip/liba.so"},
+					[]string{"testdata/restricted/RESTRICTED_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"data/container.zip/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/restricted/RESTRICTED_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/reciprocal/RECIPROCAL_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "proprietary",
+			name:      "highest.apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/apex/highest.apex/bin/bin2", "system/apex/highest.apex/lib/libb.so", "system/apex/highest.apex", "system/apex/highest.apex/bin/bin1"},
+					[]string{"testdata/restricted/RESTRICTED_LICENSE", "testdata/firstparty/FIRST_PARTY_LICENSE", "testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/apex/highest.apex/bin/bin1", "system/apex/highest.apex/lib/liba.so"},
+					[]string{"testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/apex/highest.apex/bin/bin1"},
+					[]string{"testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "proprietary",
+			name:      "container.zip",
+			roots:     []string{"container.zip.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"data/container.zip/bin2", "data/container.zip/libb.so", "data/container.zip", "data/container.zip/bin1"},
+					[]string{"testdata/restricted/RESTRICTED_LICENSE", "testdata/firstparty/FIRST_PARTY_LICENSE", "testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"data/container.zip/bin1", "data/container.zip/liba.so"},
+					[]string{"testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"data/container.zip/bin1"},
+					[]string{"testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+			},
+		},
+		{
+			condition: "proprietary",
+			name:      "bin1",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expected: []section{
+				{
+					"Android",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/firstparty/FIRST_PARTY_LICENSE"},
+				},
+				{
+					"Device",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+				{
+					"External",
+					[]string{"system/bin/bin1"},
+					[]string{"testdata/proprietary/PROPRIETARY_LICENSE"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
+			rootFiles := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+			}
+
+			stdout, stderr := notice(t, "", false, rootFiles...)
+			if stderr.Len() > 0 {
+				t.Errorf("mdnotice: gotStderr = %v, want none", stderr)
+			}
+
+			t.Logf("got stdout: %s", stdout.String())
+
+			expected := make([]section, 0, len(tt.expected))
+			for _, s := range tt.expected {
+				texts := make([]string, 0, len(s.texts))
+				for _, file := range s.texts {
+					text, err := os.ReadFile(file)
+					if err != nil {
+						t.Fatalf("cannot read license text: %v", err)
+					}
+					texts = append(texts, compliance.NormalizeCopyrights(string(text)))
+				}
+				expected = append(expected, section{s.libName, s.installPaths, texts})
+			}
+			title, toc, actual, err := parseNotice(stdout.String())
+			if err != nil {
+				t.Fatalf("mdnotice: cannot parse output: %v", err)
+			}
+			if len(title) > 0 || len(toc) > 0 {
+				t.Errorf("mdnotice: got title %q and contents %v, want none", title, toc)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("mdnotice: got sections %q, want %q", actual, expected)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	stdout, _ := notice(t, "Notices for *all* [libs]", true, "testdata/regressmarkdown/bin/bin1.meta_lic")
+
+	t.Logf("got stdout: %s", stdout.String())
+
+	libName := "Lib *bold* _em_ [link](x) #1 `tick`"
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "#") && strings.Contains(line, "bold") && !strings.Contains(line, "\\*bold\\*") {
+			t.Errorf("mdnotice: got unescaped heading %q", line)
+		}
+	}
+	text, err := os.ReadFile("testdata/regressmarkdown/MARKDOWN_LICENSE")
+	if err != nil {
+		t.Fatalf("cannot read license text: %v", err)
+	}
+	title, toc, actual, err := parseNotice(stdout.String())
+	if err != nil {
+		t.Fatalf("mdnotice: cannot parse output: %v", err)
+	}
+	if expected := "Notices for *all* [libs]"; title != expected {
+		t.Errorf("mdnotice: got title %q, want %q", title, expected)
+	}
+	expectedTOC := []tocEntry{{libName, "lib-bold-_em_-linkx-1-tick"}}
+	if !reflect.DeepEqual(toc, expectedTOC) {
+		t.Errorf("mdnotice: got contents %q, want %q", toc, expectedTOC)
+	}
+	expected := []section{{libName, []string{"system/bin/bin`1*"}, []string{string(text)}}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("mdnotice: got sections %q, want %q", actual, expected)
+	}
+}
+
+func TestTOC(t *testing.T) {
+	stdout, _ := notice(t, "Android", true, "testdata/notice/highest.apex.meta_lic")
+
+	_, toc, sections, err := parseNotice(stdout.String())
+	if err != nil {
+		t.Fatalf("mdnotice: cannot parse output: %v", err)
+	}
+	// The title heading takes the "android" anchor.
+	expected := []tocEntry{{"Android", "android-1"}, {"Device", "device"}, {"External", "external"}}
+	if !reflect.DeepEqual(toc, expected) {
+		t.Errorf("mdnotice: got contents %q, want %q", toc, expected)
+	}
+	if len(sections) != len(toc) {
+		t.Fatalf("mdnotice: got %d sections for %d contents entries, want equal", len(sections), len(toc))
+	}
+	for i, s := range sections {
+		if s.libName != toc[i].libName {
+			t.Errorf("mdnotice: got section %q for contents entry %q, want same order", s.libName, toc[i].libName)
+		}
+	}
+}
+
+// notice returns the output and the error output of mdnotice for `roots`.
+func notice(t *testing.T, title string, toc bool, roots ...string) (*bytes.Buffer, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, title, toc, nil, nil, nil, 0, false, &deps}
+
+	err := mdNotice(&ctx, roots...)
+	if err != nil {
+		t.Fatalf("mdnotice: error = %v, stderr = %v", err, stderr)
+	}
+	return stdout, stderr
+}
+
+// tocEntry is a link in the table of contents.
+type tocEntry struct {
+	libName string
+	anchor  string
+}
+
+// tocLine matches a link in the table of contents.
+var tocLine = regexp.MustCompile(`^- \[(.*)\]\(#([^)]*)\)$`)
+
+// parseNotice returns the title, table of contents and library sections of
+// the markdown notice `text` with the escapes removed.
+func parseNotice(text string) (string, []tocEntry, []section, error) {
+	var title string
+	var toc []tocEntry
+	var sections []section
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case len(line) == 0:
+		case strings.HasPrefix(line, "# "):
+			title = escaped.ReplaceAllString(line[2:], "$1")
+		case strings.HasPrefix(line, "## "):
+			sections = append(sections, section{escaped.ReplaceAllString(line[3:], "$1"), []string{}, []string{}})
+		case len(sections) == 0 && tocLine.MatchString(line):
+			m := tocLine.FindStringSubmatch(line)
+			toc = append(toc, tocEntry{escaped.ReplaceAllString(m[1], "$1"), m[2]})
+		case len(sections) > 0 && codeSpanLine.MatchString(line):
+			m := codeSpanLine.FindStringSubmatch(line)
+			if m[1] != m[3] {
+				return "", nil, nil, fmt.Errorf("mismatched code span delimiters in %q", line)
+			}
+			s := &sections[len(sections)-1]
+			s.installPaths = append(s.installPaths, m[2])
+		case len(sections) > 0 && strings.HasPrefix(line, "```"):
+			fence := line
+			var body strings.Builder
+			closed := false
+			for scanner.Scan() {
+				if scanner.Text() == fence {
+					closed = true
+					break
+				}
+				body.WriteString(scanner.Text())
+				body.WriteString("\n")
+			}
+			if !closed {
+				return "", nil, nil, fmt.Errorf("unterminated code block")
+			}
+			s := &sections[len(sections)-1]
+			s.texts = append(s.texts, body.String())
+		default:
+			return "", nil, nil, fmt.Errorf("unexpected line %q", line)
+		}
+	}
+	return title, toc, sections, scanner.Err()
+}
@@ -0,0 +1,562 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failWrongArgs  = fmt.Errorf("\nExpected an old and a new notice")
+	failNoLicenses = fmt.Errorf("No licenses found")
+)
+
+type context struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	rootFS      fs.FS
+	product     string
+	stripPrefix []string
+	// asJSON writes the differences as a json report instead of one per line.
+	asJSON bool
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(2)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(2)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	outputFile := flags.String("o", "-", "Where to write the differences. (default stdout)")
+	oldFiles := newMultiString(flags, "old", "The old notice file or an old root license metadata file. (multiple roots allowed)")
+	newFiles := newMultiString(flags, "new", "The new notice file or a new root license metadata file. (multiple roots allowed)")
+	product := flags.String("product", "", "The name of the product for which the notices were generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from install paths read from license metadata. i.e. path to root (multiple allowed)")
+	asJSON := flags.Bool("json", false, "Whether to write the differences as a json report.")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} old new
+       %s {options} -old old_root.meta_lic... -new new_root.meta_lic...
+
+Outputs the libraries added, removed, or changed, the libraries whose license
+texts changed, and the install paths moved between libraries from the old to
+the new notice, one per line.
+
+Each notice is a NOTICE.xml written by xmlnotice, a NOTICE.json written by
+jsonnotice, either optionally gzipped, or the set of root license metadata
+files from which to compute the notice.
+
+Exits 0 when the notices match, 1 when they differ, and 2 on error.
+
+Options:
+`, filepath.Base(os.Args[0]), filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(expandedArgs)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+
+	// Must specify exactly the old and the new notice either as arguments or
+	// with -old and -new.
+	switch {
+	case flags.NArg() == 2 && len(*oldFiles) == 0 && len(*newFiles) == 0:
+		*oldFiles = multiString{flags.Arg(0)}
+		*newFiles = multiString{flags.Arg(1)}
+	case flags.NArg() == 0 && len(*oldFiles) > 0 && len(*newFiles) > 0:
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, *asJSON}
+
+	differ, err := noticeDiff(ctx, *oldFiles, *newFiles)
+	if err != nil {
+		if err == failWrongArgs {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(2)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(2)
+		}
+	}
+	if differ {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// libraryNotice describes a library in a notice independent of the format of
+// the notice.
+type libraryNotice struct {
+	conditions   map[string]struct{}
+	hashes       map[string]struct{}
+	installPaths map[string]struct{}
+}
+
+// notice maps library names to their descriptions.
+type notice map[string]*libraryNotice
+
+// library returns the description of library `name` adding it when missing.
+func (n notice) library(name string) *libraryNotice {
+	lib, ok := n[name]
+	if !ok {
+		lib = &libraryNotice{make(map[string]struct{}), make(map[string]struct{}), make(map[string]struct{})}
+		n[name] = lib
+	}
+	return lib
+}
+
+// report describes the differences between two notices.
+type report struct {
+	// Added lists the libraries only in the new notice with their conditions.
+	Added []change `json:"added"`
+	// Removed lists the libraries only in the old notice with their conditions.
+	Removed []change `json:"removed"`
+	// Changed lists the libraries whose license conditions changed.
+	Changed []change `json:"changed"`
+	// Relicensed lists the libraries whose license text hashes changed.
+	Relicensed []change `json:"relicensed"`
+	// Moved lists the install paths whose libraries changed.
+	Moved []change `json:"moved"`
+}
+
+// change describes the values of a library or install path before and after.
+type change struct {
+	Name   string   `json:"name"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// empty returns true when `r` lists no differences.
+func (r *report) empty() bool {
+	return len(r.Added)+len(r.Removed)+len(r.Changed)+len(r.Relicensed)+len(r.Moved) == 0
+}
+
+// openNotice opens the notice file `name` decompressing it when it ends with
+// ".gz".
+func openNotice(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, f}, nil
+}
+
+// noticeDiff implements the noticediff utility returning true when the
+// notices differ.
+func noticeDiff(ctx *context, oldFiles, newFiles []string) (bool, error) {
+	// Must be exactly an old and a new notice.
+	if len(oldFiles) == 0 || len(newFiles) == 0 {
+		return false, failWrongArgs
+	}
+
+	before, err := readNotice(ctx, oldFiles)
+	if err != nil {
+		return false, fmt.Errorf("Unable to read old notice %q: %v\n", oldFiles, err)
+	}
+	after, err := readNotice(ctx, newFiles)
+	if err != nil {
+		return false, fmt.Errorf("Unable to read new notice %q: %v\n", newFiles, err)
+	}
+
+	r := diffNotices(before, after)
+
+	if ctx.asJSON {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return false, fmt.Errorf("Unable to write json report: %v\n", err)
+		}
+		fmt.Fprintln(ctx.stdout, string(data))
+		return !r.empty(), nil
+	}
+
+	// Describe the libraries in name order followed by the moved install paths.
+	type line struct {
+		name, text string
+	}
+	var lines []line
+	for _, c := range r.Added {
+		lines = append(lines, line{c.Name, fmt.Sprintf("added %s: %s", c.Name, strings.Join(c.After, "|"))})
+	}
+	for _, c := range r.Removed {
+		lines = append(lines, line{c.Name, fmt.Sprintf("removed %s: %s", c.Name, strings.Join(c.Before, "|"))})
+	}
+	for _, c := range r.Changed {
+		lines = append(lines, line{c.Name, fmt.Sprintf("changed %s: %s -> %s", c.Name, strings.Join(c.Before, "|"), strings.Join(c.After, "|"))})
+	}
+	for _, c := range r.Relicensed {
+		lines = append(lines, line{c.Name, fmt.Sprintf("relicensed %s: %s -> %s", c.Name, strings.Join(c.Before, "|"), strings.Join(c.After, "|"))})
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].name < lines[j].name
+	})
+	for _, l := range lines {
+		fmt.Fprintln(ctx.stdout, l.text)
+	}
+	for _, c := range r.Moved {
+		fmt.Fprintf(ctx.stdout, "moved %s: %s -> %s\n", c.Name, strings.Join(c.Before, "|"), strings.Join(c.After, "|"))
+	}
+	return !r.empty(), nil
+}
+
+// readNotice reads the notice from `files`, which is either a single xml or
+// json notice file or a set of root license metadata files.
+func readNotice(ctx *context, files []string) (notice, error) {
+	roots := 0
+	for _, f := range files {
+		if strings.HasSuffix(f, ".meta_lic") {
+			roots++
+		}
+	}
+	if roots > 0 {
+		if roots != len(files) {
+			return nil, fmt.Errorf("cannot mix notice files with license metadata files")
+		}
+		return readLicenseGraphNotice(ctx, files)
+	}
+	if len(files) != 1 {
+		return nil, fmt.Errorf("expected 1 notice file, got %d", len(files))
+	}
+
+	r, err := openNotice(files[0])
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if strings.HasSuffix(strings.TrimSuffix(files[0], ".gz"), ".json") {
+		return readJSONNotice(r)
+	}
+	return readXMLNotice(r)
+}
+
+// readJSONNotice reads the notice written by jsonnotice from `r`.
+func readJSONNotice(r io.Reader) (notice, error) {
+	var libs []struct {
+		Name         string   `json:"name"`
+		Conditions   []string `json:"conditions"`
+		InstallPaths []string `json:"installPaths"`
+		Licenses     []struct {
+			Hash string `json:"hash"`
+		} `json:"licenses"`
+	}
+	if err := json.NewDecoder(r).Decode(&libs); err != nil {
+		return nil, err
+	}
+	n := make(notice)
+	for _, l := range libs {
+		lib := n.library(l.Name)
+		addAll(lib.conditions, l.Conditions...)
+		addAll(lib.installPaths, l.InstallPaths...)
+		for _, t := range l.Licenses {
+			lib.hashes[t.Hash] = struct{}{}
+		}
+	}
+	return n, nil
+}
+
+// readXMLNotice reads the notice written by xmlnotice from `r` in either the
+// default or the -by_target form.
+//
+// Identifies license texts by the sha256 hash written by newer versions of
+// xmlnotice, or by contentId for older notices without the hash.
+func readXMLNotice(r io.Reader) (notice, error) {
+	type reference struct {
+		lib, contentID, conditions, installPath string
+	}
+	var refs []reference
+	hashes := make(map[string]string)
+	path := ""
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attrs := make(map[string]string)
+		for _, attr := range start.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+		switch start.Name.Local {
+		case "file":
+			path = attrs["path"]
+		case "license":
+			refs = append(refs, reference{attrs["lib"], attrs["contentId"], attrs["condition"], path})
+		case "file-name":
+			var installPath string
+			if err := d.DecodeElement(&installPath, &start); err != nil {
+				return nil, err
+			}
+			refs = append(refs, reference{attrs["lib"], attrs["contentId"], attrs["condition"], installPath})
+		case "file-content":
+			if h, ok := attrs["hash"]; ok {
+				hashes[attrs["contentId"]] = h
+			}
+		}
+	}
+
+	n := make(notice)
+	for _, ref := range refs {
+		lib := n.library(ref.lib)
+		if h, ok := hashes[ref.contentID]; ok {
+			lib.hashes[h] = struct{}{}
+		} else {
+			lib.hashes[ref.contentID] = struct{}{}
+		}
+		if len(ref.conditions) > 0 {
+			addAll(lib.conditions, strings.Split(ref.conditions, ",")...)
+		}
+		if len(ref.installPath) > 0 {
+			lib.installPaths[ref.installPath] = struct{}{}
+		}
+	}
+	return n, nil
+}
+
+// readLicenseGraphNotice computes the notice for the root license metadata
+// files in `files` the same way as jsonnotice.
+func readLicenseGraphNotice(ctx *context, files []string) (notice, error) {
+	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	if err != nil {
+		return nil, err
+	}
+	if licenseGraph == nil {
+		return nil, failNoLicenses
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTexts(ctx.rootFS, licenseGraph, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	n := make(notice)
+	for _, libName := range ni.AllLibraries() {
+		lib := n.library(libName)
+		for _, h := range ni.LibHashes(libName) {
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				lib.installPaths[ctx.strip(installPath)] = struct{}{}
+				addAll(lib.conditions, ni.InstallHashLibConditions(installPath, h, libName).Names()...)
+			}
+			text, err := ni.HashText(h)
+			if err != nil {
+				return nil, err
+			}
+			lib.hashes[fmt.Sprintf("sha256:%x", sha256.Sum256(text))] = struct{}{}
+		}
+	}
+	return n, nil
+}
+
+// diffNotices compares the notice `before` to the notice `after`.
+//
+// An install path moves when it loses a library still in the new notice or
+// gains a library already in the old notice so that adding or removing a
+// library does not also move every install path it shares with others.
+func diffNotices(before, after notice) *report {
+	r := &report{[]change{}, []change{}, []change{}, []change{}, []change{}}
+
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b := before[name]
+		a, ok := after[name]
+		if !ok {
+			r.Removed = append(r.Removed, change{name, sortedKeys(b.conditions), nil})
+			continue
+		}
+		if !sameKeys(b.conditions, a.conditions) {
+			r.Changed = append(r.Changed, change{name, sortedKeys(b.conditions), sortedKeys(a.conditions)})
+		}
+		if !sameKeys(b.hashes, a.hashes) {
+			r.Relicensed = append(r.Relicensed, change{name, sortedKeys(b.hashes), sortedKeys(a.hashes)})
+		}
+	}
+	for name, a := range after {
+		if _, ok := before[name]; !ok {
+			r.Added = append(r.Added, change{name, nil, sortedKeys(a.conditions)})
+		}
+	}
+	sort.Slice(r.Added, func(i, j int) bool {
+		return r.Added[i].Name < r.Added[j].Name
+	})
+
+	// pathLibs maps each install path in `n` to the libraries installed there.
+	pathLibs := func(n notice) map[string]map[string]struct{} {
+		result := make(map[string]map[string]struct{})
+		for name, lib := range n {
+			for installPath := range lib.installPaths {
+				if _, ok := result[installPath]; !ok {
+					result[installPath] = make(map[string]struct{})
+				}
+				result[installPath][name] = struct{}{}
+			}
+		}
+		return result
+	}
+	beforePaths := pathLibs(before)
+	afterPaths := pathLibs(after)
+	for installPath, b := range beforePaths {
+		a, ok := afterPaths[installPath]
+		if !ok {
+			continue
+		}
+		moved := false
+		for name := range b {
+			if _, ok := a[name]; !ok {
+				if _, ok := after[name]; ok {
+					moved = true
+				}
+			}
+		}
+		for name := range a {
+			if _, ok := b[name]; !ok {
+				if _, ok := before[name]; ok {
+					moved = true
+				}
+			}
+		}
+		if moved {
+			r.Moved = append(r.Moved, change{installPath, sortedKeys(b), sortedKeys(a)})
+		}
+	}
+	sort.Slice(r.Moved, func(i, j int) bool {
+		return r.Moved[i].Name < r.Moved[j].Name
+	})
+	return r
+}
+
+// addAll adds each of `values` to the set `m`.
+func addAll(m map[string]struct{}, values ...string) {
+	for _, v := range values {
+		m[v] = struct{}{}
+	}
+}
+
+// sortedKeys returns the keys of `m` in sorted order.
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameKeys returns true when `a` and `b` have the same keys.
+func sameKeys(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// writeNotice writes an xml notice like xmlnotice to `name` for libraries
+// given as "lib:condition" strings, and returns the path.
+func writeNotice(t *testing.T, dir, name string, libs ...string) string {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<licenses>\n")
+	for _, l := range libs {
+		lib, condition, _ := strings.Cut(l, ":")
+		fmt.Fprintf(&b, "<file-name contentId=\"%s\" lib=\"%s\" condition=\"%s\">system/lib/%s.so</file-name>\n", condition, lib, condition, lib)
+	}
+	b.WriteString("<file-content contentId=\"notice\">notice text</file-content>\n")
+	b.WriteString("<file-content contentId=\"restricted\">restricted text</file-content>\n")
+	b.WriteString("</licenses>\n")
+
+	data := []byte(b.String())
+	if strings.HasSuffix(name, ".gz") {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		data = buf.Bytes()
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		t.Fatalf("cannot write notice: %v", err)
+	}
+	return path
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldName     string
+		oldLibs     []string
+		newName     string
+		newLibs     []string
+		expectedOut []string
+	}{
+		{
+			name:    "unchanged",
+			oldName: "old.xml",
+			oldLibs: []string{"liba:notice", "libb:notice"},
+			newName: "new.xml",
+			newLibs: []string{"libb:notice", "liba:notice"},
+		},
+		{
+			name:        "added",
+			oldName:     "old.xml",
+			oldLibs:     []string{"liba:notice"},
+			newName:     "new.xml",
+			newLibs:     []string{"liba:notice", "libb:notice"},
+			expectedOut: []string{"added libb: notice"},
+		},
+		{
+			name:        "removed",
+			oldName:     "old.xml",
+			oldLibs:     []string{"liba:notice", "libb:notice"},
+			newName:     "new.xml",
+			newLibs:     []string{"liba:notice"},
+			expectedOut: []string{"removed libb: notice"},
+		},
+		{
+			name:        "changed+gz",
+			oldName:     "old.xml.gz",
+			oldLibs:     []string{"liba:notice", "libb:notice"},
+			newName:     "new.xml.gz",
+			newLibs:     []string{"liba:restricted", "libb:notice", "libc:notice"},
+			expectedOut: []string{"changed liba: notice -> restricted", "relicensed liba: notice -> restricted", "added libc: notice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			oldNotice := writeNotice(t, dir, tt.oldName, tt.oldLibs...)
+			newNotice := writeNotice(t, dir, tt.newName, tt.newLibs...)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			ctx := context{stdout, stderr, compliance.FS, "", nil, false}
+
+			differ, err := noticeDiff(&ctx, []string{oldNotice}, []string{newNotice})
+			if err != nil {
+				t.Fatalf("noticediff: error = %v, stderr = %v", err, stderr)
+			}
+			if differ != (len(tt.expectedOut) > 0) {
+				t.Errorf("noticediff: got differ = %v for %q", differ, tt.expectedOut)
+			}
+			var actual []string
+			if stdout.Len() > 0 {
+				actual = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			}
+			if !reflect.DeepEqual(actual, tt.expectedOut) {
+				t.Errorf("noticediff: got %q, want %q", actual, tt.expectedOut)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	notice := writeNotice(t, dir, "notice.xml", "liba:notice")
+
+	ctx := context{&bytes.Buffer{}, &bytes.Buffer{}, compliance.FS, "", nil, false}
+
+	if _, err := noticeDiff(&ctx, []string{notice}, nil); err != failWrongArgs {
+		t.Errorf("noticediff: got error %v for one file, want %v", err, failWrongArgs)
+	}
+	if _, err := noticeDiff(&ctx, []string{notice}, []string{filepath.Join(dir, "missing.xml")}); err == nil {
+		t.Errorf("noticediff: got no error for a missing file, want error")
+	}
+	if _, err := noticeDiff(&ctx, []string{notice}, []string{notice, "testdata/notice/highest.apex.meta_lic"}); err == nil {
+		t.Errorf("noticediff: got no error mixing notice and license metadata files, want error")
+	}
+}
+
+// writeJSONNotice writes a json notice like jsonnotice to `name` for libraries
+// given as "lib:condition:hash:installPath" strings, and returns the path.
+func writeJSONNotice(t *testing.T, dir, name string, libs ...string) string {
+	type license struct {
+		Hash string `json:"hash"`
+	}
+	type library struct {
+		Name         string    `json:"name"`
+		Conditions   []string  `json:"conditions"`
+		InstallPaths []string  `json:"installPaths"`
+		Licenses     []license `json:"licenses"`
+	}
+	var notice []library
+	for _, l := range libs {
+		fields := strings.Split(l, ":")
+		notice = append(notice, library{fields[0], []string{fields[1]}, []string{fields[3]}, []license{{"sha256:" + fields[2]}}})
+	}
+	data, err := json.Marshal(notice)
+	if err != nil {
+		t.Fatalf("cannot marshal notice: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		t.Fatalf("cannot write notice: %v", err)
+	}
+	return path
+}
+
+func TestJSON(t *testing.T) {
+	dir := t.TempDir()
+	oldNotice := writeJSONNotice(t, dir, "old.json",
+		"liba:notice:aaa:system/lib/liba.so",
+		"libb:notice:bbb:system/lib/libb.so",
+		"libc:reciprocal:ccc:system/lib/libc.so",
+		"libd:notice:ddd:system/lib/libd.so")
+	newNotice := writeJSONNotice(t, dir, "new.json",
+		"liba:notice:aaa:system/lib/liba.so",
+		"libb:notice:b2b:system/lib/libb.so",
+		"libd:notice:ddd:system/lib/liba.so",
+		"libe:restricted:eee:system/lib/libc.so")
+
+	stdout := &bytes.Buffer{}
+	ctx := context{stdout, &bytes.Buffer{}, compliance.FS, "", nil, false}
+	differ, err := noticeDiff(&ctx, []string{oldNotice}, []string{newNotice})
+	if err != nil {
+		t.Fatalf("noticediff: error = %v", err)
+	}
+	if !differ {
+		t.Errorf("noticediff: got differ = false, want true")
+	}
+	expectedOut := []string{
+		"relicensed libb: sha256:bbb -> sha256:b2b",
+		"removed libc: reciprocal",
+		"added libe: restricted",
+		"moved system/lib/liba.so: liba -> liba|libd",
+	}
+	actual := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if !reflect.DeepEqual(actual, expectedOut) {
+		t.Errorf("noticediff: got %q, want %q", actual, expectedOut)
+	}
+
+	stdout.Reset()
+	ctx.asJSON = true
+	if _, err := noticeDiff(&ctx, []string{oldNotice}, []string{newNotice}); err != nil {
+		t.Fatalf("noticediff -json: error = %v", err)
+	}
+	var r report
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		t.Fatalf("noticediff -json: cannot parse %q: %v", stdout.String(), err)
+	}
+	expected := report{
+		Added:      []change{{"libe", nil, []string{"restricted"}}},
+		Removed:    []change{{"libc", []string{"reciprocal"}, nil}},
+		Changed:    []change{},
+		Relicensed: []change{{"libb", []string{"sha256:bbb"}, []string{"sha256:b2b"}}},
+		Moved:      []change{{"system/lib/liba.so", []string{"liba"}, []string{"liba", "libd"}}},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("noticediff -json: got %+v, want %+v", r, expected)
+	}
+}
+
+func TestLicenseGraph(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	ctx := context{stdout, &bytes.Buffer{}, compliance.FS, "", nil, false}
+
+	roots := []string{"testdata/notice/highest.apex.meta_lic"}
+	differ, err := noticeDiff(&ctx, roots, roots)
+	if err != nil {
+		t.Fatalf("noticediff: error = %v", err)
+	}
+	if differ || stdout.Len() > 0 {
+		t.Errorf("noticediff: got differ = %v, output %q comparing a notice to itself", differ, stdout.String())
+	}
+
+	differ, err = noticeDiff(&ctx, roots, []string{"testdata/restricted/highest.apex.meta_lic"})
+	if err != nil {
+		t.Fatalf("noticediff: error = %v", err)
+	}
+	if !differ {
+		t.Errorf("noticediff: got differ = false comparing different notices, want true")
+	}
+	var changed []string
+	for _, line := range strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, "changed ") {
+			changed = append(changed, line)
+		}
+	}
+	expectedChanged := []string{
+		"changed Android: notice -> notice|restricted|restricted_if_statically_linked",
+		"changed Device: notice -> restricted_if_statically_linked",
+		"changed External: notice -> reciprocal|restricted_if_statically_linked",
+	}
+	if !reflect.DeepEqual(changed, expectedChanged) {
+		t.Errorf("noticediff: got changed %q, want %q", changed, expectedChanged)
+	}
+}
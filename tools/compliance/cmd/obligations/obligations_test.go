@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+var (
+	// columnSeparator matches the padding between columns of the table.
+	columnSeparator = regexp.MustCompile(`\s{2,}`)
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition   string
+		name        string
+		roots       []string
+		expectedOut [][]string
+	}{
+		{
+			condition: "firstparty",
+			name:      "binary",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: [][]string{
+				{"notice", "attribution", "testdata/firstparty/bin/bin1.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/firstparty/bin/bin1.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+				{"notice", "attribution", "testdata/firstparty/lib/liba.so.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/firstparty/lib/liba.so.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+				{"notice", "attribution", "testdata/firstparty/lib/libc.a.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/firstparty/lib/libc.a.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+			},
+		},
+		{
+			condition: "notice",
+			name:      "binary",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: [][]string{
+				{"notice", "attribution", "testdata/notice/bin/bin1.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/notice/bin/bin1.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+				{"notice", "attribution", "testdata/notice/lib/liba.so.meta_lic", "include the license text and notices of Device"},
+				{"notice", "attribution", "testdata/notice/lib/libc.a.meta_lic", "include the license text and notices of External"},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expectedOut: [][]string{
+				{"notice", "attribution", "testdata/restricted/bin/bin1.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/restricted/bin/bin1.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+				{"notice", "attribution", "testdata/restricted/bin/bin2.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/restricted/bin/bin2.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+				{"notice", "attribution", "testdata/restricted/highest.apex.meta_lic", "include the license text and notices of Android"},
+				{"notice", "patentNotice", "testdata/restricted/highest.apex.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+				{"reciprocal", "attribution", "testdata/restricted/lib/libc.a.meta_lic", "include the license text and notices of External"},
+				{"reciprocal", "sourceDisclosure", "testdata/restricted/lib/libc.a.meta_lic", "offer the source of External"},
+				{"restricted", "attribution", "testdata/restricted/lib/libb.so.meta_lic", "include the license text and notices of Android"},
+				{"restricted", "sourceDisclosure", "testdata/restricted/lib/libb.so.meta_lic", "offer the source of Android and of everything linked with it"},
+				{"restricted_if_statically_linked", "attribution", "testdata/restricted/lib/liba.so.meta_lic", "include the license text and notices of Device"},
+				{"restricted_if_statically_linked", "sourceDisclosure", "testdata/restricted/lib/liba.so.meta_lic", "offer the source of Device and of everything statically linked with it"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			rootFiles := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+			}
+
+			ctx := context{stdout, stderr, compliance.GetFS("")}
+
+			err := obligations(&ctx, rootFiles...)
+			if err != nil {
+				t.Fatalf("obligations: error = %v, stderr = %v", err, stderr)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("obligations: gotStderr = %v, want none", stderr)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			if len(lines) < 1 || !reflect.DeepEqual(columnSeparator.Split(lines[0], -1), []string{"CONDITION", "OBLIGATION", "TARGET", "DETAIL"}) {
+				t.Fatalf("obligations: got header %q, want column names", lines[0])
+			}
+			var actual [][]string
+			for _, line := range lines[1:] {
+				actual = append(actual, columnSeparator.Split(line, -1))
+			}
+			if !reflect.DeepEqual(actual, tt.expectedOut) {
+				t.Errorf("obligations: got rows:\n%q\nwant:\n%q", actual, tt.expectedOut)
+			}
+		})
+	}
+}
+
+func TestNoneRequested(t *testing.T) {
+	ctx := context{&bytes.Buffer{}, &bytes.Buffer{}, compliance.GetFS("")}
+	if err := obligations(&ctx); err != failNoneRequested {
+		t.Errorf("obligations: got error %v, want %v", err, failNoneRequested)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"android/soong/tools/compliance"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+const (
+	// dsseMediaType is the media type of the cosign attestation layers,
+	// each a DSSE envelope around an in-toto statement.
+	dsseMediaType = "application/vnd.dsse.envelope.v1+json"
+	// inTotoPayloadType is the payload type of DSSE envelopes holding an
+	// in-toto statement.
+	inTotoPayloadType = "application/vnd.in-toto+json"
+
+	// The in-toto predicate types cosign uses for SBOM attestations.
+	spdxPredicateType      = "https://spdx.dev/Document"
+	cycloneDXPredicateType = "https://cyclonedx.org/bom"
+)
+
+// imageSBOMExtractor describes OCI container images with MetaLicStubs
+// using the SPDX or CycloneDX SBOM attestation cosign attached to them.
+//
+// Does not verify the attestation signatures; use `cosign verify-attestation`
+// for that.
+type imageSBOMExtractor struct {
+	// Keychain finds the registry credentials or is nil to use
+	// authn.DefaultKeychain, which reads the Docker config file and runs the
+	// Docker credential helpers it names.
+	Keychain authn.Keychain
+	// Options adds options for talking to the registry, e.g. a transport.
+	Options []remote.Option
+}
+
+// Extract returns the MetaLicStubs for the image `ref`, which must name the
+// image by digest, e.g. "registry.example.com/app@sha256:...".
+//
+// The first stub describes the image as a container depending on a stub for
+// each package of the SBOM. The stub names start with the repository and
+// digest, e.g. "registry.example.com/app@sha256-....meta_lic" and
+// "registry.example.com/app@sha256-.../zlib@1.3.meta_lic".
+//
+// The stubs for the packages come from compliance.ExtractSPDXSBOMLicenses
+// or compliance.ExtractCycloneDXSBOMLicenses.
+//
+// Returns a *compliance.UnknownLicenseError when the image has no SBOM
+// attestation.
+func (e *imageSBOMExtractor) Extract(ctx gocontext.Context, ref string) ([]compliance.MetaLicStub, error) {
+	digest, err := name.NewDigest(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: want repository@sha256:digest: %w", ref, err)
+	}
+	keychain := e.Keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+	options := append([]remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain)}, e.Options...)
+
+	if _, err := remote.Head(digest, options...); err != nil {
+		return nil, fmt.Errorf("cannot find image %q: %w", ref, err)
+	}
+	// cosign attaches attestations to an image as the layers of the image
+	// tagged "sha256-<hex>.att" in the same repository.
+	attTag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + ".att")
+	att, err := remote.Image(attTag, options...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return nil, &compliance.UnknownLicenseError{File: ref, Reason: "no cosign attestation"}
+		}
+		return nil, fmt.Errorf("cannot read attestations of %q: %w", ref, err)
+	}
+	layers, err := att.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read attestations of %q: %w", ref, err)
+	}
+	for _, layer := range layers {
+		mediaType, err := layer.MediaType()
+		if err != nil || string(mediaType) != dsseMediaType {
+			continue
+		}
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read attestation of %q: %w", ref, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read attestation of %q: %w", ref, err)
+		}
+		statement, err := parseInTotoEnvelope(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attestation of %q: %w", ref, err)
+		}
+		if !statement.hasSubject(digest.DigestStr()) {
+			continue
+		}
+		image := compliance.MetaLicStub{
+			Name:        digest.Context().Name() + "@" + strings.Replace(digest.DigestStr(), ":", "-", 1) + ".meta_lic",
+			PackageName: digest.Context().Name(),
+			Version:     digest.DigestStr(),
+			IsContainer: true,
+		}
+		var stubs []compliance.MetaLicStub
+		switch statement.PredicateType {
+		case spdxPredicateType:
+			stubs, err = compliance.ExtractSPDXSBOMLicenses(image, statement.Predicate)
+		case cycloneDXPredicateType:
+			stubs, err = compliance.ExtractCycloneDXSBOMLicenses(image, statement.Predicate)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s SBOM attestation of %q: %w", statement.PredicateType, ref, err)
+		}
+		return stubs, nil
+	}
+	return nil, &compliance.UnknownLicenseError{File: ref, Reason: "no SPDX or CycloneDX SBOM attestation"}
+}
+
+// inTotoStatement is the part of an in-toto statement naming the SBOM and
+// the images it describes.
+type inTotoStatement struct {
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// hasSubject returns true when the statement describes the image with
+// digest `digest`, e.g. "sha256:...".
+func (s inTotoStatement) hasSubject(digest string) bool {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	for _, subject := range s.Subject {
+		if subject.Digest[algorithm] == hex {
+			return true
+		}
+	}
+	return false
+}
+
+// parseInTotoEnvelope returns the in-toto statement in the DSSE envelope
+// `data`.
+func parseInTotoEnvelope(data []byte) (inTotoStatement, error) {
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return inTotoStatement{}, fmt.Errorf("invalid DSSE envelope: %w", err)
+	}
+	if envelope.PayloadType != inTotoPayloadType {
+		return inTotoStatement{}, fmt.Errorf("unexpected DSSE payload type %q: want %q", envelope.PayloadType, inTotoPayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return inTotoStatement{}, fmt.Errorf("invalid DSSE payload: %w", err)
+	}
+	var statement inTotoStatement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return inTotoStatement{}, fmt.Errorf("invalid in-toto statement: %w", err)
+	}
+	return statement, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+)
+
+// testRegistryAuth is the user name and password the test registry wants.
+var testRegistryAuth = authn.AuthConfig{Username: "builder", Password: "s3cret"}
+
+// newTestRegistry returns the host of an in-memory registry wanting basic
+// authentication with testRegistryAuth.
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != testRegistryAuth.Username || password != testRegistryAuth.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// testKeychain resolves every registry to testRegistryAuth.
+type testKeychain struct{}
+
+func (testKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return authn.FromConfig(testRegistryAuth), nil
+}
+
+// pushTestImage pushes a random image to `repo` returning its digest
+// reference.
+func pushTestImage(t *testing.T, repo string) name.Digest {
+	t.Helper()
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(repo + ":latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img, remote.WithAuthFromKeychain(testKeychain{})); err != nil {
+		t.Fatalf("cannot push %s: %s", tag, err)
+	}
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tag.Context().Digest(h.String())
+}
+
+// attest pushes the cosign attestation for `digest` holding an in-toto
+// statement with `predicateType` and `predicate` for each of `subjects`.
+func attest(t *testing.T, digest name.Digest, subjects []v1.Hash, predicateType string, predicate string) {
+	t.Helper()
+	img := empty.Image
+	for _, subject := range subjects {
+		statement, err := json.Marshal(map[string]interface{}{
+			"_type":         "https://in-toto.io/Statement/v0.1",
+			"subject":       []interface{}{map[string]interface{}{"name": digest.Context().Name(), "digest": map[string]string{subject.Algorithm: subject.Hex}}},
+			"predicateType": predicateType,
+			"predicate":     json.RawMessage(predicate),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope, err := json.Marshal(map[string]interface{}{
+			"payloadType": "application/vnd.in-toto+json",
+			"payload":     base64.StdEncoding.EncodeToString(statement),
+			"signatures":  []interface{}{map[string]string{"keyid": "", "sig": "c2ln"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err = mutate.AppendLayers(img, static.NewLayer(envelope, dsseMediaType))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + ".att")
+	if err := remote.Write(tag, img, remote.WithAuthFromKeychain(testKeychain{})); err != nil {
+		t.Fatalf("cannot push %s: %s", tag, err)
+	}
+}
+
+const cycloneDXDocument = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"type": "library", "name": "left-pad", "version": "1.3.0", "licenses": [{"license": {"id": "MIT"}}]}
+  ]
+}`
+
+func TestImageSBOMExtractor(t *testing.T) {
+	host := newTestRegistry(t)
+	e := &imageSBOMExtractor{Keychain: testKeychain{}}
+
+	spdxImage := pushTestImage(t, host+"/spdx/app")
+	h, _ := v1.NewHash(spdxImage.DigestStr())
+	attest(t, spdxImage, []v1.Hash{h}, "https://spdx.dev/Document", spdxDocument)
+	base := host + "/spdx/app@" + strings.Replace(spdxImage.DigestStr(), ":", "-", 1)
+	expected := []compliance.MetaLicStub{
+		{
+			Name:        base + ".meta_lic",
+			PackageName: host + "/spdx/app",
+			Version:     spdxImage.DigestStr(),
+			IsContainer: true,
+			Deps: []string{
+				base + "/busybox@1.36.1.meta_lic",
+				base + "/musl@1.2.4.meta_lic",
+			},
+		},
+		{Name: base + "/busybox@1.36.1.meta_lic", PackageName: "busybox", Version: "1.36.1", LicenseKinds: []string{"SPDX-license-identifier-GPL-2.0-only"}, LicenseConditions: []string{"restricted"}},
+		{Name: base + "/musl@1.2.4.meta_lic", PackageName: "musl", Version: "1.2.4", LicenseKinds: []string{"SPDX-license-identifier-MIT"}, LicenseConditions: []string{"notice"}},
+	}
+	stubs, err := e.Extract(gocontext.Background(), spdxImage.String())
+	if err != nil {
+		t.Fatalf("Extract(spdx): got error %s, want no error", err)
+	}
+	if !reflect.DeepEqual(stubs, expected) {
+		t.Errorf("Extract(spdx): got %+v, want %+v", stubs, expected)
+	}
+
+	// Attestations for other images do not count.
+	cdxImage := pushTestImage(t, host+"/cyclonedx/app")
+	h, _ = v1.NewHash(cdxImage.DigestStr())
+	other, _ := v1.NewHash("sha256:" + strings.Repeat("0", 64))
+	attest(t, cdxImage, []v1.Hash{other, h}, "https://cyclonedx.org/bom", cycloneDXDocument)
+	stubs, err = e.Extract(gocontext.Background(), cdxImage.String())
+	if err != nil {
+		t.Fatalf("Extract(cyclonedx): got error %s, want no error", err)
+	}
+	var actual []string
+	for _, stub := range stubs {
+		actual = append(actual, stub.PackageName+" "+strings.Join(stub.LicenseKinds, ","))
+	}
+	expectedPackages := []string{
+		host + "/cyclonedx/app ",
+		"left-pad SPDX-license-identifier-MIT",
+	}
+	if !reflect.DeepEqual(actual, expectedPackages) {
+		t.Errorf("Extract(cyclonedx): got %q, want %q", actual, expectedPackages)
+	}
+}
+
+func TestImageSBOMExtractorErrors(t *testing.T) {
+	host := newTestRegistry(t)
+	unattested := pushTestImage(t, host+"/unattested")
+	otherImage := pushTestImage(t, host+"/other")
+	attest(t, otherImage, []v1.Hash{{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}}, "https://spdx.dev/Document", spdxDocument)
+	provenance := pushTestImage(t, host+"/provenance")
+	h, _ := v1.NewHash(provenance.DigestStr())
+	attest(t, provenance, []v1.Hash{h}, "https://slsa.dev/provenance/v0.2", `{"builder": {"id": "ci"}}`)
+	invalid := pushTestImage(t, host+"/invalid")
+	h, _ = v1.NewHash(invalid.DigestStr())
+	attest(t, invalid, []v1.Hash{h}, "https://spdx.dev/Document", `{"name": "not spdx"}`)
+
+	tests := []struct {
+		name          string
+		keychain      authn.Keychain
+		ref           string
+		expectUnknown bool
+		expectedError string
+	}{
+		{"tag", testKeychain{}, host + "/unattested:latest", false, "want repository@sha256:digest"},
+		{"no credentials", authn.NewMultiKeychain(), unattested.String(), false, "cannot find image"},
+		{"missing image", testKeychain{}, host + "/missing@sha256:" + strings.Repeat("1", 64), false, "cannot find image"},
+		{"no attestation", testKeychain{}, unattested.String(), true, "no cosign attestation"},
+		{"other subject", testKeychain{}, otherImage.String(), true, "no SPDX or CycloneDX SBOM attestation"},
+		{"not an SBOM", testKeychain{}, provenance.String(), true, "no SPDX or CycloneDX SBOM attestation"},
+		{"invalid SBOM", testKeychain{}, invalid.String(), false, "not an SPDX JSON document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&imageSBOMExtractor{Keychain: tt.keychain}).Extract(gocontext.Background(), tt.ref)
+			if err == nil {
+				t.Fatalf("Extract(): got no error, want %q", tt.expectedError)
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Extract(): got error %q, want %q", err, tt.expectedError)
+			}
+			var unknown *compliance.UnknownLicenseError
+			if errors.As(err, &unknown) != tt.expectUnknown {
+				t.Errorf("Extract(): got error %T, want *compliance.UnknownLicenseError %v", err, tt.expectUnknown)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo images requested")
+)
+
+type context struct {
+	stdout io.Writer
+	stderr io.Writer
+	// extractor reads the SBOM attestations of the images.
+	extractor *imageSBOMExtractor
+	// outDir is the directory to write the license metadata files to.
+	outDir string
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} repository@sha256:digest {repository@sha256:digest...}
+
+Reads the SPDX or CycloneDX SBOM attestation cosign attached to each OCI
+image and writes a license metadata file (*.meta_lic) for the image and for
+each package of the SBOM under -outdir.
+
+Outputs the path to the license metadata file of each image, which the notice
+tools accept as a root. Signs in to the registries with the credentials in the
+Docker config file, running the Docker credential helpers it names.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the list of image license metadata files. (default stdout)")
+	outDir := flags.String("outdir", "", "The directory to write the license metadata files to.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one image.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outDir) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify -outdir\n")
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	ctx := &context{ofile, os.Stderr, &imageSBOMExtractor{}, *outDir}
+
+	// Stop pulling on interrupt.
+	gctx, stop := signal.NotifyContext(gocontext.Background(), os.Interrupt)
+	err := ociSBOM(gctx, ctx, flags.Args()...)
+	stop()
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// ociSBOM implements the ocisbom utility.
+func ociSBOM(gctx gocontext.Context, ctx *context, images ...string) error {
+	// Must be at least one image.
+	if len(images) < 1 {
+		return failNoneRequested
+	}
+
+	for _, image := range images {
+		stubs, err := ctx.extractor.Extract(gctx, image)
+		if err != nil {
+			return fmt.Errorf("Unable to read the SBOM of %q: %w\n", image, err)
+		}
+		paths, err := compliance.WriteMetaLicStubs(ctx.outDir, stubs)
+		if err != nil {
+			return fmt.Errorf("Unable to write license metadata for %q: %w\n", image, err)
+		}
+		// Rewrite the dependencies of the image to the paths written.
+		stubs[0].Deps = paths[1:]
+		if err := os.WriteFile(paths[0], []byte(stubs[0].String()), 0666); err != nil {
+			return fmt.Errorf("Unable to write license metadata for %q: %w\n", image, err)
+		}
+		fmt.Fprintln(ctx.stdout, paths[0])
+	}
+	return nil
+}
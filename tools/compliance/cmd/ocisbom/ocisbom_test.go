@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+)
+
+const spdxDocument = `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"SPDXID": "SPDXRef-busybox", "name": "busybox", "versionInfo": "1.36.1", "licenseConcluded": "GPL-2.0-only"},
+    {"SPDXID": "SPDXRef-musl", "name": "musl", "versionInfo": "1.2.4", "licenseDeclared": "MIT"}
+  ]
+}`
+
+// credentialHelper is a Docker credential helper answering every `get`
+// with the user name "builder" and the password "s3cret".
+const credentialHelper = `#!/bin/sh
+[ "$1" = get ] || exit 1
+read server
+printf '{"ServerURL":"%s","Username":"builder","Secret":"s3cret"}' "$server"
+`
+
+// pushAttestedImage pushes an image with an SPDX SBOM attestation to the
+// registry `host` returning its digest reference.
+func pushAttestedImage(t *testing.T, host string) name.Digest {
+	t.Helper()
+	auth := remote.WithAuth(&authn.Basic{Username: "builder", Password: "s3cret"})
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(host + "/base/busybox:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img, auth); err != nil {
+		t.Fatalf("cannot push %s: %s", tag, err)
+	}
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement, err := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"subject":       []interface{}{map[string]interface{}{"name": tag.Context().Name(), "digest": map[string]string{h.Algorithm: h.Hex}}},
+		"predicateType": "https://spdx.dev/Document",
+		"predicate":     json.RawMessage(spdxDocument),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(map[string]string{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := mutate.AppendLayers(empty.Image, static.NewLayer(envelope, "application/vnd.dsse.envelope.v1+json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attTag := tag.Context().Tag(h.Algorithm + "-" + h.Hex + ".att")
+	if err := remote.Write(attTag, att, auth); err != nil {
+		t.Fatalf("cannot push %s: %s", attTag, err)
+	}
+	return tag.Context().Digest(h.String())
+}
+
+func Test(t *testing.T) {
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "builder" || password != "s3cret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+	image := pushAttestedImage(t, host)
+
+	// The default keychain finds the credentials with the credential
+	// helper the Docker config file names for the registry.
+	home := t.TempDir()
+	dockerDir := filepath.Join(home, ".docker")
+	if err := os.MkdirAll(dockerDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	config, err := json.Marshal(map[string]interface{}{"credHelpers": map[string]string{host: "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dockerDir, "config.json"), config, 0666); err != nil {
+		t.Fatal(err)
+	}
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "docker-credential-test"), []byte(credentialHelper), 0777); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("DOCKER_CONFIG", dockerDir)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	outDir := t.TempDir()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	ctx := &context{stdout, stderr, &imageSBOMExtractor{}, outDir}
+	if err := ociSBOM(gocontext.Background(), ctx, image.String()); err != nil {
+		t.Fatalf("ocisbom: got error %s, want no error", err)
+	}
+	root := strings.TrimSuffix(stdout.String(), "\n")
+	expectedRoot := filepath.Join(outDir, host, "base", "busybox@"+strings.Replace(image.DigestStr(), ":", "-", 1)+".meta_lic")
+	if root != expectedRoot {
+		t.Errorf("ocisbom: got output %q, want %q", root, expectedRoot)
+	}
+
+	// The notice tools can read the license graph from the files written.
+	lg, err := compliance.ReadLicenseGraph(compliance.FS, stderr, []string{root})
+	if err != nil {
+		t.Fatalf("ReadLicenseGraph(): got error %s, want no error", err)
+	}
+	var actual []string
+	for _, tn := range lg.Targets() {
+		actual = append(actual, tn.PackageName()+" "+strings.Join(tn.LicenseConditions().Names(), ","))
+	}
+	sort.Strings(actual)
+	expected := []string{
+		host + "/base/busybox ",
+		"busybox restricted",
+		"musl notice",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ReadLicenseGraph(): got %q, want %q", actual, expected)
+	}
+
+	if err := ociSBOM(gocontext.Background(), ctx); err != failNoneRequested {
+		t.Errorf("ocisbom(no images): got error %v, want %v", err, failNoneRequested)
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+// conditionColors maps sets of conditions from the strongest to the weakest
+// to the color filling nodes with a condition in the set.
+var conditionColors = []struct {
+	conditions compliance.LicenseConditionSet
+	color      string
+}{
+	{compliance.NewLicenseConditionSet(compliance.NotAllowedCondition), "#9e9e9e"},
+	{compliance.ImpliesByExceptionOnly, "#d1b3ff"},
+	{compliance.NewLicenseConditionSet(compliance.RestrictedCondition), "#ff9e9e"},
+	{compliance.NewLicenseConditionSet(compliance.WeaklyRestrictedCondition), "#ffc891"},
+	{compliance.ImpliesReciprocal, "#fff59e"},
+	{compliance.ImpliesNotice, "#c8f0c8"},
+}
+
+type context struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	rootFS      fs.FS
+	conditions  []compliance.LicenseCondition
+	focus       string
+	stripPrefix []string
+}
+
+func (ctx context) strip(name string) string {
+	return compliance.StripPrefix(name, ctx.stripPrefix, "")
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs the license graph with its resolutions in graphviz directed graph
+format.
+
+Each target is a node filled with the color of the strongest condition that
+applies to it either from its own license or from a resolution acting on it.
+Each dependency is a solid edge from target to dependency labelled with its
+annotations. Each resolution is a dashed edge from the target the action
+attaches to, to the target it acts on, labelled with the conditions it
+resolves.
+
+If one or more '-c condition' conditions are given, outputs the
+resolutions for the union of the conditions. Otherwise, outputs the
+resolutions for all conditions.
+
+When '-focus target' is given, outputs only the target and its ancestors
+and descendants.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	conditions := newMultiString(flags, "c", "License condition to resolve. (may be given multiple times)")
+	focus := flags.String("focus", "", "The license metadata file of the target to limit the graph to.")
+	outputFile := flags.String("o", "-", "Where to write the output. (default stdout)")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	}
+
+	lcs := make([]compliance.LicenseCondition, 0, len(*conditions))
+	for _, name := range *conditions {
+		lc, ok := compliance.Conditions.Condition(name)
+		if !ok {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "unrecognized license condition %q\n", name)
+			os.Exit(2)
+		}
+		lcs = append(lcs, lc)
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, lcs, *focus, *stripPrefix}
+
+	err := resolutionDot(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// resolutionDot implements the resolutiondot utility.
+func resolutionDot(ctx *context, files ...string) error {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return failNoneRequested
+	}
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	if err != nil {
+		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return failNoLicenses
+	}
+
+	compliance.ResolveTopDownConditions(licenseGraph)
+	cs := compliance.AllLicenseConditions
+	if len(ctx.conditions) > 0 {
+		cs = compliance.NewLicenseConditionSet(ctx.conditions...)
+	}
+	resolutions := compliance.WalkResolutionsForCondition(licenseGraph, cs)
+
+	// keep identifies the targets to output.
+	keep, err := focusTargets(ctx, licenseGraph)
+	if err != nil {
+		return err
+	}
+
+	// Gather the sorted resolutions between kept targets other than those
+	// attaching to the target they act on, which the node colors show, and
+	// the conditions acting on each target.
+	var rl compliance.ResolutionList
+	actedOn := make(map[*compliance.TargetNode]compliance.LicenseConditionSet)
+	attachesTo := resolutions.AttachesTo()
+	sort.Sort(attachesTo)
+	for _, target := range attachesTo {
+		trl := resolutions.Resolutions(target)
+		sort.Sort(trl)
+		for _, r := range trl {
+			actedOn[r.ActsOn()] = actedOn[r.ActsOn()].Union(r.Resolves())
+			if r.AttachesTo() == r.ActsOn() || !keep[r.AttachesTo()] || !keep[r.ActsOn()] {
+				continue
+			}
+			rl = append(rl, r)
+		}
+	}
+
+	targets := licenseGraph.Targets()
+	sort.Sort(targets)
+
+	// nodes maps targets to graphViz node names.
+	nodes := make(map[*compliance.TargetNode]string)
+
+	fmt.Fprintf(ctx.stdout, "digraph {\n\trankdir=LR;\n")
+	for _, target := range targets {
+		if !keep[target] {
+			continue
+		}
+		nodeName := fmt.Sprintf("n%d", len(nodes))
+		nodes[target] = nodeName
+		label := append([]string{ctx.strip(target.Name())}, target.LicenseConditions().Names()...)
+		fmt.Fprintf(ctx.stdout, "\t%s [label=%s, style=filled, fillcolor=%q];\n", nodeName, dotString(label...), nodeColor(target.LicenseConditions().Union(actedOn[target])))
+	}
+
+	edges := licenseGraph.Edges()
+	sort.Sort(edges)
+	for _, e := range edges {
+		if !keep[e.Target()] || !keep[e.Dependency()] {
+			continue
+		}
+		annotations := e.Annotations().AsList()
+		sort.Strings(annotations)
+		fmt.Fprintf(ctx.stdout, "\t%s -> %s [label=%s];\n", nodes[e.Target()], nodes[e.Dependency()], dotString(annotations...))
+	}
+
+	for _, r := range rl {
+		fmt.Fprintf(ctx.stdout, "\t%s -> %s [style=dashed, color=%q, label=%s];\n", nodes[r.AttachesTo()], nodes[r.ActsOn()], nodeColor(r.Resolves()), dotString(r.Resolves().Names()...))
+	}
+
+	// Rank the root nodes together, and complete the directed graph.
+	fmt.Fprintf(ctx.stdout, "\t{rank=same;")
+	for _, f := range files {
+		fName := f
+		if !strings.HasSuffix(fName, ".meta_lic") {
+			fName += ".meta_lic"
+		}
+		for _, target := range targets {
+			if target.Name() == fName && keep[target] {
+				fmt.Fprintf(ctx.stdout, " %s", nodes[target])
+			}
+		}
+	}
+	fmt.Fprintf(ctx.stdout, "}\n}\n")
+	return nil
+}
+
+// focusTargets returns the targets in `lg` to output: all of them, or the
+// focus target with its ancestors and descendants when ctx.focus is set.
+func focusTargets(ctx *context, lg *compliance.LicenseGraph) (map[*compliance.TargetNode]bool, error) {
+	keep := make(map[*compliance.TargetNode]bool)
+	var focus *compliance.TargetNode
+	for _, target := range lg.Targets() {
+		if len(ctx.focus) == 0 {
+			keep[target] = true
+		} else if target.Name() == ctx.focus || ctx.strip(target.Name()) == ctx.focus {
+			focus = target
+		}
+	}
+	if len(ctx.focus) == 0 {
+		return keep, nil
+	}
+	if focus == nil {
+		return nil, fmt.Errorf("focus target %q not in the license graph", ctx.focus)
+	}
+
+	// dependents maps each target to the targets depending on it.
+	dependents := make(map[*compliance.TargetNode][]*compliance.TargetNode)
+	for _, e := range lg.Edges() {
+		dependents[e.Dependency()] = append(dependents[e.Dependency()], e.Target())
+	}
+
+	// Walk down to the descendants and up to the ancestors.
+	var down, up func(target *compliance.TargetNode)
+	visited := make(map[*compliance.TargetNode]bool)
+	down = func(target *compliance.TargetNode) {
+		keep[target] = true
+		for _, e := range target.Dependencies() {
+			if !visited[e.Dependency()] {
+				visited[e.Dependency()] = true
+				down(e.Dependency())
+			}
+		}
+	}
+	down(focus)
+	visited = make(map[*compliance.TargetNode]bool)
+	up = func(target *compliance.TargetNode) {
+		keep[target] = true
+		for _, dependent := range dependents[target] {
+			if !visited[dependent] {
+				visited[dependent] = true
+				up(dependent)
+			}
+		}
+	}
+	up(focus)
+	return keep, nil
+}
+
+// nodeColor returns the color for the strongest condition in `cs`.
+func nodeColor(cs compliance.LicenseConditionSet) string {
+	for _, cc := range conditionColors {
+		if cs.MatchesAnySet(cc.conditions) {
+			return cc.color
+		}
+	}
+	return "white"
+}
+
+// dotString returns `lines` as a quoted graphviz string with one line per
+// element.
+func dotString(lines ...string) string {
+	escaped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, `\`, `\\`)
+		escaped = append(escaped, strings.ReplaceAll(line, `"`, `\"`))
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
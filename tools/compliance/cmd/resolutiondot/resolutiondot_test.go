@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		name        string
+		roots       []string
+		conditions  []compliance.LicenseCondition
+		focus       string
+		stripPrefix []string
+		expectedOut []string
+	}{
+		{
+			name:        "binary",
+			roots:       []string{"testdata/restricted/bin/bin1.meta_lic"},
+			stripPrefix: []string{"testdata/restricted/"},
+			expectedOut: []string{
+				`digraph {`,
+				`	rankdir=LR;`,
+				`	n0 [label="bin/bin1.meta_lic\nnotice", style=filled, fillcolor="#ffc891"];`,
+				`	n1 [label="lib/liba.so.meta_lic\nrestricted_if_statically_linked", style=filled, fillcolor="#ffc891"];`,
+				`	n2 [label="lib/libc.a.meta_lic\nreciprocal", style=filled, fillcolor="#ffc891"];`,
+				`	n0 -> n1 [label="static"];`,
+				`	n0 -> n2 [label="static"];`,
+				`	n0 -> n1 [style=dashed, color="#ffc891", label="restricted_if_statically_linked"];`,
+				`	n0 -> n2 [style=dashed, color="#ffc891", label="reciprocal\nrestricted_if_statically_linked"];`,
+				`	{rank=same; n0}`,
+				`}`,
+			},
+		},
+		{
+			name:        "focus",
+			roots:       []string{"testdata/restricted/highest.apex.meta_lic"},
+			focus:       "lib/libb.so.meta_lic",
+			stripPrefix: []string{"testdata/restricted/"},
+			expectedOut: []string{
+				`digraph {`,
+				`	rankdir=LR;`,
+				`	n0 [label="bin/bin2.meta_lic\nnotice", style=filled, fillcolor="#ff9e9e"];`,
+				`	n1 [label="highest.apex.meta_lic\nnotice", style=filled, fillcolor="#ff9e9e"];`,
+				`	n2 [label="lib/libb.so.meta_lic\nrestricted", style=filled, fillcolor="#ff9e9e"];`,
+				`	n0 -> n2 [label="dynamic"];`,
+				`	n1 -> n0 [label="static"];`,
+				`	n1 -> n2 [label="static"];`,
+				`	n0 -> n2 [style=dashed, color="#ff9e9e", label="restricted"];`,
+				`	n1 -> n0 [style=dashed, color="#ff9e9e", label="notice\nrestricted"];`,
+				`	n1 -> n2 [style=dashed, color="#ff9e9e", label="restricted"];`,
+				`	{rank=same; n1}`,
+				`}`,
+			},
+		},
+		{
+			name:       "focus condition",
+			roots:      []string{"testdata/restricted/highest.apex.meta_lic"},
+			conditions: []compliance.LicenseCondition{compliance.NoticeCondition},
+			focus:      "testdata/restricted/bin/bin1.meta_lic",
+			expectedOut: []string{
+				`digraph {`,
+				`	rankdir=LR;`,
+				`	n0 [label="testdata/restricted/bin/bin1.meta_lic\nnotice", style=filled, fillcolor="#c8f0c8"];`,
+				`	n1 [label="testdata/restricted/highest.apex.meta_lic\nnotice", style=filled, fillcolor="#c8f0c8"];`,
+				`	n2 [label="testdata/restricted/lib/liba.so.meta_lic\nrestricted_if_statically_linked", style=filled, fillcolor="#ffc891"];`,
+				`	n3 [label="testdata/restricted/lib/libc.a.meta_lic\nreciprocal", style=filled, fillcolor="#fff59e"];`,
+				`	n0 -> n2 [label="static"];`,
+				`	n0 -> n3 [label="static"];`,
+				`	n1 -> n0 [label="static"];`,
+				`	n1 -> n2 [label="static"];`,
+				`	n1 -> n0 [style=dashed, color="#c8f0c8", label="notice"];`,
+				`	{rank=same; n1}`,
+				`}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			ctx := context{stdout, stderr, compliance.GetFS(""), tt.conditions, tt.focus, tt.stripPrefix}
+			if err := resolutionDot(&ctx, tt.roots...); err != nil {
+				t.Fatalf("resolutiondot: error = %v, stderr = %v", err, stderr)
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("resolutiondot: gotStderr = %v, want none", stderr)
+			}
+			if expected := strings.Join(tt.expectedOut, "\n") + "\n"; stdout.String() != expected {
+				t.Errorf("resolutiondot: got:\n%s\nwant:\n%s", stdout, expected)
+			}
+		})
+	}
+}
+
+func TestFocusAllConditions(t *testing.T) {
+	// Every target of the apex is either an ancestor or a descendant of the
+	// root, so focusing on it leaves the graph unchanged.
+	var outputs []string
+	for _, focus := range []string{"", "testdata/restricted/highest.apex.meta_lic"} {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		ctx := context{stdout, stderr, compliance.GetFS(""), nil, focus, nil}
+		if err := resolutionDot(&ctx, "testdata/restricted/highest.apex.meta_lic"); err != nil {
+			t.Fatalf("resolutiondot: error = %v, stderr = %v", err, stderr)
+		}
+		outputs = append(outputs, stdout.String())
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("resolutiondot: got focused output:\n%s\nwant:\n%s", outputs[1], outputs[0])
+	}
+}
+
+func TestErrors(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := context{stdout, stderr, compliance.GetFS(""), nil, "", nil}
+	if err := resolutionDot(&ctx); err != failNoneRequested {
+		t.Errorf("resolutiondot: got error %v, want %v", err, failNoneRequested)
+	}
+
+	ctx.focus = "lib/missing.so.meta_lic"
+	err := resolutionDot(&ctx, "testdata/restricted/highest.apex.meta_lic")
+	if err == nil || !strings.Contains(err.Error(), "not in the license graph") {
+		t.Errorf("resolutiondot: got error %v, want focus target not in the license graph", err)
+	}
+}
+
+func TestDotString(t *testing.T) {
+	if got, want := dotString(`a "b"`, `c\d`), `"a \"b\"\nc\\d"`; got != want {
+		t.Errorf("dotString: got %s, want %s", got, want)
+	}
+}
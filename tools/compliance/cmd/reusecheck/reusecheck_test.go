@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	fullHeader    = "// SPDX-FileCopyrightText: 2026 Example Authors\n// SPDX-License-Identifier: Apache-2.0\n"
+	licenseHeader = "// SPDX-License-Identifier: Apache-2.0\n"
+	noHeader      = "package foo\n"
+)
+
+func Test(t *testing.T) {
+	tests := []struct {
+		name           string
+		dirs           []string
+		fs             fstest.MapFS
+		expectedStdout string
+		expectedStderr []string
+		expectedErr    error
+	}{
+		{
+			name: "compliant",
+			dirs: []string{"src"},
+			fs: fstest.MapFS{
+				"src/a.go":     {Data: []byte(fullHeader)},
+				"src/sub/b.go": {Data: []byte(fullHeader)},
+			},
+			expectedStdout: "PASS",
+		},
+		{
+			name: "warningsonly",
+			dirs: []string{"src"},
+			fs: fstest.MapFS{
+				"src/a.go": {Data: []byte(fullHeader)},
+				"src/b.go": {Data: []byte(licenseHeader)},
+			},
+			expectedStdout: "PASS",
+			expectedStderr: []string{"warning: src/b.go: missingCopyright"},
+		},
+		{
+			name: "errors",
+			dirs: []string{"src", "other/"},
+			fs: fstest.MapFS{
+				"src/a.go":   {Data: []byte(fullHeader)},
+				"src/b.go":   {Data: []byte(noHeader)},
+				"other/c.go": {Data: []byte(licenseHeader)},
+			},
+			expectedStdout: "FAIL",
+			expectedStderr: []string{
+				"warning: src/b.go: missingCopyright",
+				"error: src/b.go: missingLicense",
+				"warning: other/c.go: missingCopyright",
+			},
+			expectedErr: failViolations,
+		},
+		{
+			name:        "nonerequested",
+			fs:          fstest.MapFS{},
+			expectedErr: failNoneRequested,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := reuseCheck(stdout, stderr, tt.fs, tt.dirs...)
+			if err != tt.expectedErr {
+				t.Fatalf("reusecheck: got error %v, want %v, stderr = %v", err, tt.expectedErr, stderr)
+			}
+			if tt.expectedErr == failNoneRequested {
+				return
+			}
+			if got := strings.TrimSpace(stdout.String()); got != tt.expectedStdout {
+				t.Errorf("reusecheck: got stdout %q, want %q", got, tt.expectedStdout)
+			}
+			var lines []string
+			if stderr.Len() > 0 {
+				lines = strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+			}
+			if len(lines) != len(tt.expectedStderr) {
+				t.Fatalf("reusecheck: got %d stderr lines %q, want %d lines %q", len(lines), lines, len(tt.expectedStderr), tt.expectedStderr)
+			}
+			for i, line := range lines {
+				if line != tt.expectedStderr[i] {
+					t.Errorf("reusecheck: unexpected stderr line %d: got %q, want %q", i+1, line, tt.expectedStderr[i])
+				}
+			}
+		})
+	}
+}
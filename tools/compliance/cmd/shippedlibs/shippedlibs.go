@@ -158,7 +158,7 @@ func shippedLibs(ctx *context, files ...string) error {
 		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
 	}
 
-	for lib := range ni.Libraries() {
+	for _, lib := range ni.AllLibraries() {
 		fmt.Fprintln(ctx.stdout, lib)
 	}
 	return nil
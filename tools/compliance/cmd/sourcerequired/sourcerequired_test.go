@@ -0,0 +1,285 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func Test(t *testing.T) {
+	type disclosure struct {
+		target       string
+		condition    string
+		writtenOffer bool
+	}
+	tests := []struct {
+		condition   string
+		name        string
+		outDir      string
+		roots       []string
+		expectedOut []disclosure
+	}{
+		{
+			condition:   "firstparty",
+			name:        "apex",
+			roots:       []string{"highest.apex.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "firstparty",
+			name:        "container",
+			roots:       []string{"container.zip.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "firstparty",
+			name:        "application",
+			roots:       []string{"application.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "firstparty",
+			name:        "binary",
+			roots:       []string{"bin/bin1.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "firstparty",
+			name:        "library",
+			roots:       []string{"lib/libd.so.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "notice",
+			name:        "apex",
+			roots:       []string{"highest.apex.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "notice",
+			name:        "container",
+			roots:       []string{"container.zip.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "notice",
+			name:        "application",
+			roots:       []string{"application.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "notice",
+			name:        "binary",
+			roots:       []string{"bin/bin1.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "notice",
+			name:        "library",
+			roots:       []string{"lib/libd.so.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition: "reciprocal",
+			name:      "apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expectedOut: []disclosure{
+				{"lib/liba.so.meta_lic", "reciprocal", false},
+				{"lib/libc.a.meta_lic", "reciprocal", false},
+			},
+		},
+		{
+			condition: "reciprocal",
+			name:      "container",
+			roots:     []string{"container.zip.meta_lic"},
+			expectedOut: []disclosure{
+				{"lib/liba.so.meta_lic", "reciprocal", false},
+				{"lib/libc.a.meta_lic", "reciprocal", false},
+			},
+		},
+		{
+			condition: "reciprocal",
+			name:      "application",
+			roots:     []string{"application.meta_lic"},
+			expectedOut: []disclosure{
+				{"lib/liba.so.meta_lic", "reciprocal", false},
+			},
+		},
+		{
+			condition: "reciprocal",
+			name:      "binary",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []disclosure{
+				{"lib/liba.so.meta_lic", "reciprocal", false},
+				{"lib/libc.a.meta_lic", "reciprocal", false},
+			},
+		},
+		{
+			condition:   "reciprocal",
+			name:        "library",
+			roots:       []string{"lib/libd.so.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition: "restricted",
+			name:      "apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expectedOut: []disclosure{
+				{"bin/bin1.meta_lic", "restricted_if_statically_linked", true},
+				{"bin/bin2.meta_lic", "restricted", true},
+				{"lib/liba.so.meta_lic", "restricted_if_statically_linked", true},
+				{"lib/libb.so.meta_lic", "restricted", true},
+				{"lib/libc.a.meta_lic", "restricted_if_statically_linked", true},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "container",
+			roots:     []string{"container.zip.meta_lic"},
+			expectedOut: []disclosure{
+				{"bin/bin1.meta_lic", "restricted_if_statically_linked", true},
+				{"bin/bin2.meta_lic", "restricted", true},
+				{"lib/liba.so.meta_lic", "restricted_if_statically_linked", true},
+				{"lib/libb.so.meta_lic", "restricted", true},
+				{"lib/libc.a.meta_lic", "restricted_if_statically_linked", true},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "application",
+			roots:     []string{"application.meta_lic"},
+			expectedOut: []disclosure{
+				{"application.meta_lic", "restricted", true},
+				{"lib/liba.so.meta_lic", "restricted", true},
+			},
+		},
+		{
+			condition: "restricted",
+			name:      "binary",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []disclosure{
+				{"bin/bin1.meta_lic", "restricted_if_statically_linked", true},
+				{"lib/liba.so.meta_lic", "restricted_if_statically_linked", true},
+				{"lib/libc.a.meta_lic", "restricted_if_statically_linked", true},
+			},
+		},
+		{
+			condition:   "restricted",
+			name:        "library",
+			roots:       []string{"lib/libd.so.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition: "proprietary",
+			name:      "apex",
+			roots:     []string{"highest.apex.meta_lic"},
+			expectedOut: []disclosure{
+				{"bin/bin2.meta_lic", "restricted", true},
+				{"lib/libb.so.meta_lic", "restricted", true},
+			},
+		},
+		{
+			condition: "proprietary",
+			name:      "container",
+			roots:     []string{"container.zip.meta_lic"},
+			expectedOut: []disclosure{
+				{"bin/bin2.meta_lic", "restricted", true},
+				{"lib/libb.so.meta_lic", "restricted", true},
+			},
+		},
+		{
+			condition: "proprietary",
+			name:      "application",
+			roots:     []string{"application.meta_lic"},
+			expectedOut: []disclosure{
+				{"application.meta_lic", "restricted", true},
+				{"lib/liba.so.meta_lic", "restricted", true},
+			},
+		},
+		{
+			condition:   "proprietary",
+			name:        "binary",
+			roots:       []string{"bin/bin1.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+		{
+			condition:   "proprietary",
+			name:        "library",
+			roots:       []string{"lib/libd.so.meta_lic"},
+			expectedOut: []disclosure{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			rootFiles := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+			}
+
+			ctx := context{stdout, stderr, compliance.GetFS(tt.outDir)}
+
+			err := sourceRequired(&ctx, rootFiles...)
+			if err != nil {
+				t.Fatalf("sourcerequired: error = %v, stderr = %v", err, stderr)
+				return
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("sourcerequired: gotStderr = %v, want none", stderr)
+			}
+
+			t.Logf("got stdout: %s", stdout.String())
+
+			var actual []compliance.DisclosureRequirement
+			err = json.Unmarshal(stdout.Bytes(), &actual)
+			if err != nil {
+				t.Fatalf("sourcerequired: cannot parse output: %v", err)
+			}
+			if len(actual) != len(tt.expectedOut) {
+				t.Fatalf("sourcerequired: got %d requirements, want %d", len(actual), len(tt.expectedOut))
+			}
+			for i, dr := range actual {
+				expected := tt.expectedOut[i]
+				if want := "testdata/" + tt.condition + "/" + expected.target; dr.Target != want {
+					t.Errorf("sourcerequired: unexpected target at %d: got %q, want %q", i, dr.Target, want)
+				}
+				if dr.Condition != expected.condition {
+					t.Errorf("sourcerequired: unexpected condition for %q: got %q, want %q", dr.Target, dr.Condition, expected.condition)
+				}
+				if dr.WrittenOffer != expected.writtenOffer {
+					t.Errorf("sourcerequired: unexpected writtenOffer for %q: got %v, want %v", dr.Target, dr.WrittenOffer, expected.writtenOffer)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,464 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+
+	"github.com/google/blueprint/deptools"
+
+	"github.com/spdx/tools-golang/builder/builder2v3"
+	spdx_json "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/common"
+	spdx "github.com/spdx/tools-golang/spdx/v2_3"
+	"github.com/spdx/tools-golang/spdxlib"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+const NOASSERTION = "NOASSERTION"
+
+type context struct {
+	stdout       io.Writer
+	stderr       io.Writer
+	rootFS       fs.FS
+	product      string
+	stripPrefix  []string
+	creationTime creationTimeGetter
+	deps         *[]string
+}
+
+func (ctx context) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
+
+Outputs an SPDX 2.3 JSON document with a package for each target in the
+license graph, a file for each install path, and relationships for the
+dependencies between them.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the SPDX json file. (default stdout)")
+	depsFile := flags.String("d", "", "Where to write the deps file")
+	product := flags.String("product", "", "The name of the product for which the document is generated.")
+	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	created := flags.String("creation_time", "", "The creation time to record in the document as YYYY-MM-DDThh:mm:ssZ. (default now)")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	} else {
+		dir, err := filepath.Abs(filepath.Dir(*outputFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot determine path to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		fi, err := os.Stat(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read directory %q of %q: %s\n", dir, *outputFile, err)
+			os.Exit(1)
+		}
+		if !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "parent %q of %q is not a directory\n", dir, *outputFile)
+			os.Exit(1)
+		}
+	}
+
+	creationTime := actualTime
+	if len(*created) > 0 {
+		if _, err := time.Parse("2006-01-02T15:04:05Z", *created); err != nil {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "invalid -creation_time %q: %s\n", *created, err)
+			os.Exit(2)
+		}
+		creationTime = func() string { return *created }
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	var obuf *bytes.Buffer
+	if *outputFile != "-" {
+		obuf = &bytes.Buffer{}
+		ofile = obuf
+	}
+
+	var deps []string
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, creationTime, &deps}
+
+	doc, err := spdxNotice(ctx, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if err := spdx_json.Save2_3(doc, ofile); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write document to %v: %v\n", *outputFile, err)
+		os.Exit(1)
+	}
+
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, obuf.Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+	}
+	if *depsFile != "" {
+		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+type creationTimeGetter func() string
+
+// actualTime returns current time in UTC
+func actualTime() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// spdxIDs allocates deterministic SPDX element identifiers.
+//
+// Identifiers may contain only letters, numbers, `.` and `-` so other
+// characters become `-`, and names colliding after replacement get a numeric
+// suffix in the order allocated.
+type spdxIDs map[string]int
+
+// allocate returns a new identifier for `name` with `prefix`.
+func (ids spdxIDs) allocate(prefix, name string) common.ElementID {
+	id := prefix + strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+	ids[id]++
+	if n := ids[id]; n > 1 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return common.ElementID(id)
+}
+
+// relationship returns the SPDX relationship `rel` from `a` to `b`.
+func relationship(a common.ElementID, rel string, b common.ElementID) *spdx.Relationship {
+	return &spdx.Relationship{
+		RefA:         common.MakeDocElementID("", string(a)),
+		RefB:         common.MakeDocElementID("", string(b)),
+		Relationship: rel,
+	}
+}
+
+// edgeRelationship returns the SPDX relationship for the dependency `e`
+// between packages `target` and `dep`.
+//
+// Build tools and test dependencies point from the dependency to the target.
+// Otherwise, containers contain their dependencies, and other targets link
+// their dependencies statically or dynamically.
+func edgeRelationship(e *compliance.TargetEdge, target, dep common.ElementID) *spdx.Relationship {
+	switch {
+	case e.DepType() == compliance.DepTypeBuildtime:
+		return relationship(dep, "BUILD_TOOL_OF", target)
+	case e.DepType() == compliance.DepTypeTest:
+		return relationship(dep, "TEST_DEPENDENCY_OF", target)
+	case e.Target().IsContainer() && e.Linkage() == compliance.LinkageStatic:
+		return relationship(target, "CONTAINS", dep)
+	case e.Linkage() == compliance.LinkageStatic:
+		return relationship(target, "STATIC_LINK", dep)
+	}
+	return relationship(target, "DYNAMIC_LINK", dep)
+}
+
+// licenseExpression returns the SPDX license expression joining `ids`.
+func licenseExpression(ids []string) string {
+	switch len(ids) {
+	case 0:
+		return NOASSERTION
+	case 1:
+		return ids[0]
+	}
+	return "(" + strings.Join(ids, " AND ") + ")"
+}
+
+// generateSPDXNamespace returns a unique document namespace for the document
+// named `docName` created at `created`.
+func generateSPDXNamespace(docName, created string) string {
+	hash := sha1.Sum([]byte(docName + "\n" + created))
+	return fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", docName, hex.EncodeToString(hash[:]))
+}
+
+// spdxNotice implements the spdxnotice utility.
+//
+// Every target in the license graph becomes a package, and every install path
+// becomes a file contained by the package. Package identifiers derive from
+// the sorted target names, and file identifiers from the sorted install paths
+// so that identical inputs produce identical documents.
+func spdxNotice(ctx *context, files ...string) (*spdx.Document, error) {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return nil, failNoneRequested
+	}
+
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic).
+	licenseGraph, err := compliance.ReadLicenseGraph(rootFS, ctx.stderr, files)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return nil, failNoLicenses
+	}
+
+	targets := licenseGraph.Targets()
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name() < targets[j].Name() })
+
+	ids := make(spdxIDs)
+	pkgIDs := make(map[*compliance.TargetNode]common.ElementID)
+	for _, tn := range targets {
+		pkgIDs[tn] = ids.allocate("SPDXRef-Package-", tn.Name())
+	}
+
+	// licenseRefs maps license kinds without SPDX identifiers to their
+	// LicenseRef entry.
+	licenseRefs := make(map[string]*spdx.OtherLicense)
+
+	pkgs := []*spdx.Package{}
+	relationships := []*spdx.Relationship{}
+	type installedFile struct {
+		path string
+		pkg  common.ElementID
+	}
+	installed := []installedFile{}
+	for _, tn := range targets {
+		var licenseIDs []string
+		for _, kind := range tn.LicenseKinds() {
+			if id, ok := compliance.SpdxLicenseID(kind); ok {
+				licenseIDs = append(licenseIDs, id)
+				continue
+			}
+			ol, ok := licenseRefs[kind]
+			if !ok {
+				ol = &spdx.OtherLicense{
+					LicenseIdentifier: string(ids.allocate("LicenseRef-", compliance.SpdxIdentifierFromKind(kind))),
+					LicenseName:       kind,
+				}
+				licenseRefs[kind] = ol
+			}
+			if len(ol.ExtractedText) == 0 {
+				text, err := licenseText(rootFS, tn)
+				if err != nil {
+					return nil, err
+				}
+				ol.ExtractedText = text
+			}
+			licenseIDs = append(licenseIDs, ol.LicenseIdentifier)
+		}
+		sort.Strings(licenseIDs)
+
+		pkg := &spdx.Package{
+			PackageName:             tn.Name(),
+			PackageSPDXIdentifier:   pkgIDs[tn],
+			PackageDownloadLocation: NOASSERTION,
+			PackageLicenseConcluded: NOASSERTION,
+			PackageLicenseDeclared:  licenseExpression(licenseIDs),
+			PackageCopyrightText:    NOASSERTION,
+		}
+		if len(tn.ModuleName()) > 0 {
+			pkg.PackageComment = "module: " + tn.ModuleName()
+		}
+		pkgs = append(pkgs, pkg)
+
+		for _, installPath := range tn.Installed() {
+			installed = append(installed, installedFile{installPath, pkgIDs[tn]})
+		}
+	}
+
+	for _, root := range files {
+		for _, tn := range targets {
+			if tn.Name() == root {
+				relationships = append(relationships, relationship("DOCUMENT", "DESCRIBES", pkgIDs[tn]))
+			}
+		}
+	}
+
+	edges := licenseGraph.Edges()
+	sort.Sort(edges)
+	for _, e := range edges {
+		relationships = append(relationships, edgeRelationship(e, pkgIDs[e.Target()], pkgIDs[e.Dependency()]))
+	}
+
+	sort.SliceStable(installed, func(i, j int) bool { return installed[i].path < installed[j].path })
+	spdxFiles := []*spdx.File{}
+	for _, f := range installed {
+		file := &spdx.File{
+			FileName:           ctx.strip(f.path),
+			FileSPDXIdentifier: ids.allocate("SPDXRef-File-", ctx.strip(f.path)),
+			Checksums:          []common.Checksum{},
+			LicenseConcluded:   NOASSERTION,
+			FileCopyrightText:  NOASSERTION,
+		}
+		if data, err := fs.ReadFile(rootFS, f.path); err == nil {
+			sha1sum := sha1.Sum(data)
+			sha256sum := sha256.Sum256(data)
+			file.Checksums = append(file.Checksums,
+				common.Checksum{Algorithm: common.SHA1, Value: hex.EncodeToString(sha1sum[:])},
+				common.Checksum{Algorithm: common.SHA256, Value: hex.EncodeToString(sha256sum[:])})
+		} else {
+			file.FileComment = "not found in the build output; no checksum"
+		}
+		spdxFiles = append(spdxFiles, file)
+		relationships = append(relationships, relationship(f.pkg, "CONTAINS", file.FileSPDXIdentifier))
+	}
+
+	kinds := make([]string, 0, len(licenseRefs))
+	for kind := range licenseRefs {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	otherLicenses := []*spdx.OtherLicense{}
+	for _, kind := range kinds {
+		if len(licenseRefs[kind].ExtractedText) == 0 {
+			licenseRefs[kind].ExtractedText = NOASSERTION
+		}
+		otherLicenses = append(otherLicenses, licenseRefs[kind])
+	}
+
+	ci, err := builder2v3.BuildCreationInfoSection2_3("Organization", "Google LLC", nil)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to build creation info section for SPDX doc: %v\n", err)
+	}
+	ci.Created = ctx.creationTime()
+	// The version of the SPDX license list bundled with compliance.
+	ci.LicenseListVersion = "3.22"
+
+	docName := ctx.product
+	if len(docName) == 0 {
+		docName = strings.TrimSuffix(filepath.Base(files[0]), ".meta_lic")
+	}
+
+	doc := &spdx.Document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      docName,
+		DocumentNamespace: generateSPDXNamespace(docName, ci.Created),
+		CreationInfo:      ci,
+		Packages:          pkgs,
+		Files:             spdxFiles,
+		OtherLicenses:     otherLicenses,
+		Relationships:     relationships,
+	}
+
+	if err := spdxlib.ValidateDocument2_3(doc); err != nil {
+		return nil, fmt.Errorf("Unable to validate the SPDX doc: %v\n", err)
+	}
+
+	*ctx.deps = rootFS.Files()
+
+	return doc, nil
+}
+
+// licenseText returns the concatenated license texts of `tn` or the empty
+// string when it has none.
+func licenseText(rootFS fs.FS, tn *compliance.TargetNode) (string, error) {
+	var sb strings.Builder
+	for _, path := range tn.LicenseTexts() {
+		path = strings.SplitN(path, ":", 2)[0]
+		text, err := fs.ReadFile(rootFS, filepath.Clean(path))
+		if err != nil {
+			return "", fmt.Errorf("error reading license text file %q: %w", path, err)
+		}
+		sb.Write(text)
+	}
+	return sb.String(), nil
+}
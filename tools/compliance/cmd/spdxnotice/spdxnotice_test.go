@@ -0,0 +1,386 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance"
+
+	spdx_json "github.com/spdx/tools-golang/json"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func fixedTime() string {
+	return "2026-01-01T00:00:00Z"
+}
+
+var (
+	// spdxIDPattern matches the SPDXID of elements per the SPDX JSON schema.
+	spdxIDPattern = regexp.MustCompile(`^SPDXRef-[a-zA-Z0-9.-]+$`)
+	// licenseRefPattern matches the licenseId of extracted licensing infos.
+	licenseRefPattern = regexp.MustCompile(`^LicenseRef-[a-zA-Z0-9.-]+$`)
+	// createdPattern matches the creation time.
+	createdPattern = regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ$`)
+)
+
+// relationshipTypes lists the relationship types spdxnotice writes.
+var relationshipTypes = map[string]bool{
+	"DESCRIBES":          true,
+	"CONTAINS":           true,
+	"STATIC_LINK":        true,
+	"DYNAMIC_LINK":       true,
+	"BUILD_TOOL_OF":      true,
+	"TEST_DEPENDENCY_OF": true,
+}
+
+// document holds the parts of an SPDX 2.3 JSON document checked by tests.
+type document struct {
+	SPDXVersion       *string `json:"spdxVersion"`
+	DataLicense       *string `json:"dataLicense"`
+	SPDXID            *string `json:"SPDXID"`
+	Name              *string `json:"name"`
+	DocumentNamespace *string `json:"documentNamespace"`
+	CreationInfo      *struct {
+		Created  *string  `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages []struct {
+		Name             *string `json:"name"`
+		SPDXID           *string `json:"SPDXID"`
+		DownloadLocation *string `json:"downloadLocation"`
+		LicenseDeclared  *string `json:"licenseDeclared"`
+		CopyrightText    *string `json:"copyrightText"`
+	} `json:"packages"`
+	Files []struct {
+		FileName  *string           `json:"fileName"`
+		SPDXID    *string           `json:"SPDXID"`
+		Checksums []json.RawMessage `json:"checksums"`
+	} `json:"files"`
+	HasExtractedLicensingInfos []struct {
+		LicenseID     *string `json:"licenseId"`
+		ExtractedText *string `json:"extractedText"`
+	} `json:"hasExtractedLicensingInfos"`
+	Relationships []struct {
+		SPDXElementID      *string `json:"spdxElementId"`
+		RelatedSPDXElement *string `json:"relatedSpdxElement"`
+		RelationshipType   *string `json:"relationshipType"`
+	} `json:"relationships"`
+}
+
+// validate checks `data` against the structure required by the SPDX 2.3 JSON
+// schema: required properties, identifier patterns and references.
+func validate(t *testing.T, data []byte) *document {
+	t.Helper()
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unable to parse document: %s\n%s", err, string(data))
+	}
+	required := func(what string, v *string) string {
+		t.Helper()
+		if v == nil || len(*v) == 0 {
+			t.Errorf("missing required %s", what)
+			return ""
+		}
+		return *v
+	}
+	if v := required("spdxVersion", doc.SPDXVersion); v != "SPDX-2.3" {
+		t.Errorf("got spdxVersion %q, want \"SPDX-2.3\"", v)
+	}
+	if v := required("dataLicense", doc.DataLicense); v != "CC0-1.0" {
+		t.Errorf("got dataLicense %q, want \"CC0-1.0\"", v)
+	}
+	if v := required("SPDXID", doc.SPDXID); v != "SPDXRef-DOCUMENT" {
+		t.Errorf("got SPDXID %q, want \"SPDXRef-DOCUMENT\"", v)
+	}
+	required("name", doc.Name)
+	if v := required("documentNamespace", doc.DocumentNamespace); !strings.HasPrefix(v, "https://") || strings.Contains(v, "#") {
+		t.Errorf("got documentNamespace %q, want an absolute URI without #", v)
+	}
+	if doc.CreationInfo == nil {
+		t.Fatalf("missing required creationInfo")
+	}
+	if v := required("creationInfo.created", doc.CreationInfo.Created); !createdPattern.MatchString(v) {
+		t.Errorf("got creationInfo.created %q, want YYYY-MM-DDThh:mm:ssZ", v)
+	}
+	if len(doc.CreationInfo.Creators) == 0 {
+		t.Errorf("missing required creationInfo.creators")
+	}
+
+	ids := map[string]bool{"SPDXRef-DOCUMENT": true}
+	addID := func(id string) {
+		t.Helper()
+		if !spdxIDPattern.MatchString(id) {
+			t.Errorf("SPDXID %q does not match %s", id, spdxIDPattern)
+		}
+		if ids[id] {
+			t.Errorf("duplicate SPDXID %q", id)
+		}
+		ids[id] = true
+	}
+	licenseRefs := make(map[string]bool)
+	for _, l := range doc.HasExtractedLicensingInfos {
+		id := required("hasExtractedLicensingInfos.licenseId", l.LicenseID)
+		if !licenseRefPattern.MatchString(id) {
+			t.Errorf("licenseId %q does not match %s", id, licenseRefPattern)
+		}
+		licenseRefs[id] = true
+		required("hasExtractedLicensingInfos.extractedText", l.ExtractedText)
+	}
+	for _, p := range doc.Packages {
+		name := required("packages.name", p.Name)
+		addID(required("packages.SPDXID", p.SPDXID))
+		required("packages.downloadLocation", p.DownloadLocation)
+		required("packages.copyrightText", p.CopyrightText)
+		for _, id := range strings.Fields(strings.NewReplacer("(", " ", ")", " ", " AND ", " ").Replace(required("packages.licenseDeclared", p.LicenseDeclared))) {
+			if strings.HasPrefix(id, "LicenseRef-") && !licenseRefs[id] {
+				t.Errorf("package %q declares %q without extracted licensing info", name, id)
+			}
+		}
+	}
+	for _, f := range doc.Files {
+		required("files.fileName", f.FileName)
+		addID(required("files.SPDXID", f.SPDXID))
+		if f.Checksums == nil {
+			t.Errorf("missing required files.checksums for %q", *f.FileName)
+		}
+	}
+	for _, r := range doc.Relationships {
+		a := required("relationships.spdxElementId", r.SPDXElementID)
+		b := required("relationships.relatedSpdxElement", r.RelatedSPDXElement)
+		rel := required("relationships.relationshipType", r.RelationshipType)
+		if !ids[a] {
+			t.Errorf("relationship %s %s %s refers to unknown element %q", a, rel, b, a)
+		}
+		if !ids[b] {
+			t.Errorf("relationship %s %s %s refers to unknown element %q", a, rel, b, b)
+		}
+		if !relationshipTypes[rel] {
+			t.Errorf("unexpected relationship type %q", rel)
+		}
+	}
+	return &doc
+}
+
+// generate writes the SPDX document for `roots` in `rootFS`.
+func generate(t *testing.T, ctx *context, roots ...string) []byte {
+	t.Helper()
+	doc, err := spdxNotice(ctx, roots...)
+	if err != nil {
+		t.Fatalf("spdxnotice: error = %v, stderr = %v", err, ctx.stderr)
+	}
+	var out bytes.Buffer
+	if err := spdx_json.Save2_3(doc, &out); err != nil {
+		t.Fatalf("unable to save document: %s", err)
+	}
+	return out.Bytes()
+}
+
+func Test(t *testing.T) {
+	tests := []struct {
+		condition     string
+		name          string
+		roots         []string
+		packages      int
+		files         int
+		relationships []string
+		licenseRefs   []string
+	}{
+		{
+			condition:     "firstparty",
+			name:          "highest.apex",
+			roots:         []string{"highest.apex.meta_lic"},
+			packages:      7,
+			files:         6,
+			relationships: []string{"CONTAINS", "DESCRIBES", "DYNAMIC_LINK", "STATIC_LINK"},
+		},
+		{
+			condition:     "firstparty",
+			name:          "application",
+			roots:         []string{"application.meta_lic"},
+			packages:      4,
+			files:         4,
+			relationships: []string{"BUILD_TOOL_OF", "CONTAINS", "DESCRIBES", "DYNAMIC_LINK", "STATIC_LINK"},
+		},
+		{
+			condition:     "notice",
+			name:          "container",
+			roots:         []string{"container.zip.meta_lic"},
+			packages:      7,
+			files:         6,
+			relationships: []string{"CONTAINS", "DESCRIBES", "DYNAMIC_LINK", "STATIC_LINK"},
+			licenseRefs:   []string{"LicenseRef-BSD"},
+		},
+		{
+			condition:     "proprietary",
+			name:          "highest.apex",
+			roots:         []string{"highest.apex.meta_lic"},
+			packages:      7,
+			files:         6,
+			relationships: []string{"CONTAINS", "DESCRIBES", "DYNAMIC_LINK", "STATIC_LINK"},
+			licenseRefs:   []string{"LicenseRef-legacy-proprietary"},
+		},
+		{
+			condition:     "reciprocal",
+			name:          "bin1",
+			roots:         []string{"bin/bin1.meta_lic"},
+			packages:      3,
+			files:         2,
+			relationships: []string{"CONTAINS", "DESCRIBES", "STATIC_LINK"},
+			licenseRefs:   []string{"LicenseRef-MPL"},
+		},
+		{
+			condition:     "restricted",
+			name:          "highest.apex",
+			roots:         []string{"highest.apex.meta_lic"},
+			packages:      7,
+			files:         6,
+			relationships: []string{"CONTAINS", "DESCRIBES", "DYNAMIC_LINK", "STATIC_LINK"},
+			licenseRefs:   []string{"LicenseRef-LGPL-2.0", "LicenseRef-MPL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
+			roots := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				roots = append(roots, "testdata/"+tt.condition+"/"+r)
+			}
+			var deps []string
+			ctx := &context{&bytes.Buffer{}, &bytes.Buffer{}, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, fixedTime, &deps}
+			data := generate(t, ctx, roots...)
+			doc := validate(t, data)
+
+			if len(doc.Packages) != tt.packages {
+				t.Errorf("got %d packages, want %d", len(doc.Packages), tt.packages)
+			}
+			if len(doc.Files) != tt.files {
+				t.Errorf("got %d files, want %d", len(doc.Files), tt.files)
+			}
+			relTypes := make(map[string]bool)
+			for _, r := range doc.Relationships {
+				relTypes[*r.RelationshipType] = true
+			}
+			var actualRels []string
+			for rel := range relTypes {
+				actualRels = append(actualRels, rel)
+			}
+			sort.Strings(actualRels)
+			if strings.Join(actualRels, " ") != strings.Join(tt.relationships, " ") {
+				t.Errorf("got relationship types %q, want %q", actualRels, tt.relationships)
+			}
+			var actualRefs []string
+			for _, l := range doc.HasExtractedLicensingInfos {
+				actualRefs = append(actualRefs, *l.LicenseID)
+			}
+			if strings.Join(actualRefs, " ") != strings.Join(tt.licenseRefs, " ") {
+				t.Errorf("got license refs %q, want %q", actualRefs, tt.licenseRefs)
+			}
+
+			// Identical inputs produce identical documents.
+			if again := generate(t, ctx, roots...); !bytes.Equal(data, again) {
+				t.Errorf("document not deterministic:\n%s\n%s", string(data), string(again))
+			}
+			if _, err := spdx_json.Load2_3(bytes.NewReader(data)); err != nil {
+				t.Errorf("unable to load document: %s", err)
+			}
+			if len(deps) == 0 {
+				t.Errorf("no deps recorded")
+			}
+		})
+	}
+}
+
+func TestLicenseRefText(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin.meta_lic": &fstest.MapFile{Data: []byte(`package_name: "Bin"
+license_kinds: "legacy_by_exception_only"
+license_kinds: "SPDX-license-identifier-Apache-2.0"
+license_conditions: "by_exception_only"
+license_texts: "LICENSE"
+installed: "out/bin/bin"
+deps: {
+  file: "tool.meta_lic"
+  annotations: "toolchain"
+}
+`)},
+		"tool.meta_lic": &fstest.MapFile{Data: []byte(`package_name: "Tool"
+license_kinds: "SPDX-license-identifier-MIT"
+license_conditions: "notice"
+`)},
+		"LICENSE":     &fstest.MapFile{Data: []byte("Exceptional license text\n")},
+		"out/bin/bin": &fstest.MapFile{Data: []byte("binary")},
+	}
+	var deps []string
+	ctx := &context{&bytes.Buffer{}, &bytes.Buffer{}, rootFS, "product", []string{"out/"}, fixedTime, &deps}
+	data := generate(t, ctx, "bin.meta_lic")
+	doc := validate(t, data)
+
+	if len(doc.HasExtractedLicensingInfos) != 1 {
+		t.Fatalf("got %d extracted licensing infos, want 1", len(doc.HasExtractedLicensingInfos))
+	}
+	l := doc.HasExtractedLicensingInfos[0]
+	if *l.LicenseID != "LicenseRef-legacy-by-exception-only" || *l.ExtractedText != "Exceptional license text\n" {
+		t.Errorf("got %q with text %q, want \"LicenseRef-legacy-by-exception-only\" with text \"Exceptional license text\\n\"", *l.LicenseID, *l.ExtractedText)
+	}
+	if *doc.Name != "product" {
+		t.Errorf("got document name %q, want \"product\"", *doc.Name)
+	}
+	if actual, expected := *doc.Packages[0].LicenseDeclared, "(Apache-2.0 AND LicenseRef-legacy-by-exception-only)"; actual != expected {
+		t.Errorf("got licenseDeclared %q, want %q", actual, expected)
+	}
+	if len(doc.Files) != 1 || *doc.Files[0].FileName != "bin/bin" || len(doc.Files[0].Checksums) != 2 {
+		t.Errorf("got files %s, want bin/bin with SHA1 and SHA256 checksums", string(data))
+	}
+	found := false
+	for _, r := range doc.Relationships {
+		if *r.SPDXElementID == "SPDXRef-Package-tool.meta-lic" && *r.RelationshipType == "BUILD_TOOL_OF" && *r.RelatedSPDXElement == "SPDXRef-Package-bin.meta-lic" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("missing tool BUILD_TOOL_OF bin relationship in %s", string(data))
+	}
+}
+
+func TestSPDXIDs(t *testing.T) {
+	ids := make(spdxIDs)
+	for _, tt := range []struct{ name, expected string }{
+		{"lib/a_b.meta_lic", "SPDXRef-Package-lib-a-b.meta-lic"},
+		{"lib/a-b.meta_lic", "SPDXRef-Package-lib-a-b.meta-lic-2"},
+		{"lib/a+b.meta_lic", "SPDXRef-Package-lib-a-b.meta-lic-3"},
+		{"lib/ÿ.meta_lic", "SPDXRef-Package-lib--.meta-lic"},
+	} {
+		if actual := ids.allocate("SPDXRef-Package-", tt.name); string(actual) != tt.expected {
+			t.Errorf("allocate(%q): got %q, want %q", tt.name, actual, tt.expected)
+		}
+	}
+}
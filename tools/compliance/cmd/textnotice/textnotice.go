@@ -15,21 +15,35 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"hash"
 	"io"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"android/soong/response"
 	"android/soong/tools/compliance"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/google/blueprint/deptools"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
 )
 
 var (
@@ -37,30 +51,285 @@ var (
 	failNoLicenses    = fmt.Errorf("No licenses found")
 )
 
-type context struct {
-	stdout      io.Writer
-	stderr      io.Writer
-	rootFS      fs.FS
-	product     string
-	stripPrefix []string
-	title       string
-	deps        *[]string
+// buildContext holds the options and outputs of a textnotice run.
+type buildContext struct {
+	stdout         io.Writer
+	stderr         io.Writer
+	rootFS         fs.FS
+	product        string
+	stripPrefix    []string
+	title          []string
+	mergeSimilar   float64
+	useSpdxTexts   bool
+	skipBuildtime  bool
+	copyleftStatic bool
+	// markdown writes the notice as Markdown or is nil for plain text.
+	markdown *markdownWriter
+	// metrics receives Prometheus metrics for the license graph or is nil.
+	metrics io.Writer
+	// outputHashFile names where to write the SHA-256 of the output or is
+	// empty.
+	outputHashFile string
+	// logLevel is the minimum level of the messages logged to stderr.
+	logLevel slog.Level
+	// logJSON logs messages as JSON lines instead of key=value text.
+	logJSON bool
+	// aggregateIdentical merges the sections with license texts identical
+	// apart from whitespace.
+	aggregateIdentical bool
+	// stats prints a summary of the sections and size of the notice to
+	// stderr.
+	stats bool
+	// texts shares the normalized license texts between the products of a
+	// multiProductNotice run or is nil.
+	texts *textCache
+	// overrides replace the license conditions of targets per -overrides_file.
+	overrides []compliance.Override
+	// delta applies -baseline and -write_baseline to the notice index.
+	delta *compliance.NoticeDelta
+	// loadGraph names a license graph written by graphcache to load instead
+	// of reading the license metadata files or is empty.
+	loadGraph string
+	// missing applies -report_missing and -missing_fatal to the notice index.
+	missing *compliance.MissingTextReport
+	// pathTransform applies -rewrite_prefix and -filter_prefix to the
+	// install paths of the notice index or is nil to keep them all.
+	pathTransform compliance.PathTransform
+	// ort writes the notice as an ORT evaluated model instead of text.
+	ort bool
+	// hashWorkers limits the license texts hashed in parallel or is 0 for
+	// GOMAXPROCS.
+	hashWorkers int
+	// hashCache remembers the hashes of the license texts between runs or
+	// is nil to read every license text.
+	hashCache *compliance.HashCache
+	// showVersions adds the version from METADATA to the library names.
+	showVersions bool
+	// libraryNames overrides the library names per -library_names or is
+	// nil.
+	libraryNames *compliance.LibraryNames
+	// emptyText selects how to index empty license texts per -empty_text.
+	emptyText compliance.EmptyTextMode
+	// keepGoing reports every malformed license metadata file per -keep_going.
+	keepGoing bool
+	deps      *[]string
 }
 
-func (ctx context) strip(installPath string) string {
-	for _, prefix := range ctx.stripPrefix {
-		if strings.HasPrefix(installPath, prefix) {
-			p := strings.TrimPrefix(installPath, prefix)
-			if 0 == len(p) {
-				p = ctx.product
-			}
-			if 0 == len(p) {
-				continue
+func (bc buildContext) strip(installPath string) string {
+	return compliance.StripPrefix(installPath, bc.stripPrefix, bc.product)
+}
+
+// libName returns the name of library `libName` to write followed by its
+// version when showing versions and METADATA names one.
+func (bc buildContext) libName(ni *compliance.NoticeIndex, libName string) string {
+	if !bc.showVersions {
+		return libName
+	}
+	if version := ni.Version(libName); len(version) > 0 {
+		return libName + " " + version
+	}
+	return libName
+}
+
+// filterStripped returns the transform keeping the install paths starting
+// with any of `prefixes` after -strip_prefix.
+func filterStripped(prefixes, stripPrefix []string, product string) compliance.PathTransform {
+	filter := compliance.FilterPrefixTransform(prefixes)
+	return func(installPath string) (string, bool) {
+		if _, ok := filter(compliance.StripPrefix(installPath, stripPrefix, product)); !ok {
+			return "", false
+		}
+		return installPath, true
+	}
+}
+
+// logger returns a logger writing the messages at or above `bc.logLevel` to
+// `bc.stderr` as JSON or text.
+func (bc buildContext) logger() *slog.Logger {
+	opts := slog.HandlerOptions{
+		Level: bc.logLevel,
+		// Omit the time so that identical runs log identical lines.
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
 			}
-			return p
+			return a
+		},
+	}
+	if bc.logJSON {
+		return slog.New(opts.NewJSONHandler(bc.stderr))
+	}
+	return slog.New(opts.NewTextHandler(bc.stderr))
+}
+
+// NoticeStats summarizes the sections and size of a notice.
+type NoticeStats struct {
+	// SectionCount is the number of license text sections.
+	SectionCount int
+	// TotalBytes is the size of the notice before compression.
+	TotalBytes int64
+	// ConditionCounts maps condition names to the number of sections for
+	// which the condition is the most restrictive per statsConditions.
+	ConditionCounts map[string]int
+}
+
+// statsConditions lists the license conditions from most to least
+// restrictive to attribute each section to a single condition.
+var statsConditions = []compliance.LicenseCondition{
+	compliance.NotAllowedCondition,
+	compliance.ByExceptionOnlyCondition,
+	compliance.ProprietaryCondition,
+	compliance.RestrictedCondition,
+	compliance.WeaklyRestrictedCondition,
+	compliance.ReciprocalCondition,
+	compliance.NoticeCondition,
+	compliance.PermissiveCondition,
+	compliance.UnencumberedCondition,
+}
+
+// add counts the section for `group`.
+func (ns *NoticeStats) add(group compliance.NoticeGroup) {
+	ns.SectionCount++
+	for _, lc := range statsConditions {
+		if group.Conditions.HasAny(lc) {
+			ns.ConditionCounts[lc.Name()]++
+			return
+		}
+	}
+}
+
+// String returns a summary such as "42 sections, 3 restricted, 39 notice,
+// 128 KiB" listing the conditions from most to least restrictive.
+func (ns NoticeStats) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d sections", ns.SectionCount)
+	for _, lc := range statsConditions {
+		if n := ns.ConditionCounts[lc.Name()]; n > 0 {
+			fmt.Fprintf(&sb, ", %d %s", n, lc.Name())
+		}
+	}
+	if ns.TotalBytes < 1024 {
+		fmt.Fprintf(&sb, ", %d B", ns.TotalBytes)
+	} else {
+		fmt.Fprintf(&sb, ", %d KiB", (ns.TotalBytes+512)/1024)
+	}
+	return sb.String()
+}
+
+// attrs returns the stats as log attributes listing the conditions from most
+// to least restrictive.
+func (ns NoticeStats) attrs() []any {
+	var conditions []slog.Attr
+	for _, lc := range statsConditions {
+		if n := ns.ConditionCounts[lc.Name()]; n > 0 {
+			conditions = append(conditions, slog.Int(lc.Name(), n))
+		}
+	}
+	return []any{slog.Int("sections", ns.SectionCount), slog.Group("conditions", conditions...), slog.Int64("bytes", ns.TotalBytes)}
+}
+
+// countingWriter counts the bytes written through it to `w`.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// textCache remembers the normalized license text for each hash so that
+// products sharing a license text normalize it only once.
+type textCache struct {
+	mu    sync.Mutex
+	texts map[string]string
+}
+
+// newTextCache returns an empty textCache.
+func newTextCache() *textCache {
+	return &textCache{texts: make(map[string]string)}
+}
+
+// normalize returns `text` with the copyright lines normalized, reusing the
+// result for an earlier text with the same `key` when `tc` is not nil.
+func (tc *textCache) normalize(key string, text []byte) string {
+	if tc == nil {
+		return compliance.NormalizeCopyrights(string(text))
+	}
+	tc.mu.Lock()
+	normalized, ok := tc.texts[key]
+	tc.mu.Unlock()
+	if ok {
+		return normalized
+	}
+	normalized = compliance.NormalizeCopyrights(string(text))
+	tc.mu.Lock()
+	tc.texts[key] = normalized
+	tc.mu.Unlock()
+	return normalized
+}
+
+// markdownWriter writes the elements of a Markdown document.
+type markdownWriter struct {
+	w io.Writer
+}
+
+// markdownSpecial matches the characters with special meaning in Markdown
+// inline text.
+var markdownSpecial = regexp.MustCompile("[\\\\`*_{}\\[\\]()<>#+\\-.!|~&]")
+
+// heading writes a level `level` heading for `text`.
+func (mw *markdownWriter) heading(level int, text string) {
+	fmt.Fprintf(mw.w, "%s %s\n\n", strings.Repeat("#", level), markdownSpecial.ReplaceAllString(text, "\\$0"))
+}
+
+// paragraph writes `text` as a paragraph.
+func (mw *markdownWriter) paragraph(text string) {
+	fmt.Fprintf(mw.w, "%s\n\n", markdownSpecial.ReplaceAllString(text, "\\$0"))
+}
+
+// codeBullets writes a bullet list with each of `items` as inline code.
+func (mw *markdownWriter) codeBullets(items []string) {
+	for _, item := range items {
+		// Delimit with more backticks than any run in the item and pad
+		// with spaces so leading or trailing backticks do not merge.
+		fence := strings.Repeat("`", longestRun(item, '`')+1)
+		fmt.Fprintf(mw.w, "- %s %s %s\n", fence, item, fence)
+	}
+	fmt.Fprintln(mw.w)
+}
+
+// codeBlock writes `text` as a fenced code block.
+func (mw *markdownWriter) codeBlock(text string) {
+	n := longestRun(text, '`') + 1
+	if n < 3 {
+		n = 3
+	}
+	fence := strings.Repeat("`", n)
+	fmt.Fprintln(mw.w, fence)
+	io.WriteString(mw.w, text)
+	if !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(mw.w)
+	}
+	fmt.Fprintf(mw.w, "%s\n\n", fence)
+}
+
+// longestRun returns the length of the longest run of `c` in `s`.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
 		}
 	}
-	return installPath
+	return longest
 }
 
 // newMultiString creates a flag that allows multiple values in an array.
@@ -103,7 +372,19 @@ func main() {
 	flags.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
 
-Outputs a text NOTICE file.
+Outputs a text NOTICE file, or a Markdown NOTICE file with -format markdown,
+or an OSS Review Toolkit (ORT) evaluated model JSON file with -format ort.
+
+Reads additional root files one per line from -roots_file when given.
+
+Loads the license graph written by graphcache from -load_graph instead of
+reading the license metadata files when given, e.g. -load_graph graph.pb
+
+Writes one notice per product to -output_dir instead when -product_roots is
+given, e.g. -product_roots phone=phone_roots.txt -product_roots tablet=...
+
+Regenerates -o whenever the root files or any file they read change when
+-watch is given, until interrupted.
 
 Options:
 `, filepath.Base(os.Args[0]))
@@ -112,15 +393,135 @@ Options:
 
 	outputFile := flags.String("o", "-", "Where to write the NOTICE text file. (default stdout)")
 	depsFile := flags.String("d", "", "Where to write the deps file")
+	ninjaDepsFile := flags.String("ninja_deps_file", "", "Where to write the Ninja depfile listing every license metadata and license text file read. Must name the -o file with a .d suffix, e.g. NOTICE.txt.d")
+	rootsFile := flags.String("roots_file", "", "File listing root .meta_lic files one per line, in addition to any arguments. (use - for stdin)")
 	product := flags.String("product", "", "The name of the product for which the notice is generated.")
 	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
-	title := flags.String("title", "", "The title of the notice file.")
+	rewritePrefix := newMultiString(flags, "rewrite_prefix", "A prefix of install paths to replace before -strip_prefix as from=to. e.g. out/dist/images/=out/target/product/fictional/ (multiple allowed; first match wins)")
+	filterPrefix := newMultiString(flags, "filter_prefix", "Only include install paths, after -strip_prefix, starting with prefix. Targets with no included install paths contribute no license texts. (multiple allowed)")
+	baseline := flags.String("baseline", "", "A baseline of license text hashes per library from a prior release. Only libraries with new or changed license texts appear in the notice, and removed libraries are listed on stderr.")
+	writeBaseline := flags.String("write_baseline", "", "Where to write the baseline of license text hashes per library for use with -baseline in the next release.")
+	reportMissing := flags.String("report_missing", "", "Where to write the shipped targets contributing no license text to the notice with their license conditions and install paths.")
+	missingFatal := flags.Bool("missing_fatal", false, "Whether to fail when any shipped target contributes no license text to the notice.")
+	emptyTextName := flags.String("empty_text", "keep", "How to treat license text files that are empty or only whitespace after warning about them: skip, placeholder or keep.")
+	keepGoing := flags.Bool("keep_going", false, "Report every malformed license metadata file, up to 100, instead of stopping at the first.")
+	zipFile := flags.String("zip", "", "Read the license metadata, license texts and METADATA files from this zip archive, or from a directory in it given as archive.zip!/dir.")
+	loadGraph := flags.String("load_graph", "", "A license graph written by graphcache to load instead of reading the license metadata files. (replaces the root files)")
+	overridesFile := flags.String("overrides_file", "", "A YAML file of {path, condition} entries replacing the license conditions declared by the named license metadata files.")
+	title := newMultiString(flags, "title", "The title of the notice file. (multiple allowed; one heading line each)")
+	mergeSimilar := flags.Float64("merge_similar", 0, "Merge license texts at least this similar (0.0 to 1.0) into one section. e.g. 0.9 (default 0 means no merging)")
+	skipBuildtime := flags.Bool("skip_buildtime", false, "Whether to ignore dependencies annotated \"buildtime\" or \"test\" so their licenses do not apply.")
+	copyleftStatic := flags.Bool("copyleft_static_only", false, "Whether restricted licenses apply only across static links.")
+	logLevel := slog.LevelInfo
+	flags.Func("log_level", "The minimum level of messages to log: debug, info, warn or error. (default INFO)", func(s string) error {
+		return logLevel.UnmarshalText([]byte(s))
+	})
+	logJSON := flags.Bool("log_json", false, "Whether to log messages as JSON lines.")
+	format := flags.String("format", "text", "The output format: text, markdown or ort.")
+	timeout := flags.Duration("timeout", 0, "Give up reading and indexing license metadata after this long, and exit with status 2. e.g. 120s (default 0 means no limit)")
+	outputHashFile := flags.String("output_hash_file", "", "Where to write the SHA-256 hex digest of the notice after a successful run.")
+	metricsFile := flags.String("metrics_file", "", "Where to write metrics about the license graph in Prometheus text format.")
+	stats := flags.Bool("stats", false, "Whether to print the number of sections per most restrictive license condition and the size of the notice to stderr.")
+	aggregateIdentical := flags.Bool("aggregate_identical", false, "Whether to merge the sections of license texts identical apart from whitespace into one section listing all of their libraries.")
+	hashWorkers := flags.Int("hash_workers", 0, "How many license text files to read and hash in parallel. (default 0 means GOMAXPROCS)")
+	showVersions := flags.Bool("show_versions", false, "Whether to show the version of each library from the METADATA of its projects and warn about libraries whose projects name different versions.")
+	libraryNamesFile := flags.String("library_names", "", "A file of key=name lines naming the library of the targets in projects under the key directory, or of the key license metadata file, instead of the derived name.")
+	hashCacheFile := flags.String("hash_cache", "", "Where to keep the hashes of the license texts between runs to read only the changed files.")
+	useSpdxTexts := flags.Bool("use_spdx_texts", false, "Use the SPDX license list text for targets with SPDX license identifiers but no license text files.")
+	productRoots := newMultiString(flags, "product_roots", "A product name and the file listing its root .meta_lic files one per line as name=file. (multiple allowed; requires -output_dir)")
+	outputDir := flags.String("output_dir", "", "Where to write the notice for each product of -product_roots as <product>.txt or <product>.md.")
+	watch := flags.Bool("watch", false, "Whether to keep running and regenerate -o whenever a license metadata or license text file read for the notice changes.")
 
 	flags.Parse(expandedArgs)
 
-	// Must specify at least one root target.
-	if flags.NArg() == 0 {
+	roots := flags.Args()
+	if len(*rootsFile) > 0 {
+		rootsFromFile, err := readRootsFile(*rootsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read roots file %q: %s\n", *rootsFile, err)
+			os.Exit(1)
+		}
+		roots = append(roots, rootsFromFile...)
+	}
+
+	if len(*loadGraph) > 0 && (len(roots) > 0 || len(*productRoots) > 0) {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-load_graph cannot be combined with root files, -roots_file or -product_roots\n")
+		os.Exit(2)
+	}
+
+	var products map[string][]string
+	if len(*productRoots) > 0 {
+		if len(roots) > 0 {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "-product_roots cannot be combined with root files or -roots_file\n")
+			os.Exit(2)
+		}
+		if len(*outputDir) == 0 {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "-product_roots requires -output_dir\n")
+			os.Exit(2)
+		}
+		if *outputFile != "-" || len(*outputHashFile) > 0 || len(*metricsFile) > 0 || len(*baseline) > 0 || len(*writeBaseline) > 0 || len(*reportMissing) > 0 {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "-product_roots cannot be combined with -o, -output_hash_file, -metrics_file, -baseline, -write_baseline or -report_missing\n")
+			os.Exit(2)
+		}
+		products = make(map[string][]string)
+		for _, productRoot := range *productRoots {
+			name, rootsFile, ok := strings.Cut(productRoot, "=")
+			if !ok || len(name) == 0 || len(rootsFile) == 0 {
+				flags.Usage()
+				fmt.Fprintf(os.Stderr, "-product_roots must be name=file, got %q\n", productRoot)
+				os.Exit(2)
+			}
+			if _, ok := products[name]; ok {
+				flags.Usage()
+				fmt.Fprintf(os.Stderr, "-product_roots names product %q more than once\n", name)
+				os.Exit(2)
+			}
+			productFiles, err := readRootsFile(rootsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "cannot read roots file %q for product %q: %s\n", rootsFile, name, err)
+				os.Exit(1)
+			}
+			products[name] = productFiles
+		}
+	} else if len(roots) == 0 && len(*loadGraph) == 0 {
+		// Must specify at least one root target.
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if *mergeSimilar < 0 || *mergeSimilar > 1 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-merge_similar must be between 0.0 and 1.0\n")
+		os.Exit(2)
+	}
+
+	if *timeout < 0 {
 		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-timeout must not be negative\n")
+		os.Exit(2)
+	}
+
+	if len(*ninjaDepsFile) > 0 && (*outputFile == "-" || *ninjaDepsFile != *outputFile+".d") {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-ninja_deps_file must name the -o file with a .d suffix, got %q for %q\n", *ninjaDepsFile, *outputFile)
+		os.Exit(2)
+	}
+
+	if *watch {
+		if *outputFile == "-" || len(*depsFile) > 0 || len(*ninjaDepsFile) > 0 || len(*loadGraph) > 0 || len(*productRoots) > 0 || len(*metricsFile) > 0 || len(*writeBaseline) > 0 || *timeout > 0 {
+			flags.Usage()
+			fmt.Fprintf(os.Stderr, "-watch requires -o and cannot be combined with -d, -ninja_deps_file, -load_graph, -product_roots, -metrics_file, -write_baseline or -timeout\n")
+			os.Exit(2)
+		}
+	}
+
+	if *format != "text" && *format != "markdown" && *format != "ort" {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-format must be text, markdown or ort\n")
 		os.Exit(2)
 	}
 
@@ -160,79 +561,923 @@ Options:
 
 	var deps []string
 
-	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, *title, &deps}
+	var markdown *markdownWriter
+	if *format == "markdown" {
+		markdown = &markdownWriter{ofile}
+	}
+
+	var metrics io.Writer
+	var metricsBuf *bytes.Buffer
+	if len(*metricsFile) > 0 {
+		metricsBuf = &bytes.Buffer{}
+		metrics = metricsBuf
+	}
+
+	var overrides []compliance.Override
+	if len(*overridesFile) > 0 {
+		var err error
+		overrides, err = compliance.ReadOverridesFile(*overridesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read overrides file %q: %s\n", *overridesFile, err)
+			os.Exit(1)
+		}
+	}
 
-	err := textNotice(ctx, flags.Args()...)
+	var libraryNames *compliance.LibraryNames
+	if len(*libraryNamesFile) > 0 {
+		var err error
+		libraryNames, err = compliance.ReadLibraryNamesFile(*libraryNamesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read library names file %q: %s\n", *libraryNamesFile, err)
+			os.Exit(1)
+		}
+	}
+
+	emptyText, err := compliance.ParseEmptyTextMode(*emptyTextName)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	if *hashWorkers < 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-hash_workers must not be negative\n")
+		os.Exit(2)
+	}
+
+	rewrite, err := compliance.RewritePrefixTransform(*rewritePrefix)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+	var pathTransform compliance.PathTransform
+	if len(*rewritePrefix) > 0 || len(*filterPrefix) > 0 {
+		pathTransform = compliance.ChainPathTransforms(rewrite, filterStripped(*filterPrefix, *stripPrefix, *product))
+	}
+
+	// Log like the buildContext below from here on.
+	logger := buildContext{stderr: os.Stderr, logLevel: logLevel, logJSON: *logJSON}.logger()
+
+	var hashCache *compliance.HashCache
+	if len(*hashCacheFile) > 0 {
+		hashCache, err = compliance.ReadHashCache(*hashCacheFile)
+		if err != nil {
+			// A corrupt cache only costs reading every license text again.
+			logger.Warn("ignoring hash cache", "file", *hashCacheFile, "error", err)
+		}
+	}
+
+	var rootFS fs.FS = compliance.FS
+	var zipFS *compliance.ZipFS
+	if len(*zipFile) > 0 {
+		zipFS, err = compliance.OpenZipFS(*zipFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *zipFile, err)
+			os.Exit(1)
+		}
+		rootFS = zipFS
+	}
+
+	bc := &buildContext{
+		stdout:             ofile,
+		stderr:             os.Stderr,
+		rootFS:             rootFS,
+		product:            *product,
+		stripPrefix:        *stripPrefix,
+		title:              *title,
+		mergeSimilar:       *mergeSimilar,
+		useSpdxTexts:       *useSpdxTexts,
+		skipBuildtime:      *skipBuildtime,
+		copyleftStatic:     *copyleftStatic,
+		markdown:           markdown,
+		metrics:            metrics,
+		outputHashFile:     *outputHashFile,
+		logLevel:           logLevel,
+		logJSON:            *logJSON,
+		aggregateIdentical: *aggregateIdentical,
+		stats:              *stats,
+		overrides:          overrides,
+		delta:              &compliance.NoticeDelta{Baseline: *baseline, WriteBaseline: *writeBaseline},
+		loadGraph:          *loadGraph,
+		missing:            &compliance.MissingTextReport{File: *reportMissing, Fatal: *missingFatal},
+		pathTransform:      pathTransform,
+		ort:                *format == "ort",
+		hashWorkers:        *hashWorkers,
+		hashCache:          hashCache,
+		showVersions:       *showVersions,
+		libraryNames:       libraryNames,
+		emptyText:          emptyText,
+		keepGoing:          *keepGoing,
+		deps:               &deps,
+	}
+
+	// Stop reading and indexing on interrupt.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	if *watch {
+		w, err := newFsnotifyWatcher()
+		if err != nil {
+			logger.Error("could not watch files", "error", err)
+			os.Exit(1)
+		}
+		err = watchNotice(ctx, w, logger, watchDebounce, roots, func() ([]string, error) {
+			return writeNotice(ctx, bc, *outputFile, roots...)
+		})
+		stop()
+		w.Close()
+		if err != nil {
+			logger.Error(strings.TrimSpace(err.Error()))
+			os.Exit(1)
+		}
+		if hashCache != nil {
+			err := hashCache.Write(*hashCacheFile)
+			if err != nil {
+				logger.Error("could not write hash cache", "file", *hashCacheFile, "error", err)
+				os.Exit(1)
+			}
+		}
+		os.Exit(0)
+	}
+	var digest string
+	if products != nil {
+		err = multiProductNotice(ctx, bc, *outputDir, products)
+	} else {
+		digest, err = textNotice(ctx, bc, roots...)
+	}
+	stop()
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Error(fmt.Sprintf("textnotice timed out after %s reading license metadata", *timeout), "files", roots)
+		os.Exit(2)
+	}
 	if err != nil {
 		if err == failNoneRequested {
 			flags.Usage()
 		}
-		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		logger.Error(strings.TrimSpace(err.Error()))
 		os.Exit(1)
 	}
 	if closer != nil {
 		closer.Close()
 	}
+	if hashCache != nil {
+		err := hashCache.Write(*hashCacheFile)
+		if err != nil {
+			logger.Error("could not write hash cache", "file", *hashCacheFile, "error", err)
+			os.Exit(1)
+		}
+	}
 
 	if *outputFile != "-" {
-		err := os.WriteFile(*outputFile, obuf.Bytes(), 0666)
+		err = writeOutput(*outputFile, obuf.Bytes(), *outputHashFile, digest)
+	} else {
+		err = writeOutputHash(*outputHashFile, digest)
+	}
+	if err != nil {
+		logger.Error(strings.TrimSpace(err.Error()))
+		os.Exit(1)
+	}
+	if metricsBuf != nil {
+		err := os.WriteFile(*metricsFile, metricsBuf.Bytes(), 0666)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			logger.Error("could not write metrics", "file", *metricsFile, "error", err)
 			os.Exit(1)
 		}
 	}
+	if zipFS != nil {
+		// The files read are inside the archive.
+		deps = []string{zipFS.Archive()}
+	}
+	if len(*baseline) > 0 {
+		deps = append(deps, *baseline)
+	}
+	if len(*overridesFile) > 0 {
+		deps = append(deps, *overridesFile)
+	}
+	if len(*libraryNamesFile) > 0 {
+		deps = append(deps, *libraryNamesFile)
+	}
+	if len(*loadGraph) > 0 {
+		deps = append(deps, *loadGraph)
+	}
 	if *depsFile != "" {
-		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
+		target := *outputFile
+		if products != nil {
+			// Ninja reads the deps for the first output of the rule.
+			names := make([]string, 0, len(products))
+			for name := range products {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			target = productOutput(bc, *outputDir, names[0])
+		}
+		err := deptools.WriteDepFile(*depsFile, target, deps)
+		if err != nil {
+			logger.Error("could not write deps", "file", *depsFile, "error", err)
+			os.Exit(1)
+		}
+	}
+	if *ninjaDepsFile != "" {
+		err := compliance.WriteDepsFile(*ninjaDepsFile, deps)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
+			logger.Error("could not write Ninja deps", "file", *ninjaDepsFile, "error", err)
 			os.Exit(1)
 		}
 	}
 	os.Exit(0)
 }
 
+// readRootsFile returns the root files listed one per line in the file at
+// `path`, or on stdin when `path` is "-", ignoring blank lines and lines
+// starting with "#".
+func readRootsFile(path string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var roots []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		roots = append(roots, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
 // textNotice implements the textNotice utility.
-func textNotice(ctx *context, files ...string) error {
-	// Must be at least one root file.
-	if len(files) < 1 {
-		return failNoneRequested
+//
+// Returns the SHA-256 hex digest of the notice when `bc.outputHashFile` is
+// not empty, or `ctx.Err()` when `ctx` is done before reading and indexing
+// finish.
+func textNotice(ctx context.Context, bc *buildContext, files ...string) (string, error) {
+	// Must be at least one root file unless loading the graph.
+	if len(files) < 1 && len(bc.loadGraph) == 0 {
+		return "", failNoneRequested
 	}
 
-	// Read the license graph from the license metadata files (*.meta_lic).
-	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
-	if err != nil {
-		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(bc.rootFS)
+
+	// Read the license graph from the license metadata files (*.meta_lic)
+	// or load the graph written by graphcache.
+	//
+	// The returned error gets logged instead of the unstructured error lines.
+	start := time.Now()
+	var licenseGraph *compliance.LicenseGraph
+	var err error
+	if len(bc.loadGraph) > 0 {
+		licenseGraph, err = loadLicenseGraph(bc.loadGraph)
+		if err != nil {
+			return "", fmt.Errorf("Unable to load license graph %q: %w\n", bc.loadGraph, err)
+		}
+	} else {
+		licenseGraph, err = compliance.ReadLicenseGraphWithOptions(ctx, rootFS, io.Discard, files, compliance.ReadOptions{KeepGoing: bc.keepGoing})
+		if err != nil {
+			return "", fmt.Errorf("Unable to read license metadata file(s) %q: %w\n", files, err)
+		}
 	}
 	if licenseGraph == nil {
-		return failNoLicenses
+		return "", failNoLicenses
+	}
+	if len(bc.overrides) > 0 {
+		licenseGraph = compliance.ApplyOverrides(licenseGraph, bc.overrides)
+	}
+	parseDuration := time.Since(start)
+	if bc.skipBuildtime {
+		licenseGraph.SkipBuildtimeDeps()
+	}
+	if bc.copyleftStatic {
+		licenseGraph.PropagateCopyleftStaticOnly()
+	}
+
+	// Report every missing license text file before writing any output.
+	err = compliance.ValidateLicenseFiles(licenseGraph, rootFS)
+	if err != nil {
+		return "", err
 	}
 
 	// rs contains all notice resolutions.
 	rs := compliance.ResolveNotices(licenseGraph)
 
-	ni, err := compliance.IndexLicenseTexts(ctx.rootFS, licenseGraph, rs)
+	if bc.metrics != nil {
+		err = compliance.RecordMetrics(licenseGraph, parseDuration).WritePrometheus(bc.metrics)
+		if err != nil {
+			return "", fmt.Errorf("Unable to write metrics: %w\n", err)
+		}
+	}
+
+	if bc.copyleftStatic {
+		logger := bc.logger()
+		for _, e := range compliance.DynamicLinkWarnings(licenseGraph) {
+			logger.Warn("dynamically links restricted", "target", e.Target().Name(), "dependency", e.Dependency().Name())
+		}
+	}
+
+	opts := compliance.IndexOptions{PathTransform: bc.pathTransform, HashWorkers: bc.hashWorkers, HashCache: bc.hashCache, LibraryNames: bc.libraryNames, EmptyText: bc.emptyText}
+	if bc.useSpdxTexts {
+		opts.SpdxFallback = true
+		opts.Cache = compliance.MapLicenseCache{}
+	}
+	ni, err := compliance.IndexLicenseTextsWithOptions(ctx, rootFS, licenseGraph, rs, opts)
 	if err != nil {
-		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
+		return "", fmt.Errorf("Unable to read license text file(s) for %q: %w\n", files, err)
+	}
+	if bc.mergeSimilar > 0 {
+		ni.MergeSimilarTexts(bc.mergeSimilar)
+	}
+	if err := bc.delta.Apply(ni, bc.stderr); err != nil {
+		return "", err
+	}
+	if err := bc.missing.Apply(ni); err != nil {
+		return "", err
+	}
+	for _, e := range ni.EmptyTexts() {
+		bc.logger().Warn("empty license text file", "target", e.Target, "path", e.Path)
+	}
+	if bc.libraryNames != nil {
+		logger := bc.logger()
+		for _, key := range bc.libraryNames.Unused() {
+			logger.Warn("unused library name override", "entry", key)
+		}
+	}
+	if bc.showVersions {
+		logger := bc.logger()
+		for _, vc := range ni.VersionConflicts() {
+			logger.Warn("conflicting library versions", "library", vc.LibName, "versions", vc.String())
+		}
 	}
 
-	if len(ctx.title) > 0 {
-		fmt.Fprintf(ctx.stdout, "%s\n\n", ctx.title)
+	// Hash the output as written when requested.
+	var outputHash hash.Hash
+	if len(bc.outputHashFile) > 0 {
+		outputHash = sha256.New()
+		hashing := *bc
+		hashing.stdout = io.MultiWriter(bc.stdout, outputHash)
+		if bc.markdown != nil {
+			hashing.markdown = &markdownWriter{io.MultiWriter(bc.markdown.w, outputHash)}
+		}
+		bc = &hashing
 	}
-	for h := range ni.Hashes() {
-		fmt.Fprintln(ctx.stdout, "==============================================================================")
-		for _, libName := range ni.HashLibs(h) {
-			fmt.Fprintf(ctx.stdout, "%s used by:\n", libName)
-			for _, installPath := range ni.HashLibInstalls(h, libName) {
-				fmt.Fprintf(ctx.stdout, "  %s\n", ctx.strip(installPath))
+
+	// Count the bytes of the output as written when requested.
+	var size *countingWriter
+	if bc.stats {
+		counting := *bc
+		if bc.markdown != nil {
+			size = &countingWriter{w: bc.markdown.w}
+			counting.markdown = &markdownWriter{size}
+		} else {
+			size = &countingWriter{w: bc.stdout}
+			counting.stdout = size
+		}
+		bc = &counting
+	}
+
+	var stats NoticeStats
+	if bc.ort {
+		stats, err = writeORT(bc, ni, files)
+		if err != nil {
+			return "", err
+		}
+	} else if bc.markdown != nil {
+		stats = writeMarkdown(bc, ni)
+	} else {
+		stats = writeText(bc, ni)
+	}
+
+	if size != nil {
+		stats.TotalBytes = size.n
+		bc.logger().Info("notice stats", stats.attrs()...)
+	}
+
+	*bc.deps = rootFS.Files()
+
+	if outputHash != nil {
+		return hex.EncodeToString(outputHash.Sum(nil)), nil
+	}
+	return "", nil
+}
+
+// watchDebounce is how long watchNotice waits after a change for further
+// changes before regenerating the notice.
+const watchDebounce = 200 * time.Millisecond
+
+// Watcher reports the changes to a set of files.
+type Watcher interface {
+	// Add starts watching the file `name`.
+	Add(name string) error
+	// Remove stops watching the file `name`.
+	Remove(name string) error
+	// Events returns the channel receiving the changes to watched files.
+	Events() <-chan fsnotify.Event
+	// Errors returns the channel receiving any errors watching files.
+	Errors() <-chan error
+	// Close stops watching all files.
+	Close() error
+}
+
+// fsnotifyWatcher implements Watcher using fsnotify.
+type fsnotifyWatcher struct {
+	*fsnotify.Watcher
+}
+
+// newFsnotifyWatcher returns a Watcher of the files on disk.
+func newFsnotifyWatcher() (*fsnotifyWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifyWatcher{w}, nil
+}
+
+func (w *fsnotifyWatcher) Events() <-chan fsnotify.Event { return w.Watcher.Events }
+func (w *fsnotifyWatcher) Errors() <-chan error          { return w.Watcher.Errors }
+
+// writeNotice writes the textNotice for `files` to `outputFile`, compressed
+// when `outputFile` ends in ".gz", and returns the files read.
+func writeNotice(ctx context.Context, bc *buildContext, outputFile string, files ...string) ([]string, error) {
+	var obuf bytes.Buffer
+	var ofile io.Writer = &obuf
+	var gz *gzip.Writer
+	if strings.HasSuffix(outputFile, ".gz") {
+		gz, _ = gzip.NewWriterLevel(&obuf, gzip.BestCompression)
+		ofile = gz
+	}
+	var deps []string
+	wc := *bc
+	wc.stdout = ofile
+	if bc.markdown != nil {
+		wc.markdown = &markdownWriter{ofile}
+	}
+	wc.deps = &deps
+	digest, err := textNotice(ctx, &wc, files...)
+	if err != nil {
+		return nil, err
+	}
+	if gz != nil {
+		gz.Close()
+	}
+	if err := writeOutput(outputFile, obuf.Bytes(), bc.outputHashFile, digest); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// writeOutput writes the notice `output` to `outputFile` and only then the
+// hex digest `digest` of the notice to `outputHashFile`, so that no hash is
+// left behind for a notice that could not be written.
+func writeOutput(outputFile string, output []byte, outputHashFile, digest string) error {
+	if err := os.WriteFile(outputFile, output, 0666); err != nil {
+		return fmt.Errorf("Unable to write notice to %q: %w\n", outputFile, err)
+	}
+	return writeOutputHash(outputHashFile, digest)
+}
+
+// writeOutputHash writes the hex digest `digest` of the notice to
+// `outputHashFile` unless empty.
+func writeOutputHash(outputHashFile, digest string) error {
+	if len(outputHashFile) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(outputHashFile, []byte(digest+"\n"), 0666); err != nil {
+		return fmt.Errorf("Unable to write output hash to %q: %w\n", outputHashFile, err)
+	}
+	return nil
+}
+
+// watchNotice calls `regenerate` and then calls it again whenever any of the
+// `roots` or the files returned by the last successful call change, until
+// `ctx` is done.
+//
+// Waits until no further change arrives for `debounce` before calling
+// `regenerate` so that a burst of changes, e.g. an editor saving a file,
+// regenerates the notice once. Logs the errors from `regenerate` and from `w`
+// to `logger` and keeps watching.
+func watchNotice(ctx context.Context, w Watcher, logger *slog.Logger, debounce time.Duration, roots []string, regenerate func() ([]string, error)) error {
+	watched := make(map[string]bool)
+	// update watches the roots and `deps` and stops watching anything else.
+	// Adds every file again because editors often replace the file, which
+	// ends the watch.
+	update := func(deps []string) {
+		files := make(map[string]bool)
+		for _, f := range roots {
+			files[f] = true
+		}
+		for _, f := range deps {
+			files[f] = true
+		}
+		for f := range watched {
+			if !files[f] {
+				w.Remove(f)
+				delete(watched, f)
+			}
+		}
+		for f := range files {
+			if err := w.Add(f); err != nil {
+				logger.Error("cannot watch", "file", f, "error", err)
+				continue
+			}
+			watched[f] = true
+		}
+	}
+	var deps []string
+	run := func() {
+		newDeps, err := regenerate()
+		if err != nil {
+			// Keep watching the same files until the notice regenerates.
+			logger.Error(strings.TrimSpace(err.Error()))
+		} else {
+			deps = newDeps
+		}
+		update(deps)
+	}
+
+	run()
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case event, ok := <-w.Events():
+			if !ok {
+				return fmt.Errorf("Unable to watch files: watcher closed\n")
 			}
-			fmt.Fprintln(ctx.stdout)
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(debounce)
+		case err, ok := <-w.Errors():
+			if !ok {
+				return fmt.Errorf("Unable to watch files: watcher closed\n")
+			}
+			logger.Error("error watching files", "error", err)
+		case <-timer.C:
+			run()
 		}
-		ctx.stdout.Write(ni.HashText(h))
-		fmt.Fprintln(ctx.stdout)
 	}
+}
+
+// loadLicenseGraph returns the license graph written by graphcache to `path`.
+func loadLicenseGraph(path string) (*compliance.LicenseGraph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return compliance.UnmarshalGraph(data)
+}
+
+// productOutput returns the path in `outputDir` of the notice for `product`.
+func productOutput(bc *buildContext, outputDir, product string) string {
+	if bc.ort {
+		return filepath.Join(outputDir, product+".json")
+	}
+	if bc.markdown != nil {
+		return filepath.Join(outputDir, product+".md")
+	}
+	return filepath.Join(outputDir, product+".txt")
+}
+
+// multiProductNotice writes the notice for each product in `products`, which
+// maps the product names to their root files, to the productOutput in
+// `outputDir`.
+//
+// Reads the license graphs of the products in parallel and normalizes each
+// license text shared between products only once. The notice for each
+// product is identical to the textNotice output for its root files.
+func multiProductNotice(ctx context.Context, bc *buildContext, outputDir string, products map[string][]string) error {
+	// Must be at least one product.
+	if len(products) < 1 {
+		return failNoneRequested
+	}
+
+	names := make([]string, 0, len(products))
+	for name := range products {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	*ctx.deps = ni.InputFiles()
-	sort.Strings(*ctx.deps)
+	texts := newTextCache()
+	outputs := make([]bytes.Buffer, len(names))
+	deps := make([][]string, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		pc := *bc
+		pc.stdout = &outputs[i]
+		if bc.markdown != nil {
+			pc.markdown = &markdownWriter{&outputs[i]}
+		}
+		pc.product = name
+		pc.texts = texts
+		pc.deps = &deps[i]
+		wg.Add(1)
+		go func(i int, pc *buildContext) {
+			defer wg.Done()
+			_, errs[i] = textNotice(ctx, pc, products[names[i]]...)
+		}(i, &pc)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("product %q: %w", names[i], err)
+		}
+	}
+	for i, name := range names {
+		err := os.WriteFile(productOutput(bc, outputDir, name), outputs[i].Bytes(), 0666)
+		if err != nil {
+			return fmt.Errorf("Unable to write notice for product %q: %w\n", name, err)
+		}
+	}
+
+	// The deps file lists every file read for any product.
+	var allDeps []string
+	for _, productDeps := range deps {
+		allDeps = append(allDeps, productDeps...)
+	}
+	sort.Strings(allDeps)
+	*bc.deps = nil
+	for i, dep := range allDeps {
+		if i > 0 && dep == allDeps[i-1] {
+			continue
+		}
+		*bc.deps = append(*bc.deps, dep)
+	}
 
 	return nil
 }
+
+// noticeGroups returns the sections of the notice for `ni`, merging those
+// with texts identical apart from whitespace when bc.aggregateIdentical.
+func noticeGroups(bc *buildContext, ni *compliance.NoticeIndex) []compliance.NoticeGroup {
+	groups := ni.Groups()
+	if bc.aggregateIdentical {
+		groups = compliance.AggregateByText(groups)
+	}
+	return groups
+}
+
+// writeText writes the notice for `ni` as plain text with the libraries and
+// install paths using each license text followed by the text.
+//
+// Returns the statistics for the sections written.
+func writeText(bc *buildContext, ni *compliance.NoticeIndex) NoticeStats {
+	stats := NoticeStats{ConditionCounts: make(map[string]int)}
+	if len(bc.title) > 0 {
+		for _, title := range bc.title {
+			fmt.Fprintln(bc.stdout, title)
+		}
+		fmt.Fprintln(bc.stdout)
+	}
+	for _, group := range noticeGroups(bc, ni) {
+		fmt.Fprintln(bc.stdout, "==============================================================================")
+		for _, lib := range group.Libs {
+			fmt.Fprintf(bc.stdout, "%s used by:\n", bc.libName(ni, lib.Name))
+			for _, installPath := range lib.InstallPaths {
+				fmt.Fprintf(bc.stdout, "  %s\n", bc.strip(installPath))
+			}
+			fmt.Fprintln(bc.stdout)
+		}
+		io.WriteString(bc.stdout, bc.texts.normalize(group.Hash, group.Text))
+		fmt.Fprintln(bc.stdout)
+		stats.add(group)
+	}
+	return stats
+}
+
+// writeMarkdown writes the notice for `ni` as Markdown with a level 2 heading
+// for each library followed by the install paths using it and a fenced code
+// block for each license text.
+//
+// Returns the statistics for the sections written.
+func writeMarkdown(bc *buildContext, ni *compliance.NoticeIndex) NoticeStats {
+	stats := NoticeStats{ConditionCounts: make(map[string]int)}
+	for _, title := range bc.title {
+		bc.markdown.heading(1, title)
+	}
+	for _, group := range noticeGroups(bc, ni) {
+		for _, lib := range group.Libs {
+			bc.markdown.heading(2, bc.libName(ni, lib.Name))
+			bc.markdown.paragraph("Used by:")
+			var installPaths []string
+			for _, installPath := range lib.InstallPaths {
+				installPaths = append(installPaths, bc.strip(installPath))
+			}
+			bc.markdown.codeBullets(installPaths)
+		}
+		bc.markdown.codeBlock(bc.texts.normalize(group.Hash, group.Text))
+		stats.add(group)
+	}
+	return stats
+}
+
+// ortModel is the subset of the OSS Review Toolkit (ORT) evaluated model that
+// -format ort writes for the ORT reporter to render. Packages and findings
+// refer to licenses by their index in Licenses.
+type ortModel struct {
+	Licenses       []ortLicense  `json:"licenses"`
+	Packages       []ortPackage  `json:"packages"`
+	Copyrights     []interface{} `json:"copyrights"`
+	Issues         []interface{} `json:"issues"`
+	RuleViolations []interface{} `json:"rule_violations"`
+	ScopeNames     []string      `json:"scope_names"`
+	Labels         struct{}      `json:"labels"`
+}
+
+// ortLicense is a license referenced by the packages of an ortModel.
+type ortLicense struct {
+	Index int    `json:"_id"`
+	ID    string `json:"id"`
+}
+
+// ortPackage is an ORT project, for a root license metadata file, or package,
+// for a library in the notice.
+type ortPackage struct {
+	Index int `json:"_id"`
+	// ID is the ORT identifier "type:namespace:name:version".
+	ID                        string               `json:"id"`
+	IsProject                 bool                 `json:"is_project"`
+	DefinitionFilePath        string               `json:"definition_file_path,omitempty"`
+	DeclaredLicenses          []int                `json:"declared_licenses"`
+	DeclaredLicensesProcessed ortProcessedLicenses `json:"declared_licenses_processed"`
+	DetectedLicenses          []int                `json:"detected_licenses"`
+	Curations                 []ortCuratedPackage  `json:"curations"`
+	Findings                  []ortLicenseFinding  `json:"findings"`
+	IsExcluded                bool                 `json:"is_excluded"`
+}
+
+// ortProcessedLicenses maps the declared licenses of an ortPackage to SPDX.
+type ortProcessedLicenses struct {
+	SpdxExpression   string `json:"spdx_expression,omitempty"`
+	MappedLicenses   []int  `json:"mapped_licenses"`
+	UnmappedLicenses []int  `json:"unmapped_licenses"`
+}
+
+// ortCuratedPackage records a change to the metadata of an ortPackage, i.e.
+// the library name and version taken from the METADATA of the project.
+type ortCuratedPackage struct {
+	Base     map[string]string `json:"base"`
+	Curation map[string]string `json:"curation"`
+}
+
+// ortLicenseFinding locates a license text used by an ortPackage.
+type ortLicenseFinding struct {
+	Type      string `json:"type"`
+	License   int    `json:"license"`
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// ortIdentifier returns the ORT identifier of the Android library `name` at
+// `version`, escaping the colons that separate the parts.
+func ortIdentifier(name, version string) string {
+	escape := strings.NewReplacer(":", "%3A")
+	return "Android::" + escape.Replace(name) + ":" + escape.Replace(version)
+}
+
+// writeORT writes the notice for `ni` and the root files `files` as an ORT
+// evaluated model with a project for each root file and a package for each
+// library. Each license text of a library becomes a license finding for the
+// SPDX expression of its license kinds, or NOASSERTION when none map to SPDX.
+//
+// Returns the statistics for the sections written.
+func writeORT(bc *buildContext, ni *compliance.NoticeIndex, files []string) (NoticeStats, error) {
+	stats := NoticeStats{ConditionCounts: make(map[string]int)}
+	model := ortModel{
+		Licenses:       []ortLicense{},
+		Packages:       []ortPackage{},
+		Copyrights:     []interface{}{},
+		Issues:         []interface{}{},
+		RuleViolations: []interface{}{},
+		ScopeNames:     []string{},
+	}
+	licenses := make(map[string]int)
+	// license returns the index of the license `id` adding it when new.
+	license := func(id string) int {
+		if i, ok := licenses[id]; ok {
+			return i
+		}
+		i := len(model.Licenses)
+		licenses[id] = i
+		model.Licenses = append(model.Licenses, ortLicense{i, id})
+		return i
+	}
+	newPackage := func(id string) ortPackage {
+		return ortPackage{
+			Index:            len(model.Packages),
+			ID:               id,
+			DeclaredLicenses: []int{},
+			DeclaredLicensesProcessed: ortProcessedLicenses{
+				MappedLicenses:   []int{},
+				UnmappedLicenses: []int{},
+			},
+			DetectedLicenses: []int{},
+			Curations:        []ortCuratedPackage{},
+			Findings:         []ortLicenseFinding{},
+		}
+	}
+
+	roots := append([]string{}, files...)
+	sort.Strings(roots)
+	for _, root := range roots {
+		p := newPackage(ortIdentifier(strings.TrimSuffix(filepath.Base(root), ".meta_lic"), ""))
+		p.IsProject = true
+		p.DefinitionFilePath = root
+		model.Packages = append(model.Packages, p)
+	}
+
+	for _, libName := range ni.AllLibraries() {
+		// Only versions all the METADATA agree on; conflicts get no version.
+		version := ni.Version(libName)
+		var curation *ortCuratedPackage
+		if len(version) > 0 {
+			pms, err := ni.LibProjectMetadata(libName)
+			if err != nil {
+				return stats, fmt.Errorf("Unable to read project metadata for %q: %w\n", libName, err)
+			}
+			for _, pm := range pms {
+				if pm.Version() == version {
+					curation = &ortCuratedPackage{
+						Base:     map[string]string{"id": ortIdentifier(libName, "")},
+						Curation: map[string]string{"comment": "Version from " + pm.Project() + "/METADATA"},
+					}
+					break
+				}
+			}
+		}
+		p := newPackage(ortIdentifier(libName, version))
+		if curation != nil {
+			p.Curations = append(p.Curations, *curation)
+		}
+
+		var kinds []string
+		detected := make(map[int]struct{})
+		for _, h := range ni.LibHashes(libName) {
+			hashKinds := ni.HashLibLicenseKinds(h, libName)
+			kinds = append(kinds, hashKinds...)
+			expression, _ := compliance.SpdxExpression(hashKinds)
+			if len(expression) == 0 {
+				// ORT marks licenses it cannot identify as NOASSERTION.
+				expression = "NOASSERTION"
+			}
+			i := license(expression)
+			detected[i] = struct{}{}
+			for _, file := range ni.HashFiles(h) {
+				lines := bytes.Count(ni.TextContent(h), []byte("\n"))
+				if lines == 0 {
+					lines = 1
+				}
+				p.Findings = append(p.Findings, ortLicenseFinding{"LICENSE", i, file, 1, lines})
+			}
+		}
+		sort.Strings(kinds)
+		kinds = slices.Compact(kinds)
+		for _, kind := range kinds {
+			p.DeclaredLicenses = append(p.DeclaredLicenses, license(kind))
+			if e, _ := compliance.SpdxExpression([]string{kind}); len(e) > 0 {
+				if i := license(e); !slices.Contains(p.DeclaredLicensesProcessed.MappedLicenses, i) {
+					p.DeclaredLicensesProcessed.MappedLicenses = append(p.DeclaredLicensesProcessed.MappedLicenses, i)
+				}
+			} else {
+				p.DeclaredLicensesProcessed.UnmappedLicenses = append(p.DeclaredLicensesProcessed.UnmappedLicenses, license(kind))
+			}
+		}
+		p.DeclaredLicensesProcessed.SpdxExpression, _ = compliance.SpdxExpression(kinds)
+		for i := range detected {
+			p.DetectedLicenses = append(p.DetectedLicenses, i)
+		}
+		sort.Ints(p.DetectedLicenses)
+		model.Packages = append(model.Packages, p)
+	}
+
+	for _, group := range noticeGroups(bc, ni) {
+		stats.add(group)
+	}
+
+	data, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return stats, fmt.Errorf("Unable to write ORT evaluated model: %w\n", err)
+	}
+	fmt.Fprintln(bc.stdout, string(data))
+	return stats, nil
+}
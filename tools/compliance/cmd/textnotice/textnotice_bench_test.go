@@ -0,0 +1,245 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance"
+)
+
+// benchmarkSizes maps the benchmark names to the number of nodes in their
+// fixture graphs.
+var benchmarkSizes = []struct {
+	name  string
+	nodes int
+}{
+	{"BenchmarkTextNoticeSmall", 10},
+	{"BenchmarkTextNoticeMedium", 100},
+	{"BenchmarkTextNoticeLarge", 1000},
+}
+
+// benchmarkConditions lists the license conditions assigned round-robin to the
+// fixture libraries with notice conditions the most common.
+var benchmarkConditions = []string{"notice", "notice", "notice", "unencumbered", "reciprocal", "notice", "restricted_if_statically_linked", "notice"}
+
+// benchmarkFixture returns a synthetic license graph of `nodes` targets
+// resembling a system image: a root container installing the binaries, each
+// binary linking a few libraries statically or dynamically, and libraries
+// linking lower libraries in a tree. About a fifth of the libraries share license texts
+// with others the way common licenses do.
+func benchmarkFixture(nodes int) (fstest.MapFS, string) {
+	fs := make(fstest.MapFS)
+	binaries := nodes / 10
+	if binaries < 1 {
+		binaries = 1
+	}
+	libraries := nodes - binaries - 1
+
+	text := func(i int) string {
+		if i%5 == 0 {
+			return "licenses/COMMON_LICENSE"
+		}
+		return fmt.Sprintf("licenses/LICENSE%d", i)
+	}
+	fs["licenses/COMMON_LICENSE"] = &fstest.MapFile{Data: []byte("Copyright (C) The Common Project Authors\n" + strings.Repeat("Permission is hereby granted, free of charge.\n", 20))}
+
+	dep := func(sb *strings.Builder, lib, n int) {
+		annotation := "static"
+		if n%3 == 0 {
+			annotation = "dynamic"
+		}
+		fmt.Fprintf(sb, "deps: {\n  file: \"lib/lib%d.so.meta_lic\"\n  annotations: %q\n}\n", lib, annotation)
+	}
+
+	for i := 0; i < libraries; i++ {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "package_name: \"lib%d\"\n", i)
+		fmt.Fprintf(&sb, "license_kinds: \"SPDX-license-identifier-Apache-2.0\"\n")
+		fmt.Fprintf(&sb, "license_conditions: %q\n", benchmarkConditions[i%len(benchmarkConditions)])
+		fmt.Fprintf(&sb, "license_texts: %q\n", text(i))
+		fmt.Fprintf(&sb, "installed: \"out/target/product/fictional/system/lib/lib%d.so\"\n", i)
+		// Libraries link the lower libraries i/2 and (i-1)/2 so that the
+		// graph is acyclic and reaches every library in the lower half.
+		if i > 0 {
+			dep(&sb, i/2, i)
+		}
+		if i > 2 && (i-1)/2 != i/2 {
+			dep(&sb, (i-1)/2, i+1)
+		}
+		fs[fmt.Sprintf("lib/lib%d.so.meta_lic", i)] = &fstest.MapFile{Data: []byte(sb.String())}
+		if i%5 != 0 {
+			fs[text(i)] = &fstest.MapFile{Data: []byte(fmt.Sprintf("Copyright (C) 2026 Library %d\n%s", i, strings.Repeat("Redistribution and use in source and binary forms are permitted.\n", 20)))}
+		}
+	}
+
+	var root strings.Builder
+	root.WriteString("package_name: \"Android\"\nlicense_kinds: \"SPDX-license-identifier-Apache-2.0\"\nlicense_conditions: \"notice\"\nlicense_texts: \"licenses/COMMON_LICENSE\"\nis_container: true\ninstalled: \"out/target/product/fictional/system.img\"\n")
+	for i := 0; i < binaries; i++ {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "package_name: \"bin%d\"\n", i)
+		fmt.Fprintf(&sb, "license_kinds: \"SPDX-license-identifier-Apache-2.0\"\nlicense_conditions: \"notice\"\nlicense_texts: \"licenses/COMMON_LICENSE\"\n")
+		fmt.Fprintf(&sb, "installed: \"out/target/product/fictional/system/bin/bin%d\"\n", i)
+		// Binaries link the upper half of the libraries round-robin.
+		for lib := libraries / 2; lib < libraries; lib++ {
+			if lib%binaries == i {
+				dep(&sb, lib, lib)
+			}
+		}
+		fs[fmt.Sprintf("bin/bin%d.meta_lic", i)] = &fstest.MapFile{Data: []byte(sb.String())}
+		fmt.Fprintf(&root, "deps: {\n  file: \"bin/bin%d.meta_lic\"\n  annotations: \"static\"\n}\n", i)
+	}
+	fs["system.img.meta_lic"] = &fstest.MapFile{Data: []byte(root.String())}
+	return fs, "system.img.meta_lic"
+}
+
+// benchmarkTextNotice benchmarks the fixture graph with `nodes` targets:
+//
+//	pipeline: the whole textNotice pipeline discarding the output
+//	graph: only reading the license graph
+//	render: only indexing the license texts and writing the notice for a
+//	    graph already read and resolved
+func benchmarkTextNotice(b *testing.B, nodes int) {
+	rootFS, root := benchmarkFixture(nodes)
+
+	b.Run("pipeline", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var deps []string
+
+			bc := buildContext{stdout: io.Discard, stderr: io.Discard, rootFS: rootFS, deps: &deps}
+
+			if _, err := textNotice(context.Background(), &bc, root); err != nil {
+				b.Fatalf("textnotice: error = %v", err)
+			}
+		}
+	})
+	b.Run("graph", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := compliance.ReadLicenseGraph(rootFS, io.Discard, []string{root}); err != nil {
+				b.Fatalf("ReadLicenseGraph: error = %v", err)
+			}
+		}
+	})
+	b.Run("render", func(b *testing.B) {
+		lg, err := compliance.ReadLicenseGraph(rootFS, io.Discard, []string{root})
+		if err != nil {
+			b.Fatalf("ReadLicenseGraph: error = %v", err)
+		}
+		rs := compliance.ResolveNotices(lg)
+		var deps []string
+
+		bc := buildContext{stdout: io.Discard, stderr: io.Discard, rootFS: rootFS, deps: &deps}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ni, err := compliance.IndexLicenseTexts(rootFS, lg, rs)
+			if err != nil {
+				b.Fatalf("IndexLicenseTexts: error = %v", err)
+			}
+			writeText(&bc, ni)
+		}
+	})
+}
+
+func BenchmarkTextNoticeSmall(b *testing.B) {
+	benchmarkTextNotice(b, 10)
+}
+
+func BenchmarkTextNoticeMedium(b *testing.B) {
+	benchmarkTextNotice(b, 100)
+}
+
+func BenchmarkTextNoticeLarge(b *testing.B) {
+	benchmarkTextNotice(b, 1000)
+}
+
+// TestBenchmarkFixture checks the fixture graphs have the advertised sizes
+// and produce a notice.
+func TestBenchmarkFixture(t *testing.T) {
+	for _, size := range benchmarkSizes {
+		rootFS, root := benchmarkFixture(size.nodes)
+		lg, err := compliance.ReadLicenseGraph(rootFS, io.Discard, []string{root})
+		if err != nil {
+			t.Fatalf("%s: ReadLicenseGraph: error = %v", size.name, err)
+		}
+		if n := len(lg.Targets()); n != size.nodes {
+			t.Errorf("%s: got %d nodes, want %d", size.name, n, size.nodes)
+		}
+
+		stdout := &bytes.Buffer{}
+		var deps []string
+
+		bc := buildContext{stdout: stdout, stderr: io.Discard, rootFS: rootFS, deps: &deps}
+
+		if _, err := textNotice(context.Background(), &bc, root); err != nil {
+			t.Fatalf("%s: textnotice: error = %v", size.name, err)
+		}
+		if stdout.Len() == 0 {
+			t.Errorf("%s: got no output", size.name)
+		}
+	}
+}
+
+// TestBenchmarkBaseline checks that testdata/benchmarks/baseline.txt records
+// a result for every benchmark so the baseline gets updated with the suite.
+func TestBenchmarkBaseline(t *testing.T) {
+	f, err := os.Open("testdata/benchmarks/baseline.txt")
+	if err != nil {
+		t.Fatalf("cannot read baseline: %v", err)
+	}
+	defer f.Close()
+
+	recorded := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if len(fields) < 4 || fields[3] != "ns/op" {
+			t.Errorf("baseline: malformed result line %q", scanner.Text())
+		}
+		// Strip any -GOMAXPROCS suffix. e.g. BenchmarkTextNoticeSmall/graph-8
+		name := fields[0]
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+		recorded[name] = true
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("cannot read baseline: %v", err)
+	}
+	for _, size := range benchmarkSizes {
+		for _, sub := range []string{"pipeline", "graph", "render"} {
+			if name := size.name + "/" + sub; !recorded[name] {
+				t.Errorf("baseline: missing %s; run `make bench-baseline` in tools/compliance", name)
+			}
+		}
+	}
+}
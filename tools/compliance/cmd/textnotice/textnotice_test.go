@@ -15,20 +15,43 @@
 package main
 
 import (
+	"archive/zip"
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/fixturegen"
+	"android/soong/tools/compliance/goldentest"
+	"android/soong/tools/compliance/testutil"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
 )
 
 var (
 	horizontalRule = regexp.MustCompile("^===[=]*===$")
+
+	// fixtures holds the content of every file under testdata.
+	fixtures map[string]string
 )
 
 func TestMain(m *testing.M) {
@@ -38,18 +61,35 @@ func TestMain(m *testing.M) {
 		fmt.Printf("failed to change to testdata directory: %s\n", err)
 		os.Exit(1)
 	}
+	var err error
+	fixtures, err = testutil.ReadFiles("testdata")
+	if err != nil {
+		fmt.Printf("failed to read testdata directory: %s\n", err)
+		os.Exit(1)
+	}
 	os.Exit(m.Run())
 }
 
+// fixtureFS returns an in-memory copy of the testdata fixtures so the tests
+// read nothing from disk.
+func fixtureFS() fs.FS {
+	return testutil.NewMemFS(fixtures)
+}
+
 func Test(t *testing.T) {
 	tests := []struct {
-		condition    string
-		name         string
-		outDir       string
-		roots        []string
-		stripPrefix  string
-		expectedOut  []matcher
-		expectedDeps []string
+		condition      string
+		name           string
+		roots          []string
+		stripPrefix    string
+		title          []string
+		mergeSimilar   float64
+		useSpdxTexts   bool
+		skipBuildtime  bool
+		copyleftStatic bool
+		expectedStderr []string
+		expectedOut    []matcher
+		expectedDeps   []string
 	}{
 		{
 			condition: "firstparty",
@@ -76,6 +116,34 @@ func Test(t *testing.T) {
 				"testdata/firstparty/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "firstparty",
+			name:      "apex-with-stacked-title",
+			roots:     []string{"highest.apex.meta_lic"},
+			title:     []string{"Licenses", "Emperor"},
+			expectedOut: []matcher{
+				pageTitle{"Licenses"},
+				pageTitle{"Emperor"},
+				hr{},
+				library{"Android"},
+				usedBy{"highest.apex"},
+				usedBy{"highest.apex/bin/bin1"},
+				usedBy{"highest.apex/bin/bin2"},
+				usedBy{"highest.apex/lib/liba.so"},
+				usedBy{"highest.apex/lib/libb.so"},
+				firstParty{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/firstparty/bin/bin1.meta_lic",
+				"testdata/firstparty/bin/bin2.meta_lic",
+				"testdata/firstparty/highest.apex.meta_lic",
+				"testdata/firstparty/lib/liba.so.meta_lic",
+				"testdata/firstparty/lib/libb.so.meta_lic",
+				"testdata/firstparty/lib/libc.a.meta_lic",
+				"testdata/firstparty/lib/libd.so.meta_lic",
+			},
+		},
 		{
 			condition: "firstparty",
 			name:      "container",
@@ -477,6 +545,44 @@ func Test(t *testing.T) {
 				"testdata/restricted/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition:    "restricted",
+			name:         "container+merge_similar",
+			roots:        []string{"container.zip.meta_lic"},
+			mergeSimilar: 0.5,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"container.zip"},
+				usedBy{"container.zip/bin1"},
+				usedBy{"container.zip/bin2"},
+				firstParty{},
+				hr{},
+				library{"Android"},
+				usedBy{"container.zip/bin2"},
+				usedBy{"container.zip/libb.so"},
+				library{"Device"},
+				usedBy{"container.zip/bin1"},
+				usedBy{"container.zip/liba.so"},
+				restricted{},
+				hr{},
+				library{"External"},
+				usedBy{"container.zip/bin1"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/restricted/RESTRICTED_LICENSE",
+				"testdata/restricted/bin/bin1.meta_lic",
+				"testdata/restricted/bin/bin2.meta_lic",
+				"testdata/restricted/container.zip.meta_lic",
+				"testdata/restricted/lib/liba.so.meta_lic",
+				"testdata/restricted/lib/libb.so.meta_lic",
+				"testdata/restricted/lib/libc.a.meta_lic",
+				"testdata/restricted/lib/libd.so.meta_lic",
+			},
+		},
 		{
 			condition: "restricted",
 			name:      "application",
@@ -680,6 +786,144 @@ func Test(t *testing.T) {
 				"testdata/proprietary/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "regressspdx",
+			name:      "bin",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []matcher{
+				hr{},
+				library{"Gadget"},
+				usedBy{"bin1"},
+				notice{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regressspdx/bin/bin1.meta_lic",
+				"testdata/regressspdx/lib/liba.a.meta_lic",
+			},
+		},
+		{
+			condition:    "regressspdx",
+			name:         "bin+use_spdx_texts",
+			roots:        []string{"bin/bin1.meta_lic"},
+			useSpdxTexts: true,
+			expectedOut: append([]matcher{
+				hr{},
+				library{"Gadget"},
+				usedBy{"bin1"},
+				notice{},
+				hr{},
+				library{"Widget"},
+				usedBy{"bin1"},
+			}, spdxText("MIT")...),
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regressspdx/bin/bin1.meta_lic",
+				"testdata/regressspdx/lib/liba.a.meta_lic",
+			},
+		},
+		{
+			condition: "regressbuildtime",
+			name:      "bin",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"bin1"},
+				library{"Runtime"},
+				usedBy{"bin1"},
+				notice{},
+				hr{},
+				library{"Generator"},
+				usedBy{"bin1"},
+				restricted{},
+				hr{},
+				library{"Harness"},
+				usedBy{"bin1"},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/regressbuildtime/bin/bin1.meta_lic",
+				"testdata/regressbuildtime/bin/gen.meta_lic",
+				"testdata/regressbuildtime/lib/liba.a.meta_lic",
+				"testdata/regressbuildtime/lib/libharness.a.meta_lic",
+				"testdata/restricted/RESTRICTED_LICENSE",
+			},
+		},
+		{
+			condition:     "regressbuildtime",
+			name:          "bin+skip_buildtime",
+			roots:         []string{"bin/bin1.meta_lic"},
+			skipBuildtime: true,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"bin1"},
+				library{"Runtime"},
+				usedBy{"bin1"},
+				notice{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regressbuildtime/bin/bin1.meta_lic",
+				"testdata/regressbuildtime/bin/gen.meta_lic",
+				"testdata/regressbuildtime/lib/liba.a.meta_lic",
+				"testdata/regressbuildtime/lib/libharness.a.meta_lic",
+			},
+		},
+		{
+			condition: "regresslinkage",
+			name:      "bin",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"bin1"},
+				notice{},
+				hr{},
+				library{"Static"},
+				usedBy{"bin1"},
+				restricted{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regresslinkage/bin/bin1.meta_lic",
+				"testdata/regresslinkage/lib/libdynamic.so.meta_lic",
+				"testdata/regresslinkage/lib/libshared.so.meta_lic",
+				"testdata/regresslinkage/lib/libstatic.a.meta_lic",
+				"testdata/restricted/RESTRICTED_LICENSE",
+			},
+		},
+		{
+			condition:      "regresslinkage",
+			name:           "bin+copyleft_static_only",
+			roots:          []string{"bin/bin1.meta_lic"},
+			copyleftStatic: true,
+			expectedOut: []matcher{
+				hr{},
+				library{"Android"},
+				usedBy{"bin1"},
+				notice{},
+				hr{},
+				library{"Static"},
+				usedBy{"bin1"},
+				restricted{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regresslinkage/bin/bin1.meta_lic",
+				"testdata/regresslinkage/lib/libdynamic.so.meta_lic",
+				"testdata/regresslinkage/lib/libshared.so.meta_lic",
+				"testdata/regresslinkage/lib/libstatic.a.meta_lic",
+				"testdata/restricted/RESTRICTED_LICENSE",
+			},
+			expectedStderr: []string{
+				`level=WARN msg="dynamically links restricted" target=testdata/regresslinkage/bin/bin1.meta_lic dependency=testdata/regresslinkage/lib/libdynamic.so.meta_lic`,
+				`level=WARN msg="dynamically links restricted" target=testdata/regresslinkage/bin/bin1.meta_lic dependency=testdata/regresslinkage/lib/libshared.so.meta_lic`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
@@ -693,17 +937,36 @@ func Test(t *testing.T) {
 
 			var deps []string
 
-			ctx := context{stdout, stderr, compliance.GetFS(tt.outDir), "", []string{tt.stripPrefix}, "", &deps}
+			bc := buildContext{
+				stdout:         stdout,
+				stderr:         stderr,
+				rootFS:         fixtureFS(),
+				stripPrefix:    []string{tt.stripPrefix},
+				title:          tt.title,
+				mergeSimilar:   tt.mergeSimilar,
+				useSpdxTexts:   tt.useSpdxTexts,
+				skipBuildtime:  tt.skipBuildtime,
+				copyleftStatic: tt.copyleftStatic,
+				deps:           &deps,
+			}
 
-			err := textNotice(&ctx, rootFiles...)
+			_, err := textNotice(context.Background(), &bc, rootFiles...)
 			if err != nil {
 				t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
 				return
 			}
-			if stderr.Len() > 0 {
+			if len(tt.expectedStderr) > 0 {
+				if expected := strings.Join(tt.expectedStderr, "\n") + "\n"; stderr.String() != expected {
+					t.Errorf("textnotice: gotStderr = %v, want %v", stderr, expected)
+				}
+			} else if stderr.Len() > 0 {
 				t.Errorf("textnotice: gotStderr = %v, want none", stderr)
 			}
 
+			goldentest.CheckGolden(t, "textnotice/"+tt.condition+"_"+tt.name+".txt", stdout.String())
+
+			// The matchers check the structure of the output independent of
+			// the golden file.
 			t.Logf("got stdout: %s", stdout.String())
 
 			t.Logf("want stdout: %s", matcherList(tt.expectedOut).String())
@@ -738,108 +1001,1983 @@ func Test(t *testing.T) {
 	}
 }
 
-type matcher interface {
-	isMatch(line string) bool
-	String() string
-}
+func TestRootsFile(t *testing.T) {
+	roots := []string{
+		"testdata/notice/bin/bin1.meta_lic",
+		"testdata/reciprocal/application.meta_lic",
+		"testdata/restricted/lib/liba.so.meta_lic",
+	}
 
-type hr struct{}
+	rootsFile := filepath.Join(t.TempDir(), "roots.txt")
+	content := "# notice roots\n" +
+		roots[0] + "\n" +
+		"\n" +
+		"  " + roots[1] + "\t\n" +
+		"   # indented comment\n" +
+		roots[2]
+	if err := os.WriteFile(rootsFile, []byte(content), 0666); err != nil {
+		t.Fatalf("cannot write roots file: %v", err)
+	}
 
-func (m hr) isMatch(line string) bool {
-	return horizontalRule.MatchString(line)
-}
+	actual, err := readRootsFile(rootsFile)
+	if err != nil {
+		t.Fatalf("readRootsFile(): got error %v, want no error", err)
+	}
+	if !reflect.DeepEqual(actual, roots) {
+		t.Fatalf("readRootsFile(): got %q, want %q", actual, roots)
+	}
 
-func (m hr) String() string {
-	return " ================================================== "
-}
+	// notice returns the output and deps of textnotice for `files`.
+	notice := func(files []string) (string, []string) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
 
-type library struct {
-	name string
-}
+		var deps []string
 
-func (m library) isMatch(line string) bool {
-	return strings.HasPrefix(line, m.name+" ")
-}
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), stripPrefix: []string{"out/target/product/fictional/"}, deps: &deps}
 
-func (m library) String() string {
-	return m.name + " used by:"
-}
+		_, err := textNotice(context.Background(), &bc, files...)
+		if err != nil {
+			t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+		}
+		return stdout.String(), deps
+	}
+	expectedOut, expectedDeps := notice(roots)
+	actualOut, actualDeps := notice(actual)
+	if actualOut != expectedOut {
+		t.Errorf("textnotice: got output %q from roots file, want %q", actualOut, expectedOut)
+	}
+	if !reflect.DeepEqual(actualDeps, expectedDeps) {
+		t.Errorf("textnotice: got deps %q from roots file, want %q", actualDeps, expectedDeps)
+	}
 
-type usedBy struct {
-	name string
+	if _, err := readRootsFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("readRootsFile(missing): got no error, want error")
+	}
 }
 
-func (m usedBy) isMatch(line string) bool {
-	return len(line) > 0 && line[0] == ' ' && strings.HasPrefix(strings.TrimLeft(line, " "), "out/") && strings.HasSuffix(line, "/"+m.name)
-}
+func TestLoadGraph(t *testing.T) {
+	tests := []struct {
+		condition string
+		roots     []string
+	}{
+		{"firstparty", []string{"highest.apex.meta_lic"}},
+		{"notice", []string{"container.zip.meta_lic"}},
+		{"reciprocal", []string{"application.meta_lic"}},
+		{"restricted", []string{"container.zip.meta_lic", "highest.apex.meta_lic"}},
+		{"proprietary", []string{"bin/bin2.meta_lic", "lib/libb.so.meta_lic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			rootFiles := make([]string, 0, len(tt.roots))
+			for _, r := range tt.roots {
+				rootFiles = append(rootFiles, "testdata/"+tt.condition+"/"+r)
+			}
 
-func (m usedBy) String() string {
-	return "  out/.../" + m.name
-}
+			// notice returns the output and deps of textnotice for `files`
+			// or for the graph at `loadGraph`.
+			notice := func(loadGraph string, files ...string) (string, []string) {
+				stdout := &bytes.Buffer{}
+				stderr := &bytes.Buffer{}
 
-type firstParty struct{}
+				var deps []string
 
-func (m firstParty) isMatch(line string) bool {
-	return strings.HasPrefix(strings.TrimLeft(line, " "), "&&&First Party License&&&")
-}
+				bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), stripPrefix: []string{"out/target/product/fictional/"}, loadGraph: loadGraph, deps: &deps}
 
-func (m firstParty) String() string {
-	return "&&&First Party License&&&"
-}
+				_, err := textNotice(context.Background(), &bc, files...)
+				if err != nil {
+					t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+				}
+				return stdout.String(), deps
+			}
 
-type notice struct{}
+			lg, err := compliance.ReadLicenseGraph(fixtureFS(), io.Discard, rootFiles)
+			if err != nil {
+				t.Fatalf("cannot read license graph: %v", err)
+			}
+			data, err := compliance.MarshalGraph(lg)
+			if err != nil {
+				t.Fatalf("cannot serialize license graph: %v", err)
+			}
+			graphFile := filepath.Join(t.TempDir(), "graph.pb")
+			if err := os.WriteFile(graphFile, data, 0666); err != nil {
+				t.Fatalf("cannot write license graph: %v", err)
+			}
 
-func (m notice) isMatch(line string) bool {
-	return strings.HasPrefix(strings.TrimLeft(line, " "), "%%%Notice License%%%")
+			expectedOut, _ := notice("", rootFiles...)
+			actualOut, actualDeps := notice(graphFile)
+			if actualOut != expectedOut {
+				t.Errorf("textnotice: got output %q from -load_graph, want %q", actualOut, expectedOut)
+			}
+			for _, dep := range actualDeps {
+				if strings.HasSuffix(dep, ".meta_lic") {
+					t.Errorf("textnotice: got deps %q from -load_graph, want no license metadata", actualDeps)
+					break
+				}
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var deps []string
+		bc := buildContext{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, rootFS: fixtureFS(), loadGraph: filepath.Join(t.TempDir(), "missing.pb"), deps: &deps}
+		_, err := textNotice(context.Background(), &bc)
+		if err == nil || !strings.Contains(err.Error(), "Unable to load license graph") {
+			t.Errorf("textnotice: got error %v, want \"Unable to load license graph\"", err)
+		}
+	})
 }
 
-func (m notice) String() string {
-	return "%%%Notice License%%%"
+func TestOutputHashFile(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "Android"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin1"
+deps: {
+  file: "lib/liba.so.meta_lic"
+  annotations: "dynamic"
 }
+`)},
+		"lib/liba.so.meta_lic": {Data: []byte(`package_name: "liba"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/lib/liba.so"
+`)},
+		"LICENSE": {Data: []byte("%%%Notice License%%%\n")},
+	}
+	dir := t.TempDir()
 
-type reciprocal struct{}
+	// notice runs textnotice writing the output and the hash to `hashFile`
+	// and returns the hash file content and the output.
+	notice := func(hashFile string) (string, []byte) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
 
-func (m reciprocal) isMatch(line string) bool {
-	return strings.HasPrefix(strings.TrimLeft(line, " "), "$$$Reciprocal License$$$")
-}
+		var deps []string
 
-func (m reciprocal) String() string {
-	return "$$$Reciprocal License$$$"
-}
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, outputHashFile: filepath.Join(dir, hashFile), deps: &deps}
 
-type restricted struct{}
+		digest, err := textNotice(context.Background(), &bc, "bin/bin1.meta_lic")
+		if err != nil {
+			t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+		}
+		err = writeOutput(filepath.Join(dir, "NOTICE.txt"), stdout.Bytes(), bc.outputHashFile, digest)
+		if err != nil {
+			t.Fatalf("textnotice: cannot write output: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, hashFile))
+		if err != nil {
+			t.Fatalf("textnotice: cannot read hash file: %v", err)
+		}
+		return string(data), stdout.Bytes()
+	}
 
-func (m restricted) isMatch(line string) bool {
-	return strings.HasPrefix(strings.TrimLeft(line, " "), "###Restricted License###")
-}
+	first, out := notice("first.sha256")
+	if expected := fmt.Sprintf("%x\n", sha256.Sum256(out)); first != expected {
+		t.Errorf("textnotice: got hash %q, want %q of the output", first, expected)
+	}
+	second, _ := notice("second.sha256")
+	if second != first {
+		t.Errorf("textnotice: got hash %q for identical inputs, want %q", second, first)
+	}
 
-func (m restricted) String() string {
-	return "###Restricted License###"
+	rootFS["LICENSE"] = &fstest.MapFile{Data: []byte("%%%Changed Notice License%%%\n")}
+	changed, _ := notice("changed.sha256")
+	if changed == first {
+		t.Errorf("textnotice: got hash %q after changing a license text, want a different hash", changed)
+	}
 }
 
-type proprietary struct{}
+func TestOutputHashFileOutputError(t *testing.T) {
+	dir := t.TempDir()
+	outputFile := filepath.Join(dir, "missing", "NOTICE.txt")
+	hashFile := filepath.Join(dir, "NOTICE.txt.sha256")
 
-func (m proprietary) isMatch(line string) bool {
-	return strings.HasPrefix(strings.TrimLeft(line, " "), "@@@Proprietary License@@@")
+	err := writeOutput(outputFile, []byte("notice\n"), hashFile, fmt.Sprintf("%x", sha256.Sum256([]byte("notice\n"))))
+	if err == nil || !strings.Contains(err.Error(), outputFile) {
+		t.Errorf("textnotice: got error %v, want error naming %q", err, outputFile)
+	}
+	if _, err := os.Stat(hashFile); !os.IsNotExist(err) {
+		t.Errorf("textnotice: got hash file %q for output that could not be written, want none", hashFile)
+	}
 }
 
-func (m proprietary) String() string {
-	return "@@@Proprietary License@@@"
-}
+func TestMultiProductNotice(t *testing.T) {
+	products := map[string][]string{
+		"fictional": {"testdata/notice/highest.apex.meta_lic"},
+		"container": {"testdata/reciprocal/container.zip.meta_lic"},
+		"lib":       {"testdata/restricted/lib/liba.so.meta_lic", "testdata/notice/application.meta_lic"},
+	}
 
-type matcherList []matcher
+	// notice returns the textnotice output for `product` and its `roots`.
+	notice := func(product string, markdown bool, roots ...string) ([]byte, []string) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
 
-func (l matcherList) String() string {
-	var sb strings.Builder
-	for _, m := range l {
-		s := m.String()
-		if s[:3] == s[len(s)-3:] {
-			fmt.Fprintln(&sb)
+		var deps []string
+
+		var mw *markdownWriter
+		if markdown {
+			mw = &markdownWriter{stdout}
 		}
-		fmt.Fprintf(&sb, "%s\n", s)
-		if s[:3] == s[len(s)-3:] {
-			fmt.Fprintln(&sb)
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), product: product, stripPrefix: []string{"out/target/product/fictional/"}, title: []string{"Notices"}, markdown: mw, deps: &deps}
+
+		_, err := textNotice(context.Background(), &bc, roots...)
+		if err != nil {
+			t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
 		}
+		return stdout.Bytes(), deps
+	}
+
+	for _, markdown := range []bool{false, true} {
+		for _, tt := range []struct {
+			name     string
+			products []string
+		}{
+			{"single", []string{"fictional"}},
+			{"several", []string{"fictional", "container", "lib"}},
+		} {
+			t.Run(fmt.Sprintf("%s markdown=%t", tt.name, markdown), func(t *testing.T) {
+				stdout := &bytes.Buffer{}
+				stderr := &bytes.Buffer{}
+
+				var deps []string
+
+				var mw *markdownWriter
+				if markdown {
+					mw = &markdownWriter{stdout}
+				}
+				bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), stripPrefix: []string{"out/target/product/fictional/"}, title: []string{"Notices"}, markdown: mw, deps: &deps}
+
+				requested := make(map[string][]string)
+				for _, product := range tt.products {
+					requested[product] = products[product]
+				}
+				dir := t.TempDir()
+				err := multiProductNotice(context.Background(), &bc, dir, requested)
+				if err != nil {
+					t.Fatalf("multiProductNotice: error = %v, stderr = %v", err, stderr)
+				}
+				if stdout.Len() > 0 {
+					t.Errorf("multiProductNotice: got output on stdout %q, want none", stdout.String())
+				}
+
+				expectedDeps := make(map[string]bool)
+				for _, product := range tt.products {
+					expected, productDeps := notice(product, markdown, products[product]...)
+					actual, err := os.ReadFile(productOutput(&bc, dir, product))
+					if err != nil {
+						t.Fatalf("multiProductNotice: cannot read notice for %q: %v", product, err)
+					}
+					if !bytes.Equal(actual, expected) {
+						t.Errorf("multiProductNotice: got notice for %q:\n%s\nwant textnotice output:\n%s", product, actual, expected)
+					}
+					for _, dep := range productDeps {
+						expectedDeps[dep] = true
+					}
+				}
+				if len(deps) != len(expectedDeps) {
+					t.Errorf("multiProductNotice: got %d deps %v, want %d", len(deps), deps, len(expectedDeps))
+				}
+				for i, dep := range deps {
+					if !expectedDeps[dep] {
+						t.Errorf("multiProductNotice: got unexpected dep %q", dep)
+					}
+					if i > 0 && deps[i-1] >= dep {
+						t.Errorf("multiProductNotice: got unsorted or duplicate deps %q, %q", deps[i-1], dep)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestMultiProductNoticeError(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), deps: &deps}
+
+	dir := t.TempDir()
+	err := multiProductNotice(context.Background(), &bc, dir, map[string][]string{
+		"fictional": {"testdata/notice/highest.apex.meta_lic"},
+		"missing":   {"testdata/notice/missing.meta_lic"},
+	})
+	if err == nil {
+		t.Fatalf("multiProductNotice: got no error for a missing root file, want error")
+	}
+	if !strings.Contains(err.Error(), `product "missing"`) {
+		t.Errorf("multiProductNotice: got error %q, want error naming product \"missing\"", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("cannot read output directory: %v", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("multiProductNotice: got %d output files after an error, want none", len(entries))
+	}
+}
+
+// BenchmarkMultiProductNotice compares one multiProductNotice run against a
+// textNotice run per product for 10 products sharing 80% of their libraries.
+func BenchmarkMultiProductNotice(b *testing.B) {
+	const products = 10
+	const libsPerProduct = 500
+	const sharedLibs = libsPerProduct * 8 / 10
+
+	// Use a real directory because fstest.MapFS takes time proportional to
+	// the number of files to look up a missing file.
+	dir := b.TempDir()
+	for _, d := range []string{"lib", "licenses"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0777); err != nil {
+			b.Fatalf("cannot create test data: %v", err)
+		}
+	}
+	writeLib := func(i int) string {
+		name := fmt.Sprintf("lib/lib%d.so.meta_lic", i)
+		metadata := fmt.Sprintf("package_name: \"lib%d\"\nlicense_conditions: \"notice\"\nlicense_texts: \"licenses/LICENSE%d\"\ninstalled: \"out/target/product/fictional/system/lib/lib%d.so\"\n", i, i, i)
+		text := fmt.Sprintf("Copyright (C) 2026 Library %d\n%s", i, strings.Repeat("Permission is hereby granted, free of charge.\n", 20))
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(metadata), 0666); err != nil {
+			b.Fatalf("cannot create test data: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("licenses/LICENSE%d", i)), []byte(text), 0666); err != nil {
+			b.Fatalf("cannot create test data: %v", err)
+		}
+		return name
+	}
+	var shared []string
+	for i := 0; i < sharedLibs; i++ {
+		shared = append(shared, writeLib(i))
+	}
+	roots := make(map[string][]string)
+	next := sharedLibs
+	for p := 0; p < products; p++ {
+		productRoots := append([]string{}, shared...)
+		for i := sharedLibs; i < libsPerProduct; i++ {
+			productRoots = append(productRoots, writeLib(next))
+			next++
+		}
+		roots[fmt.Sprintf("product%d", p)] = productRoots
+	}
+	rootFS := os.DirFS(dir)
+
+	b.Run("separate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for product, productRoots := range roots {
+				var deps []string
+
+				bc := buildContext{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, rootFS: rootFS, product: product, deps: &deps}
+
+				if _, err := textNotice(context.Background(), &bc, productRoots...); err != nil {
+					b.Fatalf("textnotice: error = %v", err)
+				}
+			}
+		}
+	})
+	b.Run("multi", func(b *testing.B) {
+		b.ReportAllocs()
+		outputDir := b.TempDir()
+		for i := 0; i < b.N; i++ {
+			var deps []string
+
+			bc := buildContext{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, rootFS: rootFS, deps: &deps}
+
+			if err := multiProductNotice(context.Background(), &bc, outputDir, roots); err != nil {
+				b.Fatalf("multiProductNotice: error = %v", err)
+			}
+		}
+	})
+}
+
+func TestAggregateIdentical(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "Android"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin1"
+deps: {
+  file: "lib/liba.so.meta_lic"
+  annotations: "static"
+}
+deps: {
+  file: "lib/libb.so.meta_lic"
+  annotations: "static"
+}
+`)},
+		"lib/liba.so.meta_lic": {Data: []byte(`package_name: "liba"
+license_conditions: "notice"
+license_texts: "LICENSE_A"
+installed: "out/target/product/fictional/system/lib/liba.so"
+`)},
+		"lib/libb.so.meta_lic": {Data: []byte(`package_name: "libb"
+license_conditions: "notice"
+license_texts: "LICENSE_B"
+installed: "out/target/product/fictional/system/lib/libb.so"
+`)},
+		"LICENSE":   {Data: []byte("%%%Android License%%%\n")},
+		"LICENSE_A": {Data: []byte("%%%Shared License%%%\nAll rights reserved.\n")},
+		"LICENSE_B": {Data: []byte("%%%Shared   License%%%\n  All rights\nreserved.")},
+	}
+
+	// sections returns the libraries in each section of the textnotice
+	// output with or without -aggregate_identical.
+	sections := func(aggregate bool) [][]string {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		var deps []string
+
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, stripPrefix: []string{"out/target/product/fictional/"}, aggregateIdentical: aggregate, deps: &deps}
+
+		_, err := textNotice(context.Background(), &bc, "bin/bin1.meta_lic")
+		if err != nil {
+			t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+		}
+		var result [][]string
+		for _, line := range strings.Split(stdout.String(), "\n") {
+			if horizontalRule.MatchString(line) {
+				result = append(result, []string{})
+			} else if strings.HasSuffix(line, " used by:") {
+				result[len(result)-1] = append(result[len(result)-1], strings.TrimSuffix(line, " used by:"))
+			}
+		}
+		return result
+	}
+
+	if actual, expected := sections(false), [][]string{{"Android"}, {"liba"}, {"libb"}}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("textnotice: got sections %v, want %v", actual, expected)
+	}
+	if actual, expected := sections(true), [][]string{{"Android"}, {"liba", "libb"}}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("textnotice: got sections %v with -aggregate_identical, want %v", actual, expected)
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		condition        string
+		expectedSections int
+		expectedCounts   map[string]int
+	}{
+		{"firstparty", 1, map[string]int{"notice": 1}},
+		{"notice", 2, map[string]int{"notice": 2}},
+		{"reciprocal", 2, map[string]int{"reciprocal": 1, "notice": 1}},
+		{"restricted", 3, map[string]int{"restricted": 2, "restricted_if_statically_linked": 1}},
+		{"proprietary", 3, map[string]int{"by_exception_only": 1, "restricted": 1, "notice": 1}},
+	}
+	for _, tt := range tests {
+		for _, markdown := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s markdown=%t", tt.condition, markdown), func(t *testing.T) {
+				stdout := &bytes.Buffer{}
+				stderr := &bytes.Buffer{}
+
+				var deps []string
+
+				var mw *markdownWriter
+				if markdown {
+					mw = &markdownWriter{stdout}
+				}
+				bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), stripPrefix: []string{"out/target/product/fictional/"}, markdown: mw, logJSON: true, stats: true, deps: &deps}
+
+				_, err := textNotice(context.Background(), &bc, "testdata/"+tt.condition+"/highest.apex.meta_lic")
+				if err != nil {
+					t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+				}
+
+				var record struct {
+					Msg        string
+					Sections   int
+					Conditions map[string]int
+					Bytes      int
+				}
+				if err := json.Unmarshal(stderr.Bytes(), &record); err != nil || record.Msg != "notice stats" {
+					t.Fatalf("textnotice: got stderr %q, want a notice stats record", stderr)
+				}
+				if record.Sections != tt.expectedSections {
+					t.Errorf("textnotice: got %d sections, want %d", record.Sections, tt.expectedSections)
+				}
+				if !reflect.DeepEqual(record.Conditions, tt.expectedCounts) {
+					t.Errorf("textnotice: got condition counts %v, want %v", record.Conditions, tt.expectedCounts)
+				}
+				if record.Bytes != stdout.Len() {
+					t.Errorf("textnotice: got size %d, want %d", record.Bytes, stdout.Len())
+				}
+			})
+		}
+	}
+}
+
+func TestNoticeStatsString(t *testing.T) {
+	stats := NoticeStats{42, 131000, map[string]int{"notice": 12, "restricted": 3, "unencumbered": 27}}
+	if actual, expected := stats.String(), "42 sections, 3 restricted, 12 notice, 27 unencumbered, 128 KiB"; actual != expected {
+		t.Errorf("NoticeStats: got %q, want %q", actual, expected)
+	}
+}
+
+func TestJSONLogging(t *testing.T) {
+	tests := []struct {
+		name     string
+		logLevel slog.Level
+		expected []map[string]string
+	}{
+		{
+			name:     "warn",
+			logLevel: slog.LevelInfo,
+			expected: []map[string]string{
+				{"level": "WARN", "msg": "dynamically links restricted", "target": "testdata/regresslinkage/bin/bin1.meta_lic", "dependency": "testdata/regresslinkage/lib/libdynamic.so.meta_lic"},
+				{"level": "WARN", "msg": "dynamically links restricted", "target": "testdata/regresslinkage/bin/bin1.meta_lic", "dependency": "testdata/regresslinkage/lib/libshared.so.meta_lic"},
+			},
+		},
+		{
+			name:     "error",
+			logLevel: slog.LevelError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			var deps []string
+
+			bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), copyleftStatic: true, logLevel: tt.logLevel, logJSON: true, deps: &deps}
+
+			_, err := textNotice(context.Background(), &bc, "testdata/regresslinkage/bin/bin1.meta_lic")
+			if err != nil {
+				t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+			}
+
+			var actual []map[string]string
+			for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+				if len(line) == 0 {
+					continue
+				}
+				var record map[string]string
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("textnotice: cannot unmarshal log line %q: %v", line, err)
+				}
+				actual = append(actual, record)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("textnotice: got log records %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJSONLoggingStats(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), copyleftStatic: true, logJSON: true, stats: true, deps: &deps}
+
+	_, err := textNotice(context.Background(), &bc, "testdata/regresslinkage/bin/bin1.meta_lic")
+	if err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("textnotice: got stderr %q, want warnings and stats", stderr)
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("textnotice: got stderr line %q, want JSON", line)
+		}
+	}
+}
+
+func TestMissingLicenseFiles(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "Android"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin1"
+deps: {
+  file: "lib/liba.so.meta_lic"
+  annotations: "dynamic"
+}
+deps: {
+  file: "lib/libb.so.meta_lic"
+  annotations: "dynamic"
+}
+`)},
+		"lib/liba.so.meta_lic": {Data: []byte(`package_name: "liba"
+license_conditions: "notice"
+license_texts: "external/liba/LICENSE:liba"
+installed: "out/target/product/fictional/system/lib/liba.so"
+`)},
+		"lib/libb.so.meta_lic": {Data: []byte(`package_name: "libb"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/lib/libb.so"
+`)},
+		"LICENSE": {Data: []byte("%%%Notice License%%%\n")},
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, deps: &deps}
+
+	_, err := textNotice(context.Background(), &bc, "bin/bin1.meta_lic")
+	var mfe *compliance.MissingFilesError
+	if !errors.As(err, &mfe) {
+		t.Fatalf("textnotice: got error %v, want *compliance.MissingFilesError", err)
+	}
+	if expected := []string{"external/liba/LICENSE"}; !reflect.DeepEqual(mfe.Files, expected) {
+		t.Errorf("textnotice: got missing files %q, want %q", mfe.Files, expected)
+	}
+	if !strings.Contains(err.Error(), "external/liba/LICENSE") {
+		t.Errorf("textnotice: got error %q, want error naming %q", err.Error(), "external/liba/LICENSE")
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("textnotice: got partial output %q, want none", stdout)
+	}
+}
+
+func TestFixtureErrors(t *testing.T) {
+	// without returns a copy of the fixtures without the file `name`.
+	without := func(name string) map[string]string {
+		files := make(map[string]string)
+		for k, v := range fixtures {
+			if k != name {
+				files[k] = v
+			}
+		}
+		return files
+	}
+
+	tests := []struct {
+		name          string
+		rootFS        fs.FS
+		expectedErr   error
+		expectMissing []string
+	}{
+		{
+			name:          "missing license text",
+			rootFS:        testutil.NewMemFS(without("testdata/notice/NOTICE_LICENSE")),
+			expectMissing: []string{"testdata/notice/NOTICE_LICENSE"},
+		},
+		{
+			name:        "missing metadata",
+			rootFS:      testutil.NewMemFS(without("testdata/notice/lib/liba.so.meta_lic")),
+			expectedErr: fs.ErrNotExist,
+		},
+		{
+			name:        "unreadable license text",
+			rootFS:      testutil.WithErrors(fixtureFS(), map[string]error{"testdata/notice/NOTICE_LICENSE": fs.ErrPermission}),
+			expectedErr: fs.ErrPermission,
+		},
+		{
+			name:        "unreadable metadata",
+			rootFS:      testutil.WithErrors(fixtureFS(), map[string]error{"testdata/notice/lib/liba.so.meta_lic": fs.ErrPermission}),
+			expectedErr: fs.ErrPermission,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			var deps []string
+			bc := buildContext{stdout: stdout, stderr: stderr, rootFS: tt.rootFS, deps: &deps}
+
+			_, err := textNotice(context.Background(), &bc, "testdata/notice/highest.apex.meta_lic")
+			if err == nil {
+				t.Fatalf("textnotice: got no error, want error")
+			}
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("textnotice: got error %v, want %v", err, tt.expectedErr)
+			}
+			var mfe *compliance.MissingFilesError
+			if errors.As(err, &mfe) != (len(tt.expectMissing) > 0) {
+				t.Errorf("textnotice: got error %v, want missing files %q", err, tt.expectMissing)
+			} else if mfe != nil && !reflect.DeepEqual(mfe.Files, tt.expectMissing) {
+				t.Errorf("textnotice: got missing files %q, want %q", mfe.Files, tt.expectMissing)
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("textnotice: got partial output %q, want none", stdout)
+			}
+		})
+	}
+}
+
+func TestGeneratedFixture(t *testing.T) {
+	const nodes = 10
+	files, err := fixturegen.Generate(fixturegen.Options{
+		Nodes:      nodes,
+		Conditions: []string{"notice", "reciprocal", "restricted", "proprietary"},
+		Depth:      3,
+		Seed:       1,
+	})
+	if err != nil {
+		t.Fatalf("cannot generate fixture: %v", err)
+	}
+	rootFS := make(fstest.MapFS)
+	for name, data := range files {
+		rootFS[name] = &fstest.MapFile{Data: data}
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	var deps []string
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, deps: &deps}
+	if _, err := textNotice(context.Background(), &bc, fixturegen.Root); err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+
+	// Collect the install paths listed under each "used by:" line.
+	usedBy := make(map[string]struct{})
+	inUsedBy := false
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		switch {
+		case strings.HasSuffix(line, " used by:"):
+			inUsedBy = true
+		case inUsedBy && strings.HasPrefix(line, "  "):
+			usedBy[strings.TrimSpace(line)] = struct{}{}
+		default:
+			inUsedBy = false
+		}
+	}
+	if len(usedBy) != nodes {
+		t.Errorf("textnotice: got %d distinct used by entries, want %d:\n%s", len(usedBy), nodes, stdout.String())
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		roots []string
+		title []string
+	}{
+		{
+			name:  "notice",
+			roots: []string{"testdata/notice/bin/bin1.meta_lic"},
+		},
+		{
+			name:  "container",
+			roots: []string{"testdata/notice/highest.apex.meta_lic"},
+			title: []string{"Product *Notices*"},
+		},
+		{
+			name:  "reciprocal",
+			roots: []string{"testdata/reciprocal/bin/bin1.meta_lic", "testdata/reciprocal/bin/bin2.meta_lic"},
+		},
+		{
+			name:  "escaping",
+			roots: []string{"testdata/regressmarkdown/bin/bin1.meta_lic"},
+			title: []string{"# [Title](x) `1`"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// notice returns the output of textnotice in plain text or
+			// Markdown.
+			notice := func(markdown bool) string {
+				stdout := &bytes.Buffer{}
+				stderr := &bytes.Buffer{}
+
+				var deps []string
+
+				var mw *markdownWriter
+				if markdown {
+					mw = &markdownWriter{stdout}
+				}
+				bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), stripPrefix: []string{"out/target/product/fictional/"}, title: tt.title, markdown: mw, deps: &deps}
+
+				_, err := textNotice(context.Background(), &bc, tt.roots...)
+				if err != nil {
+					t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+				}
+				return stdout.String()
+			}
+
+			// The Markdown must hold the same headings, install paths, and
+			// license texts as the plain text notice.
+			var expected []markdownBlock
+			for _, title := range tt.title {
+				expected = append(expected, markdownBlock{"heading", 1, title})
+			}
+			sections := regexp.MustCompile("(?m)^===[=]*===$").Split(strings.TrimPrefix(notice(false), strings.Join(tt.title, "\n")+"\n\n"), -1)
+			for _, section := range sections[1:] {
+				lines := strings.Split(strings.TrimPrefix(section, "\n"), "\n")
+				for len(lines) > 0 && strings.HasSuffix(lines[0], " used by:") {
+					expected = append(expected, markdownBlock{"heading", 2, strings.TrimSuffix(lines[0], " used by:")})
+					expected = append(expected, markdownBlock{"paragraph", 0, "Used by:"})
+					lines = lines[1:]
+					for len(lines[0]) > 0 {
+						expected = append(expected, markdownBlock{"bullet", 0, strings.TrimPrefix(lines[0], "  ")})
+						lines = lines[1:]
+					}
+					lines = lines[1:]
+				}
+				expected = append(expected, markdownBlock{"code", 0, strings.TrimRight(strings.Join(lines, "\n"), "\n")})
+			}
+			if len(expected) == len(tt.title) {
+				t.Fatalf("textnotice: got no sections in plain text notice")
+			}
+
+			output := notice(true)
+			t.Logf("got markdown: %s", output)
+			actual, err := parseMarkdown(output)
+			if err != nil {
+				t.Fatalf("textnotice: invalid markdown: %v", err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("textnotice: got markdown blocks:\n%v\nwant:\n%v", actual, expected)
+			}
+		})
+	}
+}
+
+// markdownBlock describes a block-level element of a Markdown document.
+type markdownBlock struct {
+	kind  string
+	level int
+	text  string
+}
+
+func (b markdownBlock) String() string {
+	return fmt.Sprintf("%s(%d): %q\n", b.kind, b.level, b.text)
+}
+
+var (
+	markdownFence   = regexp.MustCompile("^(```+)$")
+	markdownHeading = regexp.MustCompile("^(#{1,6}) (.*)$")
+	markdownBullet  = regexp.MustCompile("^- (`+) (.*) (`+)$")
+)
+
+// parseMarkdown parses the subset of Markdown textnotice writes into blocks
+// with inline escapes and code spans resolved, returning an error for any
+// unescaped inline markup.
+func parseMarkdown(text string) ([]markdownBlock, error) {
+	var blocks []markdownBlock
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if len(line) == 0 {
+			continue
+		}
+		if groups := markdownFence.FindStringSubmatch(line); groups != nil {
+			// A fence closes at a line of at least as many backticks.
+			var code []string
+			for i++; ; i++ {
+				if i >= len(lines) {
+					return nil, fmt.Errorf("unterminated code block")
+				}
+				if strings.HasPrefix(lines[i], groups[1]) && strings.Trim(lines[i], "`") == "" {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, markdownBlock{"code", 0, strings.TrimRight(strings.Join(code, "\n"), "\n")})
+			continue
+		}
+		if groups := markdownHeading.FindStringSubmatch(line); groups != nil {
+			inline, err := parseInline(groups[2])
+			if err != nil {
+				return nil, fmt.Errorf("heading %q: %w", line, err)
+			}
+			blocks = append(blocks, markdownBlock{"heading", len(groups[1]), inline})
+			continue
+		}
+		if groups := markdownBullet.FindStringSubmatch(line); groups != nil {
+			// The code span must close with the opening backticks and
+			// cannot contain that run.
+			if groups[1] != groups[3] || strings.Contains(groups[2], groups[1]) {
+				return nil, fmt.Errorf("bullet %q: malformed code span", line)
+			}
+			blocks = append(blocks, markdownBlock{"bullet", 0, groups[2]})
+			continue
+		}
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "#") {
+			return nil, fmt.Errorf("line %q: unexpected block markup", line)
+		}
+		inline, err := parseInline(line)
+		if err != nil {
+			return nil, fmt.Errorf("paragraph %q: %w", line, err)
+		}
+		blocks = append(blocks, markdownBlock{"paragraph", 0, inline})
+	}
+	return blocks, nil
+}
+
+// parseInline resolves the backslash escapes in `text` and returns an error
+// for any unescaped character with special meaning.
+func parseInline(text string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\\' {
+			i++
+			if i >= len(text) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			sb.WriteByte(text[i])
+			continue
+		}
+		if strings.IndexByte("`*_[]<>&|~", c) >= 0 {
+			return "", fmt.Errorf("unescaped %q", c)
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+func TestMetricsFile(t *testing.T) {
+	root := "testdata/proprietary/highest.apex.meta_lic"
+
+	lg, err := compliance.ReadLicenseGraph(fixtureFS(), &bytes.Buffer{}, []string{root})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+
+	metricsFile := filepath.Join(t.TempDir(), "metrics.prom")
+	f, err := os.Create(metricsFile)
+	if err != nil {
+		t.Fatalf("cannot create metrics file: %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: fixtureFS(), metrics: f, deps: &deps}
+
+	_, err = textNotice(context.Background(), &bc, root)
+	f.Close()
+	if err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+
+	data, err := os.ReadFile(metricsFile)
+	if err != nil {
+		t.Fatalf("cannot read metrics file: %v", err)
+	}
+
+	// values maps each metric name to its type and value.
+	values := make(map[string][2]string)
+	types := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "# TYPE "):
+			if len(fields) != 4 {
+				t.Fatalf("textnotice: got malformed TYPE line %q", line)
+			}
+			types[fields[2]] = fields[3]
+		case strings.HasPrefix(line, "# HELP "):
+		default:
+			if len(fields) != 2 {
+				t.Fatalf("textnotice: got malformed sample line %q", line)
+			}
+			if _, ok := types[fields[0]]; !ok {
+				t.Errorf("textnotice: got sample %q before its TYPE line", fields[0])
+			}
+			values[fields[0]] = [2]string{types[fields[0]], fields[1]}
+		}
+	}
+
+	expected := map[string][2]string{
+		"compliance_graph_nodes_total": {"counter", fmt.Sprint(len(lg.Targets()))},
+		"compliance_graph_edges_total": {"counter", fmt.Sprint(len(lg.Edges()))},
+		"compliance_violations_total":  {"counter", fmt.Sprint(len(compliance.ConflictingSharedPrivateSource(lg)))},
+	}
+	for name, want := range expected {
+		if got, ok := values[name]; !ok {
+			t.Errorf("textnotice: got no metric %q in %q, want %v", name, data, want)
+		} else if got != want {
+			t.Errorf("textnotice: got metric %q = %v, want %v", name, got, want)
+		}
+	}
+	for _, name := range []string{"compliance_parse_duration_seconds", "compliance_cache_hit_ratio"} {
+		if got, ok := values[name]; !ok {
+			t.Errorf("textnotice: got no metric %q in %q, want gauge", name, data)
+		} else if got[0] != "gauge" {
+			t.Errorf("textnotice: got metric %q of type %q, want gauge", name, got[0])
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	tests := []struct {
+		name     string
+		cancelAt string
+	}{
+		{
+			name:     "before",
+			cancelAt: "",
+		},
+		{
+			name:     "metadata",
+			cancelAt: "testdata/notice/lib/liba.so.meta_lic",
+		},
+		{
+			name:     "licensetext",
+			cancelAt: "testdata/notice/NOTICE_LICENSE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if len(tt.cancelAt) == 0 {
+				cancel()
+			}
+			rootFS := cancelingFS{fixtureFS(), tt.cancelAt, cancel}
+
+			var deps []string
+
+			bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, deps: &deps}
+
+			_, err := textNotice(ctx, &bc, "testdata/notice/highest.apex.meta_lic")
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("textnotice: got error %v, want %v", err, context.Canceled)
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("textnotice: got output %q after cancel, want none", stdout)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rootFS := slowFS{fixtureFS(), 100 * time.Millisecond}
+
+	var deps []string
+
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, deps: &deps}
+
+	start := time.Now()
+	_, err := textNotice(ctx, &bc, "testdata/notice/highest.apex.meta_lic")
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("textnotice: got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	// Reading every file would take at least 100ms per file.
+	if elapsed >= 100*time.Millisecond {
+		t.Errorf("textnotice: got timeout after %s, want before the first read finishes", elapsed)
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("textnotice: got output %q after timeout, want none", stdout)
+	}
+}
+
+// slowFS delays opening every file by `delay`.
+type slowFS struct {
+	fs.FS
+	delay time.Duration
+}
+
+func (s slowFS) Open(name string) (fs.File, error) {
+	time.Sleep(s.delay)
+	return s.FS.Open(name)
+}
+
+// cancelingFS calls `cancel` upon opening the file `name`.
+type cancelingFS struct {
+	fs.FS
+	name   string
+	cancel context.CancelFunc
+}
+
+func (c cancelingFS) Open(name string) (fs.File, error) {
+	if name == c.name {
+		c.cancel()
+	}
+	return c.FS.Open(name)
+}
+
+type matcher interface {
+	isMatch(line string) bool
+	String() string
+}
+
+type pageTitle struct {
+	t string
+}
+
+func (m pageTitle) isMatch(line string) bool {
+	return line == m.t
+}
+
+func (m pageTitle) String() string {
+	return m.t
+}
+
+type licenseLine struct {
+	l string
+}
+
+func (m licenseLine) isMatch(line string) bool {
+	return strings.TrimSpace(line) == strings.TrimSpace(m.l)
+}
+
+func (m licenseLine) String() string {
+	return m.l
+}
+
+// spdxText returns matchers for the non-blank lines of the embedded SPDX
+// license text for `spdxID`.
+func spdxText(spdxID string) []matcher {
+	text, err := compliance.FetchLicenseText(spdxID, nil)
+	if err != nil {
+		panic(err)
+	}
+	var result []matcher
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			result = append(result, licenseLine{line})
+		}
+	}
+	return result
+}
+
+type hr struct{}
+
+func (m hr) isMatch(line string) bool {
+	return horizontalRule.MatchString(line)
+}
+
+func (m hr) String() string {
+	return " ================================================== "
+}
+
+type library struct {
+	name string
+}
+
+func (m library) isMatch(line string) bool {
+	return strings.HasPrefix(line, m.name+" ")
+}
+
+func (m library) String() string {
+	return m.name + " used by:"
+}
+
+type usedBy struct {
+	name string
+}
+
+func (m usedBy) isMatch(line string) bool {
+	return len(line) > 0 && line[0] == ' ' && strings.HasPrefix(strings.TrimLeft(line, " "), "out/") && strings.HasSuffix(line, "/"+m.name)
+}
+
+func (m usedBy) String() string {
+	return "  out/.../" + m.name
+}
+
+type firstParty struct{}
+
+func (m firstParty) isMatch(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "&&&First Party License&&&")
+}
+
+func (m firstParty) String() string {
+	return "&&&First Party License&&&"
+}
+
+type notice struct{}
+
+func (m notice) isMatch(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "%%%Notice License%%%")
+}
+
+func (m notice) String() string {
+	return "%%%Notice License%%%"
+}
+
+type reciprocal struct{}
+
+func (m reciprocal) isMatch(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "$$$Reciprocal License$$$")
+}
+
+func (m reciprocal) String() string {
+	return "$$$Reciprocal License$$$"
+}
+
+type restricted struct{}
+
+func (m restricted) isMatch(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "###Restricted License###")
+}
+
+func (m restricted) String() string {
+	return "###Restricted License###"
+}
+
+type proprietary struct{}
+
+func (m proprietary) isMatch(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "@@@Proprietary License@@@")
+}
+
+func (m proprietary) String() string {
+	return "@@@Proprietary License@@@"
+}
+
+type matcherList []matcher
+
+func (l matcherList) String() string {
+	var sb strings.Builder
+	for _, m := range l {
+		s := m.String()
+		if s[:3] == s[len(s)-3:] {
+			fmt.Fprintln(&sb)
+		}
+		fmt.Fprintf(&sb, "%s\n", s)
+		if s[:3] == s[len(s)-3:] {
+			fmt.Fprintln(&sb)
+		}
+	}
+	return sb.String()
+}
+
+// fakeWatcher implements Watcher for events sent by the test.
+type fakeWatcher struct {
+	mu      sync.Mutex
+	watched map[string]bool
+	events  chan fsnotify.Event
+	errors  chan error
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{watched: make(map[string]bool), events: make(chan fsnotify.Event), errors: make(chan error)}
+}
+
+func (w *fakeWatcher) Add(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watched[name] = true
+	return nil
+}
+
+func (w *fakeWatcher) Remove(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.watched, name)
+	return nil
+}
+
+func (w *fakeWatcher) Events() <-chan fsnotify.Event { return w.events }
+func (w *fakeWatcher) Errors() <-chan error          { return w.errors }
+func (w *fakeWatcher) Close() error                  { return nil }
+
+// files returns the sorted names of the watched files.
+func (w *fakeWatcher) files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var files []string
+	for f := range w.watched {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestWatchNotice(t *testing.T) {
+	const debounce = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := newFakeWatcher()
+	stderr := &bytes.Buffer{}
+
+	runs := make(chan int, 10)
+	results := []struct {
+		deps []string
+		err  error
+	}{
+		{[]string{"bin.meta_lic", "lib.meta_lic", "NOTICE"}, nil},
+		{nil, fmt.Errorf("Unable to read license metadata file(s) %q: bad\n", []string{"bin.meta_lic"})},
+		{[]string{"bin.meta_lic", "NOTICE"}, nil},
+	}
+	count := 0
+	regenerate := func() ([]string, error) {
+		r := results[count]
+		count++
+		runs <- count
+		return r.deps, r.err
+	}
+	done := make(chan error)
+	go func() {
+		done <- watchNotice(ctx, w, buildContext{stderr: stderr, logJSON: true}.logger(), debounce, []string{"bin.meta_lic"}, regenerate)
+	}()
+
+	// expectRun waits for run `n` and checks no other run follows.
+	expectRun := func(n int, expectedFiles []string) {
+		t.Helper()
+		select {
+		case actual := <-runs:
+			if actual != n {
+				t.Fatalf("watchNotice: got run %d, want run %d", actual, n)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("watchNotice: got no run, want run %d", n)
+		}
+		select {
+		case actual := <-runs:
+			t.Fatalf("watchNotice: got extra run %d after run %d, want one run", actual, n)
+		case <-time.After(4 * debounce):
+		}
+		if actual := w.files(); !reflect.DeepEqual(actual, expectedFiles) {
+			t.Errorf("watchNotice: got watched files %q after run %d, want %q", actual, n, expectedFiles)
+		}
+	}
+	expectRun(1, []string{"NOTICE", "bin.meta_lic", "lib.meta_lic"})
+
+	// A chmod alone does not regenerate.
+	w.events <- fsnotify.Event{Name: "NOTICE", Op: fsnotify.Chmod}
+	select {
+	case actual := <-runs:
+		t.Fatalf("watchNotice: got run %d after chmod, want none", actual)
+	case <-time.After(4 * debounce):
+	}
+
+	// A burst of changes regenerates once; the failure keeps the files.
+	for i := 0; i < 5; i++ {
+		w.events <- fsnotify.Event{Name: "lib.meta_lic", Op: fsnotify.Write}
+	}
+	expectRun(2, []string{"NOTICE", "bin.meta_lic", "lib.meta_lic"})
+	if !strings.Contains(stderr.String(), "bad") {
+		t.Errorf("watchNotice: got stderr %q, want the error from the failed run", stderr.String())
+	}
+
+	// The next change regenerates and stops watching files no longer read.
+	w.errors <- fmt.Errorf("queue overflow")
+	w.events <- fsnotify.Event{Name: "bin.meta_lic", Op: fsnotify.Rename}
+	expectRun(3, []string{"NOTICE", "bin.meta_lic"})
+	if !strings.Contains(stderr.String(), "queue overflow") {
+		t.Errorf("watchNotice: got stderr %q, want the watcher error", stderr.String())
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watchNotice: got error %s after cancel, want no error", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("watchNotice: got stderr line %q, want JSON", line)
+		}
+	}
+}
+
+func TestWriteNotice(t *testing.T) {
+	for _, name := range []string{"NOTICE.txt", "NOTICE.txt.gz"} {
+		t.Run(name, func(t *testing.T) {
+			outputFile := filepath.Join(t.TempDir(), name)
+			bc := buildContext{stderr: &bytes.Buffer{}, rootFS: fixtureFS()}
+
+			deps, err := writeNotice(context.Background(), &bc, outputFile, "testdata/notice/application.meta_lic")
+			if err != nil {
+				t.Fatalf("writeNotice: got error %s, want no error", err)
+			}
+			if !slices.Contains(deps, "testdata/notice/application.meta_lic") || !slices.Contains(deps, "testdata/notice/NOTICE_LICENSE") {
+				t.Errorf("writeNotice: got deps %q, want the files read", deps)
+			}
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("writeNotice: cannot read output: %s", err)
+			}
+			if strings.HasSuffix(name, ".gz") {
+				r, err := gzip.NewReader(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("writeNotice: got output not compressed: %s", err)
+				}
+				data, _ = io.ReadAll(r)
+			}
+			if !strings.Contains(string(data), "used by:") {
+				t.Errorf("writeNotice: got output %q, want the notice", data)
+			}
+		})
+	}
+}
+
+func TestReportMissing(t *testing.T) {
+	for _, fatal := range []bool{false, true} {
+		t.Run(fmt.Sprintf("fatal=%t", fatal), func(t *testing.T) {
+			report := filepath.Join(t.TempDir(), "missing.tsv")
+			stdout := &bytes.Buffer{}
+			var deps []string
+			rootFS := fstest.MapFS{
+				"bin.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"bin\"\nlicense_conditions: \"notice\"\n" +
+					"license_texts: \"LICENSE\"\ninstalled: \"system/bin/bin\"\n" +
+					"deps: {\n  file: \"vendor.meta_lic\"\n  annotations: \"static\"\n}\n")},
+				"vendor.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"vendor\"\nlicense_conditions: \"proprietary\"\n")},
+				"LICENSE":         &fstest.MapFile{Data: []byte("Licensed.\n")},
+			}
+			bc := buildContext{stdout: stdout, stderr: &bytes.Buffer{}, rootFS: rootFS, missing: &compliance.MissingTextReport{File: report, Fatal: fatal}, deps: &deps}
+
+			_, err := textNotice(context.Background(), &bc, "bin.meta_lic")
+			if fatal {
+				if err == nil || !strings.Contains(err.Error(), "vendor.meta_lic") {
+					t.Errorf("textnotice: got error %v, want error naming vendor.meta_lic", err)
+				}
+				if stdout.Len() > 0 {
+					t.Errorf("textnotice: got output %q with missing texts, want none", stdout)
+				}
+			} else if err != nil {
+				t.Fatalf("textnotice: got error %s, want no error", err)
+			}
+
+			data, err := os.ReadFile(report)
+			if err != nil {
+				t.Fatalf("textnotice: cannot read report: %s", err)
+			}
+			expected := "# target\tconditions\tinstall paths\nvendor.meta_lic\tproprietary\tsystem/bin/bin\n"
+			if string(data) != expected {
+				t.Errorf("textnotice: got report %q, want %q", data, expected)
+			}
+		})
+	}
+}
+
+func TestNinjaDepsFile(t *testing.T) {
+	depsFile := filepath.Join(t.TempDir(), "NOTICE.txt.d")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	var deps []string
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), deps: &deps}
+
+	if _, err := textNotice(context.Background(), &bc, "testdata/notice/highest.apex.meta_lic"); err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+	if err := compliance.WriteDepsFile(depsFile, deps); err != nil {
+		t.Fatalf("WriteDepsFile: got error %s, want no error", err)
+	}
+	data, err := os.ReadFile(depsFile)
+	if err != nil {
+		t.Fatalf("WriteDepsFile: cannot read deps file: %s", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	if lines[0] != strings.TrimSuffix(depsFile, ".d")+": \\" {
+		t.Errorf("WriteDepsFile: got target line %q, want %q", lines[0], strings.TrimSuffix(depsFile, ".d")+": \\")
+	}
+	got := make(map[string]bool)
+	for _, line := range lines[1:] {
+		got[strings.TrimSpace(strings.TrimSuffix(line, "\\"))] = true
+	}
+	for _, f := range []string{
+		"testdata/notice/NOTICE_LICENSE",
+		"testdata/notice/bin/bin1.meta_lic",
+		"testdata/notice/bin/bin2.meta_lic",
+		"testdata/notice/highest.apex.meta_lic",
+		"testdata/notice/lib/liba.so.meta_lic",
+		"testdata/notice/lib/libb.so.meta_lic",
+		"testdata/notice/lib/libc.a.meta_lic",
+		"testdata/notice/lib/libd.so.meta_lic",
+	} {
+		if !got[f] {
+			t.Errorf("WriteDepsFile: got deps %q, want %q", data, f)
+		}
+	}
+}
+
+func TestPathTransform(t *testing.T) {
+	rewrite, err := compliance.RewritePrefixTransform([]string{"out/target/product/fictional/system/apex/=out/target/product/fictional/apex/"})
+	if err != nil {
+		t.Fatalf("RewritePrefixTransform: got error %s, want no error", err)
+	}
+	stripPrefix := []string{"out/target/product/fictional/"}
+	transform := compliance.ChainPathTransforms(rewrite, filterStripped([]string{"apex/highest.apex/bin"}, stripPrefix, ""))
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	var deps []string
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), stripPrefix: stripPrefix, pathTransform: transform, deps: &deps}
+
+	if _, err := textNotice(context.Background(), &bc, "testdata/notice/highest.apex.meta_lic"); err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+	var installPaths []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "  ") {
+			installPaths = append(installPaths, strings.TrimSpace(line))
+		}
+	}
+	if len(installPaths) == 0 {
+		t.Fatalf("textnotice: got no install paths in output:\n%s", stdout)
+	}
+	for _, installPath := range installPaths {
+		if !strings.HasPrefix(installPath, "apex/highest.apex/bin/") {
+			t.Errorf("textnotice: got install path %q, want only rewritten paths under apex/highest.apex/bin/", installPath)
+		}
+	}
+}
+
+func TestORT(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	var deps []string
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), ort: true, deps: &deps}
+
+	if _, err := textNotice(context.Background(), &bc, "testdata/notice/highest.apex.meta_lic"); err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+
+	// Check the structure independent of the types writing it.
+	var model map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &model); err != nil {
+		t.Fatalf("textnotice: got invalid JSON %q: %s", stdout, err)
+	}
+	for _, key := range []string{"licenses", "packages", "copyrights", "issues", "rule_violations", "scope_names"} {
+		if _, ok := model[key].([]interface{}); !ok {
+			t.Errorf("textnotice: got %s %v, want array", key, model[key])
+		}
+	}
+	licenses, _ := model["licenses"].([]interface{})
+	licenseIDs := make(map[float64]string)
+	for i, l := range licenses {
+		l := l.(map[string]interface{})
+		if l["_id"] != float64(i) {
+			t.Errorf("textnotice: got license _id %v at %d, want %d", l["_id"], i, i)
+		}
+		id, ok := l["id"].(string)
+		if !ok || len(id) == 0 {
+			t.Errorf("textnotice: got license id %v, want non-empty string", l["id"])
+		}
+		licenseIDs[float64(i)] = id
+	}
+	// checkRefs verifies `refs` refer to licenses.
+	checkRefs := func(pkg, field string, refs interface{}) {
+		list, ok := refs.([]interface{})
+		if !ok {
+			t.Errorf("textnotice: got %s %s %v, want array", pkg, field, refs)
+			return
+		}
+		for _, r := range list {
+			if _, ok := licenseIDs[r.(float64)]; !ok {
+				t.Errorf("textnotice: got %s %s reference %v, want license index", pkg, field, r)
+			}
+		}
+	}
+
+	// expectedExpressions maps the package names to their declared SPDX
+	// expressions; "SPDX-license-identifier-BSD" has no version to map.
+	expectedExpressions := map[string]string{
+		"Android":  "Apache-2.0",
+		"Device":   "",
+		"External": "MIT",
+	}
+	packages, _ := model["packages"].([]interface{})
+	projects := 0
+	names := make(map[string]bool)
+	for i, p := range packages {
+		p := p.(map[string]interface{})
+		id, _ := p["id"].(string)
+		if p["_id"] != float64(i) {
+			t.Errorf("textnotice: got package _id %v at %d, want %d", p["_id"], i, i)
+		}
+		parts := strings.Split(id, ":")
+		if len(parts) != 4 || parts[0] != "Android" || len(parts[2]) == 0 {
+			t.Errorf("textnotice: got package id %q, want Android::name:version", id)
+			continue
+		}
+		names[parts[2]] = true
+		if p["is_project"] == true {
+			projects++
+			if p["definition_file_path"] != "testdata/notice/highest.apex.meta_lic" {
+				t.Errorf("textnotice: got project %q definition file %v, want the root file", id, p["definition_file_path"])
+			}
+			continue
+		}
+		checkRefs(id, "declared_licenses", p["declared_licenses"])
+		checkRefs(id, "detected_licenses", p["detected_licenses"])
+		processed := p["declared_licenses_processed"].(map[string]interface{})
+		checkRefs(id, "mapped_licenses", processed["mapped_licenses"])
+		checkRefs(id, "unmapped_licenses", processed["unmapped_licenses"])
+		expression, _ := processed["spdx_expression"].(string)
+		if expected := expectedExpressions[parts[2]]; expression != expected {
+			t.Errorf("textnotice: got package %q spdx_expression %q, want %q", id, expression, expected)
+		}
+		if unmapped, _ := processed["unmapped_licenses"].([]interface{}); (len(expression) == 0) != (len(unmapped) > 0) {
+			t.Errorf("textnotice: got package %q unmapped licenses %v for expression %q", id, unmapped, expression)
+		}
+		if _, ok := p["curations"].([]interface{}); !ok {
+			t.Errorf("textnotice: got package %q curations %v, want array", id, p["curations"])
+		}
+		findings, _ := p["findings"].([]interface{})
+		if len(findings) == 0 {
+			t.Errorf("textnotice: got package %q with no findings, want license findings", id)
+		}
+		for _, f := range findings {
+			f := f.(map[string]interface{})
+			if f["type"] != "LICENSE" {
+				t.Errorf("textnotice: got finding type %v, want LICENSE", f["type"])
+			}
+			if _, ok := licenseIDs[f["license"].(float64)]; !ok {
+				t.Errorf("textnotice: got finding license %v, want license index", f["license"])
+			}
+			path, _ := f["path"].(string)
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("textnotice: got finding path %q, want license text file: %s", path, err)
+			}
+			if f["start_line"].(float64) < 1 || f["end_line"].(float64) < f["start_line"].(float64) {
+				t.Errorf("textnotice: got finding lines %v-%v, want valid range", f["start_line"], f["end_line"])
+			}
+		}
+	}
+	if projects != 1 {
+		t.Errorf("textnotice: got %d projects, want 1", projects)
+	}
+	for _, name := range []string{"highest.apex", "Android", "Device", "External"} {
+		if !names[name] {
+			t.Errorf("textnotice: got packages %v, want %q", names, name)
+		}
+	}
+}
+
+func TestHashCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "hashes.json")
+
+	// run writes the notice for highest.apex reusing the hashes in
+	// `cacheFile`.
+	run := func() string {
+		hashCache, err := compliance.ReadHashCache(cacheFile)
+		if err != nil {
+			t.Fatalf("ReadHashCache: got error %s, want no error", err)
+		}
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		var deps []string
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), hashCache: hashCache, deps: &deps}
+
+		if _, err := textNotice(context.Background(), &bc, "testdata/notice/highest.apex.meta_lic"); err != nil {
+			t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+		}
+		if err := hashCache.Write(cacheFile); err != nil {
+			t.Fatalf("Write: got error %s, want no error", err)
+		}
+		return stdout.String()
+	}
+
+	first := run()
+	if second := run(); second != first {
+		t.Errorf("textnotice: got cached notice:\n%s\nwant:\n%s", second, first)
+	}
+}
+
+func TestShowVersions(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "Android"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin1"
+deps: {
+  file: "lib/liba.so.meta_lic"
+  annotations: "static"
+}
+deps: {
+  file: "lib/libb1.so.meta_lic"
+  annotations: "static"
+}
+deps: {
+  file: "lib/libb2.so.meta_lic"
+  annotations: "static"
+}
+`)},
+		"lib/liba.so.meta_lic": {Data: []byte(`package_name: "liba"
+projects: "external/a"
+license_conditions: "notice"
+license_texts: "external/a/LICENSE"
+installed: "out/target/product/fictional/system/lib/liba.so"
+`)},
+		"lib/libb1.so.meta_lic": {Data: []byte(`package_name: "libb"
+projects: "external/b1"
+license_conditions: "notice"
+license_texts: "external/b1/LICENSE"
+installed: "out/target/product/fictional/system/lib/libb1.so"
+`)},
+		"lib/libb2.so.meta_lic": {Data: []byte(`package_name: "libb"
+projects: "external/b2"
+license_conditions: "notice"
+license_texts: "external/b2/LICENSE"
+installed: "out/target/product/fictional/system/lib/libb2.so"
+`)},
+		"LICENSE":              {Data: []byte("%%%Android License%%%\n")},
+		"external/a/LICENSE":   {Data: []byte("%%%A License%%%\n")},
+		"external/a/METADATA":  {Data: []byte("third_party { version: \"1.2\" }\n")},
+		"external/b1/LICENSE":  {Data: []byte("%%%B1 License%%%\n")},
+		"external/b1/METADATA": {Data: []byte("third_party { version: \"2.0\" }\n")},
+		"external/b2/LICENSE":  {Data: []byte("%%%B2 License%%%\n")},
+		"external/b2/METADATA": {Data: []byte("third_party { version: \"3.0\" }\n")},
+	}
+
+	// usedBy returns the "used by" lines and stderr of the textnotice output
+	// with or without -show_versions.
+	usedBy := func(showVersions bool) ([]string, string) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		var deps []string
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, showVersions: showVersions, deps: &deps}
+		if _, err := textNotice(context.Background(), &bc, "bin/bin1.meta_lic"); err != nil {
+			t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+		}
+		var lines []string
+		for _, line := range strings.Split(stdout.String(), "\n") {
+			if strings.HasSuffix(line, " used by:") {
+				lines = append(lines, line)
+			}
+		}
+		sort.Strings(lines)
+		return lines, stderr.String()
+	}
+
+	lines, stderr := usedBy(false)
+	expected := []string{"Android used by:", "liba used by:", "libb used by:", "libb used by:"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("textnotice: got %q, want %q", lines, expected)
+	}
+	if len(stderr) > 0 {
+		t.Errorf("textnotice: got stderr %q, want none", stderr)
+	}
+
+	lines, stderr = usedBy(true)
+	expected = []string{"Android used by:", "liba 1.2 used by:", "libb used by:", "libb used by:"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("textnotice -show_versions: got %q, want %q", lines, expected)
+	}
+	if !strings.Contains(stderr, "conflicting library versions") || !strings.Contains(stderr, "libb has versions 2.0 (external/b1) and 3.0 (external/b2)") {
+		t.Errorf("textnotice -show_versions: got stderr %q, want libb version conflict", stderr)
+	}
+}
+
+func TestLibraryNames(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin/bin1.meta_lic": {Data: []byte(`package_name: "Android"
+license_conditions: "notice"
+license_texts: "LICENSE"
+installed: "out/target/product/fictional/system/bin/bin1"
+deps: {
+  file: "lib/liba.so.meta_lic"
+  annotations: "static"
+}
+`)},
+		"lib/liba.so.meta_lic": {Data: []byte(`package_name: "liba_v_1.2"
+projects: "external/a"
+license_conditions: "notice"
+license_texts: "external/a/LICENSE"
+installed: "out/target/product/fictional/system/lib/liba.so"
+`)},
+		"LICENSE":            {Data: []byte("%%%Android License%%%\n")},
+		"external/a/LICENSE": {Data: []byte("%%%A License%%%\n")},
+	}
+	libraryNames, err := compliance.ParseLibraryNames(strings.NewReader("external/a=Library A\nexternal/stale=Stale\n"))
+	if err != nil {
+		t.Fatalf("ParseLibraryNames(): got error %s, want no error", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	var deps []string
+	bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, libraryNames: libraryNames, deps: &deps}
+	if _, err := textNotice(context.Background(), &bc, "bin/bin1.meta_lic"); err != nil {
+		t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+	}
+	if !strings.Contains(stdout.String(), "Library A used by:") || strings.Contains(stdout.String(), "liba_v_1.2") {
+		t.Errorf("textnotice -library_names: got %q, want library named \"Library A\"", stdout)
+	}
+	if !strings.Contains(stderr.String(), "unused library name override") || !strings.Contains(stderr.String(), "external/stale") {
+		t.Errorf("textnotice -library_names: got stderr %q, want warning about external/stale", stderr)
+	}
+	if strings.Count(stderr.String(), "unused library name override") != 1 {
+		t.Errorf("textnotice -library_names: got stderr %q, want only external/stale unused", stderr)
+	}
+}
+
+func TestEmptyText(t *testing.T) {
+	tests := []struct {
+		mode            compliance.EmptyTextMode
+		expectedLibs    []string
+		expectedTexts   []string
+		expectedMissing string
+	}{
+		{
+			mode:         compliance.EmptyTextKeep,
+			expectedLibs: []string{"Gadget used by:", "Gizmo used by:", "Widget used by:"},
+		},
+		{
+			mode:         compliance.EmptyTextSkip,
+			expectedLibs: []string{"Widget used by:"},
+			expectedMissing: "testdata/regressempty/lib/liba.a.meta_lic\tnotice\tout/target/product/fictional/system/bin/bin1\n" +
+				"testdata/regressempty/lib/libb.a.meta_lic\tnotice\tout/target/product/fictional/system/bin/bin1\n",
+		},
+		{
+			mode:         compliance.EmptyTextPlaceholder,
+			expectedLibs: []string{"Gadget used by:", "Gizmo used by:", "Widget used by:"},
+			expectedTexts: []string{
+				"*** MISSING LICENSE TEXT: the license text file testdata/regressempty/lib/BLANK is empty. ***",
+				"*** MISSING LICENSE TEXT: the license text file testdata/regressempty/lib/NOTICE is empty. ***",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			report := filepath.Join(t.TempDir(), "missing.tsv")
+			var deps []string
+			bc := buildContext{stdout: stdout, stderr: stderr, rootFS: compliance.GetFS(""), missing: &compliance.MissingTextReport{File: report}, emptyText: tt.mode, deps: &deps}
+			if _, err := textNotice(context.Background(), &bc, "testdata/regressempty/bin/bin1.meta_lic"); err != nil {
+				t.Fatalf("textnotice: error = %v, stderr = %v", err, stderr)
+			}
+
+			var libs []string
+			for _, line := range strings.Split(stdout.String(), "\n") {
+				if strings.HasSuffix(line, " used by:") {
+					libs = append(libs, line)
+				}
+			}
+			sort.Strings(libs)
+			if !reflect.DeepEqual(libs, tt.expectedLibs) {
+				t.Errorf("textnotice -empty_text=%s: got libraries %q, want %q", tt.mode, libs, tt.expectedLibs)
+			}
+			for _, text := range tt.expectedTexts {
+				if !strings.Contains(stdout.String(), text) {
+					t.Errorf("textnotice -empty_text=%s: got %q, want placeholder %q", tt.mode, stdout, text)
+				}
+			}
+			if !strings.Contains(stdout.String(), "Permission is hereby granted") {
+				t.Errorf("textnotice -empty_text=%s: got %q, want the Widget license text", tt.mode, stdout)
+			}
+
+			// Skipped texts leave their targets without a license text.
+			data, err := os.ReadFile(report)
+			if err != nil {
+				t.Fatalf("textnotice: cannot read report: %s", err)
+			}
+			if expected := "# target\tconditions\tinstall paths\n" + tt.expectedMissing; string(data) != expected {
+				t.Errorf("textnotice -empty_text=%s: got missing texts %q, want %q", tt.mode, data, expected)
+			}
+
+			// Every mode warns about each empty text.
+			for _, expected := range []string{
+				"target=testdata/regressempty/lib/liba.a.meta_lic path=testdata/regressempty/lib/NOTICE",
+				"target=testdata/regressempty/lib/libb.a.meta_lic path=testdata/regressempty/lib/BLANK",
+			} {
+				if !strings.Contains(stderr.String(), expected) {
+					t.Errorf("textnotice -empty_text=%s: got stderr %q, want warning %q", tt.mode, stderr, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestKeepGoing(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"bin\"\nlicense_conditions: \"notice\"\n" +
+			"deps: {\n  file: \"liba.meta_lic\"\n}\ndeps: {\n  file: \"libb.meta_lic\"\n}\n")},
+		"liba.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"liba\"\nlicense_kind: \"notice\"\n")},
+		"libb.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"libb\"\ndeps: {\n  file: 3\n}\n")},
+	}
+	for _, keepGoing := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keep_going=%t", keepGoing), func(t *testing.T) {
+			var deps []string
+			bc := buildContext{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, rootFS: rootFS, keepGoing: keepGoing, deps: &deps}
+
+			_, err := textNotice(context.Background(), &bc, "bin.meta_lic")
+			if err == nil {
+				t.Fatalf("textnotice: got no error, want error")
+			}
+			var errs compliance.MetadataErrors
+			if errors.As(err, &errs) != keepGoing {
+				t.Errorf("textnotice: got error %v, want MetadataErrors %t", err, keepGoing)
+			}
+			expected := []string{
+				`license metadata "liba.meta_lic" line 2 field "license_kind": unknown field: license_kind (required by root "bin.meta_lic")`,
+				`license metadata "libb.meta_lic" line 3 field "deps.file": invalid value for string type: 3 (required by root "bin.meta_lic")`,
+			}
+			found := 0
+			for _, e := range expected {
+				if strings.Contains(err.Error(), e) {
+					found++
+				}
+			}
+			if keepGoing && found != 2 || !keepGoing && found != 1 {
+				t.Errorf("textnotice: got error %q, want %d of %q", err, map[bool]int{false: 1, true: 2}[keepGoing], expected)
+			}
+		})
+	}
+}
+
+func TestZipFS(t *testing.T) {
+	// Archive the testdata under out/ like a release archive.
+	archive := filepath.Join(t.TempDir(), "meta_lic.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bin.meta_lic uses the METADATA of the testdata/notice project.
+	files := map[string]string{
+		"testdata/zip/bin.meta_lic": `package_name: "zipped"
+projects: "testdata/notice"
+license_conditions: "notice"
+license_texts: "testdata/notice/NOTICE_LICENSE"
+installed: "out/target/product/fictional/system/bin/zipped"
+`,
+	}
+	for name, content := range fixtures {
+		files[name] = content
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create("out/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	notice := func(rootFS fs.FS, root string) (string, []string) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		var deps []string
+		bc := buildContext{stdout: stdout, stderr: stderr, rootFS: rootFS, stripPrefix: []string{"out/target/product/fictional/"}, showVersions: true, deps: &deps}
+		if _, err := textNotice(context.Background(), &bc, root); err != nil {
+			t.Fatalf("textnotice: got error %s, want no error: %s", err, stderr)
+		}
+		return stdout.String(), deps
+	}
+
+	zfs, err := compliance.OpenZipFS(archive + "!/out")
+	if err != nil {
+		t.Fatalf("OpenZipFS(): got error %s, want no error", err)
+	}
+	defer zfs.Close()
+	actual, deps := notice(zfs, "testdata/notice/highest.apex.meta_lic")
+	expected, expectedDeps := notice(fixtureFS(), "testdata/notice/highest.apex.meta_lic")
+	if actual != expected {
+		t.Errorf("textnotice(zip): got %q, want %q", actual, expected)
+	}
+	if !reflect.DeepEqual(deps, expectedDeps) {
+		t.Errorf("textnotice(zip): got deps %q, want %q", deps, expectedDeps)
+	}
+	// The version comes from the METADATA file in the archive.
+	actual, deps = notice(zfs, "testdata/zip/bin.meta_lic")
+	if !strings.Contains(actual, "noticetd_v_1.0 1.0 used by:") || !strings.Contains(actual, "%%%Notice License%%%") || !slices.Contains(deps, "testdata/notice/METADATA") {
+		t.Errorf("textnotice(zip): got output %q and deps %q, want the version from testdata/notice/METADATA", actual, deps)
 	}
-	return sb.String()
 }
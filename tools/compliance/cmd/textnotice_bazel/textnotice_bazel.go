@@ -0,0 +1,339 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+
+	"github.com/google/blueprint/deptools"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+	failNoOutput      = fmt.Errorf("\nNo notice output requested")
+)
+
+// actionArgs holds the inputs and outputs of the action per the args.json
+// file written by the Bazel rule.
+type actionArgs struct {
+	// Inputs lists the root license metadata files.
+	Inputs []string `json:"inputs"`
+	// Outputs names where to write the notice and optionally the deps file.
+	Outputs struct {
+		Notice string `json:"notice"`
+		Deps   string `json:"deps"`
+	} `json:"outputs"`
+	Product     string   `json:"product"`
+	StripPrefix []string `json:"strip_prefix"`
+	Title       []string `json:"title"`
+}
+
+// manifestEntry describes one output in the output manifest.
+type manifestEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// outputManifest lists the outputs written by the action in path order.
+type outputManifest struct {
+	Outputs []manifestEntry `json:"outputs"`
+	// SourceDateEpoch is the modification time given to every output or 0.
+	SourceDateEpoch int64 `json:"source_date_epoch,omitempty"`
+}
+
+type context struct {
+	stderr io.Writer
+	rootFS fs.FS
+	// sourceDateEpoch is the modification time for the outputs or the zero
+	// time to leave the current time.
+	sourceDateEpoch time.Time
+}
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} args.json
+
+Runs textnotice as a Bazel action reading the root license metadata files and
+the output paths from the args.json file of the action, e.g.
+
+  {"inputs": ["a.meta_lic"], "outputs": {"notice": "NOTICE.txt", "deps": "NOTICE.d"}}
+
+Gives every output the modification time SOURCE_DATE_EPOCH when set. Exits 0
+only after writing every output.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputManifest := flags.String("output_manifest", "", "Where to write the JSON manifest listing the size and SHA-256 of each output.")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify exactly one args.json file.
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	sourceDateEpoch, err := parseSourceDateEpoch(os.Getenv("SOURCE_DATE_EPOCH"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	args, err := readActionArgs(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read action args %q: %s\n", flags.Arg(0), err)
+		os.Exit(1)
+	}
+
+	ctx := &context{os.Stderr, compliance.FS, sourceDateEpoch}
+	err = runAction(ctx, args, *outputManifest)
+	if err != nil {
+		if err == failNoneRequested || err == failNoOutput {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// parseSourceDateEpoch returns the time for the SOURCE_DATE_EPOCH value `s`,
+// which counts seconds since the Unix epoch, or the zero time when empty.
+func parseSourceDateEpoch(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: want a non-negative number of seconds", s)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// readActionArgs parses the args.json file at `path`.
+func readActionArgs(path string) (*actionArgs, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var args actionArgs
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&args); err != nil {
+		return nil, err
+	}
+	return &args, nil
+}
+
+// runAction writes the notice for `args`, the deps file when requested, and
+// the output manifest to `manifestFile` when not empty.
+//
+// Removes any outputs already written when a later output fails so that
+// Bazel never sees a partial set of outputs.
+func runAction(ctx *context, args *actionArgs, manifestFile string) (err error) {
+	// Must be at least one root file.
+	if len(args.Inputs) < 1 {
+		return failNoneRequested
+	}
+	if len(args.Outputs.Notice) == 0 {
+		return failNoOutput
+	}
+
+	notice, deps, err := textNotice(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	var written []string
+	defer func() {
+		if err != nil {
+			for _, f := range written {
+				os.Remove(f)
+			}
+		}
+	}()
+	var manifest outputManifest
+	if !ctx.sourceDateEpoch.IsZero() {
+		manifest.SourceDateEpoch = ctx.sourceDateEpoch.Unix()
+	}
+	// finish sets the time of the output at `path` and adds it to the
+	// manifest.
+	finish := func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("Unable to read output %q: %w\n", path, err)
+		}
+		if !ctx.sourceDateEpoch.IsZero() {
+			if err := os.Chtimes(path, ctx.sourceDateEpoch, ctx.sourceDateEpoch); err != nil {
+				return fmt.Errorf("Unable to set the time of output %q: %w\n", path, err)
+			}
+		}
+		sum := sha256.Sum256(data)
+		manifest.Outputs = append(manifest.Outputs, manifestEntry{path, int64(len(data)), hex.EncodeToString(sum[:])})
+		return nil
+	}
+
+	written = append(written, args.Outputs.Notice)
+	if err = os.WriteFile(args.Outputs.Notice, notice, 0666); err != nil {
+		return fmt.Errorf("Unable to write notice %q: %w\n", args.Outputs.Notice, err)
+	}
+	if err = finish(args.Outputs.Notice); err != nil {
+		return err
+	}
+	if len(args.Outputs.Deps) > 0 {
+		written = append(written, args.Outputs.Deps)
+		if err = deptools.WriteDepFile(args.Outputs.Deps, args.Outputs.Notice, deps); err != nil {
+			return fmt.Errorf("Unable to write deps %q: %w\n", args.Outputs.Deps, err)
+		}
+		if err = finish(args.Outputs.Deps); err != nil {
+			return err
+		}
+	}
+
+	if len(manifestFile) > 0 {
+		sort.Slice(manifest.Outputs, func(i, j int) bool { return manifest.Outputs[i].Path < manifest.Outputs[j].Path })
+		var data []byte
+		data, err = json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+		written = append(written, manifestFile)
+		if err = os.WriteFile(manifestFile, append(data, '\n'), 0666); err != nil {
+			return fmt.Errorf("Unable to write output manifest %q: %w\n", manifestFile, err)
+		}
+		if !ctx.sourceDateEpoch.IsZero() {
+			if err = os.Chtimes(manifestFile, ctx.sourceDateEpoch, ctx.sourceDateEpoch); err != nil {
+				return fmt.Errorf("Unable to set the time of output %q: %w\n", manifestFile, err)
+			}
+		}
+	}
+	return nil
+}
+
+// textNotice returns the textnotice output for `args`, compressed when the
+// notice output ends in ".gz", and the files read.
+func textNotice(ctx *context, args *actionArgs) ([]byte, []string, error) {
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
+	licenseGraph, err := compliance.ReadLicenseGraph(rootFS, ctx.stderr, args.Inputs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read license metadata file(s) %q: %w\n", args.Inputs, err)
+	}
+	if licenseGraph == nil {
+		return nil, nil, failNoLicenses
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTexts(rootFS, licenseGraph, rs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read license text file(s) for %q: %w\n", args.Inputs, err)
+	}
+
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if strings.HasSuffix(args.Outputs.Notice, ".gz") {
+		gz, _ = gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		// The header holds no time unless SOURCE_DATE_EPOCH gives one.
+		if !ctx.sourceDateEpoch.IsZero() {
+			gz.ModTime = ctx.sourceDateEpoch
+		}
+		w = gz
+	}
+	if err := writeText(w, args, ni); err != nil {
+		return nil, nil, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return buf.Bytes(), rootFS.Files(), nil
+}
+
+// writeText writes the notice for `ni` in the format of textnotice.
+func writeText(w io.Writer, args *actionArgs, ni *compliance.NoticeIndex) error {
+	if len(args.Title) > 0 {
+		for _, title := range args.Title {
+			fmt.Fprintln(w, title)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, h := range ni.AllHashes() {
+		text, err := ni.HashText(h)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "==============================================================================")
+		for _, libName := range ni.HashLibs(h) {
+			fmt.Fprintf(w, "%s used by:\n", libName)
+			for _, installPath := range ni.HashLibInstalls(h, libName) {
+				fmt.Fprintf(w, "  %s\n", compliance.StripPrefix(installPath, args.StripPrefix, args.Product))
+			}
+			fmt.Fprintln(w)
+		}
+		w.Write(text)
+		fmt.Fprintln(w)
+	}
+	return nil
+}
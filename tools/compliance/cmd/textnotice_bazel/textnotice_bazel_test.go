@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"android/soong/tools/compliance"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// writeArgs writes the args.json file for `inputs` and `notice` and `deps`
+// outputs in `dir` and returns its path.
+func writeArgs(t *testing.T, dir string, inputs []string, notice, deps string) string {
+	t.Helper()
+	args := map[string]interface{}{
+		"inputs":       inputs,
+		"outputs":      map[string]string{"notice": notice, "deps": deps},
+		"product":      "fictional",
+		"strip_prefix": []string{"out/target/product/fictional/"},
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("cannot encode args.json: %s", err)
+	}
+	path := filepath.Join(dir, "args.json")
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		t.Fatalf("cannot write args.json: %s", err)
+	}
+	return path
+}
+
+func TestAction(t *testing.T) {
+	epoch := time.Unix(1700000000, 0).UTC()
+	for _, name := range []string{"NOTICE.txt", "NOTICE.txt.gz"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			notice := filepath.Join(dir, name)
+			deps := filepath.Join(dir, "NOTICE.d")
+			manifestFile := filepath.Join(dir, "manifest.json")
+			args, err := readActionArgs(writeArgs(t, dir, []string{"testdata/notice/application.meta_lic"}, notice, deps))
+			if err != nil {
+				t.Fatalf("readActionArgs: got error %s, want no error", err)
+			}
+
+			ctx := &context{&bytes.Buffer{}, compliance.GetFS(""), epoch}
+			if err := runAction(ctx, args, manifestFile); err != nil {
+				t.Fatalf("runAction: got error %s, want no error", err)
+			}
+
+			data, err := os.ReadFile(notice)
+			if err != nil {
+				t.Fatalf("runAction: cannot read notice: %s", err)
+			}
+			text := data
+			if strings.HasSuffix(name, ".gz") {
+				r, err := gzip.NewReader(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("runAction: got notice not compressed: %s", err)
+				}
+				if !r.ModTime.Equal(epoch) {
+					t.Errorf("runAction: got gzip time %s, want %s", r.ModTime, epoch)
+				}
+				text, _ = io.ReadAll(r)
+			}
+			if !strings.Contains(string(text), "Android used by:\n  bin/application\n") {
+				t.Errorf("runAction: got notice %q, want stripped install paths", text)
+			}
+
+			depsData, err := os.ReadFile(deps)
+			if err != nil || !strings.Contains(string(depsData), "testdata/notice/NOTICE_LICENSE") {
+				t.Errorf("runAction: got deps %q error %v, want the license text", depsData, err)
+			}
+
+			var manifest outputManifest
+			manifestData, err := os.ReadFile(manifestFile)
+			if err != nil {
+				t.Fatalf("runAction: cannot read manifest: %s", err)
+			}
+			if err := json.Unmarshal(manifestData, &manifest); err != nil {
+				t.Fatalf("runAction: cannot parse manifest %q: %s", manifestData, err)
+			}
+			if manifest.SourceDateEpoch != epoch.Unix() || len(manifest.Outputs) != 2 {
+				t.Fatalf("runAction: got manifest %+v, want 2 outputs at %d", manifest, epoch.Unix())
+			}
+			if manifest.Outputs[0].Path != deps || manifest.Outputs[1].Path != notice {
+				t.Errorf("runAction: got manifest %+v, want deps then notice", manifest)
+			}
+			sum := sha256.Sum256(data)
+			if entry := manifest.Outputs[1]; entry.Size != int64(len(data)) || entry.Sha256 != hex.EncodeToString(sum[:]) {
+				t.Errorf("runAction: got manifest entry %+v, want size %d sha256 %x", entry, len(data), sum)
+			}
+
+			for _, f := range []string{notice, deps, manifestFile} {
+				fi, err := os.Stat(f)
+				if err != nil {
+					t.Errorf("runAction: cannot stat %q: %s", f, err)
+				} else if !fi.ModTime().Equal(epoch) {
+					t.Errorf("runAction: got time %s for %q, want %s", fi.ModTime(), f, epoch)
+				}
+			}
+		})
+	}
+}
+
+func TestActionErrors(t *testing.T) {
+	dir := t.TempDir()
+	notice := filepath.Join(dir, "NOTICE.txt")
+	ctx := &context{&bytes.Buffer{}, compliance.GetFS(""), time.Time{}}
+
+	args, _ := readActionArgs(writeArgs(t, dir, nil, notice, ""))
+	if err := runAction(ctx, args, ""); err != failNoneRequested {
+		t.Errorf("runAction(no inputs): got error %v, want %v", err, failNoneRequested)
+	}
+	args, _ = readActionArgs(writeArgs(t, dir, []string{"testdata/notice/application.meta_lic"}, "", ""))
+	if err := runAction(ctx, args, ""); err != failNoOutput {
+		t.Errorf("runAction(no notice): got error %v, want %v", err, failNoOutput)
+	}
+	args, _ = readActionArgs(writeArgs(t, dir, []string{"testdata/notice/missing.meta_lic"}, notice, ""))
+	if err := runAction(ctx, args, ""); err == nil {
+		t.Errorf("runAction(missing input): got no error, want error")
+	}
+
+	// A failed output removes the outputs already written.
+	args, _ = readActionArgs(writeArgs(t, dir, []string{"testdata/notice/application.meta_lic"}, notice, filepath.Join(dir, "missing", "NOTICE.d")))
+	if err := runAction(ctx, args, ""); err == nil {
+		t.Errorf("runAction(unwritable deps): got no error, want error")
+	}
+	if _, err := os.Stat(notice); !os.IsNotExist(err) {
+		t.Errorf("runAction(unwritable deps): got notice left behind, want removed")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"input": []}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readActionArgs(filepath.Join(dir, "bad.json")); err == nil {
+		t.Errorf("readActionArgs(unknown field): got no error, want error")
+	}
+}
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	if epoch, err := parseSourceDateEpoch(""); err != nil || !epoch.IsZero() {
+		t.Errorf("parseSourceDateEpoch(\"\"): got %s error %v, want zero time", epoch, err)
+	}
+	if epoch, err := parseSourceDateEpoch("315532800"); err != nil || epoch.Year() != 1980 {
+		t.Errorf("parseSourceDateEpoch(315532800): got %s error %v, want 1980", epoch, err)
+	}
+	for _, s := range []string{"-1", "yesterday", "1.5"} {
+		if _, err := parseSourceDateEpoch(s); err == nil {
+			t.Errorf("parseSourceDateEpoch(%q): got no error, want error", s)
+		}
+	}
+}
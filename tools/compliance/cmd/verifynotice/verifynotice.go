@@ -0,0 +1,501 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"android/soong/response"
+	"android/soong/tools/compliance"
+)
+
+var (
+	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
+	failNoNotice      = fmt.Errorf("\nNo notice file given with -notice")
+	failNoLicenses    = fmt.Errorf("No licenses found")
+)
+
+var (
+	// horizontalRule separates the sections of a text notice.
+	horizontalRule = regexp.MustCompile(`^=+$`)
+
+	// usedBy starts the list of install paths of a library in a text notice.
+	usedBy = regexp.MustCompile(`^(\S.*) used by:$`)
+
+	// htmlEntry matches either a library or a license text in an html notice.
+	htmlEntry = regexp.MustCompile(`(?s)<strong id="[^"]*">([^<]*)</strong> used by:|<pre class="license-text">(.*?)</pre><!-- license-text -->`)
+)
+
+type context struct {
+	stdout io.Writer
+	stderr io.Writer
+	rootFS fs.FS
+	// format is the format of the notice files: "text", "html" or "json", or
+	// empty to infer it from the name of the first notice file.
+	format string
+}
+
+// newMultiString creates a flag that allows multiple values in an array.
+func newMultiString(flags *flag.FlagSet, name, usage string) *multiString {
+	var f multiString
+	flags.Var(&f, name, usage)
+	return &f
+}
+
+// multiString implements the flag `Value` interface for multiple strings.
+type multiString []string
+
+func (ms *multiString) String() string     { return strings.Join(*ms, ", ") }
+func (ms *multiString) Set(s string) error { *ms = append(*ms, s); return nil }
+
+func main() {
+	var expandedArgs []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "@") {
+			f, err := os.Open(strings.TrimPrefix(arg, "@"))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(2)
+			}
+
+			respArgs, err := response.ReadRspFile(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(2)
+			}
+			expandedArgs = append(expandedArgs, respArgs...)
+		} else {
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s {options} -notice NOTICE file.meta_lic {file.meta_lic...}
+
+Verifies that the existing notice covers the license texts of every library
+shipped by the root license metadata files, and outputs the license texts of
+libraries missing from the notice and the extra license texts in the notice
+not shipped, one per line.
+
+The notice is a text or html notice written by textnotice or htmlnotice, a
+NOTICE.json written by jsonnotice, or a -json_index written by htmlnotice,
+either optionally gzipped. A notice split across several files may be given
+with -notice for each file.
+
+Exits 0 when the notice covers the libraries exactly, 1 when the notice has
+missing or extra license texts, and 2 when the notice cannot be read or
+parsed or on any other error.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+
+	outputFile := flags.String("o", "-", "Where to write the missing and extra license texts. (default stdout)")
+	notices := newMultiString(flags, "notice", "The notice file to verify. (multiple allowed)")
+	format := flags.String("format", "", "The format of the notice: text, html or json. (default from the file name)")
+
+	flags.Parse(expandedArgs)
+
+	// Must specify at least one root target.
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	switch *format {
+	case "", "text", "html", "json":
+	default:
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-format must be text, html or json\n")
+		os.Exit(2)
+	}
+
+	if len(*outputFile) == 0 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
+		os.Exit(2)
+	}
+
+	var ofile io.Writer
+	ofile = os.Stdout
+	if *outputFile != "-" {
+		ofile = &bytes.Buffer{}
+	}
+
+	ctx := &context{ofile, os.Stderr, compliance.FS, *format}
+
+	gap, err := verifyNotice(ctx, *notices, flags.Args()...)
+	if err != nil {
+		if err == failNoneRequested || err == failNoNotice {
+			flags.Usage()
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(2)
+	}
+	if *outputFile != "-" {
+		err := os.WriteFile(*outputFile, ofile.(*bytes.Buffer).Bytes(), 0666)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+			os.Exit(2)
+		}
+	}
+	if gap {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// coverage maps library names to the keys identifying their license texts.
+type coverage map[string]map[string]struct{}
+
+// add records license text `key` for library `libName`.
+func (c coverage) add(libName, key string) {
+	keys, ok := c[libName]
+	if !ok {
+		keys = make(map[string]struct{})
+		c[libName] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// textKey identifies a license text by the sha256 hash of the text with the
+// copyrights normalized and the surrounding whitespace removed, which is all
+// that text and html notices preserve.
+func textKey(text string) string {
+	text = strings.TrimSpace(compliance.NormalizeCopyrights(text))
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(text)))
+}
+
+// jsonNoticeKey identifies a license text the same way as jsonnotice.
+func jsonNoticeKey(text string) string {
+	text = compliance.NormalizeCopyrights(text)
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(text)))
+}
+
+// notice describes the libraries and license texts in a notice given a
+// function computing the key identifying a license text from the text and
+// the compliance hash of the text.
+type notice struct {
+	libs coverage
+	key  func(text []byte, hash string) string
+}
+
+// openNotice opens the notice file `name` decompressing it when it ends with
+// ".gz".
+func openNotice(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, f}, nil
+}
+
+// noticeFormat returns the format of notice file `name` inferred from its
+// extension.
+func noticeFormat(name string) (string, error) {
+	switch filepath.Ext(strings.TrimSuffix(name, ".gz")) {
+	case ".txt":
+		return "text", nil
+	case ".html", ".htm":
+		return "html", nil
+	case ".json":
+		return "json", nil
+	}
+	return "", fmt.Errorf("cannot infer the format of notice %q; use -format", name)
+}
+
+// verifyNotice implements the verifynotice utility returning true when the
+// notice files `notices` have missing or extra license texts for the
+// libraries shipped by the root license metadata files `files`.
+func verifyNotice(ctx *context, notices []string, files ...string) (bool, error) {
+	// Must be at least one root file.
+	if len(files) < 1 {
+		return false, failNoneRequested
+	}
+	// Must be at least one notice file.
+	if len(notices) < 1 {
+		return false, failNoNotice
+	}
+
+	format := ctx.format
+	if len(format) == 0 {
+		var err error
+		format, err = noticeFormat(notices[0])
+		if err != nil {
+			return false, err
+		}
+	}
+
+	n := &notice{make(coverage), nil}
+	for _, name := range notices {
+		err := readNotice(n, name, format)
+		if err != nil {
+			return false, fmt.Errorf("Unable to parse notice %q: %v\n", name, err)
+		}
+	}
+
+	expected, err := shippedCoverage(ctx, n.key, files)
+	if err != nil {
+		return false, err
+	}
+
+	missing := difference(expected, n.libs)
+	extra := difference(n.libs, expected)
+	for _, s := range missing {
+		fmt.Fprintf(ctx.stdout, "missing %s\n", s)
+	}
+	for _, s := range extra {
+		fmt.Fprintf(ctx.stdout, "extra %s\n", s)
+	}
+	return len(missing)+len(extra) > 0, nil
+}
+
+// readNotice adds the libraries and license texts in notice file `name` of
+// format `format` to `n`.
+func readNotice(n *notice, name, format string) error {
+	r, err := openNotice(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	switch format {
+	case "text":
+		n.key = func(text []byte, _ string) string { return textKey(string(text)) }
+		return readTextNotice(n.libs, r)
+	case "html":
+		n.key = func(text []byte, _ string) string { return textKey(string(text)) }
+		return readHTMLNotice(n.libs, r)
+	case "json":
+		return readJSONNotice(n, r)
+	}
+	return fmt.Errorf("unknown notice format %q", format)
+}
+
+// readTextNotice adds the libraries and license texts of the text notice
+// written by textnotice in `r` to `c`.
+//
+// Each section starts with a horizontal rule followed by the libraries using
+// the license text, each with its indented install paths and a blank line,
+// followed by the license text.
+func readTextNotice(c coverage, r io.Reader) error {
+	var libs []string
+	var text []string
+	inSection, inText := false, false
+	lineno, sectionLine := 0, 0
+
+	endSection := func() error {
+		if !inSection {
+			return nil
+		}
+		if len(libs) == 0 {
+			return fmt.Errorf("line %d: section names no libraries", sectionLine)
+		}
+		if !inText {
+			return fmt.Errorf("line %d: section has no license text", sectionLine)
+		}
+		key := textKey(strings.Join(text, "\n"))
+		for _, libName := range libs {
+			c.add(libName, key)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineno++
+		if horizontalRule.MatchString(line) {
+			if err := endSection(); err != nil {
+				return err
+			}
+			libs, text = nil, nil
+			inSection, inText = true, false
+			sectionLine = lineno
+			continue
+		}
+		if !inSection {
+			// Skip the title.
+			continue
+		}
+		if !inText {
+			if m := usedBy.FindStringSubmatch(line); m != nil {
+				libs = append(libs, m[1])
+				continue
+			}
+			if len(line) == 0 || strings.HasPrefix(line, " ") {
+				continue
+			}
+			inText = true
+		}
+		text = append(text, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return endSection()
+}
+
+// readHTMLNotice adds the libraries and license texts of the html notice
+// written by htmlnotice in `r` to `c`.
+//
+// Each license text follows the libraries using it.
+func readHTMLNotice(c coverage, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var libs []string
+	for _, m := range htmlEntry.FindAllSubmatchIndex(data, -1) {
+		if m[2] >= 0 {
+			libs = append(libs, html.UnescapeString(string(data[m[2]:m[3]])))
+			continue
+		}
+		if len(libs) == 0 {
+			return fmt.Errorf("license text at offset %d follows no libraries", m[0])
+		}
+		key := textKey(html.UnescapeString(string(data[m[4]:m[5]])))
+		for _, libName := range libs {
+			c.add(libName, key)
+		}
+		libs = nil
+	}
+	if len(libs) > 0 {
+		return fmt.Errorf("libraries %q have no license text", libs)
+	}
+	return nil
+}
+
+// readJSONNotice adds the libraries and license texts of the NOTICE.json
+// written by jsonnotice or the -json_index written by htmlnotice in `r` to
+// `n`.
+func readJSONNotice(n *notice, r io.Reader) error {
+	var entries []struct {
+		// Name and Licenses describe a library in a NOTICE.json.
+		Name     string `json:"name"`
+		Licenses []struct {
+			Hash string `json:"hash"`
+		} `json:"licenses"`
+
+		// LibraryName and TextHash describe a library in a -json_index.
+		LibraryName string `json:"libraryName"`
+		TextHash    string `json:"textHash"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	for i, e := range entries {
+		switch {
+		case len(e.Name) > 0 && len(e.LibraryName) == 0:
+			n.key = func(text []byte, _ string) string { return jsonNoticeKey(string(text)) }
+			for _, l := range e.Licenses {
+				if len(l.Hash) == 0 {
+					return fmt.Errorf("entry %d: library %q has a license without a hash", i, e.Name)
+				}
+				n.libs.add(e.Name, l.Hash)
+			}
+		case len(e.LibraryName) > 0 && len(e.Name) == 0:
+			if len(e.TextHash) == 0 {
+				return fmt.Errorf("entry %d: library %q has no textHash", i, e.LibraryName)
+			}
+			n.key = func(_ []byte, hash string) string { return hash }
+			n.libs.add(e.LibraryName, e.TextHash)
+		default:
+			return fmt.Errorf("entry %d: expected either a name or a libraryName", i)
+		}
+	}
+	if n.key == nil {
+		// An empty notice matches any key.
+		n.key = func(text []byte, _ string) string { return jsonNoticeKey(string(text)) }
+	}
+	return nil
+}
+
+// shippedCoverage returns the libraries and license texts shipped by the
+// root license metadata files `files` identified by `key`.
+func shippedCoverage(ctx *context, key func(text []byte, hash string) string, files []string) (coverage, error) {
+	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
+	}
+	if licenseGraph == nil {
+		return nil, failNoLicenses
+	}
+
+	// rs contains all notice resolutions.
+	rs := compliance.ResolveNotices(licenseGraph)
+
+	ni, err := compliance.IndexLicenseTexts(ctx.rootFS, licenseGraph, rs)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
+	}
+
+	c := make(coverage)
+	for _, libName := range ni.AllLibraries() {
+		for _, h := range ni.LibHashes(libName) {
+			text, err := ni.HashText(h)
+			if err != nil {
+				return nil, err
+			}
+			c.add(libName, key(text, h.String()))
+		}
+	}
+	return c, nil
+}
+
+// difference returns "library: key" for each license text of a library in
+// `a` but not in `b` sorted by library and key.
+func difference(a, b coverage) []string {
+	var result []string
+	for libName, keys := range a {
+		for key := range keys {
+			if _, ok := b[libName][key]; !ok {
+				result = append(result, libName+": "+key)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
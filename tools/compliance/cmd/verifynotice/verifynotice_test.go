@@ -0,0 +1,355 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/goldentest"
+)
+
+func TestMain(m *testing.M) {
+	// Change into the parent directory before running the tests
+	// so they can find the testdata directory.
+	if err := os.Chdir(".."); err != nil {
+		fmt.Printf("failed to change to testdata directory: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// verify runs verifynotice for notice file `name` and `roots` returning the
+// lines of output.
+func verify(t *testing.T, format, name string, roots ...string) (bool, []string) {
+	t.Helper()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx := context{stdout, stderr, compliance.GetFS(""), format}
+	gap, err := verifyNotice(&ctx, []string{name}, roots...)
+	if err != nil {
+		t.Fatalf("verifynotice: error = %v, stderr = %v", err, stderr)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("verifynotice: gotStderr = %v, want none", stderr)
+	}
+	var lines []string
+	if stdout.Len() > 0 {
+		lines = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	}
+	return gap, lines
+}
+
+// writeNotice writes `content` to file `name` in a new temporary directory
+// returning the path to the file.
+func writeNotice(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatalf("verifynotice: cannot write %q: %v", path, err)
+	}
+	return path
+}
+
+// readFile returns the content of `name` failing the test on error.
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("verifynotice: cannot read %q: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestGenerated(t *testing.T) {
+	targets := []struct {
+		name string
+		root string
+	}{
+		{"apex", "highest.apex.meta_lic"},
+		{"container", "container.zip.meta_lic"},
+		{"application", "application.meta_lic"},
+		{"binary", "bin/bin1.meta_lic"},
+		{"library", "lib/libd.so.meta_lic"},
+	}
+	for _, condition := range []string{"firstparty", "notice", "reciprocal", "restricted", "proprietary"} {
+		for _, target := range targets {
+			t.Run(condition+" "+target.name, func(t *testing.T) {
+				gap, lines := verify(t, "", goldentest.Path("textnotice/"+condition+"_"+target.name+".txt"), "testdata/"+condition+"/"+target.root)
+				if gap || len(lines) > 0 {
+					t.Errorf("verifynotice: got gap %v with %q, want none", gap, lines)
+				}
+			})
+		}
+	}
+}
+
+func TestCorruptedText(t *testing.T) {
+	golden := readFile(t, goldentest.Path("textnotice/restricted_container.txt"))
+	restricted := textKey("###Restricted License###")
+	reciprocal := textKey("$$$Reciprocal License$$$")
+
+	tests := []struct {
+		name     string
+		corrupt  func(string) string
+		expected []string
+	}{
+		{
+			name: "missing section",
+			corrupt: func(s string) string {
+				// Cut the notice at the horizontal rule before the section.
+				i := strings.Index(s, "External used by:")
+				return s[:strings.LastIndex(s[:i-1], "\n")+1]
+			},
+			expected: []string{"missing External: " + reciprocal},
+		},
+		{
+			name: "missing library",
+			corrupt: func(s string) string {
+				return strings.Replace(s, "Device used by:\n  out/target/product/fictional/data/container.zip/bin1\n  out/target/product/fictional/data/container.zip/liba.so\n\n", "", 1)
+			},
+			expected: []string{"missing Device: " + restricted},
+		},
+		{
+			name: "changed text",
+			corrupt: func(s string) string {
+				return strings.Replace(s, "$$$Reciprocal License$$$", "$$$Reciprocal License v2$$$", 1)
+			},
+			expected: []string{
+				"missing External: " + reciprocal,
+				"extra External: " + textKey("$$$Reciprocal License v2$$$"),
+			},
+		},
+		{
+			name: "extra library",
+			corrupt: func(s string) string {
+				return s + "==============================================================================\nStale used by:\n  out/target/product/fictional/system/lib/stale.so\n\n%%%Notice License%%%\n"
+			},
+			expected: []string{"extra Stale: " + textKey("%%%Notice License%%%")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupted := tt.corrupt(golden)
+			if corrupted == golden {
+				t.Fatalf("verifynotice: corruption did not change the notice")
+			}
+			name := writeNotice(t, "NOTICE.txt", corrupted)
+			gap, lines := verify(t, "", name, "testdata/restricted/container.zip.meta_lic")
+			if !gap {
+				t.Errorf("verifynotice: got no gap, want gap")
+			}
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("verifynotice: got %q, want %q", lines, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStaleNotice(t *testing.T) {
+	// The notice for the notice condition is stale for the restricted build.
+	gap, lines := verify(t, "", goldentest.Path("textnotice/notice_container.txt"), "testdata/restricted/container.zip.meta_lic")
+	if !gap {
+		t.Errorf("verifynotice: got no gap, want gap")
+	}
+	missing, extra := 0, 0
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "missing "):
+			missing++
+		case strings.HasPrefix(line, "extra "):
+			extra++
+		default:
+			t.Errorf("verifynotice: got unexpected line %q", line)
+		}
+	}
+	if missing == 0 || extra == 0 {
+		t.Errorf("verifynotice: got %d missing and %d extra, want both: %q", missing, extra, lines)
+	}
+}
+
+// htmlNotice returns an html notice in the form written by htmlnotice for the
+// restricted container without library `omit`.
+func htmlNotice(t *testing.T, omit string) string {
+	sections := []struct {
+		libs []string
+		text string
+	}{
+		{[]string{"Android"}, readFile(t, "testdata/firstparty/FIRST_PARTY_LICENSE")},
+		{[]string{"Android", "Device"}, readFile(t, "testdata/restricted/RESTRICTED_LICENSE")},
+		{[]string{"External"}, readFile(t, "testdata/reciprocal/RECIPROCAL_LICENSE")},
+	}
+	var sb strings.Builder
+	fmt.Fprintln(&sb, "<!DOCTYPE html>\n<html><head>\n</head>\n<body>")
+	for i, section := range sections {
+		fmt.Fprintln(&sb, "  <hr>")
+		for j, lib := range section.libs {
+			if lib == omit {
+				continue
+			}
+			fmt.Fprintf(&sb, "  <strong id=\"lib%d%d\">%s</strong> used by:\n    <ul class=\"file-list\">\n      <li>out/target/product/fictional/data/container.zip\n    </ul>\n", i, j, lib)
+		}
+		fmt.Fprintf(&sb, "  <a id=\"%d\"></a><pre class=\"license-text\">", i)
+		fmt.Fprintln(&sb, section.text)
+		fmt.Fprintln(&sb, "  </pre><!-- license-text -->")
+	}
+	fmt.Fprintln(&sb, "</body></html>")
+	return sb.String()
+}
+
+func TestHTML(t *testing.T) {
+	gap, lines := verify(t, "", writeNotice(t, "NOTICE.html", htmlNotice(t, "")), "testdata/restricted/container.zip.meta_lic")
+	if gap || len(lines) > 0 {
+		t.Errorf("verifynotice: got gap %v with %q, want none", gap, lines)
+	}
+
+	gap, lines = verify(t, "html", writeNotice(t, "NOTICE", htmlNotice(t, "Device")), "testdata/restricted/container.zip.meta_lic")
+	if expected := []string{"missing Device: " + textKey("###Restricted License###")}; !gap || !reflect.DeepEqual(lines, expected) {
+		t.Errorf("verifynotice: got gap %v with %q, want %q", gap, lines, expected)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	firstParty := readFile(t, "testdata/firstparty/FIRST_PARTY_LICENSE")
+	restricted := readFile(t, "testdata/restricted/RESTRICTED_LICENSE")
+	reciprocal := readFile(t, "testdata/reciprocal/RECIPROCAL_LICENSE")
+
+	md5Hash := func(text string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(text)))
+	}
+
+	notice := fmt.Sprintf(`[
+  {"name": "Android", "licenses": [{"hash": %q}, {"hash": %q}]},
+  {"name": "Device", "licenses": [{"hash": %q}]},
+  {"name": "External", "licenses": [{"hash": %q}]}
+]`, jsonNoticeKey(firstParty), jsonNoticeKey(restricted), jsonNoticeKey(restricted), jsonNoticeKey(reciprocal))
+
+	index := fmt.Sprintf(`[
+  {"libraryName": "Android", "textHash": %q},
+  {"libraryName": "Android", "textHash": %q},
+  {"libraryName": "Device", "textHash": %q},
+  {"libraryName": "External", "textHash": %q}
+]`, md5Hash(firstParty), md5Hash(restricted), md5Hash(restricted), md5Hash(reciprocal))
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(notice))
+	w.Close()
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []string
+	}{
+		{"jsonnotice", "NOTICE.json", notice, nil},
+		{"gzipped", "NOTICE.json.gz", gz.String(), nil},
+		{"json index", "index.json", index, nil},
+		{
+			name:     "corrupted jsonnotice",
+			file:     "NOTICE.json",
+			content:  strings.Replace(notice, jsonNoticeKey(reciprocal), jsonNoticeKey("stale"), 1),
+			expected: []string{"missing External: " + jsonNoticeKey(reciprocal), "extra External: " + jsonNoticeKey("stale")},
+		},
+		{
+			name:     "corrupted json index",
+			file:     "index.json",
+			content:  strings.Replace(index, `{"libraryName": "Device", "textHash": "`+md5Hash(restricted)+`"},`, "", 1),
+			expected: []string{"missing Device: " + md5Hash(restricted)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gap, lines := verify(t, "", writeNotice(t, tt.file, tt.content), "testdata/restricted/container.zip.meta_lic")
+			if gap != (len(tt.expected) > 0) || !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("verifynotice: got gap %v with %q, want %q", gap, lines, tt.expected)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		notices     []string
+		roots       []string
+		expectedErr string
+	}{
+		{
+			name:        "no roots",
+			notices:     []string{goldentest.Path("textnotice/restricted_container.txt")},
+			expectedErr: failNoneRequested.Error(),
+		},
+		{
+			name:        "no notice",
+			roots:       []string{"testdata/restricted/container.zip.meta_lic"},
+			expectedErr: failNoNotice.Error(),
+		},
+		{
+			name:        "unknown format",
+			notices:     []string{writeNotice(t, "NOTICE", "")},
+			roots:       []string{"testdata/restricted/container.zip.meta_lic"},
+			expectedErr: "cannot infer the format",
+		},
+		{
+			name:        "text without libraries",
+			notices:     []string{writeNotice(t, "NOTICE.txt", "======\n\n%%%Notice License%%%\n")},
+			roots:       []string{"testdata/restricted/container.zip.meta_lic"},
+			expectedErr: "line 1: section names no libraries",
+		},
+		{
+			name:        "html without text",
+			notices:     []string{writeNotice(t, "NOTICE.html", "<strong id=\"lib0\">Android</strong> used by:\n")},
+			roots:       []string{"testdata/restricted/container.zip.meta_lic"},
+			expectedErr: "have no license text",
+		},
+		{
+			name:        "bad json",
+			notices:     []string{writeNotice(t, "NOTICE.json", "{")},
+			roots:       []string{"testdata/restricted/container.zip.meta_lic"},
+			expectedErr: "Unable to parse notice",
+		},
+		{
+			name:        "json without hash",
+			format:      "json",
+			notices:     []string{writeNotice(t, "NOTICE", `[{"libraryName": "Android"}]`)},
+			roots:       []string{"testdata/restricted/container.zip.meta_lic"},
+			expectedErr: "has no textHash",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			ctx := context{stdout, stderr, compliance.GetFS(""), tt.format}
+			_, err := verifyNotice(&ctx, tt.notices, tt.roots...)
+			if err == nil {
+				t.Fatalf("verifynotice: got no error, want error containing %q", tt.expectedErr)
+			}
+			if !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("verifynotice: got error %q, want error containing %q", err, tt.expectedErr)
+			}
+		})
+	}
+}
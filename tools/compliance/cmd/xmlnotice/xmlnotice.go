@@ -15,8 +15,11 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
+	gocontext "context"
+	"crypto/sha256"
 	"encoding/xml"
 	"flag"
 	"fmt"
@@ -26,6 +29,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"android/soong/response"
 	"android/soong/tools/compliance"
@@ -33,35 +37,62 @@ import (
 	"github.com/google/blueprint/deptools"
 )
 
+// Versions of the xml notice format.
+//
+// Version 1 is the original format with a bare <licenses> root element.
+// Version 2 records its version in a `version` attribute on the root element
+// and may declare noticeNamespace as the default namespace. Consumers must
+// ignore unknown attributes and elements so that versions can add them.
+const (
+	formatVersion1 = 1
+	formatVersion2 = 2
+
+	// latestFormatVersion is the highest version xmlnotice can write.
+	latestFormatVersion = formatVersion2
+
+	// noticeNamespace is the namespace of the version 2 elements.
+	noticeNamespace = "urn:android:notice:2"
+)
+
 var (
 	failNoneRequested = fmt.Errorf("\nNo license metadata files requested")
 	failNoLicenses    = fmt.Errorf("No licenses found")
 )
 
 type context struct {
-	stdout      io.Writer
-	stderr      io.Writer
-	rootFS      fs.FS
-	product     string
-	stripPrefix []string
-	title       string
-	deps        *[]string
+	stdout        io.Writer
+	stderr        io.Writer
+	rootFS        fs.FS
+	product       string
+	stripPrefix   []string
+	title         string
+	skipBuildtime bool
+	// schema validates the output or is nil to skip validation.
+	schema *compliance.XMLSchema
+	// pretty indents the elements for readability.
+	pretty bool
+	// byTarget writes one element per install path instead of one per
+	// install path and library.
+	byTarget bool
+	// formatVersion is the version of the xml notice format to write.
+	formatVersion int
+	// namespace declares noticeNamespace on the root element. (version 2+)
+	namespace bool
+	// overrides replace the license conditions of targets per -overrides_file.
+	overrides []compliance.Override
+	// delta applies -baseline and -write_baseline to the notice index.
+	delta *compliance.NoticeDelta
+	// missing applies -report_missing and -missing_fatal to the notice index.
+	missing *compliance.MissingTextReport
+	// emptyText selects how to index empty license texts per -empty_text.
+	emptyText compliance.EmptyTextMode
+	// keepGoing reports every malformed license metadata file per -keep_going.
+	keepGoing bool
+	deps      *[]string
 }
 
 func (ctx context) strip(installPath string) string {
-	for _, prefix := range ctx.stripPrefix {
-		if strings.HasPrefix(installPath, prefix) {
-			p := strings.TrimPrefix(installPath, prefix)
-			if 0 == len(p) {
-				p = ctx.product
-			}
-			if 0 == len(p) {
-				continue
-			}
-			return p
-		}
-	}
-	return installPath
+	return compliance.StripPrefix(installPath, ctx.stripPrefix, ctx.product)
 }
 
 // newMultiString creates a flag that allows multiple values in an array.
@@ -105,7 +136,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, `Usage: %s {options} file.meta_lic {file.meta_lic...}
 
 Outputs an xml NOTICE.xml or gzipped NOTICE.xml.gz file if the -o filename ends
-with ".gz".
+with ".gz" or if -gzip is given.
 
 Options:
 `, filepath.Base(os.Args[0]))
@@ -114,9 +145,25 @@ Options:
 
 	outputFile := flags.String("o", "-", "Where to write the NOTICE xml or xml.gz file. (default stdout)")
 	depsFile := flags.String("d", "", "Where to write the deps file")
+	gzipOutput := flags.Bool("gzip", false, "Whether to gzip the output. (implied when -o ends with \".gz\")")
 	product := flags.String("product", "", "The name of the product for which the notice is generated.")
 	stripPrefix := newMultiString(flags, "strip_prefix", "Prefix to remove from paths. i.e. path to root (multiple allowed)")
+	baseline := flags.String("baseline", "", "A baseline of license text hashes per library from a prior release. Only libraries with new or changed license texts appear in the notice, and removed libraries are listed on stderr.")
+	writeBaseline := flags.String("write_baseline", "", "Where to write the baseline of license text hashes per library for use with -baseline in the next release.")
+	reportMissing := flags.String("report_missing", "", "Where to write the shipped targets contributing no license text to the notice with their license conditions and install paths.")
+	missingFatal := flags.Bool("missing_fatal", false, "Whether to fail when any shipped target contributes no license text to the notice.")
+	emptyTextName := flags.String("empty_text", "keep", "How to treat license text files that are empty or only whitespace after warning about them: skip, placeholder or keep.")
+	keepGoing := flags.Bool("keep_going", false, "Report every malformed license metadata file, up to 100, instead of stopping at the first.")
+	zipFile := flags.String("zip", "", "Read the license metadata, license texts and METADATA files from this zip archive, or from a directory in it given as archive.zip!/dir.")
+	overridesFile := flags.String("overrides_file", "", "A YAML file of {path, condition} entries replacing the license conditions declared by the named license metadata files.")
 	title := flags.String("title", "", "The title of the notice file.")
+	byTarget := flags.Bool("by_target", false, "Whether to write one file element per install path listing its licenses instead of one file-name element per install path and library.")
+	formatVersion := flags.Int("format_version", formatVersion1, fmt.Sprintf("The version of the xml notice format to write: %d to %d.", formatVersion1, latestFormatVersion))
+	namespace := flags.Bool("xml_namespace", false, fmt.Sprintf("Whether to declare the %q namespace on the root element. (requires -format_version 2 or higher)", noticeNamespace))
+	pretty := flags.Bool("pretty", false, "Whether to indent the xml elements two spaces per level for readability.")
+	xmlSchema := flags.String("xml_schema", "", "Path to an XML Schema (XSD) file against which to validate the output.")
+	skipBuildtime := flags.Bool("skip_buildtime", false, "Whether to ignore dependencies annotated \"buildtime\" or \"test\" so their licenses do not apply.")
+	verbose := flags.Bool("v", false, "Whether to report the size of the output on stderr.")
 
 	flags.Parse(expandedArgs)
 
@@ -126,6 +173,18 @@ Options:
 		os.Exit(2)
 	}
 
+	if *formatVersion < formatVersion1 || *formatVersion > latestFormatVersion {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-format_version must be between %d and %d\n", formatVersion1, latestFormatVersion)
+		os.Exit(2)
+	}
+
+	if *namespace && *formatVersion < formatVersion2 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "-xml_namespace requires -format_version %d or higher\n", formatVersion2)
+		os.Exit(2)
+	}
+
 	if len(*outputFile) == 0 {
 		flags.Usage()
 		fmt.Fprintf(os.Stderr, "must specify file for -o; use - for stdout\n")
@@ -147,43 +206,134 @@ Options:
 		}
 	}
 
+	var schema *compliance.XMLSchema
+	if len(*xmlSchema) > 0 {
+		f, err := os.Open(*xmlSchema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open xml schema %q: %s\n", *xmlSchema, err)
+			os.Exit(1)
+		}
+		schema, err = compliance.ReadXMLSchema(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read xml schema %q: %s\n", *xmlSchema, err)
+			os.Exit(1)
+		}
+	}
+
+	// Stream the output to the file rather than buffering the whole document.
 	var ofile io.Writer
 	var closer io.Closer
-	ofile = os.Stdout
-	var obuf *bytes.Buffer
+	var obuf *bufio.Writer
+	var out *os.File
 	if *outputFile != "-" {
-		obuf = &bytes.Buffer{}
-		ofile = obuf
+		var err error
+		out, err = os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not create output %q: %s\n", *outputFile, err)
+			os.Exit(1)
+		}
+		obuf = bufio.NewWriter(out)
+	} else {
+		obuf = bufio.NewWriter(os.Stdout)
+	}
+	ofile = obuf
+	// fail removes any partial output file and exits.
+	fail := func() {
+		if out != nil {
+			out.Close()
+			os.Remove(*outputFile)
+		}
+		os.Exit(1)
 	}
 	if strings.HasSuffix(*outputFile, ".gz") {
-		ofile, _ = gzip.NewWriterLevel(obuf, gzip.BestCompression)
-		closer = ofile.(io.Closer)
+		*gzipOutput = true
+	}
+	var compressedSize *countingWriter
+	if *gzipOutput {
+		compressedSize = &countingWriter{w: ofile}
+		gz := newGzipWriter(compressedSize)
+		ofile = gz
+		closer = gz
 	}
+	uncompressedSize := &countingWriter{w: ofile}
+	ofile = uncompressedSize
 
 	var deps []string
 
-	ctx := &context{ofile, os.Stderr, compliance.FS, *product, *stripPrefix, *title, &deps}
+	var overrides []compliance.Override
+	if len(*overridesFile) > 0 {
+		var err error
+		overrides, err = compliance.ReadOverridesFile(*overridesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read overrides file %q: %s\n", *overridesFile, err)
+			os.Exit(1)
+		}
+	}
 
-	err := xmlNotice(ctx, flags.Args()...)
+	emptyText, err := compliance.ParseEmptyTextMode(*emptyTextName)
+	if err != nil {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	var rootFS fs.FS = compliance.FS
+	var zipFS *compliance.ZipFS
+	if len(*zipFile) > 0 {
+		zipFS, err = compliance.OpenZipFS(*zipFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *zipFile, err)
+			os.Exit(1)
+		}
+		rootFS = zipFS
+	}
+
+	ctx := &context{ofile, os.Stderr, rootFS, *product, *stripPrefix, *title, *skipBuildtime, schema, *pretty, *byTarget, *formatVersion, *namespace, overrides, &compliance.NoticeDelta{Baseline: *baseline, WriteBaseline: *writeBaseline}, &compliance.MissingTextReport{File: *reportMissing, Fatal: *missingFatal}, emptyText, *keepGoing, &deps}
+
+	err = xmlNotice(ctx, flags.Args()...)
 	if err != nil {
 		if err == failNoneRequested {
 			flags.Usage()
 		}
 		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-		os.Exit(1)
+		fail()
 	}
 	if closer != nil {
-		closer.Close()
+		if err := closer.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not compress output: %s\n", err)
+			fail()
+		}
 	}
-
-	if *outputFile != "-" {
-		err := os.WriteFile(*outputFile, obuf.Bytes(), 0666)
-		if err != nil {
+	if err := obuf.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
+		fail()
+	}
+	if out != nil {
+		if err := out.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "could not write output to %q: %s\n", *outputFile, err)
-			os.Exit(1)
+			fail()
+		}
+	}
+	if *verbose {
+		if compressedSize != nil {
+			fmt.Fprintf(os.Stderr, "%s: %d bytes compressed from %d bytes\n", *outputFile, compressedSize.n, uncompressedSize.n)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %d bytes\n", *outputFile, uncompressedSize.n)
 		}
 	}
+
 	if *depsFile != "" {
+		if zipFS != nil {
+			// The files read are inside the archive.
+			deps = []string{zipFS.Archive()}
+		}
+		if len(*baseline) > 0 {
+			deps = append(deps, *baseline)
+		}
+		if len(*overridesFile) > 0 {
+			deps = append(deps, *overridesFile)
+		}
 		err := deptools.WriteDepFile(*depsFile, *outputFile, deps)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "could not write deps to %q: %s\n", *depsFile, err)
@@ -193,6 +343,103 @@ Options:
 	os.Exit(0)
 }
 
+// newGzipWriter returns a writer compressing to `w` with a fixed gzip header
+// so identical input always yields identical output bytes.
+func newGzipWriter(w io.Writer) *gzip.Writer {
+	gz, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+	// Leave the name, comment and modification time empty, and record an
+	// unknown OS, so the header does not depend on the host or build time.
+	gz.Header = gzip.Header{OS: 255}
+	return gz
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes `p` to the underlying writer counting the bytes written.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// sanitizeText returns `text` with the characters invalid in XML replaced by
+// U+FFFD the same way as xml.EscapeText so the result is exactly what a reader
+// of the escaped text gets back.
+func sanitizeText(text []byte) []byte {
+	var b bytes.Buffer
+	for len(text) > 0 {
+		r, width := utf8.DecodeRune(text)
+		if (r == utf8.RuneError && width == 1) || !isInCharacterRange(r) {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.Write(text[:width])
+		}
+		text = text[width:]
+	}
+	return b.Bytes()
+}
+
+// isInCharacterRange returns true when `r` is a character allowed in XML 1.0.
+func isInCharacterRange(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// installedLicense describes a library installed under a license text.
+type installedLicense struct {
+	contentID  string
+	lib        string
+	conditions []string
+}
+
+// installedFile lists the licenses of the libraries installed at a path.
+type installedFile struct {
+	path     string
+	licenses []installedLicense
+}
+
+// noticeText is a license text written once and referenced by its contentID.
+type noticeText struct {
+	contentID string
+	text      []byte
+}
+
+// walkNotice calls `file` for each install path in `ni` with its licenses, and
+// then `text` for each license text they reference.
+//
+// Both the default and the -by_target output walk the notice this way so that
+// they always cover the same files and texts. Only one file and one text are
+// in memory at a time so the output can stream.
+func walkNotice(ctx *context, ni *compliance.NoticeIndex, file func(installedFile), text func(noticeText)) error {
+	for _, installPath := range ni.AllInstallPaths() {
+		f := installedFile{path: ctx.strip(installPath)}
+		for _, h := range ni.InstallHashes(installPath) {
+			for _, lib := range ni.InstallHashLibs(installPath, h) {
+				conditions := ni.InstallHashLibConditions(installPath, h, lib).Names()
+				sort.Strings(conditions)
+				f.licenses = append(f.licenses, installedLicense{h.String(), lib, conditions})
+			}
+		}
+		file(f)
+	}
+	for _, h := range ni.AllHashes() {
+		content, err := ni.HashText(h)
+		if err != nil {
+			return err
+		}
+		text(noticeText{h.String(), sanitizeText(content)})
+	}
+	return nil
+}
+
 // xmlNotice implements the xmlnotice utility.
 func xmlNotice(ctx *context, files ...string) error {
 	// Must be at least one root file.
@@ -200,47 +447,114 @@ func xmlNotice(ctx *context, files ...string) error {
 		return failNoneRequested
 	}
 
+	// Record every file read for the deps file.
+	rootFS := compliance.NewRecordingFS(ctx.rootFS)
+
 	// Read the license graph from the license metadata files (*.meta_lic).
-	licenseGraph, err := compliance.ReadLicenseGraph(ctx.rootFS, ctx.stderr, files)
+	licenseGraph, err := compliance.ReadLicenseGraphWithOptions(gocontext.Background(), rootFS, ctx.stderr, files, compliance.ReadOptions{KeepGoing: ctx.keepGoing})
 	if err != nil {
 		return fmt.Errorf("Unable to read license metadata file(s) %q: %v\n", files, err)
 	}
 	if licenseGraph == nil {
 		return failNoLicenses
 	}
+	if len(ctx.overrides) > 0 {
+		licenseGraph = compliance.ApplyOverrides(licenseGraph, ctx.overrides)
+	}
+	if ctx.skipBuildtime {
+		licenseGraph.SkipBuildtimeDeps()
+	}
 
 	// rs contains all notice resolutions.
 	rs := compliance.ResolveNotices(licenseGraph)
 
-	ni, err := compliance.IndexLicenseTexts(ctx.rootFS, licenseGraph, rs)
+	ni, err := compliance.IndexLicenseTextsWithOptions(gocontext.Background(), rootFS, licenseGraph, rs, compliance.IndexOptions{EmptyText: ctx.emptyText})
 	if err != nil {
 		return fmt.Errorf("Unable to read license text file(s) for %q: %v\n", files, err)
 	}
+	compliance.WarnEmptyTexts(ctx.stderr, ni)
+	if err := ctx.delta.Apply(ni, ctx.stderr); err != nil {
+		return err
+	}
+	if err := ctx.missing.Apply(ni); err != nil {
+		return err
+	}
+
+	// Write to a buffer first when validating so invalid output is never
+	// written.
+	w := ctx.stdout
+	var obuf *bytes.Buffer
+	if ctx.schema != nil {
+		obuf = &bytes.Buffer{}
+		w = obuf
+	}
 
-	fmt.Fprintln(ctx.stdout, "<?xml version=\"1.0\" encoding=\"utf-8\"?>")
-	fmt.Fprintln(ctx.stdout, "<licenses>")
+	// indent precedes each child element of <licenses>.
+	indent := ""
+	if ctx.pretty {
+		indent = "  "
+	}
 
-	for installPath := range ni.InstallPaths() {
-		p := ctx.strip(installPath)
-		for _, h := range ni.InstallHashes(installPath) {
-			for _, lib := range ni.InstallHashLibs(installPath, h) {
-				fmt.Fprintf(ctx.stdout, "<file-name contentId=\"%s\" lib=\"", h.String())
-				xml.EscapeText(ctx.stdout, []byte(lib))
-				fmt.Fprintf(ctx.stdout, "\">")
-				xml.EscapeText(ctx.stdout, []byte(p))
-				fmt.Fprintln(ctx.stdout, "</file-name>")
+	fmt.Fprintln(w, "<?xml version=\"1.0\" encoding=\"utf-8\"?>")
+	switch {
+	case ctx.formatVersion < formatVersion2:
+		fmt.Fprintln(w, "<licenses>")
+	case ctx.namespace:
+		fmt.Fprintf(w, "<licenses xmlns=\"%s\" version=\"%d\">\n", noticeNamespace, ctx.formatVersion)
+	default:
+		fmt.Fprintf(w, "<licenses version=\"%d\">\n", ctx.formatVersion)
+	}
+
+	// writeFile writes the element(s) for an install path.
+	writeFile := func(f installedFile) {
+		for _, l := range f.licenses {
+			fmt.Fprintf(w, "%s<file-name contentId=\"%s\" lib=\"", indent, l.contentID)
+			xml.EscapeText(w, []byte(l.lib))
+			fmt.Fprintf(w, "\" condition=\"%s\">", strings.Join(l.conditions, ","))
+			xml.EscapeText(w, []byte(f.path))
+			fmt.Fprintln(w, "</file-name>")
+		}
+	}
+	if ctx.byTarget {
+		writeFile = func(f installedFile) {
+			fmt.Fprintf(w, "%s<file path=\"", indent)
+			xml.EscapeText(w, []byte(f.path))
+			fmt.Fprint(w, "\">")
+			for _, l := range f.licenses {
+				if ctx.pretty {
+					fmt.Fprintf(w, "\n%s%s", indent, indent)
+				}
+				fmt.Fprintf(w, "<license contentId=\"%s\" lib=\"", l.contentID)
+				xml.EscapeText(w, []byte(l.lib))
+				fmt.Fprintf(w, "\" condition=\"%s\"/>", strings.Join(l.conditions, ","))
+			}
+			if ctx.pretty {
+				fmt.Fprintf(w, "\n%s", indent)
 			}
+			fmt.Fprintln(w, "</file>")
 		}
 	}
-	for h := range ni.Hashes() {
-		fmt.Fprintf(ctx.stdout, "<file-content contentId=\"%s\"><![CDATA[", h)
-		xml.EscapeText(ctx.stdout, ni.HashText(h))
-		fmt.Fprintf(ctx.stdout, "]]></file-content>\n\n")
+	err = walkNotice(ctx, ni, writeFile, func(t noticeText) {
+		// Escape the text rather than wrapping it in CDATA, which cannot hold
+		// "]]>" or characters invalid in XML.
+		fmt.Fprintf(w, "%s<file-content contentId=\"%s\" hash=\"sha256:%x\">", indent, t.contentID, sha256.Sum256(t.text))
+		xml.EscapeText(w, t.text)
+		fmt.Fprintf(w, "</file-content>\n\n")
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "</licenses>")
+
+	if ctx.schema != nil {
+		err = ctx.schema.Validate(bytes.NewReader(obuf.Bytes()))
+		if err != nil {
+			return fmt.Errorf("output does not conform to xml schema: %w", err)
+		}
+		ctx.stdout.Write(obuf.Bytes())
 	}
-	fmt.Fprintln(ctx.stdout, "</licenses>")
 
-	*ctx.deps = ni.InputFiles()
-	sort.Strings(*ctx.deps)
+	*ctx.deps = rootFS.Files()
 
 	return nil
 }
@@ -17,11 +17,16 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
@@ -29,8 +34,8 @@ import (
 )
 
 var (
-	installTarget = regexp.MustCompile(`^<file-name contentId="[^"]{32}" lib="([^"]*)">([^<]+)</file-name>`)
-	licenseText = regexp.MustCompile(`^<file-content contentId="[^"]{32}"><![[]CDATA[[]([^]]*)[]][]]></file-content>`)
+	installTarget = regexp.MustCompile(`^<file-name contentId="[^"]{32}" lib="([^"]*)" condition="[^"]*">([^<]+)</file-name>`)
+	licenseText = regexp.MustCompile(`^<file-content contentId="[^"]{32}" hash="sha256:[0-9a-f]{64}">([^<]*)</file-content>`)
 )
 
 func TestMain(m *testing.M) {
@@ -50,6 +55,8 @@ func Test(t *testing.T) {
 		outDir       string
 		roots        []string
 		stripPrefix  string
+		gzip         bool
+		skipBuildtime bool
 		expectedOut  []matcher
 		expectedDeps []string
 	}{
@@ -432,6 +439,26 @@ func Test(t *testing.T) {
 				"testdata/restricted/lib/libb.so.meta_lic",
 			},
 		},
+		{
+			condition: "restricted",
+			name:      "application+gzip",
+			roots:     []string{"application.meta_lic"},
+			gzip:      true,
+			expectedOut: []matcher{
+				target{"application", "Android"},
+				target{"application", "Device"},
+				firstParty{},
+				restricted{},
+			},
+			expectedDeps: []string{
+				"testdata/firstparty/FIRST_PARTY_LICENSE",
+				"testdata/restricted/RESTRICTED_LICENSE",
+				"testdata/restricted/application.meta_lic",
+				"testdata/restricted/bin/bin3.meta_lic",
+				"testdata/restricted/lib/liba.so.meta_lic",
+				"testdata/restricted/lib/libb.so.meta_lic",
+			},
+		},
 		{
 			condition: "restricted",
 			name:      "binary",
@@ -577,6 +604,47 @@ func Test(t *testing.T) {
 				"testdata/proprietary/lib/libd.so.meta_lic",
 			},
 		},
+		{
+			condition: "regressbuildtime",
+			name:      "bin",
+			roots:     []string{"bin/bin1.meta_lic"},
+			expectedOut: []matcher{
+				target{"bin1", "Android"},
+				target{"bin1", "Runtime"},
+				target{"bin1", "Generator"},
+				target{"bin1", "Harness"},
+				notice{},
+				restricted{},
+				reciprocal{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/reciprocal/RECIPROCAL_LICENSE",
+				"testdata/regressbuildtime/bin/bin1.meta_lic",
+				"testdata/regressbuildtime/bin/gen.meta_lic",
+				"testdata/regressbuildtime/lib/liba.a.meta_lic",
+				"testdata/regressbuildtime/lib/libharness.a.meta_lic",
+				"testdata/restricted/RESTRICTED_LICENSE",
+			},
+		},
+		{
+			condition:     "regressbuildtime",
+			name:          "bin+skip_buildtime",
+			roots:         []string{"bin/bin1.meta_lic"},
+			skipBuildtime: true,
+			expectedOut: []matcher{
+				target{"bin1", "Android"},
+				target{"bin1", "Runtime"},
+				notice{},
+			},
+			expectedDeps: []string{
+				"testdata/notice/NOTICE_LICENSE",
+				"testdata/regressbuildtime/bin/bin1.meta_lic",
+				"testdata/regressbuildtime/bin/gen.meta_lic",
+				"testdata/regressbuildtime/lib/liba.a.meta_lic",
+				"testdata/regressbuildtime/lib/libharness.a.meta_lic",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.condition+" "+tt.name, func(t *testing.T) {
@@ -590,7 +658,16 @@ func Test(t *testing.T) {
 
 			var deps []string
 
-			ctx := context{stdout, stderr, compliance.GetFS(tt.outDir), "", []string{tt.stripPrefix}, "", &deps}
+			var ofile io.Writer = stdout
+			var compressed *bytes.Buffer
+			var gz *gzip.Writer
+			if tt.gzip {
+				compressed = &bytes.Buffer{}
+				gz = newGzipWriter(compressed)
+				ofile = gz
+			}
+
+			ctx := context{ofile, stderr, compliance.GetFS(tt.outDir), "", []string{tt.stripPrefix}, "", tt.skipBuildtime, nil, false, false, 1, false, nil, nil, nil, 0, false, &deps}
 
 			err := xmlNotice(&ctx, rootFiles...)
 			if err != nil {
@@ -601,6 +678,30 @@ func Test(t *testing.T) {
 				t.Errorf("xmlnotice: gotStderr = %v, want none", stderr)
 			}
 
+			if tt.gzip {
+				if err := gz.Close(); err != nil {
+					t.Fatalf("xmlnotice: could not compress output: %s", err)
+				}
+				r, err := gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+				if err != nil {
+					t.Fatalf("xmlnotice: could not read compressed output: %s", err)
+				}
+				if !r.ModTime.IsZero() || r.Name != "" || r.Comment != "" {
+					t.Errorf("xmlnotice: got gzip header %+v, want no name, comment or modification time", r.Header)
+				}
+				if _, err := io.Copy(stdout, r); err != nil {
+					t.Fatalf("xmlnotice: could not decompress output: %s", err)
+				}
+
+				again := &bytes.Buffer{}
+				gz2 := newGzipWriter(again)
+				gz2.Write(stdout.Bytes())
+				gz2.Close()
+				if !bytes.Equal(compressed.Bytes(), again.Bytes()) {
+					t.Errorf("xmlnotice: got different compressed bytes for identical output, want identical bytes")
+				}
+			}
+
 			t.Logf("got stdout: %s", stdout.String())
 
 			t.Logf("want stdout: %s", matcherList(tt.expectedOut).String())
@@ -657,6 +758,565 @@ func Test(t *testing.T) {
 	}
 }
 
+func TestXMLSchema(t *testing.T) {
+	f, err := os.Open("testdata/NOTICE.xsd")
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	schema, err := compliance.ReadXMLSchema(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+
+	// noticeXML mirrors the NOTICE.xml schema.
+	type noticeXML struct {
+		XMLName   xml.Name `xml:"licenses"`
+		FileNames []struct {
+			ContentID string `xml:"contentId,attr"`
+			Lib       string `xml:"lib,attr"`
+			Path      string `xml:",chardata"`
+		} `xml:"file-name"`
+		FileContents []struct {
+			ContentID string `xml:"contentId,attr"`
+			Text      string `xml:",chardata"`
+		} `xml:"file-content"`
+	}
+
+	tests := []struct {
+		name  string
+		roots []string
+	}{
+		{name: "notice", roots: []string{"testdata/notice/highest.apex.meta_lic"}},
+		{name: "reciprocal", roots: []string{"testdata/reciprocal/bin/bin1.meta_lic", "testdata/reciprocal/bin/bin2.meta_lic"}},
+		{name: "restricted", roots: []string{"testdata/restricted/container.zip.meta_lic"}},
+		{name: "escaping", roots: []string{"testdata/regressescape/bin/bin1.meta_lic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			var deps []string
+
+			ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, "", false, schema, false, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+			err := xmlNotice(&ctx, tt.roots...)
+			if err != nil {
+				t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+			}
+			if !strings.HasPrefix(stdout.String(), "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n") {
+				t.Errorf("xmlnotice: got no utf-8 xml declaration, want declaration")
+			}
+
+			var notice noticeXML
+			err = xml.Unmarshal(stdout.Bytes(), &notice)
+			if err != nil {
+				t.Fatalf("xmlnotice: cannot unmarshal output: %v", err)
+			}
+			if len(notice.FileNames) == 0 || len(notice.FileContents) == 0 {
+				t.Fatalf("xmlnotice: got %d file-name and %d file-content elements, want some of each", len(notice.FileNames), len(notice.FileContents))
+			}
+			contents := make(map[string]struct{})
+			for _, fc := range notice.FileContents {
+				contents[fc.ContentID] = struct{}{}
+			}
+			for _, fn := range notice.FileNames {
+				if _, ok := contents[fn.ContentID]; !ok {
+					t.Errorf("xmlnotice: got file-name %q with contentId %q, want a matching file-content", fn.Path, fn.ContentID)
+				}
+			}
+
+			// Marshaling and unmarshaling again must neither lose nor
+			// change anything, and the result must still conform.
+			data, err := xml.Marshal(notice)
+			if err != nil {
+				t.Fatalf("xmlnotice: cannot marshal output: %v", err)
+			}
+			var again noticeXML
+			err = xml.Unmarshal(data, &again)
+			if err != nil {
+				t.Fatalf("xmlnotice: cannot unmarshal marshaled output: %v", err)
+			}
+			if !reflect.DeepEqual(again, notice) {
+				t.Errorf("xmlnotice: got %+v after round trip, want %+v", again, notice)
+			}
+			err = schema.Validate(bytes.NewReader(data))
+			if err != nil {
+				t.Errorf("xmlnotice: got round trip output not conforming to schema: %v", err)
+			}
+		})
+	}
+}
+
+func TestXMLSchemaMismatch(t *testing.T) {
+	schema, err := compliance.ReadXMLSchema(strings.NewReader(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="licenses">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="file-name" type="xs:string" maxOccurs="unbounded"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	ctx := context{stdout, stderr, compliance.GetFS(""), "", nil, "", false, schema, false, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+	err = xmlNotice(&ctx, "testdata/notice/bin/bin1.meta_lic")
+	if err == nil || !strings.Contains(err.Error(), "does not conform to xml schema") {
+		t.Errorf("xmlnotice: got error %v, want schema conformance error", err)
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("xmlnotice: got output %q for non-conforming notice, want none", stdout)
+	}
+}
+
+func TestInvalidXMLText(t *testing.T) {
+	licenseText, err := os.ReadFile("testdata/regresscdata/CDATA_LICENSE")
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	// Characters invalid in XML 1.0 become U+FFFD.
+	wantText := strings.NewReplacer("\x0c", "�", "\x01", "�").Replace(string(licenseText))
+	wantLib := "CDATA ]]> Lib"
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, "", false, nil, false, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+	err = xmlNotice(&ctx, "testdata/regresscdata/bin/bin1.meta_lic")
+	if err != nil {
+		t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+	}
+	t.Logf("got stdout: %s", stdout.String())
+
+	var notice struct {
+		FileNames []struct {
+			Lib  string `xml:"lib,attr"`
+			Path string `xml:",chardata"`
+		} `xml:"file-name"`
+		FileContents []struct {
+			Text string `xml:",chardata"`
+		} `xml:"file-content"`
+	}
+	err = xml.Unmarshal(stdout.Bytes(), &notice)
+	if err != nil {
+		t.Fatalf("xmlnotice: cannot unmarshal output: %v", err)
+	}
+	if len(notice.FileNames) != 1 || notice.FileNames[0].Lib != wantLib || notice.FileNames[0].Path != "system/bin/bin1" {
+		t.Errorf("xmlnotice: got file names %+v, want lib %q at %q", notice.FileNames, wantLib, "system/bin/bin1")
+	}
+	if len(notice.FileContents) != 1 {
+		t.Fatalf("xmlnotice: got %d file contents, want 1", len(notice.FileContents))
+	}
+	if actual := notice.FileContents[0].Text; actual != wantText {
+		t.Errorf("xmlnotice: got text %q, want %q", actual, wantText)
+	}
+}
+
+func TestConditionAttribute(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	var deps []string
+
+	ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/data/"}, "", false, nil, false, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+	err := xmlNotice(&ctx, "testdata/restricted/container.zip.meta_lic")
+	if err != nil {
+		t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+	}
+
+	var notice struct {
+		FileNames []struct {
+			Lib       string `xml:"lib,attr"`
+			Condition string `xml:"condition,attr"`
+			Path      string `xml:",chardata"`
+		} `xml:"file-name"`
+	}
+	err = xml.Unmarshal(stdout.Bytes(), &notice)
+	if err != nil {
+		t.Fatalf("xmlnotice: cannot unmarshal output: %v", err)
+	}
+
+	// actual maps install paths and library names to condition attributes.
+	actual := make(map[string][]string)
+	for _, fn := range notice.FileNames {
+		conditions := strings.Split(fn.Condition, ",")
+		if !sort.StringsAreSorted(conditions) {
+			t.Errorf("xmlnotice: got unsorted condition attribute %q, want sorted", fn.Condition)
+		}
+		key := fn.Path + " " + fn.Lib
+		actual[key] = append(actual[key], fn.Condition)
+	}
+	for _, conditions := range actual {
+		sort.Strings(conditions)
+	}
+	expected := map[string][]string{
+		"container.zip Android":         {"notice"},
+		"container.zip/bin1 Android":    {"notice,restricted_if_statically_linked"},
+		"container.zip/bin1 Device":     {"restricted_if_statically_linked"},
+		"container.zip/bin1 External":   {"reciprocal,restricted_if_statically_linked"},
+		"container.zip/bin2 Android":    {"notice,restricted", "restricted"},
+		"container.zip/liba.so Device":  {"restricted_if_statically_linked"},
+		"container.zip/libb.so Android": {"restricted"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("xmlnotice: got conditions %v, want %v", actual, expected)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		name  string
+		roots []string
+	}{
+		{"notice", []string{"testdata/notice/application.meta_lic"}},
+		{"restricted", []string{"testdata/restricted/container.zip.meta_lic"}},
+		{"invalid", []string{"testdata/regresscdata/bin/bin1.meta_lic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			var deps []string
+
+			ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, "", false, nil, false, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+			err := xmlNotice(&ctx, tt.roots...)
+			if err != nil {
+				t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+			}
+
+			var notice struct {
+				FileContents []struct {
+					ContentID string `xml:"contentId,attr"`
+					Hash      string `xml:"hash,attr"`
+					Text      string `xml:",chardata"`
+				} `xml:"file-content"`
+			}
+			err = xml.Unmarshal(stdout.Bytes(), &notice)
+			if err != nil {
+				t.Fatalf("xmlnotice: cannot unmarshal output: %v", err)
+			}
+			if len(notice.FileContents) == 0 {
+				t.Fatalf("xmlnotice: got no file-content elements, want some")
+			}
+			for _, fc := range notice.FileContents {
+				if expected := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(fc.Text))); fc.Hash != expected {
+					t.Errorf("xmlnotice: got hash %q for contentId %q, want %q", fc.Hash, fc.ContentID, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestByTarget(t *testing.T) {
+	for _, condition := range []string{"firstparty", "notice", "reciprocal", "restricted", "proprietary"} {
+		t.Run(condition, func(t *testing.T) {
+			// notice returns the output of xmlnotice for the apex with or
+			// without -by_target.
+			notice := func(byTarget bool) []byte {
+				stdout := &bytes.Buffer{}
+				stderr := &bytes.Buffer{}
+
+				var deps []string
+
+				ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, "", false, nil, false, byTarget, 1, false, nil, nil, nil, 0, false, &deps}
+
+				err := xmlNotice(&ctx, "testdata/"+condition+"/highest.apex.meta_lic")
+				if err != nil {
+					t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+				}
+				return stdout.Bytes()
+			}
+
+			type fileContent struct {
+				ContentID string `xml:"contentId,attr"`
+				Hash      string `xml:"hash,attr"`
+				Text      string `xml:",chardata"`
+			}
+			var byLicense struct {
+				FileNames []struct {
+					ContentID string `xml:"contentId,attr"`
+					Lib       string `xml:"lib,attr"`
+					Condition string `xml:"condition,attr"`
+					Path      string `xml:",chardata"`
+				} `xml:"file-name"`
+				FileContents []fileContent `xml:"file-content"`
+			}
+			var byTarget struct {
+				Files []struct {
+					Path     string `xml:"path,attr"`
+					Licenses []struct {
+						ContentID string `xml:"contentId,attr"`
+						Lib       string `xml:"lib,attr"`
+						Condition string `xml:"condition,attr"`
+					} `xml:"license"`
+				} `xml:"file"`
+				FileContents []fileContent `xml:"file-content"`
+			}
+			if err := xml.Unmarshal(notice(false), &byLicense); err != nil {
+				t.Fatalf("xmlnotice: cannot unmarshal output: %v", err)
+			}
+			if err := xml.Unmarshal(notice(true), &byTarget); err != nil {
+				t.Fatalf("xmlnotice: cannot unmarshal -by_target output: %v", err)
+			}
+
+			var expected, actual []string
+			for _, fn := range byLicense.FileNames {
+				expected = append(expected, strings.Join([]string{fn.Path, fn.ContentID, fn.Lib, fn.Condition}, " "))
+			}
+			paths := make(map[string]bool)
+			for _, f := range byTarget.Files {
+				if paths[f.Path] {
+					t.Errorf("xmlnotice: got path %q more than once with -by_target, want once", f.Path)
+				}
+				paths[f.Path] = true
+				for _, l := range f.Licenses {
+					actual = append(actual, strings.Join([]string{f.Path, l.ContentID, l.Lib, l.Condition}, " "))
+				}
+			}
+			if len(expected) == 0 {
+				t.Fatalf("xmlnotice: got no file-name elements, want some")
+			}
+			sort.Strings(expected)
+			sort.Strings(actual)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("xmlnotice: got -by_target licenses:\n%s\nwant:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+			}
+			if !reflect.DeepEqual(byTarget.FileContents, byLicense.FileContents) {
+				t.Errorf("xmlnotice: got -by_target file contents %+v, want %+v", byTarget.FileContents, byLicense.FileContents)
+			}
+		})
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	// notice returns the output of xmlnotice for `version` and `namespace`.
+	notice := func(version int, namespace bool, roots ...string) []byte {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		var deps []string
+
+		ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, "", false, nil, false, false, version, namespace, nil, nil, nil, 0, false, &deps}
+
+		err := xmlNotice(&ctx, roots...)
+		if err != nil {
+			t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+		}
+		return stdout.Bytes()
+	}
+
+	t.Run("version 1 unchanged", func(t *testing.T) {
+		expected, err := os.ReadFile("testdata/notice/highest.apex.v1.xml")
+		if err != nil {
+			t.Fatalf("cannot read golden output: %v", err)
+		}
+		actual := notice(formatVersion1, false, "testdata/notice/highest.apex.meta_lic")
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("xmlnotice: got version 1 output:\n%s\nwant:\n%s", actual, expected)
+		}
+	})
+
+	roots := []string{"testdata/restricted/container.zip.meta_lic", "testdata/regresscdata/bin/bin1.meta_lic"}
+	v1 := notice(formatVersion1, false, roots...)
+
+	for _, tt := range []struct {
+		name      string
+		namespace bool
+		root      string
+	}{
+		{"version 2", false, `<licenses version="2">`},
+		{"version 2 with namespace", true, `<licenses xmlns="` + noticeNamespace + `" version="2">`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v2 := notice(formatVersion2, tt.namespace, roots...)
+			if !bytes.Contains(v2, []byte("\n"+tt.root+"\n")) {
+				t.Fatalf("xmlnotice: got output without root element %q:\n%s", tt.root, v2)
+			}
+			actual := bytes.Replace(v2, []byte(tt.root), []byte("<licenses>"), 1)
+			if !bytes.Equal(actual, v1) {
+				t.Errorf("xmlnotice: got version 2 body:\n%s\nwant version 1 body:\n%s", actual, v1)
+			}
+
+			tree, err := parseTree(v2)
+			if err != nil {
+				t.Fatalf("xmlnotice: cannot parse version 2 output: %v", err)
+			}
+			space := ""
+			if tt.namespace {
+				space = noticeNamespace
+			}
+			if tree.Name.Space != space {
+				t.Errorf("xmlnotice: got root namespace %q, want %q", tree.Name.Space, space)
+			}
+			version := ""
+			for _, attr := range tree.Attrs {
+				if attr.Name.Local == "version" {
+					version = attr.Value
+				}
+			}
+			if version != "2" {
+				t.Errorf("xmlnotice: got root version %q, want \"2\"", version)
+			}
+		})
+	}
+}
+
+func TestPretty(t *testing.T) {
+	roots := []string{"testdata/restricted/container.zip.meta_lic", "testdata/regresscdata/bin/bin1.meta_lic"}
+
+	// notice returns the output of xmlnotice with or without -pretty.
+	notice := func(pretty bool) []byte {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		var deps []string
+
+		ctx := context{stdout, stderr, compliance.GetFS(""), "", []string{"out/target/product/fictional/"}, "", false, nil, pretty, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+		err := xmlNotice(&ctx, roots...)
+		if err != nil {
+			t.Fatalf("xmlnotice: error = %v, stderr = %v", err, stderr)
+		}
+		return stdout.Bytes()
+	}
+	compact := notice(false)
+	pretty := notice(true)
+
+	if bytes.Equal(compact, pretty) {
+		t.Errorf("xmlnotice: got identical output with -pretty, want indentation")
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(pretty)), "\n") {
+		if strings.HasPrefix(line, "<file-") {
+			t.Errorf("xmlnotice: got unindented element %q with -pretty, want indented", line)
+		}
+		if strings.Count(line, "<file-name ") > 1 {
+			t.Errorf("xmlnotice: got several file-name elements on line %q, want one per line", line)
+		}
+	}
+
+	expected, err := parseTree(compact)
+	if err != nil {
+		t.Fatalf("xmlnotice: cannot parse compact output: %v", err)
+	}
+	actual, err := parseTree(pretty)
+	if err != nil {
+		t.Fatalf("xmlnotice: cannot parse pretty output: %v", err)
+	}
+	if len(expected.Children) == 0 {
+		t.Fatalf("xmlnotice: got no elements in compact output, want elements")
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("xmlnotice: got pretty tree %+v, want %+v", actual, expected)
+	}
+}
+
+// xmlTree is an xml element with the whitespace between elements removed.
+type xmlTree struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Text     string
+	Children []*xmlTree
+}
+
+// parseTree parses the xml document in `data` into an xmlTree.
+func parseTree(data []byte) (*xmlTree, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlTree
+	var root *xmlTree
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e := &xmlTree{Name: tok.Name, Attrs: tok.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, e)
+			} else {
+				root = e
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 && len(strings.TrimSpace(string(tok))) > 0 {
+				stack[len(stack)-1].Text += string(tok)
+			}
+		}
+	}
+}
+
+// BenchmarkXMLNotice50k compares streaming a notice for a synthetic 50k-target
+// graph to io.Discard with buffering the whole document as xmlnotice used to.
+func BenchmarkXMLNotice50k(b *testing.B) {
+	const targets = 50000
+
+	// Use a real directory because fstest.MapFS takes time proportional to
+	// the number of files to look up a missing file.
+	dir := b.TempDir()
+	for _, d := range []string{"lib", "licenses"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0777); err != nil {
+			b.Fatalf("cannot create test data: %v", err)
+		}
+	}
+	roots := make([]string, 0, targets)
+	for i := 0; i < targets; i++ {
+		name := fmt.Sprintf("lib/lib%d.so.meta_lic", i)
+		roots = append(roots, name)
+		metadata := fmt.Sprintf("package_name: \"lib%d\"\nlicense_conditions: \"notice\"\nlicense_texts: \"licenses/LICENSE%d\"\ninstalled: \"out/target/product/fictional/system/lib/lib%d.so\"\n", i, i, i)
+		text := fmt.Sprintf("Copyright (C) 2026 Library %d\n%s", i, strings.Repeat("Permission is hereby granted, free of charge.\n", 20))
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(metadata), 0666); err != nil {
+			b.Fatalf("cannot create test data: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("licenses/LICENSE%d", i)), []byte(text), 0666); err != nil {
+			b.Fatalf("cannot create test data: %v", err)
+		}
+	}
+	rootFS := os.DirFS(dir)
+
+	for _, bm := range []struct {
+		name   string
+		output func() io.Writer
+	}{
+		{"stream", func() io.Writer { return io.Discard }},
+		{"buffer", func() io.Writer { return &bytes.Buffer{} }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var deps []string
+
+				ctx := context{bm.output(), io.Discard, rootFS, "", nil, "", false, nil, false, false, 1, false, nil, nil, nil, 0, false, &deps}
+
+				if err := xmlNotice(&ctx, roots...); err != nil {
+					b.Fatalf("xmlnotice: error = %v", err)
+				}
+			}
+		})
+	}
+}
+
 func escape(s string) string {
 	b := &bytes.Buffer{}
 	xml.EscapeText(b, []byte(s))
@@ -694,7 +1354,7 @@ func matchesText(line, text string) bool {
 }
 
 func expectedText(text string) string {
-	return `<file-content contentId="hash"><![CDATA[` + escape(text + "\n") + `]]></file-content>`
+	return `<file-content contentId="hash" hash="sha256:hash">` + escape(text + "\n") + `</file-content>`
 }
 
 type firstParty struct{}
@@ -0,0 +1,688 @@
+// Copyright (C) 2026 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The license graph query service served by complianced and called by
+// compliancectl.
+//
+// Field numbers are part of the binary wire format. Never renumber or reuse a
+// field number: add new fields with the next unused number, and when removing
+// a field, reserve both its number and its name so that old clients and
+// servers keep working.
+//
+// Run regen.sh after changing this file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        (unknown)
+// source: compliance_proto/compliance.proto
+
+package compliance_proto
+
+import (
+	notice_proto "android/soong/tools/compliance/notice_proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Next field number: 2
+type BuildGraphRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The root license metadata files relative to the server's root directory.
+	RootFiles []string `protobuf:"bytes,1,rep,name=root_files,json=rootFiles" json:"root_files,omitempty"`
+}
+
+func (x *BuildGraphRequest) Reset() {
+	*x = BuildGraphRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BuildGraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildGraphRequest) ProtoMessage() {}
+
+func (x *BuildGraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildGraphRequest.ProtoReflect.Descriptor instead.
+func (*BuildGraphRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BuildGraphRequest) GetRootFiles() []string {
+	if x != nil {
+		return x.RootFiles
+	}
+	return nil
+}
+
+// Next field number: 5
+type BuildGraphResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// "sha256:" followed by the hex digest of the sorted distinct root files.
+	// Identifies the cache entry for the graph.
+	GraphId *string `protobuf:"bytes,1,opt,name=graph_id,json=graphId" json:"graph_id,omitempty"`
+	// The number of targets in the graph.
+	Targets *int32 `protobuf:"varint,2,opt,name=targets" json:"targets,omitempty"`
+	// The number of edges in the graph.
+	Edges *int32 `protobuf:"varint,3,opt,name=edges" json:"edges,omitempty"`
+	// Whether the graph came from the cache.
+	Cached *bool `protobuf:"varint,4,opt,name=cached" json:"cached,omitempty"`
+}
+
+func (x *BuildGraphResponse) Reset() {
+	*x = BuildGraphResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BuildGraphResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuildGraphResponse) ProtoMessage() {}
+
+func (x *BuildGraphResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuildGraphResponse.ProtoReflect.Descriptor instead.
+func (*BuildGraphResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BuildGraphResponse) GetGraphId() string {
+	if x != nil && x.GraphId != nil {
+		return *x.GraphId
+	}
+	return ""
+}
+
+func (x *BuildGraphResponse) GetTargets() int32 {
+	if x != nil && x.Targets != nil {
+		return *x.Targets
+	}
+	return 0
+}
+
+func (x *BuildGraphResponse) GetEdges() int32 {
+	if x != nil && x.Edges != nil {
+		return *x.Edges
+	}
+	return 0
+}
+
+func (x *BuildGraphResponse) GetCached() bool {
+	if x != nil && x.Cached != nil {
+		return *x.Cached
+	}
+	return false
+}
+
+// Next field number: 5
+type GetNoticeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The root license metadata files relative to the server's root directory.
+	RootFiles []string `protobuf:"bytes,1,rep,name=root_files,json=rootFiles" json:"root_files,omitempty"`
+	// The name of the product for the notice if any.
+	Product *string `protobuf:"bytes,2,opt,name=product" json:"product,omitempty"`
+	// Prefixes to strip from the install paths.
+	StripPrefix []string `protobuf:"bytes,3,rep,name=strip_prefix,json=stripPrefix" json:"strip_prefix,omitempty"`
+	// Omit the license texts and report only their hashes.
+	NoTexts *bool `protobuf:"varint,4,opt,name=no_texts,json=noTexts" json:"no_texts,omitempty"`
+}
+
+func (x *GetNoticeRequest) Reset() {
+	*x = GetNoticeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNoticeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNoticeRequest) ProtoMessage() {}
+
+func (x *GetNoticeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNoticeRequest.ProtoReflect.Descriptor instead.
+func (*GetNoticeRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetNoticeRequest) GetRootFiles() []string {
+	if x != nil {
+		return x.RootFiles
+	}
+	return nil
+}
+
+func (x *GetNoticeRequest) GetProduct() string {
+	if x != nil && x.Product != nil {
+		return *x.Product
+	}
+	return ""
+}
+
+func (x *GetNoticeRequest) GetStripPrefix() []string {
+	if x != nil {
+		return x.StripPrefix
+	}
+	return nil
+}
+
+func (x *GetNoticeRequest) GetNoTexts() bool {
+	if x != nil && x.NoTexts != nil {
+		return *x.NoTexts
+	}
+	return false
+}
+
+// Next field number: 2
+type GetNoticeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notice *notice_proto.Notice `protobuf:"bytes,1,opt,name=notice" json:"notice,omitempty"`
+}
+
+func (x *GetNoticeResponse) Reset() {
+	*x = GetNoticeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNoticeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNoticeResponse) ProtoMessage() {}
+
+func (x *GetNoticeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNoticeResponse.ProtoReflect.Descriptor instead.
+func (*GetNoticeResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetNoticeResponse) GetNotice() *notice_proto.Notice {
+	if x != nil {
+		return x.Notice
+	}
+	return nil
+}
+
+// Next field number: 2
+type CheckPolicyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The root license metadata files relative to the server's root directory.
+	RootFiles []string `protobuf:"bytes,1,rep,name=root_files,json=rootFiles" json:"root_files,omitempty"`
+}
+
+func (x *CheckPolicyRequest) Reset() {
+	*x = CheckPolicyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckPolicyRequest) ProtoMessage() {}
+
+func (x *CheckPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckPolicyRequest.ProtoReflect.Descriptor instead.
+func (*CheckPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckPolicyRequest) GetRootFiles() []string {
+	if x != nil {
+		return x.RootFiles
+	}
+	return nil
+}
+
+// Next field number: 2
+type CheckPolicyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The conflicts ordered by target then conditions. Empty when policy
+	// allows sharing all of the source that must be shared.
+	Conflicts []*Conflict `protobuf:"bytes,1,rep,name=conflicts" json:"conflicts,omitempty"`
+}
+
+func (x *CheckPolicyResponse) Reset() {
+	*x = CheckPolicyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckPolicyResponse) ProtoMessage() {}
+
+func (x *CheckPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckPolicyResponse.ProtoReflect.Descriptor instead.
+func (*CheckPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckPolicyResponse) GetConflicts() []*Conflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+// Conflict describes a target with conditions both to share and to keep
+// private its source.
+//
+// Next field number: 4
+type Conflict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the license metadata file for the target.
+	Target *string `protobuf:"bytes,1,opt,name=target" json:"target,omitempty"`
+	// The license condition with a source privacy policy.
+	// e.g. "proprietary"
+	PrivacyCondition *string `protobuf:"bytes,2,opt,name=privacy_condition,json=privacyCondition" json:"privacy_condition,omitempty"`
+	// The license condition with a source sharing policy.
+	// e.g. "restricted"
+	ShareCondition *string `protobuf:"bytes,3,opt,name=share_condition,json=shareCondition" json:"share_condition,omitempty"`
+}
+
+func (x *Conflict) Reset() {
+	*x = Conflict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_compliance_proto_compliance_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Conflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conflict) ProtoMessage() {}
+
+func (x *Conflict) ProtoReflect() protoreflect.Message {
+	mi := &file_compliance_proto_compliance_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conflict.ProtoReflect.Descriptor instead.
+func (*Conflict) Descriptor() ([]byte, []int) {
+	return file_compliance_proto_compliance_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Conflict) GetTarget() string {
+	if x != nil && x.Target != nil {
+		return *x.Target
+	}
+	return ""
+}
+
+func (x *Conflict) GetPrivacyCondition() string {
+	if x != nil && x.PrivacyCondition != nil {
+		return *x.PrivacyCondition
+	}
+	return ""
+}
+
+func (x *Conflict) GetShareCondition() string {
+	if x != nil && x.ShareCondition != nil {
+		return *x.ShareCondition
+	}
+	return ""
+}
+
+var File_compliance_proto_compliance_proto protoreflect.FileDescriptor
+
+var file_compliance_proto_compliance_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x5f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x32, 0x0a, 0x11, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x47, 0x72, 0x61, 0x70, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x46,
+	0x69, 0x6c, 0x65, 0x73, 0x22, 0x77, 0x0a, 0x12, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x47, 0x72, 0x61,
+	0x70, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x67, 0x72,
+	0x61, 0x70, 0x68, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72,
+	0x61, 0x70, 0x68, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x64, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x65, 0x64, 0x67, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x63, 0x61, 0x63, 0x68, 0x65, 0x64, 0x22, 0x89, 0x01,
+	0x0a, 0x10, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x65,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73,
+	0x74, 0x72, 0x69, 0x70, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x70, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x19,
+	0x0a, 0x08, 0x6e, 0x6f, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x6e, 0x6f, 0x54, 0x65, 0x78, 0x74, 0x73, 0x22, 0x41, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x4e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c,
+	0x0a, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4e, 0x6f,
+	0x74, 0x69, 0x63, 0x65, 0x52, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x22, 0x33, 0x0a, 0x12,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x65,
+	0x73, 0x22, 0x4f, 0x0a, 0x13, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x66,
+	0x6c, 0x69, 0x63, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43,
+	0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63,
+	0x74, 0x73, 0x22, 0x78, 0x0a, 0x08, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x70, 0x72, 0x69, 0x76, 0x61, 0x63,
+	0x79, 0x5f, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x10, 0x70, 0x72, 0x69, 0x76, 0x61, 0x63, 0x79, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x5f, 0x63, 0x6f, 0x6e,
+	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x32, 0x97, 0x02, 0x0a,
+	0x0a, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x57, 0x0a, 0x0a, 0x42,
+	0x75, 0x69, 0x6c, 0x64, 0x47, 0x72, 0x61, 0x70, 0x68, 0x12, 0x23, 0x2e, 0x63, 0x6f, 0x6d, 0x70,
+	0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x42, 0x75, 0x69,
+	0x6c, 0x64, 0x47, 0x72, 0x61, 0x70, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24,
+	0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x47, 0x72, 0x61, 0x70, 0x68, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x63,
+	0x65, 0x12, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74, 0x69,
+	0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0b, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x24, 0x2e, 0x63, 0x6f, 0x6d, 0x70,
+	0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x25, 0x2e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69,
+	0x64, 0x2f, 0x73, 0x6f, 0x6f, 0x6e, 0x67, 0x2f, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2f, 0x63, 0x6f,
+	0x6d, 0x70, 0x6c, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61,
+	0x6e, 0x63, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+}
+
+var (
+	file_compliance_proto_compliance_proto_rawDescOnce sync.Once
+	file_compliance_proto_compliance_proto_rawDescData = file_compliance_proto_compliance_proto_rawDesc
+)
+
+func file_compliance_proto_compliance_proto_rawDescGZIP() []byte {
+	file_compliance_proto_compliance_proto_rawDescOnce.Do(func() {
+		file_compliance_proto_compliance_proto_rawDescData = protoimpl.X.CompressGZIP(file_compliance_proto_compliance_proto_rawDescData)
+	})
+	return file_compliance_proto_compliance_proto_rawDescData
+}
+
+var file_compliance_proto_compliance_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_compliance_proto_compliance_proto_goTypes = []interface{}{
+	(*BuildGraphRequest)(nil),   // 0: compliance_proto.BuildGraphRequest
+	(*BuildGraphResponse)(nil),  // 1: compliance_proto.BuildGraphResponse
+	(*GetNoticeRequest)(nil),    // 2: compliance_proto.GetNoticeRequest
+	(*GetNoticeResponse)(nil),   // 3: compliance_proto.GetNoticeResponse
+	(*CheckPolicyRequest)(nil),  // 4: compliance_proto.CheckPolicyRequest
+	(*CheckPolicyResponse)(nil), // 5: compliance_proto.CheckPolicyResponse
+	(*Conflict)(nil),            // 6: compliance_proto.Conflict
+	(*notice_proto.Notice)(nil), // 7: notice_proto.Notice
+}
+var file_compliance_proto_compliance_proto_depIdxs = []int32{
+	7, // 0: compliance_proto.GetNoticeResponse.notice:type_name -> notice_proto.Notice
+	6, // 1: compliance_proto.CheckPolicyResponse.conflicts:type_name -> compliance_proto.Conflict
+	0, // 2: compliance_proto.Compliance.BuildGraph:input_type -> compliance_proto.BuildGraphRequest
+	2, // 3: compliance_proto.Compliance.GetNotice:input_type -> compliance_proto.GetNoticeRequest
+	4, // 4: compliance_proto.Compliance.CheckPolicy:input_type -> compliance_proto.CheckPolicyRequest
+	1, // 5: compliance_proto.Compliance.BuildGraph:output_type -> compliance_proto.BuildGraphResponse
+	3, // 6: compliance_proto.Compliance.GetNotice:output_type -> compliance_proto.GetNoticeResponse
+	5, // 7: compliance_proto.Compliance.CheckPolicy:output_type -> compliance_proto.CheckPolicyResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_compliance_proto_compliance_proto_init() }
+func file_compliance_proto_compliance_proto_init() {
+	if File_compliance_proto_compliance_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_compliance_proto_compliance_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuildGraphRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_compliance_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuildGraphResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_compliance_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetNoticeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_compliance_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetNoticeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_compliance_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckPolicyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_compliance_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckPolicyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_compliance_proto_compliance_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Conflict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_compliance_proto_compliance_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_compliance_proto_compliance_proto_goTypes,
+		DependencyIndexes: file_compliance_proto_compliance_proto_depIdxs,
+		MessageInfos:      file_compliance_proto_compliance_proto_msgTypes,
+	}.Build()
+	File_compliance_proto_compliance_proto = out.File
+	file_compliance_proto_compliance_proto_rawDesc = nil
+	file_compliance_proto_compliance_proto_goTypes = nil
+	file_compliance_proto_compliance_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2026 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The license graph query service served by complianced and called by
+// compliancectl.
+//
+// Field numbers are part of the binary wire format. Never renumber or reuse a
+// field number: add new fields with the next unused number, and when removing
+// a field, reserve both its number and its name so that old clients and
+// servers keep working.
+//
+// Run regen.sh after changing this file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: compliance_proto/compliance.proto
+
+package compliance_proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Compliance_BuildGraph_FullMethodName  = "/compliance_proto.Compliance/BuildGraph"
+	Compliance_GetNotice_FullMethodName   = "/compliance_proto.Compliance/GetNotice"
+	Compliance_CheckPolicy_FullMethodName = "/compliance_proto.Compliance/CheckPolicy"
+)
+
+// ComplianceClient is the client API for Compliance service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ComplianceClient interface {
+	// BuildGraph reads the license graph for the root files into the cache.
+	BuildGraph(ctx context.Context, in *BuildGraphRequest, opts ...grpc.CallOption) (*BuildGraphResponse, error)
+	// GetNotice returns the notice for the root files.
+	GetNotice(ctx context.Context, in *GetNoticeRequest, opts ...grpc.CallOption) (*GetNoticeResponse, error)
+	// CheckPolicy returns the targets where policy says that the source both
+	// must and must not be shared.
+	CheckPolicy(ctx context.Context, in *CheckPolicyRequest, opts ...grpc.CallOption) (*CheckPolicyResponse, error)
+}
+
+type complianceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewComplianceClient(cc grpc.ClientConnInterface) ComplianceClient {
+	return &complianceClient{cc}
+}
+
+func (c *complianceClient) BuildGraph(ctx context.Context, in *BuildGraphRequest, opts ...grpc.CallOption) (*BuildGraphResponse, error) {
+	out := new(BuildGraphResponse)
+	err := c.cc.Invoke(ctx, Compliance_BuildGraph_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *complianceClient) GetNotice(ctx context.Context, in *GetNoticeRequest, opts ...grpc.CallOption) (*GetNoticeResponse, error) {
+	out := new(GetNoticeResponse)
+	err := c.cc.Invoke(ctx, Compliance_GetNotice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *complianceClient) CheckPolicy(ctx context.Context, in *CheckPolicyRequest, opts ...grpc.CallOption) (*CheckPolicyResponse, error) {
+	out := new(CheckPolicyResponse)
+	err := c.cc.Invoke(ctx, Compliance_CheckPolicy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ComplianceServer is the server API for Compliance service.
+// All implementations must embed UnimplementedComplianceServer
+// for forward compatibility
+type ComplianceServer interface {
+	// BuildGraph reads the license graph for the root files into the cache.
+	BuildGraph(context.Context, *BuildGraphRequest) (*BuildGraphResponse, error)
+	// GetNotice returns the notice for the root files.
+	GetNotice(context.Context, *GetNoticeRequest) (*GetNoticeResponse, error)
+	// CheckPolicy returns the targets where policy says that the source both
+	// must and must not be shared.
+	CheckPolicy(context.Context, *CheckPolicyRequest) (*CheckPolicyResponse, error)
+	mustEmbedUnimplementedComplianceServer()
+}
+
+// UnimplementedComplianceServer must be embedded to have forward compatible implementations.
+type UnimplementedComplianceServer struct {
+}
+
+func (UnimplementedComplianceServer) BuildGraph(context.Context, *BuildGraphRequest) (*BuildGraphResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildGraph not implemented")
+}
+func (UnimplementedComplianceServer) GetNotice(context.Context, *GetNoticeRequest) (*GetNoticeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotice not implemented")
+}
+func (UnimplementedComplianceServer) CheckPolicy(context.Context, *CheckPolicyRequest) (*CheckPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPolicy not implemented")
+}
+func (UnimplementedComplianceServer) mustEmbedUnimplementedComplianceServer() {}
+
+// UnsafeComplianceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ComplianceServer will
+// result in compilation errors.
+type UnsafeComplianceServer interface {
+	mustEmbedUnimplementedComplianceServer()
+}
+
+func RegisterComplianceServer(s grpc.ServiceRegistrar, srv ComplianceServer) {
+	s.RegisterService(&Compliance_ServiceDesc, srv)
+}
+
+func _Compliance_BuildGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuildGraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServer).BuildGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Compliance_BuildGraph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServer).BuildGraph(ctx, req.(*BuildGraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Compliance_GetNotice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNoticeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServer).GetNotice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Compliance_GetNotice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServer).GetNotice(ctx, req.(*GetNoticeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Compliance_CheckPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComplianceServer).CheckPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Compliance_CheckPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComplianceServer).CheckPolicy(ctx, req.(*CheckPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Compliance_ServiceDesc is the grpc.ServiceDesc for Compliance service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Compliance_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "compliance_proto.Compliance",
+	HandlerType: (*ComplianceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BuildGraph",
+			Handler:    _Compliance_BuildGraph_Handler,
+		},
+		{
+			MethodName: "GetNotice",
+			Handler:    _Compliance_GetNotice_Handler,
+		},
+		{
+			MethodName: "CheckPolicy",
+			Handler:    _Compliance_CheckPolicy_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "compliance_proto/compliance.proto",
+}
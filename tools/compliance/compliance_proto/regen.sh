@@ -0,0 +1,3 @@
+#!/bin/bash
+
+cd "$(dirname "$0")/.." && aprotoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. compliance_proto/compliance.proto
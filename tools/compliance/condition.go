@@ -22,8 +22,9 @@ import (
 // corresponding bit.
 type LicenseCondition uint16
 
-// LicenseConditionMask is a bitmask for the recognized license conditions.
-const LicenseConditionMask = LicenseCondition(0x1ff)
+// LicenseConditionMask is a bitmask for the built-in and registered license
+// conditions.
+const LicenseConditionMask = LicenseCondition(0xffff)
 
 const (
 	// UnencumberedCondition identifies public domain or public domain-
@@ -53,10 +54,16 @@ const (
 	// NotAllowedCondition identifies a license with onerous conditions
 	// where policy prohibits use.
 	NotAllowedCondition = LicenseCondition(0x0100)
+	// DynamicLinkWarningCondition identifies a target linking a restricted
+	// library dynamically, which policy flags for review rather than
+	// treating as a derivative work.
+	DynamicLinkWarningCondition = LicenseCondition(0x0200)
 )
 
 var (
-	// RecognizedConditionNames maps condition strings to LicenseCondition.
+	// RecognizedConditionNames maps the built-in condition strings to
+	// LicenseCondition. Use Conditions.Condition to look up registered
+	// conditions too.
 	RecognizedConditionNames = map[string]LicenseCondition{
 		"unencumbered":                    UnencumberedCondition,
 		"permissive":                      PermissiveCondition,
@@ -67,30 +74,14 @@ var (
 		"proprietary":                     ProprietaryCondition,
 		"by_exception_only":               ByExceptionOnlyCondition,
 		"not_allowed":                     NotAllowedCondition,
+		"dynamic_link_warning":            DynamicLinkWarningCondition,
 	}
 )
 
 // Name returns the condition string corresponding to the LicenseCondition.
 func (lc LicenseCondition) Name() string {
-	switch lc {
-	case UnencumberedCondition:
-		return "unencumbered"
-	case PermissiveCondition:
-		return "permissive"
-	case NoticeCondition:
-		return "notice"
-	case ReciprocalCondition:
-		return "reciprocal"
-	case RestrictedCondition:
-		return "restricted"
-	case WeaklyRestrictedCondition:
-		return "restricted_if_statically_linked"
-	case ProprietaryCondition:
-		return "proprietary"
-	case ByExceptionOnlyCondition:
-		return "by_exception_only"
-	case NotAllowedCondition:
-		return "not_allowed"
+	if name, ok := Conditions.name(lc); ok {
+		return name
 	}
 	panic(fmt.Errorf("unrecognized license condition: %#v", lc))
 }
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// BuiltinLicenseConditionMask is a bitmask for the license conditions
+// recognized without registration.
+const BuiltinLicenseConditionMask = LicenseCondition(0x3ff)
+
+var (
+	// Conditions is the registry of all recognized license conditions.
+	Conditions = newConditionRegistry()
+
+	// conditionNameRe matches valid names for registered conditions.
+	conditionNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// PropagationRules describe how a license condition propagates through the
+// license graph during resolution.
+type PropagationRules struct {
+	// Static propagates the condition between targets and the dependencies
+	// they derive from, e.g. by static linking, like
+	// restricted_if_statically_linked.
+	Static bool
+	// Dynamic propagates the condition across dynamic links too, like
+	// restricted.
+	Dynamic bool
+	// Overridable drops the condition from targets inheriting it from
+	// other targets when a stricter restricted condition, whose
+	// source-sharing requirement governs instead, also applies.
+	Overridable bool
+}
+
+// ConditionRegistry records the name, sentinel and propagation rules of each
+// recognized license condition.
+//
+// Conditions must be registered before reading the license graphs that use
+// them.
+type ConditionRegistry struct {
+	mu sync.RWMutex
+
+	// conditions maps the name of each recognized condition to the condition.
+	conditions map[string]LicenseCondition
+	// names maps each recognized condition to its name.
+	names map[LicenseCondition]string
+	// sentinels maps registered conditions to the marker starting their
+	// license texts.
+	sentinels map[LicenseCondition]string
+	// next is the condition to assign to the next registration or 0 when
+	// all have been assigned. The recognized conditions are the bits below.
+	next LicenseCondition
+
+	// static and dynamic are the conditions propagating across derivations
+	// and across dynamic links. local are the registered conditions not
+	// propagating at all.
+	static, dynamic, local LicenseConditionSet
+	// overridable are the conditions that stricter conditions override.
+	overridable LicenseConditionSet
+}
+
+// newConditionRegistry returns a registry of the built-in conditions.
+func newConditionRegistry() *ConditionRegistry {
+	r := &ConditionRegistry{}
+	r.reset()
+	return r
+}
+
+// reset forgets all registered conditions.
+func (r *ConditionRegistry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conditions = make(map[string]LicenseCondition)
+	r.names = make(map[LicenseCondition]string)
+	for name, lc := range RecognizedConditionNames {
+		r.conditions[name] = lc
+		r.names[lc] = name
+	}
+	r.sentinels = make(map[LicenseCondition]string)
+	r.next = BuiltinLicenseConditionMask + 1
+	r.static = ImpliesRestricted
+	r.dynamic = LicenseConditionSet(RestrictedCondition)
+	r.local = NewLicenseConditionSet()
+	r.overridable = NewLicenseConditionSet()
+}
+
+// RegisterCondition adds the license condition `name` with license texts
+// starting with `sentinel` that propagates according to `rules`.
+//
+// Returns an error when `name` is invalid or already recognized, or when no
+// more conditions fit in a LicenseConditionSet.
+func (r *ConditionRegistry) RegisterCondition(name string, sentinel string, rules PropagationRules) error {
+	if !conditionNameRe.MatchString(name) {
+		return fmt.Errorf("invalid license condition name %q", name)
+	}
+	if rules.Dynamic && !rules.Static {
+		return fmt.Errorf("license condition %q cannot propagate across dynamic links only", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.conditions[name]; ok {
+		return fmt.Errorf("license condition %q already registered", name)
+	}
+	if r.next == 0 {
+		return fmt.Errorf("cannot register license condition %q: too many conditions", name)
+	}
+	lc := r.next
+	r.next <<= 1
+
+	r.conditions[name] = lc
+	r.names[lc] = name
+	r.sentinels[lc] = sentinel
+	switch {
+	case rules.Dynamic:
+		r.static |= LicenseConditionSet(lc)
+		r.dynamic |= LicenseConditionSet(lc)
+	case rules.Static:
+		r.static |= LicenseConditionSet(lc)
+	default:
+		r.local |= LicenseConditionSet(lc)
+	}
+	if rules.Overridable {
+		r.overridable |= LicenseConditionSet(lc)
+	}
+	return nil
+}
+
+// Condition returns the built-in or registered condition named `name`.
+func (r *ConditionRegistry) Condition(name string) (LicenseCondition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	lc, ok := r.conditions[name]
+	return lc, ok
+}
+
+// Sentinel returns the marker starting the license texts of registered
+// condition `lc` or the empty string.
+func (r *ConditionRegistry) Sentinel(lc LicenseCondition) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sentinels[lc]
+}
+
+// name returns the name of recognized condition `lc`.
+func (r *ConditionRegistry) name(lc LicenseCondition) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.names[lc]
+	return name, ok
+}
+
+// recognized returns the set of built-in and registered conditions.
+func (r *ConditionRegistry) recognized() LicenseConditionSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return LicenseConditionSet(r.next - 1)
+}
+
+// propagation returns the conditions propagating across derivations, across
+// dynamic links, and not at all, and the overridable conditions.
+func (r *ConditionRegistry) propagation() (static, dynamic, local, overridable LicenseConditionSet) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.static, r.dynamic, r.local, r.overridable
+}
@@ -0,0 +1,289 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRegisterCondition(t *testing.T) {
+	defer Conditions.reset()
+
+	if err := Conditions.RegisterCondition("commercial", "***Commercial License***", PropagationRules{Static: true}); err != nil {
+		t.Fatalf("RegisterCondition(commercial): got error %v, want none", err)
+	}
+	lc, ok := Conditions.Condition("commercial")
+	if !ok {
+		t.Fatalf("Condition(commercial): got not found, want found")
+	}
+	if lc != BuiltinLicenseConditionMask+1 {
+		t.Errorf("Condition(commercial): got %#v, want %#v", lc, BuiltinLicenseConditionMask+1)
+	}
+	if _, ok := RecognizedConditionNames["commercial"]; ok {
+		t.Errorf("RecognizedConditionNames[commercial]: got found, want only built-in conditions")
+	}
+	if name := lc.Name(); name != "commercial" {
+		t.Errorf("Name(): got %q, want %q", name, "commercial")
+	}
+	if sentinel := Conditions.Sentinel(lc); sentinel != "***Commercial License***" {
+		t.Errorf("Sentinel(): got %q, want %q", sentinel, "***Commercial License***")
+	}
+	if cs := LicenseConditionSetFromNames("notice", "commercial"); cs != NewLicenseConditionSet(NoticeCondition, lc) {
+		t.Errorf("LicenseConditionSetFromNames(notice, commercial): got %s, want {notice|commercial}", cs)
+	}
+	if names := AllLicenseConditions.Names(); names[len(names)-1] != "commercial" {
+		t.Errorf("AllLicenseConditions.Names(): got %q, want commercial last", names)
+	}
+	if n := AllLicenseConditions.Len(); n != 11 {
+		t.Errorf("AllLicenseConditions.Len(): got %d, want 11", n)
+	}
+
+	tests := []struct {
+		name        string
+		rules       PropagationRules
+		expectedErr string
+	}{
+		{"commercial", PropagationRules{}, "already registered"},
+		{"notice", PropagationRules{}, "already registered"},
+		{"Commercial", PropagationRules{}, "invalid license condition name"},
+		{"", PropagationRules{}, "invalid license condition name"},
+		{"dynamic_only", PropagationRules{Dynamic: true}, "dynamic links only"},
+	}
+	for _, tt := range tests {
+		err := Conditions.RegisterCondition(tt.name, "", tt.rules)
+		if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+			t.Errorf("RegisterCondition(%q): got error %v, want error containing %q", tt.name, err, tt.expectedErr)
+		}
+	}
+
+	for _, name := range []string{"custom1", "custom2", "custom3", "custom4", "custom5"} {
+		if err := Conditions.RegisterCondition(name, "", PropagationRules{}); err != nil {
+			t.Fatalf("RegisterCondition(%q): got error %v, want none", name, err)
+		}
+	}
+	if err := Conditions.RegisterCondition("custom6", "", PropagationRules{}); err == nil || !strings.Contains(err.Error(), "too many conditions") {
+		t.Errorf("RegisterCondition(custom6): got error %v, want too many conditions", err)
+	}
+	if n := AllLicenseConditions.Len(); n != 16 {
+		t.Errorf("AllLicenseConditions.Len(): got %d, want 16", n)
+	}
+
+	Conditions.reset()
+	if _, ok := Conditions.Condition("commercial"); ok {
+		t.Errorf("Condition(commercial) after reset: got found, want not found")
+	}
+	if n := AllLicenseConditions.Len(); n != 10 {
+		t.Errorf("AllLicenseConditions.Len() after reset: got %d, want 10", n)
+	}
+}
+
+func TestCustomConditionPropagation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    PropagationRules
+		roots    []string
+		edges    []annotated
+		expected map[string][]string
+	}{
+		{
+			name:  "staticlinked",
+			rules: PropagationRules{Static: true},
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "commercialLib.meta_lic", []string{"static"}},
+			},
+			expected: map[string][]string{
+				"apacheBin.meta_lic":     {"notice", "commercial"},
+				"commercialLib.meta_lic": {"commercial"},
+			},
+		},
+		{
+			name:  "staticonlydynamiclinked",
+			rules: PropagationRules{Static: true},
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "commercialLib.meta_lic", []string{"dynamic"}},
+			},
+			expected: map[string][]string{
+				"apacheBin.meta_lic":     {"notice"},
+				"commercialLib.meta_lic": {"commercial"},
+			},
+		},
+		{
+			name:  "dynamiclinked",
+			rules: PropagationRules{Static: true, Dynamic: true},
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "commercialLib.meta_lic", []string{"dynamic"}},
+			},
+			expected: map[string][]string{
+				"apacheBin.meta_lic":     {"notice", "commercial"},
+				"commercialLib.meta_lic": {"commercial"},
+			},
+		},
+		{
+			name:  "toolchain",
+			rules: PropagationRules{Static: true, Dynamic: true},
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "commercialLib.meta_lic", []string{"toolchain"}},
+			},
+			expected: map[string][]string{
+				"apacheBin.meta_lic":     {"notice"},
+				"commercialLib.meta_lic": {"commercial"},
+			},
+		},
+		{
+			name:  "downstatic",
+			rules: PropagationRules{Static: true},
+			roots: []string{"commercialBin.meta_lic"},
+			edges: []annotated{
+				{"commercialBin.meta_lic", "apacheLib.meta_lic", []string{"static"}},
+			},
+			expected: map[string][]string{
+				"commercialBin.meta_lic": {"commercial"},
+				"apacheLib.meta_lic":     {"notice", "commercial"},
+			},
+		},
+		{
+			name:  "downstaticonlydynamic",
+			rules: PropagationRules{Static: true},
+			roots: []string{"commercialBin.meta_lic"},
+			edges: []annotated{
+				{"commercialBin.meta_lic", "apacheLib.meta_lic", []string{"dynamic"}},
+			},
+			expected: map[string][]string{
+				"commercialBin.meta_lic": {"commercial"},
+				"apacheLib.meta_lic":     {"notice"},
+			},
+		},
+		{
+			name:  "downdynamic",
+			rules: PropagationRules{Static: true, Dynamic: true},
+			roots: []string{"commercialBin.meta_lic"},
+			edges: []annotated{
+				{"commercialBin.meta_lic", "apacheLib.meta_lic", []string{"dynamic"}},
+			},
+			expected: map[string][]string{
+				"commercialBin.meta_lic": {"commercial"},
+				"apacheLib.meta_lic":     {"notice", "commercial"},
+			},
+		},
+		{
+			name:  "local",
+			rules: PropagationRules{},
+			roots: []string{"commercialBin.meta_lic"},
+			edges: []annotated{
+				{"commercialBin.meta_lic", "apacheLib.meta_lic", []string{"static"}},
+				{"commercialBin.meta_lic", "commercialLib.meta_lic", []string{"static"}},
+				{"commercialLib.meta_lic", "mitLib.meta_lic", []string{"static"}},
+			},
+			expected: map[string][]string{
+				"commercialBin.meta_lic": {"commercial"},
+				"apacheLib.meta_lic":     {"notice"},
+				"commercialLib.meta_lic": {"commercial"},
+				"mitLib.meta_lic":        {"notice"},
+			},
+		},
+		{
+			name:  "notoverridden",
+			rules: PropagationRules{Static: true},
+			roots: []string{"gplBin.meta_lic"},
+			edges: []annotated{
+				{"gplBin.meta_lic", "commercialLib.meta_lic", []string{"static"}},
+			},
+			expected: map[string][]string{
+				"gplBin.meta_lic":        {"restricted", "commercial"},
+				"commercialLib.meta_lic": {"restricted", "commercial"},
+			},
+		},
+		{
+			name:  "overridden",
+			rules: PropagationRules{Static: true, Overridable: true},
+			roots: []string{"gplBin.meta_lic"},
+			edges: []annotated{
+				{"gplBin.meta_lic", "commercialLib.meta_lic", []string{"static"}},
+			},
+			expected: map[string][]string{
+				"gplBin.meta_lic":        {"restricted"},
+				"commercialLib.meta_lic": {"restricted", "commercial"},
+			},
+		},
+		{
+			name:  "overriddenbyinherited",
+			rules: PropagationRules{Static: true, Overridable: true},
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "commercialLib.meta_lic", []string{"static"}},
+				{"apacheBin.meta_lic", "gplLib.meta_lic", []string{"static"}},
+			},
+			expected: map[string][]string{
+				"apacheBin.meta_lic":     {"notice", "restricted"},
+				"commercialLib.meta_lic": {"restricted", "commercial"},
+				"gplLib.meta_lic":        {"restricted"},
+			},
+		},
+		{
+			name:  "overridablewithoutrestricted",
+			rules: PropagationRules{Static: true, Overridable: true},
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "commercialLib.meta_lic", []string{"static"}},
+				{"apacheBin.meta_lic", "lgplLib.meta_lic", []string{"dynamic"}},
+			},
+			expected: map[string][]string{
+				"apacheBin.meta_lic":     {"notice", "commercial"},
+				"commercialLib.meta_lic": {"commercial"},
+				"lgplLib.meta_lic":       {"restricted_if_statically_linked"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Conditions.reset()
+			if err := Conditions.RegisterCondition("commercial", "", tt.rules); err != nil {
+				t.Fatalf("RegisterCondition(commercial): got error %v, want none", err)
+			}
+
+			stderr := &bytes.Buffer{}
+			lg, err := toGraph(stderr, tt.roots, tt.edges)
+			if err != nil {
+				t.Errorf("unexpected test data error: got %s, want no error", err)
+				return
+			}
+
+			logGraph(lg, t)
+
+			ResolveTopDownConditions(lg)
+			actual := make(map[string][]string)
+			for name, tn := range lg.targets {
+				actual[name] = tn.resolution.Names()
+			}
+			for _, names := range tt.expected {
+				sort.Strings(names)
+			}
+			for _, names := range actual {
+				sort.Strings(names)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("unexpected resolution: got %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
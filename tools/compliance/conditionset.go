@@ -22,7 +22,8 @@ import (
 // LicenseConditionSet identifies sets of license conditions.
 type LicenseConditionSet LicenseCondition
 
-// AllLicenseConditions is the set of all recognized license conditions.
+// AllLicenseConditions is the set of all built-in and registered license
+// conditions.
 const AllLicenseConditions = LicenseConditionSet(LicenseConditionMask)
 
 // NewLicenseConditionSet returns a set containing exactly the elements of
@@ -147,8 +148,9 @@ func (cs LicenseConditionSet) Difference(other ...LicenseConditionSet) LicenseCo
 
 // Len returns the number of license conditions in the set.
 func (cs LicenseConditionSet) Len() int {
+	recognized := Conditions.recognized()
 	size := 0
-	for lc := LicenseConditionSet(0x01); 0x00 != (AllLicenseConditions & lc); lc <<= 1 {
+	for lc := LicenseConditionSet(0x01); 0x00 != (recognized & lc); lc <<= 1 {
 		if 0x00 != (cs & lc) {
 			size++
 		}
@@ -158,8 +160,9 @@ func (cs LicenseConditionSet) Len() int {
 
 // AsList returns an array of the license conditions in the set.
 func (cs LicenseConditionSet) AsList() []LicenseCondition {
+	recognized := Conditions.recognized()
 	result := make([]LicenseCondition, 0, cs.Len())
-	for lc := LicenseConditionSet(0x01); 0x00 != (AllLicenseConditions & lc); lc <<= 1 {
+	for lc := LicenseConditionSet(0x01); 0x00 != (recognized & lc); lc <<= 1 {
 		if 0x00 != (cs & lc) {
 			result = append(result, LicenseCondition(lc))
 		}
@@ -169,8 +172,9 @@ func (cs LicenseConditionSet) AsList() []LicenseCondition {
 
 // Names returns an array of the names of the license conditions in the set.
 func (cs LicenseConditionSet) Names() []string {
+	recognized := Conditions.recognized()
 	result := make([]string, 0, cs.Len())
-	for lc := LicenseConditionSet(0x01); 0x00 != (AllLicenseConditions & lc); lc <<= 1 {
+	for lc := LicenseConditionSet(0x01); 0x00 != (recognized & lc); lc <<= 1 {
 		if 0x00 != (cs & lc) {
 			result = append(result, LicenseCondition(lc).Name())
 		}
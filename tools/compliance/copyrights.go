@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// copyrightLine matches a copyright line capturing the indentation, the
+	// copyright prefix, the years, the separator, and the copyright holder.
+	//
+	// e.g. "Copyright (C) 2018, 2020-2021 The Android Open Source Project"
+	copyrightLine = regexp.MustCompile(`^(\s*)((?i:copyright)(?:\s*(?:\([cC]\)|©))?|\([cC]\)|©)\s+([0-9]{4}(?:\s*[-,]\s*[0-9]{4})*)(\s*,?\s+)(\S.*?)\s*$`)
+
+	// yearRange matches a single year or a range of years.
+	yearRange = regexp.MustCompile(`([0-9]{4})(?:\s*-\s*([0-9]{4}))?`)
+)
+
+// maxYearSpan limits the number of years a single range may span to avoid
+// expanding nonsense like "1000-9999".
+const maxYearSpan = 200
+
+// NormalizeCopyrights collapses consecutive copyright lines for the same
+// copyright holder into a single line listing the union of their years as
+// ranges.
+//
+// e.g. "Copyright 2018 Foo", "Copyright 2019 Foo", and "(c) 2020 Foo" become
+// "Copyright 2018-2020 Foo".
+//
+// Only lines within the same run of adjacent copyright lines merge, and only
+// when the copyright holders match exactly. The merged line keeps the
+// indentation, prefix, and position of the first line for the holder. Lines
+// that do not merge remain unchanged.
+func NormalizeCopyrights(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+
+	// parsed describes a copyright line in the current run.
+	type parsed struct {
+		index  int
+		indent string
+		prefix string
+		sep    string
+		holder string
+		years  map[int]struct{}
+	}
+
+	changed := false
+	// merge collapses the lines of the run with the same holder.
+	merge := func(run []parsed) {
+		first := make(map[string]*parsed)
+		var order []*parsed
+		counts := make(map[string]int)
+		for i := range run {
+			p := &run[i]
+			counts[p.holder]++
+			if f, ok := first[p.holder]; ok {
+				for y := range p.years {
+					f.years[y] = struct{}{}
+				}
+				lines[p.index] = ""
+				continue
+			}
+			first[p.holder] = p
+			order = append(order, p)
+		}
+		for _, p := range order {
+			if counts[p.holder] < 2 {
+				continue
+			}
+			eol := ""
+			if strings.HasSuffix(lines[p.index], "\n") {
+				eol = "\n"
+			}
+			lines[p.index] = p.indent + p.prefix + " " + formatYears(p.years) + p.sep + p.holder + eol
+			changed = true
+		}
+	}
+
+	var run []parsed
+	for i, line := range lines {
+		groups := copyrightLine.FindStringSubmatch(strings.TrimSuffix(line, "\n"))
+		var years map[int]struct{}
+		if groups != nil {
+			years = parseYears(groups[3])
+		}
+		if years == nil {
+			if len(run) > 1 {
+				merge(run)
+			}
+			run = nil
+			continue
+		}
+		run = append(run, parsed{i, groups[1], groups[2], groups[4], groups[5], years})
+	}
+	if len(run) > 1 {
+		merge(run)
+	}
+	if !changed {
+		return text
+	}
+	normalized := strings.Join(lines, "")
+	if !strings.HasSuffix(text, "\n") {
+		// A dropped last line takes the missing final newline with it.
+		normalized = strings.TrimSuffix(normalized, "\n")
+	}
+	return normalized
+}
+
+// parseYears returns the set of years listed in `s` or nil if any range is
+// reversed or implausibly long.
+func parseYears(s string) map[int]struct{} {
+	years := make(map[int]struct{})
+	for _, m := range yearRange.FindAllStringSubmatch(s, -1) {
+		start, _ := strconv.Atoi(m[1])
+		end := start
+		if len(m[2]) > 0 {
+			end, _ = strconv.Atoi(m[2])
+		}
+		if end < start || end-start > maxYearSpan {
+			return nil
+		}
+		for y := start; y <= end; y++ {
+			years[y] = struct{}{}
+		}
+	}
+	return years
+}
+
+// formatYears returns the sorted `years` as comma-separated ranges.
+//
+// e.g. "2015, 2018-2020"
+func formatYears(years map[int]struct{}) string {
+	sorted := make([]int, 0, len(years))
+	for y := range years {
+		sorted = append(sorted, y)
+	}
+	sort.Ints(sorted)
+	var ranges []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if j == i {
+			ranges = append(ranges, fmt.Sprintf("%04d", sorted[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%04d-%04d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
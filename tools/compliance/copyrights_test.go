@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCopyrights(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "empty",
+			text:     "",
+			expected: "",
+		},
+		{
+			name:     "single",
+			text:     "Copyright 2018 The Android Open Source Project\n",
+			expected: "Copyright 2018 The Android Open Source Project\n",
+		},
+		{
+			name:     "consecutive",
+			text:     "Copyright 2018 Foo\nCopyright 2019 Foo\nCopyright 2020 Foo\n",
+			expected: "Copyright 2018-2020 Foo\n",
+		},
+		{
+			name:     "gap",
+			text:     "Copyright 2015 Foo\nCopyright 2018-2019 Foo\nCopyright 2020 Foo\n",
+			expected: "Copyright 2015, 2018-2020 Foo\n",
+		},
+		{
+			name:     "symbols",
+			text:     "Copyright (c) 2018 Foo\n(C) 2019 Foo\n© 2020 Foo\n",
+			expected: "Copyright (c) 2018-2020 Foo\n",
+		},
+		{
+			name:     "mixedcase",
+			text:     "COPYRIGHT © 2019 Foo Inc.\ncopyright 2018 Foo Inc.\n",
+			expected: "COPYRIGHT © 2018-2019 Foo Inc.\n",
+		},
+		{
+			name:     "multiauthor",
+			text:     "Copyright 2018 Foo, Bar\nCopyright 2019 Foo, Bar\nCopyright 2019 Baz\n",
+			expected: "Copyright 2018-2019 Foo, Bar\nCopyright 2019 Baz\n",
+		},
+		{
+			name:     "distinctholders",
+			text:     "Copyright 2018-2020 Foo\nCopyright 2018-2020 Bar\n",
+			expected: "Copyright 2018-2020 Foo\nCopyright 2018-2020 Bar\n",
+		},
+		{
+			name:     "interleaved",
+			text:     "Copyright 2018 Foo\nCopyright 2018 Bar\nCopyright 2019 Foo\n",
+			expected: "Copyright 2018-2019 Foo\nCopyright 2018 Bar\n",
+		},
+		{
+			name:     "separateruns",
+			text:     "Copyright 2018 Foo\n\nCopyright 2019 Foo\n",
+			expected: "Copyright 2018 Foo\n\nCopyright 2019 Foo\n",
+		},
+		{
+			name:     "indented",
+			text:     "  Copyright 2018 Foo\n  Copyright 2019 Foo\n\nLicensed under...\n",
+			expected: "  Copyright 2018-2019 Foo\n\nLicensed under...\n",
+		},
+		{
+			name:     "reversed",
+			text:     "Copyright 2020-2018 Foo\nCopyright 2019 Foo\n",
+			expected: "Copyright 2020-2018 Foo\nCopyright 2019 Foo\n",
+		},
+		{
+			name:     "noeol",
+			text:     "Copyright 2018 Foo\nCopyright 2019 Foo",
+			expected: "Copyright 2018-2019 Foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := NormalizeCopyrights(tt.text)
+			if actual != tt.expected {
+				t.Errorf("unexpected text: got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func FuzzNormalizeCopyrights(f *testing.F) {
+	f.Add("Copyright 2018 Foo\nCopyright 2019 Foo\n")
+	f.Add("Copyright (c) 2015, 2018 Foo\n(C) 2019-2020 Foo\nCopyright 2019 Bar\n")
+	f.Add("© 2018 Foo, Bar\ncopyright © 2019 Foo, Bar\n\nPermission is hereby granted...\n")
+	f.Add("Copyright 2018-2020 Foo\nCopyright 2018-2020 Bar\n")
+	f.Fuzz(func(t *testing.T, text string) {
+		normalized := NormalizeCopyrights(text)
+		if again := NormalizeCopyrights(normalized); again != normalized {
+			t.Errorf("not idempotent: %q normalized to %q then %q", text, normalized, again)
+		}
+
+		// Every non-copyright line survives in order and every holder remains.
+		var expectedOther, actualOther []string
+		expectedHolders := make(map[string]struct{})
+		for _, line := range strings.Split(text, "\n") {
+			if groups := copyrightLine.FindStringSubmatch(line); groups != nil && parseYears(groups[3]) != nil {
+				expectedHolders[groups[5]] = struct{}{}
+				continue
+			}
+			expectedOther = append(expectedOther, line)
+		}
+		actualHolders := make(map[string]struct{})
+		for _, line := range strings.Split(normalized, "\n") {
+			if groups := copyrightLine.FindStringSubmatch(line); groups != nil && parseYears(groups[3]) != nil {
+				actualHolders[groups[5]] = struct{}{}
+				continue
+			}
+			actualOther = append(actualOther, line)
+		}
+		if strings.Join(actualOther, "\n") != strings.Join(expectedOther, "\n") {
+			t.Errorf("non-copyright lines changed: %q normalized to %q", text, normalized)
+		}
+		for holder := range expectedHolders {
+			if _, ok := actualHolders[holder]; !ok {
+				t.Errorf("lost copyright holder %q: %q normalized to %q", holder, text, normalized)
+			}
+		}
+	})
+}
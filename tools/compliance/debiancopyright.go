@@ -0,0 +1,399 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"android/soong/tools/compliance/spdx"
+)
+
+// DEP5Entry describes a `Files:` paragraph of a machine-readable Debian
+// copyright file.
+//
+// See https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+type DEP5Entry struct {
+	// Files lists the patterns of the files the paragraph covers, e.g. "*"
+	// or "src/*.c".
+	Files []string
+	// Copyright lists the copyright notices one per line.
+	Copyright string
+	// License is the license as written, e.g. "GPL-2+ or Expat".
+	License string
+	// Expression is the SPDX license expression for License, e.g.
+	// "GPL-2.0-or-later OR MIT".
+	Expression string
+	// LicenseText is the text of the license from the paragraph, or else
+	// from the stand-alone `License:` paragraphs naming its licenses, or
+	// empty.
+	LicenseText string
+	// Line is the line the paragraph starts on.
+	Line int
+}
+
+// Matches returns whether the entry covers the file `name`, a path relative
+// to the root of the source.
+func (e DEP5Entry) Matches(name string) bool {
+	for _, pattern := range e.Files {
+		if matchDep5Pattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupDEP5 returns the last of `entries` covering the file `name`, which
+// the format makes take precedence over the earlier ones, e.g. over `Files:
+// *`, or nil when none does.
+func LookupDEP5(entries []DEP5Entry, name string) *DEP5Entry {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Matches(name) {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// dep5Paragraph is a paragraph of a Debian copyright file with the fields
+// by lower case name.
+type dep5Paragraph struct {
+	line   int
+	fields map[string]string
+}
+
+// ParseDEP5 returns the `Files:` paragraphs of the machine-readable Debian
+// copyright file read from `r` in order.
+//
+// Fills in the license texts that the paragraphs refer to by short name from
+// the stand-alone `License:` paragraphs.
+//
+// Returns an error for a `Files:` paragraph without a `License:` field, or
+// with a license that does not translate into a valid SPDX expression.
+func ParseDEP5(r io.Reader) ([]DEP5Entry, error) {
+	_, entries, err := parseDEP5(r)
+	return entries, err
+}
+
+// parseDEP5 returns the header paragraph and the `Files:` paragraphs of the
+// Debian copyright file read from `r`.
+func parseDEP5(r io.Reader) (map[string]string, []DEP5Entry, error) {
+	paragraphs, err := readDEP5Paragraphs(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(paragraphs) == 0 {
+		return nil, nil, fmt.Errorf("no paragraphs")
+	}
+	header := paragraphs[0].fields
+	if _, ok := header["format"]; !ok {
+		return nil, nil, fmt.Errorf("line %d: want Format field in header paragraph", paragraphs[0].line)
+	}
+
+	var entries []DEP5Entry
+	// texts maps the short names of the stand-alone license paragraphs to
+	// their texts.
+	texts := make(map[string]string)
+	for _, p := range paragraphs[1:] {
+		license, hasLicense := p.fields["license"]
+		name, text, _ := strings.Cut(license, "\n")
+		name = strings.TrimSpace(name)
+		files, hasFiles := p.fields["files"]
+		if !hasFiles {
+			if hasLicense && len(name) > 0 {
+				texts[strings.ToLower(name)] = text
+			}
+			continue
+		}
+		if !hasLicense || len(name) == 0 {
+			return nil, nil, fmt.Errorf("line %d: Files paragraph without License field", p.line)
+		}
+		expr, err := dep5Expression(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid License %q: %w", p.line, name, err)
+		}
+		entries = append(entries, DEP5Entry{
+			Files:       strings.Fields(files),
+			Copyright:   strings.TrimSpace(p.fields["copyright"]),
+			License:     name,
+			Expression:  expr.String(),
+			LicenseText: text,
+			Line:        p.line,
+		})
+	}
+	for i := range entries {
+		if len(entries[i].LicenseText) > 0 {
+			continue
+		}
+		var parts []string
+		for _, short := range dep5ShortNames(entries[i].License) {
+			if text, ok := texts[strings.ToLower(short)]; ok && len(text) > 0 {
+				parts = append(parts, text)
+			}
+		}
+		entries[i].LicenseText = strings.Join(parts, "\n")
+	}
+	return header, entries, nil
+}
+
+// readDEP5Paragraphs returns the blank line separated paragraphs read from
+// `r`.
+//
+// Joins the continuation lines of a field with newlines dropping the leading
+// space, and treating a line of a single "." as an empty line.
+func readDEP5Paragraphs(r io.Reader) ([]dep5Paragraph, error) {
+	var paragraphs []dep5Paragraph
+	var current *dep5Paragraph
+	field := ""
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if len(text) == 0 {
+			current = nil
+			field = ""
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		if text[0] == ' ' || text[0] == '\t' {
+			if current == nil || len(field) == 0 {
+				return nil, fmt.Errorf("line %d: continuation line outside any field", line)
+			}
+			text = strings.TrimSpace(text)
+			if text == "." {
+				text = ""
+			}
+			current.fields[field] += "\n" + text
+			continue
+		}
+		name, value, ok := strings.Cut(text, ":")
+		if !ok || len(name) == 0 {
+			return nil, fmt.Errorf("line %d: want Field: value, got %q", line, text)
+		}
+		if current == nil {
+			paragraphs = append(paragraphs, dep5Paragraph{line, make(map[string]string)})
+			current = &paragraphs[len(paragraphs)-1]
+		}
+		field = strings.ToLower(name)
+		if _, ok := current.fields[field]; ok {
+			return nil, fmt.Errorf("line %d: repeated field %q", line, name)
+		}
+		current.fields[field] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return paragraphs, nil
+}
+
+var (
+	// dep5GNULicense matches the Debian short names of the GNU licenses,
+	// e.g. "GPL-2+" or "LGPL-2.1".
+	dep5GNULicense = regexp.MustCompile(`(?i)^(A?GPL|LGPL|GFDL)-(\d+)(\.\d+)?(\+)?$`)
+
+	// dep5Exception matches the exceptions in Debian license names, e.g.
+	// "with OpenSSL exception".
+	dep5Exception = regexp.MustCompile(`(?i)\bwith\s+(\S+)\s+exception\b`)
+
+	// dep5Licenses maps the lower case Debian short names that differ from
+	// the SPDX identifiers to the SPDX identifiers.
+	dep5Licenses = map[string]string{
+		"expat":         "MIT",
+		"public-domain": "LicenseRef-Debian-public-domain",
+	}
+)
+
+// dep5Expression returns the SPDX expression for the Debian license short
+// name or expression `name`, e.g. "(GPL-2.0-or-later OR MIT) AND Zlib" for
+// "GPL-2+ or Expat, and Zlib".
+func dep5Expression(name string) (spdx.Expression, error) {
+	name = dep5Exception.ReplaceAllString(name, "WITH $1-exception")
+	// A comma makes the operator following it bind less tightly than the
+	// ones before it.
+	parts := strings.Split(name, ",")
+	expr := ""
+	for i, part := range parts {
+		words := strings.Fields(part)
+		if i > 0 {
+			if len(words) < 2 {
+				return nil, fmt.Errorf("want and or or after comma")
+			}
+			expr = "(" + expr + ") " + words[0] + " "
+			words = words[1:]
+		}
+		for j, w := range words {
+			words[j] = dep5LicenseID(w)
+		}
+		expr += "(" + strings.Join(words, " ") + ")"
+	}
+	return spdx.ParseExpression(expr)
+}
+
+// dep5LicenseID returns the SPDX identifier for the Debian short name
+// `name`, e.g. "GPL-2.0-or-later" for "GPL-2+", or `name` when it is an
+// operator or needs no translation.
+func dep5LicenseID(name string) string {
+	if id, ok := dep5Licenses[strings.ToLower(name)]; ok {
+		return id
+	}
+	if m := dep5GNULicense.FindStringSubmatch(name); m != nil {
+		version := m[2] + firstNonEmpty(m[3], ".0")
+		return canonicalSpdxID(strings.ToUpper(m[1]) + "-" + version + m[4])
+	}
+	for id := range spdxConditions {
+		if strings.EqualFold(id, name) {
+			return id
+		}
+	}
+	return name
+}
+
+// dep5ShortNames returns the Debian short names of the licenses in the
+// license `name`, e.g. "GPL-2+" and "Expat" for "GPL-2+ or Expat".
+func dep5ShortNames(name string) []string {
+	name = dep5Exception.ReplaceAllString(name, "")
+	var names []string
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ',' || r == '(' || r == ')'
+	}) {
+		switch strings.ToLower(w) {
+		case "and", "or":
+			continue
+		}
+		names = append(names, w)
+	}
+	return names
+}
+
+// spdxLicenseKind returns the license kind and condition for the SPDX
+// identifier `id`, or false when the condition of the license is unknown.
+//
+// The "or later" GNU licenses have the condition of the version they name,
+// e.g. "restricted" for "GPL-2.0-or-later".
+func spdxLicenseKind(id string) (string, string, bool) {
+	ids := licenseIDs([]string{id})
+	if len(ids) > 0 {
+		return spdxLicenseKindPrefix + ids[0], spdxConditions[ids[0]], true
+	}
+	if strings.HasSuffix(id, "-or-later") {
+		base := strings.TrimSuffix(id, "-or-later")
+		if condition, ok := spdxConditions[base+"-only"]; ok {
+			return spdxLicenseKindPrefix + id, condition, true
+		}
+	}
+	return "", "", false
+}
+
+// DebianCopyrightParser describes Debian packages with MetaLicStubs using
+// the paragraphs of their machine-readable copyright files.
+type DebianCopyrightParser struct {
+	// TextDir is where to write the copyright notices and license text of
+	// each paragraph, as <TextDir>/<copyright path>/<n>.txt, so that the
+	// stubs can list them, or empty to only use the texts to identify the
+	// licenses.
+	TextDir string
+}
+
+// Parse returns a MetaLicStub named "<copyrightPath>-<n>.meta_lic" for the
+// n-th `Files:` paragraph, counting from 1, of the Debian copyright file
+// `copyrightPath` in `fsys`.
+//
+// The license kinds come from the SPDX identifiers the license names
+// translate to, or for the names of unknown licenses from identifying the
+// license text. Unknown licenses without a license text make the license
+// kind unidentified.
+//
+// The package name comes from the Upstream-Name field, or else the directory
+// of the copyright file, e.g. "libfoo" for "usr/share/doc/libfoo/copyright"
+// or "debian/copyright" in "libfoo/debian/copyright". The package names of
+// the paragraphs other than `Files: *` list their file patterns.
+func (p *DebianCopyrightParser) Parse(fsys fs.FS, copyrightPath string) ([]MetaLicStub, error) {
+	data, err := fs.ReadFile(fsys, copyrightPath)
+	if err != nil {
+		return nil, err
+	}
+	header, entries, err := parseDEP5(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Debian copyright file %q: %w", copyrightPath, err)
+	}
+	if len(entries) == 0 {
+		return nil, &UnknownLicenseError{copyrightPath, "no Files paragraph"}
+	}
+
+	dir := path.Dir(copyrightPath)
+	if path.Base(dir) == "debian" {
+		dir = path.Dir(dir)
+	}
+	packageName := firstNonEmpty(header["upstream-name"], path.Base(dir))
+
+	stubs := make([]MetaLicStub, 0, len(entries))
+	for i, entry := range entries {
+		stub := MetaLicStub{
+			Name:        fmt.Sprintf("%s-%d.meta_lic", copyrightPath, i+1),
+			PackageName: packageName,
+		}
+		if len(entry.Files) != 1 || entry.Files[0] != "*" {
+			stub.PackageName += ": " + strings.Join(entry.Files, " ")
+		}
+		kinds := make(map[string]string)
+		e, _ := spdx.ParseExpression(entry.Expression)
+		for _, id := range e.Licenses() {
+			if kind, condition, ok := spdxLicenseKind(id); ok {
+				kinds[kind] = condition
+			} else if len(entry.LicenseText) > 0 {
+				kind, condition := identifyLicense(entry.LicenseText, licenseMatchThreshold)
+				kinds[kind] = condition
+			} else {
+				// Licenses naming no known license, without a license
+				// text, need someone to review them.
+				kinds[unidentifiedLicenseKind] = "by_exception_only"
+			}
+		}
+		conditions := make(map[string]struct{})
+		for kind, condition := range kinds {
+			stub.LicenseKinds = append(stub.LicenseKinds, kind)
+			conditions[condition] = struct{}{}
+		}
+		for condition := range conditions {
+			stub.LicenseConditions = append(stub.LicenseConditions, condition)
+		}
+		sort.Strings(stub.LicenseKinds)
+		sort.Strings(stub.LicenseConditions)
+
+		if len(p.TextDir) > 0 && len(entry.Copyright)+len(entry.LicenseText) > 0 {
+			text := filepath.Join(p.TextDir, filepath.FromSlash(copyrightPath), fmt.Sprintf("%d.txt", i+1))
+			if err := os.MkdirAll(filepath.Dir(text), 0777); err != nil {
+				return nil, err
+			}
+			content := strings.TrimSpace(entry.Copyright + "\n\n" + entry.LicenseText)
+			if err := os.WriteFile(text, []byte(content+"\n"), 0666); err != nil {
+				return nil, err
+			}
+			stub.LicenseTexts = append(stub.LicenseTexts, filepath.ToSlash(text))
+		}
+		stubs = append(stubs, stub)
+	}
+	return stubs, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const dep5Copyright = `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: libfoo
+Source: https://example.com/libfoo
+
+Files: *
+Copyright: 2020-2026 The Foo Authors
+           2019 Example Corp.
+License: GPL-2+ or Expat, and Zlib
+
+Files: src/compat/*.c
+       include/compat.h
+Copyright: 2018 Jane Doe
+License: BSD-3-clause
+ Redistribution and use in source and binary forms are permitted.
+ .
+ THIS SOFTWARE IS PROVIDED AS IS.
+
+Files: debian/*
+Copyright: 2026 Debian Maintainer
+License: public-domain
+
+License: GPL-2+
+ This program is free software; you can redistribute it under the terms of
+ the GNU General Public License version 2 or later.
+
+License: Expat
+ Permission is hereby granted, free of charge.
+`
+
+func TestParseDEP5(t *testing.T) {
+	entries, err := ParseDEP5(strings.NewReader(dep5Copyright))
+	if err != nil {
+		t.Fatalf("ParseDEP5(): got error %s, want no error", err)
+	}
+	expected := []DEP5Entry{
+		{
+			Files:      []string{"*"},
+			Copyright:  "2020-2026 The Foo Authors\n2019 Example Corp.",
+			License:    "GPL-2+ or Expat, and Zlib",
+			Expression: "(GPL-2.0-or-later OR MIT) AND Zlib",
+			LicenseText: "This program is free software; you can redistribute it under the terms of\n" +
+				"the GNU General Public License version 2 or later.\n" +
+				"Permission is hereby granted, free of charge.",
+			Line: 5,
+		},
+		{
+			Files:       []string{"src/compat/*.c", "include/compat.h"},
+			Copyright:   "2018 Jane Doe",
+			License:     "BSD-3-clause",
+			Expression:  "BSD-3-Clause",
+			LicenseText: "Redistribution and use in source and binary forms are permitted.\n\nTHIS SOFTWARE IS PROVIDED AS IS.",
+			Line:        10,
+		},
+		{
+			Files:      []string{"debian/*"},
+			Copyright:  "2026 Debian Maintainer",
+			License:    "public-domain",
+			Expression: "LicenseRef-Debian-public-domain",
+			Line:       18,
+		},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("ParseDEP5(): got %#v, want %#v", entries, expected)
+	}
+
+	// The last paragraph matching a file takes precedence over `Files: *`.
+	for name, expectedLicense := range map[string]string{
+		"README":               "GPL-2+ or Expat, and Zlib",
+		"src/compat/strlcpy.c": "BSD-3-clause",
+		"src/compat/strlcpy.h": "GPL-2+ or Expat, and Zlib",
+		"include/compat.h":     "BSD-3-clause",
+		"debian/rules":         "public-domain",
+	} {
+		if e := LookupDEP5(entries, name); e == nil || e.License != expectedLicense {
+			t.Errorf("LookupDEP5(%q): got %v, want %q", name, e, expectedLicense)
+		}
+	}
+	if e := LookupDEP5(entries[1:], "README"); e != nil {
+		t.Errorf("LookupDEP5(README): got %v, want nil", e)
+	}
+}
+
+func TestParseDEP5Expressions(t *testing.T) {
+	tests := map[string]string{
+		"Apache-2.0":                        "Apache-2.0",
+		"LGPL-2.1+":                         "LGPL-2.1-or-later",
+		"GPL-3":                             "GPL-3.0-only",
+		"GPL-2+ with OpenSSL exception":     "GPL-2.0-or-later WITH OpenSSL-exception",
+		"MIT or Apache-2.0, and ISC":        "(MIT OR Apache-2.0) AND ISC",
+		"MIT and Apache-2.0, or Zlib":       "MIT AND Apache-2.0 OR Zlib",
+		"LicenseRef-Foo":                    "LicenseRef-Foo",
+		"GPL-2 and LGPL-2+ or bsd-2-clause": "GPL-2.0-only AND LGPL-2.0-or-later OR BSD-2-Clause",
+	}
+	for license, expected := range tests {
+		data := "Format: x\n\nFiles: *\nCopyright: 2026 Someone\nLicense: " + license + "\n"
+		entries, err := ParseDEP5(strings.NewReader(data))
+		if err != nil {
+			t.Errorf("ParseDEP5(%q): got error %s, want no error", license, err)
+			continue
+		}
+		if entries[0].Expression != expected {
+			t.Errorf("ParseDEP5(%q): got expression %q, want %q", license, entries[0].Expression, expected)
+		}
+	}
+}
+
+func TestParseDEP5Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{"empty", "", "no paragraphs"},
+		{"noformat", "Files: *\nCopyright: 2026 Someone\nLicense: MIT\n", "line 1: want Format field"},
+		{"nolicense", "Format: x\n\nFiles: *\nCopyright: 2026 Someone\n", "line 3: Files paragraph without License field"},
+		{"emptylicense", "Format: x\n\nFiles: *\nLicense:\n text only\n", "line 3: Files paragraph without License field"},
+		{"invalidexpression", "Format: x\n\nFiles: *\nLicense: MIT or\n", `line 3: invalid License "MIT or"`},
+		{"invalidcharacter", "Format: x\n\nFiles: *\nLicense: MIT & Zlib\n", `invalid character '&'`},
+		{"danglingcomma", "Format: x\n\nFiles: *\nLicense: MIT, Zlib\n", "want and or or after comma"},
+		{"nocolon", "Format: x\n\nFiles *\n", `line 3: want Field: value, got "Files *"`},
+		{"continuation", "Format: x\n\n continued\n", "line 3: continuation line outside any field"},
+		{"repeated", "Format: x\n\nFiles: *\nLicense: MIT\nLicense: Zlib\n", `line 5: repeated field "License"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDEP5(strings.NewReader(tt.data))
+			if err == nil {
+				t.Fatalf("ParseDEP5(): got no error, want %q", tt.expectedError)
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("ParseDEP5(): got error %q, want %q", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestDebianCopyrightParser(t *testing.T) {
+	fsys := fstest.MapFS{
+		"usr/share/doc/libfoo/copyright": {Data: []byte(dep5Copyright)},
+		"usr/share/doc/libbar/copyright": {Data: []byte("Format: x\n\nFiles: *\nCopyright: 2026 Someone\nLicense: Apache-2.0\n")},
+		"usr/share/doc/libbaz/copyright": {Data: []byte("Format: x\nFiles-Excluded: *.o\n\nLicense: MIT\n text\n")},
+		"usr/share/doc/libqux/copyright": {Data: []byte("Format: x\n\nFiles: *\nLicense: Apache-2.0 and\n")},
+	}
+	dir := t.TempDir()
+	p := &DebianCopyrightParser{TextDir: dir}
+
+	stubs, err := p.Parse(fsys, "usr/share/doc/libfoo/copyright")
+	if err != nil {
+		t.Fatalf("Parse(libfoo): got error %s, want no error", err)
+	}
+	textDir := filepath.ToSlash(filepath.Join(dir, "usr/share/doc/libfoo/copyright"))
+	expected := []MetaLicStub{
+		{
+			Name:              "usr/share/doc/libfoo/copyright-1.meta_lic",
+			PackageName:       "libfoo",
+			LicenseKinds:      []string{"SPDX-license-identifier-GPL-2.0-or-later", "SPDX-license-identifier-MIT", "SPDX-license-identifier-Zlib"},
+			LicenseConditions: []string{"notice", "restricted"},
+			LicenseTexts:      []string{textDir + "/1.txt"},
+		},
+		{
+			Name:              "usr/share/doc/libfoo/copyright-2.meta_lic",
+			PackageName:       "libfoo: src/compat/*.c include/compat.h",
+			LicenseKinds:      []string{"SPDX-license-identifier-BSD-3-Clause"},
+			LicenseConditions: []string{"notice"},
+			LicenseTexts:      []string{textDir + "/2.txt"},
+		},
+		{
+			Name:              "usr/share/doc/libfoo/copyright-3.meta_lic",
+			PackageName:       "libfoo: debian/*",
+			LicenseKinds:      []string{"legacy_by_exception_only"},
+			LicenseConditions: []string{"by_exception_only"},
+			LicenseTexts:      []string{textDir + "/3.txt"},
+		},
+	}
+	if !reflect.DeepEqual(stubs, expected) {
+		t.Errorf("Parse(libfoo): got %#v, want %#v", stubs, expected)
+	}
+	text, err := os.ReadFile(filepath.Join(dir, "usr/share/doc/libfoo/copyright/2.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedText := "2018 Jane Doe\n\nRedistribution and use in source and binary forms are permitted.\n\nTHIS SOFTWARE IS PROVIDED AS IS.\n"
+	if string(text) != expectedText {
+		t.Errorf("Parse(libfoo): got text %q, want %q", text, expectedText)
+	}
+
+	// The license graph reader accepts the stubs.
+	paths, err := WriteMetaLicStubs(t.TempDir(), stubs)
+	if err != nil {
+		t.Fatalf("WriteMetaLicStubs(): got error %s, want no error", err)
+	}
+	if _, err := ReadLicenseGraph(FS, &bytes.Buffer{}, paths); err != nil {
+		t.Errorf("ReadLicenseGraph(stubs): got error %s, want no error", err)
+	}
+
+	// The package name falls back to the directory of the copyright file.
+	stubs, err = (&DebianCopyrightParser{}).Parse(fsys, "usr/share/doc/libbar/copyright")
+	if err != nil {
+		t.Fatalf("Parse(libbar): got error %s, want no error", err)
+	}
+	if len(stubs) != 1 || stubs[0].PackageName != "libbar" || len(stubs[0].LicenseTexts) != 0 {
+		t.Errorf("Parse(libbar): got %#v, want libbar without license texts", stubs)
+	}
+
+	if _, err := p.Parse(fsys, "usr/share/doc/libbaz/copyright"); err == nil || !strings.Contains(err.Error(), "no Files paragraph") {
+		t.Errorf("Parse(libbaz): got error %v, want no Files paragraph", err)
+	}
+	if _, err := p.Parse(fsys, "usr/share/doc/libqux/copyright"); err == nil || !strings.Contains(err.Error(), `invalid Debian copyright file "usr/share/doc/libqux/copyright": line 3`) {
+		t.Errorf("Parse(libqux): got error %v, want invalid Debian copyright file", err)
+	}
+	if _, err := p.Parse(fsys, "usr/share/doc/missing/copyright"); err == nil {
+		t.Errorf("Parse(missing): got no error, want error")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// EmptyTextMode selects how NoticeIndex treats license text files that are
+// empty or hold only whitespace, which otherwise produce notice sections
+// showing no license at all.
+type EmptyTextMode int
+
+const (
+	// EmptyTextKeep indexes empty license texts like any other.
+	EmptyTextKeep EmptyTextMode = iota
+	// EmptyTextSkip leaves empty license texts out of the index so that
+	// targets without any other license text count as missing texts.
+	EmptyTextSkip
+	// EmptyTextPlaceholder replaces each empty license text with a
+	// placeholder naming the file.
+	EmptyTextPlaceholder
+)
+
+// emptyTextModes maps the -empty_text flag values to the modes.
+var emptyTextModes = map[string]EmptyTextMode{
+	"keep":        EmptyTextKeep,
+	"skip":        EmptyTextSkip,
+	"placeholder": EmptyTextPlaceholder,
+}
+
+// ParseEmptyTextMode returns the mode for the `-empty_text` flag value `s`:
+// "keep", "skip" or "placeholder".
+func ParseEmptyTextMode(s string) (EmptyTextMode, error) {
+	m, ok := emptyTextModes[s]
+	if !ok {
+		return EmptyTextKeep, fmt.Errorf("invalid -empty_text %q: want skip, placeholder or keep", s)
+	}
+	return m, nil
+}
+
+// String returns the flag value for `m`.
+func (m EmptyTextMode) String() string {
+	for s, mode := range emptyTextModes {
+		if mode == m {
+			return s
+		}
+	}
+	return fmt.Sprintf("EmptyTextMode(%d)", int(m))
+}
+
+// EmptyText describes a license text file of a shipped target that is empty
+// or holds only whitespace.
+type EmptyText struct {
+	// Target names the license metadata file of the target.
+	Target string
+	// Path is the license text file.
+	Path string
+}
+
+// emptyTextPlaceholder returns the text EmptyTextPlaceholder substitutes for
+// the empty license text file `file`.
+func emptyTextPlaceholder(file string) []byte {
+	return []byte(fmt.Sprintf("*** MISSING LICENSE TEXT: the license text file %s is empty. ***\n", file))
+}
+
+// addEmptyText records that `tn` lists the empty license text file `file`.
+func (ni *NoticeIndex) addEmptyText(tn *TargetNode, file string) {
+	ni.emptyTexts[EmptyText{tn.Name(), file}] = struct{}{}
+}
+
+// addPlaceholder indexes the placeholder for the empty license text file
+// `file` in place of its content.
+func (ni *NoticeIndex) addPlaceholder(file string) {
+	text := emptyTextPlaceholder(file)
+	h := Hash{fmt.Sprintf("%x", md5.Sum(text))}
+	ni.hash[file] = h
+	if _, alreadyPresent := ni.text[h]; !alreadyPresent {
+		ni.text[h] = text
+	}
+}
+
+// EmptyTexts returns the empty license text files of the shipped targets
+// ordered by target and path.
+func (ni *NoticeIndex) EmptyTexts() []EmptyText {
+	empty := make([]EmptyText, 0, len(ni.emptyTexts))
+	for e := range ni.emptyTexts {
+		empty = append(empty, e)
+	}
+	sort.Slice(empty, func(i, j int) bool {
+		if empty[i].Target != empty[j].Target {
+			return empty[i].Target < empty[j].Target
+		}
+		return empty[i].Path < empty[j].Path
+	})
+	return empty
+}
+
+// WarnEmptyTexts writes a warning to `w` naming the target and path of each
+// empty license text file of `ni`.
+func WarnEmptyTexts(w io.Writer, ni *NoticeIndex) {
+	for _, e := range ni.EmptyTexts() {
+		fmt.Fprintf(w, "empty license text file %q for %s\n", e.Path, e.Target)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseEmptyTextMode(t *testing.T) {
+	for _, mode := range []EmptyTextMode{EmptyTextKeep, EmptyTextSkip, EmptyTextPlaceholder} {
+		actual, err := ParseEmptyTextMode(mode.String())
+		if err != nil || actual != mode {
+			t.Errorf("ParseEmptyTextMode(%q): got %v, %v, want %v", mode.String(), actual, err, mode)
+		}
+	}
+	if _, err := ParseEmptyTextMode("drop"); err == nil {
+		t.Errorf("ParseEmptyTextMode(\"drop\"): got no error, want error")
+	}
+}
+
+func TestEmptyTextsHashCache(t *testing.T) {
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	rootFS := cachingFixture(mtime)
+	rootFS["licenses/LICENSE3"] = &fstest.MapFile{Data: []byte(" \n\t\n"), ModTime: mtime}
+	cacheFile := filepath.Join(t.TempDir(), "hashes.json")
+
+	expected := []EmptyText{{"lib3.meta_lic", "licenses/LICENSE3"}}
+	cache := NewHashCache()
+	ni, _ := indexCached(t, rootFS, cache)
+	if actual := ni.EmptyTexts(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("EmptyTexts(): got %v, want %v", actual, expected)
+	}
+	if err := cache.Write(cacheFile); err != nil {
+		t.Fatalf("Write(): got error %s, want no error", err)
+	}
+
+	// The cache remembers the text is blank without reading it again.
+	cache, err := ReadHashCache(cacheFile)
+	if err != nil {
+		t.Fatalf("ReadHashCache(): got error %s, want no error", err)
+	}
+	ni, reads := indexCached(t, rootFS, cache)
+	if reads != 0 {
+		t.Errorf("IndexLicenseTextsWithOptions(cached): got %d reads, want 0", reads)
+	}
+	if actual := ni.EmptyTexts(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("EmptyTexts(cached): got %v, want %v", actual, expected)
+	}
+
+	var warnings bytes.Buffer
+	WarnEmptyTexts(&warnings, ni)
+	if actual, expected := warnings.String(), "empty license text file \"licenses/LICENSE3\" for lib3.meta_lic\n"; actual != expected {
+		t.Errorf("WarnEmptyTexts(): got %q, want %q", actual, expected)
+	}
+}
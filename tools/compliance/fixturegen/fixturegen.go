@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fixturegen generates synthetic license graphs for tests and
+// performance measurements.
+//
+// A generated graph is a tree of targets linking their children statically
+// rooted at a container installing system.img. The container also holds the
+// file installed by every other target. Every target installs exactly one file
+// and names its own package, so the notice for the root lists one library and
+// one install path per target.
+package fixturegen
+
+import (
+	"fmt"
+	"math/rand"
+	"path"
+	"sort"
+	"strings"
+
+	"android/soong/tools/compliance"
+)
+
+// Root names the license metadata file of the root of a generated graph
+// relative to Options.Dir.
+const Root = "system.img.meta_lic"
+
+// Options describes the graph to generate.
+type Options struct {
+	// Nodes is the number of targets in the graph including the root.
+	Nodes int
+	// Conditions lists the license conditions assigned randomly to targets.
+	Conditions []string
+	// Depth is the maximum number of edges from the root to any target.
+	Depth int
+	// Seed seeds the random choices so the same options generate the same
+	// graph.
+	Seed int64
+	// Dir prefixes the paths of the generated files and the paths the license
+	// metadata files reference so the graph can be read from Dir.
+	Dir string
+}
+
+// conditionKinds maps license conditions to license kinds with the condition.
+var conditionKinds = map[string]string{
+	"unencumbered":                    "SPDX-license-identifier-0BSD",
+	"permissive":                      "SPDX-license-identifier-BSL-1.0",
+	"notice":                          "SPDX-license-identifier-Apache-2.0",
+	"reciprocal":                      "SPDX-license-identifier-MPL-2.0",
+	"restricted":                      "SPDX-license-identifier-GPL-2.0",
+	"restricted_if_statically_linked": "SPDX-license-identifier-LGPL-2.1",
+	"proprietary":                     "legacy_proprietary",
+	"by_exception_only":               "legacy_by_exception_only",
+	"not_allowed":                     "SPDX-license-identifier-SSPL",
+}
+
+// Generate returns the files of the graph described by `opts` mapping paths
+// to contents.
+//
+// Read the graph rooted at path.Join(opts.Dir, Root).
+func Generate(opts Options) (map[string][]byte, error) {
+	if opts.Nodes < 1 {
+		return nil, fmt.Errorf("need at least 1 node, got %d", opts.Nodes)
+	}
+	if opts.Nodes > 1 && opts.Depth < 1 {
+		return nil, fmt.Errorf("need a depth of at least 1 for %d nodes, got %d", opts.Nodes, opts.Depth)
+	}
+	if len(opts.Conditions) == 0 {
+		return nil, fmt.Errorf("need at least 1 license condition")
+	}
+	for _, c := range opts.Conditions {
+		if _, ok := compliance.Conditions.Condition(c); !ok {
+			return nil, fmt.Errorf("unknown license condition %q", c)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	files := make(map[string][]byte)
+
+	// level[i] is the number of edges from the root to target i, and deps[i]
+	// lists the children of target i.
+	level := make([]int, opts.Nodes)
+	deps := make([][]int, opts.Nodes)
+	var parents []int
+	if opts.Depth > 0 {
+		parents = append(parents, 0)
+	}
+	for i := 1; i < opts.Nodes; i++ {
+		p := parents[rng.Intn(len(parents))]
+		level[i] = level[p] + 1
+		deps[p] = append(deps[p], i)
+		if level[i] < opts.Depth {
+			parents = append(parents, i)
+		}
+	}
+
+	// Targets with the same condition share a license text.
+	texts := make(map[string]string)
+	for i := 0; i < opts.Nodes; i++ {
+		condition := opts.Conditions[rng.Intn(len(opts.Conditions))]
+		text, ok := texts[condition]
+		if !ok {
+			text = path.Join(opts.Dir, "licenses", strings.ToUpper(condition)+"_LICENSE")
+			texts[condition] = text
+			files[text] = []byte(fmt.Sprintf("Copyright (C) 2026 The Fixture Authors\n\nThe %s license.\n", condition))
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "package_name: %q\n", packageName(i))
+		fmt.Fprintf(&sb, "module_name: %q\n", packageName(i))
+		kind, ok := conditionKinds[condition]
+		if !ok {
+			kind = "legacy_" + condition
+		}
+		fmt.Fprintf(&sb, "license_kinds: %q\n", kind)
+		fmt.Fprintf(&sb, "license_conditions: %q\n", condition)
+		fmt.Fprintf(&sb, "license_texts: %q\n", text)
+		if i == 0 {
+			sb.WriteString("is_container: true\n")
+		}
+		fmt.Fprintf(&sb, "installed: %q\n", installPath(i, len(deps[i]) > 0))
+		if i == 0 {
+			// The container holds the files installed by every other target.
+			sb.WriteString("install_map: {\n  from_path: \"out/target/product/fictional/system/\"\n  container_path: \"/\"\n}\n")
+			for j := 1; j < opts.Nodes; j++ {
+				fmt.Fprintf(&sb, "sources: %q\n", installPath(j, len(deps[j]) > 0))
+			}
+		}
+		children := deps[i]
+		if i == 0 {
+			// The container holds every other target as well as linking its
+			// children in the tree.
+			children = nil
+			for j := 1; j < opts.Nodes; j++ {
+				children = append(children, j)
+			}
+		}
+		sort.Ints(children)
+		for _, d := range children {
+			// Link statically so every target is part of the root's notice
+			// unlike dynamically linked targets.
+			fmt.Fprintf(&sb, "deps: {\n  file: %q\n  annotations: \"static\"\n}\n", metaLic(opts.Dir, d))
+		}
+		files[metaLic(opts.Dir, i)] = []byte(sb.String())
+	}
+	return files, nil
+}
+
+// packageName returns the package name of target `i`.
+func packageName(i int) string {
+	if i == 0 {
+		return "Android"
+	}
+	return fmt.Sprintf("node%d", i)
+}
+
+// installPath returns the install path of target `i`, which is a binary when
+// it has dependencies and a library otherwise.
+func installPath(i int, hasDeps bool) string {
+	switch {
+	case i == 0:
+		return "out/target/product/fictional/system.img"
+	case hasDeps:
+		return fmt.Sprintf("out/target/product/fictional/system/bin/node%d", i)
+	}
+	return fmt.Sprintf("out/target/product/fictional/system/lib/libnode%d.so", i)
+}
+
+// metaLic returns the path to the license metadata file of target `i`.
+func metaLic(dir string, i int) string {
+	if i == 0 {
+		return path.Join(dir, Root)
+	}
+	return path.Join(dir, fmt.Sprintf("node%d.meta_lic", i))
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixturegen
+
+import (
+	"bytes"
+	"path"
+	"reflect"
+	"testing"
+
+	"android/soong/tools/compliance"
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"single", Options{Nodes: 1, Conditions: []string{"notice"}}},
+		{"flat", Options{Nodes: 10, Conditions: []string{"notice"}, Depth: 1}},
+		{"deep", Options{Nodes: 50, Conditions: []string{"notice", "reciprocal", "restricted"}, Depth: 4, Seed: 7}},
+		{"dir", Options{Nodes: 20, Conditions: []string{"proprietary", "unencumbered"}, Depth: 3, Seed: 3, Dir: "fixtures/gen"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Generate(tt.opts)
+			if err != nil {
+				t.Fatalf("Generate: error = %v", err)
+			}
+			rootFS := testfs.TestFS(files)
+			stderr := &bytes.Buffer{}
+			lg, err := compliance.ReadLicenseGraph(&rootFS, stderr, []string{path.Join(tt.opts.Dir, Root)})
+			if err != nil {
+				t.Fatalf("ReadLicenseGraph: error = %v, stderr = %v", err, stderr)
+			}
+			if len(lg.Targets()) != tt.opts.Nodes {
+				t.Errorf("Generate: got %d targets, want %d", len(lg.Targets()), tt.opts.Nodes)
+			}
+
+			// Walk every path in the graph measuring the depth of every target.
+			depth := make(map[string]int)
+			var walk func(tn *compliance.TargetNode, d int)
+			walk = func(tn *compliance.TargetNode, d int) {
+				if d > tt.opts.Depth {
+					t.Errorf("Generate: target %q at depth %d exceeds %d", tn.Name(), d, tt.opts.Depth)
+					return
+				}
+				depth[tn.Name()] = d
+				for _, e := range tn.Dependencies() {
+					walk(e.Dependency(), d+1)
+				}
+			}
+			for _, root := range lg.Targets() {
+				if root.IsContainer() {
+					walk(root, 0)
+				}
+			}
+			if len(depth) != tt.opts.Nodes {
+				t.Errorf("Generate: reached %d targets from the root, want %d", len(depth), tt.opts.Nodes)
+			}
+		})
+	}
+}
+
+func TestGenerateSeed(t *testing.T) {
+	opts := Options{Nodes: 30, Conditions: []string{"notice", "restricted"}, Depth: 3, Seed: 42}
+	first, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate: error = %v", err)
+	}
+	second, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate: error = %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Generate: got different graphs for the same seed")
+	}
+	opts.Seed++
+	other, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate: error = %v", err)
+	}
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("Generate: got the same graph for different seeds")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{Nodes: 0, Conditions: []string{"notice"}, Depth: 1},
+		{Nodes: 2, Conditions: []string{"notice"}, Depth: 0},
+		{Nodes: 2, Depth: 1},
+		{Nodes: 2, Conditions: []string{"noticeable"}, Depth: 1},
+	} {
+		if _, err := Generate(opts); err == nil {
+			t.Errorf("Generate(%+v): got no error, want error", opts)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxFossologyError limits how much of an error response body a Fossology
+// error quotes.
+const maxFossologyError = 512
+
+// fossologyAgents lists the Fossology scanners to run on submitted packages
+// and to report the findings of.
+var fossologyAgents = []string{"nomos", "monk", "ojo"}
+
+// ErrFossologyScanPending is returned by GetScanResult while Fossology is
+// still scanning the upload.
+var ErrFossologyScanPending = errors.New("fossology scan not finished")
+
+// FossologyClient submits packages to a Fossology server for license
+// scanning using its REST API.
+type FossologyClient struct {
+	// URL is the base URL of the REST API without the "/api/v1" suffix,
+	// e.g. "https://fossology.example.com/repo".
+	URL string
+	// Token authenticates the requests. The token needs write access to
+	// submit packages.
+	Token string
+	// FolderID is the folder to upload into or 0 for the root folder.
+	FolderID int64
+	// Client sends the requests or is nil to use http.DefaultClient. Tests
+	// substitute the Transport of the client.
+	Client *http.Client
+}
+
+// FossologyFinding describes the licenses and copyrights Fossology found
+// in one file of an upload.
+type FossologyFinding struct {
+	// FilePath is the path of the file within the upload.
+	FilePath string `json:"filePath"`
+	// Scanner lists the licenses the scanners found.
+	Scanner []string `json:"scanner"`
+	// Conclusion lists the licenses concluded by clearing the file.
+	Conclusion []string `json:"conclusion"`
+	// Copyright lists the copyright statements found.
+	Copyright []string `json:"copyright"`
+}
+
+// fossologyInfo is the body of most Fossology responses. Message holds the
+// upload ID after an upload.
+type fossologyInfo struct {
+	Code    int             `json:"code"`
+	Message json.RawMessage `json:"message"`
+	Type    string          `json:"type"`
+}
+
+// fossologyLicenses is one element of a GET /uploads/{id}/licenses
+// response.
+type fossologyLicenses struct {
+	FilePath string `json:"filePath"`
+	Findings struct {
+		Scanner    []string `json:"scanner"`
+		Conclusion []string `json:"conclusion"`
+		Copyright  []string `json:"copyright"`
+	} `json:"findings"`
+}
+
+// fossologyAnalysis is the body of a POST /jobs request.
+type fossologyAnalysis struct {
+	Analysis map[string]bool `json:"analysis"`
+}
+
+// SubmitPackage uploads the gzipped tarball `tarball` of package `name` at
+// `version` and schedules the license scanners on it until `ctx` is done.
+//
+// Returns the ID of the upload to pass to GetScanResult.
+func (c *FossologyClient) SubmitPackage(ctx context.Context, name, version string, tarball io.Reader) (int64, error) {
+	if len(name) == 0 {
+		return 0, fmt.Errorf("no package name to submit to Fossology")
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fileName := name
+	if len(version) > 0 {
+		fileName += "-" + version
+	}
+	part, err := mw.CreateFormFile("fileInput", fileName+".tar.gz")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(part, tarball); err != nil {
+		return 0, fmt.Errorf("could not read package %q: %w", name, err)
+	}
+	if err := mw.Close(); err != nil {
+		return 0, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/uploads", &body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("folderId", c.folderID())
+	req.Header.Set("uploadDescription", strings.TrimSpace(name+" "+version))
+	req.Header.Set("uploadType", "file")
+	req.Header.Set("public", "private")
+	var info fossologyInfo
+	if err := c.do(req, &info); err != nil {
+		return 0, err
+	}
+	uploadID, err := strconv.ParseInt(string(info.Message), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Fossology upload ID %s: %w", info.Message, err)
+	}
+
+	analysis := fossologyAnalysis{make(map[string]bool)}
+	for _, agent := range fossologyAgents {
+		analysis.Analysis[agent] = true
+	}
+	analysis.Analysis["copyright_email_author"] = true
+	jobs, err := json.Marshal(analysis)
+	if err != nil {
+		return 0, err
+	}
+	req, err = c.newRequest(ctx, http.MethodPost, "/jobs", bytes.NewReader(jobs))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("folderId", c.folderID())
+	req.Header.Set("uploadId", strconv.FormatInt(uploadID, 10))
+	if err := c.do(req, &info); err != nil {
+		return 0, fmt.Errorf("could not schedule scan of upload %d: %w", uploadID, err)
+	}
+	return uploadID, nil
+}
+
+// GetScanResult returns the findings for each file of upload `uploadID`
+// until `ctx` is done.
+//
+// Returns an error wrapping ErrFossologyScanPending until the scan
+// finishes.
+func (c *FossologyClient) GetScanResult(ctx context.Context, uploadID int64) ([]FossologyFinding, error) {
+	path := fmt.Sprintf("/uploads/%d/licenses?agent=%s&containers=false&copyright=true", uploadID, strings.Join(fossologyAgents, ","))
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var results []fossologyLicenses
+	if err := c.do(req, &results); err != nil {
+		return nil, err
+	}
+	findings := make([]FossologyFinding, 0, len(results))
+	for _, r := range results {
+		findings = append(findings, FossologyFinding{r.FilePath, r.Findings.Scanner, r.Findings.Conclusion, r.Findings.Copyright})
+	}
+	return findings, nil
+}
+
+// folderID returns the header value for the folder to upload into.
+func (c *FossologyClient) folderID() string {
+	if c.FolderID == 0 {
+		return "1"
+	}
+	return strconv.FormatInt(c.FolderID, 10)
+}
+
+// newRequest returns an authenticated request for the API endpoint `path`.
+func (c *FossologyClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if len(c.URL) == 0 {
+		return nil, fmt.Errorf("no Fossology URL to submit to")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.URL, "/")+"/api/v1"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Fossology URL %q: %w", c.URL, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do sends `req` and decodes the JSON response into `v`.
+func (c *FossologyClient) do(req *http.Request, v interface{}) error {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach Fossology at %q: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable && req.Method == http.MethodGet {
+		// Fossology responds 503 with a Retry-After header until the scanners finish.
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("%w (retry after %q)", ErrFossologyScanPending, resp.Header.Get("Retry-After"))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxFossologyError))
+		return fmt.Errorf("Fossology request %s %q failed: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid Fossology response to %s %q: %w", req.Method, req.URL.Path, err)
+	}
+	// Drain the body so the client can reuse the connection.
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc implements http.RoundTripper by calling the function.
+type roundTripFunc func(*http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// fossologyFake returns a client sending the requests to `handler`.
+func fossologyFake(handler http.HandlerFunc) *FossologyClient {
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Result()
+	})}
+	return &FossologyClient{URL: "https://fossology.example.com/repo/", Token: "secret", FolderID: 3, Client: client}
+}
+
+func TestFossologySubmitPackage(t *testing.T) {
+	var requests []string
+	c := fossologyFake(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("SubmitPackage: got Authorization %q, want %q", auth, "Bearer secret")
+		}
+		if folder := r.Header.Get("folderId"); folder != "3" {
+			t.Errorf("SubmitPackage: got folderId %q, want %q", folder, "3")
+		}
+		switch r.URL.Path {
+		case "/repo/api/v1/uploads":
+			if desc := r.Header.Get("uploadDescription"); desc != "fictional 1.0" {
+				t.Errorf("SubmitPackage: got uploadDescription %q, want %q", desc, "fictional 1.0")
+			}
+			f, fh, err := r.FormFile("fileInput")
+			if err != nil {
+				t.Fatalf("SubmitPackage: got no fileInput: %s", err)
+			}
+			content, _ := io.ReadAll(f)
+			if fh.Filename != "fictional-1.0.tar.gz" || string(content) != "tarball" {
+				t.Errorf("SubmitPackage: got file %q %q, want %q %q", fh.Filename, content, "fictional-1.0.tar.gz", "tarball")
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"code": 201, "message": 42, "type": "INFO"}`))
+		case "/repo/api/v1/jobs":
+			if upload := r.Header.Get("uploadId"); upload != "42" {
+				t.Errorf("SubmitPackage: got uploadId %q, want %q", upload, "42")
+			}
+			var analysis fossologyAnalysis
+			if err := json.NewDecoder(r.Body).Decode(&analysis); err != nil {
+				t.Errorf("SubmitPackage: got invalid jobs body: %s", err)
+			}
+			for _, agent := range fossologyAgents {
+				if !analysis.Analysis[agent] {
+					t.Errorf("SubmitPackage: got analysis %v, want %s", analysis.Analysis, agent)
+				}
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"code": 201, "message": 7, "type": "INFO"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	uploadID, err := c.SubmitPackage(context.Background(), "fictional", "1.0", strings.NewReader("tarball"))
+	if err != nil {
+		t.Fatalf("SubmitPackage: got error %s, want no error", err)
+	}
+	if uploadID != 42 {
+		t.Errorf("SubmitPackage: got upload ID %d, want 42", uploadID)
+	}
+	expected := []string{"POST /repo/api/v1/uploads", "POST /repo/api/v1/jobs"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("SubmitPackage: got requests %q, want %q", requests, expected)
+	}
+}
+
+func TestFossologyGetScanResult(t *testing.T) {
+	pending := true
+	c := fossologyFake(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/repo/api/v1/uploads/42/licenses" {
+			t.Errorf("GetScanResult: got %s %s, want GET /repo/api/v1/uploads/42/licenses", r.Method, r.URL.Path)
+		}
+		if agent := r.URL.Query().Get("agent"); agent != "nomos,monk,ojo" {
+			t.Errorf("GetScanResult: got agent %q, want %q", agent, "nomos,monk,ojo")
+		}
+		if pending {
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[
+			{"filePath": "fictional/NOTICE", "findings": {"scanner": ["Apache-2.0"], "conclusion": ["Apache-2.0"], "copyright": ["Copyright 2026 Google LLC"]}, "clearing_status": "Identified"},
+			{"filePath": "fictional/lib.meta_lic", "findings": {"scanner": ["No_license_found"], "conclusion": null, "copyright": null}, "clearing_status": "NotIdentified"}
+		]`))
+	})
+
+	_, err := c.GetScanResult(context.Background(), 42)
+	if !errors.Is(err, ErrFossologyScanPending) {
+		t.Fatalf("GetScanResult(pending): got error %v, want ErrFossologyScanPending", err)
+	}
+
+	pending = false
+	findings, err := c.GetScanResult(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetScanResult: got error %s, want no error", err)
+	}
+	expected := []FossologyFinding{
+		{"fictional/NOTICE", []string{"Apache-2.0"}, []string{"Apache-2.0"}, []string{"Copyright 2026 Google LLC"}},
+		{"fictional/lib.meta_lic", []string{"No_license_found"}, nil, nil},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("GetScanResult: got %+v, want %+v", findings, expected)
+	}
+}
+
+func TestFossologyErrors(t *testing.T) {
+	unauthorized := fossologyFake(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 403, "message": "Access denied", "type": "ERROR"}`, http.StatusForbidden)
+	})
+	invalid := fossologyFake(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code": 201, "message": "not an id", "type": "INFO"}`))
+	})
+
+	tests := []struct {
+		name     string
+		client   *FossologyClient
+		pkg      string
+		expected string
+	}{
+		{"unauthorized", unauthorized, "fictional", `403 Forbidden: {"code": 403, "message": "Access denied", "type": "ERROR"}`},
+		{"invalidid", invalid, "fictional", `invalid Fossology upload ID "not an id"`},
+		{"nourl", &FossologyClient{Token: "secret"}, "fictional", "no Fossology URL"},
+		{"nopackage", unauthorized, "", "no package name"},
+		{"badurl", &FossologyClient{URL: "http://[::1"}, "fictional", "invalid Fossology URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.SubmitPackage(context.Background(), tt.pkg, "", strings.NewReader("tarball"))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("SubmitPackage: got error %v, want error containing %q", err, tt.expected)
+			}
+		})
+	}
+}
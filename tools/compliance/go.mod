@@ -1,15 +1,40 @@
 module android/soong/tools/compliance
 
-require google.golang.org/protobuf v0.0.0
+require google.golang.org/protobuf v1.30.0
 
-replace google.golang.org/protobuf v0.0.0 => ../../../../external/golang-protobuf
+// Unversioned so that the protobuf version grpc requires resolves to the
+// same copy.
+replace google.golang.org/protobuf => ../../../../external/golang-protobuf
 
 require (
 	android/soong v0.0.0
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/google/blueprint v0.0.0
+	github.com/google/go-containerregistry v0.20.2
+	golang.org/x/exp v0.0.0-20230321023759-10a507213a29
+	google.golang.org/grpc v1.57.1
 )
 
-require golang.org/x/xerrors v0.0.0-20220609144429-65e65417b02f // indirect
+require (
+	github.com/containerd/stargz-snapshotter/estargz v0.14.3 // indirect
+	github.com/docker/cli v27.1.1+incompatible // indirect
+	github.com/docker/distribution v2.8.2+incompatible // indirect
+	github.com/docker/docker-credential-helpers v0.7.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/klauspost/compress v1.16.5 // indirect
+	github.com/mitchellh/go-homedir v1.1.0 // indirect
+	github.com/opencontainers/go-digest v1.0.0 // indirect
+	github.com/opencontainers/image-spec v1.1.0-rc3 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/sirupsen/logrus v1.9.1 // indirect
+	github.com/vbatts/tar-split v0.11.3 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sync v0.2.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	golang.org/x/xerrors v0.0.0-20220609144429-65e65417b02f // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19 // indirect
+)
 
 replace android/soong v0.0.0 => ../../../soong
 
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goldentest compares test output with golden files under
+// testdata/golden/ relative to the working directory of the test.
+//
+// Setting the UPDATE_GOLDEN environment variable makes CheckGolden rewrite
+// the golden files with the actual output instead, e.g.
+//
+//	UPDATE_GOLDEN=1 go test ./cmd/textnotice/
+//
+// Review the changes to the golden files like any other change.
+package goldentest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Dir is the directory holding golden files relative to the working
+// directory of the test.
+const Dir = "testdata/golden"
+
+// UpdateEnv names the environment variable that makes CheckGolden update the
+// golden files.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Path returns the path to golden file `name`.
+func Path(name string) string {
+	return filepath.Join(Dir, filepath.FromSlash(name))
+}
+
+// UpdateGolden writes `got` to golden file `name` creating any missing
+// directories.
+func UpdateGolden(t testing.TB, name, got string) {
+	t.Helper()
+	path := Path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatalf("goldentest: cannot create directory for %q: %s", path, err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(got), 0666); err != nil {
+		t.Fatalf("goldentest: cannot write %q: %s", path, err)
+	}
+}
+
+// CheckGolden reports an error when `got` differs from golden file `name`,
+// or updates the golden file when the UPDATE_GOLDEN environment variable is
+// set.
+func CheckGolden(t testing.TB, name, got string) {
+	t.Helper()
+	if len(os.Getenv(UpdateEnv)) > 0 {
+		UpdateGolden(t, name, got)
+		return
+	}
+	path := Path(name)
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("goldentest: cannot read %q: %s (set %s=1 to create it)", path, err, UpdateEnv)
+		return
+	}
+	if diff := Diff(string(want), got); len(diff) > 0 {
+		t.Errorf("goldentest: output differs from %q (set %s=1 to update it):\n%s", path, UpdateEnv, diff)
+	}
+}
+
+// Diff describes the first line where `got` differs from `want`, or returns
+// the empty string when they are the same.
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = fmt.Sprintf("%q", wantLines[i])
+		} else {
+			w = "end of file"
+		}
+		if i < len(gotLines) {
+			g = fmt.Sprintf("%q", gotLines[i])
+		} else {
+			g = "end of file"
+		}
+		if w != g {
+			return fmt.Sprintf("line %d: got %s, want %s (got %d lines, want %d)", i+1, g, w, len(gotLines), len(wantLines))
+		}
+	}
+	return ""
+}
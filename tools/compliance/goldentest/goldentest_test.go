@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goldentest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// recordingT records the errors reported by CheckGolden.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckGolden(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("cannot get working directory: %s", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("cannot change to temporary directory: %s", err)
+	}
+	defer os.Chdir(wd)
+	t.Setenv(UpdateEnv, "")
+
+	r := &recordingT{TB: t}
+	CheckGolden(r, "a/missing.txt", "text\n")
+	if len(r.errors) != 1 {
+		t.Errorf("got errors %q for missing golden file, want 1 error", r.errors)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	r = &recordingT{TB: t}
+	CheckGolden(r, "a/missing.txt", "text\n")
+	if len(r.errors) != 0 {
+		t.Errorf("got errors %q updating golden file, want none", r.errors)
+	}
+	if data, err := os.ReadFile("testdata/golden/a/missing.txt"); err != nil || string(data) != "text\n" {
+		t.Errorf("got golden file %q, %v, want \"text\\n\"", string(data), err)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	r = &recordingT{TB: t}
+	CheckGolden(r, "a/missing.txt", "text\n")
+	if len(r.errors) != 0 {
+		t.Errorf("got errors %q for matching output, want none", r.errors)
+	}
+	CheckGolden(r, "a/missing.txt", "changed\n")
+	if len(r.errors) != 1 {
+		t.Errorf("got errors %q for changed output, want 1 error", r.errors)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		want, got, expected string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nb\n", "a\nc\n", `line 2: got "c", want "b" (got 3 lines, want 3)`},
+		{"a\n", "a\nb\n", `line 2: got "b", want "" (got 3 lines, want 2)`},
+		{"a\nb", "a", `line 2: got end of file, want "b" (got 1 lines, want 2)`},
+	}
+	for _, tt := range tests {
+		if actual := Diff(tt.want, tt.got); actual != tt.expected {
+			t.Errorf("Diff(%q, %q): got %q, want %q", tt.want, tt.got, actual, tt.expected)
+		}
+	}
+}
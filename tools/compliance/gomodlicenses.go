@@ -0,0 +1,438 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// licenseMatchThreshold is the SimilarityScore at which a license text
+// identifies as a bundled SPDX license.
+const licenseMatchThreshold = 0.85
+
+// maxGoModZip limits the size of a module zip file like the go command.
+const maxGoModZip = 500 << 20
+
+// goModLicenseFile matches the names of the license files at the root of a
+// module like pkg.go.dev, e.g. LICENSE, COPYING.md or LICENSE-APACHE.
+var goModLicenseFile = regexp.MustCompile(`(?i)^(UN)?(LICEN[CS]E|COPYING|MIT[-_]LICEN[CS]E)([-._][-._a-z0-9]*)?$`)
+
+// GoModLicenseExtractor finds the license files of the modules a go.mod
+// requires in a module cache, optionally downloading missing modules from a
+// module proxy, and describes each module with a MetaLicStub.
+type GoModLicenseExtractor struct {
+	// CacheDir holds the extracted modules in the layout of the go command
+	// module cache, e.g. "gopkg.in/yaml.v3@v3.0.1/LICENSE", so that
+	// $(go env GOMODCACHE) works as is.
+	CacheDir string
+	// Proxy is the base URL of the module proxy to download missing modules
+	// from, e.g. "https://proxy.golang.org", or empty to use only CacheDir.
+	Proxy string
+	// Client sends the requests to Proxy or is nil to use
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// goModule identifies a module required by a go.mod.
+type goModule struct {
+	path    string
+	version string
+	// dir is the local directory replacing the module or empty.
+	dir string
+}
+
+// ExtractGoModLicenses returns a MetaLicStub for each module required by the
+// go.mod file `modFile` using the license files in the module cache
+// `cacheDir` without downloading anything.
+//
+// Every module must have a go.sum entry in `sumFile`.
+func ExtractGoModLicenses(modFile, sumFile string, cacheDir string) ([]MetaLicStub, error) {
+	e := &GoModLicenseExtractor{CacheDir: cacheDir}
+	return e.Extract(context.Background(), modFile, sumFile)
+}
+
+// Extract returns a MetaLicStub for each module required by the go.mod file
+// `modFile` ordered by module path, downloading the modules missing from the
+// cache when Proxy is set until `ctx` is done.
+//
+// Every module must have a go.sum entry in `sumFile`, which downloads must
+// match. Modules replaced by local directories use the license files in the
+// directories.
+func (e *GoModLicenseExtractor) Extract(ctx context.Context, modFile, sumFile string) ([]MetaLicStub, error) {
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := parseGoMod(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %q: %w", modFile, err)
+	}
+	data, err = os.ReadFile(sumFile)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := parseGoSum(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %q: %w", sumFile, err)
+	}
+
+	var missing []string
+	for _, m := range modules {
+		if len(m.dir) == 0 && len(sums[m.path+" "+m.version]) == 0 {
+			missing = append(missing, m.path+"@"+m.version)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing go.sum entry for module(s) %s", strings.Join(missing, ", "))
+	}
+
+	stubs := make([]MetaLicStub, 0, len(modules))
+	for _, m := range modules {
+		dir := m.dir
+		if len(dir) == 0 {
+			dir, err = e.moduleDir(ctx, m, sums[m.path+" "+m.version])
+			if err != nil {
+				return nil, err
+			}
+		} else if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(modFile), dir)
+		}
+		stub, err := goModuleStub(m, dir)
+		if err != nil {
+			return nil, err
+		}
+		stubs = append(stubs, stub)
+	}
+	return stubs, nil
+}
+
+// moduleDir returns the directory holding module `m` in the cache,
+// downloading the module when missing and Proxy is set.
+func (e *GoModLicenseExtractor) moduleDir(ctx context.Context, m goModule, sum string) (string, error) {
+	escaped, err := escapeModulePath(m.path + "@" + m.version)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(e.CacheDir, filepath.FromSlash(escaped))
+	if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+		return dir, nil
+	}
+	if len(e.Proxy) == 0 {
+		return "", fmt.Errorf("module %s@%s not in cache %q", m.path, m.version, e.CacheDir)
+	}
+	if err := e.download(ctx, m, sum, dir); err != nil {
+		return "", fmt.Errorf("could not download module %s@%s: %w", m.path, m.version, err)
+	}
+	return dir, nil
+}
+
+// download fetches the zip file of module `m` from Proxy, checks it matches
+// the go.sum hash `sum`, and extracts the license files at its root into
+// `dir`.
+func (e *GoModLicenseExtractor) download(ctx context.Context, m goModule, sum, dir string) error {
+	escapedPath, err := escapeModulePath(m.path)
+	if err != nil {
+		return err
+	}
+	escapedVersion, err := escapeModulePath(m.version)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(e.Proxy, "/") + "/" + escapedPath + "/@v/" + escapedVersion + ".zip"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid module proxy URL %q: %w", e.Proxy, err)
+	}
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %q: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxGoModZip+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxGoModZip {
+		return fmt.Errorf("module zip larger than %d bytes", maxGoModZip)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	hash, err := hashGoModZip(zr)
+	if err != nil {
+		return err
+	}
+	if hash != sum {
+		return fmt.Errorf("checksum mismatch: downloaded %s, go.sum %s", hash, sum)
+	}
+
+	// Extract into a temporary directory renamed into place so that an
+	// interrupted download leaves no partial module in the cache.
+	if err := os.MkdirAll(filepath.Dir(dir), 0777); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	prefix := m.path + "@" + m.version + "/"
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(f.Name, prefix)
+		if name == f.Name || strings.Contains(name, "/") || !goModLicenseFile.MatchString(name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), content, 0666); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, dir)
+}
+
+// goModuleStub returns the MetaLicStub for module `m` using the license
+// files at the root of `dir`.
+func goModuleStub(m goModule, dir string) (MetaLicStub, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return MetaLicStub{}, err
+	}
+	name := m.path
+	if len(m.version) > 0 {
+		name += "@" + m.version
+	}
+	stub := MetaLicStub{Name: name + ".meta_lic", PackageName: m.path, Version: m.version}
+	kinds := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !goModLicenseFile.MatchString(entry.Name()) {
+			continue
+		}
+		text := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(text)
+		if err != nil {
+			return MetaLicStub{}, err
+		}
+		kind, condition := identifyLicense(string(content), licenseMatchThreshold)
+		kinds[kind] = condition
+		stub.LicenseTexts = append(stub.LicenseTexts, filepath.ToSlash(text))
+	}
+	conditions := make(map[string]struct{})
+	for kind, condition := range kinds {
+		stub.LicenseKinds = append(stub.LicenseKinds, kind)
+		conditions[condition] = struct{}{}
+	}
+	for condition := range conditions {
+		stub.LicenseConditions = append(stub.LicenseConditions, condition)
+	}
+	sort.Strings(stub.LicenseKinds)
+	sort.Strings(stub.LicenseConditions)
+	return stub, nil
+}
+
+// parseGoMod returns the modules required by the go.mod file content
+// `data` after applying its replace directives, ordered by module path.
+func parseGoMod(data []byte) ([]goModule, error) {
+	var required []goModule
+	// replace maps "path version" and "path" to the replacement.
+	replace := make(map[string]goModule)
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		verb := block
+		if len(block) == 0 {
+			verb, fields = fields[0], fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = verb
+				continue
+			}
+		} else if fields[0] == ")" {
+			block = ""
+			continue
+		}
+		for i, field := range fields {
+			if strings.HasPrefix(field, `"`) {
+				unquoted, err := strconv.Unquote(field)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid quoted string %s", lineno, field)
+				}
+				fields[i] = unquoted
+			}
+		}
+		switch verb {
+		case "require":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: want require path version", lineno)
+			}
+			required = append(required, goModule{path: fields[0], version: fields[1]})
+		case "replace":
+			arrow := -1
+			for i, field := range fields {
+				if field == "=>" {
+					arrow = i
+				}
+			}
+			if arrow < 1 || arrow > 2 || len(fields)-arrow < 2 || len(fields)-arrow > 3 {
+				return nil, fmt.Errorf("line %d: want replace path [version] => path [version]", lineno)
+			}
+			key := strings.Join(fields[:arrow], " ")
+			to := fields[arrow+1:]
+			if len(to) == 1 {
+				if !isLocalGoModPath(to[0]) {
+					return nil, fmt.Errorf("line %d: replacement module %s needs a version", lineno, to[0])
+				}
+				replace[key] = goModule{path: fields[0], dir: to[0]}
+			} else {
+				replace[key] = goModule{path: to[0], version: to[1]}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	modules := make([]goModule, 0, len(required))
+	for _, m := range required {
+		if r, ok := replace[m.path+" "+m.version]; ok {
+			m = r
+		} else if r, ok := replace[m.path]; ok {
+			m = r
+		}
+		modules = append(modules, m)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		if modules[i].path != modules[j].path {
+			return modules[i].path < modules[j].path
+		}
+		return modules[i].version < modules[j].version
+	})
+	return modules, nil
+}
+
+// isLocalGoModPath returns true when replacement `p` names a directory
+// rather than a module.
+func isLocalGoModPath(p string) bool {
+	return strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || path.IsAbs(p) || filepath.IsAbs(p)
+}
+
+// parseGoSum returns the go.sum file content `data` as a map from "path
+// version" to the "h1:" hash of the module zip.
+func parseGoSum(data []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: want path version hash", lineno)
+		}
+		// Skip the hashes of the go.mod files alone.
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// escapeModulePath returns module path or version `p` with each upper-case
+// letter replaced by "!" and the lower-case letter as in the module cache
+// and module proxy protocol, e.g. "github.com/!azure" for
+// "github.com/Azure".
+func escapeModulePath(p string) (string, error) {
+	var sb strings.Builder
+	for _, r := range p {
+		switch {
+		case r == '!' || r >= 0x80:
+			return "", fmt.Errorf("invalid module path or version %q", p)
+		case 'A' <= r && r <= 'Z':
+			sb.WriteByte('!')
+			sb.WriteRune(r + 'a' - 'A')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), nil
+}
+
+// hashGoModZip returns the "h1:" hash go.sum records for the module zip
+// `zr`: the base64 SHA-256 of the sorted lines of SHA-256 and name of each
+// file.
+func hashGoModZip(zr *zip.Reader) (string, error) {
+	files := make([]*zip.File, len(zr.File))
+	copy(files, zr.File)
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	h := sha256.New()
+	for _, f := range files {
+		if strings.Contains(f.Name, "\n") {
+			return "", fmt.Errorf("invalid file name %q in module zip", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		hf := sha256.New()
+		_, err = io.Copy(hf, rc)
+		rc.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%x  %s\n", hf.Sum(nil), f.Name)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	// fixtureGoMod requires modules with each kind of license file name and
+	// a module replaced by a local directory.
+	fixtureGoMod = `module example.com/android/tool
+
+go 1.21
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require example.com/forked v1.0.0
+
+replace example.com/forked => ./third_party/forked
+`
+
+	// fixtureGoSum lists the go.sum entries for fixtureGoMod.
+	fixtureGoSum = `github.com/BurntSushi/toml v1.3.2 h1:o7IhLm0Msx3BaB+n3Ag7L8EVlByGnpq14C4YWiu/gL8=
+github.com/BurntSushi/toml v1.3.2/go.mod h1:CxXYINrC8qIiEnFrOxCa7Jy5BFHlXnUU2pbicEuybxQ=
+golang.org/x/text v0.14.0 h1:ScX5w1eTa3QqT8oi6+ziP7dTV1S2+ALU0bI+0zXKWiQ=
+golang.org/x/text v0.14.0/go.mod h1:18ZOQIKpY8NJVqYksKHtTdi31H5itFRjB5/qKTNYzSU=
+gopkg.in/yaml.v3 v3.0.1 h1:fxVm/GzAzEWqLHuvctI91KS9hhNmmWOoWu0XTYJS7CA=
+gopkg.in/yaml.v3 v3.0.1/go.mod h1:K4uyk7z7BCEPqu6E+C64Yfv1cQ7kz7rIZviUmN+EgEM=
+`
+)
+
+// writeGoModFixture writes the go.mod, go.sum, local replacement and module
+// cache for fixtureGoMod under `dir` and returns the paths to the go.mod,
+// go.sum and cache.
+func writeGoModFixture(t *testing.T, dir string) (string, string, string) {
+	t.Helper()
+	mit, err := FetchLicenseText("MIT", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bsd, err := FetchLicenseText("BSD-3-Clause", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apache, err := FetchLicenseText("Apache-2.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := filepath.Join(dir, "cache")
+	files := map[string]string{
+		"go.mod":                     fixtureGoMod,
+		"go.sum":                     fixtureGoSum,
+		"third_party/forked/LICENSE": apache,
+		"cache/github.com/!burnt!sushi/toml@v1.3.2/COPYING": strings.Replace(mit, "<year> <copyright holders>", "2013 TOML authors", 1),
+		"cache/golang.org/x/text@v0.14.0/LICENSE":           strings.Replace(bsd, "<year> <owner>", "2009 The Go Authors", 1),
+		"cache/golang.org/x/text@v0.14.0/README.md":         "# Go Text\n",
+		"cache/gopkg.in/yaml.v3@v3.0.1/LICENSE":             "This project is covered by two different licenses: MIT and Apache.\n",
+		"cache/gopkg.in/yaml.v3@v3.0.1/NOTICE":              "Copyright 2011-2016 Canonical Ltd.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "go.mod"), filepath.Join(dir, "go.sum"), cache
+}
+
+func TestExtractGoModLicenses(t *testing.T) {
+	dir := t.TempDir()
+	modFile, sumFile, cache := writeGoModFixture(t, dir)
+
+	stubs, err := ExtractGoModLicenses(modFile, sumFile, cache)
+	if err != nil {
+		t.Fatalf("ExtractGoModLicenses(): got error %s, want no error", err)
+	}
+	expected := []MetaLicStub{
+		{
+			Name:              "example.com/forked.meta_lic",
+			PackageName:       "example.com/forked",
+			LicenseKinds:      []string{"SPDX-license-identifier-Apache-2.0"},
+			LicenseConditions: []string{"notice"},
+			LicenseTexts:      []string{filepath.ToSlash(filepath.Join(dir, "third_party/forked/LICENSE"))},
+		},
+		{
+			Name:              "github.com/BurntSushi/toml@v1.3.2.meta_lic",
+			PackageName:       "github.com/BurntSushi/toml",
+			Version:           "v1.3.2",
+			LicenseKinds:      []string{"SPDX-license-identifier-MIT"},
+			LicenseConditions: []string{"notice"},
+			LicenseTexts:      []string{filepath.ToSlash(filepath.Join(cache, "github.com/!burnt!sushi/toml@v1.3.2/COPYING"))},
+		},
+		{
+			Name:              "golang.org/x/text@v0.14.0.meta_lic",
+			PackageName:       "golang.org/x/text",
+			Version:           "v0.14.0",
+			LicenseKinds:      []string{"SPDX-license-identifier-BSD-3-Clause"},
+			LicenseConditions: []string{"notice"},
+			LicenseTexts:      []string{filepath.ToSlash(filepath.Join(cache, "golang.org/x/text@v0.14.0/LICENSE"))},
+		},
+		{
+			Name:              "gopkg.in/yaml.v3@v3.0.1.meta_lic",
+			PackageName:       "gopkg.in/yaml.v3",
+			Version:           "v3.0.1",
+			LicenseKinds:      []string{"legacy_by_exception_only"},
+			LicenseConditions: []string{"by_exception_only"},
+			LicenseTexts:      []string{filepath.ToSlash(filepath.Join(cache, "gopkg.in/yaml.v3@v3.0.1/LICENSE"))},
+		},
+	}
+	if !reflect.DeepEqual(stubs, expected) {
+		t.Errorf("ExtractGoModLicenses(): got %+v, want %+v", stubs, expected)
+	}
+
+	// The stubs work as the roots of a notice.
+	paths, err := WriteMetaLicStubs(filepath.Join(dir, "stubs"), stubs)
+	if err != nil {
+		t.Fatalf("WriteMetaLicStubs(): got error %s, want no error", err)
+	}
+	lg, err := ReadLicenseGraph(FS, &bytes.Buffer{}, paths)
+	if err != nil {
+		t.Fatalf("ReadLicenseGraph(stubs): got error %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(FS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(stubs): got error %s, want no error", err)
+	}
+	expectedLibs := []string{"example.com/forked", "github.com/BurntSushi/toml", "golang.org/x/text", "gopkg.in/yaml.v3"}
+	if libs := ni.AllLibraries(); !reflect.DeepEqual(libs, expectedLibs) {
+		t.Errorf("IndexLicenseTexts(stubs): got libraries %q, want %q", libs, expectedLibs)
+	}
+	if hashes := ni.AllHashes(); len(hashes) != 4 {
+		t.Errorf("IndexLicenseTexts(stubs): got %d texts, want 4", len(hashes))
+	}
+}
+
+func TestExtractGoModLicensesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		goMod    string
+		goSum    string
+		expected string
+	}{
+		{"missingsum", fixtureGoMod, "gopkg.in/yaml.v3 v3.0.1 h1:fxVm/GzAzEWqLHuvctI91KS9hhNmmWOoWu0XTYJS7CA=\n", "missing go.sum entry for module(s) github.com/BurntSushi/toml@v1.3.2, golang.org/x/text@v0.14.0"},
+		{"notcached", "module m\nrequire example.com/absent v1.0.0\n", "example.com/absent v1.0.0 h1:AAAA\n", "module example.com/absent@v1.0.0 not in cache"},
+		{"badrequire", "module m\nrequire example.com/absent\n", "", "line 2: want require path version"},
+		{"badreplace", "module m\nreplace a => b\n", "", "line 2: replacement module b needs a version"},
+		{"badsum", fixtureGoMod, "gopkg.in/yaml.v3 v3.0.1\n", "line 1: want path version hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			_, _, cache := writeGoModFixture(t, dir)
+			modFile := filepath.Join(dir, "go.mod")
+			sumFile := filepath.Join(dir, "go.sum")
+			os.WriteFile(modFile, []byte(tt.goMod), 0666)
+			os.WriteFile(sumFile, []byte(tt.goSum), 0666)
+			_, err := ExtractGoModLicenses(modFile, sumFile, cache)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("ExtractGoModLicenses(): got error %v, want error containing %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGoModLicenseExtractorDownload(t *testing.T) {
+	// moduleZip holds the files of example.com/Upper@v1.0.0 whose go.sum
+	// hash is zipSum.
+	var moduleZip bytes.Buffer
+	zw := zip.NewWriter(&moduleZip)
+	for _, f := range []struct{ name, content string }{
+		{"example.com/Upper@v1.0.0/LICENSE", "Do as you please.\n"},
+		{"example.com/Upper@v1.0.0/go.mod", "module example.com/Upper\n"},
+		{"example.com/Upper@v1.0.0/sub/LICENSE", "Not at the root.\n"},
+	} {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f.content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	const zipSum = "h1:MUhVx5g1WPY7hBz0s6seExBh35RJ2zMYqWPnSWTJl7Y="
+
+	var requests []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		requests = append(requests, req.URL.String())
+		w := httptest.NewRecorder()
+		if req.URL.Path == "/proxy/example.com/!upper/@v/v1.0.0.zip" {
+			w.Write(moduleZip.Bytes())
+		} else {
+			http.NotFound(w, req)
+		}
+		return w.Result()
+	})}
+
+	dir := t.TempDir()
+	modFile := filepath.Join(dir, "go.mod")
+	sumFile := filepath.Join(dir, "go.sum")
+	cache := filepath.Join(dir, "cache")
+	os.WriteFile(modFile, []byte("module m\nrequire example.com/Upper v1.0.0\n"), 0666)
+
+	e := &GoModLicenseExtractor{CacheDir: cache, Proxy: "https://proxy.example.com/proxy/", Client: client}
+
+	os.WriteFile(sumFile, []byte("example.com/Upper v1.0.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"), 0666)
+	if _, err := e.Extract(context.Background(), modFile, sumFile); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Extract(bad sum): got error %v, want checksum mismatch", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(cache, "example.com")); len(entries) != 0 {
+		t.Errorf("Extract(bad sum): got cache entries %v, want none", entries)
+	}
+
+	os.WriteFile(sumFile, []byte("example.com/Upper v1.0.0 "+zipSum+"\n"), 0666)
+	stubs, err := e.Extract(context.Background(), modFile, sumFile)
+	if err != nil {
+		t.Fatalf("Extract(): got error %s, want no error", err)
+	}
+	text := filepath.Join(cache, "example.com", "!upper@v1.0.0", "LICENSE")
+	if len(stubs) != 1 || !reflect.DeepEqual(stubs[0].LicenseTexts, []string{filepath.ToSlash(text)}) {
+		t.Errorf("Extract(): got %+v, want the root LICENSE in the cache", stubs)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(text)); len(entries) != 1 {
+		t.Errorf("Extract(): got cached files %v, want only LICENSE", entries)
+	}
+
+	// The second run uses the cache.
+	requests = nil
+	if _, err := e.Extract(context.Background(), modFile, sumFile); err != nil {
+		t.Fatalf("Extract(cached): got error %s, want no error", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("Extract(cached): got requests %q, want none", requests)
+	}
+}
@@ -68,10 +68,68 @@ type LicenseGraph struct {
 	// distributed either directly or as derivative works. (creation guarded by mu)
 	shippedNodes *TargetNodeSet
 
+	// copyleftStaticOnly is true when restricted conditions propagate only
+	// along static links. (set before resolving)
+	copyleftStaticOnly bool
+
+	// walkLookups counts the targets visited by the resolve walks, and
+	// walkHits counts the visits answered by earlier walk results. (guarded by
+	// onceBottomUp and onceTopDown)
+	walkLookups, walkHits int
+
+	// trace receives the edges traversed while resolving when not nil. (set
+	// before resolving)
+	trace EdgeTraceFunc
+
+	// progress receives progress reports when not nil. (immutable)
+	progress ProgressFunc
+
 	// mu guards against concurrent update.
 	mu sync.Mutex
 }
 
+// SkipBuildtimeDeps makes policy ignore the `buildtime` and `test` edges of
+// the graph so that license conditions neither propagate nor attach across
+// them and their dependencies do not ship with the targets.
+//
+// Must be called before resolving or walking the graph.
+func (lg *LicenseGraph) SkipBuildtimeDeps() {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	for _, e := range lg.edges {
+		if e.depType != DepTypeRuntime {
+			e.skipped = true
+		}
+	}
+}
+
+// PropagateCopyleftStaticOnly makes policy propagate restricted conditions
+// only along static links. Instead of inheriting the restricted condition, a
+// target dynamically linking a restricted dependency gets the
+// `dynamic_link_warning` condition, and no conditions attach across dynamic
+// links.
+//
+// Must be called before resolving or walking the graph.
+func (lg *LicenseGraph) PropagateCopyleftStaticOnly() {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	lg.copyleftStaticOnly = true
+}
+
+// EdgeTraceFunc receives an edge traversed while resolving the graph and the
+// license conditions of the dependency that apply across the edge.
+type EdgeTraceFunc func(edge *TargetEdge, conditions LicenseConditionSet)
+
+// TraceEdges makes the resolution walks report each edge they traverse to
+// `trace`. e.g. to explain why a condition applies to a target.
+//
+// Must be called before resolving or walking the graph.
+func (lg *LicenseGraph) TraceEdges(trace EdgeTraceFunc) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	lg.trace = trace
+}
+
 // Edges returns the list of edges in the graph. (unordered)
 func (lg *LicenseGraph) Edges() TargetEdgeList {
 	edges := make(TargetEdgeList, 0, len(lg.edges))
@@ -120,6 +178,68 @@ type TargetEdge struct {
 
 	// annotations identifies the set of compliance-relevant annotations describing the edge.
 	annotations TargetEdgeAnnotations
+
+	// depType identifies when the target needs the dependency.
+	depType DepType
+
+	// linkage identifies how the target links the dependency.
+	linkage Linkage
+
+	// skipped is true when policy ignores the edge. (guarded by graph mu)
+	skipped bool
+}
+
+// DepType identifies when a target needs a dependency.
+type DepType string
+
+const (
+	// DepTypeRuntime identifies dependencies shipped with or used by the
+	// target at runtime. (default)
+	DepTypeRuntime = DepType("runtime")
+
+	// DepTypeBuildtime identifies dependencies like host tools used only to
+	// build the target.
+	DepTypeBuildtime = DepType("buildtime")
+
+	// DepTypeTest identifies dependencies used only to test the target.
+	DepTypeTest = DepType("test")
+)
+
+// Linkage identifies how a target links a dependency.
+type Linkage string
+
+const (
+	// LinkageStatic identifies dependencies built into the target. (default)
+	LinkageStatic = Linkage("static")
+
+	// LinkageDynamic identifies dependencies linked dynamically at runtime.
+	LinkageDynamic = Linkage("dynamic")
+
+	// LinkageSharedLibrary identifies shared libraries installed separately
+	// from and loaded by the target at runtime.
+	LinkageSharedLibrary = Linkage("shared_library")
+)
+
+// newLinkage returns the linkage described by `annotations`.
+func newLinkage(annotations TargetEdgeAnnotations) Linkage {
+	if annotations.HasAnnotation("shared_library") {
+		return LinkageSharedLibrary
+	}
+	if annotations.HasAnnotation("dynamic") {
+		return LinkageDynamic
+	}
+	return LinkageStatic
+}
+
+// newDepType returns the dependency type described by `annotations`.
+func newDepType(annotations TargetEdgeAnnotations) DepType {
+	if annotations.HasAnnotation("test") {
+		return DepTypeTest
+	}
+	if annotations.HasAnnotation("buildtime") || annotations.HasAnnotation("toolchain") {
+		return DepTypeBuildtime
+	}
+	return DepTypeRuntime
 }
 
 // Target identifies the target that depends on the dependency.
@@ -146,6 +266,18 @@ func (e *TargetEdge) Annotations() TargetEdgeAnnotations {
 	return e.annotations
 }
 
+// DepType identifies when the target needs the dependency: at runtime, only
+// at build time, or only for testing.
+func (e *TargetEdge) DepType() DepType {
+	return e.depType
+}
+
+// Linkage identifies how the target links the dependency: statically,
+// dynamically, or as a separately installed shared library.
+func (e *TargetEdge) Linkage() Linkage {
+	return e.linkage
+}
+
 // IsRuntimeDependency returns true for edges representing shared libraries
 // linked dynamically at runtime.
 func (e *TargetEdge) IsRuntimeDependency() bool {
@@ -353,6 +485,14 @@ func (tn *TargetNode) LicenseConditions() LicenseConditionSet {
 	return tn.licenseConditions
 }
 
+// LicenseKinds returns the kinds of license applying to the target.
+// (unordered)
+//
+// e.g. SPDX-license-identifier-Apache-2.0 or legacy_notice
+func (tn *TargetNode) LicenseKinds() []string {
+	return append([]string{}, tn.proto.LicenseKinds...)
+}
+
 // LicenseTexts returns the paths to the files containing the license texts for
 // the target. (unordered)
 func (tn *TargetNode) LicenseTexts() []string {
@@ -0,0 +1,370 @@
+// Copyright (C) 2026 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// License graphs cached by graphcache and loaded by textnotice -load_graph.
+//
+// Field numbers are part of the binary wire format. Never renumber or reuse a
+// field number: add new fields with the next unused number, and when removing
+// a field, reserve both its number and its name so that old data still parses
+// and new data does not get misread by old readers.
+//
+// Run regen.sh after changing this file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        (unknown)
+// source: license_graph.proto
+
+package graph_proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// LicenseGraph describes a license graph as read from the license metadata
+// files before resolving any conditions.
+//
+// Next field number: 3
+type LicenseGraph struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The root license metadata files of the graph in the order given.
+	RootFiles []string `protobuf:"bytes,1,rep,name=root_files,json=rootFiles" json:"root_files,omitempty"`
+	// Every target in the graph ordered by name.
+	Targets []*TargetNode `protobuf:"bytes,2,rep,name=targets" json:"targets,omitempty"`
+}
+
+func (x *LicenseGraph) Reset() {
+	*x = LicenseGraph{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_license_graph_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LicenseGraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LicenseGraph) ProtoMessage() {}
+
+func (x *LicenseGraph) ProtoReflect() protoreflect.Message {
+	mi := &file_license_graph_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LicenseGraph.ProtoReflect.Descriptor instead.
+func (*LicenseGraph) Descriptor() ([]byte, []int) {
+	return file_license_graph_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LicenseGraph) GetRootFiles() []string {
+	if x != nil {
+		return x.RootFiles
+	}
+	return nil
+}
+
+func (x *LicenseGraph) GetTargets() []*TargetNode {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+// TargetNode describes a target in the graph.
+//
+// Next field number: 6
+type TargetNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The path to the license metadata file of the target.
+	Name *string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// The license metadata of the target as a serialized
+	// license_metadata_proto.LicenseMetadata without its deps.
+	Metadata []byte `protobuf:"bytes,2,opt,name=metadata" json:"metadata,omitempty"`
+	// The license conditions originating at the target.
+	LicenseConditions []string `protobuf:"bytes,3,rep,name=license_conditions,json=licenseConditions" json:"license_conditions,omitempty"`
+	// The dependencies of the target in the order read.
+	Deps []*TargetEdge `protobuf:"bytes,5,rep,name=deps" json:"deps,omitempty"`
+}
+
+func (x *TargetNode) Reset() {
+	*x = TargetNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_license_graph_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TargetNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetNode) ProtoMessage() {}
+
+func (x *TargetNode) ProtoReflect() protoreflect.Message {
+	mi := &file_license_graph_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetNode.ProtoReflect.Descriptor instead.
+func (*TargetNode) Descriptor() ([]byte, []int) {
+	return file_license_graph_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TargetNode) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *TargetNode) GetMetadata() []byte {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *TargetNode) GetLicenseConditions() []string {
+	if x != nil {
+		return x.LicenseConditions
+	}
+	return nil
+}
+
+func (x *TargetNode) GetDeps() []*TargetEdge {
+	if x != nil {
+		return x.Deps
+	}
+	return nil
+}
+
+// TargetEdge describes an edge from a target to a dependency.
+//
+// Next field number: 4
+type TargetEdge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the dependency. Matches the name of an entry in
+	// LicenseGraph.targets.
+	Dependency *string `protobuf:"bytes,1,opt,name=dependency" json:"dependency,omitempty"`
+	// The sorted recognized annotations of the edge. e.g. "static"
+	Annotations []string `protobuf:"bytes,2,rep,name=annotations" json:"annotations,omitempty"`
+}
+
+func (x *TargetEdge) Reset() {
+	*x = TargetEdge{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_license_graph_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TargetEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetEdge) ProtoMessage() {}
+
+func (x *TargetEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_license_graph_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetEdge.ProtoReflect.Descriptor instead.
+func (*TargetEdge) Descriptor() ([]byte, []int) {
+	return file_license_graph_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TargetEdge) GetDependency() string {
+	if x != nil && x.Dependency != nil {
+		return *x.Dependency
+	}
+	return ""
+}
+
+func (x *TargetEdge) GetAnnotations() []string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+var File_license_graph_proto protoreflect.FileDescriptor
+
+var file_license_graph_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0x60, 0x0a, 0x0c, 0x4c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x47, 0x72, 0x61,
+	0x70, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x65,
+	0x73, 0x12, 0x31, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x07, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x73, 0x22, 0xaf, 0x01, 0x0a, 0x0a, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e,
+	0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x2d, 0x0a, 0x12, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x63,
+	0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x11, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x04, 0x64, 0x65, 0x70, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x45, 0x64, 0x67, 0x65, 0x52, 0x04, 0x64, 0x65, 0x70, 0x73, 0x4a,
+	0x04, 0x08, 0x04, 0x10, 0x05, 0x52, 0x0f, 0x73, 0x70, 0x64, 0x78, 0x5f, 0x65, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x69, 0x0a, 0x0a, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x45, 0x64, 0x67, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64, 0x65, 0x6e,
+	0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64,
+	0x65, 0x6e, 0x63, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x52, 0x13, 0x6f, 0x76,
+	0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x42, 0x2c, 0x5a, 0x2a, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x2f, 0x73, 0x6f, 0x6f,
+	0x6e, 0x67, 0x2f, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x69, 0x61,
+	0x6e, 0x63, 0x65, 0x2f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+}
+
+var (
+	file_license_graph_proto_rawDescOnce sync.Once
+	file_license_graph_proto_rawDescData = file_license_graph_proto_rawDesc
+)
+
+func file_license_graph_proto_rawDescGZIP() []byte {
+	file_license_graph_proto_rawDescOnce.Do(func() {
+		file_license_graph_proto_rawDescData = protoimpl.X.CompressGZIP(file_license_graph_proto_rawDescData)
+	})
+	return file_license_graph_proto_rawDescData
+}
+
+var file_license_graph_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_license_graph_proto_goTypes = []interface{}{
+	(*LicenseGraph)(nil), // 0: graph_proto.LicenseGraph
+	(*TargetNode)(nil),   // 1: graph_proto.TargetNode
+	(*TargetEdge)(nil),   // 2: graph_proto.TargetEdge
+}
+var file_license_graph_proto_depIdxs = []int32{
+	1, // 0: graph_proto.LicenseGraph.targets:type_name -> graph_proto.TargetNode
+	2, // 1: graph_proto.TargetNode.deps:type_name -> graph_proto.TargetEdge
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_license_graph_proto_init() }
+func file_license_graph_proto_init() {
+	if File_license_graph_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_license_graph_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LicenseGraph); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_license_graph_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TargetNode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_license_graph_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TargetEdge); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_license_graph_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_license_graph_proto_goTypes,
+		DependencyIndexes: file_license_graph_proto_depIdxs,
+		MessageInfos:      file_license_graph_proto_msgTypes,
+	}.Build()
+	File_license_graph_proto = out.File
+	file_license_graph_proto_rawDesc = nil
+	file_license_graph_proto_goTypes = nil
+	file_license_graph_proto_depIdxs = nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph_proto
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TestFieldNumbers guards the wire format against renumbered fields. Only add
+// entries here; changing or removing one breaks existing readers.
+func TestFieldNumbers(t *testing.T) {
+	tests := []struct {
+		message protoreflect.MessageDescriptor
+		fields  map[string]protoreflect.FieldNumber
+	}{
+		{(&LicenseGraph{}).ProtoReflect().Descriptor(), map[string]protoreflect.FieldNumber{
+			"root_files": 1,
+			"targets":    2,
+		}},
+		{(&TargetNode{}).ProtoReflect().Descriptor(), map[string]protoreflect.FieldNumber{
+			"name":               1,
+			"metadata":           2,
+			"license_conditions": 3,
+			"deps":               5,
+		}},
+		{(&TargetEdge{}).ProtoReflect().Descriptor(), map[string]protoreflect.FieldNumber{
+			"dependency":  1,
+			"annotations": 2,
+		}},
+	}
+	for _, tt := range tests {
+		fields := tt.message.Fields()
+		for name, number := range tt.fields {
+			fd := fields.ByName(protoreflect.Name(name))
+			if fd == nil {
+				t.Errorf("%s: missing field %q", tt.message.FullName(), name)
+			} else if fd.Number() != number {
+				t.Errorf("%s: got field %q number %d, want %d", tt.message.FullName(), name, fd.Number(), number)
+			}
+		}
+		if fields.Len() != len(tt.fields) {
+			t.Errorf("%s: got %d fields, want %d; add new fields to this test", tt.message.FullName(), fields.Len(), len(tt.fields))
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"sort"
+
+	"android/soong/tools/compliance/graph_proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalGraph serializes `lg` as a graph_proto.LicenseGraph in the binary
+// protobuf format so that UnmarshalGraph can load it again without reading
+// the license metadata files.
+//
+// Serializes the graph as read: the conditions originating at each target
+// after any ApplyOverrides, but not SkipBuildtimeDeps,
+// PropagateCopyleftStaticOnly or any resolutions.
+func MarshalGraph(lg *LicenseGraph) ([]byte, error) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+
+	names := make([]string, 0, len(lg.targets))
+	for name := range lg.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g := &graph_proto.LicenseGraph{
+		RootFiles: append([]string{}, lg.rootFiles...),
+		Targets:   make([]*graph_proto.TargetNode, 0, len(names)),
+	}
+	for _, name := range names {
+		tn := lg.targets[name]
+		if tn == nil {
+			return nil, fmt.Errorf("missing target %q", name)
+		}
+		metadata, err := proto.Marshal(&tn.proto)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal license metadata for %q: %w", name, err)
+		}
+		ptn := &graph_proto.TargetNode{
+			Name:              proto.String(name),
+			Metadata:          metadata,
+			LicenseConditions: tn.licenseConditions.Names(),
+			Deps:              make([]*graph_proto.TargetEdge, 0, len(tn.edges)),
+		}
+		for _, e := range tn.edges {
+			annotations := e.annotations.AsList()
+			sort.Strings(annotations)
+			ptn.Deps = append(ptn.Deps, &graph_proto.TargetEdge{
+				Dependency:  proto.String(e.dependency.name),
+				Annotations: annotations,
+			})
+		}
+		g.Targets = append(g.Targets, ptn)
+	}
+	return proto.Marshal(g)
+}
+
+// UnmarshalGraph returns the license graph serialized by MarshalGraph as `b`.
+//
+// Custom conditions named by the graph must be registered before calling.
+func UnmarshalGraph(b []byte) (*LicenseGraph, error) {
+	var g graph_proto.LicenseGraph
+	if err := proto.Unmarshal(b, &g); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal license graph: %w", err)
+	}
+	if len(g.RootFiles) == 0 {
+		return nil, fmt.Errorf("no root files in license graph")
+	}
+
+	lg := newLicenseGraph()
+	lg.rootFiles = append(lg.rootFiles, g.RootFiles...)
+	for _, ptn := range g.Targets {
+		name := ptn.GetName()
+		if len(name) == 0 {
+			return nil, fmt.Errorf("missing target name")
+		}
+		if _, ok := lg.targets[name]; ok {
+			return nil, fmt.Errorf("duplicate target %q", name)
+		}
+		tn := &TargetNode{lg: lg, name: name}
+		if err := proto.Unmarshal(ptn.Metadata, &tn.proto); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal license metadata for %q: %w", name, err)
+		}
+		cs, err := conditionsFromNames(ptn.LicenseConditions)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", name, err)
+		}
+		tn.licenseConditions = cs
+		lg.targets[name] = tn
+	}
+	for _, f := range lg.rootFiles {
+		if _, ok := lg.targets[f]; !ok {
+			return nil, fmt.Errorf("unknown root file %q", f)
+		}
+	}
+
+	esize := 0
+	for _, ptn := range g.Targets {
+		esize += len(ptn.Deps)
+	}
+	lg.edges = make(TargetEdgeList, 0, esize)
+	for _, ptn := range g.Targets {
+		tn := lg.targets[ptn.GetName()]
+		tn.edges = make(TargetEdgeList, 0, len(ptn.Deps))
+		for _, pe := range ptn.Deps {
+			dependency := pe.GetDependency()
+			dtn, ok := lg.targets[dependency]
+			if !ok {
+				return nil, fmt.Errorf("target %q: unknown dependency name %q", tn.name, dependency)
+			}
+			annotations := newEdgeAnnotations()
+			for _, a := range pe.Annotations {
+				if ann, ok := RecognizedAnnotations[a]; ok {
+					annotations.annotations[ann] = struct{}{}
+				}
+			}
+			edge := &TargetEdge{tn, dtn, annotations, newDepType(annotations), newLinkage(annotations), false}
+			lg.edges = append(lg.edges, edge)
+			tn.edges = append(tn.edges, edge)
+		}
+	}
+	return lg, nil
+}
+
+// conditionsFromNames returns the set of the recognized conditions `names`
+// or an error naming the first unrecognized condition.
+func conditionsFromNames(names []string) (LicenseConditionSet, error) {
+	cs := NewLicenseConditionSet()
+	for _, name := range names {
+		lc, ok := Conditions.Condition(name)
+		if !ok {
+			return cs, fmt.Errorf("unrecognized license condition %q", name)
+		}
+		cs = cs.Plus(lc)
+	}
+	return cs, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/graph_proto"
+	"android/soong/tools/compliance/testfs"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// traversal returns a description of every path of a top-down walk of `lg`
+// with the conditions and resolution of each target and the targets' other
+// metadata in name order.
+func traversal(lg *LicenseGraph) string {
+	ResolveTopDownConditions(lg)
+	var sb strings.Builder
+	WalkTopDown(NoEdgeContext{}, lg, func(lg *LicenseGraph, tn *TargetNode, path TargetEdgePath) bool {
+		fmt.Fprintf(&sb, "%s %s %s %s\n", path.String(), tn.name, tn.licenseConditions.Names(), tn.resolution.Names())
+		return true
+	})
+	targets := lg.Targets()
+	sort.Sort(targets)
+	for _, tn := range targets {
+		fmt.Fprintf(&sb, "%s: package=%q kinds=%v texts=%v container=%t installed=%v sources=%v\n",
+			tn.name, tn.PackageName(), tn.LicenseKinds(), tn.LicenseTexts(), tn.IsContainer(), tn.Installed(), tn.Sources())
+		for _, e := range tn.edges {
+			annotations := e.annotations.AsList()
+			sort.Strings(annotations)
+			fmt.Fprintf(&sb, "  %s %v %s %s\n", e.dependency.name, annotations, e.DepType(), e.Linkage())
+		}
+	}
+	return sb.String()
+}
+
+// roundTrip returns the graph from marshaling then unmarshaling `lg`.
+func roundTrip(t *testing.T, lg *LicenseGraph) *LicenseGraph {
+	t.Helper()
+	b, err := MarshalGraph(lg)
+	if err != nil {
+		t.Fatalf("cannot marshal graph: %s", err)
+	}
+	result, err := UnmarshalGraph(b)
+	if err != nil {
+		t.Fatalf("cannot unmarshal graph: %s", err)
+	}
+	return result
+}
+
+// TestGraphCachePropertyRoundTrip verifies that marshaling then unmarshaling
+// a random graph produces a graph with identical traversal output.
+func TestGraphCachePropertyRoundTrip(t *testing.T) {
+	checkProperty(t, func(g randomGraph) bool {
+		expected := traversal(g.read(t))
+		actual := traversal(roundTrip(t, g.read(t)))
+		if actual != expected {
+			t.Logf("got traversal:\n%s\nwant:\n%s\nin:\n%s", actual, expected, g)
+			return false
+		}
+		return true
+	})
+}
+
+func TestGraphCacheRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		fs    *testfs.TestFS
+		roots []string
+	}{
+		{
+			name: "metadata",
+			fs: &testfs.TestFS{
+				"app.meta_lic": []byte(AOSP + "installed: \"/system/app\"\ninstall_map: {\n  from_path: \"out/app\"\n  container_path: \"/app\"\n}\ndeps: {\n  file: \"lib.meta_lic\"\n  annotations: \"dynamic\"\n}\n"),
+				"lib.meta_lic": []byte(MIT + "sources: \"lib.c\"\n"),
+			},
+			roots: []string{"app.meta_lic"},
+		},
+		{
+			name: "multiple roots",
+			fs: &testfs.TestFS{
+				"app.meta_lic":  []byte(AOSP + "deps: {\n  file: \"lib.meta_lic\"\n  annotations: \"toolchain\"\n}\n"),
+				"tool.meta_lic": []byte(Proprietary + "deps: {\n  file: \"lib.meta_lic\"\n}\n"),
+				"lib.meta_lic":  []byte(LGPL),
+			},
+			roots: []string{"tool.meta_lic", "app.meta_lic"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read := func() *LicenseGraph {
+				stderr := &bytes.Buffer{}
+				lg, err := ReadLicenseGraph(tt.fs, stderr, tt.roots)
+				if err != nil {
+					t.Fatalf("unable to read graph: %s\nstderr: %s", err, stderr)
+				}
+				return lg
+			}
+			lg := roundTrip(t, read())
+			if actual, expected := traversal(lg), traversal(read()); actual != expected {
+				t.Errorf("got traversal:\n%s\nwant:\n%s", actual, expected)
+			}
+			if actual, expected := lg.rootFiles, tt.roots; strings.Join(actual, " ") != strings.Join(expected, " ") {
+				t.Errorf("got root files %v, want %v", actual, expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshalGraphErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		graph         *graph_proto.LicenseGraph
+		expectedError string
+	}{
+		{
+			name:          "no roots",
+			graph:         &graph_proto.LicenseGraph{},
+			expectedError: "no root files",
+		},
+		{
+			name: "unknown root",
+			graph: &graph_proto.LicenseGraph{
+				RootFiles: []string{"app.meta_lic"},
+			},
+			expectedError: "unknown root file \"app.meta_lic\"",
+		},
+		{
+			name: "unknown dependency",
+			graph: &graph_proto.LicenseGraph{
+				RootFiles: []string{"app.meta_lic"},
+				Targets: []*graph_proto.TargetNode{{
+					Name: proto.String("app.meta_lic"),
+					Deps: []*graph_proto.TargetEdge{{Dependency: proto.String("lib.meta_lic")}},
+				}},
+			},
+			expectedError: "unknown dependency name \"lib.meta_lic\"",
+		},
+		{
+			name: "unknown condition",
+			graph: &graph_proto.LicenseGraph{
+				RootFiles: []string{"app.meta_lic"},
+				Targets: []*graph_proto.TargetNode{{
+					Name:              proto.String("app.meta_lic"),
+					LicenseConditions: []string{"free"},
+				}},
+			},
+			expectedError: "unrecognized license condition \"free\"",
+		},
+		{
+			name: "duplicate target",
+			graph: &graph_proto.LicenseGraph{
+				RootFiles: []string{"app.meta_lic"},
+				Targets: []*graph_proto.TargetNode{
+					{Name: proto.String("app.meta_lic")},
+					{Name: proto.String("app.meta_lic")},
+				},
+			},
+			expectedError: "duplicate target \"app.meta_lic\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := proto.Marshal(tt.graph)
+			if err != nil {
+				t.Fatalf("cannot marshal test graph: %s", err)
+			}
+			_, err = UnmarshalGraph(b)
+			if err == nil {
+				t.Fatalf("unexpected success: got no error, want %q", tt.expectedError)
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("got error %q, want %q", err, tt.expectedError)
+			}
+		})
+	}
+	if _, err := UnmarshalGraph([]byte("not a proto")); err == nil {
+		t.Errorf("unexpected success unmarshaling garbage")
+	}
+}
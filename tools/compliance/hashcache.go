@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// hashCacheVersion identifies the format of the hash cache file. Files with
+// any other version get ignored.
+const hashCacheVersion = 2
+
+// HashCache remembers the hashes of license text files between runs so that
+// indexing can skip reading the files unchanged since, judged by their size
+// and modification time. The index reads the content of a cached text only
+// when a notice needs it.
+//
+// HashCache is safe for concurrent use.
+type HashCache struct {
+	// mu guards entries and used.
+	mu sync.Mutex
+	// entries maps the file paths to the cached hashes.
+	entries map[string]hashCacheEntry
+	// used is the set of paths looked up or stored, which Write keeps.
+	used map[string]struct{}
+}
+
+// hashCacheEntry is the cached hash of a single license text file.
+type hashCacheEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// ModTime is the modification time in nanoseconds since the Unix epoch.
+	ModTime int64  `json:"mtime"`
+	Sha256  string `json:"sha256"`
+	// Hash is the key of the text in the NoticeIndex.
+	Hash string `json:"hash"`
+	// Blank is true when the text is empty or only whitespace.
+	Blank bool `json:"blank,omitempty"`
+}
+
+// hashCacheFile is the content of a hash cache file.
+type hashCacheFile struct {
+	Version int              `json:"version"`
+	Entries []hashCacheEntry `json:"entries"`
+}
+
+// NewHashCache returns an empty HashCache.
+func NewHashCache() *HashCache {
+	return &HashCache{entries: make(map[string]hashCacheEntry), used: make(map[string]struct{})}
+}
+
+// ReadHashCache reads the hash cache file at `path`.
+//
+// Returns an empty cache for a missing file. Returns an empty cache and an
+// error describing the problem for an unreadable or corrupt file, which
+// callers may report but need not treat as fatal.
+func ReadHashCache(path string) (*HashCache, error) {
+	c := NewHashCache()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("ignoring hash cache %q: %w", path, err)
+	}
+	var f hashCacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return c, fmt.Errorf("ignoring corrupt hash cache %q: %w", path, err)
+	}
+	if f.Version != hashCacheVersion {
+		return c, fmt.Errorf("ignoring hash cache %q: got version %d, want %d", path, f.Version, hashCacheVersion)
+	}
+	for _, e := range f.Entries {
+		if len(e.Path) == 0 || len(e.Hash) == 0 {
+			return NewHashCache(), fmt.Errorf("ignoring corrupt hash cache %q: incomplete entry %+v", path, e)
+		}
+		c.entries[e.Path] = e
+	}
+	return c, nil
+}
+
+// lookup returns the cache entry for the file `path` described by `fi` and
+// true, or false when not cached or changed since.
+func (c *HashCache) lookup(path string, fi fs.FileInfo) (hashCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	if !ok || e.Size != fi.Size() || e.ModTime != fi.ModTime().UnixNano() {
+		return hashCacheEntry{}, false
+	}
+	c.used[path] = struct{}{}
+	return e, true
+}
+
+// store caches the hash `h` of the content `text` of the file `path`
+// described by `fi`.
+func (c *HashCache) store(path string, fi fs.FileInfo, text []byte, h Hash) {
+	sum := sha256.Sum256(text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = hashCacheEntry{path, fi.Size(), fi.ModTime().UnixNano(), hex.EncodeToString(sum[:]), h.key, len(bytes.TrimSpace(text)) == 0}
+	c.used[path] = struct{}{}
+}
+
+// Write writes the entries looked up or stored since reading the cache to
+// the file `path`.
+//
+// Writes a temporary file in the same directory and renames it so that
+// concurrent readers and writers see either the old or the new cache but
+// never a partial one.
+func (c *HashCache) Write(path string) error {
+	c.mu.Lock()
+	f := hashCacheFile{Version: hashCacheVersion, Entries: make([]hashCacheEntry, 0, len(c.used))}
+	for p := range c.used {
+		f.Entries = append(f.Entries, c.entries[p])
+	}
+	c.mu.Unlock()
+	sort.Slice(f.Entries, func(i, j int) bool { return f.Entries[i].Path < f.Entries[j].Path })
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not write hash cache %q: %w", path, err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write hash cache %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// countingFS counts the reads of the content of the license text files under
+// "licenses/".
+type countingFS struct {
+	fstest.MapFS
+	reads *int64
+}
+
+func (c countingFS) Open(name string) (fs.File, error) {
+	f, err := c.MapFS.Open(name)
+	if err != nil || !strings.HasPrefix(name, "licenses/") {
+		return f, err
+	}
+	return countingFile{f, c.reads}, nil
+}
+
+func (c countingFS) Stat(name string) (fs.FileInfo, error) {
+	return c.MapFS.Stat(name)
+}
+
+// countingFile counts the Read calls on a file.
+type countingFile struct {
+	fs.File
+	reads *int64
+}
+
+func (f countingFile) Read(b []byte) (int, error) {
+	atomic.AddInt64(f.reads, 1)
+	return f.File.Read(b)
+}
+
+// cachingFixture returns a container installing 10 libraries with license
+// texts under "licenses/" modified at `mtime`.
+func cachingFixture(mtime time.Time) fstest.MapFS {
+	rootFS := fstest.MapFS{}
+	for name, data := range hashingFixture(10) {
+		rootFS[name] = &fstest.MapFile{Data: data, ModTime: mtime}
+	}
+	return rootFS
+}
+
+// indexCached indexes `rootFS` with `cache` returning the index and the
+// number of content reads while indexing.
+func indexCached(t *testing.T, rootFS fstest.MapFS, cache *HashCache) (*NoticeIndex, int64) {
+	t.Helper()
+	cfs := countingFS{rootFS, new(int64)}
+	lg, err := ReadLicenseGraph(cfs, &bytes.Buffer{}, []string{"root.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTextsWithOptions(context.Background(), cfs, lg, ResolveNotices(lg), IndexOptions{HashCache: cache})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(): got error %s, want no error", err)
+	}
+	return ni, atomic.LoadInt64(cfs.reads)
+}
+
+func TestHashCache(t *testing.T) {
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	rootFS := cachingFixture(mtime)
+	cacheFile := filepath.Join(t.TempDir(), "hashes.json")
+
+	cache, err := ReadHashCache(cacheFile)
+	if err != nil {
+		t.Fatalf("ReadHashCache(missing): got error %s, want no error", err)
+	}
+	expected, reads := indexCached(t, rootFS, cache)
+	if reads == 0 {
+		t.Fatalf("IndexLicenseTextsWithOptions(empty cache): got no reads, want reads")
+	}
+	if err := cache.Write(cacheFile); err != nil {
+		t.Fatalf("Write(): got error %s, want no error", err)
+	}
+
+	// describe returns the texts of `ni` in order.
+	describe := func(ni *NoticeIndex) []string {
+		var result []string
+		for _, h := range ni.AllHashes() {
+			text, err := ni.HashText(h)
+			if err != nil {
+				t.Fatalf("HashText(%s): got error %s, want no error", h, err)
+			}
+			result = append(result, h.String()+" "+string(text)+" "+strings.Join(ni.HashFiles(h), " "))
+		}
+		return result
+	}
+
+	cache, err = ReadHashCache(cacheFile)
+	if err != nil {
+		t.Fatalf("ReadHashCache(): got error %s, want no error", err)
+	}
+	ni, reads := indexCached(t, rootFS, cache)
+	if reads != 0 {
+		t.Errorf("IndexLicenseTextsWithOptions(cached): got %d content reads, want none", reads)
+	}
+	if actual := describe(ni); !reflect.DeepEqual(actual, describe(expected)) {
+		t.Errorf("IndexLicenseTextsWithOptions(cached): got %q, want %q", actual, describe(expected))
+	}
+
+	// A text with a new modification time or size gets read again.
+	rootFS["licenses/LICENSE3"] = &fstest.MapFile{Data: []byte("Changed.\n"), ModTime: mtime}
+	rootFS["licenses/LICENSE4"].ModTime = mtime.Add(time.Second)
+	ni, reads = indexCached(t, rootFS, cache)
+	if reads == 0 {
+		t.Errorf("IndexLicenseTextsWithOptions(changed): got no content reads, want reads of the changed files")
+	}
+	found := false
+	for _, h := range ni.AllHashes() {
+		if string(ni.TextContent(h)) == "Changed.\n" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("IndexLicenseTextsWithOptions(changed): got stale texts, want the changed text")
+	}
+}
+
+func TestReadHashCacheCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"garbage":    "not json",
+		"version":    `{"version": 99, "entries": []}`,
+		"incomplete": `{"version": 2, "entries": [{"path": "licenses/LICENSE0"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+				t.Fatal(err)
+			}
+			cache, err := ReadHashCache(path)
+			if err == nil {
+				t.Errorf("ReadHashCache(%s): got no error, want error", name)
+			}
+			if cache == nil || len(cache.entries) != 0 {
+				t.Fatalf("ReadHashCache(%s): got %v, want empty cache", name, cache)
+			}
+			// The empty cache still works.
+			if _, reads := indexCached(t, cachingFixture(time.Now()), cache); reads == 0 {
+				t.Errorf("IndexLicenseTextsWithOptions(%s): got no reads, want reads", name)
+			}
+		})
+	}
+}
+
+func TestHashCacheConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "hashes.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache := NewHashCache()
+			mtime := time.Unix(int64(i), 0)
+			for j := 0; j <= i; j++ {
+				fi, _ := fstest.MapFS{"f": &fstest.MapFile{Data: []byte("text"), ModTime: mtime}}.Stat("f")
+				cache.store(fmt.Sprintf("licenses/LICENSE%d", j), fi, []byte("text"), Hash{"0123"})
+			}
+			if err := cache.Write(cacheFile); err != nil {
+				t.Errorf("Write(): got error %s, want no error", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := ReadHashCache(cacheFile); err != nil {
+		t.Errorf("ReadHashCache(): got error %s, want a complete cache from one writer", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Write(): got files %v, want only %q", entries, "hashes.json")
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jarManifest is the path to the manifest inside a JAR file.
+const jarManifest = "META-INF/MANIFEST.MF"
+
+// jarLicenseURLs maps the license URLs Bundle-License headers commonly give
+// instead of SPDX identifiers, without scheme, "www." or suffix, to the SPDX
+// identifiers.
+var jarLicenseURLs = map[string]string{
+	"apache.org/licenses/license-2.0":           "Apache-2.0",
+	"opensource.org/licenses/apache-2.0":        "Apache-2.0",
+	"opensource.org/licenses/bsd-2-clause":      "BSD-2-Clause",
+	"opensource.org/licenses/bsd-3-clause":      "BSD-3-Clause",
+	"opensource.org/licenses/bsd-license":       "BSD-3-Clause",
+	"opensource.org/licenses/isc":               "ISC",
+	"opensource.org/licenses/mit":               "MIT",
+	"opensource.org/licenses/mit-license":       "MIT",
+	"mozilla.org/mpl/2.0":                       "MPL-2.0",
+	"gnu.org/licenses/gpl-2.0":                  "GPL-2.0-only",
+	"gnu.org/licenses/gpl-3.0":                  "GPL-3.0-only",
+	"gnu.org/licenses/old-licenses/gpl-2.0":     "GPL-2.0-only",
+	"gnu.org/licenses/old-licenses/lgpl-2.1":    "LGPL-2.1-only",
+	"creativecommons.org/publicdomain/zero/1.0": "CC0-1.0",
+}
+
+// jarPom matches the paths of the Maven POM files inside JAR files.
+var jarPom = regexp.MustCompile(`^META-INF/maven/[^/]+/[^/]+/pom\.xml$`)
+
+// pomProject holds the parts of a Maven POM file JarLicenseExtractor uses.
+type pomProject struct {
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Licenses   []struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+	} `xml:"licenses>license"`
+}
+
+// UnknownLicenseError reports an archive, e.g. a JAR file or APK, declaring
+// no license that an extractor recognizes, e.g. a JAR file without a
+// manifest.
+type UnknownLicenseError struct {
+	// File is the path to the archive.
+	File string
+	// Reason explains what is missing.
+	Reason string
+}
+
+// Error returns a string naming the archive and what is missing.
+func (e *UnknownLicenseError) Error() string {
+	return fmt.Sprintf("unknown license for %q: %s", e.File, e.Reason)
+}
+
+// JarLicenseExtractor describes prebuilt JAR files with MetaLicStubs using
+// the Bundle-License header of META-INF/MANIFEST.MF, the licenses of the
+// Maven pom.xml and the license files in META-INF.
+type JarLicenseExtractor struct {
+	// TextDir is where to copy the license files out of the JAR files, as
+	// <TextDir>/<jar path>/META-INF/LICENSE etc., so that the stubs can list
+	// them, or empty to only use the files to identify the license.
+	TextDir string
+}
+
+// ExtractJarLicense returns the MetaLicStub for the JAR file `jarPath` in
+// `fsys` without copying its license files per JarLicenseExtractor.Extract.
+func ExtractJarLicense(jarPath string, fsys fs.FS) (MetaLicStub, error) {
+	return (&JarLicenseExtractor{}).Extract(fsys, jarPath)
+}
+
+// Extract returns the MetaLicStub named "<jarPath>.meta_lic" for the JAR
+// file `jarPath` in `fsys`.
+//
+// The license kinds come from the SPDX identifiers or well-known license
+// URLs in the Bundle-License header, or else from the licenses of the Maven
+// pom.xml, e.g. META-INF/maven/com.example/lib/pom.xml, or else from
+// identifying the text of each license file, e.g. META-INF/LICENSE.
+// Licenses naming no known license without a license file make the license
+// kind unidentified.
+//
+// The package name and version come from the Bundle-Name or
+// Implementation-Title and the Bundle-Version or Implementation-Version
+// headers, or else the artifactId and version of a single pom.xml, with the
+// JAR file name as the fallback package name.
+//
+// Returns an *UnknownLicenseError when the JAR file has no manifest, or no
+// Bundle-License header, no pom.xml license and no license file.
+func (e *JarLicenseExtractor) Extract(fsys fs.FS, jarPath string) (MetaLicStub, error) {
+	zr, closer, err := openJar(fsys, jarPath)
+	if err != nil {
+		return MetaLicStub{}, err
+	}
+	defer closer.Close()
+
+	var manifest map[string]string
+	var poms []pomProject
+	var licenseFiles []*zip.File
+	for _, f := range zr.File {
+		if f.Name == jarManifest {
+			manifest, err = readJarManifest(f)
+			if err != nil {
+				return MetaLicStub{}, fmt.Errorf("invalid manifest in %q: %w", jarPath, err)
+			}
+			continue
+		}
+		if jarPom.MatchString(f.Name) {
+			content, err := readZipFile(f)
+			if err != nil {
+				return MetaLicStub{}, fmt.Errorf("cannot read %q in %q: %w", f.Name, jarPath, err)
+			}
+			var pom pomProject
+			if err := xml.Unmarshal(content, &pom); err != nil {
+				return MetaLicStub{}, fmt.Errorf("invalid %q in %q: %w", f.Name, jarPath, err)
+			}
+			poms = append(poms, pom)
+			continue
+		}
+		dir, base := path.Split(f.Name)
+		if dir == "META-INF/" && !f.FileInfo().IsDir() && goModLicenseFile.MatchString(base) {
+			licenseFiles = append(licenseFiles, f)
+		}
+	}
+	if manifest == nil {
+		return MetaLicStub{}, &UnknownLicenseError{jarPath, "no " + jarManifest}
+	}
+	// licenses lists the license names or URLs the manifest or else the
+	// pom.xml files declare.
+	var licenses []string
+	for _, entry := range splitUnquoted(manifest["Bundle-License"], ',') {
+		// Ignore attributes, e.g. `;link="https://..."`.
+		if name := strings.TrimSpace(splitUnquoted(entry, ';')[0]); len(name) > 0 {
+			licenses = append(licenses, name)
+		}
+	}
+	if len(licenses) == 0 {
+		for _, pom := range poms {
+			for _, l := range pom.Licenses {
+				// Prefer the URL over names like "The Apache Software
+				// License, Version 2.0".
+				if name := firstNonEmpty(strings.TrimSpace(l.URL), strings.TrimSpace(l.Name)); len(name) > 0 {
+					licenses = append(licenses, name)
+				}
+			}
+		}
+	}
+	if len(licenses) == 0 && len(licenseFiles) == 0 {
+		return MetaLicStub{}, &UnknownLicenseError{jarPath, "no Bundle-License header, pom.xml license or license file"}
+	}
+	sort.Slice(licenseFiles, func(i, j int) bool { return licenseFiles[i].Name < licenseFiles[j].Name })
+
+	var pom pomProject
+	if len(poms) == 1 {
+		// Shaded JAR files hold the pom.xml of each library they bundle,
+		// none of which names the JAR file.
+		pom = poms[0]
+	}
+	stub := MetaLicStub{
+		Name:        jarPath + ".meta_lic",
+		PackageName: firstNonEmpty(manifest["Bundle-Name"], manifest["Implementation-Title"], pom.ArtifactID, strings.TrimSuffix(path.Base(jarPath), ".jar")),
+		Version:     firstNonEmpty(manifest["Bundle-Version"], manifest["Implementation-Version"], pom.Version),
+	}
+	kinds := make(map[string]string)
+	ids := licenseIDs(licenses)
+	for _, id := range ids {
+		kinds[spdxLicenseKindPrefix+id] = spdxConditions[id]
+	}
+	for _, f := range licenseFiles {
+		content, err := readZipFile(f)
+		if err != nil {
+			return MetaLicStub{}, fmt.Errorf("cannot read %q in %q: %w", f.Name, jarPath, err)
+		}
+		if len(ids) == 0 {
+			kind, condition := identifyLicense(string(content), licenseMatchThreshold)
+			kinds[kind] = condition
+		}
+		if len(e.TextDir) == 0 {
+			continue
+		}
+		text := filepath.Join(e.TextDir, filepath.FromSlash(jarPath), filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(text), 0777); err != nil {
+			return MetaLicStub{}, err
+		}
+		if err := os.WriteFile(text, content, 0666); err != nil {
+			return MetaLicStub{}, err
+		}
+		stub.LicenseTexts = append(stub.LicenseTexts, filepath.ToSlash(text))
+	}
+	if len(kinds) == 0 {
+		// Licenses naming no known license, without a license file, need
+		// someone to review them.
+		kinds[unidentifiedLicenseKind] = "by_exception_only"
+	}
+
+	conditions := make(map[string]struct{})
+	for kind, condition := range kinds {
+		stub.LicenseKinds = append(stub.LicenseKinds, kind)
+		conditions[condition] = struct{}{}
+	}
+	for condition := range conditions {
+		stub.LicenseConditions = append(stub.LicenseConditions, condition)
+	}
+	sort.Strings(stub.LicenseKinds)
+	sort.Strings(stub.LicenseConditions)
+	return stub, nil
+}
+
+// openJar opens the JAR file `jarPath` in `fsys` as a zip file, reading it
+// into memory when the file does not support io.ReaderAt.
+func openJar(fsys fs.FS, jarPath string) (*zip.Reader, io.Closer, error) {
+	f, err := fsys.Open(jarPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	ra, ok := f.(io.ReaderAt)
+	size := fi.Size()
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		ra, size = bytes.NewReader(data), int64(len(data))
+	}
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("invalid JAR file %q: %w", jarPath, err)
+	}
+	return zr, f, nil
+}
+
+// readZipFile returns the content of `f`.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// readJarManifest returns the headers of the main section of the manifest
+// `f` joining continuation lines, which start with a space.
+func readJarManifest(f *zip.File) (map[string]string, error) {
+	content, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	last := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) == 0 {
+			// The main section ends at the first blank line.
+			break
+		}
+		if strings.HasPrefix(text, " ") {
+			if len(last) == 0 {
+				return nil, fmt.Errorf("line %d: continuation without header", line)
+			}
+			headers[last] += text[1:]
+			continue
+		}
+		name, value, ok := strings.Cut(text, ":")
+		if !ok || len(name) == 0 {
+			return nil, fmt.Errorf("line %d: want name: value, got %q", line, text)
+		}
+		last = name
+		headers[name] = strings.TrimPrefix(value, " ")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// licenseIDs returns the SPDX identifiers with known license conditions
+// that `licenses` name by identifier or by URL, e.g. "Apache-2.0" for
+// "https://www.apache.org/licenses/LICENSE-2.0.txt".
+func licenseIDs(licenses []string) []string {
+	var ids []string
+	for _, name := range licenses {
+		id := canonicalSpdxID(name)
+		if _, ok := spdxConditions[id]; !ok {
+			id = jarLicenseURLs[normalizeLicenseURL(name)]
+		}
+		if len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// normalizeLicenseURL returns `u` in lower case without scheme, "www.",
+// trailing slash or file suffix.
+func normalizeLicenseURL(u string) string {
+	u = strings.ToLower(u)
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	}
+	u = strings.TrimPrefix(u, "www.")
+	u = strings.TrimSuffix(u, "/")
+	for _, suffix := range []string{".txt", ".html", ".php"} {
+		u = strings.TrimSuffix(u, suffix)
+	}
+	return u
+}
+
+// splitUnquoted splits `s` at each `sep` outside double quotes.
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// firstNonEmpty returns the first of `values` that is not empty or "".
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// makeJar returns a JAR file holding `files` in order of the names.
+func makeJar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractJarLicense(t *testing.T) {
+	mit, err := FetchLicenseText("MIT", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mit = strings.Replace(mit, "<year> <copyright holders>", "2026 The Fixture Authors", 1)
+	tests := []struct {
+		name               string
+		files              map[string]string
+		expectedPackage    string
+		expectedVersion    string
+		expectedKinds      []string
+		expectedConditions []string
+	}{
+		{
+			name: "spdx bundle license",
+			files: map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\r\nBundle-Name: Fixture Lib\r\nBundle-Version: 1.2.3\r\nBundle-License: Apache-2.0;link=\"https://www.apache.org/licenses/LICENSE-2.0.txt\"\r\n\r\nName: com/example/\r\nBundle-License: MIT\r\n",
+			},
+			expectedPackage:    "Fixture Lib",
+			expectedVersion:    "1.2.3",
+			expectedKinds:      []string{"SPDX-license-identifier-Apache-2.0"},
+			expectedConditions: []string{"notice"},
+		},
+		{
+			name: "url bundle licenses with continuation",
+			files: map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nImplementation-Title: fixture\nImplementation-Version: 4.0\nBundle-License: http://www.apache.org/licenses/LICENSE-2.0.txt, https://www.gnu.org/licenses/old-lic\n enses/lgpl-2.1.html\n",
+			},
+			expectedPackage:    "fixture",
+			expectedVersion:    "4.0",
+			expectedKinds:      []string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-LGPL-2.1-only"},
+			expectedConditions: []string{"notice", "restricted_if_statically_linked"},
+		},
+		{
+			name: "pom licenses",
+			files: map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n",
+				"META-INF/maven/com.example/fixture/pom.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <groupId>com.example</groupId>
+  <artifactId>fixture</artifactId>
+  <version>2.5</version>
+  <licenses>
+    <license>
+      <name>The Apache Software License, Version 2.0</name>
+      <url>http://www.apache.org/licenses/LICENSE-2.0.txt</url>
+    </license>
+    <license>
+      <name>MIT</name>
+    </license>
+  </licenses>
+</project>
+`,
+			},
+			expectedPackage:    "fixture",
+			expectedVersion:    "2.5",
+			expectedKinds:      []string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-MIT"},
+			expectedConditions: []string{"notice"},
+		},
+		{
+			name: "shaded poms",
+			files: map[string]string{
+				"META-INF/MANIFEST.MF":                 "Manifest-Version: 1.0\n",
+				"META-INF/maven/com.example/a/pom.xml": "<project><artifactId>a</artifactId><licenses><license><url>https://opensource.org/licenses/MIT</url></license></licenses></project>",
+				"META-INF/maven/com.example/b/pom.xml": "<project><artifactId>b</artifactId><licenses><license><url>https://opensource.org/licenses/BSD-3-Clause</url></license></licenses></project>",
+			},
+			expectedPackage:    "lib",
+			expectedKinds:      []string{"SPDX-license-identifier-BSD-3-Clause", "SPDX-license-identifier-MIT"},
+			expectedConditions: []string{"notice"},
+		},
+		{
+			name: "license file",
+			files: map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n",
+				"META-INF/LICENSE":     mit,
+			},
+			expectedPackage:    "lib",
+			expectedKinds:      []string{"SPDX-license-identifier-MIT"},
+			expectedConditions: []string{"notice"},
+		},
+		{
+			name: "unknown bundle license",
+			files: map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nBundle-License: https://example.com/our-license\n",
+			},
+			expectedPackage:    "lib",
+			expectedKinds:      []string{"legacy_by_exception_only"},
+			expectedConditions: []string{"by_exception_only"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"prebuilts/lib.jar": {Data: makeJar(t, tt.files)}}
+			stub, err := ExtractJarLicense("prebuilts/lib.jar", fsys)
+			if err != nil {
+				t.Fatalf("ExtractJarLicense(): got error %s, want no error", err)
+			}
+			expected := MetaLicStub{
+				Name:              "prebuilts/lib.jar.meta_lic",
+				PackageName:       tt.expectedPackage,
+				Version:           tt.expectedVersion,
+				LicenseKinds:      tt.expectedKinds,
+				LicenseConditions: tt.expectedConditions,
+			}
+			if !reflect.DeepEqual(stub, expected) {
+				t.Errorf("ExtractJarLicense(): got %+v, want %+v", stub, expected)
+			}
+		})
+	}
+}
+
+func TestExtractJarLicenseTextDir(t *testing.T) {
+	apache, err := FetchLicenseText("Apache-2.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"prebuilts/lib.jar": {Data: makeJar(t, map[string]string{
+		"META-INF/MANIFEST.MF":  "Manifest-Version: 1.0\n",
+		"META-INF/LICENSE.txt":  apache,
+		"META-INF/NOTICE":       "Not a license file.\n",
+		"com/example/LICENSE":   "Not in META-INF.\n",
+		"com/example/Lib.class": "\xca\xfe\xba\xbe",
+	})}}
+	dir := t.TempDir()
+	stub, err := (&JarLicenseExtractor{TextDir: dir}).Extract(fsys, "prebuilts/lib.jar")
+	if err != nil {
+		t.Fatalf("Extract(): got error %s, want no error", err)
+	}
+	text := filepath.Join(dir, "prebuilts", "lib.jar", "META-INF", "LICENSE.txt")
+	if expected := []string{filepath.ToSlash(text)}; !reflect.DeepEqual(stub.LicenseTexts, expected) {
+		t.Errorf("Extract(): got license texts %q, want %q", stub.LicenseTexts, expected)
+	}
+	if content, err := os.ReadFile(text); err != nil || string(content) != apache {
+		t.Errorf("Extract(): got license text %q (error %v), want Apache-2.0 text", content, err)
+	}
+	if expected := []string{"SPDX-license-identifier-Apache-2.0"}; !reflect.DeepEqual(stub.LicenseKinds, expected) {
+		t.Errorf("Extract(): got license kinds %q, want %q", stub.LicenseKinds, expected)
+	}
+}
+
+func TestExtractJarLicenseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		expectUnknown bool
+		expectedError string
+	}{
+		{
+			name:          "no manifest",
+			data:          makeJar(t, map[string]string{"META-INF/LICENSE": "Licensed.\n"}),
+			expectUnknown: true,
+			expectedError: "no META-INF/MANIFEST.MF",
+		},
+		{
+			name:          "no license",
+			data:          makeJar(t, map[string]string{"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nBundle-Name: lib\n"}),
+			expectUnknown: true,
+			expectedError: "no Bundle-License header, pom.xml license or license file",
+		},
+		{
+			name:          "invalid manifest",
+			data:          makeJar(t, map[string]string{"META-INF/MANIFEST.MF": " continued\n"}),
+			expectedError: "line 1: continuation without header",
+		},
+		{
+			name: "invalid pom",
+			data: makeJar(t, map[string]string{
+				"META-INF/MANIFEST.MF":                 "Manifest-Version: 1.0\n",
+				"META-INF/maven/com.example/a/pom.xml": "<project><licenses>",
+			}),
+			expectedError: "invalid \"META-INF/maven/com.example/a/pom.xml\"",
+		},
+		{
+			name:          "not a zip file",
+			data:          []byte("not a zip file"),
+			expectedError: "invalid JAR file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"lib.jar": {Data: tt.data}}
+			_, err := ExtractJarLicense("lib.jar", fsys)
+			if err == nil {
+				t.Fatalf("ExtractJarLicense(): got no error, want %q", tt.expectedError)
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("ExtractJarLicense(): got error %q, want %q", err, tt.expectedError)
+			}
+			var unknown *UnknownLicenseError
+			if errors.As(err, &unknown) != tt.expectUnknown {
+				t.Errorf("ExtractJarLicense(): got error %T, want *UnknownLicenseError %v", err, tt.expectUnknown)
+			}
+		})
+	}
+}
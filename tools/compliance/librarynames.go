@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LibraryNames replaces the library names NoticeIndex derives for targets
+// from license text names, METADATA, package names or project directories
+// when they read badly in a published notice.
+//
+// Each entry names either a license metadata file, matching only that
+// target, or a project directory prefix, matching the targets in projects
+// under it. A license metadata file entry wins over project entries, and the
+// longest project prefix wins over shorter ones.
+//
+// LibraryNames is safe for concurrent use.
+type LibraryNames struct {
+	// metaLics maps license metadata file paths to library names.
+	metaLics map[string]string
+	// projects maps project directory prefixes to library names.
+	projects map[string]string
+
+	// mu guards used.
+	mu sync.Mutex
+	// used records the entries that named a library.
+	used map[string]struct{}
+}
+
+// ParseLibraryNames reads the library names from lines of `key=name` where
+// `key` is a license metadata file path ending in ".meta_lic" or a project
+// directory prefix. e.g.
+//
+//	# The METADATA name reads "libfoo_v_1.2"
+//	external/foo=Foo
+//	vendor/acme/lib/libbar.so.meta_lic=Acme Bar
+//
+// Blank lines and lines starting with "#" are ignored.
+func ParseLibraryNames(r io.Reader) (*LibraryNames, error) {
+	ln := &LibraryNames{
+		metaLics: make(map[string]string),
+		projects: make(map[string]string),
+		used:     make(map[string]struct{}),
+	}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, name, ok := strings.Cut(text, "=")
+		key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+		if !ok || len(key) == 0 || len(name) == 0 {
+			return nil, fmt.Errorf("line %d: want key=name, got %q", line, text)
+		}
+		entries := ln.projects
+		if strings.HasSuffix(key, ".meta_lic") {
+			entries = ln.metaLics
+		} else {
+			key = strings.TrimSuffix(key, "/")
+		}
+		if _, ok := entries[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line, key)
+		}
+		entries[key] = name
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ln, nil
+}
+
+// ReadLibraryNamesFile reads the library names from the file at `path` per
+// ParseLibraryNames.
+func ReadLibraryNamesFile(path string) (*LibraryNames, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ln, err := ParseLibraryNames(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid library names file %q: %w", path, err)
+	}
+	return ln, nil
+}
+
+// lookup returns the library name for `tn` and true, or false when no entry
+// matches.
+func (ln *LibraryNames) lookup(tn *TargetNode) (string, bool) {
+	key := tn.Name()
+	name, ok := ln.metaLics[key]
+	if !ok {
+		for _, p := range tn.Projects() {
+			for prefix, prefixName := range ln.projects {
+				if !hasPathPrefix(p, prefix) || (ok && len(prefix) <= len(key)) {
+					continue
+				}
+				key, name, ok = prefix, prefixName, true
+			}
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	ln.mu.Lock()
+	ln.used[key] = struct{}{}
+	ln.mu.Unlock()
+	return name, true
+}
+
+// Unused returns the keys of the entries that have not named any library
+// yet, ordered by key, so that stale entries can be removed.
+func (ln *LibraryNames) Unused() []string {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+	var unused []string
+	for _, entries := range []map[string]string{ln.metaLics, ln.projects} {
+		for key := range entries {
+			if _, ok := ln.used[key]; !ok {
+				unused = append(unused, key)
+			}
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestParseLibraryNames(t *testing.T) {
+	tests := []struct {
+		name          string
+		in            string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			in:   "# comment\n\nexternal/foo/ = Foo\nvendor/lib.so.meta_lic=Vendor Lib\n",
+		},
+		{
+			name:          "missing separator",
+			in:            "external/foo=Foo\nexternal/bar\n",
+			expectedError: "line 2: want key=name",
+		},
+		{
+			name:          "empty name",
+			in:            "external/foo=\n",
+			expectedError: "line 1: want key=name",
+		},
+		{
+			name:          "duplicate",
+			in:            "external/foo=Foo\n# again\nexternal/foo/=Foo2\n",
+			expectedError: "line 3: duplicate key \"external/foo\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := ParseLibraryNames(strings.NewReader(tt.in))
+			if len(tt.expectedError) > 0 {
+				if err == nil {
+					t.Fatalf("ParseLibraryNames(): got no error, want %q", tt.expectedError)
+				}
+				if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("ParseLibraryNames(): got error %q, want %q", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLibraryNames(): got error %s, want no error", err)
+			}
+			expected := []string{"external/foo", "vendor/lib.so.meta_lic"}
+			if actual := ln.Unused(); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Unused(): got %q, want %q", actual, expected)
+			}
+		})
+	}
+}
+
+func TestIndexLibraryNames(t *testing.T) {
+	rootFS := testfs.TestFS{}
+	var root strings.Builder
+	root.WriteString(AOSP + "is_container: true\ninstalled: \"system.img\"\n")
+	// target adds a target `name` for package `pkg` in `project`.
+	target := func(name, pkg, project string) {
+		rootFS[name+".meta_lic"] = []byte(fmt.Sprintf("package_name: \"%s\"\nprojects: \"%s\"\nlicense_conditions: \"notice\"\nlicense_texts: \"%s/LICENSE\"\ninstalled: \"system/lib/%s.so\"\n", pkg, project, project, name))
+		rootFS[project+"/LICENSE"] = []byte("License of " + pkg + ".\n")
+		fmt.Fprintf(&root, "deps: {\n  file: \"%s.meta_lic\"\n  annotations: \"static\"\n}\n", name)
+	}
+	target("libfoo", "foo_v_1.2", "external/foo")
+	target("libbar", "bar", "external/bar")
+	target("vendor/acme/libbaz", "baz", "vendor/acme")
+	target("libqux", "qux", "other/qux")
+	rootFS["root.meta_lic"] = []byte(root.String())
+
+	lg, err := ReadLicenseGraph(&rootFS, &bytes.Buffer{}, []string{"root.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ln, err := ParseLibraryNames(strings.NewReader(`external=External
+external/foo=Foo
+external/ba=Not A Prefix
+vendor=Vendor
+vendor/acme/libbaz.meta_lic=Acme Baz
+gone/project=Gone
+`))
+	if err != nil {
+		t.Fatalf("ParseLibraryNames(): got error %s, want no error", err)
+	}
+	ni, err := IndexLicenseTextsWithOptions(context.Background(), &rootFS, lg, ResolveNotices(lg), IndexOptions{LibraryNames: ln})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(): got error %s, want no error", err)
+	}
+
+	// The longest prefix names libfoo, the shorter one libbar, the license
+	// metadata file entry libbaz, and libqux keeps its derived name.
+	expected := []string{"Acme Baz", "External", "Foo", "qux"}
+	if actual := ni.AllLibraries(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("AllLibraries(): got %q, want %q", actual, expected)
+	}
+	expectedUnused := []string{"external/ba", "gone/project", "vendor"}
+	if actual := ln.Unused(); !reflect.DeepEqual(actual, expectedUnused) {
+		t.Errorf("Unused(): got %q, want %q", actual, expectedUnused)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MissingFilesError lists license text files referenced by license metadata
+// that do not exist.
+type MissingFilesError struct {
+	// Files lists the missing files. (sorted)
+	Files []string
+}
+
+// Error returns a string listing the missing files.
+func (e *MissingFilesError) Error() string {
+	return fmt.Sprintf("missing license text file(s): %s", strings.Join(e.Files, ", "))
+}
+
+// ValidateLicenseFiles checks that every license text file referenced by the
+// targets in `lg` exists in `rootFS` returning a *MissingFilesError listing
+// all of the missing files, or nil if none are missing.
+//
+// Returns any other error checking a file, e.g. fs.ErrPermission, as is
+// rather than reporting the file missing.
+func ValidateLicenseFiles(lg *LicenseGraph, rootFS fs.FS) error {
+	checked := make(map[string]struct{})
+	var missing []string
+	for _, tn := range lg.Targets() {
+		for _, text := range tn.LicenseTexts() {
+			fname := filepath.Clean(strings.SplitN(text, ":", 2)[0])
+			if _, ok := checked[fname]; ok {
+				continue
+			}
+			checked[fname] = struct{}{}
+			if _, err := fs.Stat(rootFS, fname); errors.Is(err, fs.ErrNotExist) {
+				missing = append(missing, fname)
+			} else if err != nil {
+				return fmt.Errorf("cannot read license text file %q: %w", fname, err)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &MissingFilesError{missing}
+}
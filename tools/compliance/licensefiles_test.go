@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+	"android/soong/tools/compliance/testutil"
+)
+
+func TestValidateLicenseFiles(t *testing.T) {
+	dep := func(file string) string {
+		return "deps: {\n  file: \"" + file + "\"\n  annotations: \"static\"\n}\n"
+	}
+	tests := []struct {
+		name     string
+		fs       *testfs.TestFS
+		expected []string
+	}{
+		{
+			name: "allpresent",
+			fs: &testfs.TestFS{
+				"bin.meta_lic":  []byte(AOSP + dep("liba.meta_lic")),
+				"liba.meta_lic": []byte("license_texts: \"liba/LICENSE:liba\"\n"),
+				"LICENSE":       []byte("license"),
+				"liba/LICENSE":  []byte("license"),
+			},
+		},
+		{
+			name: "onemissing",
+			fs: &testfs.TestFS{
+				"bin.meta_lic":  []byte(AOSP + dep("liba.meta_lic")),
+				"liba.meta_lic": []byte("license_texts: \"liba/LICENSE\"\n"),
+				"LICENSE":       []byte("license"),
+			},
+			expected: []string{"liba/LICENSE"},
+		},
+		{
+			name: "manymissing",
+			fs: &testfs.TestFS{
+				"bin.meta_lic":  []byte(AOSP + dep("liba.meta_lic") + dep("libb.meta_lic")),
+				"liba.meta_lic": []byte("license_texts: \"liba/LICENSE\"\nlicense_texts: \"NOTICE\"\n"),
+				"libb.meta_lic": []byte("license_texts: \"liba/LICENSE:other\"\nlicense_texts: \"LICENSE\"\n"),
+			},
+			expected: []string{"LICENSE", "NOTICE", "liba/LICENSE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			lg, err := ReadLicenseGraph(tt.fs, stderr, []string{"bin.meta_lic"})
+			if err != nil {
+				t.Fatalf("unexpected test data error: got %s, want no error", err)
+			}
+			err = ValidateLicenseFiles(lg, tt.fs)
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("ValidateLicenseFiles(): got error %s, want no error", err)
+				}
+				return
+			}
+			var mfe *MissingFilesError
+			if !errors.As(err, &mfe) {
+				t.Fatalf("ValidateLicenseFiles(): got %v, want *MissingFilesError", err)
+			}
+			if !reflect.DeepEqual(mfe.Files, tt.expected) {
+				t.Errorf("ValidateLicenseFiles(): got missing files %q, want %q", mfe.Files, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateLicenseFilesUnreadable(t *testing.T) {
+	rootFS := testutil.WithErrors(testutil.NewMemFS(map[string]string{
+		"bin.meta_lic": AOSP + "license_texts: \"LICENSE\"\n",
+		"LICENSE":      "license",
+	}), map[string]error{"LICENSE": fs.ErrPermission})
+
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	err = ValidateLicenseFiles(lg, rootFS)
+	var mfe *MissingFilesError
+	if errors.As(err, &mfe) {
+		t.Errorf("ValidateLicenseFiles(): got %v for an unreadable file, want it not missing", err)
+	}
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("ValidateLicenseFiles(): got %v, want fs.ErrPermission", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LicenseGraphCache reads the license graph rooted at each distinct set of
+// root files once and shares it between callers, e.g. the requests to a
+// long-running server.
+//
+// Safe for concurrent use. Never evicts a graph: create a new cache after the
+// license metadata changes.
+type LicenseGraphCache struct {
+	rootFS fs.FS
+	stderr io.Writer
+
+	mu sync.Mutex
+	// graphs maps the root set id to the graph. (guarded by mu)
+	graphs map[string]*cachedLicenseGraph
+}
+
+// cachedLicenseGraph is a license graph read, or being read, by a
+// LicenseGraphCache.
+type cachedLicenseGraph struct {
+	// ready closes when lg and err are set.
+	ready chan struct{}
+	lg    *LicenseGraph
+	err   error
+}
+
+// NewLicenseGraphCache returns an empty cache reading license metadata files
+// from `rootFS` and writing any warnings to `stderr`.
+func NewLicenseGraphCache(rootFS fs.FS, stderr io.Writer) *LicenseGraphCache {
+	return &LicenseGraphCache{rootFS: rootFS, stderr: stderr, graphs: make(map[string]*cachedLicenseGraph)}
+}
+
+// RootSet returns the sorted distinct `rootFiles` and the id of the set:
+// "sha256:" followed by the hex digest of the files.
+func RootSet(rootFiles []string) ([]string, string) {
+	files := append([]string{}, rootFiles...)
+	sort.Strings(files)
+	distinct := files[:0]
+	for i, f := range files {
+		if i == 0 || f != files[i-1] {
+			distinct = append(distinct, f)
+		}
+	}
+	return distinct, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(strings.Join(distinct, "\n"))))
+}
+
+// Get returns the root set id and the license graph rooted at `rootFiles`,
+// reading the graph unless already cached. `cached` reports whether the graph
+// came from the cache.
+//
+// Concurrent calls for the same root set wait for a single read. Failed reads
+// are not cached so that a later call can retry.
+func (c *LicenseGraphCache) Get(rootFiles []string) (id string, lg *LicenseGraph, cached bool, err error) {
+	files, id := RootSet(rootFiles)
+
+	c.mu.Lock()
+	g, cached := c.graphs[id]
+	if !cached {
+		g = &cachedLicenseGraph{ready: make(chan struct{})}
+		c.graphs[id] = g
+	}
+	c.mu.Unlock()
+
+	if cached {
+		<-g.ready
+		return id, g.lg, cached, g.err
+	}
+	g.lg, g.err = ReadLicenseGraph(c.rootFS, c.stderr, files)
+	if g.err == nil && g.lg == nil {
+		g.err = fmt.Errorf("no license graph for %q", files)
+	}
+	if g.err != nil {
+		c.mu.Lock()
+		delete(c.graphs, id)
+		c.mu.Unlock()
+	}
+	close(g.ready)
+	return id, g.lg, cached, g.err
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestRootSet(t *testing.T) {
+	files, id := RootSet([]string{"b.meta_lic", "a.meta_lic", "b.meta_lic"})
+	if expected := []string{"a.meta_lic", "b.meta_lic"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("RootSet(): got files %q, want %q", files, expected)
+	}
+	if _, other := RootSet([]string{"a.meta_lic", "b.meta_lic"}); other != id {
+		t.Errorf("RootSet(): got id %q for the same set, want %q", other, id)
+	}
+	if _, other := RootSet([]string{"a.meta_lic"}); other == id {
+		t.Errorf("RootSet(): got id %q for a different set, want a different id", other)
+	}
+}
+
+func TestLicenseGraphCache(t *testing.T) {
+	rootFS := &testfs.TestFS{
+		"app.meta_lic": []byte(AOSP + "deps: {\n  file: \"lib.meta_lic\"\n}\n"),
+		"bin.meta_lic": []byte(AOSP + "deps: {\n  file: \"lib.meta_lic\"\n}\n"),
+		"lib.meta_lic": []byte(MIT),
+	}
+	c := NewLicenseGraphCache(rootFS, &bytes.Buffer{})
+
+	id, lg, cached, err := c.Get([]string{"bin.meta_lic", "app.meta_lic"})
+	if err != nil {
+		t.Fatalf("Get(): got error %s, want no error", err)
+	}
+	if cached {
+		t.Errorf("Get(): got cached graph on first call, want read")
+	}
+	if len(lg.Targets()) != 3 {
+		t.Errorf("Get(): got %d targets, want 3", len(lg.Targets()))
+	}
+
+	again, lgAgain, cached, err := c.Get([]string{"app.meta_lic", "bin.meta_lic", "app.meta_lic"})
+	if err != nil {
+		t.Fatalf("Get(): got error %s, want no error", err)
+	}
+	if !cached || again != id || lgAgain != lg {
+		t.Errorf("Get(): got cached=%t id %q for the same root set, want cached graph %q", cached, again, id)
+	}
+
+	if _, _, _, err = c.Get([]string{"missing.meta_lic"}); err == nil {
+		t.Errorf("Get(missing): got no error, want error")
+	}
+	(*rootFS)["missing.meta_lic"] = []byte(MIT)
+	if _, _, cached, err = c.Get([]string{"missing.meta_lic"}); err != nil || cached {
+		t.Errorf("Get(missing) after adding the file: got cached=%t error %v, want read with no error", cached, err)
+	}
+}
+
+func TestLicenseGraphCacheConcurrent(t *testing.T) {
+	rootFS := &testfs.TestFS{"app.meta_lic": []byte(AOSP)}
+	c := NewLicenseGraphCache(rootFS, &bytes.Buffer{})
+
+	const n = 8
+	var wg sync.WaitGroup
+	graphs := make([]*LicenseGraph, n)
+	reads := make([]bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, lg, cached, err := c.Get([]string{"app.meta_lic"})
+			if err != nil {
+				t.Errorf("Get(): got error %s, want no error", err)
+			}
+			graphs[i], reads[i] = lg, !cached
+		}(i)
+	}
+	wg.Wait()
+	count := 0
+	for i := range graphs {
+		if graphs[i] != graphs[0] {
+			t.Errorf("Get(): got different graphs for concurrent calls, want one")
+		}
+		if reads[i] {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Get(): got %d reads for concurrent calls, want 1", count)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxReadErrors is the number of errors after which reading a license
+// graph with ReadOptions.KeepGoing stops when ReadOptions.MaxErrors is 0.
+const DefaultMaxReadErrors = 100
+
+// MetadataError describes a license metadata file that cannot be read or
+// parsed.
+type MetadataError struct {
+	// File is the path to the license metadata file.
+	File string
+	// Line and Column locate the error in the file, starting at 1, or are 0
+	// when unknown.
+	Line, Column int
+	// Field is the offending field, e.g. "deps.file", or empty when unknown.
+	Field string
+	// Root is the root file of the graph whose dependencies pulled in File.
+	Root string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the error with its location, e.g.
+// `license metadata "lib.meta_lic" line 2 field "foo": unknown field: foo
+// (required by root "app.meta_lic")`.
+func (e *MetadataError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "license metadata %q", e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, " line %d", e.Line)
+	}
+	if len(e.Field) > 0 {
+		fmt.Fprintf(&sb, " field %q", e.Field)
+	}
+	fmt.Fprintf(&sb, ": %s", e.Err)
+	if len(e.Root) > 0 && e.Root != e.File {
+		fmt.Fprintf(&sb, " (required by root %q)", e.Root)
+	}
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *MetadataError) Unwrap() error {
+	return e.Err
+}
+
+// MetadataErrors lists the errors reading a license graph with
+// ReadOptions.KeepGoing in the order they occurred.
+type MetadataErrors []*MetadataError
+
+// Error returns the errors one per line.
+func (errs MetadataErrors) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d errors reading license metadata:", len(errs))
+	for _, err := range errs {
+		fmt.Fprintf(&sb, "\n  %s", err)
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors for errors.Is and errors.As.
+func (errs MetadataErrors) Unwrap() []error {
+	result := make([]error, 0, len(errs))
+	for _, err := range errs {
+		result = append(result, err)
+	}
+	return result
+}
+
+// protoTextErrorLocation matches the location prototext reports in its
+// errors, e.g. "proto: (line 2:1): unknown field: foo", where the space after
+// "proto:" is sometimes a non-breaking space to discourage matching the text.
+var protoTextErrorLocation = regexp.MustCompile(`^proto:[\s\x{00a0}]*(?:syntax error )?\(line (\d+):(\d+)\):\s*`)
+
+// newMetadataError returns the MetadataError for `err` reading `file` for
+// `root` locating prototext errors in `data`.
+func newMetadataError(file, root string, data []byte, err error) *MetadataError {
+	var me *MetadataError
+	if !errors.As(err, &me) {
+		me = &MetadataError{Err: err}
+		if m := protoTextErrorLocation.FindStringSubmatch(err.Error()); m != nil {
+			me.Line, _ = strconv.Atoi(m[1])
+			me.Column, _ = strconv.Atoi(m[2])
+			me.Field = fieldAtLine(data, me.Line)
+			me.Err = errors.New(err.Error()[len(m[0]):])
+		}
+	}
+	me.File = file
+	me.Root = root
+	return me
+}
+
+// fieldAtLine returns the path of the field starting on line `line` of the
+// license metadata text `data`, e.g. "deps.file", or empty when the line does
+// not start with a field name.
+func fieldAtLine(data []byte, line int) string {
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	fieldName := func(text string) string {
+		text = strings.TrimSpace(text)
+		end := strings.IndexFunc(text, func(r rune) bool {
+			return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		})
+		if end < 0 {
+			end = len(text)
+		}
+		return text[:end]
+	}
+	// Track the messages enclosing the line, e.g. `deps {` or `deps: {`.
+	var path []string
+	for _, text := range lines[:line-1] {
+		text = strings.TrimSpace(text)
+		switch {
+		case strings.HasSuffix(text, "{"):
+			path = append(path, fieldName(text))
+		case text == "}" && len(path) > 0:
+			path = path[:len(path)-1]
+		}
+	}
+	name := fieldName(lines[line-1])
+	if len(name) == 0 {
+		return ""
+	}
+	return strings.Join(append(path, name), ".")
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestMetadataError(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedLine  int
+		expectedField string
+		expectedError string
+	}{
+		{
+			name:          "unknownfield",
+			data:          "package_name: \"lib\"\nfoo: \"bar\"\n",
+			expectedLine:  2,
+			expectedField: "foo",
+			expectedError: `license metadata "lib.meta_lic" line 2 field "foo": unknown field: foo (required by root "app.meta_lic")`,
+		},
+		{
+			name:          "depfield",
+			data:          "package_name: \"lib\"\ndeps: {\n  file: 3\n}\n",
+			expectedLine:  3,
+			expectedField: "deps.file",
+			expectedError: `license metadata "lib.meta_lic" line 3 field "deps.file": invalid value for string type: 3 (required by root "app.meta_lic")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootFS := &testfs.TestFS{
+				"app.meta_lic": []byte("package_name: \"app\"\ndeps: {\n  file: \"lib.meta_lic\"\n}\n"),
+				"lib.meta_lic": []byte(tt.data),
+				"a.meta_lic":   []byte("package_name: \"a\"\n"),
+			}
+			_, err := ReadLicenseGraph(rootFS, io.Discard, []string{"app.meta_lic"})
+			var me *MetadataError
+			if !errors.As(err, &me) {
+				t.Fatalf("ReadLicenseGraph(): got error %v, want *MetadataError", err)
+			}
+			if me.File != "lib.meta_lic" || me.Root != "app.meta_lic" || me.Line != tt.expectedLine || me.Field != tt.expectedField {
+				t.Errorf("ReadLicenseGraph(): got %q %q line %d field %q, want %q %q line %d field %q",
+					me.File, me.Root, me.Line, me.Field, "lib.meta_lic", "app.meta_lic", tt.expectedLine, tt.expectedField)
+			}
+			if len(tt.expectedError) > 0 && err.Error() != tt.expectedError {
+				t.Errorf("ReadLicenseGraph(): got error %q, want %q", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestMetadataErrorNotFound(t *testing.T) {
+	rootFS := &testfs.TestFS{
+		"app.meta_lic": []byte("package_name: \"app\"\ndeps: {\n  file: \"lib.meta_lic\"\n}\n"),
+	}
+	_, err := ReadLicenseGraph(rootFS, io.Discard, []string{"app.meta_lic"})
+	var me *MetadataError
+	if !errors.As(err, &me) || me.File != "lib.meta_lic" || me.Root != "app.meta_lic" {
+		t.Fatalf("ReadLicenseGraph(): got error %v, want *MetadataError for lib.meta_lic", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadLicenseGraph(): got error %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestReadLicenseGraphKeepGoing(t *testing.T) {
+	rootFS := testfs.TestFS{}
+	var roots []string
+	for i := 0; i < 5; i++ {
+		root := fmt.Sprintf("app%d.meta_lic", i)
+		lib := fmt.Sprintf("lib%d.meta_lic", i)
+		rootFS[root] = []byte(fmt.Sprintf("package_name: \"app%d\"\ndeps: {\n  file: %q\n}\n", i, lib))
+		if i%2 == 0 {
+			rootFS[lib] = []byte(fmt.Sprintf("package_name: \"lib%d\"\nfoo: 1\n", i))
+		} else {
+			rootFS[lib] = []byte(fmt.Sprintf("package_name: \"lib%d\"\n", i))
+		}
+		roots = append(roots, root)
+	}
+
+	// Without KeepGoing, reading stops at the first error.
+	stderr := &bytes.Buffer{}
+	lg, err := ReadLicenseGraphWithOptions(context.Background(), &rootFS, stderr, roots, ReadOptions{})
+	var me *MetadataError
+	if lg != nil || !errors.As(err, &me) {
+		t.Fatalf("ReadLicenseGraphWithOptions(): got %v, %v, want nil graph and *MetadataError", lg, err)
+	}
+	if actual := strings.Count(stderr.String(), "\n"); actual != 1 {
+		t.Errorf("ReadLicenseGraphWithOptions(): got %d errors reported, want 1: %s", actual, stderr)
+	}
+
+	// With KeepGoing, every malformed file gets reported with its root.
+	stderr.Reset()
+	lg, err = ReadLicenseGraphWithOptions(context.Background(), &rootFS, stderr, roots, ReadOptions{KeepGoing: true})
+	var errs MetadataErrors
+	if lg != nil || !errors.As(err, &errs) {
+		t.Fatalf("ReadLicenseGraphWithOptions(KeepGoing): got %v, %v, want nil graph and MetadataErrors", lg, err)
+	}
+	var actual []string
+	for _, e := range errs {
+		actual = append(actual, e.Root+" "+e.File)
+	}
+	sort.Strings(actual)
+	expected := []string{"app0.meta_lic lib0.meta_lic", "app2.meta_lic lib2.meta_lic", "app4.meta_lic lib4.meta_lic"}
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Errorf("ReadLicenseGraphWithOptions(KeepGoing): got errors %q, want %q", actual, expected)
+	}
+	if !strings.HasPrefix(err.Error(), "3 errors reading license metadata:\n") {
+		t.Errorf("ReadLicenseGraphWithOptions(KeepGoing): got error %q, want 3 errors", err)
+	}
+	if !errors.As(err, &me) {
+		t.Errorf("ReadLicenseGraphWithOptions(KeepGoing): got error %v, want *MetadataError", err)
+	}
+
+	// MaxErrors caps the errors reported.
+	stderr.Reset()
+	_, err = ReadLicenseGraphWithOptions(context.Background(), &rootFS, stderr, roots, ReadOptions{KeepGoing: true, MaxErrors: 2})
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("ReadLicenseGraphWithOptions(MaxErrors: 2): got error %v, want 2 errors", err)
+	}
+	if actual := strings.Count(stderr.String(), "\n"); actual != 2 {
+		t.Errorf("ReadLicenseGraphWithOptions(MaxErrors: 2): got %d errors reported, want 2: %s", actual, stderr)
+	}
+
+	// A well-formed graph reads the same with KeepGoing.
+	lg, err = ReadLicenseGraphWithOptions(context.Background(), &rootFS, io.Discard, []string{"app1.meta_lic", "app3.meta_lic"}, ReadOptions{KeepGoing: true})
+	if err != nil || len(lg.Targets()) != 4 {
+		t.Errorf("ReadLicenseGraphWithOptions(valid): got %v, %v, want 4 targets", lg, err)
+	}
+}
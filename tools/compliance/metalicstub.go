@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// spdxConditions maps the SPDX identifiers of the bundled license texts to
+// the license condition Soong assigns their license kinds.
+var spdxConditions = map[string]string{
+	"Apache-2.0":    "notice",
+	"BSD-2-Clause":  "notice",
+	"BSD-3-Clause":  "notice",
+	"CC0-1.0":       "unencumbered",
+	"GPL-2.0-only":  "restricted",
+	"GPL-3.0-only":  "restricted",
+	"ISC":           "notice",
+	"LGPL-2.1-only": "restricted_if_statically_linked",
+	"MIT":           "notice",
+	"MPL-2.0":       "reciprocal",
+	"Zlib":          "notice",
+}
+
+// unidentifiedLicenseKind is the license kind of license texts matching no
+// bundled SPDX license text closely enough. Policy makes someone review them.
+const unidentifiedLicenseKind = "legacy_by_exception_only"
+
+// MetaLicStub describes a license metadata file (*.meta_lic) for a
+// dependency that the build does not describe, e.g. a vendored Go module, so
+// that the notice tools can include it.
+type MetaLicStub struct {
+	// Name is the path to write the license metadata file to relative to
+	// the output directory, e.g. "gopkg.in/yaml.v3@v3.0.1.meta_lic".
+	Name string
+	// PackageName names the dependency in notices.
+	PackageName string
+	// Version is the version of the dependency or empty.
+	Version string
+	// LicenseKinds lists the license kinds of the dependency, e.g.
+	// "SPDX-license-identifier-MIT".
+	LicenseKinds []string
+	// LicenseConditions lists the conditions of the license kinds.
+	LicenseConditions []string
+	// LicenseTexts lists the paths to the license text files.
+	LicenseTexts []string
+	// Installed lists the install paths of the dependency if any.
+	Installed []string
+	// IsContainer marks a dependency aggregating others, e.g. an image.
+	IsContainer bool
+	// Deps lists the license metadata files of the static dependencies.
+	Deps []string
+}
+
+// String returns the license metadata file content in text proto format.
+func (s MetaLicStub) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "package_name: %q\n", s.PackageName)
+	if len(s.Version) > 0 {
+		fmt.Fprintf(&sb, "module_name: %q\n", s.PackageName+"@"+s.Version)
+	} else {
+		fmt.Fprintf(&sb, "module_name: %q\n", s.PackageName)
+	}
+	for _, kind := range s.LicenseKinds {
+		fmt.Fprintf(&sb, "license_kinds: %q\n", kind)
+	}
+	for _, condition := range s.LicenseConditions {
+		fmt.Fprintf(&sb, "license_conditions: %q\n", condition)
+	}
+	for _, text := range s.LicenseTexts {
+		fmt.Fprintf(&sb, "license_texts: %q\n", text)
+	}
+	if s.IsContainer {
+		fmt.Fprintf(&sb, "is_container: true\n")
+	}
+	for _, installed := range s.Installed {
+		fmt.Fprintf(&sb, "installed: %q\n", installed)
+	}
+	for _, dep := range s.Deps {
+		fmt.Fprintf(&sb, "deps: {\n  file: %q\n  annotations: \"static\"\n}\n", dep)
+	}
+	return sb.String()
+}
+
+// WriteMetaLicStubs writes each of `stubs` to its Name under `dir` creating
+// directories as needed.
+//
+// Returns the paths to the files written.
+func WriteMetaLicStubs(dir string, stubs []MetaLicStub) ([]string, error) {
+	paths := make([]string, 0, len(stubs))
+	for _, stub := range stubs {
+		path := filepath.Join(dir, filepath.FromSlash(stub.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(stub.String()), 0666); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// identifyLicense returns the license kind and condition of the license text
+// `text`: the SPDX license scoring highest per SimilarityScore among the
+// bundled texts when it scores at least `threshold`, or else
+// unidentifiedLicenseKind.
+func identifyLicense(text string, threshold float64) (string, string) {
+	words := similarityWords(text)
+	best, bestScore := "", 0.0
+	for id := range spdxConditions {
+		spdxText, err := FetchLicenseText(id, nil)
+		if err != nil {
+			continue
+		}
+		spdxWords := similarityWords(spdxText)
+		// Skip the expensive comparison when it cannot reach the threshold.
+		if maxSimilarity(len(words), len(spdxWords)) < threshold {
+			continue
+		}
+		score := similarityScore(words, spdxWords)
+		if score > bestScore || (score == bestScore && id < best) {
+			best, bestScore = id, score
+		}
+	}
+	if len(best) == 0 || bestScore < threshold {
+		return unidentifiedLicenseKind, "by_exception_only"
+	}
+	return spdxLicenseKindPrefix + best, spdxConditions[best]
+}
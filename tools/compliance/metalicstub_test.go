@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetaLicStub(t *testing.T) {
+	stub := MetaLicStub{
+		Name:              "example.com/mod@v1.0.0.meta_lic",
+		PackageName:       "example.com/mod",
+		Version:           "v1.0.0",
+		LicenseKinds:      []string{"SPDX-license-identifier-MIT"},
+		LicenseConditions: []string{"notice"},
+		LicenseTexts:      []string{"cache/example.com/mod@v1.0.0/LICENSE"},
+		Installed:         []string{"out/target/product/fictional/system/bin/tool"},
+	}
+	expected := `package_name: "example.com/mod"
+module_name: "example.com/mod@v1.0.0"
+license_kinds: "SPDX-license-identifier-MIT"
+license_conditions: "notice"
+license_texts: "cache/example.com/mod@v1.0.0/LICENSE"
+installed: "out/target/product/fictional/system/bin/tool"
+`
+	if actual := stub.String(); actual != expected {
+		t.Errorf("String(): got %q, want %q", actual, expected)
+	}
+
+	dir := t.TempDir()
+	paths, err := WriteMetaLicStubs(dir, []MetaLicStub{stub})
+	if err != nil {
+		t.Fatalf("WriteMetaLicStubs(): got error %s, want no error", err)
+	}
+	expectedPaths := []string{filepath.Join(dir, "example.com", "mod@v1.0.0.meta_lic")}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("WriteMetaLicStubs(): got %q, want %q", paths, expectedPaths)
+	}
+
+	// The license graph reader accepts the stub.
+	lg, err := ReadLicenseGraph(FS, &bytes.Buffer{}, paths)
+	if err != nil {
+		t.Fatalf("ReadLicenseGraph(stub): got error %s, want no error", err)
+	}
+	tn := lg.Targets()[0]
+	if tn.PackageName() != "example.com/mod" || !reflect.DeepEqual(tn.LicenseTexts(), stub.LicenseTexts) {
+		t.Errorf("ReadLicenseGraph(stub): got %s %q, want %s %q", tn.PackageName(), tn.LicenseTexts(), stub.PackageName, stub.LicenseTexts)
+	}
+
+	container := MetaLicStub{
+		Name:        "image.meta_lic",
+		PackageName: "image",
+		IsContainer: true,
+		Deps:        []string{paths[0]},
+	}
+	expected = `package_name: "image"
+module_name: "image"
+is_container: true
+deps: {
+  file: "` + paths[0] + `"
+  annotations: "static"
+}
+`
+	if actual := container.String(); actual != expected {
+		t.Errorf("String(container): got %q, want %q", actual, expected)
+	}
+	containerPaths, err := WriteMetaLicStubs(dir, []MetaLicStub{container})
+	if err != nil {
+		t.Fatalf("WriteMetaLicStubs(container): got error %s, want no error", err)
+	}
+	lg, err = ReadLicenseGraph(FS, &bytes.Buffer{}, containerPaths)
+	if err != nil {
+		t.Fatalf("ReadLicenseGraph(container): got error %s, want no error", err)
+	}
+	if edges := lg.Edges(); len(edges) != 1 || !edges[0].Target().IsContainer() || edges[0].Dependency().PackageName() != "example.com/mod" {
+		t.Errorf("ReadLicenseGraph(container): got edges %s, want image -> example.com/mod", edges)
+	}
+}
+
+func TestIdentifyLicense(t *testing.T) {
+	mit, err := FetchLicenseText("MIT", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apache, err := FetchLicenseText("Apache-2.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name              string
+		text              string
+		expectedKind      string
+		expectedCondition string
+	}{
+		{"mit", strings.Replace(mit, "<year> <copyright holders>", "2026 The Fixture Authors", 1), "SPDX-license-identifier-MIT", "notice"},
+		{"apache", apache, "SPDX-license-identifier-Apache-2.0", "notice"},
+		{"unknown", "You may do anything with this software except sell it.\n", "legacy_by_exception_only", "by_exception_only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, condition := identifyLicense(tt.text, licenseMatchThreshold)
+			if kind != tt.expectedKind || condition != tt.expectedCondition {
+				t.Errorf("identifyLicense(): got %s %s, want %s %s", kind, condition, tt.expectedKind, tt.expectedCondition)
+			}
+		})
+	}
+}
+
+func TestWriteMetaLicStubsError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteMetaLicStubs(file, []MetaLicStub{{Name: "a.meta_lic"}}); err == nil {
+		t.Errorf("WriteMetaLicStubs(file): got no error, want error")
+	}
+}
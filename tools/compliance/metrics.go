@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Metrics describes the size of a license graph and the work done analyzing
+// it for export to monitoring systems.
+type Metrics struct {
+	// GraphNodes counts the target nodes in the graph.
+	GraphNodes int
+	// GraphEdges counts the edges in the graph.
+	GraphEdges int
+	// ParseDuration is the time spent reading the license metadata files.
+	ParseDuration time.Duration
+	// CacheLookups counts the targets visited by the resolve walks.
+	CacheLookups int
+	// CacheHits counts the visits answered by earlier walk results.
+	CacheHits int
+	// Violations counts the conflicts between source-sharing and source
+	// privacy conditions.
+	Violations int
+}
+
+// RecordMetrics returns the Metrics for `lg` read in duration `d`.
+//
+// Resolves the graph top-down when not already resolved.
+func RecordMetrics(lg *LicenseGraph, d time.Duration) Metrics {
+	violations := len(ConflictingSharedPrivateSource(lg))
+	return Metrics{
+		GraphNodes:    len(lg.targets),
+		GraphEdges:    len(lg.edges),
+		ParseDuration: d,
+		CacheLookups:  lg.walkLookups,
+		CacheHits:     lg.walkHits,
+		Violations:    violations,
+	}
+}
+
+// CacheHitRate returns the fraction of resolve walk visits answered by earlier
+// walk results or 0 when there were none.
+func (m Metrics) CacheHitRate() float64 {
+	if m.CacheLookups == 0 {
+		return 0
+	}
+	return float64(m.CacheHits) / float64(m.CacheLookups)
+}
+
+// WritePrometheus writes `m` to `w` in the Prometheus text exposition format.
+func (m Metrics) WritePrometheus(w io.Writer) error {
+	metrics := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"compliance_graph_nodes_total", "counter", "Number of target nodes in the license graph.", float64(m.GraphNodes)},
+		{"compliance_graph_edges_total", "counter", "Number of edges in the license graph.", float64(m.GraphEdges)},
+		{"compliance_parse_duration_seconds", "gauge", "Time spent reading license metadata files.", m.ParseDuration.Seconds()},
+		{"compliance_cache_lookups_total", "counter", "Number of targets visited by the resolve walks.", float64(m.CacheLookups)},
+		{"compliance_cache_hits_total", "counter", "Number of resolve walk visits answered by earlier walk results.", float64(m.CacheHits)},
+		{"compliance_cache_hit_ratio", "gauge", "Fraction of resolve walk visits answered by earlier walk results.", m.CacheHitRate()},
+		{"compliance_violations_total", "counter", "Number of conflicts between source-sharing and source privacy conditions.", float64(m.Violations)},
+	}
+	for _, metric := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"bin\"\n" +
+			"license_kinds: \"SPDX-license-identifier-GPL-2.0\"\n" +
+			"license_conditions: \"restricted\"\n" +
+			"deps: {\n  file: \"lib.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"util.meta_lic\"\n  annotations: \"static\"\n}\n")},
+		"lib.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"lib\"\n" +
+			"license_kinds: \"legacy_proprietary\"\n" +
+			"license_conditions: \"proprietary\"\n" +
+			"deps: {\n  file: \"util.meta_lic\"\n  annotations: \"static\"\n}\n")},
+		"util.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"util\"\n" +
+			"license_kinds: \"SPDX-license-identifier-MIT\"\n" +
+			"license_conditions: \"notice\"\n")},
+	}
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+
+	m := RecordMetrics(lg, 1500*time.Millisecond)
+	if m.GraphNodes != 3 {
+		t.Errorf("GraphNodes: got %d, want 3", m.GraphNodes)
+	}
+	if m.GraphEdges != 3 {
+		t.Errorf("GraphEdges: got %d, want 3", m.GraphEdges)
+	}
+	if m.ParseDuration != 1500*time.Millisecond {
+		t.Errorf("ParseDuration: got %s, want 1.5s", m.ParseDuration)
+	}
+	if m.CacheHits == 0 || m.CacheHits >= m.CacheLookups {
+		t.Errorf("CacheHits: got %d of %d lookups, want some but not all", m.CacheHits, m.CacheLookups)
+	}
+	if m.Violations != 1 {
+		t.Errorf("Violations: got %d, want 1", m.Violations)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus(): got error %s, want no error", err)
+	}
+	for _, line := range []string{
+		"# TYPE compliance_graph_nodes_total counter",
+		"compliance_graph_nodes_total 3",
+		"compliance_graph_edges_total 3",
+		"# TYPE compliance_parse_duration_seconds gauge",
+		"compliance_parse_duration_seconds 1.5",
+		"compliance_violations_total 1",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("WritePrometheus(): got %q, want line %q", buf.String(), line)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MissingText describes a shipped target that contributes no license text to
+// the notice, e.g. because its license metadata lists no license texts.
+type MissingText struct {
+	// Target names the license metadata file of the target.
+	Target string
+	// Conditions are the license conditions that would have attached the
+	// license texts of the target to the notice.
+	Conditions LicenseConditionSet
+	// InstallPaths lists, in order, the install paths that would have used
+	// the license texts of the target.
+	InstallPaths []string
+}
+
+// addMissing records that `tn` contributes no license text to `installPaths`
+// for `conditions`.
+func (ni *NoticeIndex) addMissing(tn *TargetNode, installPaths []string, conditions LicenseConditionSet) {
+	ni.missingConditions[tn] = ni.missingConditions[tn].Union(conditions)
+	if _, ok := ni.missingInstalls[tn]; !ok {
+		ni.missingInstalls[tn] = make(map[string]struct{})
+	}
+	for _, installPath := range installPaths {
+		ni.missingInstalls[tn][installPath] = struct{}{}
+	}
+}
+
+// MissingTexts returns the shipped targets contributing no license text to
+// the notice ordered by target name.
+func (ni *NoticeIndex) MissingTexts() []MissingText {
+	missing := make([]MissingText, 0, len(ni.missingConditions))
+	for tn, conditions := range ni.missingConditions {
+		installPaths := make([]string, 0, len(ni.missingInstalls[tn]))
+		for installPath := range ni.missingInstalls[tn] {
+			installPaths = append(installPaths, installPath)
+		}
+		sort.Strings(installPaths)
+		missing = append(missing, MissingText{tn.Name(), conditions, installPaths})
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Target < missing[j].Target })
+	return missing
+}
+
+// WriteMissingTexts writes `missing` to `w` one target per line with tabs
+// separating the target, the comma-separated conditions, and the
+// space-separated install paths after a header line starting with "#".
+func WriteMissingTexts(w io.Writer, missing []MissingText) error {
+	if _, err := fmt.Fprintln(w, "# target\tconditions\tinstall paths"); err != nil {
+		return err
+	}
+	for _, m := range missing {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%s\n", m.Target, strings.Join(m.Conditions.Names(), ","), strings.Join(m.InstallPaths, " "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MissingTextReport configures the `-report_missing` and `-missing_fatal`
+// flags shared by the notice tools.
+type MissingTextReport struct {
+	// File names where to write the targets contributing no license text or
+	// is empty.
+	File string
+	// Fatal makes Apply fail when any target contributes no license text.
+	Fatal bool
+}
+
+// Apply writes the targets of `ni` contributing no license text to `r.File`
+// when set, and then returns an error naming them when `r.Fatal` and there
+// are any.
+func (r *MissingTextReport) Apply(ni *NoticeIndex) error {
+	if r == nil {
+		return nil
+	}
+	missing := ni.MissingTexts()
+	if len(r.File) > 0 {
+		f, err := os.Create(r.File)
+		if err != nil {
+			return fmt.Errorf("could not write missing license text report to %q: %w", r.File, err)
+		}
+		err = WriteMissingTexts(f, missing)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("could not write missing license text report to %q: %w", r.File, err)
+		}
+	}
+	if r.Fatal && len(missing) > 0 {
+		targets := make([]string, 0, len(missing))
+		for _, m := range missing {
+			targets = append(targets, m.Target)
+		}
+		return fmt.Errorf("%d shipped target(s) contribute no license text: %s", len(missing), strings.Join(targets, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+// missingTextIndex returns the index of a binary with a license text that
+// statically links a vendor library without one.
+func missingTextIndex(t *testing.T) *NoticeIndex {
+	t.Helper()
+	rootFS := &testfs.TestFS{
+		"bin.meta_lic": []byte(AOSP + "installed: \"system/bin/bin\"\nlicense_texts: \"LICENSE\"\n" +
+			"deps: {\n  file: \"vendor.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"lib.meta_lic\"\n  annotations: \"static\"\n}\n"),
+		"vendor.meta_lic": []byte(Proprietary + "built: \"vendor.a\"\n"),
+		"lib.meta_lic":    []byte(MIT + "built: \"lib.a\"\nlicense_texts: \"LICENSE\"\n"),
+		"LICENSE":         []byte("Licensed.\n"),
+	}
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(rootFS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	return ni
+}
+
+func TestMissingTexts(t *testing.T) {
+	ni := missingTextIndex(t)
+	expected := []MissingText{{"vendor.meta_lic", NewLicenseConditionSet(ProprietaryCondition), []string{"system/bin/bin"}}}
+	if actual := ni.MissingTexts(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("MissingTexts(): got %+v, want %+v", actual, expected)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMissingTexts(&buf, ni.MissingTexts()); err != nil {
+		t.Fatalf("WriteMissingTexts(): got error %s, want no error", err)
+	}
+	if expected := "# target\tconditions\tinstall paths\nvendor.meta_lic\tproprietary\tsystem/bin/bin\n"; buf.String() != expected {
+		t.Errorf("WriteMissingTexts(): got %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestMissingTextReportApply(t *testing.T) {
+	ni := missingTextIndex(t)
+	report := filepath.Join(t.TempDir(), "missing.tsv")
+
+	if err := (&MissingTextReport{File: report}).Apply(ni); err != nil {
+		t.Fatalf("Apply(): got error %s, want no error", err)
+	}
+	data, err := os.ReadFile(report)
+	if err != nil || !strings.Contains(string(data), "vendor.meta_lic\tproprietary\tsystem/bin/bin\n") {
+		t.Errorf("Apply(): got report %q error %v, want vendor.meta_lic listed", data, err)
+	}
+
+	err = (&MissingTextReport{Fatal: true}).Apply(ni)
+	if err == nil || !strings.Contains(err.Error(), "vendor.meta_lic") {
+		t.Errorf("Apply(fatal): got error %v, want error naming vendor.meta_lic", err)
+	}
+	if err := (*MissingTextReport)(nil).Apply(ni); err != nil {
+		t.Errorf("Apply(nil): got error %s, want no error", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// ninjaDepsEscaper escapes the characters with special meaning in Ninja
+// depfiles, which use the make syntax.
+var ninjaDepsEscaper = strings.NewReplacer(" ", "\\ ", "#", "\\#", "$", "$$")
+
+// WriteDepsFile writes a Ninja depfile to `path` listing `inputs` as the
+// dependencies of the output named by `path` without its ".d" extension, e.g.
+// "NOTICE.txt" for "NOTICE.txt.d", per the Ninja convention `depfile = $out.d`.
+//
+// Lists each input once in sorted order so that the depfile only changes when
+// the inputs do.
+func WriteDepsFile(path string, inputs []string) error {
+	sorted := append([]string{}, inputs...)
+	sort.Strings(sorted)
+
+	var sb strings.Builder
+	sb.WriteString(ninjaDepsEscaper.Replace(strings.TrimSuffix(path, ".d")))
+	sb.WriteString(":")
+	for i, input := range sorted {
+		if i > 0 && input == sorted[i-1] {
+			continue
+		}
+		sb.WriteString(" \\\n  ")
+		sb.WriteString(ninjaDepsEscaper.Replace(input))
+	}
+	sb.WriteString("\n")
+	return os.WriteFile(path, []byte(sb.String()), 0666)
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteDepsFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		inputs   []string
+		expected string
+	}{
+		{"NOTICE.txt.d", nil, "NOTICE.txt:\n"},
+		{"NOTICE.txt.d", []string{"b.meta_lic", "a.meta_lic", "b.meta_lic"}, "NOTICE.txt: \\\n  a.meta_lic \\\n  b.meta_lic\n"},
+		{"my notice.txt.d", []string{"my lib/LICENSE", "$lib#1.meta_lic"}, "my\\ notice.txt: \\\n  $$lib\\#1.meta_lic \\\n  my\\ lib/LICENSE\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := WriteDepsFile(path, tt.inputs); err != nil {
+			t.Fatalf("WriteDepsFile(%q): got error %s, want no error", tt.inputs, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("WriteDepsFile(%q): cannot read depfile: %s", tt.inputs, err)
+		}
+		expected := ninjaDepsEscaper.Replace(filepath.Join(dir, "")) + "/" + tt.expected
+		if string(data) != expected {
+			t.Errorf("WriteDepsFile(%q): got %q, want %q", tt.inputs, data, expected)
+		}
+	}
+}
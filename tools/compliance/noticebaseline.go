@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// NoticeBaseline maps library names to the ordered hashes of their license
+// texts in the notice for a prior release.
+//
+// Stored as JSON, a baseline lets a later release generate a notice with only
+// the libraries added or changed since.
+type NoticeBaseline map[string][]string
+
+// ReadNoticeBaseline reads a baseline written by NoticeBaseline.Write.
+func ReadNoticeBaseline(r io.Reader) (NoticeBaseline, error) {
+	var b NoticeBaseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid notice baseline: %w", err)
+	}
+	if b == nil {
+		return nil, fmt.Errorf("invalid notice baseline: want object mapping library names to text hashes")
+	}
+	return b, nil
+}
+
+// Write writes the baseline to `w` as a JSON object ordered by library name.
+func (b NoticeBaseline) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// Baseline returns the hashes of the license texts for every library in the
+// index.
+func (ni *NoticeIndex) Baseline() NoticeBaseline {
+	b := make(NoticeBaseline)
+	for libName := range ni.libHash {
+		hashes := make([]string, 0, len(ni.libHash[libName]))
+		for h := range ni.libHash[libName] {
+			hashes = append(hashes, h.String())
+		}
+		sort.Strings(hashes)
+		b[libName] = hashes
+	}
+	return b
+}
+
+// RemoveBaseline removes from the index the license texts that libraries
+// already used in baseline `b` so that only new libraries and new or changed
+// texts remain.
+//
+// Returns the ordered names of the libraries in `b` that are no longer in the
+// index at all.
+func (ni *NoticeIndex) RemoveBaseline(b NoticeBaseline) []string {
+	removed := make([]string, 0)
+	for libName := range b {
+		if _, ok := ni.libHash[libName]; !ok {
+			removed = append(removed, libName)
+		}
+	}
+	sort.Strings(removed)
+
+	for libName, hashes := range b {
+		if _, ok := ni.libHash[libName]; !ok {
+			continue
+		}
+		for _, key := range hashes {
+			ni.removeLibHash(libName, Hash{key})
+		}
+	}
+	return removed
+}
+
+// removeLibHash removes every reference to library `libName` using the
+// license text hashed as `h`.
+func (ni *NoticeIndex) removeLibHash(libName string, h Hash) {
+	if _, ok := ni.libHash[libName][h]; !ok {
+		return
+	}
+	for installPath := range ni.hashLibInstall[h][libName] {
+		delete(ni.installHashLib[installPath][h], libName)
+		if len(ni.installHashLib[installPath][h]) == 0 {
+			delete(ni.installHashLib[installPath], h)
+		}
+		if len(ni.installHashLib[installPath]) == 0 {
+			delete(ni.installHashLib, installPath)
+		}
+		delete(ni.installHashLibConditions[installPath][h], libName)
+		if len(ni.installHashLibConditions[installPath][h]) == 0 {
+			delete(ni.installHashLibConditions[installPath], h)
+		}
+		if len(ni.installHashLibConditions[installPath]) == 0 {
+			delete(ni.installHashLibConditions, installPath)
+		}
+	}
+	delete(ni.hashLibInstall[h], libName)
+	if len(ni.hashLibInstall[h]) == 0 {
+		delete(ni.hashLibInstall, h)
+	}
+	delete(ni.hashLibKinds[h], libName)
+	if len(ni.hashLibKinds[h]) == 0 {
+		delete(ni.hashLibKinds, h)
+	}
+	delete(ni.libHash[libName], h)
+	if len(ni.libHash[libName]) == 0 {
+		delete(ni.libHash, libName)
+		delete(ni.libProjects, libName)
+	}
+}
+
+// NoticeDelta configures the `-baseline` and `-write_baseline` flags shared
+// by the notice tools.
+type NoticeDelta struct {
+	// Baseline names the file holding the baseline of a prior release or is
+	// empty to include every library.
+	Baseline string
+	// WriteBaseline names where to write the baseline for the next release
+	// or is empty.
+	WriteBaseline string
+}
+
+// Apply writes the baseline of `ni` to `d.WriteBaseline` when set, and then
+// removes the texts in `d.Baseline` from `ni` when set, listing the libraries
+// removed since the baseline on `stderr`.
+//
+// The baseline written always covers the full index so that each release
+// compares against the complete notice of the one before.
+func (d *NoticeDelta) Apply(ni *NoticeIndex, stderr io.Writer) error {
+	if d == nil {
+		return nil
+	}
+	if len(d.WriteBaseline) > 0 {
+		f, err := os.Create(d.WriteBaseline)
+		if err != nil {
+			return fmt.Errorf("could not write baseline to %q: %w", d.WriteBaseline, err)
+		}
+		err = ni.Baseline().Write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("could not write baseline to %q: %w", d.WriteBaseline, err)
+		}
+	}
+	if len(d.Baseline) > 0 {
+		f, err := os.Open(d.Baseline)
+		if err != nil {
+			return fmt.Errorf("could not read baseline %q: %w", d.Baseline, err)
+		}
+		b, err := ReadNoticeBaseline(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("could not read baseline %q: %w", d.Baseline, err)
+		}
+		for _, libName := range ni.RemoveBaseline(b) {
+			fmt.Fprintf(stderr, "removed since baseline: %s\n", libName)
+		}
+	}
+	return nil
+}
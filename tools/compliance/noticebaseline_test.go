@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// baselineTestIndex returns the notice index for the libraries in `libs`
+// mapping library names to license texts.
+func baselineTestIndex(t *testing.T, libs map[string]string) *NoticeIndex {
+	t.Helper()
+	rootFS := fstest.MapFS{}
+	var roots []string
+	for name, text := range libs {
+		rootFS[name+".txt"] = &fstest.MapFile{Data: []byte(text + "\n")}
+		rootFS[name+".meta_lic"] = &fstest.MapFile{Data: []byte(fmt.Sprintf("package_name: %q\nlicense_conditions: \"notice\"\nlicense_texts: \"%s.txt\"\ninstalled: \"system/lib/%s.so\"\n", name, name, name))}
+		roots = append(roots, name+".meta_lic")
+	}
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, roots)
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(rootFS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	return ni
+}
+
+// indexedLibraries returns the libraries and install paths in `ni`.
+func indexedLibraries(ni *NoticeIndex) ([]string, []string) {
+	libs := []string{}
+	for _, h := range ni.AllHashes() {
+		libs = append(libs, ni.HashLibs(h)...)
+	}
+	installPaths := []string{}
+	for _, installPath := range ni.AllInstallPaths() {
+		installPaths = append(installPaths, installPath)
+	}
+	return libs, installPaths
+}
+
+func TestRemoveBaseline(t *testing.T) {
+	prior := baselineTestIndex(t, map[string]string{
+		"Same":    "same text",
+		"Changed": "old text",
+		"Removed": "removed text",
+	}).Baseline()
+
+	tests := []struct {
+		name                 string
+		libs                 map[string]string
+		expectedLibs         []string
+		expectedInstallPaths []string
+		expectedRemoved      []string
+	}{
+		{
+			name:                 "unchanged",
+			libs:                 map[string]string{"Same": "same text", "Changed": "old text", "Removed": "removed text"},
+			expectedLibs:         []string{},
+			expectedInstallPaths: []string{},
+			expectedRemoved:      []string{},
+		},
+		{
+			name:                 "added",
+			libs:                 map[string]string{"Same": "same text", "Changed": "old text", "Removed": "removed text", "Added": "added text"},
+			expectedLibs:         []string{"Added"},
+			expectedInstallPaths: []string{"system/lib/Added.so"},
+			expectedRemoved:      []string{},
+		},
+		{
+			name:                 "changed",
+			libs:                 map[string]string{"Same": "same text", "Changed": "new text", "Removed": "removed text"},
+			expectedLibs:         []string{"Changed"},
+			expectedInstallPaths: []string{"system/lib/Changed.so"},
+			expectedRemoved:      []string{},
+		},
+		{
+			name:                 "removed",
+			libs:                 map[string]string{"Same": "same text", "Changed": "old text"},
+			expectedLibs:         []string{},
+			expectedInstallPaths: []string{},
+			expectedRemoved:      []string{"Removed"},
+		},
+		{
+			name:                 "sharedtext",
+			libs:                 map[string]string{"Same": "same text", "Changed": "old text", "Added": "same text"},
+			expectedLibs:         []string{"Added"},
+			expectedInstallPaths: []string{"system/lib/Added.so"},
+			expectedRemoved:      []string{"Removed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ni := baselineTestIndex(t, tt.libs)
+			full := ni.Baseline()
+			removed := ni.RemoveBaseline(prior)
+			if !reflect.DeepEqual(removed, tt.expectedRemoved) {
+				t.Errorf("unexpected removed libraries: got %q, want %q", removed, tt.expectedRemoved)
+			}
+			libs, installPaths := indexedLibraries(ni)
+			if !reflect.DeepEqual(libs, tt.expectedLibs) {
+				t.Errorf("unexpected libraries: got %q, want %q", libs, tt.expectedLibs)
+			}
+			if !reflect.DeepEqual(installPaths, tt.expectedInstallPaths) {
+				t.Errorf("unexpected install paths: got %q, want %q", installPaths, tt.expectedInstallPaths)
+			}
+			if len(full) != len(tt.libs) {
+				t.Errorf("unexpected baseline: got %d libraries, want %d", len(full), len(tt.libs))
+			}
+		})
+	}
+}
+
+func TestNoticeDeltaApply(t *testing.T) {
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "baseline.json")
+
+	prior := baselineTestIndex(t, map[string]string{"Same": "same text", "Removed": "removed text"})
+	stderr := &bytes.Buffer{}
+	if err := (&NoticeDelta{WriteBaseline: baseline}).Apply(prior, stderr); err != nil {
+		t.Fatalf("Apply(): got error %s, want no error", err)
+	}
+	f, err := os.Open(baseline)
+	if err != nil {
+		t.Fatalf("could not open baseline: %s", err)
+	}
+	b, err := ReadNoticeBaseline(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("ReadNoticeBaseline(): got error %s, want no error", err)
+	}
+	if !reflect.DeepEqual(b, prior.Baseline()) {
+		t.Errorf("unexpected baseline: got %v, want %v", b, prior.Baseline())
+	}
+
+	next := filepath.Join(dir, "next.json")
+	ni := baselineTestIndex(t, map[string]string{"Same": "same text", "Added": "added text"})
+	if err := (&NoticeDelta{baseline, next}).Apply(ni, stderr); err != nil {
+		t.Fatalf("Apply(): got error %s, want no error", err)
+	}
+	if actual := stderr.String(); actual != "removed since baseline: Removed\n" {
+		t.Errorf("unexpected stderr: got %q, want removed Removed", actual)
+	}
+	if libs, _ := indexedLibraries(ni); !reflect.DeepEqual(libs, []string{"Added"}) {
+		t.Errorf("unexpected libraries: got %q, want [\"Added\"]", libs)
+	}
+	data, err := os.ReadFile(next)
+	if err != nil {
+		t.Fatalf("could not read next baseline: %s", err)
+	}
+	if !strings.Contains(string(data), "\"Same\"") || !strings.Contains(string(data), "\"Added\"") {
+		t.Errorf("unexpected next baseline: got %s, want Same and Added", string(data))
+	}
+	if err := (*NoticeDelta)(nil).Apply(ni, stderr); err != nil {
+		t.Errorf("nil Apply(): got error %s, want no error", err)
+	}
+}
+
+func TestReadNoticeBaselineErrors(t *testing.T) {
+	for _, input := range []string{"", "[]", "null", "{\"lib\": \"hash\"}"} {
+		if _, err := ReadNoticeBaseline(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadNoticeBaseline(%q): got no error, want error", input)
+		}
+	}
+	if err := (&NoticeDelta{Baseline: filepath.Join(t.TempDir(), "missing.json")}).Apply(nil, &bytes.Buffer{}); err == nil {
+		t.Errorf("Apply() with missing baseline: got no error, want error")
+	}
+}
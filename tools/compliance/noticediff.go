@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DiffType identifies how a library differs between two notices.
+type DiffType string
+
+const (
+	// DiffAdded identifies a library only in the new notice.
+	DiffAdded = DiffType("added")
+
+	// DiffRemoved identifies a library only in the old notice.
+	DiffRemoved = DiffType("removed")
+
+	// DiffChanged identifies a library in both notices with different
+	// license conditions or license texts.
+	DiffChanged = DiffType("changed")
+)
+
+// DiffChunk describes how a library differs between two notices.
+//
+// A library may have several license conditions, so the conditions before
+// and after are sets. ConditionBefore is empty for added libraries and
+// ConditionAfter is empty for removed libraries.
+type DiffChunk struct {
+	Type            DiffType
+	LibraryName     string
+	ConditionBefore LicenseConditionSet
+	ConditionAfter  LicenseConditionSet
+}
+
+// String returns a human-readable description of the chunk.
+//
+// e.g. "changed libfoo: notice -> restricted"
+func (c DiffChunk) String() string {
+	switch c.Type {
+	case DiffAdded:
+		return fmt.Sprintf("added %s: %s", c.LibraryName, conditionNames(c.ConditionAfter))
+	case DiffRemoved:
+		return fmt.Sprintf("removed %s: %s", c.LibraryName, conditionNames(c.ConditionBefore))
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", c.Type, c.LibraryName, conditionNames(c.ConditionBefore), conditionNames(c.ConditionAfter))
+}
+
+// conditionNames returns the names of the conditions in `cs` joined by "|".
+func conditionNames(cs LicenseConditionSet) string {
+	return strings.Join(cs.Names(), "|")
+}
+
+// noticeLibrary describes a library read from a notice.
+type noticeLibrary struct {
+	// conditions is the union of the license conditions of the library.
+	conditions LicenseConditionSet
+	// contentIDs identifies the license texts of the library.
+	contentIDs map[string]struct{}
+}
+
+// DiffNotice compares the xml notices read from `oldOut` and `newOut` library
+// by library, and returns the libraries added, removed, or changed ordered by
+// library name.
+//
+// Reads the notices written by xmlnotice in either the default or -by_target
+// form. A library changes when its license conditions or the license texts
+// referenced for it differ. Install paths do not matter.
+func DiffNotice(oldOut, newOut io.Reader) ([]DiffChunk, error) {
+	before, err := readNoticeLibraries(oldOut)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read old notice: %w", err)
+	}
+	after, err := readNoticeLibraries(newOut)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read new notice: %w", err)
+	}
+
+	var result []DiffChunk
+	for name, b := range before {
+		a, ok := after[name]
+		if !ok {
+			result = append(result, DiffChunk{DiffRemoved, name, b.conditions, NewLicenseConditionSet()})
+			continue
+		}
+		if a.conditions != b.conditions || !sameKeys(a.contentIDs, b.contentIDs) {
+			result = append(result, DiffChunk{DiffChanged, name, b.conditions, a.conditions})
+		}
+	}
+	for name, a := range after {
+		if _, ok := before[name]; !ok {
+			result = append(result, DiffChunk{DiffAdded, name, NewLicenseConditionSet(), a.conditions})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LibraryName < result[j].LibraryName
+	})
+	return result, nil
+}
+
+// readNoticeLibraries reads the libraries from the xml notice in `r`.
+func readNoticeLibraries(r io.Reader) (map[string]*noticeLibrary, error) {
+	libs := make(map[string]*noticeLibrary)
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return libs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || (start.Name.Local != "file-name" && start.Name.Local != "license") {
+			continue
+		}
+		var name, contentID, conditions string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "lib":
+				name = attr.Value
+			case "contentId":
+				contentID = attr.Value
+			case "condition":
+				conditions = attr.Value
+			}
+		}
+		lib, ok := libs[name]
+		if !ok {
+			lib = &noticeLibrary{NewLicenseConditionSet(), make(map[string]struct{})}
+			libs[name] = lib
+		}
+		lib.contentIDs[contentID] = struct{}{}
+		if len(conditions) == 0 {
+			continue
+		}
+		for _, c := range strings.Split(conditions, ",") {
+			lc, ok := Conditions.Condition(c)
+			if !ok {
+				return nil, fmt.Errorf("unknown license condition %q for library %q", c, name)
+			}
+			lib.conditions = lib.conditions.Plus(lc)
+		}
+	}
+}
+
+// sameKeys returns true when `a` and `b` have the same keys.
+func sameKeys(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
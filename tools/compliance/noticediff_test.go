@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// diffTestNotice returns an xml notice in the form xmlnotice writes for the
+// libraries in `libs` mapping library names to license conditions.
+func diffTestNotice(t *testing.T, libs map[string]string) *bytes.Buffer {
+	rootFS := fstest.MapFS{
+		"NOTICE":     &fstest.MapFile{Data: []byte("notice text\n")},
+		"RESTRICTED": &fstest.MapFile{Data: []byte("restricted text\n")},
+	}
+	var roots []string
+	for name, condition := range libs {
+		text := "NOTICE"
+		if condition == "restricted" {
+			text = "RESTRICTED"
+		}
+		rootFS[name+".meta_lic"] = &fstest.MapFile{Data: []byte(fmt.Sprintf("package_name: %q\nlicense_conditions: %q\nlicense_texts: %q\ninstalled: \"system/lib/%s.so\"\n", name, condition, text, name))}
+		roots = append(roots, name+".meta_lic")
+	}
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, roots)
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(rootFS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "<?xml version=\"1.0\" encoding=\"utf-8\"?>")
+	fmt.Fprintln(buf, "<licenses>")
+	for _, installPath := range ni.AllInstallPaths() {
+		for _, h := range ni.InstallHashes(installPath) {
+			for _, lib := range ni.InstallHashLibs(installPath, h) {
+				conditions := ni.InstallHashLibConditions(installPath, h, lib).Names()
+				sort.Strings(conditions)
+				fmt.Fprintf(buf, "<file-name contentId=\"%s\" lib=\"", h)
+				xml.EscapeText(buf, []byte(lib))
+				fmt.Fprintf(buf, "\" condition=\"%s\">%s</file-name>\n", strings.Join(conditions, ","), installPath)
+			}
+		}
+	}
+	for _, h := range ni.AllHashes() {
+		fmt.Fprintf(buf, "<file-content contentId=\"%s\">", h)
+		xml.EscapeText(buf, ni.TextContent(h))
+		fmt.Fprintln(buf, "</file-content>")
+	}
+	fmt.Fprintln(buf, "</licenses>")
+	return buf
+}
+
+func TestDiffNotice(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   map[string]string
+		after    map[string]string
+		expected []string
+	}{
+		{
+			name:   "same",
+			before: map[string]string{"liba": "notice", "libb": "notice"},
+			after:  map[string]string{"liba": "notice", "libb": "notice"},
+		},
+		{
+			name:     "added",
+			before:   map[string]string{"liba": "notice"},
+			after:    map[string]string{"liba": "notice", "libb": "notice"},
+			expected: []string{"added libb: notice"},
+		},
+		{
+			name:     "removed",
+			before:   map[string]string{"liba": "notice", "libb": "notice"},
+			after:    map[string]string{"libb": "notice"},
+			expected: []string{"removed liba: notice"},
+		},
+		{
+			name:     "changed",
+			before:   map[string]string{"liba": "notice", "libb": "notice"},
+			after:    map[string]string{"liba": "notice", "libb": "restricted"},
+			expected: []string{"changed libb: notice -> restricted"},
+		},
+		{
+			name:     "replaced",
+			before:   map[string]string{"liba": "notice", "libb": "notice"},
+			after:    map[string]string{"liba": "notice", "libc": "restricted"},
+			expected: []string{"removed libb: notice", "added libc: restricted"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := DiffNotice(diffTestNotice(t, tt.before), diffTestNotice(t, tt.after))
+			if err != nil {
+				t.Fatalf("DiffNotice(): got error %s, want no error", err)
+			}
+			var actual []string
+			for _, c := range chunks {
+				actual = append(actual, c.String())
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("DiffNotice(): got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffNoticeChunk(t *testing.T) {
+	chunks, err := DiffNotice(
+		diffTestNotice(t, map[string]string{"liba": "notice"}),
+		diffTestNotice(t, map[string]string{"liba": "restricted"}))
+	if err != nil {
+		t.Fatalf("DiffNotice(): got error %s, want no error", err)
+	}
+	expected := []DiffChunk{{DiffChanged, "liba", NewLicenseConditionSet(NoticeCondition), NewLicenseConditionSet(RestrictedCondition)}}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("DiffNotice(): got %v, want %v", chunks, expected)
+	}
+}
+
+func TestDiffNoticeErrors(t *testing.T) {
+	valid := `<licenses><file-name contentId="1" lib="liba" condition="notice">a</file-name></licenses>`
+	tests := []struct {
+		name     string
+		old, new string
+	}{
+		{"malformed", `<licenses><file-name>`, valid},
+		{"unknown condition", valid, `<licenses><file-name contentId="1" lib="liba" condition="bogus">a</file-name></licenses>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DiffNotice(strings.NewReader(tt.old), strings.NewReader(tt.new)); err == nil {
+				t.Errorf("DiffNotice(): got no error, want error")
+			}
+		})
+	}
+}
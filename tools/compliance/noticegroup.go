@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"sort"
+	"strings"
+)
+
+// NoticeGroup is a section of a notice: a license text and the libraries
+// using it.
+type NoticeGroup struct {
+	// Hash identifies the text in the NoticeIndex.
+	Hash string
+	// Text is the license text.
+	Text []byte
+	// Libs lists the libraries using the text ordered by name.
+	Libs []NoticeGroupLib
+	// Conditions are the license conditions attaching the text to the
+	// install paths of the libraries.
+	Conditions LicenseConditionSet
+}
+
+// NoticeGroupLib is a library in a NoticeGroup and the install paths using
+// it.
+type NoticeGroupLib struct {
+	Name string
+	// InstallPaths lists the install paths using the library. (sorted; may
+	// be shared with the NoticeIndex, so do not modify)
+	InstallPaths []string
+}
+
+// Groups returns a NoticeGroup for each license text in the order of
+// AllHashes.
+func (ni *NoticeIndex) Groups() []NoticeGroup {
+	var groups []NoticeGroup
+	for _, h := range ni.AllHashes() {
+		group := NoticeGroup{Hash: h.String(), Text: ni.TextContent(h)}
+		for _, libName := range ni.HashLibs(h) {
+			installPaths := ni.SortedInstallPaths(h, libName)
+			group.Libs = append(group.Libs, NoticeGroupLib{libName, installPaths})
+			for _, installPath := range installPaths {
+				group.Conditions = group.Conditions.Union(ni.InstallHashLibConditions(installPath, h, libName))
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// AggregateByText merges the groups in `groups` whose texts are identical
+// apart from whitespace into a single group listing the libraries and
+// install paths and the conditions of all of them.
+//
+// Each merged group takes the place, hash and text of its first member.
+// Does not modify `groups`.
+func AggregateByText(groups []NoticeGroup) []NoticeGroup {
+	result := make([]NoticeGroup, 0, len(groups))
+	// libs maps the result index to library names to install paths.
+	libs := make(map[int]map[string]map[string]struct{})
+	index := make(map[string]int)
+	for _, group := range groups {
+		key := strings.Join(strings.Fields(string(group.Text)), " ")
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, NoticeGroup{Hash: group.Hash, Text: group.Text})
+			libs[i] = make(map[string]map[string]struct{})
+		}
+		result[i].Conditions = result[i].Conditions.Union(group.Conditions)
+		for _, lib := range group.Libs {
+			if _, ok := libs[i][lib.Name]; !ok {
+				libs[i][lib.Name] = make(map[string]struct{})
+			}
+			for _, installPath := range lib.InstallPaths {
+				libs[i][lib.Name][installPath] = struct{}{}
+			}
+		}
+	}
+	for i := range result {
+		for libName, installPaths := range libs[i] {
+			lib := NoticeGroupLib{Name: libName, InstallPaths: make([]string, 0, len(installPaths))}
+			for installPath := range installPaths {
+				lib.InstallPaths = append(lib.InstallPaths, installPath)
+			}
+			sort.Strings(lib.InstallPaths)
+			result[i].Libs = append(result[i].Libs, lib)
+		}
+		sort.Slice(result[i].Libs, func(a, b int) bool { return result[i].Libs[a].Name < result[i].Libs[b].Name })
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateByText(t *testing.T) {
+	apache := []byte("Licensed under the Apache License,\nVersion 2.0 (the \"License\");\n")
+	apacheReflowed := []byte("Licensed under the Apache License, Version 2.0\n  (the \"License\");")
+	mit := []byte("Permission is hereby granted, free of charge,\nto any person.\n")
+	notice := NewLicenseConditionSet(NoticeCondition)
+	restricted := NewLicenseConditionSet(RestrictedCondition)
+
+	tests := []struct {
+		name     string
+		groups   []NoticeGroup
+		expected []NoticeGroup
+	}{
+		{
+			name:     "empty",
+			groups:   nil,
+			expected: []NoticeGroup{},
+		},
+		{
+			name: "identical texts",
+			groups: []NoticeGroup{
+				{"h1", apache, []NoticeGroupLib{{"libb", []string{"system/lib/libb.so"}}}, notice},
+				{"h2", apacheReflowed, []NoticeGroupLib{{"liba", []string{"system/lib/liba.so"}}}, restricted},
+			},
+			expected: []NoticeGroup{
+				{"h1", apache, []NoticeGroupLib{{"liba", []string{"system/lib/liba.so"}}, {"libb", []string{"system/lib/libb.so"}}}, notice.Union(restricted)},
+			},
+		},
+		{
+			name: "different texts",
+			groups: []NoticeGroup{
+				{"h1", apache, []NoticeGroupLib{{"liba", []string{"system/lib/liba.so"}}}, notice},
+				{"h2", mit, []NoticeGroupLib{{"libb", []string{"system/lib/libb.so"}}}, notice},
+			},
+			expected: []NoticeGroup{
+				{"h1", apache, []NoticeGroupLib{{"liba", []string{"system/lib/liba.so"}}}, notice},
+				{"h2", mit, []NoticeGroupLib{{"libb", []string{"system/lib/libb.so"}}}, notice},
+			},
+		},
+		{
+			name: "shared library",
+			groups: []NoticeGroup{
+				{"h1", apache, []NoticeGroupLib{{"liba", []string{"system/lib/liba.so", "vendor/lib/liba.so"}}}, notice},
+				{"h2", mit, []NoticeGroupLib{{"libc", []string{"system/lib/libc.so"}}}, notice},
+				{"h3", apacheReflowed, []NoticeGroupLib{{"liba", []string{"system/bin/bin1", "system/lib/liba.so"}}}, notice},
+			},
+			expected: []NoticeGroup{
+				{"h1", apache, []NoticeGroupLib{{"liba", []string{"system/bin/bin1", "system/lib/liba.so", "vendor/lib/liba.so"}}}, notice},
+				{"h2", mit, []NoticeGroupLib{{"libc", []string{"system/lib/libc.so"}}}, notice},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := fmt.Sprintf("%v", tt.groups)
+			actual := AggregateByText(tt.groups)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("AggregateByText: got %v, want %v", actual, tt.expected)
+			}
+			if after := fmt.Sprintf("%v", tt.groups); after != before {
+				t.Errorf("AggregateByText: modified groups to %s, want %s", after, before)
+			}
+		})
+	}
+}
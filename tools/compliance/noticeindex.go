@@ -15,25 +15,39 @@
 package compliance
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"android/soong/tools/compliance/projectmetadata"
 )
 
 var (
 	licensesPathRegexp = regexp.MustCompile(`licen[cs]es?/`)
+
+	// ErrUnknownHash is wrapped by the error HashText returns for a hash
+	// not in the index.
+	ErrUnknownHash = errors.New("unknown license text hash")
 )
 
 // NoticeIndex transforms license metadata into license text hashes, library
 // names, and install paths indexing them for fast lookup/iteration.
+//
+// The methods return ordered slices so that output built from them is
+// deterministic: AllHashes lists the license texts, HashLibs lists the
+// libraries using a text, HashLibInstalls lists where a library using a text
+// installs, and HashText returns a text as the notices write it.
 type NoticeIndex struct {
 	// lg identifies the license graph to which the index applies.
 	lg *LicenseGraph
@@ -46,50 +60,190 @@ type NoticeIndex struct {
 	// rootFS locates the root of the file system from which to read the files.
 	rootFS fs.FS
 	// hash maps license text filenames to content hashes
-	hash map[string]hash
+	hash map[string]Hash
 	// text maps content hashes to content
-	text map[hash][]byte
+	text map[Hash][]byte
+	// normalized caches the HashText result for content hashes. (guarded by
+	// mu)
+	normalized map[Hash][]byte
+	// mu guards normalized against concurrent HashText calls.
+	mu sync.Mutex
 	// hashLibInstall maps hashes to libraries to install paths.
-	hashLibInstall map[hash]map[string]map[string]struct{}
+	hashLibInstall map[Hash]map[string]map[string]struct{}
+	// sortedInstalls caches the SortedInstallPaths results for hashes and
+	// libraries. (guarded by installMu)
+	sortedInstalls map[Hash]map[string][]string
+	// installMu guards sortedInstalls against concurrent calls.
+	installMu sync.Mutex
 	// installHashLib maps install paths to libraries to hashes.
-	installHashLib map[string]map[hash]map[string]struct{}
+	installHashLib map[string]map[Hash]map[string]struct{}
 	// libHash maps libraries to hashes.
-	libHash map[string]map[hash]struct{}
+	libHash map[string]map[Hash]struct{}
+	// hashLibKinds maps hashes to libraries to license kinds.
+	hashLibKinds map[Hash]map[string]map[string]struct{}
 	// targetHash maps target nodes to hashes.
-	targetHashes map[*TargetNode]map[hash]struct{}
+	targetHashes map[*TargetNode]map[Hash]struct{}
+	// installHashLibConditions maps install paths to hashes to library
+	// names to the license conditions attaching the texts to the paths.
+	installHashLibConditions map[string]map[Hash]map[string]LicenseConditionSet
+	// libProjects maps library names to the projects of their targets.
+	libProjects map[string]map[string]struct{}
 	// projectName maps project directory names to project name text.
 	projectName map[string]string
+	// libVersion maps library names to the version the METADATA of all of
+	// their projects agree on.
+	libVersion map[string]string
+	// versionConflicts maps library names to versions to projects for the
+	// libraries whose projects' METADATA disagree on the version.
+	versionConflicts map[string]map[string][]string
+	// missingConditions maps the shipped target nodes contributing no
+	// license text to the conditions that would have attached their texts.
+	missingConditions map[*TargetNode]LicenseConditionSet
+	// missingInstalls maps the shipped target nodes contributing no license
+	// text to the install paths that would have used their texts.
+	missingInstalls map[*TargetNode]map[string]struct{}
 	// files lists all the files accessed during indexing
 	files []string
+	// useSpdxTexts enables falling back to embedded SPDX license texts.
+	useSpdxTexts bool
+	// spdxCache caches embedded SPDX license texts when not nil.
+	spdxCache LicenseCache
+	// pathTransform maps the install paths computed from the license graph
+	// to the install paths recorded in the index.
+	pathTransform PathTransform
+	// hashed maps the license text filenames read and hashed in parallel
+	// before indexing to the results.
+	hashed map[string]*hashedText
+	// hashCache caches the hashes of the license text files between runs
+	// or is nil.
+	hashCache *HashCache
+	// libraryNames overrides the derived library names or is nil.
+	libraryNames *LibraryNames
+	// emptyText selects how to index empty license texts.
+	emptyText EmptyTextMode
+	// emptyFiles is the set of license text files that are empty or hold
+	// only whitespace.
+	emptyFiles map[string]struct{}
+	// emptyTexts is the set of empty license text files of shipped targets.
+	emptyTexts map[EmptyText]struct{}
+	// textMu guards text and unread once indexing finishes.
+	textMu sync.Mutex
+	// unread maps the hashes of texts found in hashCache, which have not
+	// been read yet, to a file to read them from.
+	unread map[Hash]unreadText
+}
+
+// unreadText locates the content of a license text not read yet.
+type unreadText struct {
+	file string
+	size int
+}
+
+// hashedText is the result of reading and hashing a license text file.
+type hashedText struct {
+	// resolved names the file read after following any symbolic links.
+	resolved string
+	// text is the content or nil when the hash came from the hash cache.
+	text []byte
+	// size is the length of the content.
+	size int
+	hash Hash
+	// blank is true when the content is empty or only whitespace.
+	blank bool
+	err   error
+}
+
+// IndexOptions configures how IndexLicenseTextsWithOptions indexes the
+// license texts.
+type IndexOptions struct {
+	// SpdxFallback makes targets without any license text files use the
+	// embedded SPDX license text for each of their SPDX identifiers per
+	// FetchLicenseText. Identifiers not in the embedded license list get
+	// skipped.
+	SpdxFallback bool
+	// Cache caches the embedded SPDX license texts when not nil.
+	Cache LicenseCache
+	// PathTransform maps each install path before indexing, or drops it, or
+	// is nil to use DefaultPathTransform.
+	PathTransform PathTransform
+	// HashWorkers limits how many license text files get read and hashed in
+	// parallel, or is 0 for runtime.GOMAXPROCS(0).
+	HashWorkers int
+	// HashCache supplies the hashes of the license text files unchanged
+	// since an earlier run, and records the rest, or is nil to read every
+	// file.
+	HashCache *HashCache
+	// LibraryNames overrides the library names derived for the targets it
+	// matches, or is nil to derive every name.
+	LibraryNames *LibraryNames
+	// EmptyText selects how to index license text files that are empty or
+	// hold only whitespace. NoticeIndex.EmptyTexts lists them either way.
+	EmptyText EmptyTextMode
 }
 
 // IndexLicenseTexts creates a hashed index of license texts for `lg` and `rs`
 // using the files rooted at `rootFS`.
 func IndexLicenseTexts(rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet) (*NoticeIndex, error) {
+	return IndexLicenseTextsWithOptions(context.Background(), rootFS, lg, rs, IndexOptions{})
+}
+
+// IndexLicenseTextsWithOptions creates a hashed index of license texts like
+// IndexLicenseTexts configured by `opts` until `ctx` is done.
+//
+// Returns `ctx.Err()` when `ctx` is done before indexing finishes.
+//
+// An install path dropped by `opts.PathTransform` does not appear in the
+// index, and a target with install paths all dropped contributes no license
+// texts.
+func IndexLicenseTextsWithOptions(ctx context.Context, rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet, opts IndexOptions) (*NoticeIndex, error) {
 	if rs == nil {
 		rs = ResolveNotices(lg)
 	}
+	if opts.PathTransform == nil {
+		opts.PathTransform = DefaultPathTransform
+	}
+	if opts.HashWorkers < 0 {
+		return nil, fmt.Errorf("need at least one task in pool")
+	}
+	if opts.HashWorkers == 0 {
+		opts.HashWorkers = runtime.GOMAXPROCS(0)
+	}
 	ni := &NoticeIndex{
 		lg:             lg,
 		pmix:           projectmetadata.NewIndex(rootFS),
 		rs:             rs,
 		shipped:        ShippedNodes(lg),
 		rootFS:         rootFS,
-		hash:           make(map[string]hash),
-		text:           make(map[hash][]byte),
-		hashLibInstall: make(map[hash]map[string]map[string]struct{}),
-		installHashLib: make(map[string]map[hash]map[string]struct{}),
-		libHash:        make(map[string]map[hash]struct{}),
-		targetHashes:   make(map[*TargetNode]map[hash]struct{}),
+		hash:           make(map[string]Hash),
+		text:           make(map[Hash][]byte),
+		normalized:     make(map[Hash][]byte),
+		hashLibInstall: make(map[Hash]map[string]map[string]struct{}),
+		installHashLib: make(map[string]map[Hash]map[string]struct{}),
+		libHash:        make(map[string]map[Hash]struct{}),
+		hashLibKinds:   make(map[Hash]map[string]map[string]struct{}),
+		targetHashes:   make(map[*TargetNode]map[Hash]struct{}),
+		libProjects:    make(map[string]map[string]struct{}),
 		projectName:    make(map[string]string),
+		useSpdxTexts:   opts.SpdxFallback,
+		spdxCache:      opts.Cache,
+		pathTransform:  opts.PathTransform,
+		hashCache:      opts.HashCache,
+		libraryNames:   opts.LibraryNames,
+		emptyText:      opts.EmptyText,
+		emptyFiles:     make(map[string]struct{}),
+		emptyTexts:     make(map[EmptyText]struct{}),
+		unread:         make(map[Hash]unreadText),
+
+		missingConditions: make(map[*TargetNode]LicenseConditionSet),
+		missingInstalls:   make(map[*TargetNode]map[string]struct{}),
 	}
 
 	// index adds all license texts for `tn` to the index.
-	index := func(tn *TargetNode) (map[hash]struct{}, error) {
+	index := func(tn *TargetNode) (map[Hash]struct{}, error) {
 		if hashes, ok := ni.targetHashes[tn]; ok {
 			return hashes, nil
 		}
-		hashes := make(map[hash]struct{})
+		hashes := make(map[Hash]struct{})
 		for _, text := range tn.LicenseTexts() {
 			fname := strings.SplitN(text, ":", 2)[0]
 			if _, ok := ni.hash[fname]; !ok {
@@ -98,23 +252,41 @@ func IndexLicenseTexts(rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet) (*Notic
 					return nil, err
 				}
 			}
+			if _, ok := ni.emptyFiles[fname]; ok {
+				ni.addEmptyText(tn, fname)
+				if ni.emptyText == EmptyTextSkip {
+					continue
+				}
+			}
 			hash := ni.hash[fname]
 			if _, ok := hashes[hash]; !ok {
 				hashes[hash] = struct{}{}
 			}
 		}
+		if len(hashes) == 0 && ni.useSpdxTexts {
+			for _, id := range spdxLicenseIDs(tn) {
+				hash, ok := ni.addSpdxText(id)
+				if ok {
+					hashes[hash] = struct{}{}
+				}
+			}
+		}
 		ni.targetHashes[tn] = hashes
 		return hashes, nil
 	}
 
-	link := func(tn *TargetNode, hashes map[hash]struct{}, installPaths []string) error {
+	link := func(tn *TargetNode, hashes map[Hash]struct{}, installPaths []string, conditions LicenseConditionSet) error {
+		if len(hashes) == 0 {
+			ni.addMissing(tn, installPaths, conditions)
+			return nil
+		}
 		for h := range hashes {
 			libName, err := ni.getLibName(tn, h)
 			if err != nil {
 				return err
 			}
 			if _, ok := ni.libHash[libName]; !ok {
-				ni.libHash[libName] = make(map[hash]struct{})
+				ni.libHash[libName] = make(map[Hash]struct{})
 			}
 			if _, ok := ni.hashLibInstall[h]; !ok {
 				ni.hashLibInstall[h] = make(map[string]map[string]struct{})
@@ -122,9 +294,25 @@ func IndexLicenseTexts(rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet) (*Notic
 			if _, ok := ni.libHash[libName][h]; !ok {
 				ni.libHash[libName][h] = struct{}{}
 			}
+			if _, ok := ni.hashLibKinds[h]; !ok {
+				ni.hashLibKinds[h] = make(map[string]map[string]struct{})
+			}
+			if _, ok := ni.hashLibKinds[h][libName]; !ok {
+				ni.hashLibKinds[h][libName] = make(map[string]struct{})
+			}
+			for _, kind := range tn.LicenseKinds() {
+				ni.hashLibKinds[h][libName][kind] = struct{}{}
+			}
+			if _, ok := ni.libProjects[libName]; !ok {
+				ni.libProjects[libName] = make(map[string]struct{})
+			}
+			for _, p := range tn.Projects() {
+				ni.libProjects[libName][p] = struct{}{}
+			}
 			for _, installPath := range installPaths {
+				ni.addConditions(installPath, h, libName, conditions)
 				if _, ok := ni.installHashLib[installPath]; !ok {
-					ni.installHashLib[installPath] = make(map[hash]map[string]struct{})
+					ni.installHashLib[installPath] = make(map[Hash]map[string]struct{})
 					ni.installHashLib[installPath][h] = make(map[string]struct{})
 					ni.installHashLib[installPath][h][libName] = struct{}{}
 				} else if _, ok = ni.installHashLib[installPath][h]; !ok {
@@ -148,6 +336,11 @@ func IndexLicenseTexts(rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet) (*Notic
 		return nil
 	}
 
+	// Read and hash the license texts in parallel up front. The walk below
+	// still indexes them in walk order so the index does not depend on the
+	// order the reads finish.
+	ni.hashTexts(ctx, opts.HashWorkers)
+
 	cacheMetadata := func(tn *TargetNode) {
 		ni.pmix.MetadataForProjects(tn.Projects()...)
 	}
@@ -159,17 +352,28 @@ func IndexLicenseTexts(rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet) (*Notic
 		if err != nil {
 			return false
 		}
+		if err = ctx.Err(); err != nil {
+			return false
+		}
 		if !ni.shipped.Contains(tn) {
 			return false
 		}
 		go cacheMetadata(tn)
 		installPaths := getInstallPaths(tn, path)
-		var hashes map[hash]struct{}
+		if len(installPaths) > 0 {
+			installPaths = ni.transformPaths(installPaths)
+			if len(installPaths) == 0 {
+				// Every install path dropped: skip the target and its
+				// resolutions but not the contents of a container.
+				return tn.IsContainer()
+			}
+		}
+		var hashes map[Hash]struct{}
 		hashes, err = index(tn)
 		if err != nil {
 			return false
 		}
-		err = link(tn, hashes, installPaths)
+		err = link(tn, hashes, installPaths, tn.LicenseConditions())
 		if err != nil {
 			return false
 		}
@@ -182,51 +386,202 @@ func IndexLicenseTexts(rootFS fs.FS, lg *LicenseGraph, rs ResolutionSet) (*Notic
 			if err != nil {
 				return false
 			}
-			err = link(r.actsOn, hashes, installPaths)
+			err = link(r.actsOn, hashes, installPaths, r.Resolves())
 			if err != nil {
 				return false
 			}
 		}
 		return false
 	})
+	// The index keeps the texts it needs.
+	ni.hashed = nil
 
 	if err != nil {
 		return nil, err
 	}
 
+	if err = ni.indexVersions(); err != nil {
+		return nil, err
+	}
+
 	return ni, nil
 }
 
-// Hashes returns an ordered channel of the hashed license texts.
-func (ni *NoticeIndex) Hashes() chan hash {
-	c := make(chan hash)
-	go func() {
-		libs := make([]string, 0, len(ni.libHash))
-		for libName := range ni.libHash {
-			libs = append(libs, libName)
-		}
-		sort.Strings(libs)
-		hashes := make(map[hash]struct{})
-		for _, libName := range libs {
-			hl := make([]hash, 0, len(ni.libHash[libName]))
-			for h := range ni.libHash[libName] {
-				if _, ok := hashes[h]; ok {
-					continue
-				}
-				hashes[h] = struct{}{}
-				hl = append(hl, h)
+// indexVersions associates each library with the version in the METADATA
+// of its projects when they name a single version, and records a conflict
+// when they name several.
+func (ni *NoticeIndex) indexVersions() error {
+	ni.libVersion = make(map[string]string)
+	ni.versionConflicts = make(map[string]map[string][]string)
+	for libName := range ni.libProjects {
+		pms, err := ni.LibProjectMetadata(libName)
+		if err != nil {
+			return err
+		}
+		versions := make(map[string][]string)
+		for _, pm := range pms {
+			if version := pm.Version(); len(version) > 0 {
+				versions[version] = append(versions[version], pm.Project())
 			}
-			if len(hl) > 0 {
-				sort.Sort(hashList{ni, libName, "", &hl})
-				for _, h := range hl {
-					c <- h
-				}
+		}
+		if len(versions) > 1 {
+			for _, projects := range versions {
+				sort.Strings(projects)
 			}
+			ni.versionConflicts[libName] = versions
+			continue
+		}
+		for version := range versions {
+			ni.libVersion[libName] = version
+		}
+	}
+	return nil
+}
+
+// MergeSimilarTexts merges license texts scoring at least `threshold` per
+// SimilarityScore into a single text so that near-duplicates, which often
+// differ only by a year or a URL, share a single notice.
+//
+// The most widely installed text of each group represents the group with
+// ties going to the longest text.
+func (ni *NoticeIndex) MergeSimilarTexts(threshold float64) {
+	hashes := make([]Hash, 0, len(ni.hashLibInstall))
+	installs := make(map[Hash]int)
+	for h, libs := range ni.hashLibInstall {
+		hashes = append(hashes, h)
+		for _, paths := range libs {
+			installs[h] += len(paths)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		hi, hj := hashes[i], hashes[j]
+		if installs[hi] != installs[hj] {
+			return installs[hi] > installs[hj]
+		}
+		if ni.textLen(hi) != ni.textLen(hj) {
+			return ni.textLen(hi) > ni.textLen(hj)
+		}
+		return hi.key < hj.key
+	})
+	texts := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		texts = append(texts, string(ni.TextContent(h)))
+	}
+	for _, group := range GroupSimilarLicenses(texts, threshold) {
+		rep := hashes[group[0]]
+		for _, i := range group[1:] {
+			ni.mergeHash(hashes[i], rep)
+		}
+	}
+}
+
+// mergeHash moves every reference to the text hashed as `from` to the text
+// hashed as `to`.
+func (ni *NoticeIndex) mergeHash(from, to Hash) {
+	for libName, paths := range ni.hashLibInstall[from] {
+		if _, ok := ni.hashLibInstall[to][libName]; !ok {
+			ni.hashLibInstall[to][libName] = make(map[string]struct{})
+		}
+		for installPath := range paths {
+			ni.hashLibInstall[to][libName][installPath] = struct{}{}
+		}
+		delete(ni.libHash[libName], from)
+		ni.libHash[libName][to] = struct{}{}
+	}
+	delete(ni.hashLibInstall, from)
+	for libName, kinds := range ni.hashLibKinds[from] {
+		if _, ok := ni.hashLibKinds[to]; !ok {
+			ni.hashLibKinds[to] = make(map[string]map[string]struct{})
+		}
+		if _, ok := ni.hashLibKinds[to][libName]; !ok {
+			ni.hashLibKinds[to][libName] = make(map[string]struct{})
+		}
+		for kind := range kinds {
+			ni.hashLibKinds[to][libName][kind] = struct{}{}
+		}
+	}
+	delete(ni.hashLibKinds, from)
+	for file, h := range ni.hash {
+		if h == from {
+			ni.hash[file] = to
+		}
+	}
+	for _, hashLibs := range ni.installHashLib {
+		libs, ok := hashLibs[from]
+		if !ok {
+			continue
+		}
+		if _, ok := hashLibs[to]; !ok {
+			hashLibs[to] = make(map[string]struct{})
+		}
+		for libName := range libs {
+			hashLibs[to][libName] = struct{}{}
+		}
+		delete(hashLibs, from)
+	}
+	for installPath, hashLibs := range ni.installHashLibConditions {
+		for libName, conditions := range hashLibs[from] {
+			ni.addConditions(installPath, to, libName, conditions)
+		}
+		delete(hashLibs, from)
+	}
+	for _, hashes := range ni.targetHashes {
+		if _, ok := hashes[from]; ok {
+			delete(hashes, from)
+			hashes[to] = struct{}{}
+		}
+	}
+	delete(ni.text, from)
+	delete(ni.unread, from)
+	delete(ni.normalized, from)
+	// The install paths of `to` gained those of `from`.
+	ni.installMu.Lock()
+	delete(ni.sortedInstalls, from)
+	delete(ni.sortedInstalls, to)
+	ni.installMu.Unlock()
+}
+
+// AllHashes returns the ordered array of hashes of the license texts.
+//
+// The hashes appear in library name order, and within each library, in order
+// of decreasing popularity.
+func (ni *NoticeIndex) AllHashes() []Hash {
+	libs := make([]string, 0, len(ni.libHash))
+	for libName := range ni.libHash {
+		libs = append(libs, libName)
+	}
+	sort.Strings(libs)
+	result := make([]Hash, 0, len(ni.hashLibInstall))
+	hashes := make(map[Hash]struct{})
+	for _, libName := range libs {
+		hl := make([]Hash, 0, len(ni.libHash[libName]))
+		for h := range ni.libHash[libName] {
+			if _, ok := hashes[h]; ok {
+				continue
+			}
+			hashes[h] = struct{}{}
+			hl = append(hl, h)
+		}
+		if len(hl) > 0 {
+			sort.Sort(hashList{ni, libName, "", &hl})
+			result = append(result, hl...)
+		}
+	}
+	return result
+}
+
+// Hashes returns an ordered channel of the hashed license texts.
+//
+// Deprecated: Use AllHashes.
+func (ni *NoticeIndex) Hashes() chan Hash {
+	c := make(chan Hash)
+	go func() {
+		for _, h := range ni.AllHashes() {
+			c <- h
 		}
 		close(c)
 	}()
 	return c
-
 }
 
 // InputFiles returns the complete list of files read during indexing.
@@ -243,7 +598,7 @@ func (ni *NoticeIndex) InputFiles() []string {
 
 // HashLibs returns the ordered array of library names using the license text
 // hashed as `h`.
-func (ni *NoticeIndex) HashLibs(h hash) []string {
+func (ni *NoticeIndex) HashLibs(h Hash) []string {
 	libs := make([]string, 0, len(ni.hashLibInstall[h]))
 	for libName := range ni.hashLibInstall[h] {
 		libs = append(libs, libName)
@@ -252,27 +607,70 @@ func (ni *NoticeIndex) HashLibs(h hash) []string {
 	return libs
 }
 
-// HashLibInstalls returns the ordered array of install paths referencing
-// library `libName` using the license text hashed as `h`.
-func (ni *NoticeIndex) HashLibInstalls(h hash, libName string) []string {
+// SortedInstallPaths returns the lexicographically ordered array of install
+// paths referencing library `libName` using the license text hashed as `h`.
+//
+// Every notice format lists the install paths of a library in this order.
+// The array is computed once and shared between callers, which must not
+// modify it.
+func (ni *NoticeIndex) SortedInstallPaths(h Hash, libName string) []string {
+	ni.installMu.Lock()
+	defer ni.installMu.Unlock()
+	if installs, ok := ni.sortedInstalls[h][libName]; ok {
+		return installs
+	}
 	installs := make([]string, 0, len(ni.hashLibInstall[h][libName]))
 	for installPath := range ni.hashLibInstall[h][libName] {
 		installs = append(installs, installPath)
 	}
 	sort.Strings(installs)
+	if ni.sortedInstalls == nil {
+		ni.sortedInstalls = make(map[Hash]map[string][]string)
+	}
+	if _, ok := ni.sortedInstalls[h]; !ok {
+		ni.sortedInstalls[h] = make(map[string][]string)
+	}
+	ni.sortedInstalls[h][libName] = installs
 	return installs
 }
 
+// HashLibInstalls returns the ordered array of install paths referencing
+// library `libName` using the license text hashed as `h`.
+//
+// Returns a copy the caller may modify. See SortedInstallPaths.
+func (ni *NoticeIndex) HashLibInstalls(h Hash, libName string) []string {
+	sorted := ni.SortedInstallPaths(h, libName)
+	return append(make([]string, 0, len(sorted)), sorted...)
+}
+
+// HashLibLicenseKinds returns the license kinds of the targets contributing
+// the license text with hash `h` for library `libName`. (sorted)
+func (ni *NoticeIndex) HashLibLicenseKinds(h Hash, libName string) []string {
+	kinds := make([]string, 0, len(ni.hashLibKinds[h][libName]))
+	for kind := range ni.hashLibKinds[h][libName] {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// AllInstallPaths returns the ordered array of indexed install paths.
+func (ni *NoticeIndex) AllInstallPaths() []string {
+	paths := make([]string, 0, len(ni.installHashLib))
+	for path := range ni.installHashLib {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // InstallPaths returns the ordered channel of indexed install paths.
+//
+// Deprecated: Use AllInstallPaths.
 func (ni *NoticeIndex) InstallPaths() chan string {
 	c := make(chan string)
 	go func() {
-		paths := make([]string, 0, len(ni.installHashLib))
-		for path := range ni.installHashLib {
-			paths = append(paths, path)
-		}
-		sort.Strings(paths)
-		for _, installPath := range paths {
+		for _, installPath := range ni.AllInstallPaths() {
 			c <- installPath
 		}
 		close(c)
@@ -281,8 +679,8 @@ func (ni *NoticeIndex) InstallPaths() chan string {
 }
 
 // InstallHashes returns the ordered array of hashes attached to `installPath`.
-func (ni *NoticeIndex) InstallHashes(installPath string) []hash {
-	result := make([]hash, 0, len(ni.installHashLib[installPath]))
+func (ni *NoticeIndex) InstallHashes(installPath string) []Hash {
+	result := make([]Hash, 0, len(ni.installHashLib[installPath]))
 	for h := range ni.installHashLib[installPath] {
 		result = append(result, h)
 	}
@@ -294,7 +692,7 @@ func (ni *NoticeIndex) InstallHashes(installPath string) []hash {
 
 // InstallHashLibs returns the ordered array of library names attached to
 // `installPath` as hash `h`.
-func (ni *NoticeIndex) InstallHashLibs(installPath string, h hash) []string {
+func (ni *NoticeIndex) InstallHashLibs(installPath string, h Hash) []string {
 	result := make([]string, 0, len(ni.installHashLib[installPath][h]))
 	for libName := range ni.installHashLib[installPath][h] {
 		result = append(result, libName)
@@ -303,16 +701,45 @@ func (ni *NoticeIndex) InstallHashLibs(installPath string, h hash) []string {
 	return result
 }
 
+// InstallHashLibConditions returns the license conditions of the resolutions
+// attaching the license text with hash `h` for library `libName` to
+// `installPath`.
+func (ni *NoticeIndex) InstallHashLibConditions(installPath string, h Hash, libName string) LicenseConditionSet {
+	return ni.installHashLibConditions[installPath][h][libName]
+}
+
+// addConditions records `conditions` attaching the license text with hash
+// `h` for library `libName` to `installPath`.
+func (ni *NoticeIndex) addConditions(installPath string, h Hash, libName string, conditions LicenseConditionSet) {
+	if ni.installHashLibConditions == nil {
+		ni.installHashLibConditions = make(map[string]map[Hash]map[string]LicenseConditionSet)
+	}
+	if _, ok := ni.installHashLibConditions[installPath]; !ok {
+		ni.installHashLibConditions[installPath] = make(map[Hash]map[string]LicenseConditionSet)
+	}
+	if _, ok := ni.installHashLibConditions[installPath][h]; !ok {
+		ni.installHashLibConditions[installPath][h] = make(map[string]LicenseConditionSet)
+	}
+	ni.installHashLibConditions[installPath][h][libName] |= conditions
+}
+
+// AllLibraries returns the ordered array of indexed library names.
+func (ni *NoticeIndex) AllLibraries() []string {
+	libs := make([]string, 0, len(ni.libHash))
+	for lib := range ni.libHash {
+		libs = append(libs, lib)
+	}
+	sort.Strings(libs)
+	return libs
+}
+
 // Libraries returns the ordered channel of indexed library names.
+//
+// Deprecated: Use AllLibraries.
 func (ni *NoticeIndex) Libraries() chan string {
 	c := make(chan string)
 	go func() {
-		libs := make([]string, 0, len(ni.libHash))
-		for lib := range ni.libHash {
-			libs = append(libs, lib)
-		}
-		sort.Strings(libs)
-		for _, lib := range libs {
+		for _, lib := range ni.AllLibraries() {
 			c <- lib
 		}
 		close(c)
@@ -320,13 +747,130 @@ func (ni *NoticeIndex) Libraries() chan string {
 	return c
 }
 
-// HashText returns the file content of the license text hashed as `h`.
-func (ni *NoticeIndex) HashText(h hash) []byte {
-	return ni.text[h]
+// LibHashes returns the ordered array of hashes of the license texts used by
+// library `libName`.
+func (ni *NoticeIndex) LibHashes(libName string) []Hash {
+	result := make([]Hash, 0, len(ni.libHash[libName]))
+	for h := range ni.libHash[libName] {
+		result = append(result, h)
+	}
+	if len(result) > 0 {
+		sort.Sort(hashList{ni, libName, "", &result})
+	}
+	return result
+}
+
+// LibProjects returns the ordered array of projects of the targets for
+// library `libName`.
+func (ni *NoticeIndex) LibProjects(libName string) []string {
+	projects := make([]string, 0, len(ni.libProjects[libName]))
+	for p := range ni.libProjects[libName] {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// LibProjectMetadata returns the METADATA of the projects of the targets for
+// library `libName` skipping projects without METADATA.
+func (ni *NoticeIndex) LibProjectMetadata(libName string) ([]*projectmetadata.ProjectMetadata, error) {
+	return ni.pmix.MetadataForProjects(ni.LibProjects(libName)...)
+}
+
+// Version returns the version of library `libName` from the METADATA of its
+// projects, or the empty string when none of them names a version or they
+// name different versions.
+//
+// VersionConflicts lists the libraries whose projects name different
+// versions.
+func (ni *NoticeIndex) Version(libName string) string {
+	return ni.libVersion[libName]
+}
+
+// VersionConflict describes a library whose projects' METADATA name
+// different versions.
+type VersionConflict struct {
+	LibName string
+	// Versions maps each version to the projects naming it. (sorted)
+	Versions map[string][]string
+}
+
+// String returns a description such as "libfoo has versions 1.0 (external/a)
+// and 2.0 (external/b, external/c)" listing the versions in order.
+func (vc VersionConflict) String() string {
+	versions := make([]string, 0, len(vc.Versions))
+	for version := range vc.Versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	for i, version := range versions {
+		versions[i] = fmt.Sprintf("%s (%s)", version, strings.Join(vc.Versions[version], ", "))
+	}
+	last := len(versions) - 1
+	return fmt.Sprintf("%s has versions %s and %s", vc.LibName, strings.Join(versions[:last], ", "), versions[last])
+}
+
+// VersionConflicts returns the libraries whose projects' METADATA name
+// different versions ordered by library name.
+func (ni *NoticeIndex) VersionConflicts() []VersionConflict {
+	result := make([]VersionConflict, 0, len(ni.versionConflicts))
+	for libName, versions := range ni.versionConflicts {
+		result = append(result, VersionConflict{libName, versions})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LibName < result[j].LibName })
+	return result
+}
+
+// TextContent returns the file content of the license text hashed as `h` or
+// nil for a hash not in the index.
+//
+// The notices write HashText instead.
+func (ni *NoticeIndex) TextContent(h Hash) []byte {
+	text, _ := ni.loadText(h)
+	return text
+}
+
+// HashFiles returns the ordered license text files hashed as `h`, which
+// excludes the embedded SPDX texts without a file.
+func (ni *NoticeIndex) HashFiles(h Hash) []string {
+	var files []string
+	for file, fh := range ni.hash {
+		if fh == h {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// HashText returns the license text hashed as `h` exactly as the notice
+// commands write it: the file content with the copyright lines merged per
+// NormalizeCopyrights.
+//
+// Normalizes each text on first use and returns the cached result after.
+// Returns an error wrapping ErrUnknownHash for a hash not in the index.
+func (ni *NoticeIndex) HashText(h Hash) ([]byte, error) {
+	ni.mu.Lock()
+	defer ni.mu.Unlock()
+	if text, ok := ni.normalized[h]; ok {
+		return text, nil
+	}
+	content, err := ni.loadText(h)
+	if err != nil {
+		return nil, err
+	}
+	text := []byte(NormalizeCopyrights(string(content)))
+	ni.normalized[h] = text
+	return text, nil
 }
 
 // getLibName returns the name of the library associated with `noticeFor`.
-func (ni *NoticeIndex) getLibName(noticeFor *TargetNode, h hash) (string, error) {
+func (ni *NoticeIndex) getLibName(noticeFor *TargetNode, h Hash) (string, error) {
+	if ni.libraryNames != nil {
+		if ln, ok := ni.libraryNames.lookup(noticeFor); ok {
+			return ln, nil
+		}
+	}
 	for _, text := range noticeFor.LicenseTexts() {
 		if !strings.Contains(text, ":") {
 			if ni.hash[text].key != h.key {
@@ -484,28 +1028,190 @@ func (ni *NoticeIndex) checkMetadataForLicenseText(noticeFor *TargetNode, licens
 	return pms[0].VersionedName(), nil
 }
 
-// addText reads and indexes the content of a license text file.
+// addText reads and indexes the content of a license text file following
+// any symbolic links per ResolveLink.
 func (ni *NoticeIndex) addText(file string) error {
-	f, err := ni.rootFS.Open(filepath.Clean(file))
+	ht, ok := ni.hashed[file]
+	if !ok {
+		ht = ni.readText(file)
+	}
+	if ht.err != nil {
+		return ht.err
+	}
+
+	ni.hash[file] = ht.hash
+	if _, alreadyPresent := ni.text[ht.hash]; !alreadyPresent {
+		if ht.text != nil {
+			ni.text[ht.hash] = ht.text
+			delete(ni.unread, ht.hash)
+		} else if _, ok := ni.unread[ht.hash]; !ok {
+			ni.unread[ht.hash] = unreadText{ht.resolved, ht.size}
+		}
+	}
+	if ht.blank {
+		ni.emptyFiles[file] = struct{}{}
+		if ni.emptyText == EmptyTextPlaceholder {
+			ni.addPlaceholder(file)
+		}
+	}
+
+	ni.files = append(ni.files, file)
+	if ht.resolved != filepath.Clean(file) {
+		ni.files = append(ni.files, ht.resolved)
+	}
+
+	if ni.lg.progress != nil {
+		ni.lg.progress(ProgressHashTexts, len(ni.hash), 0)
+	}
+
+	return nil
+}
+
+// hashTexts reads and hashes the license text files of the shipped targets
+// using up to `workers` goroutines, stopping early when `ctx` is done.
+//
+// Records the results, including errors, for addText, which reads any file
+// not recorded itself.
+func (ni *NoticeIndex) hashTexts(ctx context.Context, workers int) {
+	seen := make(map[string]struct{})
+	var files []string
+	for tn := range ni.shipped {
+		for _, text := range tn.LicenseTexts() {
+			fname := strings.SplitN(text, ":", 2)[0]
+			if _, ok := seen[fname]; !ok {
+				seen[fname] = struct{}{}
+				files = append(files, fname)
+			}
+		}
+	}
+
+	// Each task fills its own element of results so none need a lock.
+	results := make([]*hashedText, len(files))
+	task := make(chan bool, workers)
+	for i := 0; i < workers; i++ {
+		task <- true
+	}
+	var wg sync.WaitGroup
+	for i, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		<-task
+		wg.Add(1)
+		go func(i int, file string) {
+			defer func() {
+				task <- true
+				wg.Done()
+			}()
+			results[i] = ni.readText(file)
+		}(i, file)
+	}
+	wg.Wait()
+
+	ni.hashed = make(map[string]*hashedText, len(files))
+	for i, ht := range results {
+		if ht != nil {
+			ni.hashed[files[i]] = ht
+		}
+	}
+}
+
+// readText reads and hashes the license text file `file`, or returns the
+// hash without the content when the hash cache has the hash of the file.
+func (ni *NoticeIndex) readText(file string) *hashedText {
+	resolved, err := ResolveLink(ni.rootFS, filepath.Clean(file))
+	if err != nil {
+		return &hashedText{err: fmt.Errorf("error resolving license text file %q: %w", file, err)}
+	}
+	var fi fs.FileInfo
+	if ni.hashCache != nil {
+		fi, err = fs.Stat(ni.rootFS, resolved)
+		if err != nil {
+			return &hashedText{err: fmt.Errorf("error opening license text file %q: %w", file, err)}
+		}
+		if e, ok := ni.hashCache.lookup(resolved, fi); ok {
+			// The file remains an input without reading it.
+			if r, ok := ni.rootFS.(interface{ Record(string) }); ok {
+				r.Record(resolved)
+			}
+			return &hashedText{resolved: resolved, size: int(fi.Size()), hash: Hash{e.Hash}, blank: e.Blank}
+		}
+	}
+	f, err := ni.rootFS.Open(resolved)
 	if err != nil {
-		return fmt.Errorf("error opening license text file %q: %w", file, err)
+		return &hashedText{err: fmt.Errorf("error opening license text file %q: %w", file, err)}
 	}
+	defer f.Close()
 
 	// read the file
 	text, err := io.ReadAll(f)
 	if err != nil {
-		return fmt.Errorf("error reading license text file %q: %w", file, err)
+		return &hashedText{err: fmt.Errorf("error reading license text file %q: %w", file, err)}
 	}
 
-	hash := hash{fmt.Sprintf("%x", md5.Sum(text))}
-	ni.hash[file] = hash
-	if _, alreadyPresent := ni.text[hash]; !alreadyPresent {
-		ni.text[hash] = text
+	h := Hash{fmt.Sprintf("%x", md5.Sum(text))}
+	if ni.hashCache != nil {
+		ni.hashCache.store(resolved, fi, text, h)
 	}
+	return &hashedText{resolved, text, len(text), h, len(bytes.TrimSpace(text)) == 0, nil}
+}
 
-	ni.files = append(ni.files, file)
+// loadText returns the content of the license text hashed as `h`, reading
+// it first when the hash came from the hash cache.
+func (ni *NoticeIndex) loadText(h Hash) ([]byte, error) {
+	ni.textMu.Lock()
+	defer ni.textMu.Unlock()
+	if text, ok := ni.text[h]; ok {
+		return text, nil
+	}
+	u, ok := ni.unread[h]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownHash, h.String())
+	}
+	text, err := fs.ReadFile(ni.rootFS, u.file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading license text file %q: %w", u.file, err)
+	}
+	ni.text[h] = text
+	delete(ni.unread, h)
+	return text, nil
+}
 
-	return nil
+// textLen returns the length of the license text hashed as `h` without
+// reading it.
+func (ni *NoticeIndex) textLen(h Hash) int {
+	ni.textMu.Lock()
+	defer ni.textMu.Unlock()
+	if text, ok := ni.text[h]; ok {
+		return len(text)
+	}
+	return ni.unread[h].size
+}
+
+// addSpdxText indexes the embedded SPDX license text for `spdxID` returning
+// its hash and true, or false if the license list has no such text.
+func (ni *NoticeIndex) addSpdxText(spdxID string) (Hash, bool) {
+	text, err := FetchLicenseText(spdxID, ni.spdxCache)
+	if err != nil {
+		return Hash{}, false
+	}
+	h := Hash{fmt.Sprintf("%x", md5.Sum([]byte(text)))}
+	if _, alreadyPresent := ni.text[h]; !alreadyPresent {
+		ni.text[h] = []byte(text)
+	}
+	return h, true
+}
+
+// transformPaths returns `installPaths` mapped by the path transform of the
+// index without the dropped paths.
+func (ni *NoticeIndex) transformPaths(installPaths []string) []string {
+	result := make([]string, 0, len(installPaths))
+	for _, installPath := range installPaths {
+		if p, ok := ni.pathTransform(installPath); ok {
+			result = append(result, p)
+		}
+	}
+	return result
 }
 
 // getInstallPaths returns the names of the used dependencies mapped to their
@@ -593,13 +1299,14 @@ func getInstallPaths(attachesTo *TargetNode, path TargetEdgePath) []string {
 	return result
 }
 
-// hash is an opaque string derived from md5sum.
-type hash struct {
+// Hash identifies a license text by an opaque string derived from its
+// md5sum.
+type Hash struct {
 	key string
 }
 
 // String returns the hexadecimal representation of the hash.
-func (h hash) String() string {
+func (h Hash) String() string {
 	return h.key
 }
 
@@ -608,7 +1315,7 @@ type hashList struct {
 	ni          *NoticeIndex
 	libName     string
 	installPath string
-	hashes      *[]hash
+	hashes      *[]Hash
 }
 
 // Len returns the count of elements in the slice.
@@ -635,8 +1342,8 @@ func (l hashList) Less(i, j int) bool {
 		}
 	}
 	if insti == instj {
-		leni := len(l.ni.text[(*l.hashes)[i]])
-		lenj := len(l.ni.text[(*l.hashes)[j]])
+		leni := l.ni.textLen((*l.hashes)[i])
+		lenj := l.ni.textLen((*l.hashes)[j])
 		if leni == lenj {
 			// all else equal, just order by hash value
 			return (*l.hashes)[i].key < (*l.hashes)[j].key
@@ -0,0 +1,459 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestNoticeIndexAPI(t *testing.T) {
+	lg, err := ReadLicenseGraph(GetFS(""), &bytes.Buffer{}, []string{"testdata/notice/highest.apex.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(GetFS(""), lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+
+	hashes := ni.AllHashes()
+	if len(hashes) != 2 {
+		t.Errorf("unexpected hashes: got %d, want 2", len(hashes))
+	}
+	libs := make(map[string]struct{})
+	for _, h := range hashes {
+		if len(ni.TextContent(h)) == 0 {
+			t.Errorf("missing text for hash %s", h)
+		}
+		if files := ni.HashFiles(h); len(files) == 0 || !sort.StringsAreSorted(files) {
+			t.Errorf("unexpected files for hash %s: got %q", h, files)
+		}
+		hashLibs := ni.HashLibs(h)
+		if !sort.StringsAreSorted(hashLibs) {
+			t.Errorf("unordered libraries for hash %s: got %q", h, hashLibs)
+		}
+		for _, lib := range hashLibs {
+			libs[lib] = struct{}{}
+			installPaths := ni.HashLibInstalls(h, lib)
+			if len(installPaths) == 0 || !sort.StringsAreSorted(installPaths) {
+				t.Errorf("unexpected install paths for %q hash %s: got %q", lib, h, installPaths)
+			}
+		}
+	}
+	if len(libs) != len(ni.AllLibraries()) {
+		t.Errorf("unexpected libraries: got %d from hashes, want %d", len(libs), len(ni.AllLibraries()))
+	}
+	if !reflect.DeepEqual(ni.AllHashes(), hashes) {
+		t.Errorf("nondeterministic hashes: got %v, want %v", ni.AllHashes(), hashes)
+	}
+
+	// The deprecated channel iterators agree with the slices.
+	var chanHashes []Hash
+	for h := range ni.Hashes() {
+		chanHashes = append(chanHashes, h)
+	}
+	if !reflect.DeepEqual(chanHashes, hashes) {
+		t.Errorf("unexpected Hashes(): got %v, want %v", chanHashes, hashes)
+	}
+	var chanLibs []string
+	for lib := range ni.Libraries() {
+		chanLibs = append(chanLibs, lib)
+	}
+	if !reflect.DeepEqual(chanLibs, ni.AllLibraries()) {
+		t.Errorf("unexpected Libraries(): got %q, want %q", chanLibs, ni.AllLibraries())
+	}
+	var chanPaths []string
+	for installPath := range ni.InstallPaths() {
+		chanPaths = append(chanPaths, installPath)
+	}
+	if !reflect.DeepEqual(chanPaths, ni.AllInstallPaths()) {
+		t.Errorf("unexpected InstallPaths(): got %q, want %q", chanPaths, ni.AllInstallPaths())
+	}
+}
+
+func TestNoticeIndexSortedInstallPaths(t *testing.T) {
+	lg, err := ReadLicenseGraph(GetFS(""), &bytes.Buffer{}, []string{"testdata/notice/highest.apex.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(GetFS(""), lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+
+	// textnotice lists the paths of each library from Groups, htmlnotice
+	// from SortedInstallPaths, and xmlnotice lists the libraries of each
+	// path from AllInstallPaths. All three must agree.
+	byFile := make(map[string][]string)
+	for _, installPath := range ni.AllInstallPaths() {
+		for _, h := range ni.InstallHashes(installPath) {
+			for _, lib := range ni.InstallHashLibs(installPath, h) {
+				key := h.String() + "/" + lib
+				byFile[key] = append(byFile[key], installPath)
+			}
+		}
+	}
+	libCount := 0
+	for _, group := range ni.Groups() {
+		for _, lib := range group.Libs {
+			libCount++
+			h := Hash{group.Hash}
+			sorted := ni.SortedInstallPaths(h, lib.Name)
+			if len(sorted) == 0 || !sort.StringsAreSorted(sorted) {
+				t.Errorf("SortedInstallPaths(%s, %q): got %q, want sorted paths", h, lib.Name, sorted)
+			}
+			if !reflect.DeepEqual(lib.InstallPaths, sorted) {
+				t.Errorf("Groups(): got %q for %q, want %q", lib.InstallPaths, lib.Name, sorted)
+			}
+			if actual := byFile[group.Hash+"/"+lib.Name]; !reflect.DeepEqual(actual, sorted) {
+				t.Errorf("AllInstallPaths(): got %q for %q, want %q", actual, lib.Name, sorted)
+			}
+			if !reflect.DeepEqual(ni.HashLibInstalls(h, lib.Name), sorted) {
+				t.Errorf("HashLibInstalls(%s, %q): got %q, want %q", h, lib.Name, ni.HashLibInstalls(h, lib.Name), sorted)
+			}
+
+			// The result is cached while HashLibInstalls returns a copy.
+			if again := ni.SortedInstallPaths(h, lib.Name); &again[0] != &sorted[0] {
+				t.Errorf("SortedInstallPaths(%s, %q): got a new array, want the cached one", h, lib.Name)
+			}
+			if copied := ni.HashLibInstalls(h, lib.Name); &copied[0] == &sorted[0] {
+				t.Errorf("HashLibInstalls(%s, %q): got the cached array, want a copy", h, lib.Name)
+			}
+		}
+	}
+	if libCount != len(byFile) {
+		t.Errorf("Groups(): got %d libraries, want %d", libCount, len(byFile))
+	}
+	if actual := ni.SortedInstallPaths(Hash{"missing"}, "nolib"); len(actual) != 0 {
+		t.Errorf("SortedInstallPaths(missing): got %q, want none", actual)
+	}
+}
+
+func TestNoticeIndexHashText(t *testing.T) {
+	rootFS := &testfs.TestFS{
+		"bin.meta_lic": []byte(AOSP + "installed: \"/system/bin/bin\"\nlicense_texts: \"LICENSE\"\n"),
+		"LICENSE":      []byte("Copyright 2018 Foo\nCopyright 2019 Foo\n\nLicensed.\n"),
+	}
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(rootFS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+
+	hashes := ni.AllHashes()
+	if len(hashes) != 1 {
+		t.Fatalf("unexpected hashes: got %d, want 1", len(hashes))
+	}
+	h := hashes[0]
+	text, err := ni.HashText(h)
+	if err != nil {
+		t.Fatalf("HashText(%s): got error %s, want no error", h, err)
+	}
+	expected := "Copyright 2018-2019 Foo\n\nLicensed.\n"
+	if string(text) != expected {
+		t.Errorf("HashText(%s): got %q, want %q", h, text, expected)
+	}
+	if string(ni.TextContent(h)) != string((*rootFS)["LICENSE"]) {
+		t.Errorf("TextContent(%s): got %q, want the unnormalized text", h, ni.TextContent(h))
+	}
+	again, err := ni.HashText(h)
+	if err != nil || &again[0] != &text[0] {
+		t.Errorf("HashText(%s): got a different result on the second call, want the cached text", h)
+	}
+
+	_, err = ni.HashText(Hash{"unknown"})
+	if !errors.Is(err, ErrUnknownHash) {
+		t.Errorf("HashText(unknown): got error %v, want ErrUnknownHash", err)
+	}
+}
+
+func TestNoticeIndexSymlink(t *testing.T) {
+	lg, err := ReadLicenseGraph(FS, &bytes.Buffer{}, []string{"testdata/regresssymlink/bin/bin1.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(FS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+
+	if len(ni.AllHashes()) != 1 {
+		t.Errorf("unexpected hashes: got %d, want 1 for the link and the file it points to", len(ni.AllHashes()))
+	}
+	inputs := make(map[string]bool)
+	for _, f := range ni.InputFiles() {
+		inputs[f] = true
+	}
+	for _, f := range []string{"testdata/regresssymlink/bin/NOTICE", "testdata/regresssymlink/LICENSE"} {
+		if !inputs[f] {
+			t.Errorf("InputFiles(): got %q, want %q included", ni.InputFiles(), f)
+		}
+	}
+
+	lfs := &linkFS{
+		testfs.TestFS{
+			"bin.meta_lic": []byte(AOSP + "installed: \"/system/bin/bin\"\nlicense_texts: \"NOTICE\"\n"),
+			"LICENSE":      []byte("Licensed.\n"),
+		},
+		map[string]string{"NOTICE": "LICENSE"},
+	}
+	lg, err = ReadLicenseGraph(lfs, &bytes.Buffer{}, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err = IndexLicenseTexts(lfs, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	if hashes := ni.AllHashes(); len(hashes) != 1 || string(ni.TextContent(hashes[0])) != "Licensed.\n" {
+		t.Errorf("IndexLicenseTexts(): got hashes %v, want the content of LICENSE", hashes)
+	}
+
+	lfs.links["NOTICE"] = "NOTICE"
+	if _, err = IndexLicenseTexts(lfs, lg, ResolveNotices(lg)); err == nil {
+		t.Errorf("IndexLicenseTexts(): got no error for a link cycle, want error")
+	}
+}
+
+func TestNoticeIndexPathTransform(t *testing.T) {
+	lg, err := ReadLicenseGraph(GetFS(""), &bytes.Buffer{}, []string{"testdata/notice/highest.apex.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	expected, err := IndexLicenseTexts(GetFS(""), lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+
+	ni, err := IndexLicenseTextsWithOptions(context.Background(), GetFS(""), lg, ResolveNotices(lg), IndexOptions{})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(): got error %s, want no error", err)
+	}
+	if !reflect.DeepEqual(ni.AllInstallPaths(), expected.AllInstallPaths()) {
+		t.Errorf("IndexLicenseTextsWithOptions(default): got %q, want %q", ni.AllInstallPaths(), expected.AllInstallPaths())
+	}
+
+	strip := StripPrefixTransform([]string{"out/target/product/fictional/"}, "")
+	ni, err = IndexLicenseTextsWithOptions(context.Background(), GetFS(""), lg, ResolveNotices(lg), IndexOptions{PathTransform: strip})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(): got error %s, want no error", err)
+	}
+	for _, installPath := range ni.AllInstallPaths() {
+		if !strings.HasPrefix(installPath, "system/apex/highest.apex") {
+			t.Errorf("IndexLicenseTextsWithOptions(strip): got install path %q, want stripped", installPath)
+		}
+	}
+	if len(ni.AllInstallPaths()) != len(expected.AllInstallPaths()) {
+		t.Errorf("IndexLicenseTextsWithOptions(strip): got %q, want %d paths", ni.AllInstallPaths(), len(expected.AllInstallPaths()))
+	}
+
+	dropLibs := ChainPathTransforms(strip, func(installPath string) (string, bool) {
+		return installPath, !strings.Contains(installPath, "/lib/")
+	})
+	ni, err = IndexLicenseTextsWithOptions(context.Background(), GetFS(""), lg, ResolveNotices(lg), IndexOptions{PathTransform: dropLibs})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(): got error %s, want no error", err)
+	}
+	expectedPaths := []string{
+		"system/apex/highest.apex",
+		"system/apex/highest.apex/bin/bin1",
+		"system/apex/highest.apex/bin/bin2",
+	}
+	if !reflect.DeepEqual(ni.AllInstallPaths(), expectedPaths) {
+		t.Errorf("IndexLicenseTextsWithOptions(drop libs): got %q, want %q", ni.AllInstallPaths(), expectedPaths)
+	}
+}
+
+func TestNoticeIndexVersion(t *testing.T) {
+	rootFS := testfs.TestFS{}
+	var root strings.Builder
+	root.WriteString(AOSP + "is_container: true\ninstalled: \"system.img\"\n")
+	// target adds a target for library `lib` in `project` with METADATA
+	// naming `version` unless empty.
+	target := func(name, lib, project, version string) {
+		rootFS[name+".meta_lic"] = []byte(fmt.Sprintf("package_name: \"%s\"\nprojects: \"%s\"\nlicense_conditions: \"notice\"\nlicense_texts: \"%s/LICENSE\"\ninstalled: \"system/lib/%s.so\"\n", lib, project, project, name))
+		rootFS[project+"/LICENSE"] = []byte("License of " + lib + ".\n")
+		if len(version) > 0 {
+			rootFS[project+"/METADATA"] = []byte(fmt.Sprintf("third_party { version: \"%s\" }\n", version))
+		}
+		fmt.Fprintf(&root, "deps: {\n  file: \"%s.meta_lic\"\n  annotations: \"static\"\n}\n", name)
+	}
+	target("liba", "liba", "external/a", "1.0")
+	target("libb1", "libb", "external/b1", "2.0")
+	target("libb2", "libb", "external/b2", "3.0")
+	target("libb3", "libb", "external/b3", "2.0")
+	target("libc1", "libc", "external/c1", "4.1")
+	target("libc2", "libc", "external/c2", "4.1")
+	target("libc3", "libc", "external/c3", "")
+	target("libd", "libd", "external/d", "")
+	rootFS["root.meta_lic"] = []byte(root.String())
+
+	lg, err := ReadLicenseGraph(&rootFS, &bytes.Buffer{}, []string{"root.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(&rootFS, lg, ResolveNotices(lg))
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+
+	for libName, expected := range map[string]string{"liba": "1.0", "libb": "", "libc": "4.1", "libd": "", "libz": ""} {
+		if actual := ni.Version(libName); actual != expected {
+			t.Errorf("Version(%q): got %q, want %q", libName, actual, expected)
+		}
+	}
+	expected := []VersionConflict{
+		{"libb", map[string][]string{"2.0": {"external/b1", "external/b3"}, "3.0": {"external/b2"}}},
+	}
+	if actual := ni.VersionConflicts(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("VersionConflicts(): got %v, want %v", actual, expected)
+	}
+	expectedString := "libb has versions 2.0 (external/b1, external/b3) and 3.0 (external/b2)"
+	if actual := expected[0].String(); actual != expectedString {
+		t.Errorf("VersionConflict.String(): got %q, want %q", actual, expectedString)
+	}
+}
+
+// hashingFixture returns a container `root.meta_lic` installing `libs`
+// libraries, each with its own license text and a text shared by every
+// fourth library, and the texts.
+func hashingFixture(libs int) testfs.TestFS {
+	rootFS := testfs.TestFS{}
+	var root strings.Builder
+	root.WriteString(AOSP + "is_container: true\ninstalled: \"system.img\"\n")
+	for i := 0; i < libs; i++ {
+		texts := fmt.Sprintf("license_texts: \"licenses/LICENSE%d\"\n", i)
+		if i%4 == 0 {
+			texts += "license_texts: \"licenses/SHARED\"\n"
+		}
+		rootFS[fmt.Sprintf("lib%d.meta_lic", i)] = []byte(fmt.Sprintf("package_name: \"lib%d\"\nlicense_conditions: \"notice\"\n%sinstalled: \"system/lib/lib%d.so\"\n", i, texts, i))
+		rootFS[fmt.Sprintf("licenses/LICENSE%d", i)] = []byte(fmt.Sprintf("Copyright %d\n\nLicensed.\n", i))
+		fmt.Fprintf(&root, "deps: {\n  file: \"lib%d.meta_lic\"\n  annotations: \"static\"\n}\n", i)
+	}
+	rootFS["licenses/SHARED"] = []byte("Shared.\n")
+	rootFS["root.meta_lic"] = []byte(root.String())
+	return rootFS
+}
+
+// TestNoticeIndexHashWorkers checks the index does not depend on the number
+// of workers hashing the texts or the order they finish. Run with -race.
+func TestNoticeIndexHashWorkers(t *testing.T) {
+	rootFS := hashingFixture(200)
+	lg, err := ReadLicenseGraph(&rootFS, &bytes.Buffer{}, []string{"root.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	rs := ResolveNotices(lg)
+
+	// describe returns the contents of `ni` in the order of its methods.
+	describe := func(ni *NoticeIndex) []string {
+		var result []string
+		for _, h := range ni.AllHashes() {
+			result = append(result, h.String()+" "+string(ni.TextContent(h)))
+			for _, libName := range ni.HashLibs(h) {
+				result = append(result, libName+": "+strings.Join(ni.HashLibInstalls(h, libName), " "))
+			}
+		}
+		// InputFiles lists the license metadata files in no order.
+		files := ni.InputFiles()
+		sort.Strings(files)
+		return append(result, files...)
+	}
+
+	expected, err := IndexLicenseTextsWithOptions(context.Background(), &rootFS, lg, rs, IndexOptions{HashWorkers: 1})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(1 worker): got error %s, want no error", err)
+	}
+	if len(expected.AllHashes()) != 201 {
+		t.Fatalf("IndexLicenseTextsWithOptions(1 worker): got %d hashes, want 201", len(expected.AllHashes()))
+	}
+	for _, workers := range []int{0, 2, 8, 64} {
+		for run := 0; run < 3; run++ {
+			ni, err := IndexLicenseTextsWithOptions(context.Background(), &rootFS, lg, rs, IndexOptions{HashWorkers: workers})
+			if err != nil {
+				t.Fatalf("IndexLicenseTextsWithOptions(%d workers): got error %s, want no error", workers, err)
+			}
+			if actual := describe(ni); !reflect.DeepEqual(actual, describe(expected)) {
+				t.Errorf("IndexLicenseTextsWithOptions(%d workers): got %q, want %q", workers, actual, describe(expected))
+			}
+		}
+	}
+
+	if _, err := IndexLicenseTextsWithOptions(context.Background(), &rootFS, lg, rs, IndexOptions{HashWorkers: -1}); err == nil {
+		t.Errorf("IndexLicenseTextsWithOptions(-1 workers): got no error, want error")
+	}
+
+	delete(rootFS, "licenses/LICENSE7")
+	if _, err := IndexLicenseTextsWithOptions(context.Background(), &rootFS, lg, rs, IndexOptions{HashWorkers: 8}); err == nil || !strings.Contains(err.Error(), "licenses/LICENSE7") {
+		t.Errorf("IndexLicenseTextsWithOptions(missing text): got error %v, want error naming licenses/LICENSE7", err)
+	}
+}
+
+// BenchmarkIndexLicenseTexts20k indexes the hashingFixture with 20,000
+// libraries written to disk hashing the texts with 1 worker and with
+// GOMAXPROCS workers.
+func BenchmarkIndexLicenseTexts20k(b *testing.B) {
+	dir := b.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "licenses"), 0777); err != nil {
+		b.Fatal(err)
+	}
+	for name, data := range hashingFixture(20000) {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0666); err != nil {
+			b.Fatal(err)
+		}
+	}
+	rootFS := os.DirFS(dir)
+	lg, err := ReadLicenseGraph(rootFS, io.Discard, []string{"root.meta_lic"})
+	if err != nil {
+		b.Fatalf("ReadLicenseGraph: got error %s, want no error", err)
+	}
+	rs := ResolveNotices(lg)
+
+	for _, bb := range []struct {
+		name    string
+		workers int
+	}{
+		{"workers=1", 1},
+		{"workers=GOMAXPROCS", runtime.GOMAXPROCS(0)},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ni, err := IndexLicenseTextsWithOptions(context.Background(), rootFS, lg, rs, IndexOptions{HashWorkers: bb.workers})
+				if err != nil {
+					b.Fatalf("IndexLicenseTextsWithOptions: got error %s, want no error", err)
+				}
+				if len(ni.AllHashes()) != 20001 {
+					b.Fatalf("IndexLicenseTextsWithOptions: got %d hashes, want 20001", len(ni.AllHashes()))
+				}
+			}
+		})
+	}
+}
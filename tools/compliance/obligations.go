@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ObligationType identifies a kind of obligation a license places on the
+// distributor of a target.
+type ObligationType string
+
+const (
+	// ObligationAttribution requires reproducing the license text and any
+	// copyright notices with the distributed target.
+	ObligationAttribution = ObligationType("attribution")
+
+	// ObligationSourceDisclosure requires offering the source code of the
+	// target and, for restricted licenses, of the code linked with it.
+	ObligationSourceDisclosure = ObligationType("sourceDisclosure")
+
+	// ObligationPatentNotice requires preserving the patent terms or
+	// notices of the license.
+	ObligationPatentNotice = ObligationType("patentNotice")
+
+	// ObligationNoEndorsement prohibits using the names of the authors to
+	// endorse or promote derived products.
+	ObligationNoEndorsement = ObligationType("noEndorsement")
+
+	// ObligationNetworkCopyleft requires offering the source code to users
+	// interacting with the target over a network.
+	ObligationNetworkCopyleft = ObligationType("networkCopyleft")
+)
+
+// obligationTypeOrder orders the obligation types in reports.
+var obligationTypeOrder = map[ObligationType]int{
+	ObligationAttribution:      0,
+	ObligationSourceDisclosure: 1,
+	ObligationPatentNotice:     2,
+	ObligationNoEndorsement:    3,
+	ObligationNetworkCopyleft:  4,
+}
+
+// Obligation describes an obligation of type `Type` that the license of
+// `Target` places on its distributor.
+type Obligation struct {
+	// Type identifies the kind of obligation.
+	Type ObligationType
+	// Target names the license metadata file of the target.
+	Target string
+	// Detail describes the obligation for a human reader.
+	Detail string
+}
+
+// String returns a human-readable string representation of the obligation.
+func (o Obligation) String() string {
+	return fmt.Sprintf("%s %s: %s", o.Type, o.Target, o.Detail)
+}
+
+var (
+	// conditionObligations maps each license condition to the obligations it
+	// carries regardless of the license.
+	conditionObligations = map[LicenseCondition][]ObligationType{
+		NoticeCondition:           {ObligationAttribution},
+		ReciprocalCondition:       {ObligationAttribution, ObligationSourceDisclosure},
+		RestrictedCondition:       {ObligationAttribution, ObligationSourceDisclosure},
+		WeaklyRestrictedCondition: {ObligationAttribution, ObligationSourceDisclosure},
+	}
+
+	// licenseKindObligations maps regular expressions matching SPDX license
+	// identifiers to the additional obligations of the matching licenses.
+	licenseKindObligations = []licenseKindObligationsType{
+		{regexp.MustCompile(`(?i)^(?:Apache-2\.0|A?GPL-3\.0|LGPL-3\.0|MPL-2\.0|EPL-)`), ObligationPatentNotice},
+		{regexp.MustCompile(`(?i)^BSD-[34]-Clause`), ObligationNoEndorsement},
+		{regexp.MustCompile(`(?i)^AGPL-`), ObligationNetworkCopyleft},
+	}
+)
+
+type licenseKindObligationsType struct {
+	re         *regexp.Regexp
+	obligation ObligationType
+}
+
+// ConditionObligations returns the obligations carried by license condition
+// `lc` regardless of the license.
+func ConditionObligations(lc LicenseCondition) []ObligationType {
+	return append([]ObligationType{}, conditionObligations[lc]...)
+}
+
+// LicenseKindObligations returns the additional obligations of the license
+// identified by license kind `kind`.
+func LicenseKindObligations(kind string) []ObligationType {
+	id := SpdxIdentifierFromKind(kind)
+	var result []ObligationType
+	for _, ko := range licenseKindObligations {
+		if ko.re.MatchString(id) {
+			result = append(result, ko.obligation)
+		}
+	}
+	return result
+}
+
+// ObligationReport returns the obligations of the shipped targets in `lg`
+// grouped by the license condition carrying each.
+//
+// Each of the conditions originating at a target carries the obligations of
+// ConditionObligations. The license kinds of the target add the obligations of
+// LicenseKindObligations to the condition implied by the license or, when the
+// target has a different condition, to each of its conditions with
+// obligations. (each list sorted by target then type)
+func ObligationReport(lg *LicenseGraph) map[LicenseCondition][]Obligation {
+	result := make(map[LicenseCondition][]Obligation)
+	for tn := range ShippedNodes(lg) {
+		name := tn.PackageName()
+		if len(name) == 0 {
+			name = tn.Name()
+		}
+		conditions := tn.LicenseConditions()
+		for _, lc := range conditions.AsList() {
+			for _, ot := range conditionObligations[lc] {
+				result[lc] = append(result[lc], Obligation{ot, tn.Name(), obligationDetail(ot, lc, name, "")})
+			}
+		}
+		for _, kind := range tn.LicenseKinds() {
+			obligations := LicenseKindObligations(kind)
+			if len(obligations) == 0 {
+				continue
+			}
+			id := SpdxIdentifierFromKind(kind)
+			kindConditions := NewLicenseConditionSet()
+			for _, lc := range spdxLicenseConditions {
+				if lc.re.MatchString(id) {
+					kindConditions = conditions.MatchingAny(lc.condition)
+					break
+				}
+			}
+			if kindConditions.IsEmpty() {
+				kindConditions = conditions
+			}
+			for _, lc := range kindConditions.AsList() {
+				if len(conditionObligations[lc]) == 0 {
+					continue
+				}
+				for _, ot := range obligations {
+					result[lc] = append(result[lc], Obligation{ot, tn.Name(), obligationDetail(ot, lc, name, id)})
+				}
+			}
+		}
+	}
+	for _, obligations := range result {
+		sort.Slice(obligations, func(i, j int) bool {
+			if obligations[i].Target != obligations[j].Target {
+				return obligations[i].Target < obligations[j].Target
+			}
+			if obligations[i].Type != obligations[j].Type {
+				return obligationTypeOrder[obligations[i].Type] < obligationTypeOrder[obligations[j].Type]
+			}
+			return obligations[i].Detail < obligations[j].Detail
+		})
+	}
+	return result
+}
+
+// obligationDetail describes obligation `ot` carried by condition `lc` for
+// package `name` and, for license-specific obligations, license `id`.
+func obligationDetail(ot ObligationType, lc LicenseCondition, name, id string) string {
+	switch ot {
+	case ObligationAttribution:
+		return fmt.Sprintf("include the license text and notices of %s", name)
+	case ObligationSourceDisclosure:
+		switch lc {
+		case RestrictedCondition:
+			return fmt.Sprintf("offer the source of %s and of everything linked with it", name)
+		case WeaklyRestrictedCondition:
+			return fmt.Sprintf("offer the source of %s and of everything statically linked with it", name)
+		}
+		return fmt.Sprintf("offer the source of %s", name)
+	case ObligationPatentNotice:
+		return fmt.Sprintf("preserve the patent terms of %s for %s", id, name)
+	case ObligationNoEndorsement:
+		return fmt.Sprintf("do not use the names of the %s authors to promote products", name)
+	case ObligationNetworkCopyleft:
+		return fmt.Sprintf("offer the source of %s to users interacting with it over a network", name)
+	}
+	return string(ot)
+}
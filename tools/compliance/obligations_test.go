@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestConditionObligations(t *testing.T) {
+	expected := map[string][]ObligationType{
+		"unencumbered":                    {},
+		"permissive":                      {},
+		"notice":                          {ObligationAttribution},
+		"reciprocal":                      {ObligationAttribution, ObligationSourceDisclosure},
+		"restricted":                      {ObligationAttribution, ObligationSourceDisclosure},
+		"restricted_if_statically_linked": {ObligationAttribution, ObligationSourceDisclosure},
+		"proprietary":                     {},
+		"by_exception_only":               {},
+		"not_allowed":                     {},
+		"dynamic_link_warning":            {},
+	}
+	for name, lc := range RecognizedConditionNames {
+		t.Run(name, func(t *testing.T) {
+			want, ok := expected[name]
+			if !ok {
+				t.Fatalf("ConditionObligations(%s): no expected obligations for condition", name)
+			}
+			if actual := ConditionObligations(lc); !reflect.DeepEqual(actual, want) {
+				t.Errorf("ConditionObligations(%s): got %q, want %q", name, actual, want)
+			}
+		})
+	}
+}
+
+func TestLicenseKindObligations(t *testing.T) {
+	tests := []struct {
+		kind     string
+		expected []ObligationType
+	}{
+		{"SPDX-license-identifier-Apache-2.0", []ObligationType{ObligationPatentNotice}},
+		{"SPDX-license-identifier-MIT", nil},
+		{"SPDX-license-identifier-BSD-3-Clause", []ObligationType{ObligationNoEndorsement}},
+		{"SPDX-license-identifier-BSD-2-Clause", nil},
+		{"SPDX-license-identifier-GPL-2.0", nil},
+		{"SPDX-license-identifier-GPL-3.0-only", []ObligationType{ObligationPatentNotice}},
+		{"SPDX-license-identifier-AGPL-3.0-or-later", []ObligationType{ObligationPatentNotice, ObligationNetworkCopyleft}},
+		{"SPDX-license-identifier-MPL-2.0", []ObligationType{ObligationPatentNotice}},
+		{"legacy_notice", nil},
+	}
+	for _, tt := range tests {
+		if actual := LicenseKindObligations(tt.kind); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("LicenseKindObligations(%q): got %q, want %q", tt.kind, actual, tt.expected)
+		}
+	}
+}
+
+func TestObligationReport(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	lg, err := toGraph(stderr, []string{"apacheBin.meta_lic"}, []annotated{
+		{"apacheBin.meta_lic", "gplLib.meta_lic", []string{"static"}},
+		{"apacheBin.meta_lic", "mplLib.meta_lic", []string{"static"}},
+		{"apacheBin.meta_lic", "proprietary.meta_lic", []string{"static"}},
+		{"apacheBin.meta_lic", "lgplLib.meta_lic", []string{"dynamic"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	expected := map[LicenseCondition][]Obligation{
+		NoticeCondition: {
+			{ObligationAttribution, "apacheBin.meta_lic", "include the license text and notices of Android"},
+			{ObligationPatentNotice, "apacheBin.meta_lic", "preserve the patent terms of Apache-2.0 for Android"},
+		},
+		RestrictedCondition: {
+			{ObligationAttribution, "gplLib.meta_lic", "include the license text and notices of Free Software"},
+			{ObligationSourceDisclosure, "gplLib.meta_lic", "offer the source of Free Software and of everything linked with it"},
+		},
+		ReciprocalCondition: {
+			{ObligationAttribution, "mplLib.meta_lic", "include the license text and notices of Reciprocal"},
+			{ObligationSourceDisclosure, "mplLib.meta_lic", "offer the source of Reciprocal"},
+			{ObligationPatentNotice, "mplLib.meta_lic", "preserve the patent terms of MPL-2.0 for Reciprocal"},
+		},
+	}
+	actual := ObligationReport(lg)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ObligationReport(): got %v, want %v", actual, expected)
+	}
+}
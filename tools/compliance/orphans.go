@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FindOrphanMetaLic returns the license metadata files (*.meta_lic) under
+// `rootDir` in `rootFS` that are not reachable from any of `roots` by
+// following dependencies. (sorted)
+//
+// Orphans usually remain after build refactors remove the targets that
+// referenced them.
+func FindOrphanMetaLic(rootDir string, rootFS fs.FS, roots []string) ([]string, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no root license metadata files")
+	}
+	lg, err := ReadLicenseGraph(rootFS, io.Discard, roots)
+	if err != nil {
+		return nil, err
+	}
+	reachable := make(map[string]struct{})
+	for _, name := range lg.TargetNames() {
+		reachable[filepath.Clean(name)] = struct{}{}
+	}
+
+	orphans := make([]string, 0)
+	err = fs.WalkDir(rootFS, filepath.Clean(rootDir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".meta_lic") {
+			return nil
+		}
+		if _, ok := reachable[filepath.Clean(p)]; !ok {
+			orphans = append(orphans, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing license metadata files under %q: %w", rootDir, err)
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
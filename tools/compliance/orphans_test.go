@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFindOrphanMetaLic(t *testing.T) {
+	dep := func(file string) string {
+		return "deps: {\n  file: \"" + file + "\"\n  annotations: \"static\"\n}\n"
+	}
+	rootFS := fstest.MapFS{
+		"out/bin/bin1.meta_lic":      {Data: []byte(AOSP + dep("out/lib/liba.meta_lic"))},
+		"out/bin/bin2.meta_lic":      {Data: []byte(AOSP + dep("out/lib/libb.meta_lic"))},
+		"out/lib/liba.meta_lic":      {Data: []byte(AOSP + dep("out/lib/libc.meta_lic"))},
+		"out/lib/libb.meta_lic":      {Data: []byte(AOSP)},
+		"out/lib/libc.meta_lic":      {Data: []byte(AOSP)},
+		"out/lib/libold.meta_lic":    {Data: []byte(AOSP)},
+		"out/obj/stale/x.meta_lic":   {Data: []byte(AOSP + dep("out/lib/libb.meta_lic"))},
+		"out/lib/liba.so":            {Data: []byte("not metadata")},
+		"out/lib/notes.meta_lic.txt": {Data: []byte("not metadata")},
+		"elsewhere/y.meta_lic":       {Data: []byte(AOSP)},
+	}
+	tests := []struct {
+		name     string
+		rootDir  string
+		roots    []string
+		expected []string
+	}{
+		{
+			name:    "oneroot",
+			rootDir: "out",
+			roots:   []string{"out/bin/bin1.meta_lic"},
+			expected: []string{
+				"out/bin/bin2.meta_lic",
+				"out/lib/libb.meta_lic",
+				"out/lib/libold.meta_lic",
+				"out/obj/stale/x.meta_lic",
+			},
+		},
+		{
+			name:    "tworoots",
+			rootDir: "out/",
+			roots:   []string{"out/bin/bin1.meta_lic", "out/bin/bin2.meta_lic"},
+			expected: []string{
+				"out/lib/libold.meta_lic",
+				"out/obj/stale/x.meta_lic",
+			},
+		},
+		{
+			name:     "subdir",
+			rootDir:  "out/lib",
+			roots:    []string{"out/bin/bin2.meta_lic"},
+			expected: []string{"out/lib/liba.meta_lic", "out/lib/libc.meta_lic", "out/lib/libold.meta_lic"},
+		},
+		{
+			name:     "none",
+			rootDir:  "out/lib",
+			roots:    []string{"out/bin/bin1.meta_lic", "out/bin/bin2.meta_lic", "out/lib/libold.meta_lic"},
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := FindOrphanMetaLic(tt.rootDir, rootFS, tt.roots)
+			if err != nil {
+				t.Fatalf("FindOrphanMetaLic(): got error %s, want no error", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("FindOrphanMetaLic(): got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindOrphanMetaLicErrors(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"out/bin/bin1.meta_lic": {Data: []byte(AOSP)},
+	}
+	tests := []struct {
+		name    string
+		rootDir string
+		roots   []string
+	}{
+		{"noroots", "out", nil},
+		{"missingroot", "out", []string{"out/bin/missing.meta_lic"}},
+		{"missingdir", "missing", []string{"out/bin/bin1.meta_lic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := FindOrphanMetaLic(tt.rootDir, rootFS, tt.roots)
+			if err == nil {
+				t.Errorf("FindOrphanMetaLic(): got %q, want error", actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Override replaces the license conditions a license metadata file declares
+// when the file cannot be corrected at its source.
+type Override struct {
+	// Path identifies the license metadata file of the target to override.
+	Path string
+
+	// Conditions replaces the license conditions declared by the target.
+	Conditions LicenseConditionSet
+}
+
+// String returns a human-readable description of the override.
+func (o Override) String() string {
+	return o.Path + ": " + strings.Join(o.Conditions.Names(), ",")
+}
+
+// ParseOverrides reads the overrides from a YAML sequence of mappings with
+// `path` and `condition` keys in either flow or block style. e.g.
+//
+//	# upstream declares restricted by mistake
+//	- {path: "lib/liba.so.meta_lic", condition: "notice"}
+//	- path: lib/libb.so.meta_lic
+//	  condition: notice
+//
+// Only this subset of YAML is supported: no anchors, multi-line scalars, or
+// nested collections.
+func ParseOverrides(r io.Reader) ([]Override, error) {
+	overrides := make([]Override, 0)
+	var fields map[string]string
+	var start int
+	finish := func() error {
+		if fields == nil {
+			return nil
+		}
+		o, err := newOverride(fields)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", start, err)
+		}
+		overrides = append(overrides, o)
+		fields = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(stripYAMLComment(scanner.Text()), " \t")
+		trimmed := strings.TrimSpace(text)
+		if len(trimmed) == 0 || trimmed == "---" || trimmed == "[]" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			fields = make(map[string]string)
+			start = line
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			if strings.HasPrefix(trimmed, "{") {
+				if !strings.HasSuffix(trimmed, "}") {
+					return nil, fmt.Errorf("line %d: unterminated flow mapping", line)
+				}
+				for _, pair := range splitYAMLFlow(trimmed[1 : len(trimmed)-1]) {
+					if err := addYAMLField(fields, pair); err != nil {
+						return nil, fmt.Errorf("line %d: %w", line, err)
+					}
+				}
+				if err := finish(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if len(trimmed) == 0 {
+				continue
+			}
+		} else if fields == nil || text == trimmed {
+			return nil, fmt.Errorf("line %d: want sequence entry starting with \"- \"", line)
+		}
+		if err := addYAMLField(fields, trimmed); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// ReadOverridesFile reads the overrides from the YAML file at `path` per
+// ParseOverrides.
+func ReadOverridesFile(path string) ([]Override, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	overrides, err := ParseOverrides(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid overrides file %q: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// newOverride returns the override described by the `path` and `condition`
+// values in `fields`.
+func newOverride(fields map[string]string) (Override, error) {
+	path, ok := fields["path"]
+	if !ok || len(path) == 0 {
+		return Override{}, fmt.Errorf("missing path")
+	}
+	name, ok := fields["condition"]
+	if !ok || len(name) == 0 {
+		return Override{}, fmt.Errorf("missing condition for %q", path)
+	}
+	lc, ok := Conditions.Condition(name)
+	if !ok {
+		return Override{}, fmt.Errorf("unrecognized condition %q for %q", name, path)
+	}
+	return Override{path, NewLicenseConditionSet(lc)}, nil
+}
+
+// addYAMLField adds the `key: value` pair in `text` to `fields`.
+func addYAMLField(fields map[string]string, text string) error {
+	key, value, ok := strings.Cut(text, ":")
+	if !ok {
+		return fmt.Errorf("want key: value, got %q", text)
+	}
+	key = strings.TrimSpace(key)
+	if key != "path" && key != "condition" {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	if _, ok := fields[key]; ok {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	value, err := unquoteYAML(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	fields[key] = value
+	return nil
+}
+
+// unquoteYAML returns the value of a plain, single-quoted, or double-quoted
+// YAML scalar.
+func unquoteYAML(s string) (string, error) {
+	if strings.HasPrefix(s, "\"") {
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", s)
+		}
+		return v, nil
+	}
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid quoted value %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
+
+// stripYAMLComment removes a `#` comment outside of quotes from `line`.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitYAMLFlow splits the body of a flow mapping at the commas outside of
+// quotes.
+func splitYAMLFlow(body string) []string {
+	var pairs []string
+	var quote byte
+	begin := 0
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			pairs = append(pairs, strings.TrimSpace(body[begin:i]))
+			begin = i + 1
+		}
+	}
+	if last := strings.TrimSpace(body[begin:]); len(last) > 0 {
+		pairs = append(pairs, last)
+	}
+	return pairs
+}
+
+// ApplyOverrides returns a copy of `lg` with the license conditions of the
+// targets named by `overrides` replaced. `lg` itself is left unchanged.
+//
+// Overrides replace only the conditions originating at the targets.
+// Overrides naming targets outside the graph are ignored. When several overrides name the same
+// target, the last one wins.
+//
+// Must be called before resolving or walking the graph.
+func ApplyOverrides(lg *LicenseGraph, overrides []Override) *LicenseGraph {
+	conditions := make(map[string]LicenseConditionSet)
+	for _, o := range overrides {
+		conditions[o.Path] = o.Conditions
+	}
+
+	result := &LicenseGraph{
+		rootFiles:          append([]string{}, lg.rootFiles...),
+		edges:              make(TargetEdgeList, 0, len(lg.edges)),
+		targets:            make(map[string]*TargetNode, len(lg.targets)),
+		copyleftStaticOnly: lg.copyleftStaticOnly,
+		trace:              lg.trace,
+		progress:           lg.progress,
+	}
+	for name, tn := range lg.targets {
+		ntn := &TargetNode{
+			lg:                result,
+			name:              tn.name,
+			licenseConditions: tn.licenseConditions,
+		}
+		proto.Merge(&ntn.proto, &tn.proto)
+		if cs, ok := conditions[name]; ok {
+			ntn.licenseConditions = cs
+		}
+		result.targets[name] = ntn
+	}
+	edges := make(map[*TargetEdge]*TargetEdge, len(lg.edges))
+	for _, e := range lg.edges {
+		ne := &TargetEdge{result.targets[e.target.name], result.targets[e.dependency.name], e.annotations, e.depType, e.linkage, e.skipped}
+		edges[e] = ne
+		result.edges = append(result.edges, ne)
+	}
+	for _, tn := range lg.targets {
+		ntn := result.targets[tn.name]
+		ntn.edges = make(TargetEdgeList, 0, len(tn.edges))
+		for _, e := range tn.edges {
+			ntn.edges = append(ntn.edges, edges[e])
+		}
+	}
+	return result
+}
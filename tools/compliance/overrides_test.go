@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOverrides(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "empty",
+			input:    "# nothing to override\n",
+			expected: []string{},
+		},
+		{
+			name:     "emptysequence",
+			input:    "[]\n",
+			expected: []string{},
+		},
+		{
+			name:     "flow",
+			input:    "- {path: \"lib/liba.so.meta_lic\", condition: \"notice\"}\n",
+			expected: []string{"lib/liba.so.meta_lic: notice"},
+		},
+		{
+			name: "block",
+			input: "---\n" +
+				"# upstream mistake\n" +
+				"- path: lib/liba.so.meta_lic  # a comment\n" +
+				"  condition: notice\n" +
+				"-\n" +
+				"  condition: 'restricted'\n" +
+				"  path: 'lib/it''s.meta_lic'\n",
+			expected: []string{"lib/liba.so.meta_lic: notice", "lib/it's.meta_lic: restricted"},
+		},
+		{
+			name:     "quotedhash",
+			input:    "- {path: \"lib/#1.meta_lic\", condition: permissive} # trailing\n",
+			expected: []string{"lib/#1.meta_lic: permissive"},
+		},
+		{
+			name:          "unrecognized",
+			input:         "- {path: lib/liba.so.meta_lic, condition: free}\n",
+			expectedError: "line 1: unrecognized condition \"free\"",
+		},
+		{
+			name:          "missingpath",
+			input:         "- condition: notice\n",
+			expectedError: "line 1: missing path",
+		},
+		{
+			name:          "missingcondition",
+			input:         "- path: lib/liba.so.meta_lic\n- path: lib/libb.so.meta_lic\n  condition: notice\n",
+			expectedError: "line 1: missing condition",
+		},
+		{
+			name:          "unknownkey",
+			input:         "- path: lib/liba.so.meta_lic\n  conditions: notice\n",
+			expectedError: "line 2: unknown key \"conditions\"",
+		},
+		{
+			name:          "duplicatekey",
+			input:         "- {path: a, path: b, condition: notice}\n",
+			expectedError: "line 1: duplicate key \"path\"",
+		},
+		{
+			name:          "notsequence",
+			input:         "path: lib/liba.so.meta_lic\n",
+			expectedError: "line 1: want sequence entry",
+		},
+		{
+			name:          "unterminated",
+			input:         "- {path: lib/liba.so.meta_lic, condition: notice\n",
+			expectedError: "line 1: unterminated flow mapping",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := ParseOverrides(strings.NewReader(tt.input))
+			if err != nil {
+				if len(tt.expectedError) == 0 {
+					t.Errorf("unexpected error: got %s, want no error", err)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("unexpected error: got %s, want %q", err, tt.expectedError)
+				}
+				return
+			}
+			if len(tt.expectedError) > 0 {
+				t.Fatalf("unexpected success: got no error, want %q err", tt.expectedError)
+			}
+			actual := []string{}
+			for _, o := range overrides {
+				actual = append(actual, o.String())
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("unexpected overrides: got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	lg, err := ReadLicenseGraph(GetFS(""), &bytes.Buffer{}, []string{"testdata/restricted/container.zip.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	libb := "testdata/restricted/lib/libb.so.meta_lic"
+	overrides := []Override{
+		{libb, NewLicenseConditionSet(NoticeCondition)},
+		{"testdata/restricted/lib/missing.meta_lic", NewLicenseConditionSet(NoticeCondition)},
+	}
+
+	result := ApplyOverrides(lg, overrides)
+
+	if actual := lg.targets[libb].LicenseConditions().Names(); !reflect.DeepEqual(actual, []string{"restricted"}) {
+		t.Errorf("unexpected original conditions: got %q, want [\"restricted\"]", actual)
+	}
+	if actual := result.targets[libb].LicenseConditions().Names(); !reflect.DeepEqual(actual, []string{"notice"}) {
+		t.Errorf("unexpected overridden conditions: got %q, want [\"notice\"]", actual)
+	}
+	if len(result.Targets()) != len(lg.Targets()) || len(result.Edges()) != len(lg.Edges()) {
+		t.Errorf("unexpected graph: got %d targets %d edges, want %d targets %d edges", len(result.Targets()), len(result.Edges()), len(lg.Targets()), len(lg.Edges()))
+	}
+	for i, e := range result.Edges() {
+		original := lg.Edges()[i]
+		if e.Target().Name() != original.Target().Name() || e.Dependency().Name() != original.Dependency().Name() {
+			t.Errorf("unexpected edge %d: got %s, want %s", i, e, original)
+		}
+		if e.Target() != result.targets[e.Target().Name()] || e.Dependency() != result.targets[e.Dependency().Name()] {
+			t.Errorf("edge %d refers to targets outside the copy", i)
+		}
+	}
+	if _, ok := result.targets["testdata/restricted/lib/missing.meta_lic"]; ok {
+		t.Errorf("unexpected target for override outside the graph")
+	}
+
+	shared := func(lg *LicenseGraph) []string {
+		var cs LicenseConditionSet
+		rs := ResolveSourceSharing(lg)
+		for _, tn := range rs.AttachesTo() {
+			for _, r := range rs.Resolutions(tn) {
+				cs = cs.Union(r.Resolves())
+			}
+		}
+		return cs.Names()
+	}
+	if actual := shared(lg); !reflect.DeepEqual(actual, []string{"reciprocal", "restricted", "restricted_if_statically_linked"}) {
+		t.Errorf("unexpected original sharing conditions: got %q, want reciprocal, restricted and restricted_if_statically_linked", actual)
+	}
+	if actual := shared(result); !reflect.DeepEqual(actual, []string{"reciprocal", "restricted_if_statically_linked"}) {
+		t.Errorf("unexpected overridden sharing conditions: got %q, want reciprocal and restricted_if_statically_linked", actual)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathTransform maps an install path computed from the license graph to the
+// install path the notice index records, or returns false to drop the path
+// entirely.
+type PathTransform func(installPath string) (string, bool)
+
+// DefaultPathTransform keeps every install path as computed from the license
+// graph, i.e. the installed path of the target or, for the contents of a
+// container, the container path followed by the path within the container.
+func DefaultPathTransform(installPath string) (string, bool) {
+	return installPath, true
+}
+
+// ChainPathTransforms returns the transform applying each of `transforms` in
+// order and dropping any path dropped by any of them. Nil transforms get
+// skipped.
+func ChainPathTransforms(transforms ...PathTransform) PathTransform {
+	return func(installPath string) (string, bool) {
+		for _, transform := range transforms {
+			if transform == nil {
+				continue
+			}
+			var ok bool
+			installPath, ok = transform(installPath)
+			if !ok {
+				return "", false
+			}
+		}
+		return installPath, true
+	}
+}
+
+// StripPrefixTransform returns the transform removing the first of
+// `prefixes` matching an install path per StripPrefix.
+func StripPrefixTransform(prefixes []string, product string) PathTransform {
+	return func(installPath string) (string, bool) {
+		return StripPrefix(installPath, prefixes, product), true
+	}
+}
+
+// FilterPrefixTransform returns the transform dropping the install paths
+// not starting with any of `prefixes` on a path component boundary, or
+// keeping every path when `prefixes` is empty.
+func FilterPrefixTransform(prefixes []string) PathTransform {
+	return func(installPath string) (string, bool) {
+		if len(prefixes) == 0 {
+			return installPath, true
+		}
+		for _, prefix := range prefixes {
+			if hasPathPrefix(installPath, prefix) {
+				return installPath, true
+			}
+		}
+		return "", false
+	}
+}
+
+// RewritePrefixTransform returns the transform replacing the first matching
+// "from" prefix of an install path with its "to" prefix for each of
+// `rewrites` given as "from=to", e.g. "out/dist/images/=out/target/product/".
+//
+// Returns an error for any rewrite not of the form "from=to" with a non-empty
+// "from".
+func RewritePrefixTransform(rewrites []string) (PathTransform, error) {
+	type rewrite struct {
+		from, to string
+	}
+	parsed := make([]rewrite, 0, len(rewrites))
+	for _, r := range rewrites {
+		from, to, ok := strings.Cut(r, "=")
+		if !ok || len(from) == 0 {
+			return nil, fmt.Errorf("invalid prefix rewrite %q: want from=to", r)
+		}
+		parsed = append(parsed, rewrite{from, to})
+	}
+	return func(installPath string) (string, bool) {
+		for _, r := range parsed {
+			if hasPathPrefix(installPath, r.from) {
+				return r.to + strings.TrimPrefix(installPath, r.from), true
+			}
+		}
+		return installPath, true
+	}, nil
+}
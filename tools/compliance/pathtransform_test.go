@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"testing"
+)
+
+func TestPathTransforms(t *testing.T) {
+	rewrite, err := RewritePrefixTransform([]string{"out/dist/images=out/target/product/fictional", "out/dist=out"})
+	if err != nil {
+		t.Fatalf("RewritePrefixTransform(): got error %s, want no error", err)
+	}
+	tests := []struct {
+		name         string
+		transform    PathTransform
+		installPath  string
+		expectedPath string
+		expectedOk   bool
+	}{
+		{"default", DefaultPathTransform, "out/target/product/fictional/system/bin/bin1", "out/target/product/fictional/system/bin/bin1", true},
+		{"strip", StripPrefixTransform([]string{"out/target/product/fictional/"}, ""), "out/target/product/fictional/system/bin/bin1", "system/bin/bin1", true},
+		{"stripproduct", StripPrefixTransform([]string{"out/target/product/fictional"}, "fictional"), "out/target/product/fictional", "fictional", true},
+		{"filternone", FilterPrefixTransform(nil), "system/bin/bin1", "system/bin/bin1", true},
+		{"filterkeeps", FilterPrefixTransform([]string{"vendor", "system"}), "system/bin/bin1", "system/bin/bin1", true},
+		{"filterdrops", FilterPrefixTransform([]string{"system/bin"}), "system/binary/bin1", "", false},
+		{"rewrite", rewrite, "out/dist/images/system/bin/bin1", "out/target/product/fictional/system/bin/bin1", true},
+		{"rewritefirst", rewrite, "out/dist/system/bin/bin1", "out/system/bin/bin1", true},
+		{"rewriteboundary", rewrite, "out/distro/system/bin/bin1", "out/distro/system/bin/bin1", true},
+		{"chain", ChainPathTransforms(rewrite, nil, StripPrefixTransform([]string{"out/target/product/fictional/"}, ""), FilterPrefixTransform([]string{"system"})), "out/dist/images/system/bin/bin1", "system/bin/bin1", true},
+		{"chaindrops", ChainPathTransforms(FilterPrefixTransform([]string{"vendor"}), DefaultPathTransform), "system/bin/bin1", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := tt.transform(tt.installPath)
+			if actual != tt.expectedPath || ok != tt.expectedOk {
+				t.Errorf("transform(%q): got %q, %t, want %q, %t", tt.installPath, actual, ok, tt.expectedPath, tt.expectedOk)
+			}
+		})
+	}
+}
+
+func TestRewritePrefixTransformErrors(t *testing.T) {
+	for _, rewrite := range []string{"out/dist", "=out/dist"} {
+		if _, err := RewritePrefixTransform([]string{rewrite}); err == nil {
+			t.Errorf("RewritePrefixTransform(%q): got no error, want error", rewrite)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"sort"
+)
+
+// DynamicLinkWarnings implements the policy for dynamic links to restricted
+// dependencies when the graph propagates copyleft along static links only.
+//
+// Returns the dynamic link edges from shipped targets to restricted
+// dependencies where the target gets a `dynamic_link_warning` condition
+// instead of the restricted condition. (sorted)
+func DynamicLinkWarnings(lg *LicenseGraph) TargetEdgeList {
+	ResolveBottomUpConditions(lg)
+	shipped := ShippedNodes(lg)
+
+	var result TargetEdgeList
+	for _, e := range lg.Edges() {
+		if !edgeIsDynamicLink(e) || !shipped.Contains(e.target) {
+			continue
+		}
+		if !e.target.resolution.HasAny(DynamicLinkWarningCondition) {
+			continue
+		}
+		if !e.dependency.resolution.HasAny(RestrictedCondition) {
+			continue
+		}
+		result = append(result, e)
+	}
+	sort.Sort(result)
+	return result
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+func TestDynamicLinkWarnings(t *testing.T) {
+	edges := []annotated{
+		{"apacheBin.meta_lic", "gplLib.meta_lic", []string{"static"}},
+		{"apacheBin.meta_lic", "gplBin.meta_lic", []string{"dynamic"}},
+		{"apacheBin.meta_lic", "gplContainer.meta_lic", []string{"shared_library"}},
+		{"apacheBin.meta_lic", "lgplLib.meta_lic", []string{"dynamic"}},
+	}
+	tests := []struct {
+		name               string
+		copyleftStaticOnly bool
+		expectedActions    []tcond
+		expectedWarnings   []string
+	}{
+		{
+			name: "default",
+			expectedActions: []tcond{
+				{"apacheBin.meta_lic", "notice|restricted"},
+				{"gplLib.meta_lic", "restricted"},
+				{"gplBin.meta_lic", "restricted"},
+				{"gplContainer.meta_lic", "restricted"},
+				{"lgplLib.meta_lic", "restricted_if_statically_linked"},
+			},
+		},
+		{
+			name:               "staticonly",
+			copyleftStaticOnly: true,
+			expectedActions: []tcond{
+				{"apacheBin.meta_lic", "notice|restricted|dynamic_link_warning"},
+				{"gplLib.meta_lic", "restricted"},
+				{"gplBin.meta_lic", "restricted"},
+				{"gplContainer.meta_lic", "restricted"},
+				{"lgplLib.meta_lic", "restricted_if_statically_linked"},
+			},
+			expectedWarnings: []string{
+				"apacheBin.meta_lic -[dynamic]> gplBin.meta_lic",
+				"apacheBin.meta_lic -[shared_library]> gplContainer.meta_lic",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			lg, err := toGraph(stderr, []string{"apacheBin.meta_lic"}, edges)
+			if err != nil {
+				t.Fatalf("unexpected test data error: got %s, want no error", err)
+			}
+			if tt.copyleftStaticOnly {
+				lg.PropagateCopyleftStaticOnly()
+			}
+
+			logGraph(lg, t)
+
+			warnings := DynamicLinkWarnings(lg)
+			actual := asActionList(lg)
+			sort.Sort(actual)
+			t.Logf("actual: %s", actual.String())
+
+			expected := toActionList(lg, tt.expectedActions)
+			sort.Sort(expected)
+			t.Logf("expected: %s", expected.String())
+
+			if len(actual) != len(expected) {
+				t.Errorf("unexpected number of actions: got %d, want %d", len(actual), len(expected))
+			} else {
+				for i := 0; i < len(actual); i++ {
+					if actual[i] != expected[i] {
+						t.Errorf("unexpected action at index %d: got %s, want %s", i, actual[i].String(), expected[i].String())
+					}
+				}
+			}
+
+			if len(warnings) != len(tt.expectedWarnings) {
+				t.Fatalf("unexpected number of warnings: got %d, want %d", len(warnings), len(tt.expectedWarnings))
+			}
+			for i, e := range warnings {
+				if e.String() != tt.expectedWarnings[i] {
+					t.Errorf("unexpected warning at index %d: got %q, want %q", i, e.String(), tt.expectedWarnings[i])
+				}
+			}
+		})
+	}
+}
@@ -24,9 +24,13 @@ var (
 	// meaning for compliance policy.
 	RecognizedAnnotations = map[string]string{
 		// used in readgraph.go to avoid creating 1000's of copies of the below 3 strings.
-		"static":    "static",
-		"dynamic":   "dynamic",
-		"toolchain": "toolchain",
+		"static":         "static",
+		"dynamic":        "dynamic",
+		"toolchain":      "toolchain",
+		"runtime":        "runtime",
+		"buildtime":      "buildtime",
+		"test":           "test",
+		"shared_library": "shared_library",
 	}
 
 	// safePathPrefixes maps the path prefixes presumed not to contain any
@@ -84,6 +88,26 @@ var (
 	ImpliesShared = LicenseConditionSet(ReciprocalCondition | RestrictedCondition | WeaklyRestrictedCondition)
 )
 
+var (
+	// spdxLicenseConditions maps regular expressions matching SPDX license
+	// identifiers to the condition each implies. The first match wins.
+	//
+	// Identifiers matching none get ByExceptionOnlyCondition so that policy
+	// requires review before use.
+	spdxLicenseConditions = []spdxLicenseConditionsType{
+		{regexp.MustCompile(`(?i)^(?:CC0-1\.0|Unlicense|0BSD|CC-PDDC)$`), UnencumberedCondition},
+		{regexp.MustCompile(`(?i)^LGPL-`), WeaklyRestrictedCondition},
+		{regexp.MustCompile(`(?i)^(?:A?GPL-|CC-BY(?:-NC)?(?:-ND)?-SA-)`), RestrictedCondition},
+		{regexp.MustCompile(`(?i)^(?:MPL-|EPL-|CDDL-|CPL-|APSL-|MS-RL$)`), ReciprocalCondition},
+		{regexp.MustCompile(`(?i)^(?:Apache-|MIT|BSD-|ISC$|Zlib$|BSL-1\.0$|PSF-|Python-|OpenSSL$|Unicode-|X11$|FTL$|ICU$|NCSA$|Libpng|curl$|W3C|CC-BY-[0-9])`), NoticeCondition},
+	}
+)
+
+type spdxLicenseConditionsType struct {
+	re        *regexp.Regexp
+	condition LicenseCondition
+}
+
 type safePathPrefixesType struct {
 	prefix string
 	strip  bool
@@ -117,7 +141,7 @@ func init() {
 func LicenseConditionSetFromNames(names ...string) LicenseConditionSet {
 	cs := NewLicenseConditionSet()
 	for _, name := range names {
-		if lc, ok := RecognizedConditionNames[name]; ok {
+		if lc, ok := Conditions.Condition(name); ok {
 			cs |= LicenseConditionSet(lc)
 		}
 	}
@@ -146,7 +170,9 @@ func LicenseConditionSetFromNames(names ...string) LicenseConditionSet {
 // The first function controls what happens during the bottom-up propagation.
 // Restricted conditions propagate up all non-toolchain dependencies; except,
 // some do not propagate up dynamic links, which may depend on whether the
-// modules are independent.
+// modules are independent. When the graph propagates copyleft along static
+// links only, a target dynamically linking a restricted dependency gets a
+// dynamic link warning instead.
 //
 // The second function controls what happens during the top-down propagation.
 // Restricted conditions propagate down as above with the added caveat that
@@ -157,10 +183,15 @@ func LicenseConditionSetFromNames(names ...string) LicenseConditionSet {
 // depending on the types of dependencies involved. All conditions apply across
 // normal derivation dependencies. No conditions apply across toolchain
 // dependencies. Some restricted conditions apply across dynamic link
-// dependencies.
+// dependencies unless the graph propagates copyleft along static links only.
 //
 // Not all restricted licenses are create equal. Some have special rules or
 // exceptions. e.g. LGPL or "with classpath excption".
+//
+// Conditions registered with the Conditions registry propagate according to
+// their PropagationRules like the built-in restricted conditions: conditions
+// propagating across derivations behave like restricted_if_statically_linked
+// and those also propagating across dynamic links behave like restricted.
 
 // depConditionsPropagatingToTarget returns the conditions which propagate up an
 // edge from dependency to target.
@@ -173,16 +204,24 @@ func LicenseConditionSetFromNames(names ...string) LicenseConditionSet {
 // that derivative work. The `treatAsAggregate` parameter will be false for
 // non-aggregates and for aggregates in non-aggregate contexts.
 func depConditionsPropagatingToTarget(lg *LicenseGraph, e *TargetEdge, depConditions LicenseConditionSet, treatAsAggregate bool) LicenseConditionSet {
+	static, dynamic, _, _ := Conditions.propagation()
 	result := LicenseConditionSet(0x0000)
 	if edgeIsDerivation(e) {
-		result |= depConditions & ImpliesRestricted
+		result |= depConditions & static
 		return result
 	}
 	if !edgeIsDynamicLink(e) {
 		return result
 	}
 
-	result |= depConditions & LicenseConditionSet(RestrictedCondition)
+	if lg.copyleftStaticOnly {
+		if depConditions.HasAny(RestrictedCondition) {
+			result |= LicenseConditionSet(DynamicLinkWarningCondition)
+		}
+		return result
+	}
+
+	result |= depConditions & dynamic
 	return result
 }
 
@@ -197,31 +236,53 @@ func depConditionsPropagatingToTarget(lg *LicenseGraph, e *TargetEdge, depCondit
 // that derivative work. The `treatAsAggregate` parameter will be false for
 // non-aggregates and for aggregates in non-aggregate contexts.
 func targetConditionsPropagatingToDep(lg *LicenseGraph, e *TargetEdge, targetConditions LicenseConditionSet, treatAsAggregate bool, conditionsFn TraceConditions) LicenseConditionSet {
+	static, dynamic, local, _ := Conditions.propagation()
 	result := targetConditions
 
 	// reverse direction -- none of these apply to things depended-on, only to targets depending-on.
-	result = result.Minus(UnencumberedCondition, PermissiveCondition, NoticeCondition, ReciprocalCondition, ProprietaryCondition, ByExceptionOnlyCondition)
+	result = result.Minus(UnencumberedCondition, PermissiveCondition, NoticeCondition, ReciprocalCondition, ProprietaryCondition, ByExceptionOnlyCondition, DynamicLinkWarningCondition)
+	result = result.Difference(local)
 
 	if !edgeIsDerivation(e) && !edgeIsDynamicLink(e) {
 		// target is not a derivative work of dependency and is not linked to dependency
-		result = result.Difference(ImpliesRestricted)
+		result = result.Difference(static)
 		return result
 	}
 	if treatAsAggregate {
 		// If the author of a pure aggregate licenses it restricted, apply restricted to immediate dependencies.
 		// Otherwise, restricted does not propagate back down to dependencies.
-		if !conditionsFn(e.target).MatchesAnySet(ImpliesRestricted) {
-			result = result.Difference(ImpliesRestricted)
+		if !conditionsFn(e.target).MatchesAnySet(static) {
+			result = result.Difference(static)
 		}
 		return result
 	}
 	if edgeIsDerivation(e) {
 		return result
 	}
-	result = result.Minus(WeaklyRestrictedCondition)
+	if lg.copyleftStaticOnly {
+		// restricted conditions propagate only along static links.
+		result = result.Difference(static)
+		return result
+	}
+	result = result.Difference(static.Difference(dynamic))
 	return result
 }
 
+// overrideConditions returns the `resolved` conditions of a target without
+// the overridable conditions inherited from other targets when `resolved`
+// includes a stricter restricted condition. The target's `own` conditions
+// always apply.
+//
+// This function sets the policy for registered conditions with overridable
+// PropagationRules after both the bottom-up and the top-down propagation.
+func overrideConditions(resolved, own LicenseConditionSet) LicenseConditionSet {
+	_, _, _, overridable := Conditions.propagation()
+	if resolved.MatchesAnySet(ImpliesRestricted) {
+		return resolved.Difference(overridable.Difference(own))
+	}
+	return resolved
+}
+
 // conditionsAttachingAcrossEdge returns the subset of conditions in `universe`
 // that apply across edge `e`.
 //
@@ -232,24 +293,28 @@ func conditionsAttachingAcrossEdge(lg *LicenseGraph, e *TargetEdge, universe Lic
 	if edgeIsDerivation(e) {
 		return result
 	}
-	if !edgeIsDynamicLink(e) {
+	if !edgeIsDynamicLink(e) || lg.copyleftStaticOnly {
 		return NewLicenseConditionSet()
 	}
 
-	result &= LicenseConditionSet(RestrictedCondition)
+	_, dynamic, _, _ := Conditions.propagation()
+	result &= dynamic
 	return result
 }
 
 // edgeIsDynamicLink returns true for edges representing shared libraries
 // linked dynamically at runtime.
 func edgeIsDynamicLink(e *TargetEdge) bool {
-	return e.annotations.HasAnnotation("dynamic")
+	return !e.skipped && e.linkage != LinkageStatic
 }
 
 // edgeIsDerivation returns true for edges where the target is a derivative
 // work of dependency.
+//
+// Skipped `buildtime` and `test` edges are neither derivations nor dynamic
+// links.
 func edgeIsDerivation(e *TargetEdge) bool {
-	isDynamic := e.annotations.HasAnnotation("dynamic")
+	isDynamic := e.linkage != LinkageStatic
 	isToolchain := e.annotations.HasAnnotation("toolchain")
-	return !isDynamic && !isToolchain
+	return !e.skipped && !isDynamic && !isToolchain
 }
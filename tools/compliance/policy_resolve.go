@@ -53,11 +53,14 @@ func TraceBottomUpConditions(lg *LicenseGraph, conditionsFn TraceConditions) {
 
 		walk = func(target *TargetNode, treatAsAggregate bool) LicenseConditionSet {
 			priorWalkResults := func() (LicenseConditionSet, bool) {
+				lg.walkLookups++
 				if _, alreadyWalked := amap[target]; alreadyWalked {
 					if treatAsAggregate {
+						lg.walkHits++
 						return target.resolution, true
 					}
 					if !target.pure {
+						lg.walkHits++
 						return target.resolution, true
 					}
 					// previously walked in a pure aggregate context,
@@ -84,6 +87,7 @@ func TraceBottomUpConditions(lg *LicenseGraph, conditionsFn TraceConditions) {
 				cs |= dcs
 			}
 			target.resolution |= cs
+			target.resolution = overrideConditions(target.resolution, conditionsFn(target))
 			cs = target.resolution
 
 			// return conditions up the tree
@@ -125,8 +129,10 @@ func TraceTopDownConditions(lg *LicenseGraph, conditionsFn TraceConditions) {
 
 		walk = func(fnode *TargetNode, cs LicenseConditionSet, treatAsAggregate bool) {
 			continueWalk := func() bool {
+				lg.walkLookups++
 				if _, alreadyWalked := amap[fnode]; alreadyWalked {
 					if cs.IsEmpty() {
+						lg.walkHits++
 						return false
 					}
 					if cs.Difference(fnode.resolution).IsEmpty() {
@@ -134,10 +140,12 @@ func TraceTopDownConditions(lg *LicenseGraph, conditionsFn TraceConditions) {
 
 						// pure aggregates never need walking a 2nd time with same conditions
 						if treatAsAggregate {
+							lg.walkHits++
 							return false
 						}
 						// non-aggregates don't need walking as non-aggregate a 2nd time
 						if !fnode.pure {
+							lg.walkHits++
 							return false
 						}
 						// previously walked as pure aggregate; need to re-walk as non-aggregate
@@ -146,6 +154,7 @@ func TraceTopDownConditions(lg *LicenseGraph, conditionsFn TraceConditions) {
 					fnode.resolution |= conditionsFn(fnode)
 				}
 				fnode.resolution |= cs
+				fnode.resolution = overrideConditions(fnode.resolution, conditionsFn(fnode))
 				fnode.pure = treatAsAggregate
 				amap[fnode] = struct{}{}
 				cs = fnode.resolution
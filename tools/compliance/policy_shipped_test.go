@@ -26,6 +26,7 @@ func TestShippedNodes(t *testing.T) {
 		name          string
 		roots         []string
 		edges         []annotated
+		skipBuildtime bool
 		expectedNodes []string
 	}{
 		{
@@ -102,6 +103,50 @@ func TestShippedNodes(t *testing.T) {
 				"apacheLib.meta_lic",
 			},
 		},
+		{
+			name:  "buildtime",
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "apacheLib.meta_lic", []string{"static", "runtime"}},
+				{"apacheBin.meta_lic", "gplBin.meta_lic", []string{"static", "buildtime"}},
+				{"apacheBin.meta_lic", "mitLib.meta_lic", []string{"static", "test"}},
+			},
+			expectedNodes: []string{
+				"apacheBin.meta_lic",
+				"apacheLib.meta_lic",
+				"gplBin.meta_lic",
+				"mitLib.meta_lic",
+			},
+		},
+		{
+			name:  "skipbuildtime",
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "apacheLib.meta_lic", []string{"static", "runtime"}},
+				{"apacheBin.meta_lic", "gplBin.meta_lic", []string{"static", "buildtime"}},
+				{"apacheBin.meta_lic", "mitLib.meta_lic", []string{"static", "test"}},
+			},
+			skipBuildtime: true,
+			expectedNodes: []string{
+				"apacheBin.meta_lic",
+				"apacheLib.meta_lic",
+			},
+		},
+		{
+			name:  "skiptoolchain",
+			roots: []string{"apacheContainer.meta_lic"},
+			edges: []annotated{
+				{"apacheContainer.meta_lic", "apacheBin.meta_lic", []string{"static"}},
+				{"apacheBin.meta_lic", "gplLib.meta_lic", []string{"static", "toolchain"}},
+				{"apacheBin.meta_lic", "apacheLib.meta_lic", []string{"static"}},
+			},
+			skipBuildtime: true,
+			expectedNodes: []string{
+				"apacheContainer.meta_lic",
+				"apacheBin.meta_lic",
+				"apacheLib.meta_lic",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -111,6 +156,9 @@ func TestShippedNodes(t *testing.T) {
 				t.Errorf("unexpected test data error: got %s, want no error", err)
 				return
 			}
+			if tt.skipBuildtime {
+				lg.SkipBuildtimeDeps()
+			}
 			t.Logf("graph:")
 			for _, edge := range lg.Edges() {
 				t.Logf("  %s", edge.String())
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"sort"
+)
+
+// DisclosureRequirement describes a target whose source must accompany the
+// distribution of the targets it gets built into.
+type DisclosureRequirement struct {
+	// Target identifies the path to the license metadata file of the target
+	// whose source must be disclosed.
+	Target string `json:"target"`
+
+	// Condition names the strongest license condition triggering the
+	// disclosure.
+	Condition string `json:"condition"`
+
+	// Sources lists the source files recorded in the license metadata for the
+	// target. (sorted)
+	Sources []string `json:"sources"`
+
+	// Projects lists the projects defining the target. (sorted)
+	Projects []string `json:"projects"`
+
+	// WrittenOffer is true when policy allows a written offer to provide the
+	// source instead of including the source with the distribution.
+	WrittenOffer bool `json:"writtenOffer"`
+}
+
+// disclosureConditionPrecedence orders the source-sharing conditions from
+// strongest to weakest for choosing the condition that triggers disclosure.
+var disclosureConditionPrecedence = []LicenseCondition{
+	RestrictedCondition,
+	WeaklyRestrictedCondition,
+	ReciprocalCondition,
+}
+
+// SourceDisclosureReport returns the list of targets in `lg` whose source
+// must be disclosed ordered by target name.
+//
+// Restricted conditions (e.g. GPL and LGPL) permit a written offer to provide
+// the source. Reciprocal conditions (e.g. MPL) require the source to be made
+// available directly.
+func SourceDisclosureReport(lg *LicenseGraph) []DisclosureRequirement {
+	rs := ResolveSourceSharing(lg)
+
+	// actions maps the targets acted on to the conditions resolved.
+	actions := make(ActionSet)
+	for _, attachesTo := range rs.AttachesTo() {
+		if rs.IsPureAggregate(attachesTo) && !attachesTo.LicenseConditions().MatchesAnySet(ImpliesShared) {
+			continue
+		}
+		for _, r := range rs.Resolutions(attachesTo) {
+			actions[r.actsOn] = actions[r.actsOn].Union(r.cs)
+		}
+	}
+
+	targets := make(TargetNodeList, 0, len(actions))
+	for tn := range actions {
+		targets = append(targets, tn)
+	}
+	sort.Sort(targets)
+
+	result := make([]DisclosureRequirement, 0, len(targets))
+	for _, tn := range targets {
+		cs := actions[tn].Intersection(ImpliesShared)
+		if cs.IsEmpty() {
+			continue
+		}
+		var condition LicenseCondition
+		for _, lc := range disclosureConditionPrecedence {
+			if cs.HasAny(lc) {
+				condition = lc
+				break
+			}
+		}
+		sources := tn.Sources()
+		sort.Strings(sources)
+		projects := tn.Projects()
+		sort.Strings(projects)
+		result = append(result, DisclosureRequirement{
+			Target:       tn.name,
+			Condition:    condition.Name(),
+			Sources:      sources,
+			Projects:     projects,
+			WrittenOffer: condition != ReciprocalCondition,
+		})
+	}
+	return result
+}
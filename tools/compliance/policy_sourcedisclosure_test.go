@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSourceDisclosureReport(t *testing.T) {
+	type disclosure struct {
+		target       string
+		condition    string
+		writtenOffer bool
+	}
+	tests := []struct {
+		name     string
+		roots    []string
+		edges    []annotated
+		expected []disclosure
+	}{
+		{
+			name:  "noticeonly",
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "mitLib.meta_lic", []string{"static"}},
+			},
+			expected: []disclosure{},
+		},
+		{
+			name:  "gplonfpstatic",
+			roots: []string{"gplBin.meta_lic"},
+			edges: []annotated{
+				{"gplBin.meta_lic", "apacheLib.meta_lic", []string{"static"}},
+			},
+			expected: []disclosure{
+				{"apacheLib.meta_lic", "restricted", true},
+				{"gplBin.meta_lic", "restricted", true},
+			},
+		},
+		{
+			name:  "lgplonfpdynamic",
+			roots: []string{"lgplBin.meta_lic"},
+			edges: []annotated{
+				{"lgplBin.meta_lic", "apacheLib.meta_lic", []string{"dynamic"}},
+			},
+			expected: []disclosure{
+				{"lgplBin.meta_lic", "restricted_if_statically_linked", true},
+			},
+		},
+		{
+			name:  "mplonfpstatic",
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "mplLib.meta_lic", []string{"static"}},
+			},
+			expected: []disclosure{
+				{"mplLib.meta_lic", "reciprocal", false},
+			},
+		},
+		{
+			name:  "toolchaingpl",
+			roots: []string{"apacheBin.meta_lic"},
+			edges: []annotated{
+				{"apacheBin.meta_lic", "gplLib.meta_lic", []string{"toolchain"}},
+			},
+			expected: []disclosure{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			lg, err := toGraph(stderr, tt.roots, tt.edges)
+			if err != nil {
+				t.Errorf("unexpected test data error: got %s, want no error", err)
+				return
+			}
+			actual := SourceDisclosureReport(lg)
+			t.Logf("actual: %v", actual)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("len(SourceDisclosureReport()): got %d, want %d", len(actual), len(tt.expected))
+			}
+			for i, dr := range actual {
+				if dr.Target != tt.expected[i].target {
+					t.Errorf("SourceDisclosureReport()[%d].Target: got %q, want %q", i, dr.Target, tt.expected[i].target)
+				}
+				if dr.Condition != tt.expected[i].condition {
+					t.Errorf("SourceDisclosureReport()[%d].Condition: got %q, want %q", i, dr.Condition, tt.expected[i].condition)
+				}
+				if dr.WrittenOffer != tt.expected[i].writtenOffer {
+					t.Errorf("SourceDisclosureReport()[%d].WrittenOffer: got %v, want %v", i, dr.WrittenOffer, tt.expected[i].writtenOffer)
+				}
+			}
+			data, err := json.Marshal(actual)
+			if err != nil {
+				t.Fatalf("json.Marshal(): got error %s, want no error", err)
+			}
+			var roundTrip []DisclosureRequirement
+			if err := json.Unmarshal(data, &roundTrip); err != nil {
+				t.Fatalf("json.Unmarshal(): got error %s, want no error", err)
+			}
+			if len(roundTrip) != len(actual) {
+				t.Errorf("json round trip: got %d requirements, want %d", len(roundTrip), len(actual))
+			}
+		})
+	}
+}
@@ -156,6 +156,9 @@ func WalkResolutionsForCondition(lg *LicenseGraph, conditions LicenseConditionSe
 		if universe.IsEmpty() {
 			return false
 		}
+		if lg.trace != nil && len(path) > 0 {
+			lg.trace(path[len(path)-1].edge, tn.resolution.Intersection(universe))
+		}
 		key := resolutionKey{tn, universe}
 
 		if _, alreadyWalked := cmap[key]; alreadyWalked {
@@ -25,9 +25,14 @@ import (
 func TestMain(m *testing.M) {
 	// Change into the cmd directory before running the tests
 	// so they can find the testdata directory.
-	if err := os.Chdir("cmd"); err != nil {
-		fmt.Printf("failed to change to testdata directory: %s\n", err)
-		os.Exit(1)
+	//
+	// Fuzzing workers start in the directory of the coordinating process,
+	// which has already changed into the cmd directory.
+	if _, err := os.Stat("testdata"); err != nil {
+		if err := os.Chdir("cmd"); err != nil {
+			fmt.Printf("failed to change to testdata directory: %s\n", err)
+			os.Exit(1)
+		}
 	}
 	os.Exit(m.Run())
 }
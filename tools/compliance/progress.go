@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressPhase identifies the step of a long-running computation for which
+// progress gets reported.
+type ProgressPhase string
+
+const (
+	// ProgressReadMetadata counts license metadata files read.
+	ProgressReadMetadata = ProgressPhase("read")
+
+	// ProgressHashTexts counts license text files hashed.
+	ProgressHashTexts = ProgressPhase("hashed")
+
+	// ProgressEmitSections counts output sections written.
+	ProgressEmitSections = ProgressPhase("emitted")
+)
+
+// progressUnits maps each phase to the units it counts.
+var progressUnits = map[ProgressPhase]string{
+	ProgressReadMetadata: "meta_lic files",
+	ProgressHashTexts:    "license texts",
+	ProgressEmitSections: "sections",
+}
+
+// ProgressFunc receives progress reports: `done` units of `phase` completed
+// out of `total` known so far, or out of an unknown total when `total` is 0.
+//
+// Reports may arrive from multiple goroutines at once.
+type ProgressFunc func(phase ProgressPhase, done, total int)
+
+// ThrottledProgress writes progress reports as lines to a writer at most once
+// per interval dropping the reports in between.
+//
+// e.g. "read 12/40 meta_lic files" or "hashed 7 license texts"
+type ThrottledProgress struct {
+	// w receives the progress lines.
+	w io.Writer
+	// interval is the minimum time between lines.
+	interval time.Duration
+	// now returns the current time.
+	now func() time.Time
+
+	// mu guards last.
+	mu sync.Mutex
+	// last is the time of the most recent line or zero if none.
+	last time.Time
+}
+
+// NewThrottledProgress returns a ThrottledProgress writing to `w` at most one
+// line per `interval`.
+func NewThrottledProgress(w io.Writer, interval time.Duration) *ThrottledProgress {
+	return &ThrottledProgress{w: w, interval: interval, now: time.Now}
+}
+
+// Report writes a line for `done` units of `phase` out of `total` unless a
+// line was written less than the interval ago. Use as a ProgressFunc.
+func (p *ThrottledProgress) Report(phase ProgressPhase, done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if !p.last.IsZero() && now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	if total > 0 {
+		fmt.Fprintf(p.w, "%s %d/%d %s\n", phase, done, total, progressUnits[phase])
+	} else {
+		fmt.Fprintf(p.w, "%s %d %s\n", phase, done, progressUnits[phase])
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestThrottledProgress(t *testing.T) {
+	w := &bytes.Buffer{}
+	p := NewThrottledProgress(w, time.Second)
+	now := time.Unix(1000, 0)
+	p.now = func() time.Time { return now }
+
+	p.Report(ProgressReadMetadata, 1, 4)
+	now = now.Add(500 * time.Millisecond)
+	p.Report(ProgressReadMetadata, 2, 4)
+	now = now.Add(500 * time.Millisecond)
+	p.Report(ProgressReadMetadata, 4, 4)
+	now = now.Add(999 * time.Millisecond)
+	p.Report(ProgressHashTexts, 1, 0)
+	now = now.Add(time.Millisecond)
+	p.Report(ProgressHashTexts, 2, 0)
+	now = now.Add(time.Hour)
+	p.Report(ProgressEmitSections, 3, 5)
+
+	expected := "read 1/4 meta_lic files\n" +
+		"read 4/4 meta_lic files\n" +
+		"hashed 2 license texts\n" +
+		"emitted 3/5 sections\n"
+	if actual := w.String(); actual != expected {
+		t.Errorf("ThrottledProgress: got %q, want %q", actual, expected)
+	}
+}
+
+func TestReadLicenseGraphProgress(t *testing.T) {
+	fs := &testfs.TestFS{
+		"bin.meta_lic": []byte(AOSP + "license_texts: \"LICENSE\"\n" +
+			"deps: {\n  file: \"liba.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"libb.meta_lic\"\n  annotations: \"static\"\n}\n"),
+		"liba.meta_lic": []byte(MIT + "license_texts: \"MIT_LICENSE\"\n"),
+		"libb.meta_lic": []byte(MIT + "license_texts: \"MIT_LICENSE\"\n"),
+		"LICENSE":       []byte("apache\n"),
+		"MIT_LICENSE":   []byte("mit\n"),
+	}
+	reports := make(map[ProgressPhase][][2]int)
+	progress := func(phase ProgressPhase, done, total int) {
+		reports[phase] = append(reports[phase], [2]int{done, total})
+	}
+
+	lg, err := ReadLicenseGraphWithOptions(context.Background(), fs, &bytes.Buffer{}, []string{"bin.meta_lic"}, ReadOptions{Progress: progress})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	read := reports[ProgressReadMetadata]
+	if len(read) != 3 {
+		t.Fatalf("ReadLicenseGraphWithOptions(): got %d read reports, want 3", len(read))
+	}
+	for i, r := range read {
+		if r[0] != i+1 || r[1] < r[0] {
+			t.Errorf("ReadLicenseGraphWithOptions(): got report %d/%d at index %d, want %d/N", r[0], r[1], i, i+1)
+		}
+	}
+	if last := read[len(read)-1]; last[1] != 3 {
+		t.Errorf("ReadLicenseGraphWithOptions(): got final total %d, want 3", last[1])
+	}
+
+	if _, err := IndexLicenseTexts(fs, lg, nil); err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	hashed := reports[ProgressHashTexts]
+	if len(hashed) != 2 || hashed[0] != [2]int{1, 0} || hashed[1] != [2]int{2, 0} {
+		t.Errorf("IndexLicenseTexts(): got hash reports %v, want [[1 0] [2 0]]", hashed)
+	}
+}
@@ -15,10 +15,13 @@
 package compliance
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -27,15 +30,39 @@ import (
 	"google.golang.org/protobuf/encoding/prototext"
 )
 
+// ConcurrentReadersEnv names the environment variable that sets the default
+// ConcurrentReaders, e.g. COMPLIANCE_CONCURRENT_READERS=4 on machines with a
+// low limit on open files.
+const ConcurrentReadersEnv = "COMPLIANCE_CONCURRENT_READERS"
+
 var (
 	// ConcurrentReaders is the size of the task pool for limiting resource usage e.g. open files.
-	ConcurrentReaders = 5
+	ConcurrentReaders = DefaultConcurrentReaders()
 )
 
+// DefaultConcurrentReaders returns the number of license metadata files to
+// read at once: the value of ConcurrentReadersEnv when set to a positive
+// integer, or else twice GOMAXPROCS up to a quarter of the limit on open
+// files so that the other open files of the process fit too.
+func DefaultConcurrentReaders() int {
+	if n, err := strconv.Atoi(os.Getenv(ConcurrentReadersEnv)); err == nil && n > 0 {
+		return n
+	}
+	n := 2 * runtime.GOMAXPROCS(0)
+	if limit, ok := openFileLimit(); ok && uint64(n) > limit/4 {
+		n = int(limit / 4)
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
 type globalFS struct{}
 
 var _ fs.FS = globalFS{}
 var _ fs.StatFS = globalFS{}
+var _ ReadLinkFS = globalFS{}
 
 func (s globalFS) Open(name string) (fs.File, error) {
 	return os.Open(name)
@@ -45,6 +72,14 @@ func (s globalFS) Stat(name string) (fs.FileInfo, error) {
 	return os.Stat(name)
 }
 
+func (s globalFS) ReadLink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+func (s globalFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
 var FS globalFS
 
 // GetFS returns a filesystem for accessing files under the OUT_DIR environment variable.
@@ -64,7 +99,7 @@ type result struct {
 	target *TargetNode
 
 	// err is nil unless an error occurs
-	err error
+	err *MetadataError
 }
 
 // receiver coordinates the tasks for reading and parsing license metadata files.
@@ -72,34 +107,96 @@ type receiver struct {
 	// lg accumulates the read metadata and becomes the final resulting LicenseGraph.
 	lg *LicenseGraph
 
+	// ctx cancels reading when done.
+	ctx context.Context
+
 	// rootFS locates the root of the file system from which to read the files.
 	rootFS fs.FS
 
 	// stderr identifies the error output writer.
 	stderr io.Writer
 
-	// task provides a fixed-size task pool to limit concurrent open files etc.
-	task chan bool
+	// mu guards queue and pending.
+	mu sync.Mutex
+
+	// ready wakes the workers waiting for a task when one gets queued or
+	// when reading is done or canceled.
+	ready *sync.Cond
+
+	// queue lists the files for the workers to read.
+	queue []readTask
+
+	// pending counts the tasks queued or being read. (guarded by mu)
+	pending int
 
 	// results returns one license metadata file result at a time.
 	results chan *result
 
-	// wg detects when done
+	// wg detects when the workers are done
 	wg sync.WaitGroup
 }
 
+// readTask identifies a license metadata file to read and the root file whose
+// dependencies pulled it in.
+type readTask struct {
+	file, root string
+}
+
 // ReadLicenseGraph reads and parses `files` and their dependencies into a LicenseGraph.
 //
 // `files` become the root files of the graph for top-down walks of the graph.
 func ReadLicenseGraph(rootFS fs.FS, stderr io.Writer, files []string) (*LicenseGraph, error) {
+	return ReadLicenseGraphWithOptions(context.Background(), rootFS, stderr, files, ReadOptions{})
+}
+
+// ReadOptions configures ReadLicenseGraphWithOptions.
+type ReadOptions struct {
+	// Progress receives a report for each file read when not nil. Indexing
+	// the license texts of the resulting graph reports each text hashed to
+	// Progress too.
+	Progress ProgressFunc
+	// KeepGoing keeps reading the files not depending on a malformed file
+	// to report every malformed file, up to MaxErrors, instead of stopping
+	// at the first.
+	KeepGoing bool
+	// MaxErrors is the number of errors after which to stop reading with
+	// KeepGoing or 0 for DefaultMaxReadErrors.
+	MaxErrors int
+	// Concurrency is the number of files to read at once or 0 for
+	// ConcurrentReaders.
+	Concurrency int
+}
+
+// ReadLicenseGraphWithOptions reads and parses `files` and their dependencies
+// into a LicenseGraph like ReadLicenseGraph using `opts` until `ctx` is done.
+//
+// Returns `ctx.Err()` when `ctx` is done before reading finishes.
+//
+// Reports each license metadata file that cannot be read or parsed to
+// `stderr` as a *MetadataError. Returns the first such error, or with
+// opts.KeepGoing a MetadataErrors listing all of them.
+func ReadLicenseGraphWithOptions(ctx context.Context, rootFS fs.FS, stderr io.Writer, files []string, opts ReadOptions) (*LicenseGraph, error) {
+	progress := opts.Progress
+	maxErrors := opts.MaxErrors
+	if maxErrors == 0 {
+		maxErrors = DefaultMaxReadErrors
+	}
+	if maxErrors < 0 {
+		return nil, fmt.Errorf("invalid maximum number of errors %d", maxErrors)
+	}
 	if len(files) == 0 {
 		return nil, fmt.Errorf("no license metadata to analyze")
 	}
-	if ConcurrentReaders < 1 {
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = ConcurrentReaders
+	}
+	if concurrency < 1 {
 		return nil, fmt.Errorf("need at least one task in pool")
 	}
 
 	lg := newLicenseGraph()
+	lg.progress = progress
 	for _, f := range files {
 		if strings.HasSuffix(f, "meta_lic") {
 			lg.rootFiles = append(lg.rootFiles, f)
@@ -108,83 +205,115 @@ func ReadLicenseGraph(rootFS fs.FS, stderr io.Writer, files []string) (*LicenseG
 		}
 	}
 
+	// Stop the remaining tasks on the last error reported too.
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	recv := &receiver{
 		lg:      lg,
+		ctx:     readCtx,
 		rootFS:  rootFS,
 		stderr:  stderr,
-		task:    make(chan bool, ConcurrentReaders),
-		results: make(chan *result, ConcurrentReaders),
+		results: make(chan *result, concurrency),
 		wg:      sync.WaitGroup{},
 	}
-	for i := 0; i < ConcurrentReaders; i++ {
-		recv.task <- true
-	}
+	recv.ready = sync.NewCond(&recv.mu)
+	// Wake the waiting workers to stop when canceled. The deferred cancel
+	// ends this task once the graph is read too.
+	go func() {
+		<-readCtx.Done()
+		recv.mu.Lock()
+		recv.ready.Broadcast()
+		recv.mu.Unlock()
+	}()
 
-	readFiles := func() {
-		lg.mu.Lock()
-		// identify the metadata files to schedule reading tasks for
-		for _, f := range lg.rootFiles {
-			lg.targets[f] = nil
-		}
-		lg.mu.Unlock()
+	lg.mu.Lock()
+	// identify the metadata files to schedule reading tasks for
+	for _, f := range lg.rootFiles {
+		lg.targets[f] = nil
+	}
+	lg.mu.Unlock()
 
-		// schedule tasks to read the files
-		for _, f := range lg.rootFiles {
-			readFile(recv, f)
-		}
+	// schedule tasks to read the files
+	for _, f := range lg.rootFiles {
+		recv.schedule(f, f)
+	}
 
-		// schedule a task to wait until finished and close the channel.
-		go func() {
-			recv.wg.Wait()
-			close(recv.task)
-			close(recv.results)
-		}()
+	// start a fixed pool of workers, which limits the goroutines and the
+	// open files however wide or deep the graph, and a task to close the
+	// channel once they finish.
+	recv.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go recv.work()
 	}
-	go readFiles()
+	go func() {
+		recv.wg.Wait()
+		close(recv.results)
+	}()
 
 	// tasks to read license metadata files are scheduled; read and process results from channel
-	var err error
-	for recv.results != nil {
+	var errs MetadataErrors
+	done := 0
+	results := recv.results
+	for results != nil {
 		select {
-		case r, ok := <-recv.results:
+		case r, ok := <-results:
 			if ok {
-				// handle errors by nil'ing ls, setting err, and clobbering results channel
+				// handle errors by recording them, and once done with
+				// errors, by clobbering results channel and canceling the
+				// remaining tasks
 				if r.err != nil {
-					err = r.err
-					fmt.Fprintf(recv.stderr, "%s\n", err.Error())
-					lg = nil
-					recv.results = nil
+					errs = append(errs, r.err)
+					fmt.Fprintf(recv.stderr, "%s\n", r.err.Error())
+					if !opts.KeepGoing || len(errs) >= maxErrors {
+						results = nil
+						cancel()
+					}
 					continue
 				}
 
 				// record the parsed metadata (guarded by mutex)
 				recv.lg.mu.Lock()
 				lg.targets[r.target.name] = r.target
+				total := len(lg.targets)
 				recv.lg.mu.Unlock()
+
+				done++
+				if progress != nil {
+					progress(ProgressReadMetadata, done, total)
+				}
 			} else {
 				// finished -- nil the results channel
-				recv.results = nil
+				results = nil
 			}
+		case <-ctx.Done():
+			// abandon the remaining tasks, which stop on their own
+			return nil, ctx.Err()
 		}
 	}
-
-	if lg != nil {
-		esize := 0
-		for _, tn := range lg.targets {
-			esize += len(tn.proto.Deps)
-		}
-		lg.edges = make(TargetEdgeList, 0, esize)
-		for _, tn := range lg.targets {
-			tn.licenseConditions = LicenseConditionSetFromNames(tn.proto.LicenseConditions...)
-			err = addDependencies(lg, tn)
-			if err != nil {
-				return nil, fmt.Errorf("error indexing dependencies for %q: %w", tn.name, err)
-			}
-			tn.proto.Deps = []*license_metadata_proto.AnnotatedDependency{}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if len(errs) > 0 {
+		if !opts.KeepGoing {
+			return nil, errs[0]
 		}
+		return nil, errs
 	}
-	return lg, err
 
+	esize := 0
+	for _, tn := range lg.targets {
+		esize += len(tn.proto.Deps)
+	}
+	lg.edges = make(TargetEdgeList, 0, esize)
+	for _, tn := range lg.targets {
+		tn.licenseConditions = LicenseConditionSetFromNames(tn.proto.LicenseConditions...)
+		if err := addDependencies(lg, tn); err != nil {
+			return nil, fmt.Errorf("error indexing dependencies for %q: %w", tn.name, err)
+		}
+		tn.proto.Deps = []*license_metadata_proto.AnnotatedDependency{}
+	}
+	return lg, nil
 }
 
 // targetNode contains the license metadata for a node in the license graph.
@@ -233,63 +362,121 @@ func addDependencies(lg *LicenseGraph, tn *TargetNode) error {
 				annotations.annotations[ann] = struct{}{}
 			}
 		}
-		edge := &TargetEdge{tn, dtn, annotations}
+		edge := &TargetEdge{tn, dtn, annotations, newDepType(annotations), newLinkage(annotations), false}
 		lg.edges = append(lg.edges, edge)
 		tn.edges = append(tn.edges, edge)
 	}
 	return nil
 }
 
-// readFile is a task to read and parse a single license metadata file, and to schedule
-// additional tasks for reading and parsing dependencies as necessary.
-func readFile(recv *receiver, file string) {
-	recv.wg.Add(1)
-	<-recv.task
-	go func() {
-		f, err := recv.rootFS.Open(file)
-		if err != nil {
-			recv.results <- &result{file, nil, fmt.Errorf("error opening license metadata %q: %w", file, err)}
-			return
-		}
+// send sends `r` to the results channel unless reading is canceled first.
+//
+// Returns false when canceled.
+func (recv *receiver) send(r *result) bool {
+	select {
+	case recv.results <- r:
+		return true
+	case <-recv.ctx.Done():
+		return false
+	}
+}
 
-		// read the file
-		data, err := io.ReadAll(f)
-		if err != nil {
-			recv.results <- &result{file, nil, fmt.Errorf("error reading license metadata %q: %w", file, err)}
-			return
-		}
-		f.Close()
+// schedule queues a task to read the license metadata file `file` pulled in
+// by the root file `root`.
+func (recv *receiver) schedule(file, root string) {
+	recv.mu.Lock()
+	recv.queue = append(recv.queue, readTask{file, root})
+	recv.pending++
+	recv.mu.Unlock()
+	recv.ready.Signal()
+}
+
+// next returns the next task to read waiting until one is queued, or false
+// when no task remains or reading is canceled.
+func (recv *receiver) next() (readTask, bool) {
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	for len(recv.queue) == 0 && recv.pending > 0 && recv.ctx.Err() == nil {
+		recv.ready.Wait()
+	}
+	if len(recv.queue) == 0 || recv.ctx.Err() != nil {
+		return readTask{}, false
+	}
+	t := recv.queue[0]
+	recv.queue[0] = readTask{}
+	recv.queue = recv.queue[1:]
+	return t, true
+}
 
-		tn := &TargetNode{lg: recv.lg, name: file}
+// finish records that a task finished after scheduling the dependencies of
+// its file, and wakes the waiting workers to stop when none remains.
+func (recv *receiver) finish() {
+	recv.mu.Lock()
+	recv.pending--
+	if recv.pending == 0 {
+		recv.ready.Broadcast()
+	}
+	recv.mu.Unlock()
+}
 
-		err = prototext.Unmarshal(data, &tn.proto)
-		if err != nil {
-			recv.results <- &result{file, nil, fmt.Errorf("error license metadata %q: %w", file, err)}
+// work reads the queued license metadata files until none remains.
+func (recv *receiver) work() {
+	defer recv.wg.Done()
+	for {
+		t, ok := recv.next()
+		if !ok {
 			return
 		}
+		readFile(recv, t.file, t.root)
+		recv.finish()
+	}
+}
 
-		// send result for this file and release task before scheduling dependencies,
-		// but do not signal done to WaitGroup until dependencies are scheduled.
-		recv.results <- &result{file, tn, nil}
-		recv.task <- true
-
-		// schedule tasks as necessary to read dependencies
-		for _, ad := range tn.proto.Deps {
-			dependency := ad.GetFile()
-			// decide, signal and record whether to schedule task in critical section
-			recv.lg.mu.Lock()
-			_, alreadyScheduled := recv.lg.targets[dependency]
-			if !alreadyScheduled {
-				recv.lg.targets[dependency] = nil
-			}
-			recv.lg.mu.Unlock()
-			// schedule task to read dependency file outside critical section
-			if !alreadyScheduled {
-				readFile(recv, dependency)
-			}
-		}
+// readFile is a task to read and parse a single license metadata file, and to schedule
+// additional tasks for reading and parsing dependencies as necessary.
+//
+// `root` is the root file whose dependencies pulled in `file`.
+func readFile(recv *receiver, file, root string) {
+	f, err := recv.rootFS.Open(file)
+	if err != nil {
+		recv.send(&result{file, nil, newMetadataError(file, root, nil, fmt.Errorf("cannot open: %w", err))})
+		return
+	}
 
-		// signal task done after scheduling dependencies
-		recv.wg.Done()
-	}()
+	// read the file
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		recv.send(&result{file, nil, newMetadataError(file, root, nil, fmt.Errorf("cannot read: %w", err))})
+		return
+	}
+
+	tn := &TargetNode{lg: recv.lg, name: file}
+
+	err = prototext.Unmarshal(data, &tn.proto)
+	if err != nil {
+		recv.send(&result{file, nil, newMetadataError(file, root, data, err)})
+		return
+	}
+
+	// send result for this file before scheduling dependencies
+	if !recv.send(&result{file, tn, nil}) {
+		return
+	}
+
+	// schedule tasks as necessary to read dependencies
+	for _, ad := range tn.proto.Deps {
+		dependency := ad.GetFile()
+		// decide, signal and record whether to schedule task in critical section
+		recv.lg.mu.Lock()
+		_, alreadyScheduled := recv.lg.targets[dependency]
+		if !alreadyScheduled {
+			recv.lg.targets[dependency] = nil
+		}
+		recv.lg.mu.Unlock()
+		// schedule task to read dependency file outside critical section
+		if !alreadyScheduled {
+			recv.schedule(dependency, root)
+		}
+	}
 }
@@ -16,9 +16,19 @@ package compliance
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"android/soong/tools/compliance/testfs"
 )
@@ -150,3 +160,239 @@ func TestReadLicenseGraph(t *testing.T) {
 		})
 	}
 }
+
+func TestReadLicenseGraphDepType(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     []string
+		expected        DepType
+		expectedLinkage Linkage
+	}{
+		{"none", []string{}, DepTypeRuntime, LinkageStatic},
+		{"static", []string{"static"}, DepTypeRuntime, LinkageStatic},
+		{"runtime", []string{"dynamic", "runtime"}, DepTypeRuntime, LinkageDynamic},
+		{"buildtime", []string{"static", "buildtime"}, DepTypeBuildtime, LinkageStatic},
+		{"toolchain", []string{"toolchain"}, DepTypeBuildtime, LinkageStatic},
+		{"test", []string{"static", "test"}, DepTypeTest, LinkageStatic},
+		{"shared_library", []string{"shared_library"}, DepTypeRuntime, LinkageSharedLibrary},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			lg, err := toGraph(stderr, []string{"apacheBin.meta_lic"}, []annotated{
+				{"apacheBin.meta_lic", "apacheLib.meta_lic", tt.annotations},
+			})
+			if err != nil {
+				t.Fatalf("unexpected test data error: got %s, want no error", err)
+			}
+			edges := lg.Edges()
+			if len(edges) != 1 {
+				t.Fatalf("unexpected edges: got %d edges, want 1 edge", len(edges))
+			}
+			if actual := edges[0].DepType(); actual != tt.expected {
+				t.Errorf("unexpected dep type: got %q, want %q", actual, tt.expected)
+			}
+			if actual := edges[0].Linkage(); actual != tt.expectedLinkage {
+				t.Errorf("unexpected linkage: got %q, want %q", actual, tt.expectedLinkage)
+			}
+		})
+	}
+}
+
+func TestReadLicenseGraphCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fs := make(testfs.TestFS)
+	fs["app.meta_lic"] = []byte("package_name: \"app\"\ndeps: {\n  file: \"lib.meta_lic\"\n}\n")
+	fs["lib.meta_lic"] = []byte("package_name: \"lib\"\n")
+
+	stderr := &bytes.Buffer{}
+	lg, err := ReadLicenseGraphWithOptions(ctx, &fs, stderr, []string{"app.meta_lic"}, ReadOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: got %v, want %v", err, context.Canceled)
+	}
+	if lg != nil {
+		t.Errorf("unexpected graph: got %v, want nil", lg)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected stderr: got %q, want none", stderr)
+	}
+}
+
+// limitFS counts the files open at once and the times each file is opened.
+type limitFS struct {
+	testfs.TestFS
+
+	// goroutines is the number of goroutines before reading.
+	goroutines int
+
+	mu          sync.Mutex
+	open        int
+	maxOpen     int
+	maxRoutines int
+	opened      map[string]int
+}
+
+// limitFile decrements the files open at once when closed.
+type limitFile struct {
+	fs.File
+	fsys *limitFS
+}
+
+func (f *limitFile) Close() error {
+	f.fsys.mu.Lock()
+	f.fsys.open--
+	f.fsys.mu.Unlock()
+	return f.File.Close()
+}
+
+func (l *limitFS) Open(name string) (fs.File, error) {
+	f, err := l.TestFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.open++
+	if l.open > l.maxOpen {
+		l.maxOpen = l.open
+	}
+	if routines := runtime.NumGoroutine() - l.goroutines; routines > l.maxRoutines {
+		l.maxRoutines = routines
+	}
+	l.opened[name]++
+	return &limitFile{f, l}, nil
+}
+
+func TestReadLicenseGraphConcurrencyLimit(t *testing.T) {
+	// A graph 200 levels deep and 20 targets wide where each target depends
+	// on 3 targets of the next level.
+	const depth, width, fanOut, limit = 200, 20, 3, 4
+	fsys := &limitFS{TestFS: make(testfs.TestFS), opened: make(map[string]int)}
+	name := func(level, i int) string {
+		return fmt.Sprintf("level%d/target%d.meta_lic", level, i%width)
+	}
+	for level := 0; level < depth; level++ {
+		for i := 0; i < width; i++ {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "package_name: \"target%d_%d\"\nlicense_conditions: \"notice\"\n", level, i)
+			if level+1 < depth {
+				for j := 0; j < fanOut; j++ {
+					fmt.Fprintf(&sb, "deps: {\n  file: %q\n  annotations: \"static\"\n}\n", name(level+1, i+j))
+				}
+			}
+			fsys.TestFS[name(level, i)] = []byte(sb.String())
+		}
+	}
+	var roots []string
+	for i := 0; i < width; i++ {
+		roots = append(roots, name(0, i))
+	}
+
+	fsys.goroutines = runtime.NumGoroutine()
+	lg, err := ReadLicenseGraphWithOptions(context.Background(), fsys, io.Discard, roots, ReadOptions{Concurrency: limit})
+	if err != nil {
+		t.Fatalf("ReadLicenseGraphWithOptions(): got error %s, want no error", err)
+	}
+	if len(lg.Targets()) != depth*width {
+		t.Errorf("ReadLicenseGraphWithOptions(): got %d targets, want %d", len(lg.Targets()), depth*width)
+	}
+	if len(lg.Edges()) != (depth-1)*width*fanOut {
+		t.Errorf("ReadLicenseGraphWithOptions(): got %d edges, want %d", len(lg.Edges()), (depth-1)*width*fanOut)
+	}
+	if fsys.maxOpen > limit {
+		t.Errorf("ReadLicenseGraphWithOptions(): got %d files open at once, want at most %d", fsys.maxOpen, limit)
+	}
+	// The workers plus the tasks closing the results channel and waking the
+	// workers on cancel.
+	if fsys.maxRoutines > limit+2 {
+		t.Errorf("ReadLicenseGraphWithOptions(): got %d goroutines, want at most %d", fsys.maxRoutines, limit+2)
+	}
+	for file, n := range fsys.opened {
+		if n != 1 {
+			t.Errorf("ReadLicenseGraphWithOptions(): got %d reads of %q, want 1", n, file)
+		}
+	}
+	checkGoroutines(t, fsys.goroutines)
+}
+
+func TestDefaultConcurrentReaders(t *testing.T) {
+	t.Setenv(ConcurrentReadersEnv, "3")
+	if n := DefaultConcurrentReaders(); n != 3 {
+		t.Errorf("DefaultConcurrentReaders(%s=3): got %d, want 3", ConcurrentReadersEnv, n)
+	}
+	for _, value := range []string{"", "0", "-2", "many"} {
+		t.Setenv(ConcurrentReadersEnv, value)
+		n := DefaultConcurrentReaders()
+		if n < 1 || n > 2*runtime.GOMAXPROCS(0) {
+			t.Errorf("DefaultConcurrentReaders(%s=%q): got %d, want 1 to %d", ConcurrentReadersEnv, value, n, 2*runtime.GOMAXPROCS(0))
+		}
+		if limit, ok := openFileLimit(); ok && limit >= 4 && uint64(n) > limit/4 {
+			t.Errorf("DefaultConcurrentReaders(%s=%q): got %d, want at most %d for %d open files", ConcurrentReadersEnv, value, n, limit/4, limit)
+		}
+	}
+}
+
+func FuzzParseMetaLic(f *testing.F) {
+	// Seed the corpus with the license metadata files of the cmd tests in
+	// addition to testdata/fuzz/FuzzParseMetaLic. (relative to cmd per
+	// TestMain)
+	fixtures, err := filepath.Glob("testdata/*/*.meta_lic")
+	if err != nil {
+		f.Fatalf("cannot find fixtures: %v", err)
+	}
+	more, err := filepath.Glob("testdata/*/*/*.meta_lic")
+	if err != nil {
+		f.Fatalf("cannot find fixtures: %v", err)
+	}
+	for _, fixture := range append(fixtures, more...) {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			f.Fatalf("cannot read fixture: %v", err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		before := runtime.NumGoroutine()
+
+		rootFS := &testfs.TestFS{
+			"fuzz.meta_lic": data,
+			"lib/liba.so.meta_lic": []byte(`package_name: "liba"
+license_conditions: "notice"
+`),
+		}
+		lg, err := ReadLicenseGraph(rootFS, io.Discard, []string{"fuzz.meta_lic"})
+		if err != nil {
+			if lg != nil {
+				t.Errorf("ReadLicenseGraph: got graph and error %q, want nil graph", err)
+			}
+			var e error = err
+			if len(e.Error()) == 0 {
+				t.Errorf("ReadLicenseGraph: got error without message")
+			}
+		} else if lg == nil {
+			t.Errorf("ReadLicenseGraph: got neither graph nor error")
+		} else if lg.targets["fuzz.meta_lic"] == nil {
+			t.Errorf("ReadLicenseGraph: got graph without root target")
+		}
+
+		checkGoroutines(t, before)
+	})
+}
+
+// checkGoroutines fails `t` unless the number of goroutines falls to at most
+// `n` within a second so that tasks leaked past the return of a function
+// get reported.
+func checkGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("got %d goroutines, want at most %d:\n%s", runtime.NumGoroutine(), n, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// MaxLinkDepth limits the number of symbolic links followed resolving a
+// single name.
+const MaxLinkDepth = 40
+
+// ReadLinkFS is a file system that can examine symbolic links without
+// following them. The methods match fs.ReadLinkFS in newer versions of Go so
+// that e.g. os.DirFS implements it there.
+type ReadLinkFS interface {
+	fs.FS
+
+	// ReadLink returns the destination of the named symbolic link.
+	ReadLink(name string) (string, error)
+
+	// Lstat returns a FileInfo describing the named file without following
+	// a symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// ResolveLink returns the name within `fsys` of the file the symbolic link
+// `name` refers to, following any chain of links, or `name` itself when not a
+// link or when `fsys` does not implement ReadLinkFS.
+//
+// Resolves only the final element of each name. Returns an error for a link
+// that points outside `fsys` e.g. to an absolute path, for a cycle of links,
+// and for chains longer than MaxLinkDepth. Only file systems accepting
+// absolute names, like FS, can resolve links from absolute names to absolute
+// paths.
+func ResolveLink(fsys fs.FS, name string) (string, error) {
+	rfs, ok := fsys.(ReadLinkFS)
+	if !ok {
+		return name, nil
+	}
+	seen := make(map[string]struct{})
+	for depth := 0; ; depth++ {
+		fi, err := rfs.Lstat(name)
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&fs.ModeSymlink == 0 {
+			return name, nil
+		}
+		if _, ok := seen[name]; ok {
+			return "", fmt.Errorf("symbolic link cycle at %q", name)
+		}
+		if depth >= MaxLinkDepth {
+			return "", fmt.Errorf("more than %d symbolic links resolving %q", MaxLinkDepth, name)
+		}
+		seen[name] = struct{}{}
+		target, err := rfs.ReadLink(name)
+		if err != nil {
+			return "", err
+		}
+		resolved := path.Clean(target)
+		if !path.IsAbs(target) {
+			resolved = path.Join(path.Dir(name), target)
+		}
+		if fs.ValidPath(name) && !fs.ValidPath(resolved) {
+			return "", fmt.Errorf("symbolic link %q points outside the root to %q", name, target)
+		}
+		name = resolved
+	}
+}
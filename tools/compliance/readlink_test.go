@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+// linkFS adds symbolic links to a testfs.TestFS. Like an archive, opening a
+// link returns the link destination instead of following it.
+type linkFS struct {
+	testfs.TestFS
+	links map[string]string
+}
+
+func (lfs *linkFS) Open(name string) (fs.File, error) {
+	if target, ok := lfs.links[name]; ok {
+		tfs := testfs.TestFS{name: []byte(target)}
+		return tfs.Open(name)
+	}
+	return lfs.TestFS.Open(name)
+}
+
+func (lfs *linkFS) ReadLink(name string) (string, error) {
+	if target, ok := lfs.links[name]; ok {
+		return target, nil
+	}
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+}
+
+func (lfs *linkFS) Lstat(name string) (fs.FileInfo, error) {
+	if _, ok := lfs.links[name]; ok {
+		return linkInfo(name), nil
+	}
+	return lfs.TestFS.Stat(name)
+}
+
+// linkInfo describes a symbolic link in a linkFS.
+type linkInfo string
+
+func (li linkInfo) Name() string       { return string(li) }
+func (li linkInfo) Size() int64        { return 0 }
+func (li linkInfo) Mode() fs.FileMode  { return fs.ModeSymlink }
+func (li linkInfo) ModTime() time.Time { return time.Time{} }
+func (li linkInfo) IsDir() bool        { return false }
+func (li linkInfo) Sys() any           { return nil }
+
+func TestResolveLink(t *testing.T) {
+	chain := make(map[string]string)
+	for i := 0; i <= MaxLinkDepth; i++ {
+		chain[fmt.Sprintf("deep%d", i)] = fmt.Sprintf("deep%d", i+1)
+	}
+	chain["a/NOTICE"] = "../b/LICENSE"
+	chain["b/LICENSE"] = "COPYING"
+	chain["loop1"] = "loop2"
+	chain["loop2"] = "loop1"
+	chain["absolute"] = "/etc/LICENSE"
+	chain["a/escape"] = "../../LICENSE"
+	chain["dangling"] = "missing"
+	lfs := &linkFS{testfs.TestFS{
+		"b/COPYING":                           []byte("text"),
+		"plain":                               []byte("text"),
+		fmt.Sprintf("deep%d", MaxLinkDepth+1): []byte("text"),
+	}, chain}
+
+	tests := []struct {
+		name          string
+		expected      string
+		expectedError bool
+	}{
+		{"plain", "plain", false},
+		{"a/NOTICE", "b/COPYING", false},
+		{"b/LICENSE", "b/COPYING", false},
+		{"deep35", fmt.Sprintf("deep%d", MaxLinkDepth+1), false},
+		{"deep0", "", true},
+		{"loop1", "", true},
+		{"absolute", "", true},
+		{"a/escape", "", true},
+		{"dangling", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ResolveLink(lfs, tt.name)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("ResolveLink(%q): got %q, want error", tt.name, actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveLink(%q): got error %s, want no error", tt.name, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("ResolveLink(%q): got %q, want %q", tt.name, actual, tt.expected)
+			}
+		})
+	}
+
+	if actual, err := ResolveLink(&lfs.TestFS, "a/NOTICE"); err != nil || actual != "a/NOTICE" {
+		t.Errorf("ResolveLink(TestFS): got %q error %v, want %q unchanged", actual, err, "a/NOTICE")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"io/fs"
+	"sort"
+	"sync"
+)
+
+// RecordingFS wraps a filesystem recording the name of every regular file
+// opened through it so that tools can report the inputs of their outputs
+// e.g. in a Makefile depfile.
+//
+// RecordingFS is safe for concurrent use.
+type RecordingFS struct {
+	// rootFS is the wrapped filesystem.
+	rootFS fs.FS
+
+	// mu guards files.
+	mu sync.Mutex
+
+	// files is the set of file names opened.
+	files map[string]struct{}
+}
+
+var _ fs.FS = (*RecordingFS)(nil)
+var _ fs.StatFS = (*RecordingFS)(nil)
+var _ ReadLinkFS = (*RecordingFS)(nil)
+
+// NewRecordingFS returns a RecordingFS wrapping `rootFS`.
+func NewRecordingFS(rootFS fs.FS) *RecordingFS {
+	return &RecordingFS{rootFS: rootFS, files: make(map[string]struct{})}
+}
+
+// Open implements fs.FS.Open() recording `name` when it opens a regular file.
+func (rfs *RecordingFS) Open(name string) (fs.File, error) {
+	f, err := rfs.rootFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := f.Stat(); err == nil && fi.IsDir() {
+		return f, nil
+	}
+	rfs.Record(name)
+	return f, nil
+}
+
+// Stat implements fs.StatFS.Stat(). Examining a file does not record it.
+func (rfs *RecordingFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(rfs.rootFS, name)
+}
+
+// ReadLink implements ReadLinkFS.ReadLink() recording the link `name` so that
+// changing the link changes the inputs. Fails unless the wrapped filesystem
+// implements ReadLinkFS.
+func (rfs *RecordingFS) ReadLink(name string) (string, error) {
+	lfs, ok := rfs.rootFS.(ReadLinkFS)
+	if !ok {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	target, err := lfs.ReadLink(name)
+	if err != nil {
+		return "", err
+	}
+	rfs.Record(name)
+	return target, nil
+}
+
+// Lstat implements ReadLinkFS.Lstat(). Same as Stat unless the wrapped
+// filesystem implements ReadLinkFS.
+func (rfs *RecordingFS) Lstat(name string) (fs.FileInfo, error) {
+	if lfs, ok := rfs.rootFS.(ReadLinkFS); ok {
+		return lfs.Lstat(name)
+	}
+	return fs.Stat(rfs.rootFS, name)
+}
+
+// Record adds `name` to the recorded files for inputs read some other way.
+func (rfs *RecordingFS) Record(name string) {
+	rfs.mu.Lock()
+	defer rfs.mu.Unlock()
+	rfs.files[name] = struct{}{}
+}
+
+// Files returns the sorted list of files opened or recorded.
+func (rfs *RecordingFS) Files() []string {
+	rfs.mu.Lock()
+	defer rfs.mu.Unlock()
+	files := make([]string, 0, len(rfs.files))
+	for f := range rfs.files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+func TestRecordingFS(t *testing.T) {
+	rootFS := fstest.MapFS{
+		"bin.meta_lic": &fstest.MapFile{Data: []byte("package_name: \"bin\"\n" +
+			"license_kinds: \"SPDX-license-identifier-MIT\"\n" +
+			"license_conditions: \"notice\"\n" +
+			"license_texts: \"licenses/LICENSE\"\n" +
+			"deps: {\n  file: \"lib.meta_lic\"\n  annotations: \"static\"\n}\n")},
+		"lib.meta_lic":     &fstest.MapFile{Data: []byte("package_name: \"lib\"\n")},
+		"licenses/LICENSE": &fstest.MapFile{Data: []byte("license text\n")},
+		"unused.meta_lic":  &fstest.MapFile{Data: []byte("package_name: \"unused\"\n")},
+	}
+	rfs := NewRecordingFS(rootFS)
+
+	lg, err := ReadLicenseGraph(rfs, &bytes.Buffer{}, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	if _, err := IndexLicenseTexts(rfs, lg, nil); err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	if _, err := fs.Stat(rfs, "unused.meta_lic"); err != nil {
+		t.Fatalf("fs.Stat(): got error %s, want no error", err)
+	}
+	if _, err := rfs.Open("licenses"); err != nil {
+		t.Fatalf("Open(): got error %s, want no error", err)
+	}
+	if _, err := rfs.Open("missing"); err == nil {
+		t.Errorf("Open(\"missing\"): got no error, want error")
+	}
+	rfs.Record("template.html")
+
+	expected := []string{"bin.meta_lic", "lib.meta_lic", "licenses/LICENSE", "template.html"}
+	if actual := rfs.Files(); strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("Files(): got %q, want %q", actual, expected)
+	}
+}
+
+func TestRecordingFSReadLink(t *testing.T) {
+	lfs := &linkFS{testfs.TestFS{"LICENSE": []byte("license text\n")}, map[string]string{"NOTICE": "LICENSE"}}
+	rfs := NewRecordingFS(lfs)
+
+	resolved, err := ResolveLink(rfs, "NOTICE")
+	if err != nil || resolved != "LICENSE" {
+		t.Fatalf("ResolveLink(NOTICE): got %q error %v, want LICENSE", resolved, err)
+	}
+	if files := rfs.Files(); len(files) != 1 || files[0] != "NOTICE" {
+		t.Errorf("Files(): got %q, want [NOTICE]", files)
+	}
+
+	plain := NewRecordingFS(&lfs.TestFS)
+	if _, err := plain.ReadLink("NOTICE"); err == nil {
+		t.Errorf("ReadLink(NOTICE): got no error without ReadLinkFS, want error")
+	}
+	if fi, err := plain.Lstat("LICENSE"); err != nil || fi.Mode()&fs.ModeSymlink != 0 {
+		t.Errorf("Lstat(LICENSE): got %v error %v, want regular file", fi, err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+// propertyLicenses lists the license metadata randomly assigned to the nodes
+// of generated graphs.
+var propertyLicenses = []string{AOSP, GPL, Classpath, DependentModule, LGPL, MPL, MIT, Proprietary, ByException}
+
+// propertyAnnotations lists the edge annotations randomly assigned to the
+// edges of generated graphs.
+var propertyAnnotations = [][]string{nil, {"static"}, {"dynamic"}, {"toolchain"}, {"dynamic", "toolchain"}}
+
+// randomGraph describes a random acyclic license graph rooted at node 0.
+//
+// Edges only point from lower to higher node numbers so the graph has no
+// cycles, and every node other than the root has at least one target so that
+// every node is reachable from the root.
+type randomGraph struct {
+	licenses   []int
+	containers []bool
+	edges      []randomEdge
+}
+
+// randomEdge describes an edge of a randomGraph.
+type randomEdge struct {
+	target, dep, annotations int
+}
+
+// Generate implements quick.Generator for randomGraph.
+func (randomGraph) Generate(r *rand.Rand, size int) reflect.Value {
+	n := 1 + r.Intn(size%20+1)
+	g := randomGraph{make([]int, n), make([]bool, n), nil}
+	for i := 0; i < n; i++ {
+		g.licenses[i] = r.Intn(len(propertyLicenses))
+		g.containers[i] = r.Intn(4) == 0
+		if i == 0 {
+			continue
+		}
+		seen := make(map[int]bool)
+		for parents := 1 + r.Intn(2); parents > 0; parents-- {
+			target := r.Intn(i)
+			if seen[target] {
+				continue
+			}
+			seen[target] = true
+			g.edges = append(g.edges, randomEdge{target, i, r.Intn(len(propertyAnnotations))})
+		}
+	}
+	return reflect.ValueOf(g)
+}
+
+// String returns a human-readable representation of the graph for failures.
+func (g randomGraph) String() string {
+	var sb strings.Builder
+	for i, l := range g.licenses {
+		fmt.Fprintf(&sb, "%s: %s", g.name(i), strings.SplitN(propertyLicenses[l], "\n", 3)[1])
+		if g.containers[i] {
+			fmt.Fprintf(&sb, " (container)")
+		}
+		fmt.Fprintln(&sb)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&sb, "%s -> %s %v\n", g.name(e.target), g.name(e.dep), propertyAnnotations[e.annotations])
+	}
+	return sb.String()
+}
+
+// name returns the metadata file name of node `i`.
+func (g randomGraph) name(i int) string {
+	return fmt.Sprintf("n%02d.meta_lic", i)
+}
+
+// read returns a newly read license graph for `g`.
+func (g randomGraph) read(t *testing.T) *LicenseGraph {
+	t.Helper()
+	fs := make(testfs.TestFS)
+	bodies := make([]string, len(g.licenses))
+	for i, l := range g.licenses {
+		bodies[i] = propertyLicenses[l]
+		if g.containers[i] {
+			bodies[i] += "is_container: true\n"
+		}
+	}
+	for _, e := range g.edges {
+		bodies[e.target] += fmt.Sprintf("deps: {\n  file: %q\n", g.name(e.dep))
+		for _, ann := range propertyAnnotations[e.annotations] {
+			bodies[e.target] += fmt.Sprintf("  annotations: %q\n", ann)
+		}
+		bodies[e.target] += "}\n"
+	}
+	for i, body := range bodies {
+		fs[g.name(i)] = []byte(body)
+	}
+	stderr := &bytes.Buffer{}
+	lg, err := ReadLicenseGraph(&fs, stderr, []string{g.name(0)})
+	if err != nil {
+		t.Fatalf("unable to read graph:\n%s\nerror: %s\nstderr: %s", g, err, stderr)
+	}
+	return lg
+}
+
+// resolved returns the resolved conditions of every node in `lg` by name.
+func resolved(lg *LicenseGraph) map[string]LicenseConditionSet {
+	result := make(map[string]LicenseConditionSet)
+	for name, tn := range lg.targets {
+		result[name] = tn.resolution
+	}
+	return result
+}
+
+// checkProperty runs `property` over random graphs reporting the first
+// counterexample.
+func checkProperty(t *testing.T, property func(g randomGraph) bool) {
+	t.Helper()
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestResolverPropertyRestrictedReachesRoot verifies that a target is never
+// less encumbered than the dependencies it derives from: the restricted
+// conditions of every dependency reachable along static links appear in the
+// resolved conditions of each target above it, and the restricted condition
+// also propagates across dynamic links.
+func TestResolverPropertyRestrictedReachesRoot(t *testing.T) {
+	checkProperty(t, func(g randomGraph) bool {
+		lg := g.read(t)
+		ResolveTopDownConditions(lg)
+
+		// check walks every path from `target` below `tn` where `static`
+		// records whether the path so far has only static links.
+		var check func(target, tn *TargetNode, static bool) bool
+		check = func(target, tn *TargetNode, static bool) bool {
+			for _, e := range tn.edges {
+				isDerivation, isDynamic := edgeIsDerivation(e), edgeIsDynamicLink(e)
+				if !isDerivation && !isDynamic {
+					continue
+				}
+				static := static && isDerivation
+				expected := e.dependency.licenseConditions & LicenseConditionSet(RestrictedCondition)
+				if static {
+					expected = e.dependency.licenseConditions & ImpliesRestricted
+				}
+				if missing := expected.Difference(target.resolution); !missing.IsEmpty() {
+					t.Logf("%s resolved %s missing %s from %s in:\n%s", target.name, target.resolution.Names(), missing.Names(), e.dependency.name, g)
+					return false
+				}
+				if !check(target, e.dependency, static) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, tn := range lg.targets {
+			if !check(tn, tn, true) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// TestResolverPropertyNoticeDoesNotPropagate verifies that first-party and
+// other notice-type conditions stay on the targets declaring them, and that
+// no target loses the restricted conditions it declares to the conditions of
+// the targets around it.
+func TestResolverPropertyNoticeDoesNotPropagate(t *testing.T) {
+	checkProperty(t, func(g randomGraph) bool {
+		lg := g.read(t)
+		ResolveTopDownConditions(lg)
+
+		for _, tn := range lg.targets {
+			if missing := tn.licenseConditions.Difference(tn.resolution); !missing.IsEmpty() {
+				t.Logf("%s resolved %s lost declared %s in:\n%s", tn.name, tn.resolution.Names(), missing.Names(), g)
+				return false
+			}
+			inherited := tn.resolution.Difference(tn.licenseConditions).Difference(ImpliesRestricted)
+			if !inherited.IsEmpty() {
+				t.Logf("%s resolved %s inherited %s in:\n%s", tn.name, tn.resolution.Names(), inherited.Names(), g)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// TestResolverPropertyIdempotent verifies that resolving a graph again
+// changes nothing, and that resolving a fresh copy of the graph, with or
+// without resolving bottom-up first, gives the same conditions.
+func TestResolverPropertyIdempotent(t *testing.T) {
+	checkProperty(t, func(g randomGraph) bool {
+		lg := g.read(t)
+		ResolveTopDownConditions(lg)
+		first := resolved(lg)
+
+		ResolveBottomUpConditions(lg)
+		ResolveTopDownConditions(lg)
+		if again := resolved(lg); !reflect.DeepEqual(first, again) {
+			t.Logf("resolving again changed %v to %v in:\n%s", first, again, g)
+			return false
+		}
+
+		fresh := g.read(t)
+		ResolveBottomUpConditions(fresh)
+		ResolveTopDownConditions(fresh)
+		if again := resolved(fresh); !reflect.DeepEqual(first, again) {
+			t.Logf("resolving a copy gave %v instead of %v in:\n%s", again, first, g)
+			return false
+		}
+		return true
+	})
+}
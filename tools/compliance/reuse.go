@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// REUSEViolationType identifies the way a file fails to comply with the
+// REUSE specification.
+type REUSEViolationType int
+
+const (
+	// MissingCopyright indicates no copyright notice covers the file.
+	MissingCopyright REUSEViolationType = iota
+
+	// MissingLicense indicates no SPDX-License-Identifier covers the file.
+	MissingLicense
+)
+
+// String returns the name of the violation type.
+func (vt REUSEViolationType) String() string {
+	switch vt {
+	case MissingCopyright:
+		return "missingCopyright"
+	case MissingLicense:
+		return "missingLicense"
+	}
+	return "unknown"
+}
+
+// REUSESeverity identifies how serious a REUSE violation is.
+type REUSESeverity int
+
+const (
+	// REUSEWarning identifies violations that lose attribution but leave the
+	// terms of use known.
+	REUSEWarning REUSESeverity = iota
+
+	// REUSEError identifies violations that leave the terms of use unknown.
+	REUSEError
+)
+
+// String returns the name of the severity.
+func (s REUSESeverity) String() string {
+	switch s {
+	case REUSEWarning:
+		return "warning"
+	case REUSEError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// REUSEViolation describes a single file failing a REUSE requirement.
+type REUSEViolation struct {
+	// Path identifies the file relative to the root of the file system.
+	Path string
+
+	// Type identifies the requirement the file fails.
+	Type REUSEViolationType
+
+	// Severity identifies how serious the failure is.
+	Severity REUSESeverity
+}
+
+// String returns a human-readable description of the violation.
+func (v REUSEViolation) String() string {
+	return v.Severity.String() + ": " + v.Path + ": " + v.Type.String()
+}
+
+// maxREUSEHeader is the number of bytes at the start of each file inspected
+// for copyright and license tags.
+const maxREUSEHeader = 4096
+
+var (
+	// reuseCopyright matches the copyright notices recognized by the REUSE
+	// specification.
+	reuseCopyright = regexp.MustCompile(`(?:SPDX-FileCopyrightText:|Copyright|©|\([cC]\))`)
+
+	// reuseLicense matches an SPDX license identifier tag.
+	reuseLicense = regexp.MustCompile(`SPDX-License-Identifier:\s*\S`)
+)
+
+// CheckREUSECompliance walks the tree under `root` in `rootFS` and returns
+// the files lacking copyright or license information ordered by path.
+//
+// Per the REUSE specification, a file may carry the information in its own
+// header, in an adjacent `.license` file, or via a `.reuse/dep5` file at
+// `root`. Version control metadata, the `LICENSES` directory, and `.reuse`
+// itself are exempt.
+func CheckREUSECompliance(root string, rootFS fs.FS) []REUSEViolation {
+	dep5 := readREUSEDep5(rootFS, path.Join(root, ".reuse/dep5"))
+
+	violations := make([]REUSEViolation, 0)
+	fs.WalkDir(rootFS, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// unreadable entries cannot be checked; keep walking the rest
+			return nil
+		}
+		rel := p
+		if root != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".hg", ".svn", ".reuse":
+				return fs.SkipDir
+			}
+			if rel == "LICENSES" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, ".license") {
+			// companion files get checked on behalf of the file they describe
+			return nil
+		}
+
+		header := p
+		if _, err := fs.Stat(rootFS, p+".license"); err == nil {
+			header = p + ".license"
+		}
+		hasCopyright, hasLicense := scanREUSEHeader(rootFS, header)
+		if !hasCopyright || !hasLicense {
+			c, l := dep5.covers(rel)
+			hasCopyright = hasCopyright || c
+			hasLicense = hasLicense || l
+		}
+		if !hasCopyright {
+			violations = append(violations, REUSEViolation{p, MissingCopyright, REUSEWarning})
+		}
+		if !hasLicense {
+			violations = append(violations, REUSEViolation{p, MissingLicense, REUSEError})
+		}
+		return nil
+	})
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Type < violations[j].Type
+	})
+	return violations
+}
+
+// scanREUSEHeader returns whether the start of file `p` contains copyright
+// and license tags.
+func scanREUSEHeader(rootFS fs.FS, p string) (hasCopyright, hasLicense bool) {
+	f, err := rootFS.Open(p)
+	if err != nil {
+		return false, false
+	}
+	defer f.Close()
+	header, err := io.ReadAll(io.LimitReader(f, maxREUSEHeader))
+	if err != nil {
+		return false, false
+	}
+	s := bufio.NewScanner(bytes.NewReader(header))
+	for s.Scan() {
+		line := s.Bytes()
+		if reuseCopyright.Match(line) {
+			hasCopyright = true
+		}
+		if reuseLicense.Match(line) {
+			hasLicense = true
+		}
+	}
+	return hasCopyright, hasLicense
+}
+
+// reuseDep5Stanza describes a `Files:` paragraph of a `.reuse/dep5` file.
+type reuseDep5Stanza struct {
+	patterns     []string
+	hasCopyright bool
+	hasLicense   bool
+}
+
+// reuseDep5 describes the coverage provided by a `.reuse/dep5` file.
+type reuseDep5 []reuseDep5Stanza
+
+// readREUSEDep5 reads the `Files:` paragraphs from the dep5 file at `p`.
+//
+// A missing or unreadable file covers nothing.
+func readREUSEDep5(rootFS fs.FS, p string) reuseDep5 {
+	data, err := fs.ReadFile(rootFS, p)
+	if err != nil {
+		return nil
+	}
+	var result reuseDep5
+	var current *reuseDep5Stanza
+	field := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			field = ""
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// continuation of the previous field
+			if current != nil && field == "files" {
+				current.patterns = append(current.patterns, strings.Fields(line)...)
+			}
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		switch field {
+		case "files":
+			result = append(result, reuseDep5Stanza{patterns: strings.Fields(value)})
+			current = &result[len(result)-1]
+		case "copyright":
+			if current != nil && len(value) > 0 {
+				current.hasCopyright = true
+			}
+		case "license":
+			if current != nil && len(value) > 0 {
+				current.hasLicense = true
+			}
+		}
+	}
+	return result
+}
+
+// covers returns whether any stanza matching `rel` provides copyright or
+// license information.
+func (d reuseDep5) covers(rel string) (hasCopyright, hasLicense bool) {
+	for _, stanza := range d {
+		for _, pattern := range stanza.patterns {
+			if matchDep5Pattern(pattern, rel) {
+				hasCopyright = hasCopyright || stanza.hasCopyright
+				hasLicense = hasLicense || stanza.hasLicense
+				break
+			}
+		}
+	}
+	return hasCopyright, hasLicense
+}
+
+// matchDep5Pattern returns whether `name` matches the dep5 glob `pattern`
+// where `*` matches any sequence of characters including `/` and `?` matches
+// any single character.
+func matchDep5Pattern(pattern, name string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	switch pattern[0] {
+	case '*':
+		for i := 0; i <= len(name); i++ {
+			if matchDep5Pattern(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return len(name) > 0 && matchDep5Pattern(pattern[1:], name[1:])
+	case '\\':
+		if len(pattern) > 1 {
+			pattern = pattern[1:]
+		}
+	}
+	return len(name) > 0 && name[0] == pattern[0] && matchDep5Pattern(pattern[1:], name[1:])
+}
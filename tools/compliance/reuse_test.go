@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckREUSECompliance(t *testing.T) {
+	const (
+		spdxHeader       = "// SPDX-FileCopyrightText: 2026 Example Authors\n// SPDX-License-Identifier: Apache-2.0\n\npackage foo\n"
+		apacheHeader     = "// Copyright 2021 Google LLC\n//\n// Licensed under the Apache License\n// SPDX-License-Identifier: Apache-2.0\n"
+		copyrightOnly    = "# Copyright (C) 2026 Example Authors\n\nall:\n"
+		licenseOnly      = "/* SPDX-License-Identifier: MIT */\nint main() {}\n"
+		noHeader         = "just some text\n"
+		dep5             = "Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/\n\nFiles: docs/*.md\n  images/*\nCopyright: 2026 Example Authors\nLicense: CC-BY-4.0\n\nFiles: data/*.csv\nLicense: CC0-1.0\n"
+		companionLicense = "SPDX-FileCopyrightText: 2026 Example Authors\nSPDX-License-Identifier: CC-BY-4.0\n"
+	)
+	tests := []struct {
+		name     string
+		root     string
+		fs       fstest.MapFS
+		expected []REUSEViolation
+	}{
+		{
+			name: "compliant",
+			root: ".",
+			fs: fstest.MapFS{
+				"a.go":             {Data: []byte(spdxHeader)},
+				"src/b.go":         {Data: []byte(apacheHeader)},
+				"LICENSES/MIT.txt": {Data: []byte(noHeader)},
+				".git/config":      {Data: []byte(noHeader)},
+			},
+			expected: []REUSEViolation{},
+		},
+		{
+			name: "missingboth",
+			root: ".",
+			fs: fstest.MapFS{
+				"a.go":      {Data: []byte(spdxHeader)},
+				"README":    {Data: []byte(noHeader)},
+				"src/c.txt": {Data: []byte(noHeader)},
+			},
+			expected: []REUSEViolation{
+				{"README", MissingCopyright, REUSEWarning},
+				{"README", MissingLicense, REUSEError},
+				{"src/c.txt", MissingCopyright, REUSEWarning},
+				{"src/c.txt", MissingLicense, REUSEError},
+			},
+		},
+		{
+			name: "missingone",
+			root: ".",
+			fs: fstest.MapFS{
+				"Makefile": {Data: []byte(copyrightOnly)},
+				"main.c":   {Data: []byte(licenseOnly)},
+			},
+			expected: []REUSEViolation{
+				{"Makefile", MissingLicense, REUSEError},
+				{"main.c", MissingCopyright, REUSEWarning},
+			},
+		},
+		{
+			name: "companion",
+			root: ".",
+			fs: fstest.MapFS{
+				"logo.png":         {Data: []byte{0x89, 'P', 'N', 'G'}},
+				"logo.png.license": {Data: []byte(companionLicense)},
+				"icon.png":         {Data: []byte{0x89, 'P', 'N', 'G'}},
+			},
+			expected: []REUSEViolation{
+				{"icon.png", MissingCopyright, REUSEWarning},
+				{"icon.png", MissingLicense, REUSEError},
+			},
+		},
+		{
+			name: "dep5",
+			root: "project",
+			fs: fstest.MapFS{
+				"project/.reuse/dep5":     {Data: []byte(dep5)},
+				"project/docs/guide.md":   {Data: []byte(noHeader)},
+				"project/docs/api/ref.md": {Data: []byte(noHeader)},
+				"project/images/a.svg":    {Data: []byte(noHeader)},
+				"project/data/table.csv":  {Data: []byte(noHeader)},
+				"project/data/notes.txt":  {Data: []byte(noHeader)},
+				"project/src/main.go":     {Data: []byte(spdxHeader)},
+				"elsewhere/uncovered.go":  {Data: []byte(noHeader)},
+			},
+			expected: []REUSEViolation{
+				{"project/data/notes.txt", MissingCopyright, REUSEWarning},
+				{"project/data/notes.txt", MissingLicense, REUSEError},
+				{"project/data/table.csv", MissingCopyright, REUSEWarning},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := CheckREUSECompliance(tt.root, tt.fs)
+			t.Logf("actual: %v", actual)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("len(CheckREUSECompliance()): got %d, want %d: %v", len(actual), len(tt.expected), actual)
+			}
+			for i, v := range actual {
+				if v != tt.expected[i] {
+					t.Errorf("CheckREUSECompliance()[%d]: got %s, want %s", i, v, tt.expected[i])
+				}
+			}
+		})
+	}
+}
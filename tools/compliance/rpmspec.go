@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"android/soong/tools/compliance/spdx"
+)
+
+var (
+	// rpmGNULicense matches the RPM short names of the GNU licenses, e.g.
+	// "GPLv2+" or "LGPLv2.1".
+	rpmGNULicense = regexp.MustCompile(`(?i)^(A?GPL|LGPL|GFDL)v(\d+)(\.\d+)?(\+)?$`)
+
+	// rpmTag matches a tag of the preamble of a spec file, e.g.
+	// "License: MIT".
+	rpmTag = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)\s*:\s*(.*)$`)
+
+	// rpmMacroDefinition matches a macro definition, e.g.
+	// "%global srcname foo".
+	rpmMacroDefinition = regexp.MustCompile(`^%(?:global|define)\s+([A-Za-z_]\w*)\s+(.*)$`)
+
+	// rpmMacro matches a macro reference, e.g. "%{name}", "%{?dist}" or
+	// "%version".
+	rpmMacro = regexp.MustCompile(`%%|%\{(\??)([A-Za-z_]\w*)\}|%([A-Za-z_]\w*)`)
+
+	// rpmSection matches the lines starting the sections that end the
+	// preamble of the main package.
+	rpmSection = regexp.MustCompile(`^%(package|description|prep|build|install|check|clean|files|changelog|pre|post|preun|postun|pretrans|posttrans|generate_buildrequires|conf)\b`)
+
+	// rpmLicenses maps the lower case RPM short names, which Fedora used
+	// before adopting SPDX expressions, to the SPDX identifiers.
+	rpmLicenses = map[string]string{
+		"artistic 2.0":  "Artistic-2.0",
+		"asl 1.1":       "Apache-1.1",
+		"asl 2.0":       "Apache-2.0",
+		"boost":         "BSL-1.0",
+		"cc0":           "CC0-1.0",
+		"gpl+":          "GPL-1.0-or-later",
+		"mplv1.1":       "MPL-1.1",
+		"mplv2.0":       "MPL-2.0",
+		"ofl":           "OFL-1.1",
+		"openssl":       "OpenSSL",
+		"public domain": "LicenseRef-Fedora-Public-Domain",
+		"python":        "Python-2.0",
+	}
+)
+
+// ParseRPMLicense returns the SPDX expression for the License tag `spec` of
+// an RPM spec file, e.g. "MIT OR Apache-2.0" for "MIT or ASL 2.0".
+//
+// Translates the RPM short names, e.g. "GPL-2.0-or-later" for "GPLv2+" and,
+// as Fedora did, "LGPL-2.1-only" for "LGPLv2". Names that are already SPDX
+// identifiers stay as they are. Other names of several words become
+// LicenseRefs, e.g. "LicenseRef-RPM-Copyright-only" for "Copyright only".
+//
+// Returns an error when the tag does not translate into a valid SPDX
+// expression.
+func ParseRPMLicense(spec string) (string, error) {
+	expr, err := rpmExpression(spec)
+	if err != nil {
+		return "", fmt.Errorf("invalid License %q: %w", spec, err)
+	}
+	return expr.String(), nil
+}
+
+// rpmExpression returns the SPDX expression for the RPM License tag `spec`.
+func rpmExpression(spec string) (spdx.Expression, error) {
+	words := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(spec))
+	var tokens, name []string
+	flush := func() {
+		if len(name) > 0 {
+			tokens = append(tokens, rpmLicenseID(name))
+			name = nil
+		}
+	}
+	for _, w := range words {
+		switch strings.ToLower(w) {
+		case "(", ")", "and", "or":
+			flush()
+			tokens = append(tokens, strings.ToUpper(w))
+		default:
+			name = append(name, w)
+		}
+	}
+	flush()
+	return spdx.ParseExpression(strings.Join(tokens, " "))
+}
+
+// rpmLicenseID returns the SPDX identifier, or the identifier WITH the
+// exception, for the RPM license name of `words`, e.g. "Apache-2.0" for
+// "ASL 2.0".
+func rpmLicenseID(words []string) string {
+	for i, w := range words {
+		if i == 0 || !strings.EqualFold(w, "with") {
+			continue
+		}
+		exception := words[i+1:]
+		if n := len(exception); n > 1 && strings.EqualFold(exception[n-1], "exception") {
+			// E.g. "GPLv2+ with OpenSSL exception".
+			exception = []string{strings.Join(exception[:n-1], "-") + "-exception"}
+		}
+		if len(exception) == 1 && !strings.EqualFold(exception[0], "exceptions") {
+			return rpmLicenseID(words[:i]) + " WITH " + exception[0]
+		}
+		break
+	}
+	name := strings.Join(words, " ")
+	if id, ok := rpmLicenses[strings.ToLower(name)]; ok {
+		return id
+	}
+	if m := rpmGNULicense.FindStringSubmatch(name); m != nil {
+		family := strings.ToUpper(m[1])
+		version := m[2] + firstNonEmpty(m[3], ".0")
+		if family == "LGPL" && len(m[3]) == 0 && m[2] == "2" {
+			// Fedora used "LGPLv2" for version 2.1.
+			version = "2.1"
+		}
+		return canonicalSpdxID(family + "-" + version + m[4])
+	}
+	for id := range spdxConditions {
+		if strings.EqualFold(id, name) {
+			return id
+		}
+	}
+	if len(words) > 1 {
+		// LicenseRefs cannot contain "+".
+		return "LicenseRef-RPM-" + strings.ReplaceAll(strings.Join(words, "-"), "+", "-or-later")
+	}
+	return name
+}
+
+// rpmPreamble returns the tags of the preamble of the main package of the
+// spec file `data` by lower case name, with the macros expanded that the
+// spec file defines, that `macros` define or that name the tags, e.g.
+// "%{name}".
+//
+// Keeps the first value of repeated tags, and reads the lines of both
+// branches of conditionals.
+func rpmPreamble(data []byte, macros map[string]string) (map[string]string, error) {
+	defined := make(map[string]string)
+	for name, value := range macros {
+		defined[name] = value
+	}
+	tags := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if rpmSection.MatchString(text) {
+			break
+		}
+		if m := rpmMacroDefinition.FindStringSubmatch(text); m != nil {
+			defined[m[1]] = expandRPMMacros(strings.TrimSpace(m[2]), defined)
+			continue
+		}
+		m := rpmTag.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		tag := strings.ToLower(m[1])
+		if _, ok := tags[tag]; ok {
+			continue
+		}
+		value := expandRPMMacros(m[2], defined)
+		tags[tag] = value
+		if _, ok := defined[tag]; !ok {
+			defined[tag] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// expandRPMMacros returns `s` with the references to the macros in `macros`
+// replaced by their values, the conditional references to other macros
+// dropped, e.g. "%{?dist}", and "%%" replaced by "%".
+func expandRPMMacros(s string, macros map[string]string) string {
+	return rpmMacro.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "%%" {
+			return "%"
+		}
+		m := rpmMacro.FindStringSubmatch(ref)
+		name := firstNonEmpty(m[2], m[3])
+		if value, ok := macros[name]; ok {
+			return value
+		}
+		if m[1] == "?" {
+			return ""
+		}
+		return ref
+	})
+}
+
+// RPMSpecLicenseExtractor describes RPM packages with MetaLicStubs using the
+// License tag of their spec files.
+type RPMSpecLicenseExtractor struct {
+	// Macros defines macros for the spec files, like `rpmbuild --define`,
+	// e.g. a version the build sets, or is nil.
+	Macros map[string]string
+}
+
+// Extract returns the MetaLicStub named "<specPath>.meta_lic" for the main
+// package of the spec file `specPath` in `fsys`.
+//
+// The license kinds come from the SPDX identifiers the License tag
+// translates to per ParseRPMLicense. Licenses naming no known license make
+// the license kind unidentified. The package name and version come from the
+// Name and Version tags, with the spec file name as the fallback package
+// name.
+//
+// Returns an *UnknownLicenseError when the preamble has no License tag.
+func (e *RPMSpecLicenseExtractor) Extract(fsys fs.FS, specPath string) (MetaLicStub, error) {
+	data, err := fs.ReadFile(fsys, specPath)
+	if err != nil {
+		return MetaLicStub{}, err
+	}
+	tags, err := rpmPreamble(data, e.Macros)
+	if err != nil {
+		return MetaLicStub{}, fmt.Errorf("cannot read spec file %q: %w", specPath, err)
+	}
+	license := strings.TrimSpace(tags["license"])
+	if len(license) == 0 {
+		return MetaLicStub{}, &UnknownLicenseError{specPath, "no License tag"}
+	}
+	expr, err := rpmExpression(license)
+	if err != nil {
+		return MetaLicStub{}, fmt.Errorf("invalid License %q in spec file %q: %w", license, specPath, err)
+	}
+
+	stub := MetaLicStub{
+		Name:        specPath + ".meta_lic",
+		PackageName: firstNonEmpty(strings.TrimSpace(tags["name"]), strings.TrimSuffix(path.Base(specPath), ".spec")),
+		Version:     strings.TrimSpace(tags["version"]),
+	}
+	kinds := make(map[string]string)
+	for _, id := range expr.Licenses() {
+		if kind, condition, ok := spdxLicenseKind(id); ok {
+			kinds[kind] = condition
+		} else {
+			// Licenses naming no known license need someone to review
+			// them.
+			kinds[unidentifiedLicenseKind] = "by_exception_only"
+		}
+	}
+	conditions := make(map[string]struct{})
+	for kind, condition := range kinds {
+		stub.LicenseKinds = append(stub.LicenseKinds, kind)
+		conditions[condition] = struct{}{}
+	}
+	for condition := range conditions {
+		stub.LicenseConditions = append(stub.LicenseConditions, condition)
+	}
+	sort.Strings(stub.LicenseKinds)
+	sort.Strings(stub.LicenseConditions)
+	return stub, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseRPMLicense(t *testing.T) {
+	tests := map[string]string{
+		"GPLv2+":                               "GPL-2.0-or-later",
+		"GPLv3":                                "GPL-3.0-only",
+		"LGPLv2 and MIT":                       "LGPL-2.1-only AND MIT",
+		"LGPLv2.1+":                            "LGPL-2.1-or-later",
+		"LGPLv3+":                              "LGPL-3.0-or-later",
+		"MIT or ASL 2.0":                       "MIT OR Apache-2.0",
+		"(GPLv2+ or MIT) and zlib":             "(GPL-2.0-or-later OR MIT) AND Zlib",
+		"GPLv2+ or MIT and BSD-3-Clause":       "GPL-2.0-or-later OR MIT AND BSD-3-Clause",
+		"GPLv2+ with OpenSSL exception":        "GPL-2.0-or-later WITH OpenSSL-exception",
+		"GPLv2 with exceptions":                "LicenseRef-RPM-GPLv2-with-exceptions",
+		"GPLv2+ with exceptions":               "LicenseRef-RPM-GPLv2-or-later-with-exceptions",
+		"Apache-2.0 AND (MIT OR BSD-2-Clause)": "Apache-2.0 AND (MIT OR BSD-2-Clause)",
+		"GPL-2.0-only WITH Linux-syscall-note": "GPL-2.0-only WITH Linux-syscall-note",
+		"Public Domain":                        "LicenseRef-Fedora-Public-Domain",
+		"Copyright only and Boost":             "LicenseRef-RPM-Copyright-only AND BSL-1.0",
+		"  MPLv2.0   or\tLGPLv2+ ":             "MPL-2.0 OR LGPL-2.1-or-later",
+		"isc":                                  "ISC",
+	}
+	for spec, expected := range tests {
+		actual, err := ParseRPMLicense(spec)
+		if err != nil {
+			t.Errorf("ParseRPMLicense(%q): got error %s, want no error", spec, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("ParseRPMLicense(%q): got %q, want %q", spec, actual, expected)
+		}
+	}
+}
+
+func TestParseRPMLicenseErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                "empty license expression",
+		"MIT or":          `invalid License "MIT or"`,
+		"(MIT and Zlib":   `invalid License "(MIT and Zlib"`,
+		"MIT and or Zlib": `invalid License "MIT and or Zlib"`,
+		"GPLv2+, LGPLv2+": `invalid character ','`,
+	}
+	for spec, expectedError := range tests {
+		_, err := ParseRPMLicense(spec)
+		if err == nil {
+			t.Errorf("ParseRPMLicense(%q): got no error, want %q", spec, expectedError)
+			continue
+		}
+		if !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("ParseRPMLicense(%q): got error %q, want %q", spec, err, expectedError)
+		}
+	}
+}
+
+const fooSpec = `# Spec file for foo.
+%global srcname foo
+%define libname lib%{srcname}
+
+Name:           %{libname}
+Version:        %{ver}
+Release:        1%{?dist}
+Summary:        The foo library
+License:        LGPLv2 and (MIT or ASL 2.0)
+License:        GPLv3
+URL:            https://example.com/%{srcname}
+
+%package devel
+Summary:        Headers for %{name}
+License:        GPLv2+
+
+%description
+The foo library.
+License: Copyright only
+`
+
+func TestRPMSpecLicenseExtractor(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rpm/foo.spec":     {Data: []byte(fooSpec)},
+		"rpm/bar.spec":     {Data: []byte("License: GPLv2+ with exceptions\n")},
+		"rpm/nolic.spec":   {Data: []byte("Name: nolic\n\n%description\nLicense: MIT\n")},
+		"rpm/invalid.spec": {Data: []byte("Name: invalid\nLicense: MIT and\n")},
+	}
+	e := &RPMSpecLicenseExtractor{Macros: map[string]string{"ver": "2.3"}}
+
+	stub, err := e.Extract(fsys, "rpm/foo.spec")
+	if err != nil {
+		t.Fatalf("Extract(foo): got error %s, want no error", err)
+	}
+	expected := MetaLicStub{
+		Name:              "rpm/foo.spec.meta_lic",
+		PackageName:       "libfoo",
+		Version:           "2.3",
+		LicenseKinds:      []string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-LGPL-2.1-only", "SPDX-license-identifier-MIT"},
+		LicenseConditions: []string{"notice", "restricted_if_statically_linked"},
+	}
+	if !reflect.DeepEqual(stub, expected) {
+		t.Errorf("Extract(foo): got %#v, want %#v", stub, expected)
+	}
+
+	// The license graph reader accepts the stub.
+	paths, err := WriteMetaLicStubs(t.TempDir(), []MetaLicStub{stub})
+	if err != nil {
+		t.Fatalf("WriteMetaLicStubs(): got error %s, want no error", err)
+	}
+	if _, err := ReadLicenseGraph(FS, &bytes.Buffer{}, paths); err != nil {
+		t.Errorf("ReadLicenseGraph(stub): got error %s, want no error", err)
+	}
+
+	// The package name falls back to the spec file name, and unknown
+	// licenses make the license kind unidentified.
+	stub, err = (&RPMSpecLicenseExtractor{}).Extract(fsys, "rpm/bar.spec")
+	if err != nil {
+		t.Fatalf("Extract(bar): got error %s, want no error", err)
+	}
+	if stub.PackageName != "bar" || !reflect.DeepEqual(stub.LicenseKinds, []string{unidentifiedLicenseKind}) {
+		t.Errorf("Extract(bar): got %#v, want bar with unidentified license kind", stub)
+	}
+
+	var unknown *UnknownLicenseError
+	if _, err := e.Extract(fsys, "rpm/nolic.spec"); !errors.As(err, &unknown) {
+		t.Errorf("Extract(nolic): got error %v, want *UnknownLicenseError", err)
+	}
+	if _, err := e.Extract(fsys, "rpm/invalid.spec"); err == nil || !strings.Contains(err.Error(), `invalid License "MIT and" in spec file "rpm/invalid.spec"`) {
+		t.Errorf("Extract(invalid): got error %v, want invalid License", err)
+	}
+	if _, err := e.Extract(fsys, "rpm/missing.spec"); err == nil {
+		t.Errorf("Extract(missing): got no error, want error")
+	}
+}
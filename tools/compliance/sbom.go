@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"android/soong/tools/compliance/spdx"
+)
+
+// unsafeStubNameChars matches the characters not to use in the file names
+// of the MetaLicStubs for SBOM packages.
+var unsafeStubNameChars = regexp.MustCompile(`[^A-Za-z0-9._+@-]`)
+
+// ExtractSPDXSBOMLicenses returns `container` depending on a MetaLicStub for
+// each package of the SPDX JSON document `data` other than those the
+// document describes, e.g. the image itself, followed by those stubs.
+//
+// The stub names start with the Name of `container` without ".meta_lic",
+// e.g. "app/zlib@1.3.meta_lic" for the container "app.meta_lic".
+// The license kinds come from the concluded, or else the declared, license
+// of each package. Licenses naming no known license and packages without
+// any license make the license kind unidentified.
+func ExtractSPDXSBOMLicenses(container MetaLicStub, data []byte) ([]MetaLicStub, error) {
+	packages, err := parseSPDXPackages(data)
+	if err != nil {
+		return nil, err
+	}
+	return sbomStubs(container, packages), nil
+}
+
+// ExtractCycloneDXSBOMLicenses returns `container` depending on a
+// MetaLicStub for each component, including the nested components, of the
+// CycloneDX JSON BOM `data` followed by those stubs.
+//
+// Names the stubs and picks their license kinds like
+// ExtractSPDXSBOMLicenses using the SPDX identifiers, expressions or
+// well-known license URLs of the components.
+func ExtractCycloneDXSBOMLicenses(container MetaLicStub, data []byte) ([]MetaLicStub, error) {
+	packages, err := parseCycloneDXPackages(data)
+	if err != nil {
+		return nil, err
+	}
+	return sbomStubs(container, packages), nil
+}
+
+// sbomPackage is a package an SBOM lists with its licenses, e.g. SPDX
+// license expressions, license names or URLs.
+type sbomPackage struct {
+	name     string
+	version  string
+	licenses []string
+}
+
+// parseSPDXPackages returns the packages of the SPDX JSON document `data`
+// other than those the document describes, e.g. the image itself.
+//
+// Prefers the concluded license over the declared license of each package.
+func parseSPDXPackages(data []byte) ([]sbomPackage, error) {
+	var doc struct {
+		SPDXVersion       string   `json:"spdxVersion"`
+		DocumentDescribes []string `json:"documentDescribes"`
+		Packages          []struct {
+			SPDXID           string `json:"SPDXID"`
+			Name             string `json:"name"`
+			VersionInfo      string `json:"versionInfo"`
+			LicenseConcluded string `json:"licenseConcluded"`
+			LicenseDeclared  string `json:"licenseDeclared"`
+		} `json:"packages"`
+		Relationships []struct {
+			Element string `json:"spdxElementId"`
+			Type    string `json:"relationshipType"`
+			Related string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.SPDXVersion, "SPDX-") {
+		return nil, fmt.Errorf("not an SPDX JSON document")
+	}
+	described := make(map[string]bool)
+	for _, id := range doc.DocumentDescribes {
+		described[id] = true
+	}
+	for _, r := range doc.Relationships {
+		if r.Element == "SPDXRef-DOCUMENT" && r.Type == "DESCRIBES" {
+			described[r.Related] = true
+		}
+	}
+	var packages []sbomPackage
+	for _, p := range doc.Packages {
+		if described[p.SPDXID] {
+			continue
+		}
+		pkg := sbomPackage{name: p.Name, version: p.VersionInfo}
+		license := p.LicenseConcluded
+		if !isSPDXLicense(license) {
+			license = p.LicenseDeclared
+		}
+		if isSPDXLicense(license) {
+			pkg.licenses = []string{license}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// isSPDXLicense returns true when the SPDX license field `license` names a
+// license rather than NOASSERTION or NONE.
+func isSPDXLicense(license string) bool {
+	return len(license) > 0 && license != "NOASSERTION" && license != "NONE"
+}
+
+// cycloneDXComponent is a component of a CycloneDX JSON BOM.
+type cycloneDXComponent struct {
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Licenses []struct {
+		License *struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseCycloneDXPackages returns the components, including the nested
+// components, of the CycloneDX JSON BOM `data`.
+func parseCycloneDXPackages(data []byte) ([]sbomPackage, error) {
+	var bom struct {
+		BOMFormat  string               `json:"bomFormat"`
+		Components []cycloneDXComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, err
+	}
+	if bom.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("not a CycloneDX JSON BOM")
+	}
+	var packages []sbomPackage
+	var walk func([]cycloneDXComponent)
+	walk = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			pkg := sbomPackage{name: c.Name, version: c.Version}
+			if len(c.Group) > 0 {
+				pkg.name = c.Group + "/" + c.Name
+			}
+			for _, l := range c.Licenses {
+				if l.License != nil {
+					pkg.licenses = append(pkg.licenses, firstNonEmpty(l.License.ID, l.License.URL, l.License.Name))
+				} else if len(l.Expression) > 0 {
+					pkg.licenses = append(pkg.licenses, l.Expression)
+				}
+			}
+			packages = append(packages, pkg)
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return packages, nil
+}
+
+// sbomStubs returns `container` depending on a stub for each of the SBOM
+// `packages` followed by those stubs.
+func sbomStubs(container MetaLicStub, packages []sbomPackage) []MetaLicStub {
+	base := strings.TrimSuffix(container.Name, ".meta_lic")
+	// byName merges the packages an SBOM lists more than once.
+	byName := make(map[string]*MetaLicStub)
+	var names []string
+	for _, pkg := range packages {
+		if len(pkg.name) == 0 {
+			continue
+		}
+		stubName := unsafeStubNameChars.ReplaceAllString(pkg.name, "_")
+		if len(pkg.version) > 0 {
+			stubName += "@" + unsafeStubNameChars.ReplaceAllString(pkg.version, "_")
+		}
+		stubName = base + "/" + stubName + ".meta_lic"
+		stub, ok := byName[stubName]
+		if !ok {
+			stub = &MetaLicStub{Name: stubName, PackageName: pkg.name, Version: pkg.version}
+			byName[stubName] = stub
+			names = append(names, stubName)
+		}
+		stub.LicenseKinds = append(stub.LicenseKinds, sbomLicenseKinds(pkg.licenses)...)
+	}
+	sort.Strings(names)
+	stubs := []MetaLicStub{container}
+	for _, stubName := range names {
+		stub := byName[stubName]
+		kinds := make(map[string]string)
+		for _, kind := range stub.LicenseKinds {
+			kinds[kind] = "by_exception_only"
+			if id := strings.TrimPrefix(kind, spdxLicenseKindPrefix); id != kind {
+				kinds[kind] = spdxConditions[id]
+			}
+		}
+		if len(kinds) == 0 {
+			// Packages without a license need someone to review them.
+			kinds[unidentifiedLicenseKind] = "by_exception_only"
+		}
+		stub.LicenseKinds = nil
+		conditions := make(map[string]struct{})
+		for kind, condition := range kinds {
+			stub.LicenseKinds = append(stub.LicenseKinds, kind)
+			conditions[condition] = struct{}{}
+		}
+		for condition := range conditions {
+			stub.LicenseConditions = append(stub.LicenseConditions, condition)
+		}
+		sort.Strings(stub.LicenseKinds)
+		sort.Strings(stub.LicenseConditions)
+		stubs[0].Deps = append(stubs[0].Deps, stub.Name)
+		stubs = append(stubs, *stub)
+	}
+	return stubs
+}
+
+// sbomLicenseKinds returns the license kinds for the SBOM `licenses`, each
+// an SPDX license expression, license name or URL.
+func sbomLicenseKinds(licenses []string) []string {
+	var kinds []string
+	for _, license := range licenses {
+		names := []string{license}
+		if expr, err := spdx.ParseExpression(license); err == nil {
+			names = expr.Licenses()
+		}
+		for _, name := range names {
+			ids := licenseIDs([]string{name})
+			if len(ids) == 0 {
+				// Licenses naming no known license need someone to
+				// review them.
+				kinds = append(kinds, unidentifiedLicenseKind)
+			}
+			for _, id := range ids {
+				kinds = append(kinds, spdxLicenseKindPrefix+id)
+			}
+		}
+	}
+	return kinds
+}
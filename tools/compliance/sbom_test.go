@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testSPDXPredicate = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "app",
+  "packages": [
+    {"SPDXID": "SPDXRef-image", "name": "app", "licenseConcluded": "NOASSERTION"},
+    {"SPDXID": "SPDXRef-zlib", "name": "zlib", "versionInfo": "1.3", "licenseConcluded": "Zlib", "licenseDeclared": "NOASSERTION"},
+    {"SPDXID": "SPDXRef-openssl", "name": "openssl", "versionInfo": "3.0.2", "licenseConcluded": "NOASSERTION", "licenseDeclared": "Apache-2.0 AND MIT"},
+    {"SPDXID": "SPDXRef-tool", "name": "vendor/tool", "licenseConcluded": "LicenseRef-Proprietary"},
+    {"SPDXID": "SPDXRef-data", "name": "data", "versionInfo": "1", "licenseConcluded": "NONE"}
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-image"}
+  ]
+}`
+
+const testCycloneDXPredicate = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"type": "container", "name": "app"}},
+  "components": [
+    {"type": "library", "group": "org.example", "name": "core", "version": "2.0",
+     "licenses": [{"license": {"url": "https://www.apache.org/licenses/LICENSE-2.0.txt"}}],
+     "components": [{"type": "library", "name": "shaded", "version": "0.1", "licenses": [{"expression": "MIT OR ISC"}]}]},
+    {"type": "library", "name": "left-pad", "version": "1.3.0", "licenses": [{"license": {"id": "MIT"}}]}
+  ]
+}`
+
+func TestExtractSPDXSBOMLicenses(t *testing.T) {
+	container := MetaLicStub{Name: "app@1.meta_lic", PackageName: "app", Version: "1", IsContainer: true}
+	expected := []MetaLicStub{
+		{
+			Name:        "app@1.meta_lic",
+			PackageName: "app",
+			Version:     "1",
+			IsContainer: true,
+			Deps: []string{
+				"app@1/data@1.meta_lic",
+				"app@1/openssl@3.0.2.meta_lic",
+				"app@1/vendor_tool.meta_lic",
+				"app@1/zlib@1.3.meta_lic",
+			},
+		},
+		{Name: "app@1/data@1.meta_lic", PackageName: "data", Version: "1", LicenseKinds: []string{"legacy_by_exception_only"}, LicenseConditions: []string{"by_exception_only"}},
+		{Name: "app@1/openssl@3.0.2.meta_lic", PackageName: "openssl", Version: "3.0.2", LicenseKinds: []string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-MIT"}, LicenseConditions: []string{"notice"}},
+		{Name: "app@1/vendor_tool.meta_lic", PackageName: "vendor/tool", LicenseKinds: []string{"legacy_by_exception_only"}, LicenseConditions: []string{"by_exception_only"}},
+		{Name: "app@1/zlib@1.3.meta_lic", PackageName: "zlib", Version: "1.3", LicenseKinds: []string{"SPDX-license-identifier-Zlib"}, LicenseConditions: []string{"notice"}},
+	}
+	stubs, err := ExtractSPDXSBOMLicenses(container, []byte(testSPDXPredicate))
+	if err != nil {
+		t.Fatalf("ExtractSPDXSBOMLicenses(): got error %s, want no error", err)
+	}
+	if !reflect.DeepEqual(stubs, expected) {
+		t.Errorf("ExtractSPDXSBOMLicenses(): got %+v, want %+v", stubs, expected)
+	}
+
+	if _, err := ExtractSPDXSBOMLicenses(container, []byte(`{"name": "not spdx"}`)); err == nil || !strings.Contains(err.Error(), "not an SPDX JSON document") {
+		t.Errorf("ExtractSPDXSBOMLicenses(not spdx): got error %v, want not an SPDX JSON document", err)
+	}
+}
+
+func TestExtractCycloneDXSBOMLicenses(t *testing.T) {
+	container := MetaLicStub{Name: "app.meta_lic", PackageName: "app", IsContainer: true}
+	stubs, err := ExtractCycloneDXSBOMLicenses(container, []byte(testCycloneDXPredicate))
+	if err != nil {
+		t.Fatalf("ExtractCycloneDXSBOMLicenses(): got error %s, want no error", err)
+	}
+
+	// The license graph reader accepts the stubs.
+	rootFS := make(fstest.MapFS)
+	for _, stub := range stubs {
+		rootFS[stub.Name] = &fstest.MapFile{Data: []byte(stub.String())}
+	}
+	lg, err := ReadLicenseGraph(rootFS, &bytes.Buffer{}, []string{stubs[0].Name})
+	if err != nil {
+		t.Fatalf("ReadLicenseGraph(): got error %s, want no error", err)
+	}
+	var actual []string
+	for _, tn := range lg.Targets() {
+		actual = append(actual, tn.PackageName()+" "+strings.Join(tn.LicenseKinds(), ","))
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"app ",
+		"left-pad SPDX-license-identifier-MIT",
+		"org.example/core SPDX-license-identifier-Apache-2.0",
+		"shaded SPDX-license-identifier-ISC,SPDX-license-identifier-MIT",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ExtractCycloneDXSBOMLicenses(): got %q, want %q", actual, expected)
+	}
+	if edges := lg.Edges(); len(edges) != 3 {
+		t.Errorf("ExtractCycloneDXSBOMLicenses(): got %d edges, want 3", len(edges))
+	}
+
+	if _, err := ExtractCycloneDXSBOMLicenses(container, []byte(`{"bomFormat": "SPDX"}`)); err == nil || !strings.Contains(err.Error(), "not a CycloneDX JSON BOM") {
+		t.Errorf("ExtractCycloneDXSBOMLicenses(not cyclonedx): got error %v, want not a CycloneDX JSON BOM", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"sort"
+	"strings"
+)
+
+// SimilarityScore returns how alike license texts `a` and `b` are on a scale
+// from 0.0 (nothing in common) to 1.0 (identical).
+//
+// The score is 1 minus the normalized edit distance: the Levenshtein distance
+// divided by the length of the longer text. Texts get compared word by word
+// ignoring case and whitespace so that reflowed paragraphs score as
+// identical and a changed year or URL counts as a single edit.
+func SimilarityScore(a, b string) float64 {
+	return similarityScore(similarityWords(a), similarityWords(b))
+}
+
+// GroupSimilarLicenses clusters `texts` into groups whose members score at
+// least `threshold` with at least one other member of the same group.
+//
+// Every index of `texts` appears in exactly one group. Groups are ordered by
+// their smallest index, and each group lists its indexes in ascending order.
+func GroupSimilarLicenses(texts []string, threshold float64) [][]int {
+	words := make([][]string, 0, len(texts))
+	for _, text := range texts {
+		words = append(words, similarityWords(text))
+	}
+
+	// parent implements a union-find forest over the indexes of `texts`.
+	parent := make([]int, len(texts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < len(words); i++ {
+		for j := i + 1; j < len(words); j++ {
+			ri, rj := find(i), find(j)
+			if ri == rj {
+				continue
+			}
+			// The edit distance is at least the difference in length so skip
+			// the expensive comparison when it cannot reach the threshold.
+			if maxSimilarity(len(words[i]), len(words[j])) < threshold {
+				continue
+			}
+			if similarityScore(words[i], words[j]) >= threshold {
+				if ri < rj {
+					parent[rj] = ri
+				} else {
+					parent[ri] = rj
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range texts {
+		r := find(i)
+		groups[r] = append(groups[r], i)
+	}
+	result := make([][]int, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i][0] < result[j][0] })
+	return result
+}
+
+// similarityWords splits `text` into lower-case words for comparison.
+func similarityWords(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+// maxSimilarity returns the best score possible for texts of `la` and `lb`
+// words.
+func maxSimilarity(la, lb int) float64 {
+	if la == 0 && lb == 0 {
+		return 1.0
+	}
+	longest, diff := la, la-lb
+	if lb > la {
+		longest, diff = lb, lb-la
+	}
+	return 1.0 - float64(diff)/float64(longest)
+}
+
+// similarityScore returns 1 minus the normalized Levenshtein distance between
+// word lists `a` and `b`.
+func similarityScore(a, b []string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1.0
+	}
+	return 1.0 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein returns the minimum number of word insertions, deletions and
+// substitutions transforming `a` into `b`.
+func levenshtein(a, b []string) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
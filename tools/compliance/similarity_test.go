@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+const (
+	mitText2020 = `Copyright (c) 2020 Example Authors
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+	bsd2Text = `Copyright (c) 2021 Example Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+	bsd3Clause = `3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+`
+
+	apacheNoticeText = `Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+`
+)
+
+var (
+	// mitText2023 differs from mitText2020 only by year and holder.
+	mitText2023 = strings.Replace(mitText2020, "2020 Example Authors", "2023 Other Example Contributors", 1)
+
+	// mitReflowed differs from mitText2020 only by line breaks and case.
+	mitReflowed = strings.ToUpper(strings.Join(strings.Fields(mitText2020), " "))
+
+	// bsd3Text adds the non-endorsement clause to bsd2Text.
+	bsd3Text = strings.Replace(bsd2Text, "THIS SOFTWARE", bsd3Clause+"THIS SOFTWARE", 1)
+)
+
+func TestSimilarityScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{"identical", mitText2020, mitText2020, 1.0, 1.0},
+		{"reflowed", mitText2020, mitReflowed, 1.0, 1.0},
+		{"bothempty", "", "  \n", 1.0, 1.0},
+		{"oneempty", mitText2020, "", 0.0, 0.0},
+		{"mitvariants", mitText2020, mitText2023, 0.95, 0.99},
+		{"bsd2vsbsd3", bsd2Text, bsd3Text, 0.85, 0.95},
+		{"mitvsbsd", mitText2020, bsd2Text, 0.0, 0.5},
+		{"mitvsapache", mitText2020, apacheNoticeText, 0.0, 0.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := SimilarityScore(tt.a, tt.b)
+			if actual < tt.min || actual > tt.max {
+				t.Errorf("SimilarityScore(): got %f, want between %f and %f", actual, tt.min, tt.max)
+			}
+			if reversed := SimilarityScore(tt.b, tt.a); reversed != actual {
+				t.Errorf("SimilarityScore() reversed: got %f, want %f", reversed, actual)
+			}
+		})
+	}
+}
+
+func TestGroupSimilarLicenses(t *testing.T) {
+	texts := []string{mitText2020, bsd2Text, apacheNoticeText, mitText2023, bsd3Text, mitReflowed}
+	tests := []struct {
+		name      string
+		threshold float64
+		expected  [][]int
+	}{
+		{"exact", 1.0, [][]int{{0, 5}, {1}, {2}, {3}, {4}}},
+		{"nearduplicates", 0.9, [][]int{{0, 3, 5}, {1}, {2}, {4}}},
+		{"loose", 0.8, [][]int{{0, 3, 5}, {1, 4}, {2}}},
+		{"everything", 0.0, [][]int{{0, 1, 2, 3, 4, 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := GroupSimilarLicenses(texts, tt.threshold)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("GroupSimilarLicenses(%f): got %v, want %v", tt.threshold, actual, tt.expected)
+			}
+		})
+	}
+	if actual := GroupSimilarLicenses(nil, 0.9); len(actual) != 0 {
+		t.Errorf("GroupSimilarLicenses(nil): got %v, want no groups", actual)
+	}
+}
+
+func TestMergeSimilarTexts(t *testing.T) {
+	fs := &testfs.TestFS{
+		"bin.meta_lic": []byte(AOSP +
+			"license_texts: \"LICENSE\"\n" +
+			"installed: \"out/bin/bin1\"\n" +
+			"deps: {\n  file: \"liba.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"libb.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"libc.meta_lic\"\n  annotations: \"static\"\n}\n"),
+		"liba.meta_lic": []byte("package_name: \"liba\"\nlicense_conditions: \"notice\"\nlicense_texts: \"liba/LICENSE\"\n"),
+		"libb.meta_lic": []byte("package_name: \"libb\"\nlicense_conditions: \"notice\"\nlicense_texts: \"libb/LICENSE\"\n"),
+		"libc.meta_lic": []byte("package_name: \"libc\"\nlicense_conditions: \"notice\"\nlicense_texts: \"libc/LICENSE\"\n"),
+		"LICENSE":       []byte(apacheNoticeText),
+		"liba/LICENSE":  []byte(mitText2020),
+		"libb/LICENSE":  []byte(mitText2023),
+		"libc/LICENSE":  []byte(bsd2Text),
+	}
+	stderr := &bytes.Buffer{}
+	lg, err := ReadLicenseGraph(fs, stderr, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(fs, lg, nil)
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	sections := func() []string {
+		result := make([]string, 0)
+		for _, h := range ni.AllHashes() {
+			result = append(result, strings.Join(ni.HashLibs(h), ","))
+		}
+		sort.Strings(result)
+		return result
+	}
+	if actual, expected := sections(), []string{"Android", "liba", "libb", "libc"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("before merging: got sections %q, want %q", actual, expected)
+	}
+
+	ni.MergeSimilarTexts(0.9)
+
+	if actual, expected := sections(), []string{"Android", "liba,libb", "libc"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("after merging: got sections %q, want %q", actual, expected)
+	}
+	for _, h := range ni.AllHashes() {
+		if len(ni.TextContent(h)) == 0 {
+			t.Errorf("after merging: got empty text for %s, want text", h)
+		}
+	}
+	for _, installPath := range ni.AllInstallPaths() {
+		for _, h := range ni.InstallHashes(installPath) {
+			if _, ok := ni.hashLibInstall[h]; !ok {
+				t.Errorf("after merging: install path %q references merged hash %s", installPath, h)
+			}
+		}
+	}
+}
+
+func TestMergeSimilarTextsSortedInstallPaths(t *testing.T) {
+	// Two binaries use library "liba" with texts that differ only in the
+	// copyright year.
+	fs := &testfs.TestFS{
+		"bin1.meta_lic": []byte(AOSP +
+			"installed: \"out/bin/bin1\"\n" +
+			"deps: {\n  file: \"liba.meta_lic\"\n  annotations: \"static\"\n}\n"),
+		"bin2.meta_lic": []byte(AOSP +
+			"installed: \"out/bin/bin2\"\n" +
+			"deps: {\n  file: \"liba2.meta_lic\"\n  annotations: \"static\"\n}\n"),
+		"liba.meta_lic":  []byte("package_name: \"liba\"\nlicense_conditions: \"notice\"\nlicense_texts: \"liba/LICENSE\"\n"),
+		"liba2.meta_lic": []byte("package_name: \"liba\"\nlicense_conditions: \"notice\"\nlicense_texts: \"liba2/LICENSE\"\n"),
+		"liba/LICENSE":   []byte(mitText2020),
+		"liba2/LICENSE":  []byte(mitText2023),
+	}
+	lg, err := ReadLicenseGraph(fs, &bytes.Buffer{}, []string{"bin1.meta_lic", "bin2.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+	ni, err := IndexLicenseTexts(fs, lg, nil)
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	// Cache the install paths of each text before merging.
+	for _, h := range ni.AllHashes() {
+		if actual := ni.SortedInstallPaths(h, "liba"); len(actual) != 1 {
+			t.Errorf("before merging: got install paths %q for %s, want 1", actual, h)
+		}
+	}
+
+	ni.MergeSimilarTexts(0.9)
+
+	hashes := ni.AllHashes()
+	if len(hashes) != 1 {
+		t.Fatalf("after merging: got %d hashes, want 1", len(hashes))
+	}
+	expected := []string{"out/bin/bin1", "out/bin/bin2"}
+	if actual := ni.SortedInstallPaths(hashes[0], "liba"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("after merging: got install paths %q, want %q", actual, expected)
+	}
+}
+
+func BenchmarkSimilarityScore(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		SimilarityScore(bsd2Text, bsd3Text)
+	}
+}
+
+func BenchmarkGroupSimilarLicenses(b *testing.B) {
+	texts := []string{mitText2020, bsd2Text, apacheNoticeText, mitText2023, bsd3Text, mitReflowed}
+	for i := 0; i < 4; i++ {
+		texts = append(texts, texts...)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GroupSimilarLicenses(texts, 0.9)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spdx parses and evaluates SPDX 2.3 license expressions.
+//
+// e.g. "Apache-2.0 AND MIT" or "(GPL-2.0-only WITH Classpath-exception-2.0) OR MIT"
+//
+// See https://spdx.github.io/spdx-spec/v2.3/SPDX-license-expressions/
+package spdx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expression is a node in the abstract syntax tree for a license expression.
+type Expression interface {
+	// Satisfies returns true when the expression can be met using only the
+	// licenses in `allowed`.
+	//
+	// An allowed license also allows that license with any exception
+	// because exceptions only grant additional permissions.
+	Satisfies(allowed []string) bool
+
+	// Licenses returns the license identifiers appearing in the expression in
+	// order of appearance.
+	Licenses() []string
+
+	// String returns the expression in canonical form with parentheses only
+	// where required.
+	String() string
+}
+
+// License is a leaf expression naming a single license.
+type License struct {
+	// ID is the SPDX license identifier or LicenseRef.
+	ID string
+
+	// OrLater is true when the identifier has the `+` suffix.
+	OrLater bool
+}
+
+// WithException is a license modified by a license exception.
+type WithException struct {
+	License   *License
+	Exception string
+}
+
+// And is a conjunction requiring both operands.
+type And struct {
+	Left, Right Expression
+}
+
+// Or is a disjunction requiring either operand.
+type Or struct {
+	Left, Right Expression
+}
+
+var _ Expression = (*License)(nil)
+var _ Expression = (*WithException)(nil)
+var _ Expression = (*And)(nil)
+var _ Expression = (*Or)(nil)
+
+// Satisfies returns true when `allowed` contains the license.
+func (l *License) Satisfies(allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(a, l.String()) || strings.EqualFold(a, l.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Licenses returns the license identifier.
+func (l *License) Licenses() []string {
+	return []string{l.String()}
+}
+
+// String returns the license identifier.
+func (l *License) String() string {
+	if l.OrLater {
+		return l.ID + "+"
+	}
+	return l.ID
+}
+
+// Satisfies returns true when `allowed` contains the license with the
+// exception or the license alone.
+func (w *WithException) Satisfies(allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(a, w.String()) {
+			return true
+		}
+	}
+	return w.License.Satisfies(allowed)
+}
+
+// Licenses returns the license identifier.
+func (w *WithException) Licenses() []string {
+	return w.License.Licenses()
+}
+
+// String returns the license with exception.
+func (w *WithException) String() string {
+	return w.License.String() + " WITH " + w.Exception
+}
+
+// Satisfies returns true when `allowed` satisfies both operands.
+func (e *And) Satisfies(allowed []string) bool {
+	return e.Left.Satisfies(allowed) && e.Right.Satisfies(allowed)
+}
+
+// Licenses returns the licenses of both operands.
+func (e *And) Licenses() []string {
+	return append(e.Left.Licenses(), e.Right.Licenses()...)
+}
+
+// String returns the conjunction parenthesizing any disjunction operands.
+func (e *And) String() string {
+	return operand(e.Left) + " AND " + operand(e.Right)
+}
+
+// Satisfies returns true when `allowed` satisfies either operand.
+func (e *Or) Satisfies(allowed []string) bool {
+	return e.Left.Satisfies(allowed) || e.Right.Satisfies(allowed)
+}
+
+// Licenses returns the licenses of both operands.
+func (e *Or) Licenses() []string {
+	return append(e.Left.Licenses(), e.Right.Licenses()...)
+}
+
+// String returns the disjunction.
+func (e *Or) String() string {
+	return e.Left.String() + " OR " + e.Right.String()
+}
+
+// operand returns the string for an operand of AND, which binds more
+// tightly than OR.
+func operand(e Expression) string {
+	if _, ok := e.(*Or); ok {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// ParseExpression parses the SPDX license expression `expr`.
+//
+// WITH binds more tightly than AND, which binds more tightly than OR.
+// Operators may be all upper or all lower case.
+func ParseExpression(expr string) (Expression, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	p := &parser{expr, tokens, 0}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in license expression %q", p.tokens[p.pos], expr)
+	}
+	return e, nil
+}
+
+// tokenize splits `expr` into parentheses and words.
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	start := -1
+	for i, r := range expr {
+		switch {
+		case r == '(' || r == ')' || r == ' ' || r == '\t' || r == '\n':
+			if start >= 0 {
+				tokens = append(tokens, expr[start:i])
+				start = -1
+			}
+			if r == '(' || r == ')' {
+				tokens = append(tokens, string(r))
+			}
+		case isIDChar(r):
+			if start < 0 {
+				start = i
+			}
+		default:
+			return nil, fmt.Errorf("invalid character %q in license expression %q", r, expr)
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, expr[start:])
+	}
+	return tokens, nil
+}
+
+// isIDChar returns true for the characters allowed in identifiers including
+// the `+` suffix and the `:` separating DocumentRef from LicenseRef.
+func isIDChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		r == '-' || r == '.' || r == '+' || r == ':'
+}
+
+// parser is a recursive descent parser over the tokens of an expression.
+type parser struct {
+	expr   string
+	tokens []string
+	pos    int
+}
+
+// peekOperator returns true if the next token is operator `op` in either case.
+func (p *parser) peekOperator(op string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos]
+	return t == op || t == strings.ToLower(op)
+}
+
+// parseOr parses: and-expression { OR and-expression }
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: with-expression { AND with-expression }
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseWith()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("AND") {
+		p.pos++
+		right, err := p.parseWith()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{left, right}
+	}
+	return left, nil
+}
+
+// parseWith parses: license [ WITH exception ] | "(" or-expression ")"
+func (p *parser) parseWith() (Expression, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of license expression %q", p.expr)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	if t == "(" {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, fmt.Errorf("missing \")\" in license expression %q", p.expr)
+		}
+		p.pos++
+		return e, nil
+	}
+	l, err := p.license(t)
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekOperator("WITH") {
+		return l, nil
+	}
+	p.pos++
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("missing exception after WITH in license expression %q", p.expr)
+	}
+	exception := p.tokens[p.pos]
+	p.pos++
+	if isReserved(exception) || strings.ContainsAny(exception, "+:") {
+		return nil, fmt.Errorf("invalid exception %q in license expression %q", exception, p.expr)
+	}
+	return &WithException{l, exception}, nil
+}
+
+// license converts token `t` into a license leaf.
+func (p *parser) license(t string) (*License, error) {
+	if t == ")" || isReserved(t) {
+		return nil, fmt.Errorf("unexpected %q in license expression %q", t, p.expr)
+	}
+	id := strings.TrimSuffix(t, "+")
+	if len(id) == 0 || strings.Contains(id, "+") {
+		return nil, fmt.Errorf("invalid license identifier %q in license expression %q", t, p.expr)
+	}
+	return &License{id, id != t}, nil
+}
+
+// isReserved returns true for operator keywords in either case.
+func isReserved(t string) bool {
+	switch t {
+	case "AND", "OR", "WITH", "and", "or", "with":
+		return true
+	}
+	return false
+}
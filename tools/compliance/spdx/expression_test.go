@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spdx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		name             string
+		expr             string
+		expectedString   string
+		expectedLicenses []string
+	}{
+		{
+			name:             "single",
+			expr:             "MIT",
+			expectedString:   "MIT",
+			expectedLicenses: []string{"MIT"},
+		},
+		{
+			name:             "orlater",
+			expr:             "GPL-2.0+",
+			expectedString:   "GPL-2.0+",
+			expectedLicenses: []string{"GPL-2.0+"},
+		},
+		{
+			name:             "and",
+			expr:             "Apache-2.0 AND MIT",
+			expectedString:   "Apache-2.0 AND MIT",
+			expectedLicenses: []string{"Apache-2.0", "MIT"},
+		},
+		{
+			name:             "or",
+			expr:             "GPL-2.0-only OR MIT",
+			expectedString:   "GPL-2.0-only OR MIT",
+			expectedLicenses: []string{"GPL-2.0-only", "MIT"},
+		},
+		{
+			name:             "lowercase",
+			expr:             "apache-2.0 and mit or bsd-3-clause",
+			expectedString:   "apache-2.0 AND mit OR bsd-3-clause",
+			expectedLicenses: []string{"apache-2.0", "mit", "bsd-3-clause"},
+		},
+		{
+			name:             "precedence",
+			expr:             "MIT OR Apache-2.0 AND BSD-3-Clause",
+			expectedString:   "MIT OR Apache-2.0 AND BSD-3-Clause",
+			expectedLicenses: []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+		},
+		{
+			name:             "parentheses",
+			expr:             "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			expectedString:   "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			expectedLicenses: []string{"MIT", "Apache-2.0", "BSD-3-Clause"},
+		},
+		{
+			name:             "nested",
+			expr:             "((MIT AND (ISC OR Zlib)) OR (Apache-2.0 AND (BSD-2-Clause OR BSD-3-Clause)))",
+			expectedString:   "MIT AND (ISC OR Zlib) OR Apache-2.0 AND (BSD-2-Clause OR BSD-3-Clause)",
+			expectedLicenses: []string{"MIT", "ISC", "Zlib", "Apache-2.0", "BSD-2-Clause", "BSD-3-Clause"},
+		},
+		{
+			name:             "with",
+			expr:             "GPL-2.0-only WITH Classpath-exception-2.0",
+			expectedString:   "GPL-2.0-only WITH Classpath-exception-2.0",
+			expectedLicenses: []string{"GPL-2.0-only"},
+		},
+		{
+			name:             "withbindstightest",
+			expr:             "GPL-2.0+ WITH Bison-exception-2.2 OR MIT AND Apache-2.0",
+			expectedString:   "GPL-2.0+ WITH Bison-exception-2.2 OR MIT AND Apache-2.0",
+			expectedLicenses: []string{"GPL-2.0+", "MIT", "Apache-2.0"},
+		},
+		{
+			name:             "licenseref",
+			expr:             "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2 OR LicenseRef-Custom",
+			expectedString:   "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2 OR LicenseRef-Custom",
+			expectedLicenses: []string{"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", "LicenseRef-Custom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q): got error %s, want no error", tt.expr, err)
+			}
+			if actual := e.String(); actual != tt.expectedString {
+				t.Errorf("ParseExpression(%q).String(): got %q, want %q", tt.expr, actual, tt.expectedString)
+			}
+			if actual := e.Licenses(); !reflect.DeepEqual(actual, tt.expectedLicenses) {
+				t.Errorf("ParseExpression(%q).Licenses(): got %q, want %q", tt.expr, actual, tt.expectedLicenses)
+			}
+			again, err := ParseExpression(e.String())
+			if err != nil {
+				t.Fatalf("ParseExpression(%q): got error %s, want no error", e.String(), err)
+			}
+			if !reflect.DeepEqual(again, e) {
+				t.Errorf("ParseExpression(%q): got %#v, want %#v", e.String(), again, e)
+			}
+		})
+	}
+}
+
+func TestParseExpressionStructure(t *testing.T) {
+	e, err := ParseExpression("(GPL-2.0-only WITH Classpath-exception-2.0 OR MIT) AND Apache-2.0")
+	if err != nil {
+		t.Fatalf("ParseExpression(): got error %s, want no error", err)
+	}
+	expected := &And{
+		&Or{
+			&WithException{&License{"GPL-2.0-only", false}, "Classpath-exception-2.0"},
+			&License{"MIT", false},
+		},
+		&License{"Apache-2.0", false},
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("ParseExpression(): got %#v, want %#v", e, expected)
+	}
+}
+
+func TestParseExpressionMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"MIT AND",
+		"OR MIT",
+		"MIT OR OR Apache-2.0",
+		"MIT Apache-2.0",
+		"(MIT OR Apache-2.0",
+		"MIT OR Apache-2.0)",
+		"()",
+		"MIT WITH",
+		"MIT WITH OR",
+		"MIT WITH Exception WITH Another",
+		"(MIT OR Apache-2.0) WITH Classpath-exception-2.0",
+		"GPL-2.0++",
+		"+",
+		"MIT/Apache-2.0",
+		"MIT, Apache-2.0",
+		"MIT And Apache-2.0",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			e, err := ParseExpression(expr)
+			if err == nil {
+				t.Errorf("ParseExpression(%q): got %q, want error", expr, e.String())
+			}
+		})
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		allowed  []string
+		expected bool
+	}{
+		{"single allowed", "MIT", []string{"MIT"}, true},
+		{"single disallowed", "MIT", []string{"Apache-2.0"}, false},
+		{"case insensitive", "mit", []string{"MIT"}, true},
+		{"and both", "Apache-2.0 AND MIT", []string{"MIT", "Apache-2.0"}, true},
+		{"and one", "Apache-2.0 AND MIT", []string{"MIT"}, false},
+		{"or either", "GPL-2.0-only OR MIT", []string{"MIT"}, true},
+		{"or neither", "GPL-2.0-only OR MIT", []string{"Apache-2.0"}, false},
+		{"nested", "(GPL-2.0-only OR MIT) AND (Apache-2.0 OR BSD-3-Clause)", []string{"MIT", "BSD-3-Clause"}, true},
+		{"nested missing", "(GPL-2.0-only OR MIT) AND (Apache-2.0 OR BSD-3-Clause)", []string{"MIT"}, false},
+		{"with license allowed", "GPL-2.0-only WITH Classpath-exception-2.0", []string{"GPL-2.0-only"}, true},
+		{"with exact allowed", "GPL-2.0-only WITH Classpath-exception-2.0", []string{"GPL-2.0-only WITH Classpath-exception-2.0"}, true},
+		{"with other exception", "GPL-2.0-only WITH Classpath-exception-2.0", []string{"GPL-2.0-only WITH Bison-exception-2.2"}, false},
+		{"exception alone", "GPL-2.0-only WITH Classpath-exception-2.0", []string{"Classpath-exception-2.0"}, false},
+		{"orlater base allowed", "GPL-2.0+", []string{"GPL-2.0"}, true},
+		{"orlater exact allowed", "GPL-2.0+", []string{"GPL-2.0+"}, true},
+		{"base does not allow orlater listing", "GPL-2.0", []string{"GPL-2.0+"}, false},
+		{"none allowed", "MIT", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q): got error %s, want no error", tt.expr, err)
+			}
+			if actual := e.Satisfies(tt.allowed); actual != tt.expected {
+				t.Errorf("ParseExpression(%q).Satisfies(%q): got %v, want %v", tt.expr, tt.allowed, actual, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"android/soong/tools/compliance/spdx"
+)
+
+var (
+	// spdxKindsMu guards spdxKinds.
+	spdxKindsMu sync.RWMutex
+
+	// spdxKinds maps the license kinds, which the "SPDX-license-identifier-"
+	// prefix cannot map, to SPDX expressions. An empty expression marks a
+	// kind with no mapping, e.g. one naming a family of licenses without a
+	// version. (guarded by spdxKindsMu)
+	spdxKinds = map[string]string{
+		"SPDX-license-identifier-Apache":                          "",
+		"SPDX-license-identifier-BSD":                             "",
+		"SPDX-license-identifier-GPL":                             "",
+		"SPDX-license-identifier-GPL-with-classpath-exception":    "",
+		"SPDX-license-identifier-LGPL":                            "",
+		"SPDX-license-identifier-MPL":                             "",
+		"SPDX-license-identifier-GPL-2.0-with-autoconf-exception": "GPL-2.0-only WITH Autoconf-exception-2.0",
+		"SPDX-license-identifier-GPL-2.0-with-bison-exception":    "GPL-2.0-only WITH Bison-exception-2.2",
+		"SPDX-license-identifier-GPL-2.0-with-GCC-exception":      "GPL-2.0-only WITH GCC-exception-2.0",
+		"SPDX-license-identifier-GPL-3.0-with-autoconf-exception": "GPL-3.0-only WITH Autoconf-exception-3.0",
+		"SPDX-license-identifier-GPL-3.0-with-bison-exception":    "GPL-3.0-only WITH Bison-exception-2.2",
+		"SPDX-license-identifier-GPL-3.0-with-GCC-exception":      "GPL-3.0-only WITH GCC-exception-3.1",
+		"legacy_by_exception_only":                                "LicenseRef-Android-legacy-by-exception-only",
+		"legacy_not_a_contribution":                               "LicenseRef-Android-legacy-not-a-contribution",
+		"legacy_notice":                                           "LicenseRef-Android-legacy-notice",
+		"legacy_permissive":                                       "LicenseRef-Android-legacy-permissive",
+		"legacy_proprietary":                                      "LicenseRef-Android-legacy-proprietary",
+		"legacy_reciprocal":                                       "LicenseRef-Android-legacy-reciprocal",
+		"legacy_restricted":                                       "LicenseRef-Android-legacy-restricted",
+		"legacy_unencumbered":                                     "LicenseRef-Android-legacy-unencumbered",
+	}
+
+	// spdxExceptions maps the lower-case exception names in license kinds
+	// like "SPDX-license-identifier-GPL-2.0-with-classpath-exception" to
+	// SPDX exception identifiers for the exceptions with a single version.
+	spdxExceptions = map[string]string{
+		"classpath-exception":        "Classpath-exception-2.0",
+		"font-exception":             "Font-exception-2.0",
+		"linux-syscall-note":         "Linux-syscall-note",
+		"llvm-exception":             "LLVM-exception",
+		"openjdk-assembly-exception": "OpenJDK-assembly-exception-1.0",
+	}
+
+	// gnuLicenseRe matches the GNU license identifiers whose bare and `+`
+	// forms are deprecated in favor of "-only" and "-or-later" forms.
+	gnuLicenseRe = regexp.MustCompile(`^(A?GPL|LGPL|GFDL)-[0-9.]+(-only)?$`)
+
+	// spdxKindExceptionRe matches license kinds ending in an exception.
+	spdxKindExceptionRe = regexp.MustCompile(`^(.+)-with-(.+)$`)
+)
+
+// RegisterSpdxKind maps license kind `kind` to SPDX expression `expression`
+// for SpdxExpression, replacing any earlier mapping, or marks `kind` as
+// having no mapping when `expression` is empty.
+func RegisterSpdxKind(kind, expression string) error {
+	if len(expression) > 0 {
+		if _, err := spdx.ParseExpression(expression); err != nil {
+			return fmt.Errorf("invalid SPDX expression for license kind %q: %w", kind, err)
+		}
+	}
+	spdxKindsMu.Lock()
+	defer spdxKindsMu.Unlock()
+	spdxKinds[kind] = expression
+	return nil
+}
+
+// SpdxExpression returns the SPDX expression requiring all of the license
+// kinds `kinds`, and the kinds without a mapping to SPDX, which the
+// expression omits. Returns an empty expression when no kind maps.
+//
+// Kinds registered by RegisterSpdxKind use the registered expression.
+// Otherwise "SPDX-license-identifier-" kinds use the identifier following
+// the prefix, with a "-with-" suffix naming an exception, and with deprecated
+// GNU identifiers replaced by their "-only" or "-or-later" forms.
+//
+// e.g. "Apache-2.0 AND GPL-2.0-or-later" for
+// "SPDX-license-identifier-Apache-2.0" and "SPDX-license-identifier-GPL-2.0+"
+func SpdxExpression(kinds []string) (string, []string) {
+	var expr spdx.Expression
+	var unmapped []string
+	seen := make(map[string]bool)
+	for _, kind := range kinds {
+		if seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		e := spdxKindExpression(kind)
+		if e == nil {
+			unmapped = append(unmapped, kind)
+			continue
+		}
+		if expr == nil {
+			expr = e
+		} else {
+			expr = &spdx.And{Left: expr, Right: e}
+		}
+	}
+	if expr == nil {
+		return "", unmapped
+	}
+	return expr.String(), unmapped
+}
+
+// spdxKindExpression returns the parsed SPDX expression for license kind
+// `kind` or nil when it has no mapping.
+func spdxKindExpression(kind string) spdx.Expression {
+	spdxKindsMu.RLock()
+	s, ok := spdxKinds[kind]
+	spdxKindsMu.RUnlock()
+	if !ok {
+		s = spdxKindToExpression(kind)
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	e, err := spdx.ParseExpression(s)
+	if err != nil {
+		return nil
+	}
+	return e
+}
+
+// spdxKindToExpression returns the SPDX expression for an unregistered
+// "SPDX-license-identifier-" license kind `kind` or "" for other kinds.
+func spdxKindToExpression(kind string) string {
+	if !strings.HasPrefix(kind, spdxLicenseKindPrefix) {
+		return ""
+	}
+	id := strings.TrimPrefix(kind, spdxLicenseKindPrefix)
+	if m := spdxKindExceptionRe.FindStringSubmatch(id); m != nil {
+		exception, ok := spdxExceptions[strings.ToLower(m[2])]
+		if !ok {
+			return ""
+		}
+		license := canonicalSpdxID(m[1])
+		if len(license) == 0 {
+			return ""
+		}
+		return license + " WITH " + exception
+	}
+	return canonicalSpdxID(id)
+}
+
+// canonicalSpdxID returns the SPDX license identifier `id` replacing the
+// deprecated GNU identifiers, e.g. "GPL-2.0-only" for "GPL-2.0" and
+// "GPL-2.0-or-later" for "GPL-2.0+", or "" when `id` is empty.
+func canonicalSpdxID(id string) string {
+	base := strings.TrimSuffix(id, "+")
+	orLater := len(base) < len(id)
+	if len(base) == 0 {
+		return ""
+	}
+	if gnuLicenseRe.MatchString(base) {
+		base = strings.TrimSuffix(base, "-only")
+		if orLater {
+			return base + "-or-later"
+		}
+		return base + "-only"
+	}
+	if orLater {
+		return base + "+"
+	}
+	return base
+}
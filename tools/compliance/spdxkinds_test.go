@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpdxExpression(t *testing.T) {
+	tests := []struct {
+		name             string
+		kinds            []string
+		expectedExpr     string
+		expectedUnmapped []string
+	}{
+		{"empty", nil, "", nil},
+		{"single", []string{"SPDX-license-identifier-MIT"}, "MIT", nil},
+		{"dual", []string{"SPDX-license-identifier-Apache-2.0", "SPDX-license-identifier-BSD-3-Clause"}, "Apache-2.0 AND BSD-3-Clause", nil},
+		{"duplicate", []string{"SPDX-license-identifier-MIT", "SPDX-license-identifier-MIT"}, "MIT", nil},
+		{"gnu", []string{"SPDX-license-identifier-GPL-2.0", "SPDX-license-identifier-LGPL-2.1+"}, "GPL-2.0-only AND LGPL-2.1-or-later", nil},
+		{"gnu canonical", []string{"SPDX-license-identifier-GPL-3.0-only", "SPDX-license-identifier-GPL-3.0-or-later"}, "GPL-3.0-only AND GPL-3.0-or-later", nil},
+		{"or later", []string{"SPDX-license-identifier-MPL-1.1+"}, "MPL-1.1+", nil},
+		{"exception", []string{"SPDX-license-identifier-GPL-2.0-with-classpath-exception"}, "GPL-2.0-only WITH Classpath-exception-2.0", nil},
+		{"exception or later", []string{"SPDX-license-identifier-GPL-2.0+-with-Linux-syscall-note"}, "GPL-2.0-or-later WITH Linux-syscall-note", nil},
+		{"versioned exception", []string{"SPDX-license-identifier-GPL-3.0-with-GCC-exception"}, "GPL-3.0-only WITH GCC-exception-3.1", nil},
+		{"legacy", []string{"legacy_notice", "SPDX-license-identifier-ISC"}, "LicenseRef-Android-legacy-notice AND ISC", nil},
+		{"unknown exception", []string{"SPDX-license-identifier-GPL-2.0-with-special-exception"}, "", []string{"SPDX-license-identifier-GPL-2.0-with-special-exception"}},
+		{"unversioned", []string{"SPDX-license-identifier-GPL", "SPDX-license-identifier-MIT"}, "MIT", []string{"SPDX-license-identifier-GPL"}},
+		{"unknown", []string{"legacy_commercial", "SPDX-license-identifier-", "SPDX-license-identifier-Zlib", "custom"}, "Zlib", []string{"legacy_commercial", "SPDX-license-identifier-", "custom"}},
+		{"invalid", []string{"SPDX-license-identifier-MIT_X11"}, "", []string{"SPDX-license-identifier-MIT_X11"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, unmapped := SpdxExpression(tt.kinds)
+			if expr != tt.expectedExpr {
+				t.Errorf("SpdxExpression(%q): got expression %q, want %q", tt.kinds, expr, tt.expectedExpr)
+			}
+			if !reflect.DeepEqual(unmapped, tt.expectedUnmapped) {
+				t.Errorf("SpdxExpression(%q): got unmapped %q, want %q", tt.kinds, unmapped, tt.expectedUnmapped)
+			}
+		})
+	}
+}
+
+func TestRegisterSpdxKind(t *testing.T) {
+	const dual = "SPDX-license-identifier-MPL-1.1-or-GPL-2.0"
+	t.Cleanup(func() {
+		spdxKindsMu.Lock()
+		delete(spdxKinds, dual)
+		delete(spdxKinds, "SPDX-license-identifier-Zlib")
+		spdxKindsMu.Unlock()
+	})
+
+	if err := RegisterSpdxKind(dual, "MPL-1.1 OR GPL-2.0-or-later"); err != nil {
+		t.Fatalf("RegisterSpdxKind(): got error %s, want no error", err)
+	}
+	expr, unmapped := SpdxExpression([]string{dual, "SPDX-license-identifier-MIT"})
+	if expected := "(MPL-1.1 OR GPL-2.0-or-later) AND MIT"; expr != expected || len(unmapped) > 0 {
+		t.Errorf("SpdxExpression(): got %q unmapped %q, want %q", expr, unmapped, expected)
+	}
+
+	if err := RegisterSpdxKind("SPDX-license-identifier-Zlib", ""); err != nil {
+		t.Fatalf("RegisterSpdxKind(): got error %s, want no error", err)
+	}
+	if expr, unmapped := SpdxExpression([]string{"SPDX-license-identifier-Zlib"}); expr != "" || len(unmapped) != 1 {
+		t.Errorf("SpdxExpression(): got %q unmapped %q for a kind registered without mapping, want unmapped", expr, unmapped)
+	}
+
+	if err := RegisterSpdxKind("bad", "MIT AND"); err == nil {
+		t.Errorf("RegisterSpdxKind(bad): got no error for an invalid expression, want error")
+	}
+}
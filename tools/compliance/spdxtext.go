@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// spdxLicenseListJSON holds the SPDX 3.22 license list with the license text
+// of each entry. Only licenses commonly found in Android are bundled.
+//
+//go:embed spdx_licenses.json
+var spdxLicenseListJSON []byte
+
+// spdxLicenseKindPrefix prefixes SPDX identifiers in license kinds.
+const spdxLicenseKindPrefix = "SPDX-license-identifier-"
+
+var (
+	// spdxTexts maps lower-case SPDX license identifiers to license texts.
+	spdxTexts map[string]string
+	// spdxTextsErr records any error parsing spdxLicenseListJSON.
+	spdxTextsErr error
+	// spdxTextsOnce guards parsing spdxLicenseListJSON.
+	spdxTextsOnce sync.Once
+)
+
+// LicenseCache remembers license texts by SPDX identifier so that each text
+// gets looked up at most once.
+type LicenseCache interface {
+	// Get returns the cached text for `spdxID` and true, or false if absent.
+	Get(spdxID string) (string, bool)
+	// Put caches `text` for `spdxID`.
+	Put(spdxID, text string)
+}
+
+// MapLicenseCache implements LicenseCache as a simple in-memory map.
+//
+// MapLicenseCache is not safe for concurrent use.
+type MapLicenseCache map[string]string
+
+// Get returns the cached text for `spdxID`.
+func (c MapLicenseCache) Get(spdxID string) (string, bool) {
+	text, ok := c[spdxID]
+	return text, ok
+}
+
+// Put caches `text` for `spdxID`.
+func (c MapLicenseCache) Put(spdxID, text string) {
+	c[spdxID] = text
+}
+
+// FetchLicenseText returns the license text for `spdxID` from the embedded
+// SPDX license list consulting and updating `cache` when not nil.
+//
+// Identifiers match without regard to case. Deprecated identifiers like
+// "GPL-2.0" and "or later" forms like "GPL-2.0+" or "GPL-2.0-or-later" use
+// the text of the "-only" license, which is the same license text.
+func FetchLicenseText(spdxID string, cache LicenseCache) (string, error) {
+	if cache != nil {
+		if text, ok := cache.Get(spdxID); ok {
+			return text, nil
+		}
+	}
+	spdxTextsOnce.Do(func() {
+		spdxTexts, spdxTextsErr = parseSpdxLicenseList(spdxLicenseListJSON)
+	})
+	if spdxTextsErr != nil {
+		return "", spdxTextsErr
+	}
+	id := strings.ToLower(strings.TrimPrefix(spdxID, spdxLicenseKindPrefix))
+	id = strings.TrimSuffix(id, "+")
+	id = strings.TrimSuffix(id, "-or-later")
+	text, ok := spdxTexts[id]
+	if !ok {
+		text, ok = spdxTexts[strings.TrimSuffix(id, "-only")+"-only"]
+	}
+	if !ok {
+		return "", fmt.Errorf("no license text for SPDX identifier %q", spdxID)
+	}
+	if cache != nil {
+		cache.Put(spdxID, text)
+	}
+	return text, nil
+}
+
+// parseSpdxLicenseList parses the license list JSON in `data` into a map from
+// lower-case license identifier to license text.
+func parseSpdxLicenseList(data []byte) (map[string]string, error) {
+	var list struct {
+		Licenses []struct {
+			LicenseID   string `json:"licenseId"`
+			LicenseText string `json:"licenseText"`
+		} `json:"licenses"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("error parsing SPDX license list: %w", err)
+	}
+	texts := make(map[string]string)
+	for _, l := range list.Licenses {
+		texts[strings.ToLower(l.LicenseID)] = l.LicenseText
+	}
+	return texts, nil
+}
+
+// spdxLicenseIDs returns the SPDX license identifiers for `tn` from its SPDX
+// license kinds.
+func spdxLicenseIDs(tn *TargetNode) []string {
+	var ids []string
+	for _, kind := range tn.LicenseKinds() {
+		if strings.HasPrefix(kind, spdxLicenseKindPrefix) {
+			ids = append(ids, strings.TrimPrefix(kind, spdxLicenseKindPrefix))
+		}
+	}
+	return ids
+}
+
+// SpdxIdentifierFromKind returns the SPDX license identifier for license kind
+// `kind` or `kind` itself for legacy kinds without an SPDX identifier.
+//
+// e.g. "MIT" for "SPDX-license-identifier-MIT", or "legacy_notice"
+func SpdxIdentifierFromKind(kind string) string {
+	if id := strings.TrimPrefix(kind, spdxLicenseKindPrefix); len(id) > 0 {
+		return id
+	}
+	return kind
+}
+
+// SpdxLicenseID returns the SPDX license identifier for license kind `kind`
+// and true when the bundled SPDX license list recognizes it, or false for
+// legacy kinds and for identifiers missing from the list, which need a
+// LicenseRef in SPDX documents.
+//
+// e.g. "MIT", true for "SPDX-license-identifier-MIT", or "", false for
+// "legacy_notice"
+func SpdxLicenseID(kind string) (string, bool) {
+	if !strings.HasPrefix(kind, spdxLicenseKindPrefix) {
+		return "", false
+	}
+	id := strings.TrimPrefix(kind, spdxLicenseKindPrefix)
+	if len(id) == 0 {
+		return "", false
+	}
+	if _, err := FetchLicenseText(id, nil); err != nil {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"android/soong/tools/compliance/testfs"
+)
+
+// countingCache records the number of lookups and stores.
+type countingCache struct {
+	MapLicenseCache
+	gets, puts int
+}
+
+func (c *countingCache) Get(spdxID string) (string, bool) {
+	c.gets++
+	return c.MapLicenseCache.Get(spdxID)
+}
+
+func (c *countingCache) Put(spdxID, text string) {
+	c.puts++
+	c.MapLicenseCache.Put(spdxID, text)
+}
+
+func TestFetchLicenseText(t *testing.T) {
+	tests := []struct {
+		spdxID   string
+		expected string
+	}{
+		{"MIT", "Permission is hereby granted, free of charge"},
+		{"mit", "Permission is hereby granted, free of charge"},
+		{"Apache-2.0", "Apache License"},
+		{"SPDX-license-identifier-Apache-2.0", "Apache License"},
+		{"BSD-3-Clause", "Neither the name of the copyright holder"},
+		{"GPL-2.0-only", "GNU GENERAL PUBLIC LICENSE"},
+		{"GPL-2.0", "GNU GENERAL PUBLIC LICENSE"},
+		{"GPL-2.0+", "GNU GENERAL PUBLIC LICENSE"},
+		{"GPL-3.0-or-later", "GNU GENERAL PUBLIC LICENSE"},
+		{"LGPL-2.1", "GNU LESSER GENERAL PUBLIC LICENSE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spdxID, func(t *testing.T) {
+			text, err := FetchLicenseText(tt.spdxID, nil)
+			if err != nil {
+				t.Fatalf("FetchLicenseText(%q): got error %s, want no error", tt.spdxID, err)
+			}
+			if !strings.Contains(text, tt.expected) {
+				t.Errorf("FetchLicenseText(%q): got %q, want text containing %q", tt.spdxID, text, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchLicenseTextUnknown(t *testing.T) {
+	for _, spdxID := range []string{"", "LicenseRef-Custom", "Not-A-License-1.0"} {
+		text, err := FetchLicenseText(spdxID, nil)
+		if err == nil {
+			t.Errorf("FetchLicenseText(%q): got %q, want error", spdxID, text)
+		}
+	}
+}
+
+func TestFetchLicenseTextCache(t *testing.T) {
+	cache := &countingCache{MapLicenseCache: MapLicenseCache{}}
+	first, err := FetchLicenseText("MIT", cache)
+	if err != nil {
+		t.Fatalf("FetchLicenseText(): got error %s, want no error", err)
+	}
+	second, err := FetchLicenseText("MIT", cache)
+	if err != nil {
+		t.Fatalf("FetchLicenseText(): got error %s, want no error", err)
+	}
+	if first != second {
+		t.Errorf("FetchLicenseText(): got different texts from cache")
+	}
+	if cache.gets != 2 || cache.puts != 1 {
+		t.Errorf("FetchLicenseText(): got %d gets and %d puts, want 2 gets and 1 put", cache.gets, cache.puts)
+	}
+
+	// cached texts take precedence over the embedded license list.
+	cache.Put("Custom-1.0", "custom text")
+	if text, err := FetchLicenseText("Custom-1.0", cache); err != nil || text != "custom text" {
+		t.Errorf("FetchLicenseText(): got %q, %v, want \"custom text\", no error", text, err)
+	}
+}
+
+func TestIndexLicenseTextsSpdxFallback(t *testing.T) {
+	fs := &testfs.TestFS{
+		"bin.meta_lic": []byte("package_name: \"bin\"\n" +
+			"license_kinds: \"SPDX-license-identifier-Apache-2.0\"\n" +
+			"license_conditions: \"notice\"\n" +
+			"license_texts: \"LICENSE\"\n" +
+			"installed: \"out/bin/bin1\"\n" +
+			"deps: {\n  file: \"liba.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"libb.meta_lic\"\n  annotations: \"static\"\n}\n" +
+			"deps: {\n  file: \"libc.meta_lic\"\n  annotations: \"static\"\n}\n"),
+		"liba.meta_lic": []byte("package_name: \"liba\"\nlicense_kinds: \"SPDX-license-identifier-MIT\"\nlicense_conditions: \"notice\"\n"),
+		"libb.meta_lic": []byte("package_name: \"libb\"\nlicense_kinds: \"SPDX-license-identifier-ISC\"\nlicense_kinds: \"SPDX-license-identifier-Zlib\"\nlicense_conditions: \"notice\"\n"),
+		"libc.meta_lic": []byte("package_name: \"libc\"\nlicense_kinds: \"SPDX-license-identifier-Unknown-1.0\"\nlicense_conditions: \"notice\"\n"),
+		"LICENSE":       []byte("local license text\n"),
+	}
+	stderr := &bytes.Buffer{}
+	lg, err := ReadLicenseGraph(fs, stderr, []string{"bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("unexpected test data error: got %s, want no error", err)
+	}
+
+	libTexts := func(ni *NoticeIndex) map[string][]string {
+		result := make(map[string][]string)
+		for _, h := range ni.AllHashes() {
+			for _, lib := range ni.HashLibs(h) {
+				result[lib] = append(result[lib], string(ni.TextContent(h)))
+			}
+		}
+		return result
+	}
+
+	ni, err := IndexLicenseTexts(fs, lg, nil)
+	if err != nil {
+		t.Fatalf("IndexLicenseTexts(): got error %s, want no error", err)
+	}
+	if actual := libTexts(ni); len(actual) != 1 || len(actual["bin"]) != 1 {
+		t.Errorf("IndexLicenseTexts(): got %q, want only the bin text", actual)
+	}
+
+	cache := MapLicenseCache{}
+	ni, err = IndexLicenseTextsWithOptions(context.Background(), fs, lg, nil, IndexOptions{SpdxFallback: true, Cache: cache})
+	if err != nil {
+		t.Fatalf("IndexLicenseTextsWithOptions(): got error %s, want no error", err)
+	}
+	actual := libTexts(ni)
+	if texts := actual["bin"]; len(texts) != 1 || texts[0] != "local license text\n" {
+		t.Errorf("IndexLicenseTextsWithOptions(): got bin texts %q, want only the local text", texts)
+	}
+	if texts := actual["liba"]; len(texts) != 1 || !strings.Contains(texts[0], "MIT License") {
+		t.Errorf("IndexLicenseTextsWithOptions(): got liba texts %q, want the MIT text", texts)
+	}
+	if texts := actual["libb"]; len(texts) != 2 {
+		t.Errorf("IndexLicenseTextsWithOptions(): got libb texts %q, want the ISC and Zlib texts", texts)
+	}
+	if texts, ok := actual["libc"]; ok {
+		t.Errorf("IndexLicenseTextsWithOptions(): got libc texts %q, want none", texts)
+	}
+	for _, spdxID := range []string{"MIT", "ISC", "Zlib"} {
+		if _, ok := cache[spdxID]; !ok {
+			t.Errorf("IndexLicenseTextsWithOptions(): got no cached text for %q, want cached", spdxID)
+		}
+	}
+	for _, f := range ni.InputFiles() {
+		if f != "LICENSE" && !strings.HasSuffix(f, ".meta_lic") {
+			t.Errorf("IndexLicenseTextsWithOptions(): got input file %q, want only \"LICENSE\" and metadata", f)
+		}
+	}
+}
+
+func TestSpdxIdentifierFromKind(t *testing.T) {
+	tests := []struct {
+		kind     string
+		expected string
+	}{
+		{"SPDX-license-identifier-MIT", "MIT"},
+		{"SPDX-license-identifier-Apache-2.0", "Apache-2.0"},
+		{"SPDX-license-identifier-GPL-2.0-with-classpath-exception", "GPL-2.0-with-classpath-exception"},
+		{"legacy_notice", "legacy_notice"},
+		{"legacy_by_exception_only", "legacy_by_exception_only"},
+		{"SPDX-license-identifier-", "SPDX-license-identifier-"},
+	}
+	for _, tt := range tests {
+		if actual := SpdxIdentifierFromKind(tt.kind); actual != tt.expected {
+			t.Errorf("SpdxIdentifierFromKind(%q): got %q, want %q", tt.kind, actual, tt.expected)
+		}
+	}
+}
+
+func TestSpdxLicenseID(t *testing.T) {
+	tests := []struct {
+		kind       string
+		expectedID string
+		expectedOK bool
+	}{
+		{"SPDX-license-identifier-MIT", "MIT", true},
+		{"SPDX-license-identifier-Apache-2.0", "Apache-2.0", true},
+		{"SPDX-license-identifier-GPL-2.0", "GPL-2.0", true},
+		{"SPDX-license-identifier-BSD", "", false},
+		{"SPDX-license-identifier-", "", false},
+		{"legacy_notice", "", false},
+		{"MIT", "", false},
+	}
+	for _, tt := range tests {
+		id, ok := SpdxLicenseID(tt.kind)
+		if id != tt.expectedID || ok != tt.expectedOK {
+			t.Errorf("SpdxLicenseID(%q): got %q, %v, want %q, %v", tt.kind, id, ok, tt.expectedID, tt.expectedOK)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"strings"
+)
+
+// StripPrefix removes the first of `prefixes` matching `installPath` and
+// returns the remainder. Prefixes match whole path components only so
+// "out/target/product/foo" strips "out/target/product/foo/system/bin" but
+// not "out/target/product/foobar/system/bin".
+//
+// When a prefix matches all of `installPath`, StripPrefix returns `product`
+// in its place or, when `product` is empty, tries the next prefix.
+func StripPrefix(installPath string, prefixes []string, product string) string {
+	for _, prefix := range prefixes {
+		if !hasPathPrefix(installPath, prefix) {
+			continue
+		}
+		p := strings.TrimPrefix(installPath, prefix)
+		if 0 == len(p) {
+			p = product
+		}
+		if 0 == len(p) {
+			continue
+		}
+		return p
+	}
+	return installPath
+}
+
+// hasPathPrefix returns true when `prefix` is a prefix of `path` ending on a
+// path component boundary.
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	if len(path) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	return path[len(prefix)] == '/'
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"testing"
+)
+
+func TestStripPrefix(t *testing.T) {
+	tests := []struct {
+		name        string
+		installPath string
+		prefixes    []string
+		product     string
+		expected    string
+	}{
+		{
+			name:        "noprefixes",
+			installPath: "out/target/product/foo/system/bin/bin1",
+			expected:    "out/target/product/foo/system/bin/bin1",
+		},
+		{
+			name:        "trailingslash",
+			installPath: "out/target/product/foo/system/bin/bin1",
+			prefixes:    []string{"out/target/product/foo/"},
+			expected:    "system/bin/bin1",
+		},
+		{
+			name:        "component",
+			installPath: "out/target/product/foo/system/bin/bin1",
+			prefixes:    []string{"out/target/product/foo"},
+			expected:    "/system/bin/bin1",
+		},
+		{
+			name:        "foobar",
+			installPath: "out/target/product/foobar/system/bin/bin1",
+			prefixes:    []string{"out/target/product/foo"},
+			expected:    "out/target/product/foobar/system/bin/bin1",
+		},
+		{
+			name:        "foobarnext",
+			installPath: "out/target/product/foobar/system/bin/bin1",
+			prefixes:    []string{"out/target/product/foo", "out/target/product/foobar/"},
+			expected:    "system/bin/bin1",
+		},
+		{
+			name:        "first",
+			installPath: "out/target/product/foo/system/bin/bin1",
+			prefixes:    []string{"out/target/product/foo/", "out/target/"},
+			expected:    "system/bin/bin1",
+		},
+		{
+			name:        "whole",
+			installPath: "out/target/product/foo",
+			prefixes:    []string{"out/target/product/foo"},
+			product:     "foo",
+			expected:    "foo",
+		},
+		{
+			name:        "wholenoproduct",
+			installPath: "out/target/product/foo",
+			prefixes:    []string{"out/target/product/foo", "out/target/"},
+			expected:    "product/foo",
+		},
+		{
+			name:        "wholeunmatched",
+			installPath: "out/target/product/foo",
+			prefixes:    []string{"out/target/product/foo"},
+			expected:    "out/target/product/foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := StripPrefix(tt.installPath, tt.prefixes, tt.product)
+			if actual != tt.expected {
+				t.Errorf("unexpected path: got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
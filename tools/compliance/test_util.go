@@ -86,6 +86,14 @@ license_conditions: "proprietary"
 		`package_name: "Special"
 license_kinds: "legacy_by_exception_only"
 license_conditions: "by_exception_only"
+`
+
+	// Commercial starts a test metadata file for a module with a custom
+	// "commercial" condition, which tests must register before use.
+	Commercial = `` +
+		`package_name: "Vendor"
+license_kinds: "legacy_commercial"
+license_conditions: "commercial"
 `
 )
 
@@ -108,6 +116,8 @@ var (
 		"mplLib.meta_lic":                    MPL,
 		"proprietary.meta_lic":               Proprietary,
 		"by_exception.meta_lic":              ByException,
+		"commercialBin.meta_lic":             Commercial,
+		"commercialLib.meta_lic":             Commercial,
 	}
 )
 
@@ -389,7 +399,7 @@ func toActionList(lg *LicenseGraph, actions []tcond) actionList {
 		target := newTestNode(lg, actn.target)
 		cs := NewLicenseConditionSet()
 		for _, name := range strings.Split(actn.conditions, "|") {
-			lc, ok := RecognizedConditionNames[name]
+			lc, ok := Conditions.Condition(name)
 			if !ok {
 				panic(fmt.Errorf("Unrecognized test condition name: %q", name))
 			}
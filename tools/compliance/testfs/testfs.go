@@ -31,7 +31,7 @@ var _ fs.StatFS = (*TestFS)(nil)
 // Open implements fs.FS.Open() to open a file based on the filename.
 func (tfs *TestFS) Open(name string) (fs.File, error) {
 	if _, ok := (*tfs)[name]; !ok {
-		return nil, fmt.Errorf("unknown file %q", name)
+		return nil, fmt.Errorf("unknown file %q: %w", name, fs.ErrNotExist)
 	}
 	return &TestFile{tfs, name, 0}, nil
 }
@@ -50,7 +50,7 @@ func (tfs *TestFS) Stat(name string) (fs.FileInfo, error) {
 			return &TestFileInfo{name, 8, fs.ModeDir | fs.ModePerm}, nil
 		}
 	}
-	return nil, fmt.Errorf("file not found: %q", name)
+	return nil, fmt.Errorf("file not found: %q: %w", name, fs.ErrNotExist)
 }
 
 // TestFileInfo implements a file info (fs.FileInfo) based on TestFS above.
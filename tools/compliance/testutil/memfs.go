@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testutil provides hermetic file systems for tests.
+package testutil
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// memFS implements an in-memory, read-only fs.FS.
+//
+// Unlike fstest.MapFS, memFS indexes the directories up front so looking up a
+// missing file takes constant time.
+type memFS struct {
+	// files maps the path of each regular file to its content.
+	files map[string]string
+	// dirs maps the path of each directory to its sorted entry names.
+	dirs map[string][]string
+}
+
+var _ fs.StatFS = (*memFS)(nil)
+var _ fs.ReadFileFS = (*memFS)(nil)
+var _ fs.ReadDirFS = (*memFS)(nil)
+
+// NewMemFS returns an in-memory fs.FS holding `files`, which maps file paths
+// to contents.
+//
+// The paths use forward slashes and may start with "./". Directories exist
+// implicitly for every file. Opening any other path fails with an error
+// wrapping fs.ErrNotExist.
+func NewMemFS(files map[string]string) fs.FS {
+	mfs := &memFS{make(map[string]string), map[string][]string{".": nil}}
+	entries := make(map[string]map[string]struct{})
+	for name, content := range files {
+		name = path.Clean(strings.TrimPrefix(name, "./"))
+		mfs.files[name] = content
+		for dir := path.Dir(name); ; dir = path.Dir(dir) {
+			if _, ok := entries[dir]; !ok {
+				entries[dir] = make(map[string]struct{})
+			}
+			entries[dir][path.Base(name)] = struct{}{}
+			if dir == "." {
+				break
+			}
+			name = dir
+		}
+	}
+	for dir, names := range entries {
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+		mfs.dirs[dir] = sorted
+	}
+	return mfs
+}
+
+// Open implements fs.FS.
+func (mfs *memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if content, ok := mfs.files[name]; ok {
+		return &memFile{memFileInfo{path.Base(name), int64(len(content)), 0444}, strings.NewReader(content)}, nil
+	}
+	if entries, ok := mfs.dirs[name]; ok {
+		return &memDir{memFileInfo{path.Base(name), 0, fs.ModeDir | 0555}, mfs, name, entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat implements fs.StatFS.
+func (mfs *memFS) Stat(name string) (fs.FileInfo, error) {
+	f, err := mfs.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err.(*fs.PathError).Err}
+	}
+	return f.Stat()
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (mfs *memFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	content, ok := mfs.files[name]
+	if !ok {
+		if _, ok := mfs.dirs[name]; ok {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return []byte(content), nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (mfs *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f, err := mfs.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err.(*fs.PathError).Err}
+	}
+	d, ok := f.(*memDir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return d.ReadDir(-1)
+}
+
+// info returns the file info for `name` in `dir`.
+func (mfs *memFS) info(dir, name string) *memFileInfo {
+	p := path.Join(dir, name)
+	if content, ok := mfs.files[p]; ok {
+		return &memFileInfo{name, int64(len(content)), 0444}
+	}
+	return &memFileInfo{name, 0, fs.ModeDir | 0555}
+}
+
+// memFileInfo implements fs.FileInfo and fs.DirEntry for memFS.
+type memFileInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (fi *memFileInfo) Name() string               { return fi.name }
+func (fi *memFileInfo) Size() int64                { return fi.size }
+func (fi *memFileInfo) Mode() fs.FileMode          { return fi.mode }
+func (fi *memFileInfo) ModTime() time.Time         { return time.Time{} }
+func (fi *memFileInfo) IsDir() bool                { return fi.mode.IsDir() }
+func (fi *memFileInfo) Sys() interface{}           { return nil }
+func (fi *memFileInfo) Type() fs.FileMode          { return fi.mode.Type() }
+func (fi *memFileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// memFile implements an open regular file in memFS.
+type memFile struct {
+	info memFileInfo
+	*strings.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return &f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir implements an open directory in memFS.
+type memDir struct {
+	info    memFileInfo
+	mfs     *memFS
+	path    string
+	entries []string
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return &d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile returning the next `n` entries or all
+// remaining entries when `n` <= 0.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n > 0 && len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n <= 0 || n > len(d.entries) {
+		n = len(d.entries)
+	}
+	result := make([]fs.DirEntry, 0, n)
+	for _, name := range d.entries[:n] {
+		result = append(result, d.mfs.info(d.path, name))
+	}
+	d.entries = d.entries[n:]
+	return result, nil
+}
+
+// errorFS wraps an fs.FS failing to open selected files.
+type errorFS struct {
+	fsys fs.FS
+	errs map[string]error
+}
+
+// WithErrors returns an fs.FS reading from `fsys` except that opening a path
+// in `errs` fails with the mapped error, e.g. fs.ErrPermission or
+// fs.ErrNotExist.
+func WithErrors(fsys fs.FS, errs map[string]error) fs.FS {
+	return &errorFS{fsys, errs}
+}
+
+// Open implements fs.FS.
+func (efs *errorFS) Open(name string) (fs.File, error) {
+	if err, ok := efs.errs[name]; ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return efs.fsys.Open(name)
+}
+
+// ReadFiles returns the content of every regular file under `dir` on disk
+// keyed by slash-separated path including `dir` for use with NewMemFS.
+func ReadFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(p)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
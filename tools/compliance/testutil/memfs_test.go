@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemFS(t *testing.T) {
+	fsys := NewMemFS(map[string]string{
+		"a.meta_lic":           "package_name: \"a\"\n",
+		"./lib/b.meta_lic":     "package_name: \"b\"\n",
+		"lib/sub/LICENSE":      "license text\n",
+		"licenses/NOTICE_FILE": "",
+	})
+	if err := fstest.TestFS(fsys, "a.meta_lic", "lib/b.meta_lic", "lib/sub/LICENSE", "licenses/NOTICE_FILE"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := fs.ReadFile(fsys, "lib/sub/LICENSE")
+	if err != nil || string(content) != "license text\n" {
+		t.Errorf("ReadFile: got %q, %v, want %q", content, err, "license text\n")
+	}
+
+	entries, err := fs.ReadDir(fsys, "lib")
+	if err != nil {
+		t.Fatalf("ReadDir: error = %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if expected := []string{"b.meta_lic", "sub"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("ReadDir: got %q, want %q", names, expected)
+	}
+
+	for _, name := range []string{"missing", "lib/missing.meta_lic", "lib/sub/LICENSE/x"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Open(%q): got error %v, want fs.ErrNotExist", name, err)
+		}
+		if _, err := fs.Stat(fsys, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q): got error %v, want fs.ErrNotExist", name, err)
+		}
+	}
+	if _, err := fsys.Open("/a.meta_lic"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Open(%q): got error %v, want fs.ErrInvalid", "/a.meta_lic", err)
+	}
+}
+
+func TestWithErrors(t *testing.T) {
+	fsys := WithErrors(NewMemFS(map[string]string{
+		"a.meta_lic": "package_name: \"a\"\n",
+		"LICENSE":    "license text\n",
+	}), map[string]error{"LICENSE": fs.ErrPermission})
+
+	if _, err := fs.ReadFile(fsys, "a.meta_lic"); err != nil {
+		t.Errorf("ReadFile(%q): got error %v, want none", "a.meta_lic", err)
+	}
+	_, err := fs.ReadFile(fsys, "LICENSE")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("ReadFile(%q): got error %v, want fs.ErrPermission", "LICENSE", err)
+	}
+	var pe *fs.PathError
+	if !errors.As(err, &pe) || pe.Path != "LICENSE" {
+		t.Errorf("ReadFile(%q): got error %v, want *fs.PathError for the file", "LICENSE", err)
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "testdata", "lib"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"testdata/a.meta_lic":     "a",
+		"testdata/lib/b.meta_lic": "b",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	files, err := ReadFiles("testdata")
+	if err != nil {
+		t.Fatalf("ReadFiles: error = %v", err)
+	}
+	expected := map[string]string{
+		"testdata/a.meta_lic":     "a",
+		"testdata/lib/b.meta_lic": "b",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("ReadFiles: got %q, want %q", files, expected)
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is a generic element of an xml document.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+// attr returns the value of the attribute `name` of `n` or `def` when absent.
+func (n *xmlNode) attr(name, def string) string {
+	if a := n.findAttr(name); a != nil {
+		return a.Value
+	}
+	return def
+}
+
+// findAttr returns the attribute `name` of `n` or nil when absent.
+func (n *xmlNode) findAttr(name string) *xml.Attr {
+	for i, a := range n.Attrs {
+		if a.Name.Local == name && len(a.Name.Space) == 0 {
+			return &n.Attrs[i]
+		}
+	}
+	return nil
+}
+
+// child returns the first child of `n` named `name` or nil.
+func (n *xmlNode) child(names ...string) *xmlNode {
+	for i := range n.Nodes {
+		for _, name := range names {
+			if n.Nodes[i].XMLName.Local == name {
+				return &n.Nodes[i]
+			}
+		}
+	}
+	return nil
+}
+
+// XMLSchema validates xml documents against an XML Schema (XSD).
+//
+// Only the subset of XML Schema needed to describe notice files is
+// supported: global and local element declarations with `type` or `ref`,
+// named and anonymous complex types, `sequence` and `choice` with
+// `minOccurs` and `maxOccurs`, `simpleContent` extensions, attributes with
+// `use="required"`, and the built-in string, integer, and boolean types.
+type XMLSchema struct {
+	// elements maps the names of global element declarations to the
+	// declarations.
+	elements map[string]*xmlNode
+	// types maps the names of named complex types to the definitions.
+	types map[string]*xmlNode
+}
+
+// ReadXMLSchema reads the XML Schema (XSD) from `r`.
+func ReadXMLSchema(r io.Reader) (*XMLSchema, error) {
+	var root xmlNode
+	err := xml.NewDecoder(r).Decode(&root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse xml schema: %w", err)
+	}
+	if root.XMLName.Local != "schema" {
+		return nil, fmt.Errorf("xml schema root must be <schema>: got <%s>", root.XMLName.Local)
+	}
+	s := &XMLSchema{make(map[string]*xmlNode), make(map[string]*xmlNode)}
+	for i := range root.Nodes {
+		n := &root.Nodes[i]
+		name := n.attr("name", "")
+		switch n.XMLName.Local {
+		case "element":
+			s.elements[name] = n
+		case "complexType":
+			s.types[name] = n
+		}
+	}
+	if len(s.elements) == 0 {
+		return nil, fmt.Errorf("xml schema declares no elements")
+	}
+	return s, nil
+}
+
+// Validate returns an error describing the first way the xml document in `r`
+// does not conform to the schema or nil when it conforms.
+func (s *XMLSchema) Validate(r io.Reader) error {
+	var root xmlNode
+	err := xml.NewDecoder(r).Decode(&root)
+	if err != nil {
+		return fmt.Errorf("cannot parse xml: %w", err)
+	}
+	decl, ok := s.elements[root.XMLName.Local]
+	if !ok {
+		return fmt.Errorf("undeclared root element <%s>", root.XMLName.Local)
+	}
+	return s.validateElement(decl, &root, "/"+root.XMLName.Local)
+}
+
+// validateElement validates `n` at `path` against element declaration `decl`.
+func (s *XMLSchema) validateElement(decl, n *xmlNode, path string) error {
+	if typeName := decl.attr("type", ""); len(typeName) > 0 {
+		if ct, ok := s.types[localName(typeName)]; ok {
+			return s.validateComplex(ct, n, path)
+		}
+		if len(n.Nodes) > 0 {
+			return fmt.Errorf("%s: unexpected child element <%s> in simple content", path, n.Nodes[0].XMLName.Local)
+		}
+		if err := checkAttrs(nil, n, path); err != nil {
+			return err
+		}
+		return checkSimple(typeName, n.Text, path)
+	}
+	if ct := decl.child("complexType"); ct != nil {
+		return s.validateComplex(ct, n, path)
+	}
+	// No type means any content.
+	return nil
+}
+
+// validateComplex validates `n` at `path` against complex type `ct`.
+func (s *XMLSchema) validateComplex(ct, n *xmlNode, path string) error {
+	if sc := ct.child("simpleContent"); sc != nil {
+		ext := sc.child("extension")
+		if ext == nil {
+			return fmt.Errorf("%s: unsupported simpleContent without extension", path)
+		}
+		if len(n.Nodes) > 0 {
+			return fmt.Errorf("%s: unexpected child element <%s> in simple content", path, n.Nodes[0].XMLName.Local)
+		}
+		if err := checkAttrs(ext, n, path); err != nil {
+			return err
+		}
+		return checkSimple(ext.attr("base", "string"), n.Text, path)
+	}
+	if err := checkAttrs(ct, n, path); err != nil {
+		return err
+	}
+	if ct.attr("mixed", "false") != "true" && len(strings.TrimSpace(n.Text)) > 0 {
+		return fmt.Errorf("%s: unexpected text %q", path, strings.TrimSpace(n.Text))
+	}
+	i := 0
+	if p := ct.child("sequence", "choice"); p != nil {
+		var err error
+		i, err = s.matchParticle(p, n.Nodes, 0, path)
+		if err != nil {
+			return err
+		}
+	}
+	if i < len(n.Nodes) {
+		return fmt.Errorf("%s: unexpected element <%s>", path, n.Nodes[i].XMLName.Local)
+	}
+	return nil
+}
+
+// matchParticle matches `children` starting at index `i` against particle
+// `p` returning the index of the first unmatched child.
+func (s *XMLSchema) matchParticle(p *xmlNode, children []xmlNode, i int, path string) (int, error) {
+	minOccurs, err := strconv.Atoi(p.attr("minOccurs", "1"))
+	if err != nil {
+		return i, fmt.Errorf("%s: invalid minOccurs %q in schema", path, p.attr("minOccurs", ""))
+	}
+	maxOccurs := -1
+	if m := p.attr("maxOccurs", "1"); m != "unbounded" {
+		maxOccurs, err = strconv.Atoi(m)
+		if err != nil {
+			return i, fmt.Errorf("%s: invalid maxOccurs %q in schema", path, m)
+		}
+	}
+
+	occurs := 0
+	for maxOccurs < 0 || occurs < maxOccurs {
+		start := i
+		switch p.XMLName.Local {
+		case "element":
+			decl := p
+			if ref := p.attr("ref", ""); len(ref) > 0 {
+				var ok bool
+				decl, ok = s.elements[localName(ref)]
+				if !ok {
+					return i, fmt.Errorf("%s: undeclared element reference %q in schema", path, ref)
+				}
+			}
+			if i >= len(children) || children[i].XMLName.Local != decl.attr("name", "") {
+				break
+			}
+			err := s.validateElement(decl, &children[i], fmt.Sprintf("%s/%s[%d]", path, children[i].XMLName.Local, i+1))
+			if err != nil {
+				return i, invalidContentError{err}
+			}
+			i++
+		case "sequence":
+			next := i
+			for j := range p.Nodes {
+				next, err = s.matchParticle(&p.Nodes[j], children, next, path)
+				if err != nil {
+					break
+				}
+			}
+			if err != nil {
+				if occurs < minOccurs || errors.As(err, &invalidContentError{}) {
+					return next, err
+				}
+				// Stop repeating once the sequence no longer matches.
+				err = nil
+				break
+			}
+			i = next
+		case "choice":
+			for j := range p.Nodes {
+				next, err := s.matchParticle(&p.Nodes[j], children, i, path)
+				if errors.As(err, &invalidContentError{}) {
+					return next, err
+				}
+				if err == nil && next > i {
+					i = next
+					break
+				}
+			}
+		}
+		if i == start {
+			break
+		}
+		occurs++
+	}
+	if occurs >= minOccurs {
+		return i, nil
+	}
+	got := "end of element"
+	if i < len(children) {
+		got = "<" + children[i].XMLName.Local + ">"
+	}
+	switch p.XMLName.Local {
+	case "element":
+		return i, fmt.Errorf("%s: got %s, want <%s>", path, got, p.attr("name", p.attr("ref", "")))
+	case "choice":
+		var alternatives []string
+		for j := range p.Nodes {
+			alternatives = append(alternatives, "<"+p.Nodes[j].attr("name", p.Nodes[j].attr("ref", p.Nodes[j].XMLName.Local))+">")
+		}
+		return i, fmt.Errorf("%s: got %s, want one of %s", path, got, strings.Join(alternatives, ", "))
+	}
+	return i, nil
+}
+
+// invalidContentError wraps the error for a child element matching its
+// declaration by name but not by content so that no other particle can match
+// the child instead.
+type invalidContentError struct {
+	err error
+}
+
+func (e invalidContentError) Error() string { return e.err.Error() }
+func (e invalidContentError) Unwrap() error { return e.err }
+
+// checkAttrs returns an error when `n` at `path` lacks an attribute required
+// by the attribute declarations of `decl` or has an undeclared attribute.
+func checkAttrs(decl, n *xmlNode, path string) error {
+	declared := make(map[string]*xmlNode)
+	if decl != nil {
+		for i := range decl.Nodes {
+			a := &decl.Nodes[i]
+			if a.XMLName.Local != "attribute" {
+				continue
+			}
+			name := a.attr("name", "")
+			declared[name] = a
+			if a.attr("use", "optional") == "required" && n.findAttr(name) == nil {
+				return fmt.Errorf("%s: missing required attribute %q", path, name)
+			}
+		}
+	}
+	for _, a := range n.Attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" || len(a.Name.Space) > 0 {
+			continue
+		}
+		ad, ok := declared[a.Name.Local]
+		if !ok {
+			return fmt.Errorf("%s: undeclared attribute %q", path, a.Name.Local)
+		}
+		if err := checkSimple(ad.attr("type", "string"), a.Value, path+"/@"+a.Name.Local); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSimple returns an error when `value` at `path` is not a valid value
+// of the built-in simple type `typeName`.
+func checkSimple(typeName, value, path string) error {
+	switch localName(typeName) {
+	case "string", "normalizedString", "token", "anyURI", "ID", "NMTOKEN":
+		return nil
+	case "int", "integer", "long", "short":
+		if _, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err != nil {
+			return fmt.Errorf("%s: got %q, want %s", path, value, localName(typeName))
+		}
+		return nil
+	case "nonNegativeInteger", "unsignedInt", "unsignedLong":
+		if _, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err != nil {
+			return fmt.Errorf("%s: got %q, want %s", path, value, localName(typeName))
+		}
+		return nil
+	case "boolean":
+		switch strings.TrimSpace(value) {
+		case "true", "false", "1", "0":
+			return nil
+		}
+		return fmt.Errorf("%s: got %q, want boolean", path, value)
+	}
+	return fmt.Errorf("%s: unsupported type %q in schema", path, typeName)
+}
+
+// localName returns `name` without any namespace prefix.
+func localName(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `<?xml version="1.0" encoding="utf-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="licenses" type="licensesType"/>
+  <xs:element name="note" type="xs:string"/>
+  <xs:complexType name="licensesType">
+    <xs:sequence>
+      <xs:element name="count" type="xs:nonNegativeInteger"/>
+      <xs:choice minOccurs="0" maxOccurs="unbounded">
+        <xs:element name="file-name">
+          <xs:complexType>
+            <xs:simpleContent>
+              <xs:extension base="xs:string">
+                <xs:attribute name="contentId" type="xs:string" use="required"/>
+                <xs:attribute name="shipped" type="xs:boolean"/>
+              </xs:extension>
+            </xs:simpleContent>
+          </xs:complexType>
+        </xs:element>
+        <xs:element ref="note"/>
+      </xs:choice>
+      <xs:sequence minOccurs="0" maxOccurs="unbounded">
+        <xs:element name="key" type="xs:string"/>
+        <xs:element name="value" type="xs:int"/>
+      </xs:sequence>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>
+`
+
+func TestXMLSchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		doc           string
+		expectedError string
+	}{
+		{
+			name: "minimal",
+			doc:  `<licenses><count>0</count></licenses>`,
+		},
+		{
+			name: "full",
+			doc: `<?xml version="1.0" encoding="utf-8"?>
+<licenses>
+  <count>3</count>
+  <file-name contentId="a" shipped="true">bin/bin1</file-name>
+  <note>a &amp; b</note>
+  <file-name contentId="b">bin/bin2</file-name>
+  <key>k1</key><value>1</value>
+  <key>k2</key><value>-2</value>
+</licenses>`,
+		},
+		{
+			name:          "undeclaredroot",
+			doc:           `<notices/>`,
+			expectedError: "undeclared root element <notices>",
+		},
+		{
+			name:          "missingchild",
+			doc:           `<licenses><file-name contentId="a">bin/bin1</file-name></licenses>`,
+			expectedError: "got <file-name>, want <count>",
+		},
+		{
+			name:          "missingattribute",
+			doc:           `<licenses><count>1</count><file-name>bin/bin1</file-name></licenses>`,
+			expectedError: `/licenses/file-name[2]: missing required attribute "contentId"`,
+		},
+		{
+			name:          "undeclaredattribute",
+			doc:           `<licenses><count>1</count><file-name contentId="a" lib="x">bin/bin1</file-name></licenses>`,
+			expectedError: `undeclared attribute "lib"`,
+		},
+		{
+			name:          "badattribute",
+			doc:           `<licenses><count>1</count><file-name contentId="a" shipped="yes">bin/bin1</file-name></licenses>`,
+			expectedError: `got "yes", want boolean`,
+		},
+		{
+			name:          "badtype",
+			doc:           `<licenses><count>-1</count></licenses>`,
+			expectedError: `got "-1", want nonNegativeInteger`,
+		},
+		{
+			name:          "unexpectedelement",
+			doc:           `<licenses><count>1</count><key>k</key><value>1</value><note>late</note></licenses>`,
+			expectedError: "/licenses: unexpected element <note>",
+		},
+		{
+			name:          "incompletesequence",
+			doc:           `<licenses><count>1</count><key>k</key></licenses>`,
+			expectedError: "/licenses: unexpected element <key>",
+		},
+		{
+			name:          "unexpectedtext",
+			doc:           `<licenses>stray<count>1</count></licenses>`,
+			expectedError: `/licenses: unexpected text "stray"`,
+		},
+		{
+			name:          "childinsimple",
+			doc:           `<licenses><count>1</count><note><b>bold</b></note></licenses>`,
+			expectedError: "unexpected child element <b> in simple content",
+		},
+		{
+			name:          "malformed",
+			doc:           `<licenses><count>1</count>`,
+			expectedError: "cannot parse xml",
+		},
+	}
+	schema, err := ReadXMLSchema(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected schema error: got %s, want no error", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(strings.NewReader(tt.doc))
+			if len(tt.expectedError) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: got %s, want no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Errorf("unexpected success: got no error, want %q", tt.expectedError)
+			} else if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("unexpected error: got %q, want %q", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestReadXMLSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		schema        string
+		expectedError string
+	}{
+		{
+			name:          "notschema",
+			schema:        `<licenses/>`,
+			expectedError: "root must be <schema>",
+		},
+		{
+			name:          "noelements",
+			schema:        `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`,
+			expectedError: "declares no elements",
+		},
+		{
+			name:          "malformed",
+			schema:        `<xs:schema`,
+			expectedError: "cannot parse xml schema",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadXMLSchema(strings.NewReader(tt.schema))
+			if err == nil {
+				t.Errorf("unexpected success: got no error, want %q", tt.expectedError)
+			} else if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("unexpected error: got %q, want %q", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// zipDirSeparator separates the path to a zip archive from the directory
+// inside the archive in the names OpenZipFS accepts.
+const zipDirSeparator = "!/"
+
+// ZipFS is a read-only file system over a zip archive, e.g. an archived out/
+// tree of license metadata files, license texts and METADATA files, so that
+// the tools can read the files without unpacking the archive.
+type ZipFS struct {
+	// archive is the path to the zip archive.
+	archive string
+	// zr reads the archive.
+	zr *zip.ReadCloser
+	// fsys is the archive or the directory inside the archive.
+	fsys fs.FS
+}
+
+var _ fs.FS = (*ZipFS)(nil)
+var _ fs.StatFS = (*ZipFS)(nil)
+
+// OpenZipFS opens the zip archive `name` as a file system.
+//
+// `name` may name a directory inside the archive after "!/", e.g.
+// "release.zip!/out/soong", to root the file system there.
+func OpenZipFS(name string) (*ZipFS, error) {
+	archive, dir, _ := strings.Cut(name, zipDirSeparator)
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	zfs := &ZipFS{archive: archive, zr: zr, fsys: zr}
+	if dir = path.Clean(strings.Trim(dir, "/")); dir != "." {
+		fi, err := fs.Stat(zr, dir)
+		if err == nil && !fi.IsDir() {
+			err = fmt.Errorf("not a directory")
+		}
+		if err != nil {
+			zr.Close()
+			return nil, fmt.Errorf("invalid directory %q in zip archive %q: %w", dir, archive, err)
+		}
+		zfs.fsys, err = fs.Sub(zr, dir)
+		if err != nil {
+			zr.Close()
+			return nil, err
+		}
+	}
+	return zfs, nil
+}
+
+// Archive returns the path to the zip archive, e.g. to list in deps files in
+// place of the files read from it.
+func (z *ZipFS) Archive() string {
+	return z.archive
+}
+
+// Open opens the file `name` in the archive accepting names like
+// "./out/x.meta_lic" that fs.ValidPath rejects.
+func (z *ZipFS) Open(name string) (fs.File, error) {
+	return z.fsys.Open(zipName(name))
+}
+
+// Stat returns the fs.FileInfo for the file `name` in the archive.
+func (z *ZipFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(z.fsys, zipName(name))
+}
+
+// Close closes the archive.
+func (z *ZipFS) Close() error {
+	return z.zr.Close()
+}
+
+// zipName returns the name inside the archive for `name` dropping any "./"
+// prefix and redundant separators.
+func zipName(name string) string {
+	if fs.ValidPath(name) {
+		return name
+	}
+	if cleaned := path.Clean(name); fs.ValidPath(cleaned) {
+		return cleaned
+	}
+	return name
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeZip writes the zip archive `name` holding `files`.
+func writeZip(t *testing.T, name string, files map[string]string) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for f, content := range files {
+		w, err := zw.Create(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZipFS(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "release.zip")
+	writeZip(t, archive, map[string]string{
+		"out/bin.meta_lic": "package_name: \"bin\"\nlicense_conditions: \"notice\"\nlicense_texts: \"LICENSE\"\n" +
+			"deps: {\n  file: \"lib/liba.so.meta_lic\"\n  annotations: \"static\"\n}\n",
+		"out/lib/liba.so.meta_lic": "package_name: \"liba\"\nlicense_conditions: \"notice\"\n",
+		"out/LICENSE":              "Licensed.\n",
+		"README":                   "Not in out.\n",
+	})
+
+	zfs, err := OpenZipFS(archive + "!/out")
+	if err != nil {
+		t.Fatalf("OpenZipFS(): got error %s, want no error", err)
+	}
+	defer zfs.Close()
+	if zfs.Archive() != archive {
+		t.Errorf("Archive(): got %q, want %q", zfs.Archive(), archive)
+	}
+
+	lg, err := ReadLicenseGraph(zfs, &bytes.Buffer{}, []string{"./bin.meta_lic"})
+	if err != nil {
+		t.Fatalf("ReadLicenseGraph(zip): got error %s, want no error", err)
+	}
+	if len(lg.Targets()) != 2 {
+		t.Errorf("ReadLicenseGraph(zip): got %d targets, want 2", len(lg.Targets()))
+	}
+	if data, err := fs.ReadFile(zfs, "LICENSE"); err != nil || string(data) != "Licensed.\n" {
+		t.Errorf("ReadFile(LICENSE): got %q, %v, want %q", data, err, "Licensed.\n")
+	}
+	if fi, err := zfs.Stat("lib"); err != nil || !fi.IsDir() {
+		t.Errorf("Stat(lib): got %v, %v, want directory", fi, err)
+	}
+	if _, err := zfs.Stat("README"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(README): got error %v, want fs.ErrNotExist outside the directory", err)
+	}
+
+	whole, err := OpenZipFS(archive)
+	if err != nil {
+		t.Fatalf("OpenZipFS(archive): got error %s, want no error", err)
+	}
+	defer whole.Close()
+	if _, err := whole.Stat("README"); err != nil {
+		t.Errorf("Stat(README): got error %s, want no error", err)
+	}
+
+	for _, name := range []string{archive + "!/missing", archive + "!/out/LICENSE", archive + ".missing"} {
+		if z, err := OpenZipFS(name); err == nil {
+			z.Close()
+			t.Errorf("OpenZipFS(%q): got no error, want error", name)
+		}
+	}
+}